@@ -37,12 +37,15 @@ import (
 	_ "github.com/grafana/alloy/internal/component/local/file"                               // Import local.file
 	_ "github.com/grafana/alloy/internal/component/local/file_match"                         // Import local.file_match
 	_ "github.com/grafana/alloy/internal/component/loki/echo"                                // Import loki.echo
+	_ "github.com/grafana/alloy/internal/component/loki/enrich"                              // Import loki.enrich
 	_ "github.com/grafana/alloy/internal/component/loki/process"                             // Import loki.process
 	_ "github.com/grafana/alloy/internal/component/loki/relabel"                             // Import loki.relabel
 	_ "github.com/grafana/alloy/internal/component/loki/rules/kubernetes"                    // Import loki.rules.kubernetes
 	_ "github.com/grafana/alloy/internal/component/loki/secretfilter"                        // Import loki.secretfilter
 	_ "github.com/grafana/alloy/internal/component/loki/source/api"                          // Import loki.source.api
 	_ "github.com/grafana/alloy/internal/component/loki/source/aws_firehose"                 // Import loki.source.awsfirehose
+	_ "github.com/grafana/alloy/internal/component/loki/source/aws_s3"                       // Import loki.source.awss3
+	_ "github.com/grafana/alloy/internal/component/loki/source/azure_blob"                   // Import loki.source.azure_blob
 	_ "github.com/grafana/alloy/internal/component/loki/source/azure_event_hubs"             // Import loki.source.azure_event_hubs
 	_ "github.com/grafana/alloy/internal/component/loki/source/cloudflare"                   // Import loki.source.cloudflare
 	_ "github.com/grafana/alloy/internal/component/loki/source/docker"                       // Import loki.source.docker
@@ -54,8 +57,12 @@ import (
 	_ "github.com/grafana/alloy/internal/component/loki/source/kafka"                        // Import loki.source.kafka
 	_ "github.com/grafana/alloy/internal/component/loki/source/kubernetes"                   // Import loki.source.kubernetes
 	_ "github.com/grafana/alloy/internal/component/loki/source/kubernetes_events"            // Import loki.source.kubernetes_events
+	_ "github.com/grafana/alloy/internal/component/loki/source/nats"                         // Import loki.source.nats
 	_ "github.com/grafana/alloy/internal/component/loki/source/podlogs"                      // Import loki.source.podlogs
+	_ "github.com/grafana/alloy/internal/component/loki/source/pulsar"                       // Import loki.source.pulsar
 	_ "github.com/grafana/alloy/internal/component/loki/source/syslog"                       // Import loki.source.syslog
+	_ "github.com/grafana/alloy/internal/component/loki/source/tcp"                          // Import loki.source.tcp
+	_ "github.com/grafana/alloy/internal/component/loki/source/udp"                          // Import loki.source.udp
 	_ "github.com/grafana/alloy/internal/component/loki/source/windowsevent"                 // Import loki.source.windowsevent
 	_ "github.com/grafana/alloy/internal/component/loki/write"                               // Import loki.write
 	_ "github.com/grafana/alloy/internal/component/mimir/rules/kubernetes"                   // Import mimir.rules.kubernetes
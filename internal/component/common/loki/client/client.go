@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/log"
@@ -44,9 +45,12 @@ const (
 	ReasonRateLimited   = "rate_limited"
 	ReasonStreamLimited = "stream_limited"
 	ReasonLineTooLong   = "line_too_long"
+	ReasonQueueFull     = "queue_full"
+
+	ReasonStructuredMetadataTooLarge = "structured_metadata_too_large"
 )
 
-var Reasons = []string{ReasonGeneric, ReasonRateLimited, ReasonStreamLimited, ReasonLineTooLong}
+var Reasons = []string{ReasonGeneric, ReasonRateLimited, ReasonStreamLimited, ReasonLineTooLong, ReasonQueueFull, ReasonStructuredMetadataTooLarge}
 
 var userAgent = useragent.Get()
 
@@ -60,6 +64,12 @@ type Metrics struct {
 	mutatedBytes                 *prometheus.CounterVec
 	requestDuration              *prometheus.HistogramVec
 	batchRetries                 *prometheus.CounterVec
+	queuedBatches                *prometheus.GaugeVec
+	queuedBytes                  *prometheus.GaugeVec
+	queueWaitDuration            *prometheus.HistogramVec
+	inflightRequests             *prometheus.GaugeVec
+	endpointHealthy              *prometheus.GaugeVec
+	routedEntries                *prometheus.CounterVec
 	countersWithHost             []*prometheus.CounterVec
 	countersWithHostTenant       []*prometheus.CounterVec
 	countersWithHostTenantReason []*prometheus.CounterVec
@@ -104,6 +114,30 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		Name: "loki_write_batch_retries_total",
 		Help: "Number of times batches has had to be retried.",
 	}, []string{HostLabel, TenantLabel})
+	m.queuedBatches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loki_write_queue_batches",
+		Help: "Number of batches waiting in the queue to be sent.",
+	}, []string{HostLabel})
+	m.queuedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loki_write_queue_bytes",
+		Help: "Number of bytes of the batches waiting in the queue to be sent.",
+	}, []string{HostLabel})
+	m.queueWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "loki_write_queue_wait_duration_seconds",
+		Help: "Time batches waited in the queue before being sent.",
+	}, []string{HostLabel})
+	m.inflightRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loki_write_inflight_requests",
+		Help: "Number of batches being sent.",
+	}, []string{HostLabel})
+	m.endpointHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loki_write_endpoint_healthy",
+		Help: "Whether the endpoint is healthy when log entries are routed by failover or weight.",
+	}, []string{HostLabel})
+	m.routedEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_write_routed_entries_total",
+		Help: "Number of log entries routed to the endpoint when log entries are routed by failover or weight.",
+	}, []string{HostLabel})
 
 	m.countersWithHost = []*prometheus.CounterVec{
 		m.encodedBytes, m.sentBytes, m.sentEntries,
@@ -127,11 +161,25 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.mutatedBytes = util.MustRegisterOrGet(reg, m.mutatedBytes).(*prometheus.CounterVec)
 		m.requestDuration = util.MustRegisterOrGet(reg, m.requestDuration).(*prometheus.HistogramVec)
 		m.batchRetries = util.MustRegisterOrGet(reg, m.batchRetries).(*prometheus.CounterVec)
+		m.queuedBatches = util.MustRegisterOrGet(reg, m.queuedBatches).(*prometheus.GaugeVec)
+		m.queuedBytes = util.MustRegisterOrGet(reg, m.queuedBytes).(*prometheus.GaugeVec)
+		m.queueWaitDuration = util.MustRegisterOrGet(reg, m.queueWaitDuration).(*prometheus.HistogramVec)
+		m.inflightRequests = util.MustRegisterOrGet(reg, m.inflightRequests).(*prometheus.GaugeVec)
+		m.endpointHealthy = util.MustRegisterOrGet(reg, m.endpointHealthy).(*prometheus.GaugeVec)
+		m.routedEntries = util.MustRegisterOrGet(reg, m.routedEntries).(*prometheus.CounterVec)
 	}
 
 	return &m
 }
 
+// initQueueMetrics initializes the queue gauges to 0 so they are exported
+// before the first batch is queued.
+func (m *Metrics) initQueueMetrics(host string) {
+	m.queuedBatches.WithLabelValues(host).Add(0)
+	m.queuedBytes.WithLabelValues(host).Add(0)
+	m.inflightRequests.WithLabelValues(host).Add(0)
+}
+
 // Client pushes entries to Loki and can be stopped
 type Client interface {
 	loki.EntryHandler
@@ -152,6 +200,10 @@ type client struct {
 	once sync.Once
 	wg   sync.WaitGroup
 
+	// failures is the number of consecutive requests which failed with a
+	// connection error or a 5xx status.
+	failures atomic.Int64
+
 	externalLabels model.LabelSet
 
 	// ctx is used in any upstream calls from the `client`.
@@ -215,9 +267,33 @@ func newClient(metrics *Metrics, cfg Config, maxStreams, maxLineSize int, maxLin
 	for _, counter := range c.metrics.countersWithHost {
 		counter.WithLabelValues(c.cfg.URL.Host).Add(0)
 	}
+	c.metrics.initQueueMetrics(c.cfg.URL.Host)
+
+	if cfg.Queue.Shards <= 1 {
+		queue := newSendQueue(cfg.Queue.MaxQueuedBytes)
+		c.wg.Add(1)
+		go c.run(c.entries, queue)
+
+		senders := max(cfg.Queue.MaxInFlight, 1)
+		c.wg.Add(senders)
+		for i := 0; i < senders; i++ {
+			go c.runSender(queue)
+		}
+		return c, nil
+	}
 
+	// Each shard batches and sends the streams it's assigned with a single
+	// sender, so that the entries of a stream are sent in order.
+	shards := make([]chan loki.Entry, cfg.Queue.Shards)
+	for i := range shards {
+		shards[i] = make(chan loki.Entry)
+		queue := newSendQueue(cfg.Queue.MaxQueuedBytes)
+		c.wg.Add(2)
+		go c.run(shards[i], queue)
+		go c.runSender(queue)
+	}
 	c.wg.Add(1)
-	go c.run()
+	go c.runSharding(shards)
 	return c, nil
 }
 
@@ -249,7 +325,24 @@ func (c *client) initBatchMetrics(tenantID string) {
 	}
 }
 
-func (c *client) run() {
+// runSharding sends the entries to the shards by the hash of their stream,
+// until the entries channel is closed.
+func (c *client) runSharding(shards []chan loki.Entry) {
+	defer func() {
+		for _, shard := range shards {
+			close(shard)
+		}
+		c.wg.Done()
+	}()
+
+	for e := range c.entries {
+		shards[uint64(e.Labels.Fingerprint())%uint64(len(shards))] <- e
+	}
+}
+
+// run batches the entries until the entries channel is closed, and adds the
+// batches to queue.
+func (c *client) run(entries <-chan loki.Entry, queue *sendQueue) {
 	batches := map[string]*batch{}
 
 	// Given the client handles multiple batches (1 per tenant) and each batch
@@ -270,15 +363,16 @@ func (c *client) run() {
 		maxWaitCheck.Stop()
 		// Send all pending batches
 		for tenantID, batch := range batches {
-			c.sendBatch(tenantID, batch)
+			c.enqueueBatch(queue, tenantID, batch)
 		}
+		queue.close()
 
 		c.wg.Done()
 	}()
 
 	for {
 		select {
-		case e, ok := <-c.entries:
+		case e, ok := <-entries:
 			if !ok {
 				return
 			}
@@ -298,6 +392,19 @@ func (c *client) run() {
 				e.Line = e.Line[:c.maxLineSize]
 			}
 
+			// Either drop the log entry or mutate its structured metadata because it exceeds the structured metadata limits.
+			md, removedBytes, ok := c.cfg.StructuredMetadataLimits.apply(e.StructuredMetadata)
+			if !ok {
+				c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Inc()
+				c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Add(float64(len(e.Line)))
+				break
+			}
+			if removedBytes > 0 {
+				c.metrics.mutatedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Inc()
+				c.metrics.mutatedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Add(float64(removedBytes))
+			}
+			e.StructuredMetadata = md
+
 			batch, ok := batches[tenantID]
 
 			// If the batch doesn't exist yet, we create a new one with the entry
@@ -310,7 +417,7 @@ func (c *client) run() {
 			// If adding the entry to the batch will increase the size over the max
 			// size allowed, we do send the current batch and then create a new one
 			if batch.sizeBytesAfter(e.Entry) > c.cfg.BatchSize {
-				c.sendBatch(tenantID, batch)
+				c.enqueueBatch(queue, tenantID, batch)
 
 				batches[tenantID] = newBatch(c.maxStreams, e)
 				break
@@ -335,7 +442,7 @@ func (c *client) run() {
 					continue
 				}
 
-				c.sendBatch(tenantID, batch)
+				c.enqueueBatch(queue, tenantID, batch)
 				delete(batches, tenantID)
 			}
 		}
@@ -350,15 +457,62 @@ func batchIsRateLimited(status int) bool {
 	return status == 429
 }
 
-func (c *client) sendBatch(tenantID string, batch *batch) {
+// enqueueBatch encodes the batch and adds it to the send queue. If the queue is
+// full, it either waits for room in the queue or drops the batch, depending on
+// the configuration of the queue.
+func (c *client) enqueueBatch(queue *sendQueue, tenantID string, batch *batch) {
 	buf, entriesCount, err := batch.encode()
 	if err != nil {
 		level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
 		return
 	}
+	buf, err = compress(buf, c.cfg.Compression, c.cfg.CompressionLevel)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "error compressing batch", "error", err)
+		return
+	}
 	bufBytes := float64(len(buf))
 	c.metrics.encodedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 
+	// Count the batch as queued before pushing it, so the gauges don't go
+	// negative if a sender pops it right away.
+	c.metrics.queuedBatches.WithLabelValues(c.cfg.URL.Host).Inc()
+	c.metrics.queuedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
+
+	eb := encodedBatch{tenantID: tenantID, buf: buf, entries: entriesCount}
+	if !queue.push(eb, c.cfg.Queue.OnFull == QueueFullDrop) {
+		c.metrics.queuedBatches.WithLabelValues(c.cfg.URL.Host).Dec()
+		c.metrics.queuedBytes.WithLabelValues(c.cfg.URL.Host).Sub(bufBytes)
+		level.Warn(c.logger).Log("msg", "dropping batch because the send queue is full", "tenant", tenantID)
+		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonQueueFull).Add(bufBytes)
+		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonQueueFull).Add(float64(entriesCount))
+		return
+	}
+}
+
+// runSender sends the batches of queue until it's closed and empty.
+func (c *client) runSender(queue *sendQueue) {
+	defer c.wg.Done()
+
+	for {
+		eb, ok := queue.pop()
+		if !ok {
+			return
+		}
+		c.metrics.queuedBatches.WithLabelValues(c.cfg.URL.Host).Dec()
+		c.metrics.queuedBytes.WithLabelValues(c.cfg.URL.Host).Sub(float64(len(eb.buf)))
+		c.metrics.queueWaitDuration.WithLabelValues(c.cfg.URL.Host).Observe(time.Since(eb.enqueuedAt).Seconds())
+
+		c.metrics.inflightRequests.WithLabelValues(c.cfg.URL.Host).Inc()
+		c.sendBatch(eb.tenantID, eb.buf, eb.entries)
+		c.metrics.inflightRequests.WithLabelValues(c.cfg.URL.Host).Dec()
+	}
+}
+
+func (c *client) sendBatch(tenantID string, buf []byte, entriesCount int) {
+	bufBytes := float64(len(buf))
+
+	var err error
 	backoff := backoff.New(c.ctx, c.cfg.BackoffConfig)
 	var status int
 	for {
@@ -368,6 +522,12 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 
 		c.metrics.requestDuration.WithLabelValues(strconv.Itoa(status), c.cfg.URL.Host).Observe(time.Since(start).Seconds())
 
+		if err != nil && (status <= 0 || status/100 == 5) {
+			c.failures.Add(1)
+		} else {
+			c.failures.Store(0)
+		}
+
 		// Immediately drop rate limited batches to avoid HOL blocking for other tenants not experiencing throttling
 		if c.cfg.DropRateLimitedBatches && batchIsRateLimited(status) {
 			level.Warn(c.logger).Log("msg", "dropping batch due to rate limiting applied at ingester")
@@ -419,6 +579,9 @@ func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, er
 		return -1, err
 	}
 	req.Header.Set("Content-Type", contentType)
+	if encoding := contentEncoding(c.cfg.Compression); encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 	req.Header.Set("User-Agent", userAgent)
 
 	// If the tenant ID is not empty promtail is running in multi-tenant mode, so
@@ -456,19 +619,7 @@ func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, er
 }
 
 func (c *client) getTenantID(labels model.LabelSet) string {
-	// Check if it has been overridden while processing the pipeline stages
-	if value, ok := labels[ReservedLabelTenantID]; ok {
-		return string(value)
-	}
-
-	// Check if has been specified in the config
-	if c.cfg.TenantID != "" {
-		return c.cfg.TenantID
-	}
-
-	// Defaults to an empty string, which means the X-Scope-OrgID header
-	// will not be sent
-	return ""
+	return resolveTenantID(c.cfg, labels)
 }
 
 // Stop the client.
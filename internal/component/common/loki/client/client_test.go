@@ -5,13 +5,17 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/grafana/loki/pkg/push"
 
 	"github.com/go-kit/log"
+	"github.com/golang/snappy"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/client_golang/prometheus"
@@ -25,6 +29,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 
+	lokipush "github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	lokiflag "github.com/grafana/loki/v3/pkg/util/flagext"
 )
@@ -88,20 +93,26 @@ func TestClient_Handle(t *testing.T) {
                                # TYPE loki_write_dropped_entries_total counter
                                loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                               loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                                # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                                # TYPE loki_write_mutated_entries_total counter
                                loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                               loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                                # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                                # TYPE loki_write_mutated_bytes_total counter
                                loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                               loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                        `,
 		},
 		"dropping log entries that have max_line_size exceeded": {
@@ -126,20 +137,26 @@ func TestClient_Handle(t *testing.T) {
                                # TYPE loki_write_dropped_entries_total counter
                                loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 1
+                               loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                                # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                                # TYPE loki_write_mutated_entries_total counter
                                loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                               loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                        `,
 		},
 		"truncating log entries that have max_line_size exceeded": {
@@ -171,20 +188,26 @@ func TestClient_Handle(t *testing.T) {
                                # TYPE loki_write_dropped_entries_total counter
                                loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                               loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                                # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                                # TYPE loki_write_mutated_entries_total counter
                                loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 1
+                               loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                                loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                               loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 4
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                        `,
 		},
 
@@ -213,20 +236,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                        `,
 		},
 		"retry send a batch up to backoff's max retries in case the server responds with a 5xx": {
@@ -254,20 +283,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 1
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_sent_entries_total Number of log entries sent to the ingester.
                               # TYPE loki_write_sent_entries_total counter
                               loki_write_sent_entries_total{host="__HOST__"} 0
@@ -290,20 +325,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 1
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_sent_entries_total Number of log entries sent to the ingester.
                               # TYPE loki_write_sent_entries_total counter
                               loki_write_sent_entries_total{host="__HOST__"} 0
@@ -334,20 +375,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 1
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_sent_entries_total Number of log entries sent to the ingester.
                               # TYPE loki_write_sent_entries_total counter
                               loki_write_sent_entries_total{host="__HOST__"} 0
@@ -371,20 +418,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 1
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_sent_entries_total Number of log entries sent to the ingester.
                               # TYPE loki_write_sent_entries_total counter
                               loki_write_sent_entries_total{host="__HOST__"} 0
@@ -411,20 +464,26 @@ func TestClient_Handle(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__", reason="ingester_error", tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__", reason="rate_limited", tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                        `,
 		},
 		"batch log entries together honoring the tenant ID overridden while processing the pipeline stages": {
@@ -458,42 +517,60 @@ func TestClient_Handle(t *testing.T) {
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-2"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-1"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-1"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-2"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-2"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-1"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-2"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-default"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-1"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-1"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-2"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-2"} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                               # HELP loki_write_mutated_entries_total The total number of log entries that have been mutated.
                               # TYPE loki_write_mutated_entries_total counter
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-1"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-2"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="ingester_error",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-1"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-1"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-2"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-2"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-1"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-2"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="rate_limited",tenant="tenant-default"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-1"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-1"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-2"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-2"} 0
                               loki_write_mutated_entries_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_mutated_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                               # HELP loki_write_mutated_bytes_total The total number of bytes that have been mutated.
                               # TYPE loki_write_mutated_bytes_total counter
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant="tenant-1"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant="tenant-2"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="ingester_error",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant="tenant-1"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant="tenant-1"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant="tenant-2"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant="tenant-2"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="line_too_long",tenant="tenant-default"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="queue_full",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant="tenant-1"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant="tenant-2"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="rate_limited",tenant="tenant-default"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant="tenant-1"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-1"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant="tenant-2"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-2"} 0
                               loki_write_mutated_bytes_total{host="__HOST__",reason="stream_limited",tenant="tenant-default"} 0
+                              loki_write_mutated_bytes_total{host="__HOST__",reason="structured_metadata_too_large",tenant="tenant-default"} 0
                        `,
 		},
 	}
@@ -609,8 +686,10 @@ func TestClient_StopNow(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                        `,
 		},
 		{
@@ -631,8 +710,10 @@ func TestClient_StopNow(t *testing.T) {
                               # TYPE loki_write_dropped_entries_total counter
                               loki_write_dropped_entries_total{host="__HOST__",reason="ingester_error",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="line_too_long",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="queue_full",tenant=""} 0
                               loki_write_dropped_entries_total{host="__HOST__",reason="rate_limited",tenant=""} 1
                               loki_write_dropped_entries_total{host="__HOST__",reason="stream_limited",tenant=""} 0
+                              loki_write_dropped_entries_total{host="__HOST__",reason="structured_metadata_too_large",tenant=""} 0
                               # HELP loki_write_sent_entries_total Number of log entries sent to the ingester.
                               # TYPE loki_write_sent_entries_total counter
                               loki_write_sent_entries_total{host="__HOST__"} 0
@@ -747,3 +828,198 @@ func Test_Tripperware(t *testing.T) {
 	c.Stop()
 	require.True(t, called)
 }
+
+func TestClient_Compression(t *testing.T) {
+	url, err := url.Parse("http://foo.com")
+	require.NoError(t, err)
+
+	for _, compression := range []string{"", CompressionSnappy, CompressionGzip} {
+		t.Run(compression, func(t *testing.T) {
+			var (
+				encoding string
+				pushReq  *logproto.PushRequest
+			)
+			c, err := NewWithTripperware(metrics, Config{
+				URL:         flagext.URLValue{URL: url},
+				Compression: compression,
+			}, 0, 0, false, log.NewNopLogger(), func(rt http.RoundTripper) http.RoundTripper {
+				return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					encoding = r.Header.Get("Content-Encoding")
+
+					// Decode the request the way Loki's push handler does.
+					var err error
+					pushReq, err = lokipush.ParseRequest(log.NewNopLogger(), "", r, nil, nil, lokipush.ParseLokiRequest, nil)
+					require.NoError(t, err)
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader("ok")),
+					}, nil
+				})
+			})
+			require.NoError(t, err)
+
+			c.Chan() <- loki.Entry{
+				Labels: model.LabelSet{"foo": "bar"},
+				Entry:  logproto.Entry{Timestamp: time.Now(), Line: "foo"},
+			}
+			c.Stop()
+
+			if compression == CompressionSnappy {
+				require.Empty(t, encoding)
+			} else {
+				require.Equal(t, compression, encoding)
+			}
+			require.NotNil(t, pushReq)
+			require.Len(t, pushReq.Streams, 1)
+			require.Equal(t, "foo", pushReq.Streams[0].Entries[0].Line)
+		})
+	}
+}
+
+func TestClient_QueueFull(t *testing.T) {
+	url, err := url.Parse("http://foo.com")
+	require.NoError(t, err)
+
+	for _, onFull := range []string{QueueFullBlock, QueueFullDrop} {
+		t.Run(onFull, func(t *testing.T) {
+			var (
+				release = make(chan struct{})
+				sent    atomic.Int64
+			)
+			m := NewMetrics(prometheus.NewRegistry())
+			c, err := NewWithTripperware(m, Config{
+				URL:       flagext.URLValue{URL: url},
+				BatchSize: 10,
+				BatchWait: time.Minute,
+				Queue: QueueConfig{
+					MaxInFlight:    1,
+					MaxQueuedBytes: 1,
+					OnFull:         onFull,
+				},
+			}, 0, 0, false, log.NewNopLogger(), func(rt http.RoundTripper) http.RoundTripper {
+				return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					// Stall the endpoint until the test releases it.
+					<-release
+					sent.Add(1)
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader("ok")),
+					}, nil
+				})
+			})
+			require.NoError(t, err)
+
+			// Every entry is bigger than the batch size, so each of them is sent in its own batch.
+			const entries = 5
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < entries; i++ {
+					c.Chan() <- loki.Entry{
+						Labels: model.LabelSet{"foo": "bar"},
+						Entry:  logproto.Entry{Timestamp: time.Now(), Line: fmt.Sprintf("line %d", i)},
+					}
+				}
+			}()
+
+			if onFull == QueueFullBlock {
+				select {
+				case <-done:
+					t.Fatal("entries were accepted while the queue was full")
+				case <-time.After(200 * time.Millisecond):
+				}
+				close(release)
+				<-done
+				c.Stop()
+
+				require.Equal(t, int64(entries), sent.Load())
+				require.Zero(t, testutil.ToFloat64(m.droppedEntries.WithLabelValues(url.Host, "", ReasonQueueFull)))
+			} else {
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					t.Fatal("entries were blocked while the queue was full")
+				}
+				close(release)
+				c.Stop()
+
+				dropped := testutil.ToFloat64(m.droppedEntries.WithLabelValues(url.Host, "", ReasonQueueFull))
+				require.Positive(t, dropped)
+				require.Equal(t, float64(entries), float64(sent.Load())+dropped)
+			}
+			require.Zero(t, testutil.ToFloat64(m.queuedBatches.WithLabelValues(url.Host)))
+			require.Zero(t, testutil.ToFloat64(m.inflightRequests.WithLabelValues(url.Host)))
+		})
+	}
+}
+
+func TestClient_Shards(t *testing.T) {
+	url, err := url.Parse("http://foo.com")
+	require.NoError(t, err)
+
+	var (
+		mut         sync.Mutex
+		received    = map[string][]string{}
+		inflight    atomic.Int64
+		maxInflight atomic.Int64
+	)
+	c, err := NewWithTripperware(NewMetrics(prometheus.NewRegistry()), Config{
+		URL:       flagext.URLValue{URL: url},
+		BatchSize: 100,
+		BatchWait: time.Minute,
+		Queue:     QueueConfig{Shards: 4},
+	}, 0, 0, false, log.NewNopLogger(), func(rt http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			n := inflight.Add(1)
+			defer inflight.Add(-1)
+			for {
+				m := maxInflight.Load()
+				if n <= m || maxInflight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			// Slow down the requests, so that the shards send concurrently.
+			time.Sleep(time.Millisecond)
+
+			buf, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			buf, err = snappy.Decode(nil, buf)
+			require.NoError(t, err)
+			var pushReq logproto.PushRequest
+			require.NoError(t, pushReq.Unmarshal(buf))
+
+			mut.Lock()
+			defer mut.Unlock()
+			for _, s := range pushReq.Streams {
+				for _, e := range s.Entries {
+					received[s.Labels] = append(received[s.Labels], e.Line)
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("ok")),
+			}, nil
+		})
+	})
+	require.NoError(t, err)
+
+	const (
+		streams = 10
+		entries = 50
+	)
+	expected := map[string][]string{}
+	for i := 0; i < entries; i++ {
+		for s := 0; s < streams; s++ {
+			labels := model.LabelSet{"stream": model.LabelValue(strconv.Itoa(s))}
+			line := fmt.Sprintf("line %d", i)
+			expected[labels.String()] = append(expected[labels.String()], line)
+			c.Chan() <- loki.Entry{Labels: labels, Entry: logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: line}}
+		}
+	}
+	c.Stop()
+
+	// The entries of each stream are sent in order.
+	require.Equal(t, expected, received)
+	require.Greater(t, maxInflight.Load(), int64(1))
+}
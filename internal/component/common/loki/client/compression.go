@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// Compression algorithms of push requests. Push requests are always
+// snappy-encoded protobufs, gzip compresses them further and is sent as the
+// Content-Encoding of the request. Loki gunzips the body and then decodes it
+// as snappy, so gzip must wrap the snappy bytes rather than the raw protobuf.
+// Loki doesn't accept other encodings, such as zstd.
+const (
+	CompressionSnappy = "snappy"
+	CompressionGzip   = "gzip"
+)
+
+// ValidateCompression checks that the compression algorithm and level are
+// supported. A level of zero means the default level of the algorithm.
+func ValidateCompression(compression string, level int) error {
+	switch compression {
+	case "", CompressionSnappy:
+		if level != 0 {
+			return fmt.Errorf("compression level is not supported with %s compression", CompressionSnappy)
+		}
+	case CompressionGzip:
+		if level < 0 || level > gzip.BestCompression {
+			return fmt.Errorf("gzip compression level must be between 1 and %d", gzip.BestCompression)
+		}
+	default:
+		return fmt.Errorf("unsupported compression %q, must be %q or %q", compression, CompressionSnappy, CompressionGzip)
+	}
+	return nil
+}
+
+// contentEncoding returns the Content-Encoding header of push requests, or
+// an empty string if it isn't set.
+func contentEncoding(compression string) string {
+	switch compression {
+	case CompressionGzip:
+		return compression
+	default:
+		return ""
+	}
+}
+
+// compress compresses an encoded push request with the configured
+// algorithm.
+func compress(buf []byte, compression string, level int) ([]byte, error) {
+	switch compression {
+	case CompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		var out bytes.Buffer
+		w, err := gzip.NewWriterLevel(&out, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(buf); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	default:
+		return buf, nil
+	}
+}
@@ -40,6 +40,12 @@ type Config struct {
 	// single tenant mode)
 	TenantID string `yaml:"tenant_id"`
 
+	// TenantLabel is the name of a label whose value is used as the tenant
+	// ID of an entry, and TenantRoutes select the tenant ID of an entry by
+	// its labels. Both take precedence over TenantID.
+	TenantLabel  string        `yaml:"tenant_label,omitempty"`
+	TenantRoutes []TenantRoute `yaml:"-"`
+
 	// When enabled, Promtail will not retry batches that get a
 	// 429 'Too Many Requests' response from the distributor. Helps
 	// prevent HOL blocking in multitenant deployments.
@@ -47,6 +53,14 @@ type Config struct {
 
 	// Queue controls configuration parameters specific to the queue client
 	Queue QueueConfig
+
+	// Compression is the algorithm used to compress push requests, and
+	// CompressionLevel its level. An empty Compression means snappy.
+	Compression      string `yaml:"compression,omitempty"`
+	CompressionLevel int    `yaml:"compression_level,omitempty"`
+
+	// StructuredMetadataLimits limits the structured metadata of each entry.
+	StructuredMetadataLimits StructuredMetadataLimits `yaml:"-"`
 }
 
 // QueueConfig holds configurations for the queue-based remote-write client.
@@ -61,8 +75,35 @@ type QueueConfig struct {
 
 	// DrainTimeout controls the maximum time that draining the send queue can take.
 	DrainTimeout time.Duration
+
+	// MaxInFlight is the maximum number of batches sent concurrently. Zero means one.
+	MaxInFlight int
+
+	// Shards is the number of shards of the client without WAL. Entries are
+	// assigned to shards by the hash of their stream, and each shard batches
+	// and sends its entries with a single sender, ignoring MaxInFlight. Zero
+	// or one disables sharding.
+	Shards int
+
+	// MaxQueuedBytes is the size in bytes of the encoded batches waiting to be sent by the client without WAL. When
+	// the limit is reached, new batches are handled according to OnFull. A batch is always accepted when the queue is
+	// empty.
+	MaxQueuedBytes int
+
+	// OnFull is the behaviour of the client without WAL when its queue is full, either QueueFullBlock or
+	// QueueFullDrop. An empty OnFull means QueueFullBlock.
+	OnFull string
 }
 
+// Behaviours of the client when its send queue is full.
+const (
+	// QueueFullBlock waits until there's room in the queue, blocking the
+	// components sending entries.
+	QueueFullBlock = "block"
+	// QueueFullDrop drops the batches that don't fit in the queue.
+	QueueFullDrop = "drop"
+)
+
 // RegisterFlags with prefix registers flags where every name is prefixed by
 // prefix. If prefix is a non-empty string, prefix should end with a period.
 func (c *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
@@ -79,6 +79,8 @@ type Manager struct {
 
 	clients []Client
 	pairs   []watcherClientPair
+	// router is nil when the entries are sent to all the clients.
+	router *router
 
 	entries chan loki.Entry
 	once    sync.Once
@@ -87,7 +89,7 @@ type Manager struct {
 }
 
 // NewManager creates a new Manager
-func NewManager(metrics *Metrics, logger log.Logger, limits limit.Config, reg prometheus.Registerer, walCfg wal.Config, notifier WriterEventsNotifier, clientCfgs ...Config) (*Manager, error) {
+func NewManager(metrics *Metrics, logger log.Logger, limits limit.Config, reg prometheus.Registerer, walCfg wal.Config, routingCfg RoutingConfig, notifier WriterEventsNotifier, clientCfgs ...Config) (*Manager, error) {
 	var fake struct{}
 
 	walWatcherMetrics := wal.NewWatcherMetrics(reg)
@@ -97,9 +99,17 @@ func NewManager(metrics *Metrics, logger log.Logger, limits limit.Config, reg pr
 	if len(clientCfgs) == 0 {
 		return nil, fmt.Errorf("at least one client config must be provided")
 	}
+	if err := ValidateRoutingMode(routingCfg.Mode); err != nil {
+		return nil, err
+	}
+	routed := routingCfg.Mode != "" && routingCfg.Mode != RoutingFanout
+	if routed && walCfg.Enabled {
+		return nil, fmt.Errorf("routing mode %q isn't supported when WAL is enabled", routingCfg.Mode)
+	}
 
 	clientsCheck := make(map[string]struct{})
 	clients := make([]Client, 0, len(clientCfgs))
+	routedClients := make([]*client, 0, len(clientCfgs))
 	pairs := make([]watcherClientPair, 0, len(clientCfgs))
 	for _, cfg := range clientCfgs {
 		// Don't allow duplicate clients, we have client specific metrics that need at least one unique label value (name).
@@ -141,12 +151,13 @@ func NewManager(metrics *Metrics, logger log.Logger, limits limit.Config, reg pr
 				client:  queue,
 			})
 		} else {
-			client, err := New(metrics, cfg, limits.MaxStreams, limits.MaxLineSize.Val(), limits.MaxLineSizeTruncate, logger)
+			client, err := newClient(metrics, cfg, limits.MaxStreams, limits.MaxLineSize.Val(), limits.MaxLineSizeTruncate, logger)
 			if err != nil {
 				return nil, fmt.Errorf("error starting client: %w", err)
 			}
 
 			clients = append(clients, client)
+			routedClients = append(routedClients, client)
 
 			pairs = append(pairs, watcherClientPair{
 				client: client,
@@ -158,6 +169,9 @@ func NewManager(metrics *Metrics, logger log.Logger, limits limit.Config, reg pr
 		pairs:   pairs,
 		entries: make(chan loki.Entry),
 	}
+	if routed {
+		manager.router = newRouter(routingCfg, metrics, logger, routedClients)
+	}
 	if walCfg.Enabled {
 		manager.name = buildManagerName("wal", clientCfgs...)
 		manager.startWithConsume()
@@ -185,12 +199,18 @@ func (m *Manager) startWithConsume() {
 }
 
 // startWithForward starts the main manager routine, which reads entries from the exposed channel, and forwards them
-// doing a fan-out across all inner clients.
+// doing a fan-out across all inner clients, or to the clients picked by the router if routing is enabled.
 func (m *Manager) startWithForward() {
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
 		for e := range m.entries {
+			if m.router != nil {
+				for _, c := range m.router.route(e) {
+					c.Chan() <- e
+				}
+				continue
+			}
 			for _, c := range m.clients {
 				c.Chan() <- e
 			}
@@ -50,7 +50,7 @@ func TestManager_NoDuplicateMetricsPanic(t *testing.T) {
 		for i := 0; i < 2; i++ {
 			_, err := NewManager(metrics, log.NewLogfmtLogger(os.Stdout), testLimitsConfig, reg, wal.Config{
 				WatchConfig: wal.DefaultWatchConfig,
-			}, NilNotifier, Config{
+			}, RoutingConfig{}, NilNotifier, Config{
 				URL: flagext.URLValue{URL: host},
 			})
 			require.NoError(t, err)
@@ -66,7 +66,7 @@ func TestManager_ErrorCreatingWhenNoClientConfigsProvided(t *testing.T) {
 				Dir:         walDir,
 				Enabled:     walEnabled,
 				WatchConfig: wal.DefaultWatchConfig,
-			}, NilNotifier)
+			}, RoutingConfig{}, NilNotifier)
 			require.Error(t, err)
 		})
 	}
@@ -88,7 +88,7 @@ func TestManager_ErrorCreatingWhenRepeatedConfigs(t *testing.T) {
 				Dir:         walDir,
 				Enabled:     walEnabled,
 				WatchConfig: wal.DefaultWatchConfig,
-			}, NilNotifier, config1, config1Copy)
+			}, RoutingConfig{}, NilNotifier, config1, config1Copy)
 			require.Error(t, err)
 		})
 	}
@@ -148,7 +148,7 @@ func TestManager_WALEnabled(t *testing.T) {
 	// start writer and manager
 	writer, err := wal.NewWriter(walConfig, logger, reg)
 	require.NoError(t, err)
-	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, writer, testClientConfig)
+	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, RoutingConfig{}, writer, testClientConfig)
 	require.NoError(t, err)
 	require.Equal(t, "wal:test-client", manager.Name())
 
@@ -204,7 +204,7 @@ func TestManager_WALDisabled(t *testing.T) {
 	clientMetrics := NewMetrics(reg)
 
 	// start writer and manager
-	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, NilNotifier, testClientConfig)
+	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, RoutingConfig{}, NilNotifier, testClientConfig)
 	require.NoError(t, err)
 	require.Equal(t, "multi:test-client", manager.Name())
 
@@ -273,7 +273,7 @@ func TestManager_WALDisabled_MultipleConfigs(t *testing.T) {
 	clientMetrics := NewMetrics(reg)
 
 	// start writer and manager
-	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, NilNotifier, testClientConfig, testClientConfig2)
+	manager, err := NewManager(clientMetrics, logger, testLimitsConfig, prometheus.NewRegistry(), walConfig, RoutingConfig{}, NilNotifier, testClientConfig, testClientConfig2)
 	require.NoError(t, err)
 	require.Equal(t, "multi:test-client,test-client-2", manager.Name())
 
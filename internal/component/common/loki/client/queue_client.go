@@ -45,8 +45,9 @@ type MarkerHandler interface {
 
 // queuedBatch is a batch specific to a tenant, that is considered ready to be sent.
 type queuedBatch struct {
-	TenantID string
-	Batch    *batch
+	TenantID   string
+	Batch      *batch
+	enqueuedAt time.Time
 }
 
 // queue wraps a buffered channel and a routine that reads from it, sending batches of entries.
@@ -58,7 +59,8 @@ type queue struct {
 	logger log.Logger
 }
 
-func newQueue(client *queueClient, size int, logger log.Logger) *queue {
+// newQueue creates a queue of the given size, from which up to maxInFlight batches are sent concurrently.
+func newQueue(client *queueClient, size, maxInFlight int, logger log.Logger) *queue {
 	q := queue{
 		client: client,
 		q:      make(chan queuedBatch, size),
@@ -66,8 +68,11 @@ func newQueue(client *queueClient, size int, logger log.Logger) *queue {
 		logger: logger,
 	}
 
-	q.wg.Add(1)
-	go q.run()
+	senders := max(maxInFlight, 1)
+	q.wg.Add(senders)
+	for i := 0; i < senders; i++ {
+		go q.run()
+	}
 
 	return &q
 }
@@ -75,20 +80,32 @@ func newQueue(client *queueClient, size int, logger log.Logger) *queue {
 // enqueue adds to the send queue a batch ready to be sent. Note that if the backing queue is has no
 // remaining capacity to enqueue the batch, calling enqueue might block.
 func (q *queue) enqueue(qb queuedBatch) {
+	qb.enqueuedAt = time.Now()
+	q.reportQueued(qb, 1)
 	q.q <- qb
 }
 
 // enqueueWithCancel tries to enqueue a batch, giving up if the supplied context times deadlines
 // times out. If the batch is successfully enqueued, it returns true.
 func (q *queue) enqueueWithCancel(ctx context.Context, qb queuedBatch) bool {
+	qb.enqueuedAt = time.Now()
+	q.reportQueued(qb, 1)
 	select {
 	case <-ctx.Done():
+		q.reportQueued(qb, -1)
 		return false
 	case q.q <- qb:
 	}
 	return true
 }
 
+// reportQueued updates the queue metrics when a batch is added to (sign 1) or removed from (sign -1) the queue.
+func (q *queue) reportQueued(qb queuedBatch, sign float64) {
+	host := q.client.cfg.URL.Host
+	q.client.metrics.queuedBatches.WithLabelValues(host).Add(sign)
+	q.client.metrics.queuedBytes.WithLabelValues(host).Add(sign * float64(qb.Batch.sizeBytes()))
+}
+
 func (q *queue) run() {
 	defer q.wg.Done()
 
@@ -99,7 +116,7 @@ func (q *queue) run() {
 		case qb := <-q.q:
 			// Since inside the actual send operation a context with time out is used, we should exceed that timeout
 			// instead of cancelling this send operation, since that batch has been taken out of the queue.
-			q.sendAndReport(context.Background(), qb.TenantID, qb.Batch)
+			q.sendAndReport(context.Background(), qb)
 		}
 	}
 }
@@ -121,7 +138,7 @@ func (q *queue) closeAndDrain(ctx context.Context) {
 		case qb := <-q.q:
 			// drain uses the same timeout, so if a timeout was applied to the parent context, it can cancel the underlying
 			// send operation preemptively.
-			q.sendAndReport(ctx, qb.TenantID, qb.Batch)
+			q.sendAndReport(ctx, qb)
 		case <-ctx.Done():
 			level.Warn(q.logger).Log("msg", "timeout exceeded while draining send queue")
 			return
@@ -135,10 +152,17 @@ func (q *queue) closeAndDrain(ctx context.Context) {
 
 // sendAndReport attempts to send the batch for the given tenant, and either way that operation succeeds or fails, reports
 // the data as sent.
-func (q *queue) sendAndReport(ctx context.Context, tenantId string, b *batch) {
-	q.client.sendBatch(ctx, tenantId, b)
+func (q *queue) sendAndReport(ctx context.Context, qb queuedBatch) {
+	host := q.client.cfg.URL.Host
+	q.reportQueued(qb, -1)
+	q.client.metrics.queueWaitDuration.WithLabelValues(host).Observe(time.Since(qb.enqueuedAt).Seconds())
+
+	q.client.metrics.inflightRequests.WithLabelValues(host).Inc()
+	q.client.sendBatch(ctx, qb.TenantID, qb.Batch)
+	q.client.metrics.inflightRequests.WithLabelValues(host).Dec()
+
 	// mark segment data for that batch as sent, even if the send operation failed
-	b.reportAsSentData(q.client.markerHandler)
+	qb.Batch.reportAsSentData(q.client.markerHandler)
 }
 
 // closeNow closes the queue, without draining batches that might be buffered to be sent.
@@ -219,7 +243,7 @@ func newQueueClient(metrics *Metrics, qcMetrics *QueueClientMetrics, cfg Config,
 	// The buffered channel size is calculated using the configured capacity, which is the worst case number of bytes
 	// the send queue can consume.
 	var queueBufferSize = cfg.Queue.Capacity / cfg.BatchSize
-	c.sendQueue = newQueue(c, queueBufferSize, logger)
+	c.sendQueue = newQueue(c, queueBufferSize, cfg.Queue.MaxInFlight, logger)
 
 	err := cfg.Client.Validate()
 	if err != nil {
@@ -238,6 +262,7 @@ func newQueueClient(metrics *Metrics, qcMetrics *QueueClientMetrics, cfg Config,
 	for _, counter := range c.metrics.countersWithHost {
 		counter.WithLabelValues(c.cfg.URL.Host).Add(0)
 	}
+	c.metrics.initQueueMetrics(c.cfg.URL.Host)
 
 	c.wg.Add(1)
 	go c.runSendOldBatches()
@@ -322,6 +347,19 @@ func (c *queueClient) appendSingleEntry(segmentNum int, lbs model.LabelSet, e lo
 		e.Line = e.Line[:c.maxLineSize]
 	}
 
+	// Either drop the log entry or mutate its structured metadata because it exceeds the structured metadata limits.
+	md, removedBytes, ok := c.cfg.StructuredMetadataLimits.apply(e.StructuredMetadata)
+	if !ok {
+		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Inc()
+		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Add(float64(len(e.Line)))
+		return
+	}
+	if removedBytes > 0 {
+		c.metrics.mutatedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Inc()
+		c.metrics.mutatedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonStructuredMetadataTooLarge).Add(float64(removedBytes))
+	}
+	e.StructuredMetadata = md
+
 	// TODO: can I make this locking more fine grained?
 	c.batchesMtx.Lock()
 
@@ -455,6 +493,11 @@ func (c *queueClient) sendBatch(ctx context.Context, tenantID string, batch *bat
 		level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
 		return
 	}
+	buf, err = compress(buf, c.cfg.Compression, c.cfg.CompressionLevel)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "error compressing batch", "error", err)
+		return
+	}
 	bufBytes := float64(len(buf))
 	c.metrics.encodedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 
@@ -519,6 +562,9 @@ func (c *queueClient) send(ctx context.Context, tenantID string, buf []byte) (in
 	}
 	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", contentType)
+	if encoding := contentEncoding(c.cfg.Compression); encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 	req.Header.Set("User-Agent", userAgent)
 
 	// If the tenant ID is not empty promtail is running in multi-tenant mode, so
@@ -556,19 +602,7 @@ func (c *queueClient) send(ctx context.Context, tenantID string, buf []byte) (in
 }
 
 func (c *queueClient) getTenantID(labels model.LabelSet) string {
-	// Check if it has been overridden while processing the pipeline stages
-	if value, ok := labels[ReservedLabelTenantID]; ok {
-		return string(value)
-	}
-
-	// Check if has been specified in the config
-	if c.cfg.TenantID != "" {
-		return c.cfg.TenantID
-	}
-
-	// Defaults to an empty string, which means the X-Scope-OrgID header
-	// will not be sent
-	return ""
+	return resolveTenantID(c.cfg, labels)
 }
 
 // Stop the client, enqueueing pending batches and draining the send queue accordingly. Both closing operations are
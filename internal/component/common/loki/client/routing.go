@@ -0,0 +1,193 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Routing modes, which decide the clients of the Manager each entry is sent
+// to.
+const (
+	// RoutingFanout sends every entry to all the clients.
+	RoutingFanout = "fanout"
+	// RoutingFailover sends the entries to the first healthy client.
+	RoutingFailover = "failover"
+	// RoutingWeighted splits the streams between the healthy clients in
+	// proportion to their weights.
+	RoutingWeighted = "weighted"
+)
+
+// RoutingConfig configures how the Manager routes entries to its clients. It
+// only applies when WAL is disabled.
+type RoutingConfig struct {
+	// Mode is one of RoutingFanout, RoutingFailover and RoutingWeighted. An
+	// empty Mode means RoutingFanout.
+	Mode string
+
+	// Weights are the weights of the clients in RoutingWeighted mode, in the
+	// order of their configs. A missing or zero weight means 1.
+	Weights []int
+
+	// FailureThreshold is the number of consecutive failed requests after
+	// which a client is unhealthy. RecoveryInterval is how often an unhealthy
+	// client is sent an entry to check whether it recovered.
+	FailureThreshold int
+	RecoveryInterval time.Duration
+}
+
+// ValidateRoutingMode returns an error if mode isn't a supported routing
+// mode.
+func ValidateRoutingMode(mode string) error {
+	switch mode {
+	case "", RoutingFanout, RoutingFailover, RoutingWeighted:
+		return nil
+	default:
+		return fmt.Errorf("unsupported routing mode %q, must be one of %q, %q or %q", mode, RoutingFanout, RoutingFailover, RoutingWeighted)
+	}
+}
+
+// route is a client the router can send entries to.
+type route struct {
+	client *client
+	weight int
+
+	// unhealthy is set once the client reaches the failure threshold, and
+	// lastProbe is when it was last sent an entry while unhealthy.
+	unhealthy bool
+	lastProbe time.Time
+}
+
+// router picks the clients each entry is sent to in RoutingFailover and
+// RoutingWeighted modes. It isn't safe for concurrent use.
+type router struct {
+	cfg     RoutingConfig
+	metrics *Metrics
+	logger  log.Logger
+	routes  []*route
+	now     func() time.Time
+
+	// healthy and dst are reused across calls to avoid allocations.
+	healthy []bool
+	dst     []*client
+}
+
+func newRouter(cfg RoutingConfig, metrics *Metrics, logger log.Logger, clients []*client) *router {
+	r := &router{
+		cfg:     cfg,
+		metrics: metrics,
+		logger:  logger,
+		now:     time.Now,
+		healthy: make([]bool, len(clients)),
+	}
+	for i, c := range clients {
+		weight := 1
+		if i < len(cfg.Weights) && cfg.Weights[i] > 0 {
+			weight = cfg.Weights[i]
+		}
+		r.routes = append(r.routes, &route{client: c, weight: weight})
+		metrics.endpointHealthy.WithLabelValues(c.cfg.URL.Host).Set(1)
+		metrics.routedEntries.WithLabelValues(c.cfg.URL.Host).Add(0)
+	}
+	return r
+}
+
+// route returns the clients to send the entry to. The returned slice is only
+// valid until the next call.
+func (r *router) route(e loki.Entry) []*client {
+	now := r.now()
+	r.dst = r.dst[:0]
+
+	anyHealthy := false
+	for i, rt := range r.routes {
+		healthy, probe := r.checkHealth(rt, now)
+		r.healthy[i] = healthy
+		anyHealthy = anyHealthy || healthy
+		if probe {
+			r.add(rt)
+		}
+	}
+
+	switch r.cfg.Mode {
+	case RoutingFailover:
+		// The entries go to the primary client if none is healthy.
+		picked := r.routes[0]
+		for i, rt := range r.routes {
+			if r.healthy[i] {
+				picked = rt
+				break
+			}
+		}
+		r.add(picked)
+	case RoutingWeighted:
+		// Streams are assigned by their hash, so that all the entries of a
+		// stream go to the same client. All the clients are used if none is
+		// healthy.
+		var total uint64
+		for i, rt := range r.routes {
+			if r.healthy[i] || !anyHealthy {
+				total += uint64(rt.weight)
+			}
+		}
+		n := uint64(e.Labels.Fingerprint()) % total
+		for i, rt := range r.routes {
+			if !r.healthy[i] && anyHealthy {
+				continue
+			}
+			if n < uint64(rt.weight) {
+				r.add(rt)
+				break
+			}
+			n -= uint64(rt.weight)
+		}
+	}
+	return r.dst
+}
+
+// add adds the client of rt to the clients to send the entry to, unless it's
+// already there.
+func (r *router) add(rt *route) {
+	for _, c := range r.dst {
+		if c == rt.client {
+			return
+		}
+	}
+	r.dst = append(r.dst, rt.client)
+	r.metrics.routedEntries.WithLabelValues(rt.client.cfg.URL.Host).Inc()
+}
+
+// checkHealth returns whether the client of rt is healthy, and whether it
+// should be sent the entry to check whether it recovered. An unhealthy client
+// is healthy again as soon as one of its requests succeeds.
+func (r *router) checkHealth(rt *route, now time.Time) (healthy, probe bool) {
+	failures := rt.client.failures.Load()
+	host := rt.client.cfg.URL.Host
+
+	if !rt.unhealthy {
+		if failures < int64(r.cfg.FailureThreshold) {
+			return true, false
+		}
+		level.Warn(r.logger).Log("msg", "endpoint is unhealthy, routing log entries to other endpoints", "host", host, "failures", failures)
+		rt.unhealthy = true
+		rt.lastProbe = now
+		r.metrics.endpointHealthy.WithLabelValues(host).Set(0)
+		return false, false
+	}
+
+	if failures == 0 {
+		level.Info(r.logger).Log("msg", "endpoint recovered", "host", host)
+		rt.unhealthy = false
+		r.metrics.endpointHealthy.WithLabelValues(host).Set(1)
+		return true, false
+	}
+
+	if now.Sub(rt.lastProbe) >= r.cfg.RecoveryInterval {
+		rt.lastProbe = now
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+func newTestRouter(t *testing.T, cfg RoutingConfig, hosts ...string) (*router, []*client, *time.Time) {
+	var clients []*client
+	for _, host := range hosts {
+		u, err := url.Parse("http://" + host + "/loki/api/v1/push")
+		require.NoError(t, err)
+		clients = append(clients, &client{cfg: Config{URL: flagext.URLValue{URL: u}}})
+	}
+
+	now := time.Now()
+	r := newRouter(cfg, NewMetrics(prometheus.NewRegistry()), log.NewNopLogger(), clients)
+	r.now = func() time.Time { return now }
+	return r, clients, &now
+}
+
+func TestRouter_Failover(t *testing.T) {
+	r, clients, now := newTestRouter(t, RoutingConfig{
+		Mode:             RoutingFailover,
+		FailureThreshold: 3,
+		RecoveryInterval: time.Minute,
+	}, "primary", "backup")
+	primary, backup := clients[0], clients[1]
+	e := loki.Entry{Labels: model.LabelSet{"job": "api"}}
+
+	require.Equal(t, []*client{primary}, r.route(e))
+
+	// The primary client stays healthy until it reaches the threshold.
+	primary.failures.Store(2)
+	require.Equal(t, []*client{primary}, r.route(e))
+	primary.failures.Store(3)
+	require.Equal(t, []*client{backup}, r.route(e))
+	require.Equal(t, 0.0, testutil.ToFloat64(r.metrics.endpointHealthy.WithLabelValues("primary")))
+
+	// An entry is sent to the unhealthy client once per recovery interval.
+	*now = now.Add(time.Minute)
+	require.Equal(t, []*client{primary, backup}, r.route(e))
+	require.Equal(t, []*client{backup}, r.route(e))
+
+	// The client is healthy again once a request succeeds.
+	primary.failures.Store(0)
+	require.Equal(t, []*client{primary}, r.route(e))
+	require.Equal(t, 1.0, testutil.ToFloat64(r.metrics.endpointHealthy.WithLabelValues("primary")))
+
+	// The primary client is used if no client is healthy.
+	primary.failures.Store(3)
+	backup.failures.Store(3)
+	require.Equal(t, []*client{primary}, r.route(e))
+
+	require.Equal(t, 5.0, testutil.ToFloat64(r.metrics.routedEntries.WithLabelValues("primary")))
+	require.Equal(t, 3.0, testutil.ToFloat64(r.metrics.routedEntries.WithLabelValues("backup")))
+}
+
+func TestRouter_Weighted(t *testing.T) {
+	r, clients, _ := newTestRouter(t, RoutingConfig{
+		Mode:             RoutingWeighted,
+		Weights:          []int{3, 1},
+		FailureThreshold: 1,
+		RecoveryInterval: time.Minute,
+	}, "a", "b")
+
+	streams := make([]loki.Entry, 1000)
+	for i := range streams {
+		streams[i] = loki.Entry{Labels: model.LabelSet{"stream": model.LabelValue(fmt.Sprint(i))}}
+	}
+
+	counts := map[*client]int{}
+	assigned := map[int]*client{}
+	for i, e := range streams {
+		dst := r.route(e)
+		require.Len(t, dst, 1)
+		counts[dst[0]]++
+		assigned[i] = dst[0]
+	}
+	require.InDelta(t, 750, counts[clients[0]], 75)
+	require.InDelta(t, 250, counts[clients[1]], 75)
+
+	// The entries of a stream always go to the same client.
+	for i, e := range streams {
+		require.Equal(t, []*client{assigned[i]}, r.route(e))
+	}
+
+	// The streams of an unhealthy client are moved to the healthy ones.
+	clients[1].failures.Store(1)
+	for _, e := range streams {
+		require.Equal(t, []*client{clients[0]}, r.route(e))
+	}
+}
+
+func TestValidateRoutingMode(t *testing.T) {
+	for _, mode := range []string{"", RoutingFanout, RoutingFailover, RoutingWeighted} {
+		require.NoError(t, ValidateRoutingMode(mode))
+	}
+	require.EqualError(t, ValidateRoutingMode("random"), `unsupported routing mode "random", must be one of "fanout", "failover" or "weighted"`)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// encodedBatch is a batch of a tenant which has been encoded, and is ready to
+// be sent.
+type encodedBatch struct {
+	tenantID   string
+	buf        []byte
+	entries    int
+	enqueuedAt time.Time
+}
+
+// sendQueue buffers the encoded batches waiting to be sent, limiting the
+// bytes they take. Unlike a buffered channel, its capacity doesn't depend on
+// the size of the batches.
+type sendQueue struct {
+	mtx      sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+
+	batches  []encodedBatch
+	bytes    int
+	maxBytes int
+	closed   bool
+}
+
+func newSendQueue(maxBytes int) *sendQueue {
+	q := &sendQueue{maxBytes: maxBytes}
+	q.notEmpty = sync.NewCond(&q.mtx)
+	q.notFull = sync.NewCond(&q.mtx)
+	return q
+}
+
+// hasRoom returns whether b fits in the queue. A batch always fits in an
+// empty queue, so that batches bigger than the limit can still be sent.
+// The caller must hold mtx.
+func (q *sendQueue) hasRoom(b encodedBatch) bool {
+	return len(q.batches) == 0 || q.bytes+len(b.buf) <= q.maxBytes
+}
+
+// push adds b to the queue. If the queue is full, push either waits until
+// there's room for b, or returns false right away if drop is set. It also
+// returns false if the queue is closed.
+func (q *sendQueue) push(b encodedBatch, drop bool) bool {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	for !q.closed && !q.hasRoom(b) {
+		if drop {
+			return false
+		}
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return false
+	}
+
+	b.enqueuedAt = time.Now()
+	q.batches = append(q.batches, b)
+	q.bytes += len(b.buf)
+	q.notEmpty.Signal()
+	return true
+}
+
+// pop removes the oldest batch of the queue, waiting for one if the queue is
+// empty. It returns false once the queue is closed and empty.
+func (q *sendQueue) pop() (encodedBatch, bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	for !q.closed && len(q.batches) == 0 {
+		q.notEmpty.Wait()
+	}
+	if len(q.batches) == 0 {
+		return encodedBatch{}, false
+	}
+
+	b := q.batches[0]
+	q.batches[0] = encodedBatch{}
+	q.batches = q.batches[1:]
+	q.bytes -= len(b.buf)
+	q.notFull.Broadcast()
+	return b, true
+}
+
+// close stops the queue from accepting batches. The batches already in the
+// queue can still be popped.
+func (q *sendQueue) close() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/loki/pkg/push"
+)
+
+// Policies applied to the structured metadata of entries which exceed the
+// StructuredMetadataLimits.
+const (
+	// StructuredMetadataDropKeys drops structured metadata pairs, lowest
+	// priority first, until the limits are met.
+	StructuredMetadataDropKeys = "drop_keys"
+	// StructuredMetadataTruncate truncates structured metadata values, lowest
+	// priority first, until the limits are met. Pairs are only dropped to
+	// meet the count limit, or if truncating every value isn't enough.
+	StructuredMetadataTruncate = "truncate"
+	// StructuredMetadataReject drops the whole entry.
+	StructuredMetadataReject = "reject"
+)
+
+// StructuredMetadataLimits limits the structured metadata of each entry, so
+// that pushes aren't rejected by the limits of Loki.
+type StructuredMetadataLimits struct {
+	// MaxEntriesCount is the maximum number of structured metadata pairs of
+	// an entry. Zero means no limit.
+	MaxEntriesCount int
+	// MaxSize is the maximum size in bytes of the names and values of the
+	// structured metadata of an entry. Zero means no limit.
+	MaxSize int
+	// OverflowPolicy is applied to entries exceeding the limits. An empty
+	// OverflowPolicy means StructuredMetadataDropKeys.
+	OverflowPolicy string
+	// Priority lists the names of the structured metadata to keep the
+	// longest, most important first. Names which aren't listed have the
+	// lowest priority.
+	Priority []string
+}
+
+// ValidateStructuredMetadataLimits checks that the limits and the overflow
+// policy are supported.
+func ValidateStructuredMetadataLimits(l StructuredMetadataLimits) error {
+	if l.MaxEntriesCount < 0 {
+		return fmt.Errorf("structured metadata max entries count must not be negative")
+	}
+	if l.MaxSize < 0 {
+		return fmt.Errorf("structured metadata max size must not be negative")
+	}
+	switch l.OverflowPolicy {
+	case "", StructuredMetadataDropKeys, StructuredMetadataTruncate, StructuredMetadataReject:
+		return nil
+	default:
+		return fmt.Errorf("unsupported structured metadata overflow policy %q, must be one of %q, %q or %q",
+			l.OverflowPolicy, StructuredMetadataDropKeys, StructuredMetadataTruncate, StructuredMetadataReject)
+	}
+}
+
+func structuredMetadataSize(md push.LabelsAdapter) int {
+	size := 0
+	for _, l := range md {
+		size += len(l.Name) + len(l.Value)
+	}
+	return size
+}
+
+// exceeded returns whether an entry with count structured metadata pairs of
+// the given size exceeds the limits.
+func (l StructuredMetadataLimits) exceeded(count, size int) bool {
+	return (l.MaxEntriesCount > 0 && count > l.MaxEntriesCount) || (l.MaxSize > 0 && size > l.MaxSize)
+}
+
+// apply enforces the limits on the structured metadata of an entry. It
+// returns the structured metadata to send and the number of bytes removed
+// from it, or false if the entry must be dropped. md is never modified, since
+// it can be shared with other clients.
+func (l StructuredMetadataLimits) apply(md push.LabelsAdapter) (push.LabelsAdapter, int, bool) {
+	origSize := structuredMetadataSize(md)
+	if !l.exceeded(len(md), origSize) {
+		return md, 0, true
+	}
+	if l.OverflowPolicy == StructuredMetadataReject {
+		return nil, 0, false
+	}
+
+	out := make(push.LabelsAdapter, len(md))
+	copy(out, md)
+
+	// Pairs are removed or truncated in order of increasing priority. Pairs
+	// with the same priority are handled from last to first.
+	rank := make(map[string]int, len(l.Priority))
+	for i, name := range l.Priority {
+		if _, ok := rank[name]; !ok {
+			rank[name] = i
+		}
+	}
+	rankOf := func(name string) int {
+		if r, ok := rank[name]; ok {
+			return r
+		}
+		return len(l.Priority)
+	}
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = len(out) - 1 - i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rankOf(out[order[i]].Name) > rankOf(out[order[j]].Name)
+	})
+
+	var (
+		removed = make([]bool, len(out))
+		count   = len(out)
+		size    = origSize
+	)
+	remove := func(i int) {
+		removed[i] = true
+		count--
+		size -= len(out[i].Name) + len(out[i].Value)
+	}
+
+	if l.OverflowPolicy == StructuredMetadataTruncate {
+		for _, i := range order {
+			if l.MaxEntriesCount <= 0 || count <= l.MaxEntriesCount {
+				break
+			}
+			remove(i)
+		}
+		for _, i := range order {
+			if l.MaxSize <= 0 || size <= l.MaxSize {
+				break
+			}
+			if removed[i] {
+				continue
+			}
+			cut := min(size-l.MaxSize, len(out[i].Value))
+			out[i].Value = out[i].Value[:len(out[i].Value)-cut]
+			size -= cut
+		}
+	}
+
+	for _, i := range order {
+		if !l.exceeded(count, size) {
+			break
+		}
+		if !removed[i] {
+			remove(i)
+		}
+	}
+
+	kept := out[:0]
+	for i, pair := range out {
+		if !removed[i] {
+			kept = append(kept, pair)
+		}
+	}
+	return kept, origSize - size, true
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredMetadataLimits(t *testing.T) {
+	md := push.LabelsAdapter{
+		{Name: "trace_id", Value: "0123456789"},
+		{Name: "user", Value: "alice"},
+		{Name: "pod", Value: "loki-0"},
+		{Name: "span_id", Value: "abcd"},
+	}
+
+	tests := []struct {
+		name            string
+		limits          StructuredMetadataLimits
+		expected        push.LabelsAdapter
+		expectedRemoved int
+		expectedOK      bool
+	}{
+		{
+			name:       "within limits",
+			limits:     StructuredMetadataLimits{MaxEntriesCount: 4, MaxSize: 100},
+			expected:   md,
+			expectedOK: true,
+		},
+		{
+			name:   "drop unlisted keys last to first",
+			limits: StructuredMetadataLimits{MaxEntriesCount: 2, Priority: []string{"trace_id", "span_id"}},
+			expected: push.LabelsAdapter{
+				{Name: "trace_id", Value: "0123456789"},
+				{Name: "span_id", Value: "abcd"},
+			},
+			expectedRemoved: 18,
+			expectedOK:      true,
+		},
+		{
+			name:   "drop keys by priority to meet the size limit",
+			limits: StructuredMetadataLimits{MaxSize: 20, OverflowPolicy: StructuredMetadataDropKeys, Priority: []string{"trace_id", "span_id"}},
+			expected: push.LabelsAdapter{
+				{Name: "trace_id", Value: "0123456789"},
+			},
+			expectedRemoved: 29,
+			expectedOK:      true,
+		},
+		{
+			name:   "truncate lowest priority values first",
+			limits: StructuredMetadataLimits{MaxSize: 40, OverflowPolicy: StructuredMetadataTruncate, Priority: []string{"trace_id", "span_id", "user"}},
+			expected: push.LabelsAdapter{
+				{Name: "trace_id", Value: "0123456789"},
+				{Name: "user", Value: "alic"},
+				{Name: "pod", Value: ""},
+				{Name: "span_id", Value: "abcd"},
+			},
+			expectedRemoved: 7,
+			expectedOK:      true,
+		},
+		{
+			name:   "truncate drops keys to meet the count limit",
+			limits: StructuredMetadataLimits{MaxEntriesCount: 3, MaxSize: 33, OverflowPolicy: StructuredMetadataTruncate},
+			expected: push.LabelsAdapter{
+				{Name: "trace_id", Value: "0123456789"},
+				{Name: "user", Value: "alice"},
+				{Name: "pod", Value: "lok"},
+			},
+			expectedRemoved: 14,
+			expectedOK:      true,
+		},
+		{
+			name:       "reject",
+			limits:     StructuredMetadataLimits{MaxEntriesCount: 3, OverflowPolicy: StructuredMetadataReject},
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := make(push.LabelsAdapter, len(md))
+			copy(original, md)
+
+			out, removed, ok := tc.limits.apply(md)
+			require.Equal(t, tc.expectedOK, ok)
+			require.Equal(t, tc.expected, out)
+			require.Equal(t, tc.expectedRemoved, removed)
+			// The input is shared with other clients and must not be modified.
+			require.Equal(t, original, md)
+		})
+	}
+}
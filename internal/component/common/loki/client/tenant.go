@@ -0,0 +1,64 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/clients/pkg/logentry/logql"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// TenantRoute sends the entries whose labels match all of its matchers to
+// Tenant.
+type TenantRoute struct {
+	Matchers []*labels.Matcher
+	Tenant   string
+}
+
+// ParseTenantRoute creates a TenantRoute from a LogQL stream selector.
+func ParseTenantRoute(selector string, tenant string) (TenantRoute, error) {
+	if tenant == "" {
+		return TenantRoute{}, fmt.Errorf("tenant of route %q must not be empty", selector)
+	}
+	matchers, err := logql.ParseMatchers(selector)
+	if err != nil {
+		return TenantRoute{}, fmt.Errorf("invalid tenant route selector %q: %w", selector, err)
+	}
+	return TenantRoute{Matchers: matchers, Tenant: tenant}, nil
+}
+
+func (r TenantRoute) matches(lbs model.LabelSet) bool {
+	for _, m := range r.Matchers {
+		if !m.Matches(string(lbs[model.LabelName(m.Name)])) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveTenantID returns the tenant entries with the given labels are sent
+// to. In order of precedence, the tenant is taken from the reserved tenant
+// label, the configured tenant label, the first matching tenant route and
+// finally the static tenant ID.
+func resolveTenantID(cfg Config, lbs model.LabelSet) string {
+	// Check if it has been overridden while processing the pipeline stages
+	if value, ok := lbs[ReservedLabelTenantID]; ok {
+		return string(value)
+	}
+
+	if cfg.TenantLabel != "" {
+		if value := lbs[model.LabelName(cfg.TenantLabel)]; value != "" {
+			return string(value)
+		}
+	}
+
+	for _, route := range cfg.TenantRoutes {
+		if route.matches(lbs) {
+			return route.Tenant
+		}
+	}
+
+	// Defaults to the tenant specified in the config. An empty string means
+	// the X-Scope-OrgID header will not be sent
+	return cfg.TenantID
+}
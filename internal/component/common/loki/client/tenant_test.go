@@ -0,0 +1,67 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTenantID(t *testing.T) {
+	teamA, err := ParseTenantRoute(`{namespace=~"team-a-.*"}`, "team-a")
+	require.NoError(t, err)
+	teamB, err := ParseTenantRoute(`{namespace="shared", team="b"}`, "team-b")
+	require.NoError(t, err)
+
+	cfg := Config{
+		TenantID:     "default",
+		TenantLabel:  "tenant",
+		TenantRoutes: []TenantRoute{teamA, teamB},
+	}
+
+	tests := []struct {
+		name     string
+		labels   model.LabelSet
+		expected string
+	}{
+		{
+			name:     "reserved label takes precedence",
+			labels:   model.LabelSet{ReservedLabelTenantID: "reserved", "tenant": "label", "namespace": "team-a-prod"},
+			expected: "reserved",
+		},
+		{
+			name:     "tenant label",
+			labels:   model.LabelSet{"tenant": "label", "namespace": "team-a-prod"},
+			expected: "label",
+		},
+		{
+			name:     "first matching route",
+			labels:   model.LabelSet{"namespace": "team-a-prod"},
+			expected: "team-a",
+		},
+		{
+			name:     "route with several matchers",
+			labels:   model.LabelSet{"namespace": "shared", "team": "b"},
+			expected: "team-b",
+		},
+		{
+			name:     "static tenant when nothing matches",
+			labels:   model.LabelSet{"namespace": "shared"},
+			expected: "default",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, resolveTenantID(cfg, tc.labels))
+		})
+	}
+}
+
+func TestParseTenantRoute(t *testing.T) {
+	_, err := ParseTenantRoute(`{namespace="a"}`, "")
+	require.ErrorContains(t, err, "must not be empty")
+
+	_, err = ParseTenantRoute(`namespace="a"`, "a")
+	require.ErrorContains(t, err, "invalid tenant route selector")
+}
@@ -31,6 +31,10 @@ type Config struct {
 	// Note that this functionality will likely be deprecated in favour of a programmatic cleanup mechanism.
 	MaxSegmentAge time.Duration
 
+	// MaxSize is the size in bytes above which the oldest WAL segments are deleted, even if they haven't been sent yet.
+	// Zero means the size of the WAL is not limited.
+	MaxSize int64
+
 	// WatchConfig configures the backoff retry used by a WAL watcher when reading from segments not via
 	// the notification channel.
 	WatchConfig WatchConfig
@@ -24,6 +24,9 @@ import (
 
 const (
 	minimumCleanSegmentsEvery = time.Second
+	// cleanSegmentsBySizeEvery is the maximum time between cleanups when the
+	// WAL has a maximum size, so that it doesn't grow far beyond it.
+	cleanSegmentsBySizeEvery = 5 * time.Second
 )
 
 // CleanupEventSubscriber is an interface that objects that want to receive events from the wal Writer can implement. After
@@ -58,6 +61,7 @@ type Writer struct {
 	writeSubscribers     []WriteEventSubscriber
 
 	reclaimedOldSegmentsSpaceCounter *prometheus.CounterVec
+	droppedSegmentsCounter           *prometheus.CounterVec
 	lastReclaimedSegment             *prometheus.GaugeVec
 	lastWrittenTimestamp             *prometheus.GaugeVec
 
@@ -91,6 +95,13 @@ func NewWriter(walCfg Config, logger log.Logger, reg prometheus.Registerer) (*Wr
 		Help:      "Number of bytes reclaimed from storage.",
 	}, []string{})
 
+	wrt.droppedSegmentsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki_write",
+		Subsystem: "wal_writer",
+		Name:      "size_limit_dropped_segments_total",
+		Help:      "Number of segments deleted because the WAL exceeded its maximum size.",
+	}, []string{})
+
 	wrt.lastReclaimedSegment = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "loki_write",
 		Subsystem: "wal_writer",
@@ -106,15 +117,16 @@ func NewWriter(walCfg Config, logger log.Logger, reg prometheus.Registerer) (*Wr
 
 	if reg != nil {
 		_ = reg.Register(wrt.reclaimedOldSegmentsSpaceCounter)
+		_ = reg.Register(wrt.droppedSegmentsCounter)
 		_ = reg.Register(wrt.lastReclaimedSegment)
 		_ = reg.Register(wrt.lastWrittenTimestamp)
 	}
 
-	wrt.start(walCfg.MaxSegmentAge)
+	wrt.start(walCfg.MaxSegmentAge, walCfg.MaxSize)
 	return wrt, nil
 }
 
-func (wrt *Writer) start(maxSegmentAge time.Duration) {
+func (wrt *Writer) start(maxSegmentAge time.Duration, maxSize int64) {
 	wrt.wg.Add(1)
 	// main WAL writer routine
 	go func() {
@@ -146,12 +158,15 @@ func (wrt *Writer) start(maxSegmentAge time.Duration) {
 		if triggerEvery < minimumCleanSegmentsEvery {
 			triggerEvery = minimumCleanSegmentsEvery
 		}
+		if maxSize > 0 && triggerEvery > cleanSegmentsBySizeEvery {
+			triggerEvery = cleanSegmentsBySizeEvery
+		}
 		trigger := time.NewTicker(triggerEvery)
 		for {
 			select {
 			case <-trigger.C:
 				level.Debug(wrt.log).Log("msg", "Running wal old segments cleanup")
-				if err := wrt.cleanSegments(maxSegmentAge); err != nil {
+				if err := wrt.cleanSegments(maxSegmentAge, maxSize); err != nil {
 					level.Error(wrt.log).Log("msg", "Error cleaning old segments", "err", err)
 				}
 			case <-wrt.closeCleaner:
@@ -180,9 +195,12 @@ func (wrt *Writer) Stop() {
 
 // cleanSegments will remove segments older than maxAge from the WAL directory. If there's just one segment, none will be
 // deleted since it's likely there's active readers on it. In case there's multiple segments, each will be deleted if:
-// - It's not the last (highest numbered) segment
-// - It's last modified date is older than the max allowed age
-func (wrt *Writer) cleanSegments(maxAge time.Duration) error {
+// - It's not the last (highest numbered) segment, which is the one being written to
+// - It's last modified date is older than the max allowed age, or the WAL is larger than maxSize
+//
+// When the WAL is larger than maxSize, segments are deleted from the oldest (lowest numbered) one until it fits, even
+// if they're newer than maxAge. A maxSize of zero means the WAL size is not limited.
+func (wrt *Writer) cleanSegments(maxAge time.Duration, maxSize int64) error {
 	maxModifiedAt := time.Now().Add(-maxAge)
 	walDir := wrt.wal.Dir()
 	segments, err := listSegments(walDir)
@@ -193,27 +211,37 @@ func (wrt *Writer) cleanSegments(maxAge time.Duration) error {
 	if len(segments) <= 1 {
 		return nil
 	}
-	// find the most recent, or head segment to avoid cleaning it up
-	lastSegment := -1
-	maxReclaimed := -1
+	// segments are sorted by number, so the last one is the most recent, or head segment, which is never cleaned up
+	lastSegment := segments[len(segments)-1].number
+	var totalSize int64
 	for _, segment := range segments {
-		if lastSegment < segment.number {
-			lastSegment = segment.number
-		}
+		totalSize += segment.size
 	}
+
+	maxReclaimed := -1
 	for _, segment := range segments {
-		if segment.lastModified.Before(maxModifiedAt) && segment.number != lastSegment {
-			// segment is older than allowed age, cleaning up
-			if err := os.Remove(filepath.Join(walDir, segment.name)); err != nil {
-				level.Error(wrt.log).Log("msg", "Error old wal segment", "err", err, "segmentNum", segment.number)
-			}
-			level.Debug(wrt.log).Log("msg", "Deleted old wal segment", "segmentNum", segment.number)
-			wrt.reclaimedOldSegmentsSpaceCounter.WithLabelValues().Add(float64(segment.size))
-			// keep track of the largest segment number reclaimed
-			if segment.number > maxReclaimed {
-				maxReclaimed = segment.number
-			}
+		if segment.number == lastSegment {
+			break
+		}
+		old := segment.lastModified.Before(maxModifiedAt)
+		overSize := maxSize > 0 && totalSize > maxSize
+		if !old && !overSize {
+			// segments are cleaned up in order, so that the WAL keeps no gaps
+			break
+		}
+		// segment is older than allowed age, or the WAL is too large, cleaning up
+		if err := os.Remove(filepath.Join(walDir, segment.name)); err != nil {
+			level.Error(wrt.log).Log("msg", "Error old wal segment", "err", err, "segmentNum", segment.number)
+			break
+		}
+		level.Debug(wrt.log).Log("msg", "Deleted old wal segment", "segmentNum", segment.number)
+		wrt.reclaimedOldSegmentsSpaceCounter.WithLabelValues().Add(float64(segment.size))
+		if !old {
+			level.Warn(wrt.log).Log("msg", "Deleted wal segment because the wal exceeded its maximum size", "segmentNum", segment.number, "maxSize", maxSize)
+			wrt.droppedSegmentsCounter.WithLabelValues().Inc()
 		}
+		totalSize -= segment.size
+		maxReclaimed = segment.number
 	}
 	// if we reclaimed at least one segment, notify all subscribers
 	if maxReclaimed != -1 {
@@ -10,6 +10,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
@@ -156,6 +157,130 @@ func TestWriter_OldSegmentsAreCleanedUp(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWriter_SegmentsAreCleanedUpBySize(t *testing.T) {
+	logger := level.NewFilter(log.NewLogfmtLogger(os.Stdout), level.AllowDebug())
+	dir := t.TempDir()
+
+	// The size limit is checked by calling cleanSegments directly, so that
+	// the cleanup routine doesn't interfere.
+	writer, err := NewWriter(Config{
+		Dir:           dir,
+		Enabled:       true,
+		MaxSegmentAge: time.Hour,
+	}, logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer func() {
+		writer.Stop()
+	}()
+
+	var reclaimed []int
+	writer.SubscribeCleanup(notifySegmentsCleanedFunc(func(num int) {
+		reclaimed = append(reclaimed, num)
+	}))
+
+	// write one entry to each of four segments
+	for i := 0; i < 4; i++ {
+		writer.Chan() <- loki.Entry{
+			Labels: model.LabelSet{"testing": "log"},
+			Entry: logproto.Entry{
+				Timestamp: time.Now(),
+				Line:      fmt.Sprintf("line %d", i),
+			},
+		}
+		// accessing the WAL inside, just for testing!
+		require.NoError(t, writer.wal.Sync(), "failed to sync wal")
+		if i < 3 {
+			_, err = writer.wal.NextSegment()
+			require.NoError(t, err, "failed to create next segment")
+		}
+	}
+
+	segments, err := listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 4)
+
+	// keep room for all segments but the first one
+	var maxSize int64
+	for _, segment := range segments[1:] {
+		maxSize += segment.size
+	}
+	require.NoError(t, writer.cleanSegments(time.Hour, maxSize))
+
+	segments, err = listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+	require.Equal(t, 1, segments[0].number)
+	require.Equal(t, []int{0}, reclaimed)
+	require.Equal(t, 1.0, testutil.ToFloat64(writer.droppedSegmentsCounter))
+
+	// the head segment is never cleaned up, even if it's larger than the limit
+	require.NoError(t, writer.cleanSegments(time.Hour, 1))
+	segments, err = listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	require.Equal(t, 3, segments[0].number)
+}
+
+func TestWriter_SegmentsNewerThanMaxAgeAreCleanedUpBySize(t *testing.T) {
+	logger := level.NewFilter(log.NewLogfmtLogger(os.Stdout), level.AllowDebug())
+	dir := t.TempDir()
+
+	writer, err := NewWriter(Config{
+		Dir:           dir,
+		Enabled:       true,
+		MaxSegmentAge: 24 * time.Hour,
+	}, logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer func() {
+		writer.Stop()
+	}()
+
+	var reclaimed []int
+	writer.SubscribeCleanup(notifySegmentsCleanedFunc(func(num int) {
+		reclaimed = append(reclaimed, num)
+	}))
+
+	// write one entry to each of five segments
+	for i := 0; i < 5; i++ {
+		writer.Chan() <- loki.Entry{
+			Labels: model.LabelSet{"testing": "log"},
+			Entry: logproto.Entry{
+				Timestamp: time.Now(),
+				Line:      fmt.Sprintf("line %d", i),
+			},
+		}
+		// accessing the WAL inside, just for testing!
+		require.NoError(t, writer.wal.Sync(), "failed to sync wal")
+		if i < 4 {
+			_, err = writer.wal.NextSegment()
+			require.NoError(t, err, "failed to create next segment")
+		}
+	}
+
+	// all segments are newer than the max age, and the oldest ones were modified last, so that only their order
+	// decides which ones are deleted
+	now := time.Now()
+	segments, err := listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 5)
+	for _, segment := range segments {
+		modifiedAt := now.Add(-time.Duration(segment.number) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, segment.name), modifiedAt, modifiedAt))
+	}
+
+	// keep room for the last two segments only
+	maxSize := segments[3].size + segments[4].size
+	require.NoError(t, writer.cleanSegments(24*time.Hour, maxSize))
+
+	segments, err = listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	require.Equal(t, 3, segments[0].number)
+	require.Equal(t, 4, segments[1].number)
+	require.Equal(t, []int{2}, reclaimed)
+	require.Equal(t, 3.0, testutil.ToFloat64(writer.droppedSegmentsCounter))
+}
+
 func TestWriter_NoSegmentIsCleanedUpIfTheresOnlyOne(t *testing.T) {
 	logger := level.NewFilter(log.NewLogfmtLogger(os.Stdout), level.AllowDebug())
 	dir := t.TempDir()
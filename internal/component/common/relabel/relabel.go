@@ -1,11 +1,15 @@
 package relabel
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/grafana/regexp"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 )
 
@@ -25,6 +29,10 @@ const (
 	Uppercase Action = "uppercase"
 	KeepEqual Action = "keepequal"
 	DropEqual Action = "dropequal"
+
+	// SHA256 sets a label to the hex encoded SHA-256 hash of labels. It's
+	// only supported by components which relabel log entries.
+	SHA256 Action = "sha256"
 )
 
 var actions = map[Action]struct{}{
@@ -39,6 +47,7 @@ var actions = map[Action]struct{}{
 	Uppercase: {},
 	KeepEqual: {},
 	DropEqual: {},
+	SHA256:    {},
 }
 
 // String returns the string representation of the Action type.
@@ -146,7 +155,7 @@ func (rc *Config) Validate() error {
 	if rc.Modulus == 0 && rc.Action == HashMod {
 		return fmt.Errorf("relabel configuration for hashmod requires non-zero modulus")
 	}
-	if (rc.Action == Replace || rc.Action == HashMod || rc.Action == Lowercase || rc.Action == Uppercase || rc.Action == KeepEqual || rc.Action == DropEqual) && rc.TargetLabel == "" {
+	if (rc.Action == Replace || rc.Action == HashMod || rc.Action == SHA256 || rc.Action == Lowercase || rc.Action == Uppercase || rc.Action == KeepEqual || rc.Action == DropEqual) && rc.TargetLabel == "" {
 		return fmt.Errorf("relabel configuration for %s action requires 'target_label' value", rc.Action)
 	}
 	if (rc.Action == Replace || rc.Action == Lowercase || rc.Action == Uppercase || rc.Action == KeepEqual || rc.Action == DropEqual) && !relabelTarget.MatchString(rc.TargetLabel) {
@@ -158,7 +167,7 @@ func (rc *Config) Validate() error {
 	if rc.Action == LabelMap && !relabelTarget.MatchString(rc.Replacement) {
 		return fmt.Errorf("%q is invalid 'replacement' for %s action", rc.Replacement, rc.Action)
 	}
-	if rc.Action == HashMod && !model.LabelName(rc.TargetLabel).IsValid() {
+	if (rc.Action == HashMod || rc.Action == SHA256) && !model.LabelName(rc.TargetLabel).IsValid() {
 		return fmt.Errorf("%q is invalid 'target_label' for %s action", rc.TargetLabel, rc.Action)
 	}
 
@@ -173,6 +182,10 @@ func (rc *Config) Validate() error {
 		}
 	}
 
+	if rc.Action == SHA256 && rc.Replacement != DefaultRelabelConfig.Replacement {
+		return fmt.Errorf("'replacement' can not be set for %s action", rc.Action)
+	}
+
 	if rc.Action == KeepEqual || rc.Action == DropEqual {
 		if !reflect.DeepEqual(*rc.Regex.Regexp, *DefaultRelabelConfig.Regex.Regexp) ||
 			rc.Modulus != DefaultRelabelConfig.Modulus ||
@@ -210,6 +223,49 @@ func ComponentToPromRelabelConfigs(rcs []*Config) []*relabel.Config {
 	return res
 }
 
+// ValidateMetricsActions returns an error if rcs use actions which are only
+// supported for log entries. It must be used by components which pass the
+// relabel configs to the Prometheus implementation.
+func ValidateMetricsActions(rcs []*Config) error {
+	for _, rc := range rcs {
+		if rc.Action == SHA256 {
+			return fmt.Errorf("the %s action is only supported for log entries", rc.Action)
+		}
+	}
+	return nil
+}
+
+// Process is like relabel.Process from Prometheus, but it also supports the
+// actions which are only supported for log entries.
+func Process(lbls labels.Labels, cfgs ...*relabel.Config) (ret labels.Labels, keep bool) {
+	lb := labels.NewBuilder(lbls)
+	if !ProcessBuilder(lb, cfgs...) {
+		return labels.EmptyLabels(), false
+	}
+	return lb.Labels(), true
+}
+
+// ProcessBuilder is like relabel.ProcessBuilder from Prometheus, but it also
+// supports the actions which are only supported for log entries.
+func ProcessBuilder(lb *labels.Builder, cfgs ...*relabel.Config) (keep bool) {
+	for _, cfg := range cfgs {
+		if cfg.Action != relabel.Action(SHA256) {
+			if !relabel.ProcessBuilder(lb, cfg) {
+				return false
+			}
+			continue
+		}
+
+		values := make([]string, 0, len(cfg.SourceLabels))
+		for _, ln := range cfg.SourceLabels {
+			values = append(values, lb.Get(string(ln)))
+		}
+		hash := sha256.Sum256([]byte(strings.Join(values, cfg.Separator)))
+		lb.Set(cfg.TargetLabel, hex.EncodeToString(hash[:]))
+	}
+	return true
+}
+
 // Rules returns the relabel configs in use for a relabeling component.
 type Rules []*Config
 
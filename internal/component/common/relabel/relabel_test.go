@@ -3,6 +3,7 @@ package relabel
 import (
 	"testing"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/alloy/syntax"
@@ -46,6 +47,32 @@ func TestParseConfig(t *testing.T) {
 			`,
 			expectErr: true,
 		},
+		{
+			name: "valid sha256 config",
+			cfg: `
+			action = "sha256"
+			target_label = "user_hash"
+			source_labels = ["user"]
+			`,
+		},
+		{
+			name: "missing sha256 target",
+			cfg: `
+			action = "sha256"
+			source_labels = ["user"]
+			`,
+			expectErr: true,
+		},
+		{
+			name: "sha256 with replacement",
+			cfg: `
+			action = "sha256"
+			target_label = "user_hash"
+			source_labels = ["user"]
+			replacement = "foo"
+			`,
+			expectErr: true,
+		},
 		{
 			name: "unknown action",
 			cfg: `
@@ -67,3 +94,41 @@ func TestParseConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestProcess(t *testing.T) {
+	var cfg struct {
+		Rules []*Config `alloy:"rule,block"`
+	}
+	err := syntax.Unmarshal([]byte(`
+	rule {
+		action        = "sha256"
+		source_labels = ["tenant", "user"]
+		target_label  = "user_hash"
+	}
+	rule {
+		action        = "hashmod"
+		source_labels = ["user"]
+		modulus       = 8
+		target_label  = "user_bucket"
+	}
+	rule {
+		action = "labeldrop"
+		regex  = "user"
+	}
+	`), &cfg)
+	require.NoError(t, err)
+
+	processed, keep := Process(labels.FromStrings("tenant", "a", "user", "alice"), ComponentToPromRelabelConfigs(cfg.Rules)...)
+	require.True(t, keep)
+	require.Equal(t, labels.FromStrings(
+		"tenant", "a",
+		// echo -n "a;alice" | sha256sum
+		"user_hash", "d84264b95702c47943f26815476596015b795f9a088d5a15681baa7ccc24523d",
+		"user_bucket", "4",
+	), processed)
+}
+
+func TestValidateMetricsActions(t *testing.T) {
+	require.NoError(t, ValidateMetricsActions([]*Config{{Action: HashMod}}))
+	require.EqualError(t, ValidateMetricsActions([]*Config{{Action: HashMod}, {Action: SHA256}}), "the sha256 action is only supported for log entries")
+}
@@ -34,6 +34,11 @@ type Arguments struct {
 	RelabelConfigs []*alloy_relabel.Config `alloy:"rule,block,optional"`
 }
 
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	return alloy_relabel.ValidateMetricsActions(a.RelabelConfigs)
+}
+
 // Exports holds values which are exported by the discovery.relabel component.
 type Exports struct {
 	Output []discovery.Target  `alloy:"output,attr"`
@@ -28,6 +28,7 @@ func init() {
 type Arguments struct {
 	PathTargets []discovery.Target `alloy:"path_targets,attr"`
 	SyncPeriod  time.Duration      `alloy:"sync_period,attr,optional"`
+	WatchEvents bool               `alloy:"watch_events,attr,optional"`
 }
 
 var _ component.Component = (*Component)(nil)
@@ -40,6 +41,11 @@ type Component struct {
 	args     Arguments
 	watches  []watch
 	watchDog *time.Ticker
+	// trigger requests a sync outside of the sync period.
+	trigger chan struct{}
+	// dirWatcher is only set when WatchEvents is enabled, and is only
+	// accessed from Run.
+	dirWatcher *dirWatcher
 }
 
 // New creates a new local.file_match component.
@@ -50,6 +56,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		args:     args,
 		watches:  make([]watch, 0),
 		watchDog: time.NewTicker(args.SyncPeriod),
+		trigger:  make(chan struct{}, 1),
 	}
 
 	if err := c.Update(args); err != nil {
@@ -84,16 +91,29 @@ func (c *Component) Update(args component.Arguments) error {
 			log:    c.opts.Logger,
 		})
 	}
+	c.requestSync()
 
 	return nil
 }
 
+// requestSync triggers a sync without waiting for the sync period. Requests
+// made while a sync is already pending are coalesced.
+func (c *Component) requestSync() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
 // Run satisfies the component interface.
 func (c *Component) Run(ctx context.Context) error {
 	update := func() {
 		c.mut.Lock()
 		defer c.mut.Unlock()
 
+		// Directories are watched before looking for files, so that files
+		// created in the meantime are not missed.
+		c.syncDirWatcher()
 		paths := c.getWatchedFiles()
 		// The component node checks to see if exports have actually changed.
 		c.opts.OnStateChange(discovery.Exports{Targets: paths})
@@ -101,11 +121,18 @@ func (c *Component) Run(ctx context.Context) error {
 	// Trigger initial check
 	update()
 	defer c.watchDog.Stop()
+	defer func() {
+		if c.dirWatcher != nil {
+			c.dirWatcher.close()
+		}
+	}()
 	for {
 		select {
 		case <-c.watchDog.C:
 			// This triggers a check for any new paths, along with pushing new targets.
 			update()
+		case <-c.trigger:
+			update()
 		case <-ctx.Done():
 			return nil
 		}
@@ -124,3 +151,33 @@ func (c *Component) getWatchedFiles() []discovery.Target {
 	}
 	return paths
 }
+
+// syncDirWatcher starts or stops watching directories for file changes
+// depending on the arguments, and updates the set of watched directories.
+// c.mut must be held.
+func (c *Component) syncDirWatcher() {
+	if !c.args.WatchEvents {
+		if c.dirWatcher != nil {
+			c.dirWatcher.close()
+			c.dirWatcher = nil
+		}
+		return
+	}
+
+	if c.dirWatcher == nil {
+		w, err := newDirWatcher(c.opts.Logger, c.requestSync)
+		if err != nil {
+			level.Error(c.opts.Logger).Log("msg", "failed to watch directories for file changes, files are only discovered every sync period", "err", err)
+			return
+		}
+		c.dirWatcher = w
+	}
+
+	dirs := make(map[string]struct{})
+	for _, w := range c.watches {
+		for dir := range w.getDirs() {
+			dirs[dir] = struct{}{}
+		}
+	}
+	c.dirWatcher.sync(dirs)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -292,3 +293,83 @@ func writeFile(t *testing.T, dir string, name string) {
 	require.NoError(t, err)
 	time.Sleep(20 * time.Millisecond)
 }
+
+func TestWatchEvents(t *testing.T) {
+	dir := path.Join(os.TempDir(), "alloy_testing", "t4")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	var (
+		mut     sync.Mutex
+		targets []discovery.Target
+	)
+	c, err := New(component.Options{
+		ID:       "test",
+		Logger:   util.TestAlloyLogger(t),
+		DataPath: dir,
+		OnStateChange: func(e component.Exports) {
+			mut.Lock()
+			defer mut.Unlock()
+			targets = e.(discovery.Exports).Targets
+		},
+		Registerer: prometheus.NewRegistry(),
+	}, Arguments{
+		PathTargets: []discovery.Target{{"__path__": path.Join(dir, "*", "*.txt")}},
+		// The sync period is long enough for files to only be discovered
+		// through events.
+		SyncPeriod:  time.Hour,
+		WatchEvents: true,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	found := func(name string) func() bool {
+		return func() bool {
+			mut.Lock()
+			defer mut.Unlock()
+			return contains(targets, name)
+		}
+	}
+
+	// Wait for the initial sync before creating files.
+	require.Eventually(t, func() bool {
+		mut.Lock()
+		defer mut.Unlock()
+		return targets != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Files in new directories are discovered too.
+	subdir := path.Join(dir, "app")
+	require.NoError(t, os.MkdirAll(subdir, 0755))
+	writeFile(t, subdir, "t1.txt")
+	require.Eventually(t, found("t1.txt"), 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(path.Join(subdir, "t1.txt")))
+	require.Eventually(t, func() bool { return !found("t1.txt")() }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchDirs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "a", "x"), 0755))
+	require.NoError(t, os.MkdirAll(path.Join(dir, "b"), 0755))
+	writeFile(t, dir, "t1.txt")
+
+	w := watch{
+		target: discovery.Target{"__path__": path.Join(dir, "*", "*", "*.txt")},
+		log:    util.TestAlloyLogger(t),
+	}
+	require.Equal(t, map[string]struct{}{
+		dir:                      {},
+		path.Join(dir, "a"):      {},
+		path.Join(dir, "b"):      {},
+		path.Join(dir, "a", "x"): {},
+	}, w.getDirs())
+
+	w.target["__path__"] = path.Join(dir, "missing", "*.txt")
+	require.Empty(t, w.getDirs())
+}
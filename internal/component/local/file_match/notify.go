@@ -0,0 +1,138 @@
+package file_match
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// dirWatcher watches the directories which can contain files matching the
+// watched patterns, and calls notify whenever an entry is created, removed or
+// renamed in one of them. Writes to files are ignored, so that busy log files
+// don't trigger any work.
+type dirWatcher struct {
+	log     log.Logger
+	watcher *fsnotify.Watcher
+	dirs    map[string]struct{}
+}
+
+func newDirWatcher(logger log.Logger, notify func()) (*dirWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					notify()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been lost, e.g. if the inotify queue overflowed.
+				// Trigger a sync so that nothing is missed until the next sync period.
+				level.Warn(logger).Log("msg", "error watching directories for file changes", "err", err)
+				notify()
+			}
+		}
+	}()
+
+	return &dirWatcher{
+		log:     logger,
+		watcher: watcher,
+		dirs:    make(map[string]struct{}),
+	}, nil
+}
+
+// sync updates the watched directories to dirs.
+func (w *dirWatcher) sync(dirs map[string]struct{}) {
+	for dir := range w.dirs {
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+		// The directory may already be gone, in which case the watch was
+		// removed along with it.
+		_ = w.watcher.Remove(dir)
+		delete(w.dirs, dir)
+	}
+	for dir := range dirs {
+		if _, ok := w.dirs[dir]; ok {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			// The files in this directory are still discovered every sync period.
+			level.Warn(w.log).Log("msg", "failed to watch directory for file changes", "path", dir, "err", err)
+			continue
+		}
+		w.dirs[dir] = struct{}{}
+	}
+}
+
+func (w *dirWatcher) close() {
+	if err := w.watcher.Close(); err != nil {
+		level.Warn(w.log).Log("msg", "failed to close directory watcher", "err", err)
+	}
+}
+
+// getDirs returns the existing directories which can contain files matching
+// the path of the watch. For a path like /var/log/pods/*/*/*.log, these are
+// /var/log/pods, and every directory matching /var/log/pods/* and
+// /var/log/pods/*/*. Intermediate directories need to be watched too, so that
+// new directories are noticed.
+func (w *watch) getDirs() map[string]struct{} {
+	dirs := make(map[string]struct{})
+
+	pattern, err := filepath.Abs(w.getPath())
+	if err != nil {
+		level.Error(w.log).Log("msg", "error getting absolute path", "path", w.getPath(), "err", err)
+		return dirs
+	}
+
+	// Split the directory of the pattern in the static base directory and the
+	// components which contain glob patterns.
+	var (
+		base  = filepath.Dir(pattern)
+		parts []string
+	)
+	for hasMeta(base) {
+		parts = append([]string{filepath.Base(base)}, parts...)
+		base = filepath.Dir(base)
+	}
+
+	addIfDir := func(p string) {
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			dirs[p] = struct{}{}
+		}
+	}
+
+	addIfDir(base)
+	dir := base
+	for _, part := range parts {
+		dir = filepath.Join(dir, part)
+		matches, err := doublestar.Glob(dir)
+		if err != nil {
+			return dirs
+		}
+		for _, m := range matches {
+			addIfDir(m)
+		}
+	}
+	return dirs
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[{")
+}
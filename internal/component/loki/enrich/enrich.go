@@ -0,0 +1,335 @@
+package enrich
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.enrich",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.enrich
+// component.
+type Arguments struct {
+	// The reference data to join log entries against, either from targets or
+	// from a CSV file.
+	Targets         []discovery.Target `alloy:"targets,attr,optional"`
+	CSVFile         string             `alloy:"csv_file,attr,optional"`
+	RefreshInterval time.Duration      `alloy:"refresh_interval,attr,optional"`
+
+	// The label of log entries which is matched against the target label of
+	// the reference data.
+	MatchLabel       string `alloy:"match_label,attr"`
+	TargetMatchLabel string `alloy:"target_match_label,attr,optional"`
+
+	// The labels of the reference data to add to the log entries. All labels
+	// are added if it's empty.
+	LabelsToCopy []string `alloy:"labels_to_copy,attr,optional"`
+
+	// Where the enriched log entries should be forwarded to.
+	ForwardTo []loki.LogsReceiver `alloy:"forward_to,attr"`
+}
+
+// DefaultArguments provides the default arguments for the loki.enrich
+// component.
+var DefaultArguments = Arguments{
+	RefreshInterval: time.Minute,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if (a.Targets != nil) == (a.CSVFile != "") {
+		return errors.New("exactly one of targets or csv_file must be set")
+	}
+	if a.RefreshInterval <= 0 {
+		return errors.New("refresh_interval must be greater than 0")
+	}
+	if !model.LabelName(a.MatchLabel).IsValid() {
+		return fmt.Errorf("invalid match_label %q", a.MatchLabel)
+	}
+	for _, l := range a.LabelsToCopy {
+		if !model.LabelName(l).IsValid() {
+			return fmt.Errorf("invalid label %q in labels_to_copy", l)
+		}
+	}
+	return nil
+}
+
+// targetMatchLabel returns the label of the reference data to match.
+func (a *Arguments) targetMatchLabel() string {
+	if a.TargetMatchLabel != "" {
+		return a.TargetMatchLabel
+	}
+	return a.MatchLabel
+}
+
+// Exports holds values which are exported by the loki.enrich component.
+type Exports struct {
+	Receiver loki.LogsReceiver `alloy:"receiver,attr"`
+}
+
+// Component implements the loki.enrich component.
+type Component struct {
+	opts    component.Options
+	metrics *metrics
+
+	mut      sync.RWMutex
+	args     Arguments
+	index    map[model.LabelValue]model.LabelSet
+	receiver loki.LogsReceiver
+	fanout   []loki.LogsReceiver
+
+	debugDataPublisher livedebugging.DebugDataPublisher
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new loki.enrich component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	debugDataPublisher, err := o.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:               o,
+		metrics:            newMetrics(o.Registerer),
+		receiver:           loki.NewLogsReceiver(),
+		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+	}
+
+	// Call to Update() to build the index once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// Immediately export the receiver which remains the same for the component
+	// lifetime.
+	o.OnStateChange(Exports{Receiver: c.receiver})
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	componentID := livedebugging.ComponentID(c.opts.ID)
+
+	timer := time.NewTimer(c.refreshInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			c.refresh()
+			timer.Reset(c.refreshInterval())
+		case entry := <-c.receiver.Chan():
+			c.mut.RLock()
+			newEntry := c.enrich(entry)
+			fanout := c.fanout
+			c.mut.RUnlock()
+
+			if c.debugDataPublisher.IsActive(componentID) {
+				c.debugDataPublisher.Publish(componentID, fmt.Sprintf("entry: %s, labels: %s => %s", entry.Line, entry.Labels.String(), newEntry.Labels.String()))
+			}
+
+			for _, f := range fanout {
+				select {
+				case <-ctx.Done():
+					return nil
+				case f.Chan() <- newEntry:
+				}
+			}
+		}
+	}
+}
+
+func (c *Component) refreshInterval() time.Duration {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.args.RefreshInterval
+}
+
+// refresh reads the CSV file again. The previous reference data is kept if
+// the file can't be read.
+func (c *Component) refresh() {
+	c.mut.RLock()
+	args := c.args
+	c.mut.RUnlock()
+
+	if args.CSVFile == "" {
+		return
+	}
+
+	targets, err := readCSV(args.CSVFile)
+	if err != nil {
+		level.Warn(c.opts.Logger).Log("msg", "failed to refresh the reference data, keeping the previous one", "file", args.CSVFile, "err", err)
+		c.metrics.refreshErrors.Inc()
+		return
+	}
+	index := buildIndex(targets, args)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	// The arguments may have been updated while the file was read.
+	if c.args.CSVFile == args.CSVFile {
+		c.setIndex(index)
+	}
+}
+
+// enrich adds the labels of the reference data matching the entry to the
+// entry. It must be called with the lock held.
+func (c *Component) enrich(entry loki.Entry) loki.Entry {
+	c.metrics.entriesProcessed.Inc()
+
+	value, ok := entry.Labels[model.LabelName(c.args.MatchLabel)]
+	if !ok {
+		return entry
+	}
+	enrichment, ok := c.index[value]
+	if !ok {
+		return entry
+	}
+
+	c.metrics.entriesEnriched.Inc()
+	labels := entry.Labels.Clone()
+	for name, value := range enrichment {
+		// Labels of the entry aren't overwritten.
+		if _, exists := labels[name]; !exists {
+			labels[name] = value
+		}
+	}
+	entry.Labels = labels
+	return entry
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	targets := newArgs.Targets
+	if newArgs.CSVFile != "" {
+		var err error
+		if targets, err = readCSV(newArgs.CSVFile); err != nil {
+			return err
+		}
+	}
+	index := buildIndex(targets, newArgs)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.fanout = newArgs.ForwardTo
+	c.setIndex(index)
+
+	return nil
+}
+
+// setIndex must be called with the lock held.
+func (c *Component) setIndex(index map[model.LabelValue]model.LabelSet) {
+	c.index = index
+	c.metrics.referenceSize.Set(float64(len(index)))
+}
+
+// buildIndex returns the labels to add to the entries, by the value of their
+// match label. Targets without the target match label are ignored, and the
+// last target wins if several targets have the same value.
+func buildIndex(targets []discovery.Target, args Arguments) map[model.LabelValue]model.LabelSet {
+	matchLabel := args.targetMatchLabel()
+
+	index := make(map[model.LabelValue]model.LabelSet, len(targets))
+	for _, t := range targets {
+		value, ok := t[matchLabel]
+		if !ok || value == "" {
+			continue
+		}
+
+		enrichment := make(model.LabelSet)
+		if len(args.LabelsToCopy) > 0 {
+			for _, name := range args.LabelsToCopy {
+				if v, ok := t[name]; ok && v != "" {
+					enrichment[model.LabelName(name)] = model.LabelValue(v)
+				}
+			}
+		} else {
+			for name, v := range t {
+				if name == matchLabel || strings.HasPrefix(name, model.ReservedLabelPrefix) || v == "" || !model.LabelName(name).IsValid() {
+					continue
+				}
+				enrichment[model.LabelName(name)] = model.LabelValue(v)
+			}
+		}
+		index[model.LabelValue(value)] = enrichment
+	}
+	return index
+}
+
+// readCSV reads the rows of a CSV file as targets. The first row of the file
+// holds the names of the labels.
+func readCSV(path string) ([]discovery.Target, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: missing header row", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var targets []discovery.Target
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return targets, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		target := make(discovery.Target, len(header))
+		for i, name := range header {
+			target[strings.TrimSpace(name)] = record[i]
+		}
+		targets = append(targets, target)
+	}
+}
+
+func (c *Component) LiveDebugging(_ int) {}
@@ -0,0 +1,231 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+	csv_file       = "/etc/alloy/hosts.csv"
+	match_label    = "host"
+	labels_to_copy = ["team"]
+	forward_to     = []
+`), &args)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, args.RefreshInterval)
+	require.Equal(t, "host", args.targetMatchLabel())
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "no reference data",
+			config: `
+	match_label = "host"
+	forward_to  = []`,
+			err: "exactly one of targets or csv_file must be set",
+		},
+		{
+			name: "targets and csv file",
+			config: `
+	targets     = []
+	csv_file    = "/etc/alloy/hosts.csv"
+	match_label = "host"
+	forward_to  = []`,
+			err: "exactly one of targets or csv_file must be set",
+		},
+		{
+			name: "invalid match label",
+			config: `
+	targets     = []
+	match_label = "host-name"
+	forward_to  = []`,
+			err: `invalid match_label "host-name"`,
+		},
+		{
+			name: "invalid label to copy",
+			config: `
+	targets        = []
+	match_label    = "host"
+	labels_to_copy = ["team-name"]
+	forward_to     = []`,
+			err: `invalid label "team-name" in labels_to_copy`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func newTestComponent(t *testing.T, args Arguments) (*Component, loki.LogsReceiver) {
+	ch := loki.NewLogsReceiver()
+	args.ForwardTo = []loki.LogsReceiver{ch}
+	if args.RefreshInterval == 0 {
+		args.RefreshInterval = DefaultArguments.RefreshInterval
+	}
+
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     prometheus.NewRegistry(),
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go c.Run(ctx)
+
+	return c, ch
+}
+
+func send(t *testing.T, c *Component, ch loki.LogsReceiver, labels model.LabelSet) model.LabelSet {
+	t.Helper()
+
+	c.receiver.Chan() <- loki.Entry{Labels: labels, Entry: logproto.Entry{Timestamp: time.Now(), Line: "hello"}}
+	select {
+	case e := <-ch.Chan():
+		return e.Labels
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for log line")
+		return nil
+	}
+}
+
+func TestEnrich_Targets(t *testing.T) {
+	c, ch := newTestComponent(t, Arguments{
+		Targets: []discovery.Target{
+			{"__address__": "10.0.0.1:9100", "__meta_host": "a", "instance": "host-a", "team": "payments", "env": "prod"},
+			{"__address__": "10.0.0.2:9100", "instance": "host-b", "team": "search"},
+			{"__address__": "10.0.0.3:9100"},
+		},
+		MatchLabel:       "host",
+		TargetMatchLabel: "instance",
+	})
+
+	tests := []struct {
+		name   string
+		labels model.LabelSet
+		expect model.LabelSet
+	}{
+		{
+			name:   "match",
+			labels: model.LabelSet{"host": "host-a"},
+			expect: model.LabelSet{"host": "host-a", "team": "payments", "env": "prod"},
+		},
+		{
+			name:   "existing labels aren't overwritten",
+			labels: model.LabelSet{"host": "host-b", "team": "platform"},
+			expect: model.LabelSet{"host": "host-b", "team": "platform"},
+		},
+		{
+			name:   "no match",
+			labels: model.LabelSet{"host": "host-c"},
+			expect: model.LabelSet{"host": "host-c"},
+		},
+		{
+			name:   "no match label",
+			labels: model.LabelSet{"job": "api"},
+			expect: model.LabelSet{"job": "api"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, send(t, c, ch, tc.labels))
+		})
+	}
+}
+
+func TestEnrich_LabelsToCopy(t *testing.T) {
+	c, ch := newTestComponent(t, Arguments{
+		Targets: []discovery.Target{
+			{"host": "host-a", "team": "payments", "env": "prod", "__meta_rack": "r1"},
+		},
+		MatchLabel:   "host",
+		LabelsToCopy: []string{"team", "__meta_rack"},
+	})
+
+	require.Equal(t, model.LabelSet{"host": "host-a", "team": "payments", "__meta_rack": "r1"}, send(t, c, ch, model.LabelSet{"host": "host-a"}))
+}
+
+func TestEnrich_CSVFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.csv")
+	require.NoError(t, os.WriteFile(path, []byte("host,team\nhost-a,payments\n"), 0o644))
+
+	c, ch := newTestComponent(t, Arguments{
+		CSVFile:         path,
+		RefreshInterval: 10 * time.Millisecond,
+		MatchLabel:      "host",
+	})
+	require.Equal(t, model.LabelSet{"host": "host-a", "team": "payments"}, send(t, c, ch, model.LabelSet{"host": "host-a"}))
+
+	// The file is refreshed periodically.
+	require.NoError(t, os.WriteFile(path, []byte("host,team\nhost-a,search\n"), 0o644))
+	require.Eventually(t, func() bool {
+		return send(t, c, ch, model.LabelSet{"host": "host-a"})["team"] == "search"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The previous reference data is kept if the file can't be read.
+	require.NoError(t, os.Remove(path))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, model.LabelSet{"host": "host-a", "team": "search"}, send(t, c, ch, model.LabelSet{"host": "host-a"}))
+}
+
+func TestReadCSV(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "valid.csv")
+	require.NoError(t, os.WriteFile(path, []byte("host, team\nhost-a,payments\nhost-b,\"search, ads\"\n"), 0o644))
+	targets, err := readCSV(path)
+	require.NoError(t, err)
+	require.Equal(t, []discovery.Target{
+		{"host": "host-a", "team": "payments"},
+		{"host": "host-b", "team": "search, ads"},
+	}, targets)
+
+	path = filepath.Join(dir, "empty.csv")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	_, err = readCSV(path)
+	require.ErrorContains(t, err, "missing header row")
+
+	path = filepath.Join(dir, "invalid.csv")
+	require.NoError(t, os.WriteFile(path, []byte("host,team\nhost-a\n"), 0o644))
+	_, err = readCSV(path)
+	require.ErrorContains(t, err, "wrong number of fields")
+}
+
+func getServiceData(name string) (interface{}, error) {
+	switch name {
+	case livedebugging.ServiceName:
+		return livedebugging.NewLiveDebugging(), nil
+	default:
+		return nil, fmt.Errorf("service not found %s", name)
+	}
+}
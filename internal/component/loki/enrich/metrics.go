@@ -0,0 +1,46 @@
+package enrich
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	entriesProcessed prometheus.Counter
+	entriesEnriched  prometheus.Counter
+	referenceSize    prometheus.Gauge
+	refreshErrors    prometheus.Counter
+}
+
+// newMetrics creates a new set of metrics. If reg is non-nil, the metrics
+// will also be registered.
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.entriesProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_enrich_entries_processed_total",
+		Help: "Total number of log entries processed",
+	})
+	m.entriesEnriched = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_enrich_entries_enriched_total",
+		Help: "Total number of log entries which matched the reference data",
+	})
+	m.referenceSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "loki_enrich_reference_size",
+		Help: "Number of values of the match label in the reference data",
+	})
+	m.refreshErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_enrich_refresh_errors_total",
+		Help: "Total number of errors while refreshing the reference data",
+	})
+
+	if reg != nil {
+		reg.MustRegister(
+			m.entriesProcessed,
+			m.entriesEnriched,
+			m.referenceSize,
+			m.refreshErrors,
+		)
+	}
+
+	return &m
+}
@@ -0,0 +1,552 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template/parse"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/loki/process/stages"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+const (
+	// dryRunMaxBodySize is the maximum size of a dry run request body.
+	dryRunMaxBodySize = 1 << 20
+	// dryRunMaxEntries is the maximum number of entries in a dry run request.
+	dryRunMaxEntries = 1000
+)
+
+// dryRunTimeout is the maximum duration of a dry run. It's a variable so that
+// tests can lower it.
+var dryRunTimeout = 10 * time.Second
+
+// errDryRunHostAccess is returned for stages sent in a dry run request which
+// could read the files or the environment of the host, as the endpoint
+// mustn't expose them.
+var errDryRunHostAccess = errors.New("the stages of a dry run request can't access the files or the environment of the host")
+
+// dryRunAllowedStages are the stage types which a dry run request can use.
+// Stages which read files, like stage.geoip, aren't allowed.
+var dryRunAllowedStages = map[string]bool{
+	"aggregate":           true,
+	"auto_parse":          true,
+	"cri":                 true,
+	"csv":                 true,
+	"decode":              true,
+	"decolorize":          true,
+	"dedup":               true,
+	"docker":              true,
+	"drop":                true,
+	"eventlogmessage":     true,
+	"fingerprint":         true,
+	"grok":                true,
+	"json":                true,
+	"key_value":           true,
+	"label_drop":          true,
+	"label_from_path":     true,
+	"label_keep":          true,
+	"labels":              true,
+	"limit":               true,
+	"logfmt":              true,
+	"luhn":                true,
+	"match":               true,
+	"metrics":             true,
+	"multiline":           true,
+	"output":              true,
+	"pack":                true,
+	"redact":              true,
+	"regex":               true,
+	"replace":             true,
+	"sampling":            true,
+	"severity":            true,
+	"static_labels":       true,
+	"structured_metadata": true,
+	"template":            true,
+	"tenant":              true,
+	"timestamp":           true,
+	"truncate":            true,
+}
+
+// dryRunAllowedTemplateFuncs are the template functions which a dry run
+// request can use: the builtin functions of Go templates, the functions added
+// by the stages, and the Sprig functions which neither access the host nor
+// generate large values.
+var dryRunAllowedTemplateFuncs = map[string]bool{
+	// Builtin functions.
+	"and": true, "eq": true, "ge": true, "gt": true, "html": true, "index": true,
+	"js": true, "le": true, "len": true, "lt": true, "ne": true, "not": true,
+	"or": true, "print": true, "printf": true, "println": true, "slice": true,
+	"urlquery": true,
+
+	// Functions added by the stages.
+	"Hash": true, "Replace": true, "Sha2Hash": true, "ToLower": true,
+	"ToUpper": true, "Trim": true, "TrimLeft": true, "TrimPrefix": true,
+	"TrimRight": true, "TrimSpace": true, "TrimSuffix": true, "group": true,
+	"namedGroup": true, "regexReplaceAll": true, "regexReplaceAllLiteral": true,
+
+	// Sprig functions.
+	"abbrev": true, "add": true, "atoi": true, "b64dec": true, "b64enc": true,
+	"camelcase": true, "ceil": true, "coalesce": true, "contains": true,
+	"date": true, "default": true, "dict": true, "div": true, "empty": true,
+	"first": true, "float64": true, "floor": true, "get": true, "has": true,
+	"hasKey": true, "hasPrefix": true, "hasSuffix": true, "int": true,
+	"int64": true, "join": true, "kebabcase": true, "keys": true, "last": true,
+	"list": true, "lower": true, "max": true, "min": true, "mod": true,
+	"mul": true, "now": true, "quote": true, "regexFind": true,
+	"regexFindAll": true, "regexMatch": true, "regexQuoteMeta": true,
+	"regexSplit": true, "replace": true, "round": true, "sha1sum": true,
+	"sha256sum": true, "snakecase": true, "split": true, "splitList": true,
+	"squote": true, "sub": true, "substr": true, "ternary": true, "title": true,
+	"toDate": true, "toJson": true, "toString": true, "trim": true,
+	"trimAll": true, "trimPrefix": true, "trimSuffix": true, "trunc": true,
+	"unixEpoch": true, "upper": true, "urlParse": true,
+}
+
+// dryRunAllowedIdentifiers are the standard library identifiers which the
+// conditions of a dry run request can use. Other standard library
+// identifiers, like sys, file or constants, can read the host.
+var dryRunAllowedIdentifiers = map[string]bool{
+	"array":     true,
+	"coalesce":  true,
+	"convert":   true,
+	"crypto":    true,
+	"encoding":  true,
+	"json_path": true,
+	"map":       true,
+	"net":       true,
+	"string":    true,
+	"time":      true,
+	"url":       true,
+	"version":   true,
+}
+
+// dryRunRequest is the body of a dry run request. If Stages is empty, the
+// stages of the component are used.
+type dryRunRequest struct {
+	Stages  string               `json:"stages,omitempty"`
+	Entries []dryRunRequestEntry `json:"entries"`
+}
+
+type dryRunRequestEntry struct {
+	Line      string            `json:"line"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// dryRunResponse is the body of a dry run response. It holds the entries
+// leaving each stage, and the entries leaving the pipeline.
+type dryRunResponse struct {
+	Stages []dryRunStage `json:"stages"`
+	Output []dryRunEntry `json:"output"`
+}
+
+type dryRunStage struct {
+	Index   int           `json:"index"`
+	Type    string        `json:"type"`
+	Entries []dryRunEntry `json:"entries"`
+}
+
+type dryRunEntry struct {
+	Line               string                 `json:"line"`
+	Timestamp          time.Time              `json:"timestamp"`
+	Labels             map[string]string      `json:"labels"`
+	Extracted          map[string]interface{} `json:"extracted"`
+	StructuredMetadata map[string]string      `json:"structured_metadata"`
+}
+
+func newDryRunEntry(e stages.Entry) dryRunEntry {
+	// Later stages mutate the labels and the extracted values, so they're
+	// copied.
+	out := dryRunEntry{
+		Line:               e.Line,
+		Timestamp:          e.Timestamp,
+		Labels:             make(map[string]string, len(e.Labels)),
+		Extracted:          make(map[string]interface{}, len(e.Extracted)),
+		StructuredMetadata: make(map[string]string, len(e.StructuredMetadata)),
+	}
+	for k, v := range e.Labels {
+		out.Labels[string(k)] = string(v)
+	}
+	for k, v := range e.Extracted {
+		out.Extracted[k] = v
+	}
+	for _, l := range e.StructuredMetadata {
+		out.StructuredMetadata[l.Name] = l.Value
+	}
+	return out
+}
+
+// Handler implements http.Component. It serves the dry run endpoint, which
+// runs sample log entries through a pipeline without forwarding them.
+func (c *Component) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dry-run", c.handleDryRun)
+	return mux
+}
+
+func (c *Component) handleDryRun(w http.ResponseWriter, r *http.Request) {
+	c.mut.RLock()
+	enabled := c.enableDryRun
+	c.mut.RUnlock()
+	if !enabled {
+		http.Error(w, "the dry run endpoint is disabled, set enable_dry_run to true to enable it", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The errors returned to the client are generic, as they could otherwise
+	// quote files read by the stages. The details are logged.
+	var req dryRunRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, dryRunMaxBodySize)).Decode(&req); err != nil {
+		level.Debug(c.opts.Logger).Log("msg", "invalid dry run request", "err", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Entries) > dryRunMaxEntries {
+		http.Error(w, fmt.Sprintf("invalid request: at most %d entries are allowed", dryRunMaxEntries), http.StatusBadRequest)
+		return
+	}
+
+	stageConfigs, err := c.dryRunStages(req.Stages)
+	if errors.Is(err, errDryRunHostAccess) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		level.Debug(c.opts.Logger).Log("msg", "invalid dry run stages", "err", err)
+		http.Error(w, "invalid stages", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dryRunTimeout)
+	defer cancel()
+	resp, err := c.dryRun(ctx, stageConfigs, req.Entries)
+	if ctx.Err() != nil {
+		level.Debug(c.opts.Logger).Log("msg", "dry run timed out or was cancelled", "err", ctx.Err())
+		http.Error(w, "dry run timed out", http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		level.Debug(c.opts.Logger).Log("msg", "invalid dry run stages", "err", err)
+		http.Error(w, "invalid stages", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		level.Debug(c.opts.Logger).Log("msg", "failed to write dry run response", "err", err)
+	}
+}
+
+// dryRunStages parses the stages of a dry run request, or returns the stages
+// of the component if there are none. The stages of a request can't access
+// the host.
+func (c *Component) dryRunStages(text string) ([]stages.StageConfig, error) {
+	if strings.TrimSpace(text) == "" {
+		c.mut.RLock()
+		defer c.mut.RUnlock()
+		return c.stages, nil
+	}
+
+	var cfg struct {
+		Stages []stages.StageConfig `alloy:"stage,enum,optional"`
+	}
+	if err := syntax.Unmarshal([]byte(text), &cfg); err != nil {
+		return nil, err
+	}
+	if err := checkHostAccess(cfg.Stages); err != nil {
+		return nil, err
+	}
+	return cfg.Stages, nil
+}
+
+// checkHostAccess returns an error wrapping errDryRunHostAccess if any of the
+// stages, including the stages nested in stage.match blocks, isn't in
+// dryRunAllowedStages, reads files, or uses templates or conditions which
+// aren't restricted to dryRunAllowedTemplateFuncs and dryRunAllowedIdentifiers.
+func checkHostAccess(cfgs []stages.StageConfig) error {
+	for _, cfg := range cfgs {
+		if name := stageType(cfg); !dryRunAllowedStages[name] {
+			return fmt.Errorf("%w: stage.%s can't be used", errDryRunHostAccess, name)
+		}
+
+		var (
+			templates  []string
+			conditions = []string{stages.StageCondition(cfg)}
+		)
+		switch {
+		case cfg.GrokConfig != nil && len(cfg.GrokConfig.PatternFiles) > 0:
+			return fmt.Errorf("%w: the pattern_files of stage.grok can't be used", errDryRunHostAccess)
+		case cfg.TemplateConfig != nil:
+			templates = append(templates, cfg.TemplateConfig.Template)
+		case cfg.ReplaceConfig != nil:
+			templates = append(templates, cfg.ReplaceConfig.Replace)
+		case cfg.LabelsConfig != nil:
+			templates = appendLabelValues(templates, cfg.LabelsConfig.Values)
+		case cfg.StaticLabelsConfig != nil:
+			templates = appendLabelValues(templates, cfg.StaticLabelsConfig.Values)
+		case cfg.DropConfig != nil:
+			conditions = append(conditions, cfg.DropConfig.Condition)
+		case cfg.MatchConfig != nil:
+			if err := checkHostAccess(cfg.MatchConfig.Stages); err != nil {
+				return err
+			}
+			if cfg.MatchConfig.Else != nil {
+				if err := checkHostAccess(cfg.MatchConfig.Else.Stages); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, text := range templates {
+			if name, ok := templateDeniedFunc(text); ok {
+				return fmt.Errorf("%w: the %s template function can't be used", errDryRunHostAccess, name)
+			}
+		}
+		for _, text := range conditions {
+			if name, ok := conditionDeniedIdentifier(text); ok {
+				return fmt.Errorf("%w: %s can't be used in conditions", errDryRunHostAccess, name)
+			}
+		}
+	}
+	return nil
+}
+
+// stageType returns the block name of the stage set in cfg, such as json or
+// regex.
+func stageType(cfg stages.StageConfig) string {
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsNil() {
+			continue
+		}
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("alloy"), ",")
+		return name
+	}
+	return ""
+}
+
+func appendLabelValues(templates []string, values map[string]*string) []string {
+	for _, v := range values {
+		if v != nil {
+			templates = append(templates, *v)
+		}
+	}
+	return templates
+}
+
+// templateDeniedFunc returns the first function which the template calls and
+// which isn't in dryRunAllowedTemplateFuncs. Templates which can't be parsed are ignored, as
+// the stages using them fail to build.
+func templateDeniedFunc(text string) (string, bool) {
+	t := parse.New("template")
+	t.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return "", false
+	}
+
+	var (
+		denied string
+		walk   func(n parse.Node)
+	)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.IdentifierNode:
+			if !dryRunAllowedTemplateFuncs[n.Ident] && denied == "" {
+				denied = n.Ident
+			}
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+	for _, tree := range trees {
+		walk(tree.Root)
+	}
+	walk(t.Root)
+	return denied, denied != ""
+}
+
+// conditionDeniedIdentifier returns the first standard library identifier
+// which the condition refers to and which isn't in dryRunAllowedIdentifiers.
+// Other identifiers refer to the labels and extracted values of the entry. Conditions which
+// can't be parsed are ignored, as the stages using them fail to build.
+func conditionDeniedIdentifier(text string) (string, bool) {
+	if text == "" {
+		return "", false
+	}
+	expr, err := parser.ParseExpression(text)
+	if err != nil {
+		return "", false
+	}
+	v := &deniedIdentifierVisitor{}
+	ast.Walk(v, expr)
+	return v.denied, v.denied != ""
+}
+
+type deniedIdentifierVisitor struct {
+	denied string
+}
+
+func (v *deniedIdentifierVisitor) Visit(node ast.Node) ast.Visitor {
+	ident, ok := node.(*ast.IdentifierExpr)
+	if !ok || v.denied != "" || dryRunAllowedIdentifiers[ident.Ident.Name] {
+		return v
+	}
+	// Labels and extracted values shadow the standard library, but the
+	// entries of a request can't be trusted to set them.
+	if _, isStdlib := (&vm.Scope{}).Lookup(ident.Ident.Name); isStdlib {
+		v.denied = ident.Ident.Name
+	}
+	return v
+}
+
+// dryRun runs entries through a new pipeline built from the stages, and
+// returns the entries leaving each stage. The pipeline reports its metrics to
+// a registry of its own, so that they don't affect the metrics of the
+// component. If ctx is done before the pipeline has processed all the
+// entries, the remaining entries aren't sent, and dryRun returns the error of
+// ctx once the pipeline has stopped.
+func (c *Component) dryRun(ctx context.Context, stageConfigs []stages.StageConfig, entries []dryRunRequestEntry) (*dryRunResponse, error) {
+	pipeline, err := stages.NewPipeline(c.opts.Logger, dropRateLimited(stageConfigs), &c.opts.ID, prometheus.NewRegistry())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dryRunResponse{
+		Stages: make([]dryRunStage, pipeline.Size()),
+		Output: []dryRunEntry{},
+	}
+	for i, name := range pipeline.StageNames() {
+		resp.Stages[i] = dryRunStage{Index: i + 1, Type: name, Entries: []dryRunEntry{}}
+	}
+
+	var mut sync.Mutex
+	pipeline.SetStageTap(func(index int, _ string, e stages.Entry) {
+		entry := newDryRunEntry(e)
+		mut.Lock()
+		defer mut.Unlock()
+		resp.Stages[index].Entries = append(resp.Stages[index].Entries, entry)
+	})
+
+	in := make(chan stages.Entry)
+	out := pipeline.Run(in)
+	go func() {
+		defer close(in)
+		for _, e := range entries {
+			labels := make(model.LabelSet, len(e.Labels))
+			for k, v := range e.Labels {
+				labels[model.LabelName(k)] = model.LabelValue(v)
+			}
+			timestamp := e.Timestamp
+			if timestamp.IsZero() {
+				timestamp = time.Now()
+			}
+			entry := stages.Entry{
+				Extracted: map[string]interface{}{},
+				Entry: loki.Entry{
+					Labels: labels,
+					Entry:  logproto.Entry{Timestamp: timestamp, Line: e.Line},
+				},
+			}
+			select {
+			case in <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case e, ok := <-out:
+			if !ok {
+				pipeline.Cleanup()
+				return resp, nil
+			}
+			resp.Output = append(resp.Output, newDryRunEntry(e))
+		case <-ctx.Done():
+			// No more entries are sent, and none of the stages wait, so the
+			// pipeline stops once it has processed the entries it already
+			// received.
+			for range out {
+			}
+			pipeline.Cleanup()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// dropRateLimited returns a copy of the stages in which every stage.limit
+// drops the entries exceeding its rate, instead of waiting to send them, so
+// that a dry run can't be held up by its rate limits.
+func dropRateLimited(cfgs []stages.StageConfig) []stages.StageConfig {
+	out := make([]stages.StageConfig, len(cfgs))
+	for i, cfg := range cfgs {
+		switch {
+		case cfg.LimitConfig != nil:
+			limit := *cfg.LimitConfig
+			limit.Drop = true
+			cfg.LimitConfig = &limit
+		case cfg.MatchConfig != nil:
+			match := *cfg.MatchConfig
+			match.Stages = dropRateLimited(match.Stages)
+			if match.Else != nil {
+				match.Else = &stages.MatchElse{Stages: dropRateLimited(match.Else.Stages)}
+			}
+			cfg.MatchConfig = &match
+		}
+		out[i] = cfg
+	}
+	return out
+}
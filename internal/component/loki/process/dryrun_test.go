@@ -0,0 +1,313 @@
+package process
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func newDryRunComponent(t *testing.T, stagesCfg string) *Component {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte("forward_to = []\nenable_dry_run = true\n"+stagesCfg), &args))
+	args.ForwardTo = []loki.LogsReceiver{loki.NewLogsReceiver()}
+
+	registry := prometheus.NewRegistry()
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     registry,
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, args)
+	require.NoError(t, err)
+	// The component isn't run, so its pipeline must be stopped explicitly.
+	t.Cleanup(c.entryHandler.Stop)
+	return c
+}
+
+func dryRunRequestBody(t *testing.T, req dryRunRequest) *strings.Reader {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	return strings.NewReader(string(body))
+}
+
+func TestDryRun(t *testing.T) {
+	c := newDryRunComponent(t, `
+stage.json {
+  expressions = { level = "", msg = "" }
+}
+stage.drop {
+  source = "level"
+  value  = "debug"
+}
+stage.labels {
+  values = { level = "" }
+}
+stage.structured_metadata {
+  values = { msg = "" }
+}`)
+
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+		Entries: []dryRunRequestEntry{
+			{Line: `{"level":"debug","msg":"ignored"}`, Timestamp: ts},
+			{Line: `{"level":"error","msg":"failed"}`, Labels: map[string]string{"app": "api"}, Timestamp: ts},
+		},
+	}))
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp dryRunResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	require.Len(t, resp.Stages, 4)
+	assert.Equal(t, "json", resp.Stages[0].Type)
+	assert.Equal(t, 1, resp.Stages[0].Index)
+	require.Len(t, resp.Stages[0].Entries, 2)
+	assert.Equal(t, map[string]interface{}{"level": "debug", "msg": "ignored"}, resp.Stages[0].Entries[0].Extracted)
+	assert.Empty(t, resp.Stages[0].Entries[1].Labels["level"])
+
+	assert.Equal(t, "drop", resp.Stages[1].Type)
+	require.Len(t, resp.Stages[1].Entries, 1)
+
+	require.Len(t, resp.Stages[2].Entries, 1)
+	assert.Equal(t, map[string]string{"app": "api", "level": "error"}, resp.Stages[2].Entries[0].Labels)
+
+	require.Len(t, resp.Output, 1)
+	out := resp.Output[0]
+	assert.Equal(t, `{"level":"error","msg":"failed"}`, out.Line)
+	assert.True(t, ts.Equal(out.Timestamp))
+	assert.Equal(t, map[string]string{"app": "api", "level": "error"}, out.Labels)
+	assert.Equal(t, map[string]string{"msg": "failed"}, out.StructuredMetadata)
+	assert.Equal(t, map[string]interface{}{"app": "api", "level": "error", "msg": "failed"}, out.Extracted)
+}
+
+func TestDryRun_Stages(t *testing.T) {
+	c := newDryRunComponent(t, "")
+
+	req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+		Stages:  `stage.static_labels { values = { env = "dev" } }`,
+		Entries: []dryRunRequestEntry{{Line: "hello"}},
+	}))
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp dryRunResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Stages, 1)
+	assert.Equal(t, "static_labels", resp.Stages[0].Type)
+	require.Len(t, resp.Output, 1)
+	assert.Equal(t, map[string]string{"env": "dev"}, resp.Output[0].Labels)
+	assert.False(t, resp.Output[0].Timestamp.IsZero())
+}
+
+func TestDryRun_InvalidRequests(t *testing.T) {
+	c := newDryRunComponent(t, "")
+
+	tests := map[string]struct {
+		method string
+		body   string
+		code   int
+	}{
+		"wrong method": {http.MethodGet, "", http.StatusMethodNotAllowed},
+		"invalid json": {http.MethodPost, "{", http.StatusBadRequest},
+		"invalid stages": {
+			http.MethodPost,
+			`{"stages": "stage.unknown {}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"invalid stage config": {
+			http.MethodPost,
+			`{"stages": "stage.drop {}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"grok pattern files": {
+			http.MethodPost,
+			`{"stages": "stage.grok {\n pattern_files = [\"/etc/shadow\"]\n patterns = [\"%{WORD}\"]\n}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"geoip": {
+			http.MethodPost,
+			`{"stages": "stage.geoip {\n source = \"ip\"\n db = \"/etc/shadow\"\n}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"nested geoip": {
+			http.MethodPost,
+			`{"stages": "stage.match {\n selector = \"{app=\\\"a\\\"}\"\n stage.geoip {\n source = \"ip\"\n db = \"/etc/shadow\"\n }\n}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"template env": {
+			http.MethodPost,
+			`{"stages": "stage.template {\n source = \"a\"\n template = \"{{ env \\\"HOME\\\" }}\"\n}", "entries": []}`,
+			http.StatusBadRequest,
+		},
+		"template without env": {
+			http.MethodPost,
+			`{"stages": "stage.template {\n source = \"a\"\n template = \"{{ ToUpper .Value }}\"\n}", "entries": []}`,
+			http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/dry-run", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			c.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestDryRun_HostAccess(t *testing.T) {
+	c := newDryRunComponent(t, "")
+
+	tests := map[string]struct {
+		stages string
+		err    string
+	}{
+		"nested grok pattern files": {
+			stages: `
+stage.match {
+  selector = "{app=\"a\"}"
+  stage.grok {
+    pattern_files = ["/etc/shadow"]
+    patterns      = ["%{WORD:word}"]
+  }
+}`,
+			err: "the pattern_files of stage.grok can't be used",
+		},
+		"replace expandenv": {
+			stages: `
+stage.replace {
+  expression = "(.*)"
+  replace    = "{{ if true }}{{ expandenv \"$HOME\" }}{{ end }}"
+}`,
+			err: "the expandenv template function can't be used",
+		},
+		"label template": {
+			stages: `stage.labels { values = { home = "{{ .Value | env }}" } }`,
+			err:    "the env template function can't be used",
+		},
+		"static label template": {
+			stages: `stage.static_labels { values = { host = "{{ getHostByName \"localhost\" }}" } }`,
+			err:    "the getHostByName template function can't be used",
+		},
+		"nested when": {
+			stages: `
+stage.match {
+  selector = "{app=\"a\"}"
+  stage.output {
+    source = "msg"
+    when   = "sys.env(\"HOME\") == \"/root\""
+  }
+}`,
+			err: "sys can't be used in conditions",
+		},
+		"drop condition": {
+			stages: `stage.drop { condition = "file.exists(\"/etc/shadow\")" }`,
+			err:    "file can't be used in conditions",
+		},
+		"constants": {
+			stages: `stage.drop { condition = "constants.hostname == \"db-1\"" }`,
+			err:    "constants can't be used in conditions",
+		},
+		"geoip": {
+			stages: `
+stage.geoip {
+  db     = "/etc/GeoLite2-City.mmdb"
+  source = "ip"
+}`,
+			err: "stage.geoip can't be used",
+		},
+		"template function outside the allowlist": {
+			stages: `stage.template {
+  source   = "out"
+  template = "{{ repeat 1000000000 \"x\" }}"
+}`,
+			err: "the repeat template function can't be used",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+				Stages:  tt.stages,
+				Entries: []dryRunRequestEntry{{Line: "hello"}},
+			}))
+			rec := httptest.NewRecorder()
+			c.Handler().ServeHTTP(rec, req)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, errDryRunHostAccess.Error()+": "+tt.err+"\n", rec.Body.String())
+		})
+	}
+}
+
+func TestDryRun_GenericErrors(t *testing.T) {
+	c := newDryRunComponent(t, "")
+
+	req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+		Stages:  `stage.regex { expression = "(?P<secret>" }`,
+		Entries: []dryRunRequestEntry{{Line: "hello"}},
+	}))
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid stages\n", rec.Body.String())
+}
+
+func TestDryRun_Timeout(t *testing.T) {
+	defer func(timeout time.Duration) { dryRunTimeout = timeout }(dryRunTimeout)
+	dryRunTimeout = time.Nanosecond
+
+	c := newDryRunComponent(t, "")
+
+	entries := make([]dryRunRequestEntry, dryRunMaxEntries)
+	for i := range entries {
+		entries[i] = dryRunRequestEntry{Line: "a"}
+	}
+	// The limit stage drops the entries exceeding its rate instead of waiting,
+	// so that the pipeline stops quickly.
+	req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+		Stages:  "stage.limit {\n  rate  = 1\n  burst = 1\n}",
+		Entries: entries,
+	}))
+	rec := httptest.NewRecorder()
+	ignore := goleak.IgnoreCurrent()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+
+	// The pipeline of the dry run has stopped when the handler returns.
+	goleak.VerifyNone(t, ignore)
+}
+
+func TestDryRun_Disabled(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte("forward_to = []"), &args))
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     prometheus.NewRegistry(),
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, args)
+	require.NoError(t, err)
+	t.Cleanup(c.entryHandler.Stop)
+
+	req := httptest.NewRequest(http.MethodPost, "/dry-run", dryRunRequestBody(t, dryRunRequest{
+		Entries: []dryRunRequestEntry{{Line: "hello"}},
+	}))
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
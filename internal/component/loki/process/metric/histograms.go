@@ -12,7 +12,9 @@ import (
 
 // DefaultHistogramConfig sets the defaults for a Histogram.
 var DefaultHistogramConfig = HistogramConfig{
-	MaxIdle: 5 * time.Minute,
+	MaxIdle:                         5 * time.Minute,
+	NativeHistogramMaxBucketNumber:  100,
+	NativeHistogramMinResetDuration: 1 * time.Hour,
 }
 
 // HistogramConfig defines a histogram metric whose values are bucketed.
@@ -26,7 +28,10 @@ type HistogramConfig struct {
 	Value       string        `alloy:"value,attr,optional"`
 
 	// Histogram-specific fields
-	Buckets []float64 `alloy:"buckets,attr"`
+	Buckets                         []float64     `alloy:"buckets,attr,optional"`
+	NativeHistogramBucketFactor     float64       `alloy:"native_histogram_bucket_factor,attr,optional"`
+	NativeHistogramMaxBucketNumber  uint32        `alloy:"native_histogram_max_bucket_number,attr,optional"`
+	NativeHistogramMinResetDuration time.Duration `alloy:"native_histogram_min_reset_duration,attr,optional"`
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -40,6 +45,13 @@ func (h *HistogramConfig) Validate() error {
 		return fmt.Errorf("max_idle_duration must be greater or equal than 1s")
 	}
 
+	if h.NativeHistogramBucketFactor != 0 && h.NativeHistogramBucketFactor <= 1 {
+		return fmt.Errorf("native_histogram_bucket_factor must be greater than 1")
+	}
+	if len(h.Buckets) == 0 && h.NativeHistogramBucketFactor == 0 {
+		return fmt.Errorf("at least one of buckets or native_histogram_bucket_factor must be set")
+	}
+
 	if h.Source == "" {
 		h.Source = h.Name
 	}
@@ -61,6 +73,10 @@ func NewHistograms(name string, config *HistogramConfig) (*Histograms, error) {
 				Name:        name,
 				ConstLabels: labels,
 				Buckets:     config.Buckets,
+
+				NativeHistogramBucketFactor:     config.NativeHistogramBucketFactor,
+				NativeHistogramMaxBucketNumber:  config.NativeHistogramMaxBucketNumber,
+				NativeHistogramMinResetDuration: config.NativeHistogramMinResetDuration,
 			}),
 				0,
 			}
@@ -41,3 +41,36 @@ func TestHistogramExpiration(t *testing.T) {
 	assert.NotContains(t, hist.metrics, lbl1.Fingerprint())
 	assert.Contains(t, hist.metrics, lbl2.Fingerprint())
 }
+
+func TestHistogramConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		cfg HistogramConfig
+		err string
+	}{
+		"buckets": {
+			cfg: HistogramConfig{MaxIdle: time.Minute, Buckets: []float64{1, 2}},
+		},
+		"native histogram": {
+			cfg: HistogramConfig{MaxIdle: time.Minute, NativeHistogramBucketFactor: 1.1},
+		},
+		"no buckets": {
+			cfg: HistogramConfig{MaxIdle: time.Minute},
+			err: "at least one of buckets or native_histogram_bucket_factor must be set",
+		},
+		"invalid bucket factor": {
+			cfg: HistogramConfig{MaxIdle: time.Minute, NativeHistogramBucketFactor: 1},
+			err: "native_histogram_bucket_factor must be greater than 1",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
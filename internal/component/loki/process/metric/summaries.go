@@ -0,0 +1,127 @@
+package metric
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultSummaryConfig sets the defaults for a Summary.
+var DefaultSummaryConfig = SummaryConfig{
+	MaxIdle: 5 * time.Minute,
+	MaxAge:  prometheus.DefMaxAge,
+}
+
+// defaultSummaryObjectives are the objectives used when none are configured.
+var defaultSummaryObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+// SummaryConfig defines a summary metric whose values are tracked as
+// quantiles.
+type SummaryConfig struct {
+	// Shared fields
+	Name        string        `alloy:"name,attr"`
+	Description string        `alloy:"description,attr,optional"`
+	Source      string        `alloy:"source,attr,optional"`
+	Prefix      string        `alloy:"prefix,attr,optional"`
+	MaxIdle     time.Duration `alloy:"max_idle_duration,attr,optional"`
+	Value       string        `alloy:"value,attr,optional"`
+
+	// Summary-specific fields
+	Objectives map[string]float64 `alloy:"objectives,attr,optional"`
+	MaxAge     time.Duration      `alloy:"max_age,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (s *SummaryConfig) SetToDefault() {
+	*s = DefaultSummaryConfig
+}
+
+// Validate implements syntax.Validator.
+func (s *SummaryConfig) Validate() error {
+	if s.MaxIdle < 1*time.Second {
+		return fmt.Errorf("max_idle_duration must be greater or equal than 1s")
+	}
+	if s.MaxAge <= 0 {
+		return fmt.Errorf("max_age must be greater than 0")
+	}
+
+	if s.Source == "" {
+		s.Source = s.Name
+	}
+
+	_, err := parseSummaryObjectives(s.Objectives)
+	return err
+}
+
+// parseSummaryObjectives converts objectives keyed by quantile strings into
+// the format used by Prometheus summaries.
+func parseSummaryObjectives(objectives map[string]float64) (map[float64]float64, error) {
+	if len(objectives) == 0 {
+		return defaultSummaryObjectives, nil
+	}
+
+	res := make(map[float64]float64, len(objectives))
+	for quantile, maxErr := range objectives {
+		q, err := strconv.ParseFloat(quantile, 64)
+		if err != nil || q < 0 || q > 1 {
+			return nil, fmt.Errorf("objective quantile %q must be a number between 0 and 1", quantile)
+		}
+		if maxErr < 0 || maxErr > 1 {
+			return nil, fmt.Errorf("objective error for quantile %q must be between 0 and 1", quantile)
+		}
+		res[q] = maxErr
+	}
+	return res, nil
+}
+
+// Summaries is a vector of summaries for a log stream.
+type Summaries struct {
+	*metricVec
+	Cfg *SummaryConfig
+}
+
+// NewSummaries creates a new summary vec.
+func NewSummaries(name string, config *SummaryConfig) (*Summaries, error) {
+	objectives, err := parseSummaryObjectives(config.Objectives)
+	if err != nil {
+		return nil, err
+	}
+	return &Summaries{
+		metricVec: newMetricVec(func(labels map[string]string) prometheus.Metric {
+			return &expiringSummary{prometheus.NewSummary(prometheus.SummaryOpts{
+				Help:        config.Description,
+				Name:        name,
+				ConstLabels: labels,
+				Objectives:  objectives,
+				MaxAge:      config.MaxAge,
+			}),
+				0,
+			}
+		}, int64(config.MaxIdle.Seconds())),
+		Cfg: config,
+	}, nil
+}
+
+// With returns the summary associated with a stream labelset.
+func (s *Summaries) With(labels model.LabelSet) prometheus.Summary {
+	return s.metricVec.With(labels).(prometheus.Summary)
+}
+
+type expiringSummary struct {
+	prometheus.Summary
+	lastModSec int64
+}
+
+// Observe adds a single observation to the summary.
+func (s *expiringSummary) Observe(val float64) {
+	s.Summary.Observe(val)
+	s.lastModSec = time.Now().Unix()
+}
+
+// HasExpired implements Expirable
+func (s *expiringSummary) HasExpired(currentTimeSec int64, maxAgeSec int64) bool {
+	return currentTimeSec-s.lastModSec >= maxAgeSec
+}
@@ -0,0 +1,69 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryExpiration(t *testing.T) {
+	t.Parallel()
+	cfg := &SummaryConfig{
+		Description: "HELP ME!!!!!",
+		MaxIdle:     1 * time.Second,
+	}
+
+	summary, err := NewSummaries("test1", cfg)
+	assert.Nil(t, err)
+
+	// Create a label and observe a value
+	lbl1 := model.LabelSet{}
+	lbl1["test"] = "app"
+	summary.With(lbl1).Observe(23)
+
+	// Collect the metrics, should still find the metric in the map
+	collect(summary)
+	assert.Contains(t, summary.metrics, lbl1.Fingerprint())
+
+	time.Sleep(1100 * time.Millisecond) // Wait just past our max idle of 1 sec
+
+	//Add another summary with new label val
+	lbl2 := model.LabelSet{}
+	lbl2["test"] = "app2"
+	summary.With(lbl2).Observe(2)
+
+	// Collect the metrics, first summary should have expired and removed, second should still be present
+	collect(summary)
+	assert.NotContains(t, summary.metrics, lbl1.Fingerprint())
+	assert.Contains(t, summary.metrics, lbl2.Fingerprint())
+}
+
+func TestSummaryConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		objectives map[string]float64
+		err        string
+	}{
+		"default objectives": {},
+		"valid objectives":   {objectives: map[string]float64{"0.5": 0.05, "1": 0}},
+		"invalid quantile":   {objectives: map[string]float64{"p99": 0.001}, err: `objective quantile "p99" must be a number between 0 and 1`},
+		"quantile too large": {objectives: map[string]float64{"1.5": 0.001}, err: `objective quantile "1.5" must be a number between 0 and 1`},
+		"invalid error":      {objectives: map[string]float64{"0.5": -1}, err: `objective error for quantile "0.5" must be between 0 and 1`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultSummaryConfig
+			cfg.Name = "test"
+			cfg.Objectives = tt.objectives
+			err := cfg.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+				require.Equal(t, "test", cfg.Source)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
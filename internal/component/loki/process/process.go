@@ -7,14 +7,20 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/loki/process/stages"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/internal/util"
 )
 
 // TODO(thampiotr): We should reconsider which parts of this component should be exported and which should
@@ -37,8 +43,100 @@ func init() {
 // Arguments holds values which are used to configure the loki.process
 // component.
 type Arguments struct {
-	ForwardTo []loki.LogsReceiver  `alloy:"forward_to,attr"`
-	Stages    []stages.StageConfig `alloy:"stage,enum,optional"`
+	ForwardTo           []loki.LogsReceiver  `alloy:"forward_to,attr"`
+	Workers             int                  `alloy:"workers,attr,optional"`
+	PreserveStreamOrder bool                 `alloy:"preserve_stream_order,attr,optional"`
+	InstrumentStages    bool                 `alloy:"instrument_stages,attr,optional"`
+	EnableDryRun        bool                 `alloy:"enable_dry_run,attr,optional"`
+	Stages              []stages.StageConfig `alloy:"stage,enum,optional"`
+}
+
+// DefaultArguments holds the default arguments of the loki.process component.
+var DefaultArguments = Arguments{
+	Workers:             1,
+	PreserveStreamOrder: true,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if a.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", a.Workers)
+	}
+	if a.Workers > 1 {
+		if name := findStatefulStage(a.Stages); name != "" {
+			return fmt.Errorf("stage.%s can't be used when workers is greater than 1, as each worker would have its own state", name)
+		}
+		if !a.PreserveStreamOrder {
+			if name := findStreamStatefulStage(a.Stages); name != "" {
+				return fmt.Errorf("stage.%s can't be used when workers is greater than 1 and preserve_stream_order is false, as the lines of a stream would be split between workers", name)
+			}
+		}
+	}
+	return nil
+}
+
+// findStatefulStage returns the name of the first stage, including the stages
+// nested in stage.match, whose state spans several streams or entries. Each
+// worker has its own copy of these stages, so their limits would be
+// multiplied by the number of workers.
+func findStatefulStage(cfgs []stages.StageConfig) string {
+	return findStage(cfgs, func(cfg stages.StageConfig) string {
+		switch {
+		case cfg.AggregateConfig != nil:
+			return "aggregate"
+		case cfg.DedupConfig != nil:
+			return "dedup"
+		case cfg.LimitConfig != nil:
+			return "limit"
+		case cfg.MultilineConfig != nil:
+			return "multiline"
+		}
+		return ""
+	})
+}
+
+// findStreamStatefulStage returns the name of the first stage, including the
+// stages nested in stage.match, whose state spans several lines of a stream:
+// stage.csv reads the header from the first line of each stream, and
+// stage.cri reassembles partial lines. These stages only work when all the
+// lines of a stream go to the same worker.
+func findStreamStatefulStage(cfgs []stages.StageConfig) string {
+	return findStage(cfgs, func(cfg stages.StageConfig) string {
+		switch {
+		case cfg.CSVConfig != nil && cfg.CSVConfig.Header:
+			return "csv"
+		case cfg.CRIConfig != nil:
+			return "cri"
+		}
+		return ""
+	})
+}
+
+// findStage returns the first non-empty name which match returns for the
+// stages, including the stages nested in stage.match.
+func findStage(cfgs []stages.StageConfig, match func(stages.StageConfig) string) string {
+	for _, cfg := range cfgs {
+		if name := match(cfg); name != "" {
+			return name
+		}
+		if cfg.MatchConfig == nil {
+			continue
+		}
+		if name := findStage(cfg.MatchConfig.Stages, match); name != "" {
+			return name
+		}
+		if cfg.MatchConfig.Else != nil {
+			if name := findStage(cfg.MatchConfig.Else.Stages, match); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
 }
 
 // Exports exposes the receiver that can be used to send log entries to
@@ -56,12 +154,18 @@ var (
 type Component struct {
 	opts component.Options
 
-	mut          sync.RWMutex
-	receiver     loki.LogsReceiver
-	processIn    chan<- loki.Entry
-	processOut   chan loki.Entry
-	entryHandler loki.EntryHandler
-	stages       []stages.StageConfig
+	mut                 sync.RWMutex
+	receiver            loki.LogsReceiver
+	processIn           chan<- loki.Entry
+	processOut          chan loki.Entry
+	entryHandler        loki.EntryHandler
+	stages              []stages.StageConfig
+	workers             int
+	stagesRegistry      *prometheus.Registry
+	stagesCollector     *util.UncheckedCollector
+	preserveStreamOrder bool
+	instrumentStages    bool
+	enableDryRun        bool
 
 	fanoutMut sync.RWMutex
 	fanout    []loki.LogsReceiver
@@ -78,8 +182,10 @@ func New(o component.Options, args Arguments) (*Component, error) {
 
 	c := &Component{
 		opts:               o,
+		stagesCollector:    util.NewUncheckedCollector(nil),
 		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
 	}
+	o.Registerer.MustRegister(c.stagesCollector)
 
 	// Create and immediately export the receiver which remains the same for
 	// the component's lifetime.
@@ -133,22 +239,69 @@ func (c *Component) Update(args component.Arguments) error {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
+	c.enableDryRun = newArgs.EnableDryRun
+
+	// A zero number of workers comes from arguments which weren't defaulted.
+	workers := newArgs.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
 	// We want to create a new pipeline if the config changed or if this is the
 	// first load. This will allow a component with no stages to function
 	// properly.
-	if stagesChanged(c.stages, newArgs.Stages) || c.stages == nil {
+	if stagesChanged(c.stages, newArgs.Stages) || c.stages == nil ||
+		workers != c.workers || newArgs.PreserveStreamOrder != c.preserveStreamOrder ||
+		newArgs.InstrumentStages != c.instrumentStages {
+
 		if c.entryHandler != nil {
 			c.entryHandler.Stop()
 		}
 
-		pipeline, err := stages.NewPipeline(c.opts.Logger, newArgs.Stages, &c.opts.ID, c.opts.Registerer)
-		if err != nil {
-			return err
+		// The metrics of the stages only have a worker label when there are
+		// several workers. As the label names of a metric can't change once
+		// it's registered, a new registry is created when the number of
+		// workers changes, and passed to an unchecked collector.
+		if c.stagesRegistry == nil || workers != c.workers {
+			c.stagesRegistry = prometheus.NewRegistry()
+			c.stagesCollector.SetCollector(c.stagesRegistry)
+		}
+
+		handlers := make([]loki.EntryHandler, 0, workers)
+		for i := 0; i < workers; i++ {
+			// Each worker has its own pipeline. Their metrics are
+			// distinguished by a worker label.
+			var registerer prometheus.Registerer = c.stagesRegistry
+			if workers > 1 {
+				registerer = prometheus.WrapRegistererWith(prometheus.Labels{"worker": strconv.Itoa(i)}, registerer)
+			}
+			pipeline, err := stages.NewPipeline(c.opts.Logger, newArgs.Stages, &c.opts.ID, registerer)
+			if err != nil {
+				for _, h := range handlers {
+					h.Stop()
+				}
+				return err
+			}
+			// Observing each stage adds a goroutine and a channel send per
+			// stage, so it's only done when asked for.
+			if newArgs.InstrumentStages {
+				pipeline.EnableStageMetrics(registerer)
+				pipeline.SetStageTap(c.publishStage)
+			}
+			entryHandler := loki.NewEntryHandler(c.processOut, func() { pipeline.Cleanup() })
+			handlers = append(handlers, pipeline.Wrap(entryHandler))
+		}
+
+		if workers == 1 {
+			c.entryHandler = handlers[0]
+		} else {
+			c.entryHandler = newWorkerPool(handlers, newArgs.PreserveStreamOrder)
 		}
-		entryHandler := loki.NewEntryHandler(c.processOut, func() { pipeline.Cleanup() })
-		c.entryHandler = pipeline.Wrap(entryHandler)
 		c.processIn = c.entryHandler.Chan()
 		c.stages = newArgs.Stages
+		c.workers = workers
+		c.preserveStreamOrder = newArgs.PreserveStreamOrder
+		c.instrumentStages = newArgs.InstrumentStages
 	}
 
 	return nil
@@ -162,14 +315,16 @@ func (c *Component) handleIn(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			return
 		case entry := <-c.receiver.Chan():
+			// Publish the entry before sending it to the pipeline, so that it
+			// comes before the entries published by each stage.
+			if c.debugDataPublisher.IsActive(componentID) {
+				c.debugDataPublisher.Publish(componentID, fmt.Sprintf("[IN]: timestamp: %s, entry: %s, labels: %s", entry.Timestamp.Format(time.RFC3339Nano), entry.Line, entry.Labels.String()))
+			}
 			c.mut.RLock()
 			select {
 			case <-ctx.Done():
 				return
 			case c.processIn <- entry.Clone():
-				if c.debugDataPublisher.IsActive(componentID) {
-					c.debugDataPublisher.Publish(componentID, fmt.Sprintf("[IN]: timestamp: %s, entry: %s, labels: %s", entry.Timestamp.Format(time.RFC3339Nano), entry.Line, entry.Labels.String()))
-				}
 				// TODO(@tpaschalis) Instead of calling Clone() at the
 				// component's entrypoint here, we can try a copy-on-write
 				// approach instead, so that the copy only gets made on the
@@ -209,6 +364,36 @@ func (c *Component) handleOut(shutdownCh chan struct{}, wg *sync.WaitGroup) {
 	}
 }
 
+// publishStage sends an entry leaving a stage of the pipeline to the live
+// debugging consumers, along with its extracted values.
+func (c *Component) publishStage(index int, name string, e stages.Entry) {
+	componentID := livedebugging.ComponentID(c.opts.ID)
+	if !c.debugDataPublisher.IsActive(componentID) {
+		return
+	}
+	c.debugDataPublisher.Publish(componentID, fmt.Sprintf("[STAGE %d %s]: timestamp: %s, entry: %s, labels: %s, extracted: %s", index+1, name, e.Timestamp.Format(time.RFC3339Nano), e.Line, e.Labels.String(), formatExtracted(e.Extracted)))
+}
+
+// formatExtracted formats the extracted values of an entry with sorted keys.
+func formatExtracted(extracted map[string]interface{}) string {
+	keys := make([]string, 0, len(extracted))
+	for k := range extracted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s=%q", k, fmt.Sprint(extracted[k]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
 func stagesChanged(prev, next []stages.StageConfig) bool {
 	if len(prev) != len(next) {
 		return true
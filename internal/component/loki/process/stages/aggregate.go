@@ -0,0 +1,341 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-logfmt/logfmt"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/loki/process/metric"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrAggregateStageInvalidWindow    = errors.New("aggregate stage `window` must be greater than 0")
+	ErrAggregateStageInvalidOutput    = errors.New("aggregate stage `output` must be one of \"log\" or \"metrics\"")
+	ErrAggregateStageInvalidMaxGroups = errors.New("aggregate stage `max_groups` must be greater than 0")
+	ErrAggregateStageInvalidLabel     = errors.New("aggregate stage `group_by` must only contain valid label names")
+	ErrAggregateStageEmptySum         = errors.New("aggregate stage `sum` must not contain empty names")
+	ErrAggregateStageInvalidMetric    = errors.New("aggregate stage metric name is invalid")
+	ErrAggregateStageMissingPrefix    = errors.New("aggregate stage `metric_prefix` is required when `output` is \"metrics\"")
+	ErrAggregateStageInvalidMaxIdle   = errors.New("aggregate stage `max_idle_duration` must be greater or equal than 1s")
+)
+
+// Aggregate outputs.
+const (
+	AggregateOutputLog     = "log"
+	AggregateOutputMetrics = "metrics"
+)
+
+var defaultAggregateReason = "aggregate_stage"
+
+// AggregateConfig contains the configuration for an aggregateStage.
+type AggregateConfig struct {
+	Window       time.Duration `alloy:"window,attr,optional"`
+	GroupBy      []string      `alloy:"group_by,attr,optional"`
+	Sum          []string      `alloy:"sum,attr,optional"`
+	Output       string        `alloy:"output,attr,optional"`
+	MetricPrefix string        `alloy:"metric_prefix,attr,optional"`
+	MaxIdle      time.Duration `alloy:"max_idle_duration,attr,optional"`
+	KeepEntries  bool          `alloy:"keep_entries,attr,optional"`
+	MaxGroups    int           `alloy:"max_groups,attr,optional"`
+	DropReason   string        `alloy:"drop_counter_reason,attr,optional"`
+	When         string        `alloy:"when,attr,optional"`
+}
+
+// DefaultAggregateConfig sets the defaults for AggregateConfig.
+var DefaultAggregateConfig = AggregateConfig{
+	Window:     time.Minute,
+	Output:     AggregateOutputLog,
+	MaxIdle:    5 * time.Minute,
+	MaxGroups:  10000,
+	DropReason: defaultAggregateReason,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *AggregateConfig) SetToDefault() {
+	*c = DefaultAggregateConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *AggregateConfig) Validate() error {
+	if c.Window <= 0 {
+		return ErrAggregateStageInvalidWindow
+	}
+	if c.MaxGroups <= 0 {
+		return ErrAggregateStageInvalidMaxGroups
+	}
+	for _, name := range c.GroupBy {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("%w: %q", ErrAggregateStageInvalidLabel, name)
+		}
+	}
+	for _, name := range c.Sum {
+		if name == "" {
+			return ErrAggregateStageEmptySum
+		}
+	}
+	switch c.Output {
+	case AggregateOutputLog:
+	case AggregateOutputMetrics:
+		// Every stage needs its own metric names, as the metrics of stages
+		// grouping by different labels can't share a name.
+		if c.MetricPrefix == "" {
+			return ErrAggregateStageMissingPrefix
+		}
+		if c.MaxIdle < time.Second {
+			return ErrAggregateStageInvalidMaxIdle
+		}
+		for _, name := range c.metricNames() {
+			if !model.IsValidMetricName(model.LabelValue(name)) {
+				return fmt.Errorf("%w: %q", ErrAggregateStageInvalidMetric, name)
+			}
+		}
+	default:
+		return ErrAggregateStageInvalidOutput
+	}
+	return nil
+}
+
+// metricNames returns the name of the lines metric followed by the names of
+// the metrics of each summed value.
+func (c *AggregateConfig) metricNames() []string {
+	names := []string{c.MetricPrefix + "lines_total"}
+	for _, name := range c.Sum {
+		names = append(names, c.MetricPrefix+name+"_total")
+	}
+	return names
+}
+
+// aggregateGroup holds the statistics of a group within the current window.
+type aggregateGroup struct {
+	labels model.LabelSet
+	count  uint64
+	sums   []float64
+}
+
+// aggregateStage counts entries and sums extracted values over tumbling
+// windows, grouped by a set of labels.
+type aggregateStage struct {
+	logger    log.Logger
+	cfg       AggregateConfig
+	dropCount prometheus.Counter
+
+	// lines and sums are only set for the metrics output.
+	lines *metric.Counters
+	sums  []*metric.Counters
+
+	groups      map[string]*aggregateGroup
+	windowStart time.Time
+	now         func() time.Time
+}
+
+// newAggregateStage creates an aggregateStage from config.
+func newAggregateStage(logger log.Logger, cfg AggregateConfig, registerer prometheus.Registerer) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	a := &aggregateStage{
+		logger:    log.With(logger, "component", "stage", "type", "aggregate"),
+		cfg:       cfg,
+		dropCount: getDropCountMetric(registerer).WithLabelValues(cfg.DropReason),
+		groups:    make(map[string]*aggregateGroup),
+		now:       time.Now,
+	}
+	if cfg.Output == AggregateOutputMetrics {
+		names := cfg.metricNames()
+		a.lines = newAggregateCounters(registerer, names[0],
+			"A count of all log lines aggregated by an aggregate stage", cfg.MaxIdle)
+		for i, source := range cfg.Sum {
+			a.sums = append(a.sums, newAggregateCounters(registerer, names[i+1],
+				fmt.Sprintf("The sum of the extracted value %q of all log lines aggregated by an aggregate stage", source), cfg.MaxIdle))
+		}
+	}
+	return a, nil
+}
+
+// newAggregateCounters creates and registers the counters of a metric. The
+// series of a group expire once they're not updated for maxIdle, the same as
+// the counters of the metrics stage.
+func newAggregateCounters(registerer prometheus.Registerer, name, help string, maxIdle time.Duration) *metric.Counters {
+	counters, _ := metric.NewCounters(name, &metric.CounterConfig{
+		Name:        name,
+		Description: help,
+		MaxIdle:     maxIdle,
+	})
+	// It is safe to .MustRegister here because the metric created above is unchecked.
+	registerer.MustRegister(counters)
+	return counters
+}
+
+func (a *aggregateStage) Run(in chan Entry) chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(a.cfg.Window)
+		defer ticker.Stop()
+		a.windowStart = a.now()
+
+		for {
+			select {
+			case <-ticker.C:
+				a.flush(out)
+			case e, ok := <-in:
+				if !ok {
+					a.flush(out)
+					return
+				}
+				if e.skip || !a.aggregate(e) || a.cfg.KeepEntries {
+					out <- e
+					continue
+				}
+				a.dropCount.Inc()
+			}
+		}
+	}()
+	return out
+}
+
+// aggregate adds an entry to the statistics of its group. It returns false if
+// the entry could not be aggregated because max_groups was reached.
+func (a *aggregateStage) aggregate(e Entry) bool {
+	key, labels := a.groupKey(e.Labels)
+	g, ok := a.groups[key]
+	if !ok {
+		if len(a.groups) >= a.cfg.MaxGroups {
+			level.Debug(a.logger).Log("msg", fmt.Sprintf("aggregate stage is tracking the maximum of %d groups, forwarding line without aggregating it", a.cfg.MaxGroups))
+			return false
+		}
+		g = &aggregateGroup{labels: labels, sums: make([]float64, len(a.cfg.Sum))}
+		a.groups[key] = g
+	}
+
+	g.count++
+	for i, source := range a.cfg.Sum {
+		v, ok := e.Extracted[source]
+		if !ok {
+			continue
+		}
+		f, err := getFloat(v)
+		if err != nil {
+			level.Debug(a.logger).Log("msg", "failed to convert extracted value to float", "source", source, "err", err)
+			continue
+		}
+		g.sums[i] += f
+	}
+	return true
+}
+
+// groupKey returns the key and the labels of the group an entry belongs to.
+func (a *aggregateStage) groupKey(labels model.LabelSet) (string, model.LabelSet) {
+	values := make([]string, len(a.cfg.GroupBy))
+	subset := make(model.LabelSet, len(a.cfg.GroupBy))
+	for i, name := range a.cfg.GroupBy {
+		if v, ok := labels[model.LabelName(name)]; ok {
+			values[i] = string(v)
+			subset[model.LabelName(name)] = v
+		}
+	}
+	return strings.Join(values, "\xff"), subset
+}
+
+// flush emits the statistics of the current window and starts a new one.
+func (a *aggregateStage) flush(out chan Entry) {
+	windowEnd := a.now()
+	defer func() {
+		a.groups = make(map[string]*aggregateGroup)
+		a.windowStart = windowEnd
+	}()
+
+	keys := make([]string, 0, len(a.groups))
+	for key := range a.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		g := a.groups[key]
+		if a.cfg.Output == AggregateOutputMetrics {
+			a.recordMetrics(g)
+			continue
+		}
+		e, err := a.summaryEntry(g, windowEnd)
+		if err != nil {
+			level.Warn(a.logger).Log("msg", "failed to build aggregate summary line", "err", err)
+			continue
+		}
+		out <- e
+	}
+}
+
+// recordMetrics adds the statistics of a group to the stage's metrics.
+func (a *aggregateStage) recordMetrics(g *aggregateGroup) {
+	a.lines.With(g.labels).Add(float64(g.count))
+	for i, sum := range a.sums {
+		sum.With(g.labels).Add(g.sums[i])
+	}
+}
+
+// summaryEntry builds a logfmt summary line of the statistics of a group.
+// The statistics are also set in the extracted map of the entry.
+func (a *aggregateStage) summaryEntry(g *aggregateGroup, windowEnd time.Time) (Entry, error) {
+	extracted := map[string]interface{}{
+		"window_start": a.windowStart.Format(time.RFC3339Nano),
+		"window_end":   windowEnd.Format(time.RFC3339Nano),
+		"count":        g.count,
+	}
+	keyvals := []interface{}{
+		"window_start", extracted["window_start"],
+		"window_end", extracted["window_end"],
+		"count", g.count,
+	}
+	for i, source := range a.cfg.Sum {
+		key := source + "_sum"
+		value := strconv.FormatFloat(g.sums[i], 'f', -1, 64)
+		extracted[key] = value
+		keyvals = append(keyvals, key, value)
+	}
+
+	line, err := logfmt.MarshalKeyvals(keyvals...)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		Extracted: extracted,
+		Entry: loki.Entry{
+			Labels: g.labels.Clone(),
+			Entry: logproto.Entry{
+				Timestamp: windowEnd,
+				Line:      string(line),
+			},
+		},
+	}, nil
+}
+
+// Name implements Stage
+func (a *aggregateStage) Name() string {
+	return StageTypeAggregate
+}
+
+// Cleanup implements Stage.
+func (a *aggregateStage) Cleanup() {
+	if a.lines == nil {
+		return
+	}
+	a.lines.DeleteAll()
+	for _, sum := range a.sums {
+		sum.DeleteAll()
+	}
+}
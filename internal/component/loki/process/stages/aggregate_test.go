@@ -0,0 +1,220 @@
+package stages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testAggregateAlloy = `
+stage.logfmt {
+    mapping = { "bytes" = "" }
+}
+stage.aggregate {
+    window        = "1h"
+    group_by      = ["app", "status"]
+    sum           = ["bytes"]
+    output        = "metrics"
+    metric_prefix = "loki_process_custom_aggregate_"
+}
+`
+
+var testAggregateTwoStagesAlloy = `
+stage.aggregate {
+    group_by      = ["app"]
+    output        = "metrics"
+    metric_prefix = "app_"
+    keep_entries  = true
+}
+stage.aggregate {
+    group_by      = ["status"]
+    output        = "metrics"
+    metric_prefix = "status_"
+}
+`
+
+func TestAggregatePipeline_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testAggregateAlloy), nil, registry)
+	require.NoError(t, err)
+
+	ok := model.LabelSet{"app": "web", "status": "200", "pod": "a"}
+	notFound := model.LabelSet{"app": "web", "status": "404", "pod": "b"}
+	out := processEntries(pl,
+		newEntry(nil, ok, "bytes=100", time.Now()),
+		newEntry(nil, ok, "bytes=250", time.Now()),
+		newEntry(nil, notFound, "bytes=12", time.Now()),
+		newEntry(nil, ok, "no bytes", time.Now()),
+	)
+	require.Empty(t, out)
+
+	expected := `
+# HELP loki_process_custom_aggregate_bytes_total The sum of the extracted value "bytes" of all log lines aggregated by an aggregate stage
+# TYPE loki_process_custom_aggregate_bytes_total counter
+loki_process_custom_aggregate_bytes_total{app="web",status="200"} 350
+loki_process_custom_aggregate_bytes_total{app="web",status="404"} 12
+# HELP loki_process_custom_aggregate_lines_total A count of all log lines aggregated by an aggregate stage
+# TYPE loki_process_custom_aggregate_lines_total counter
+loki_process_custom_aggregate_lines_total{app="web",status="200"} 3
+loki_process_custom_aggregate_lines_total{app="web",status="404"} 1
+# HELP loki_process_dropped_lines_total A count of all log lines dropped as a result of a pipeline stage
+# TYPE loki_process_dropped_lines_total counter
+loki_process_dropped_lines_total{reason="aggregate_stage"} 4
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loki_process_custom_aggregate_bytes_total",
+		"loki_process_custom_aggregate_lines_total",
+		"loki_process_dropped_lines_total"))
+}
+
+func TestAggregatePipeline_MetricsTwoStages(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testAggregateTwoStagesAlloy), nil, registry)
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, model.LabelSet{"app": "web", "status": "200"}, "a", time.Now()),
+		newEntry(nil, model.LabelSet{"app": "db", "status": "200"}, "b", time.Now()),
+	)
+	require.Empty(t, out)
+
+	expected := `
+# HELP app_lines_total A count of all log lines aggregated by an aggregate stage
+# TYPE app_lines_total counter
+app_lines_total{app="db"} 1
+app_lines_total{app="web"} 1
+# HELP status_lines_total A count of all log lines aggregated by an aggregate stage
+# TYPE status_lines_total counter
+status_lines_total{status="200"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"app_lines_total", "status_lines_total"))
+}
+
+func TestAggregateStage_Log(t *testing.T) {
+	cfg := DefaultAggregateConfig
+	cfg.GroupBy = []string{"app"}
+	cfg.Sum = []string{"bytes", "duration"}
+	cfg.MaxGroups = 2
+	st, err := newAggregateStage(util.TestAlloyLogger(t), cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	st.(*aggregateStage).now = func() time.Time {
+		defer func() { now = now.Add(time.Minute) }()
+		return now
+	}
+
+	entry := func(app, bytes string) Entry {
+		return newEntry(map[string]interface{}{"bytes": bytes, "duration": 0.5}, model.LabelSet{"app": model.LabelValue(app), "pod": "a"}, "line", time.Now())
+	}
+	out := processEntries(st,
+		entry("api", "10"),
+		entry("web", "20"),
+		entry("api", "x"),
+		entry("db", "40"), // exceeds max_groups
+	)
+
+	require.Len(t, out, 3)
+	assert.Equal(t, "line", out[0].Line)
+	assert.Equal(t, model.LabelValue("db"), out[0].Labels["app"])
+
+	assert.Equal(t, model.LabelSet{"app": "api"}, out[1].Labels)
+	assert.Equal(t, now.Add(-time.Minute), out[1].Timestamp)
+	assert.Equal(t, "window_start=2024-01-01T00:00:00Z window_end=2024-01-01T00:01:00Z count=2 bytes_sum=10 duration_sum=1", out[1].Line)
+	assert.Equal(t, uint64(2), out[1].Extracted["count"])
+	assert.Equal(t, "10", out[1].Extracted["bytes_sum"])
+
+	assert.Equal(t, model.LabelSet{"app": "web"}, out[2].Labels)
+	assert.Equal(t, "window_start=2024-01-01T00:00:00Z window_end=2024-01-01T00:01:00Z count=1 bytes_sum=20 duration_sum=0.5", out[2].Line)
+}
+
+func TestAggregateStage_KeepEntries(t *testing.T) {
+	cfg := DefaultAggregateConfig
+	cfg.KeepEntries = true
+	st, err := newAggregateStage(util.TestAlloyLogger(t), cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(st,
+		newEntry(nil, model.LabelSet{"app": "a"}, "first", time.Now()),
+		newEntry(nil, model.LabelSet{"app": "b"}, "second", time.Now()),
+	)
+	require.Len(t, out, 3)
+	assert.Equal(t, "first", out[0].Line)
+	assert.Equal(t, "second", out[1].Line)
+	assert.Empty(t, out[2].Labels)
+	assert.Contains(t, out[2].Line, "count=2")
+}
+
+func TestAggregateConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config func(*AggregateConfig)
+		err    error
+	}{
+		"defaults": {
+			func(*AggregateConfig) {},
+			nil,
+		},
+		"invalid window": {
+			func(c *AggregateConfig) { c.Window = 0 },
+			ErrAggregateStageInvalidWindow,
+		},
+		"invalid max groups": {
+			func(c *AggregateConfig) { c.MaxGroups = 0 },
+			ErrAggregateStageInvalidMaxGroups,
+		},
+		"invalid label": {
+			func(c *AggregateConfig) { c.GroupBy = []string{"app-name"} },
+			ErrAggregateStageInvalidLabel,
+		},
+		"empty sum": {
+			func(c *AggregateConfig) { c.Sum = []string{""} },
+			ErrAggregateStageEmptySum,
+		},
+		"invalid output": {
+			func(c *AggregateConfig) { c.Output = "stdout" },
+			ErrAggregateStageInvalidOutput,
+		},
+		"invalid metric name": {
+			func(c *AggregateConfig) {
+				c.Output = AggregateOutputMetrics
+				c.MetricPrefix = "access_"
+				c.Sum = []string{"response.bytes"}
+			},
+			ErrAggregateStageInvalidMetric,
+		},
+		"missing metric prefix": {
+			func(c *AggregateConfig) { c.Output = AggregateOutputMetrics },
+			ErrAggregateStageMissingPrefix,
+		},
+		"invalid max idle duration": {
+			func(c *AggregateConfig) {
+				c.Output = AggregateOutputMetrics
+				c.MetricPrefix = "access_"
+				c.MaxIdle = time.Millisecond
+			},
+			ErrAggregateStageInvalidMaxIdle,
+		},
+		"invalid sum name is fine for log output": {
+			func(c *AggregateConfig) { c.Sum = []string{"response.bytes"} },
+			nil,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultAggregateConfig
+			tt.config(&cfg)
+			require.ErrorIs(t, cfg.Validate(), tt.err)
+		})
+	}
+}
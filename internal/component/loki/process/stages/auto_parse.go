@@ -0,0 +1,160 @@
+package stages
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-logfmt/logfmt"
+	json "github.com/json-iterator/go"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Formats detected by the auto_parse stage.
+const (
+	AutoParseFormatJSON   = "json"
+	AutoParseFormatLogfmt = "logfmt"
+	AutoParseFormatPlain  = "plain"
+)
+
+// Configuration errors.
+var (
+	ErrEmptyAutoParseStageSource = errors.New("empty source")
+	ErrAutoParseFormatKeyEmpty   = errors.New("auto_parse stage `format_key` must not be empty")
+)
+
+// AutoParseConfig configures a processing stage that detects the format of
+// log lines and extracts their fields.
+type AutoParseConfig struct {
+	Source    *string `alloy:"source,attr,optional"`
+	FormatKey string  `alloy:"format_key,attr,optional"`
+	When      string  `alloy:"when,attr,optional"`
+}
+
+// DefaultAutoParseConfig sets the defaults for AutoParseConfig.
+var DefaultAutoParseConfig = AutoParseConfig{
+	FormatKey: "format",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *AutoParseConfig) SetToDefault() {
+	*c = DefaultAutoParseConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *AutoParseConfig) Validate() error {
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyAutoParseStageSource
+	}
+	if c.FormatKey == "" {
+		return ErrAutoParseFormatKeyEmpty
+	}
+	return nil
+}
+
+// autoParseStage extracts the fields of JSON and logfmt log lines.
+type autoParseStage struct {
+	cfg    AutoParseConfig
+	json   *jsonStage
+	logger log.Logger
+}
+
+// newAutoParseStage creates a new auto_parse pipeline stage from a config.
+func newAutoParseStage(logger log.Logger, cfg AutoParseConfig) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	logger = log.With(logger, "component", "stage", "type", "auto_parse")
+	return &autoParseStage{
+		cfg: cfg,
+		// JSON fields are extracted the same way as by a json stage which only
+		// flattens the top-level object.
+		json: &jsonStage{
+			cfg:    &JSONConfig{Flatten: true, FlattenSeparator: defaultFlattenSeparator, FlattenMaxDepth: 1},
+			logger: logger,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Run implements Stage.
+func (a *autoParseStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		input := e.Line
+		if a.cfg.Source != nil {
+			value, ok := e.Extracted[*a.cfg.Source]
+			if !ok {
+				if Debug {
+					level.Debug(a.logger).Log("msg", "source does not exist in the set of extracted values", "source", *a.cfg.Source)
+				}
+				return e
+			}
+			str, err := getString(value)
+			if err != nil {
+				if Debug {
+					level.Debug(a.logger).Log("msg", "failed to convert source value to string", "source", *a.cfg.Source, "err", err, "type", reflect.TypeOf(value))
+				}
+				return e
+			}
+			input = str
+		}
+
+		e.Extracted[a.cfg.FormatKey] = a.parse(e.Extracted, strings.TrimSpace(input))
+		return e
+	})
+}
+
+// parse extracts the fields of the input and returns its detected format.
+func (a *autoParseStage) parse(extracted map[string]interface{}, input string) string {
+	if strings.HasPrefix(input, "{") {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(input), &data); err == nil {
+			a.json.flatten(extracted, "", data, 1)
+			return AutoParseFormatJSON
+		}
+	}
+
+	if fields, ok := parseLogfmtFields(input); ok {
+		for k, v := range fields {
+			extracted[k] = v
+		}
+		return AutoParseFormatLogfmt
+	}
+
+	return AutoParseFormatPlain
+}
+
+// parseLogfmtFields returns the key=value pairs of a logfmt input. The input
+// is only considered to be logfmt if at least half of its fields have a
+// value, so that plain text isn't detected as a list of keys.
+func parseLogfmtFields(input string) (map[string]string, bool) {
+	var (
+		fields = make(map[string]string)
+		total  int
+	)
+	decoder := logfmt.NewDecoder(strings.NewReader(input))
+	for decoder.ScanRecord() {
+		for decoder.ScanKeyval() {
+			total++
+			if decoder.Value() != nil {
+				fields[string(decoder.Key())] = string(decoder.Value())
+			}
+		}
+	}
+	if decoder.Err() != nil || len(fields) == 0 || len(fields)*2 < total {
+		return nil, false
+	}
+	return fields, true
+}
+
+// Name implements Stage.
+func (a *autoParseStage) Name() string {
+	return StageTypeAutoParse
+}
+
+// Cleanup implements Stage.
+func (*autoParseStage) Cleanup() {
+	// no-op
+}
@@ -0,0 +1,119 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testAutoParseAlloy = `
+stage.auto_parse {}
+stage.labels {
+    values = { level = "", format = "" }
+}
+`
+
+func TestAutoParsePipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testAutoParseAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, nil, `{"level":"error","msg":"failed","user":{"id":1}}`, time.Now()),
+		newEntry(nil, nil, `level=info msg="request done" status=200`, time.Now()),
+		newEntry(nil, nil, `Started GET "/" for 127.0.0.1`, time.Now()),
+	)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, "json", string(out[0].Labels["format"]))
+	assert.Equal(t, "error", string(out[0].Labels["level"]))
+	assert.Equal(t, `{"id":1}`, out[0].Extracted["user"])
+
+	assert.Equal(t, "logfmt", string(out[1].Labels["format"]))
+	assert.Equal(t, "info", string(out[1].Labels["level"]))
+	assert.Equal(t, "request done", out[1].Extracted["msg"])
+	assert.Equal(t, "200", out[1].Extracted["status"])
+
+	assert.Equal(t, "plain", string(out[2].Labels["format"]))
+	assert.NotContains(t, out[2].Labels, model.LabelName("level"))
+}
+
+func TestAutoParseStage(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		format   string
+		expected map[string]interface{}
+	}{
+		"json": {
+			`  {"level":"warn","count":3,"ok":true,"tags":["a"]}`,
+			AutoParseFormatJSON,
+			map[string]interface{}{"level": "warn", "count": float64(3), "ok": true, "tags": `["a"]`},
+		},
+		"json array is not an object": {
+			`["a"]`,
+			AutoParseFormatPlain,
+			map[string]interface{}{},
+		},
+		"malformed json falls back to logfmt": {
+			`{broken level=info`,
+			AutoParseFormatLogfmt,
+			map[string]interface{}{"level": "info"},
+		},
+		"logfmt with bare keys": {
+			`level=debug verbose msg=x`,
+			AutoParseFormatLogfmt,
+			map[string]interface{}{"level": "debug", "msg": "x"},
+		},
+		"plain text with a key=value pair": {
+			`connection closed by peer code=1`,
+			AutoParseFormatPlain,
+			map[string]interface{}{},
+		},
+		"empty": {
+			``,
+			AutoParseFormatPlain,
+			map[string]interface{}{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			st, err := newAutoParseStage(util.TestAlloyLogger(t), DefaultAutoParseConfig)
+			require.NoError(t, err)
+
+			out := processEntries(st, newEntry(nil, nil, tt.input, time.Now()))[0]
+			tt.expected["format"] = tt.format
+			assert.Equal(t, tt.expected, out.Extracted)
+		})
+	}
+}
+
+func TestAutoParseStage_Source(t *testing.T) {
+	source := "message"
+	cfg := AutoParseConfig{Source: &source, FormatKey: "message_format"}
+	st, err := newAutoParseStage(util.TestAlloyLogger(t), cfg)
+	require.NoError(t, err)
+
+	out := processEntries(st, newEntry(map[string]interface{}{"message": "a=1 b=2"}, nil, `{"x":1}`, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{"message": "a=1 b=2", "a": "1", "b": "2", "message_format": "logfmt"}, out.Extracted)
+
+	out = processEntries(st, newEntry(map[string]interface{}{}, nil, `{"x":1}`, time.Now()))[0]
+	assert.Empty(t, out.Extracted)
+}
+
+func TestAutoParseConfig_Validate(t *testing.T) {
+	empty := ""
+	cfg := AutoParseConfig{Source: &empty, FormatKey: "format"}
+	require.ErrorIs(t, cfg.Validate(), ErrEmptyAutoParseStageSource)
+
+	cfg = AutoParseConfig{}
+	require.ErrorIs(t, cfg.Validate(), ErrAutoParseFormatKeyEmpty)
+
+	cfg = DefaultAutoParseConfig
+	require.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,205 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/ast"
+	syntaxparser "github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/token"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+// Configuration errors.
+var (
+	ErrStageConditionInvalid = errors.New("could not parse `when` condition")
+)
+
+// StageCondition returns the `when` attribute of the stage set in cfg.
+func StageCondition(cfg StageConfig) string {
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		if when := field.Elem().FieldByName("When"); when.IsValid() {
+			return when.String()
+		}
+	}
+	return ""
+}
+
+// condition is an expression evaluated against the labels and the extracted
+// values of an entry, like the `when` attribute of stages and the `condition`
+// attribute of the drop stage.
+type condition struct {
+	eval    *vm.Evaluator
+	numeric map[string]struct{}
+	logger  log.Logger
+	errors  prometheus.Counter
+}
+
+// newCondition parses a condition of a stage. The failures to evaluate it are
+// counted with the name of the stage.
+func newCondition(logger log.Logger, registerer prometheus.Registerer, stage string, expr string) (*condition, error) {
+	parsed, err := syntaxparser.ParseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	counter := registerCounterVec(registerer, "loki_process", "condition_errors_total",
+		"A count of the log lines for which a stage condition failed to evaluate.", []string{"stage"})
+	numeric := make(map[string]struct{})
+	ast.Walk(&numericVisitor{names: numeric}, parsed)
+	return &condition{
+		eval:    vm.New(parsed),
+		numeric: numeric,
+		logger:  logger,
+		errors:  counter.WithLabelValues(stage),
+	}, nil
+}
+
+// numericVisitor collects the names which a condition compares with a number
+// literal, for example status in `status >= 500`.
+type numericVisitor struct {
+	names map[string]struct{}
+}
+
+func (v *numericVisitor) Visit(node ast.Node) ast.Visitor {
+	expr, ok := node.(*ast.BinaryExpr)
+	if !ok {
+		return v
+	}
+	switch expr.Kind {
+	case token.EQ, token.NEQ, token.LT, token.LTE, token.GT, token.GTE:
+		if name, ok := identifierName(expr.Left); ok && isNumberLiteral(expr.Right) {
+			v.names[name] = struct{}{}
+		}
+		if name, ok := identifierName(expr.Right); ok && isNumberLiteral(expr.Left) {
+			v.names[name] = struct{}{}
+		}
+	}
+	return v
+}
+
+func identifierName(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.IdentifierExpr:
+		return expr.Ident.Name, true
+	case *ast.ParenExpr:
+		return identifierName(expr.Inner)
+	}
+	return "", false
+}
+
+func isNumberLiteral(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.LiteralExpr:
+		return expr.Kind == token.NUMBER || expr.Kind == token.FLOAT
+	case *ast.UnaryExpr:
+		return expr.Kind == token.SUB && isNumberLiteral(expr.Value)
+	case *ast.ParenExpr:
+		return isNumberLiteral(expr.Inner)
+	}
+	return false
+}
+
+// matches evaluates the condition against the labels and extracted values of
+// an entry. The condition is false if it fails to evaluate, for example because
+// it refers to a missing value, in which case a warning is logged.
+func (c *condition) matches(e Entry) bool {
+	var match bool
+	if err := c.eval.Evaluate(c.scope(e), &match); err != nil {
+		level.Warn(c.logger).Log("msg", "failed to evaluate condition, treating it as false", "err", err)
+		c.errors.Inc()
+		return false
+	}
+	return match
+}
+
+// scope returns the variables the condition can refer to: the labels and the
+// extracted values of the entry, the latter taking precedence. The values which
+// the condition compares with a number literal are exposed as numbers when they
+// can be parsed as numbers, other values are kept as strings.
+func (c *condition) scope(e Entry) *vm.Scope {
+	variables := make(map[string]interface{}, len(e.Labels)+len(e.Extracted))
+	for name, value := range e.Labels {
+		variables[string(name)] = c.value(string(name), string(value))
+	}
+	for name, value := range e.Extracted {
+		if s, ok := value.(string); ok {
+			variables[name] = c.value(name, s)
+		} else {
+			variables[name] = value
+		}
+	}
+	return &vm.Scope{Variables: variables}
+}
+
+func (c *condition) value(name, s string) interface{} {
+	if _, ok := c.numeric[name]; !ok {
+		return s
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return s
+	}
+	return f
+}
+
+// conditionalStage only runs its inner stage on the entries for which its
+// condition evaluates to true. Other entries are marked as skipped and still
+// sent through the inner stage, which forwards them unchanged, so that all
+// entries keep their order.
+type conditionalStage struct {
+	Stage
+
+	condition *condition
+}
+
+// newConditionalStage wraps a stage so that it only runs when the `when`
+// condition evaluates to true.
+func newConditionalStage(logger log.Logger, stage Stage, when string, registerer prometheus.Registerer) (Stage, error) {
+	logger = log.With(logger, "component", "stage", "type", stage.Name())
+	cond, err := newCondition(logger, registerer, stage.Name(), when)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrStageConditionInvalid, err)
+	}
+	return &conditionalStage{
+		Stage:     stage,
+		condition: cond,
+	}, nil
+}
+
+// Run implements Stage
+func (c *conditionalStage) Run(in chan Entry) chan Entry {
+	next := make(chan Entry)
+	out := make(chan Entry)
+	outNext := c.Stage.Run(next)
+	go func() {
+		defer close(out)
+		for e := range outNext {
+			e.skip = false
+			out <- e
+		}
+	}()
+	go func() {
+		defer close(next)
+		for e := range in {
+			e.skip = !c.condition.matches(e)
+			next <- e
+		}
+	}()
+	return out
+}
+
+func (c *conditionalStage) setErrorCounter(counter prometheus.Counter) bool {
+	return setErrorCounter(c.Stage, counter)
+}
@@ -0,0 +1,151 @@
+package stages
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testConditionAlloy = `
+stage.json {
+    expressions = { level = "", msg = "" }
+}
+stage.replace {
+    when       = "level == \"error\""
+    source     = "msg"
+    expression = "(secret)"
+    replace    = "***"
+}
+stage.static_labels {
+    when   = "team == \"a\""
+    values = { routed = "true" }
+}
+stage.output {
+    when   = "status >= 500"
+    source = "msg"
+}
+`
+
+func TestConditionPipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testConditionAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, model.LabelSet{"team": "a"}, `{"level":"error","msg":"secret"}`, time.Now()),
+	)
+	require.Len(t, out, 1)
+	assert.Equal(t, "***", out[0].Extracted["msg"])
+	assert.Equal(t, model.LabelValue("true"), out[0].Labels["routed"])
+
+	out = processEntries(pl,
+		newEntry(nil, nil, `{"level":"info","msg":"secret"}`, time.Now()),
+	)
+	require.Len(t, out, 1)
+	assert.Equal(t, "secret", out[0].Extracted["msg"])
+	assert.NotContains(t, out[0].Labels, model.LabelName("routed"))
+}
+
+var testConditionOrderAlloy = `
+stage.static_labels {
+    when   = "parity == \"even\""
+    values = { seen = "true" }
+}
+stage.drop {
+    when       = "parity == \"odd\""
+    expression = "drop"
+}
+`
+
+func TestConditionPipeline_Order(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testConditionOrderAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	var entries []Entry
+	for i := 0; i < 1000; i++ {
+		parity := "even"
+		if i%2 == 1 {
+			parity = "odd"
+		}
+		line := strconv.Itoa(i)
+		if i%10 == 9 {
+			line = "drop"
+		}
+		entries = append(entries, newEntry(nil, model.LabelSet{"parity": model.LabelValue(parity)}, line, time.Now()))
+	}
+
+	out := processEntries(pl, entries...)
+	require.Len(t, out, 900)
+	i := 0
+	for _, e := range out {
+		if i%10 == 9 {
+			i++
+		}
+		require.Equal(t, strconv.Itoa(i), e.Line)
+		require.False(t, e.skip)
+		if i%2 == 0 {
+			require.Equal(t, model.LabelValue("true"), e.Labels["seen"])
+		} else {
+			require.NotContains(t, e.Labels, model.LabelName("seen"))
+		}
+		i++
+	}
+}
+
+func TestConditionalStage_Matches(t *testing.T) {
+	tests := map[string]struct {
+		when     string
+		expected bool
+		errors   float64
+	}{
+		"true":                          {`level == "error"`, true, 0},
+		"false":                         {`level == "info"`, false, 0},
+		"label":                         {`app == "api"`, true, 0},
+		"extracted over label":          {`env == "prod"`, true, 0},
+		"number":                        {`status >= 500 && status < 600`, true, 0},
+		"number equality":               {`status == 503`, true, 0},
+		"number and string":             {`status >= 500 && env != "dev"`, true, 0},
+		"number on the left":            {`-1 < (status)`, true, 0},
+		"200 as a string":               {`code == "200"`, true, 0},
+		"200 as a number":               {`code == 200`, true, 0},
+		"number as a string":            {`status == "503"`, true, 0},
+		"long number as a string":       {`id == "1234567890123456789"`, true, 0},
+		"long number":                   {`id == 1234567890123456789`, true, 0},
+		"other long number as a string": {`id == "1234567890123456788"`, false, 0},
+		"missing":                       {`missing == "x"`, false, 1},
+		"not a boolean":                 {`level`, false, 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			inner, err := newDecolorizeStage(DecolorizeConfig{})
+			require.NoError(t, err)
+			st, err := newConditionalStage(util.TestAlloyLogger(t), inner, tt.when, prometheus.NewRegistry())
+			require.NoError(t, err)
+			e := newEntry(
+				map[string]interface{}{"level": "error", "env": "prod", "status": "503", "code": "200", "id": "1234567890123456789"},
+				model.LabelSet{"app": "api", "env": "dev"},
+				"request timeout",
+				time.Now(),
+			)
+			cond := st.(*conditionalStage).condition
+			assert.Equal(t, tt.expected, cond.matches(e))
+			assert.Equal(t, tt.errors, testutil.ToFloat64(cond.errors))
+		})
+	}
+}
+
+func TestConditionalStage_Invalid(t *testing.T) {
+	_, err := New(util.TestAlloyLogger(t), nil, StageConfig{
+		OutputConfig: &OutputConfig{Source: "msg", When: "level =="},
+	}, prometheus.NewRegistry())
+	require.ErrorContains(t, err, ErrStageConditionInvalid.Error())
+}
@@ -0,0 +1,295 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrCSVColumnsRequired    = errors.New("csv stage requires either `columns` or `header` to be set")
+	ErrCSVNegativeMaxIdle    = errors.New("csv stage `max_idle_time` must not be negative")
+	ErrCSVInvalidDelimiter   = errors.New("csv stage `delimiter` must be a single character")
+	ErrCSVInvalidQuote       = errors.New("csv stage `quote` must be a single character")
+	ErrCSVDelimiterIsQuote   = errors.New("csv stage `delimiter` and `quote` must be different characters")
+	ErrEmptyCSVStageSource   = errors.New("empty source")
+	errCSVUnterminatedQuote  = errors.New("unterminated quoted field")
+	errCSVUnexpectedAfterEnd = errors.New("unexpected character after closing quote")
+)
+
+// CSVConfig configures a processing stage that splits delimited log lines
+// into columns and stores them in the extracted values map.
+type CSVConfig struct {
+	Columns   []string `alloy:"columns,attr,optional"`
+	Header    bool     `alloy:"header,attr,optional"`
+	Delimiter string   `alloy:"delimiter,attr,optional"`
+	Quote     string   `alloy:"quote,attr,optional"`
+	Lenient   bool     `alloy:"lenient,attr,optional"`
+	TrimSpace bool     `alloy:"trim_space,attr,optional"`
+	Source    *string  `alloy:"source,attr,optional"`
+	When      string   `alloy:"when,attr,optional"`
+
+	MaxIdleTime time.Duration `alloy:"max_idle_time,attr,optional"`
+}
+
+// DefaultCSVConfig sets the defaults for CSVConfig.
+var DefaultCSVConfig = CSVConfig{
+	Delimiter:   ",",
+	Quote:       `"`,
+	MaxIdleTime: 24 * time.Hour,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *CSVConfig) SetToDefault() {
+	*c = DefaultCSVConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *CSVConfig) Validate() error {
+	if len(c.Columns) == 0 && !c.Header {
+		return ErrCSVColumnsRequired
+	}
+	if c.MaxIdleTime < 0 {
+		return ErrCSVNegativeMaxIdle
+	}
+	if utf8.RuneCountInString(c.Delimiter) != 1 {
+		return ErrCSVInvalidDelimiter
+	}
+	if utf8.RuneCountInString(c.Quote) != 1 {
+		return ErrCSVInvalidQuote
+	}
+	if c.Delimiter == c.Quote {
+		return ErrCSVDelimiterIsQuote
+	}
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyCSVStageSource
+	}
+	return nil
+}
+
+// csvStage sets extracted data by splitting the input into columns.
+type csvStage struct {
+	stageErrors
+
+	config    *CSVConfig
+	delimiter rune
+	quote     rune
+	logger    log.Logger
+
+	// headers holds the column names read from the header line of each
+	// stream when the header option is enabled.
+	headers   map[model.Fingerprint]*csvHeader
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// csvHeader holds the column names of a stream.
+type csvHeader struct {
+	columns  []string
+	lastSeen time.Time
+}
+
+// newCSVStage creates a new csv pipeline stage from a config.
+func newCSVStage(logger log.Logger, config CSVConfig) (Stage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	delimiter, _ := utf8.DecodeRuneInString(config.Delimiter)
+	quote, _ := utf8.DecodeRuneInString(config.Quote)
+	return toStage(&csvStage{
+		config:    &config,
+		delimiter: delimiter,
+		quote:     quote,
+		logger:    log.With(logger, "component", "stage", "type", "csv"),
+		headers:   make(map[model.Fingerprint]*csvHeader),
+		now:       time.Now,
+	}), nil
+}
+
+// Process implements Stage
+func (c *csvStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the csv stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if c.config.Source != nil {
+		if _, ok := extracted[*c.config.Source]; !ok {
+			level.Debug(c.logger).Log("msg", "source does not exist in the set of extracted values", "source", *c.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*c.config.Source])
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "failed to convert source value to string", "source", *c.config.Source, "err", err, "type", reflect.TypeOf(extracted[*c.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(c.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	fields, err := c.split(*input)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "failed to split line into columns", "err", err)
+		c.reportError()
+		return
+	}
+
+	columns := c.config.Columns
+	if c.config.Header {
+		var isHeader bool
+		columns, isHeader = c.streamColumns(labels.Fingerprint(), fields)
+		if isHeader {
+			level.Debug(c.logger).Log("msg", "read csv header", "columns", strings.Join(fields, ","))
+			return
+		}
+	}
+
+	if len(fields) != len(columns) && !c.config.Lenient {
+		level.Debug(c.logger).Log("msg", fmt.Sprintf("expected %d columns but found %d", len(columns), len(fields)))
+		return
+	}
+
+	for i, name := range columns {
+		if i >= len(fields) {
+			break
+		}
+		// An empty column name skips the column.
+		if name == "" {
+			continue
+		}
+		extracted[name] = fields[i]
+	}
+	level.Debug(c.logger).Log("msg", "extracted data debug in csv stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// streamColumns returns the column names of the stream with the given
+// fingerprint, and whether fields is the header line of the stream, in which
+// case the column names of the stream are updated.
+func (c *csvStage) streamColumns(fp model.Fingerprint, fields []string) ([]string, bool) {
+	now := c.now()
+	c.evictIdleHeaders(now)
+
+	header, ok := c.headers[fp]
+	if c.isHeader(fields, ok) {
+		c.headers[fp] = &csvHeader{columns: fields, lastSeen: now}
+		return nil, true
+	}
+	if !ok {
+		// Only reachable when columns are set: the header of the stream
+		// hasn't been read, for example because Alloy restarted.
+		return c.config.Columns, false
+	}
+	header.lastSeen = now
+	return header.columns, false
+}
+
+// isHeader returns whether fields is a header line. Without columns, the
+// first line of each stream is the header. With columns, a line is a header
+// when it contains every non-empty column name, in any order.
+func (c *csvStage) isHeader(fields []string, known bool) bool {
+	if len(c.config.Columns) == 0 {
+		return !known
+	}
+	for _, name := range c.config.Columns {
+		if name != "" && !slices.Contains(fields, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// evictIdleHeaders forgets the column names of the streams which haven't
+// received a line for max_idle_time. The streams are checked at most once
+// per max_idle_time.
+func (c *csvStage) evictIdleHeaders(now time.Time) {
+	if c.config.MaxIdleTime <= 0 || now.Sub(c.lastSweep) < c.config.MaxIdleTime {
+		return
+	}
+	for fp, header := range c.headers {
+		if now.Sub(header.lastSeen) >= c.config.MaxIdleTime {
+			delete(c.headers, fp)
+		}
+	}
+	c.lastSweep = now
+}
+
+// split breaks the input into fields using the configured delimiter. Fields
+// may be enclosed in the quote character, in which case they may contain the
+// delimiter; a doubled quote character inside a quoted field is an escaped
+// quote.
+func (c *csvStage) split(input string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+		closed bool
+		start  = true
+	)
+
+	flush := func() {
+		value := field.String()
+		if c.config.TrimSpace {
+			value = strings.TrimSpace(value)
+		}
+		fields = append(fields, value)
+		field.Reset()
+		quoted, closed, start = false, false, true
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quoted && r == c.quote:
+			if i+1 < len(runes) && runes[i+1] == c.quote {
+				field.WriteRune(r)
+				i++
+				continue
+			}
+			quoted, closed = false, true
+		case quoted:
+			field.WriteRune(r)
+		case r == c.delimiter:
+			flush()
+		case start && r == c.quote:
+			quoted = true
+			start = false
+		case closed:
+			if c.config.TrimSpace && r == ' ' {
+				continue
+			}
+			return nil, errCSVUnexpectedAfterEnd
+		default:
+			if start && c.config.TrimSpace && r == ' ' {
+				continue
+			}
+			start = false
+			field.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errCSVUnterminatedQuote
+	}
+	flush()
+	return fields, nil
+}
+
+// Name implements Stage
+func (c *csvStage) Name() string {
+	return StageTypeCSV
+}
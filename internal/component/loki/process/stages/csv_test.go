@@ -0,0 +1,233 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testCSVAlloyPipeDelimited = `
+stage.csv {
+    columns   = ["time", "level", "", "message"]
+    delimiter = "|"
+}
+`
+
+var testCSVAlloyWithSource = `
+stage.regex {
+    expression = "^(?P<time>\\S+) (?P<rest>.*)$"
+}
+stage.csv {
+    columns    = ["level", "user", "message"]
+    quote      = "'"
+    trim_space = true
+    source     = "rest"
+}
+`
+
+var testCSVAlloyLenient = `
+stage.csv {
+    columns = ["a", "b", "c"]
+    lenient = true
+}
+`
+
+func TestPipeline_CSV(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"pipe delimited line with a skipped column": {
+			testCSVAlloyPipeDelimited,
+			`2024-01-01T00:00:00Z|info|ignored|"hello | world"`,
+			map[string]interface{}{
+				"time":    "2024-01-01T00:00:00Z",
+				"level":   "info",
+				"message": "hello | world",
+			},
+		},
+		"custom quote, trimmed fields and source": {
+			testCSVAlloyWithSource,
+			`2024-01-01T00:00:00Z warn , frank , 'it''s, broken'`,
+			map[string]interface{}{
+				"time":    "2024-01-01T00:00:00Z",
+				"rest":    `warn , frank , 'it''s, broken'`,
+				"level":   "warn",
+				"user":    "frank",
+				"message": "it's, broken",
+			},
+		},
+		"strict column count mismatch": {
+			testCSVAlloyPipeDelimited,
+			`a|b`,
+			map[string]interface{}{},
+		},
+		"lenient with missing columns": {
+			testCSVAlloyLenient,
+			`1,2`,
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+			},
+		},
+		"lenient with extra columns": {
+			testCSVAlloyLenient,
+			`1,2,3,4`,
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+				"c": "3",
+			},
+		},
+		"unterminated quote": {
+			testCSVAlloyLenient,
+			`1,"2,3`,
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger := util.TestAlloyLogger(t)
+			pl, err := NewPipeline(logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestCSVStage_Header(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultCSVConfig
+	cfg.Header = true
+	st, err := newCSVStage(util.TestAlloyLogger(t), cfg)
+	require.NoError(t, err)
+
+	streamA := model.LabelSet{"stream": "a"}
+	streamB := model.LabelSet{"stream": "b"}
+	out := processEntries(st,
+		newEntry(nil, streamA, "level,msg", time.Now()),
+		newEntry(nil, streamB, "user,status", time.Now()),
+		newEntry(nil, streamA, "info,hello", time.Now()),
+		newEntry(nil, streamB, "frank,200", time.Now()),
+	)
+	require.Len(t, out, 4)
+
+	assert.Empty(t, out[0].Extracted)
+	assert.Empty(t, out[1].Extracted)
+	assert.Equal(t, map[string]interface{}{"level": "info", "msg": "hello"}, out[2].Extracted)
+	assert.Equal(t, map[string]interface{}{"user": "frank", "status": "200"}, out[3].Extracted)
+}
+
+func TestCSVStage_HeaderWithColumns(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultCSVConfig
+	cfg.Header = true
+	cfg.Columns = []string{"level", "msg"}
+	st, err := newCSVStage(util.TestAlloyLogger(t), cfg)
+	require.NoError(t, err)
+
+	// The header of the stream was read before a restart: data lines use
+	// columns until a header line is read.
+	stream := model.LabelSet{"stream": "a"}
+	out := processEntries(st,
+		newEntry(nil, stream, "info,hello", time.Now()),
+		newEntry(nil, stream, "msg,level", time.Now()),
+		newEntry(nil, stream, "bye,warn", time.Now()),
+	)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, map[string]interface{}{"level": "info", "msg": "hello"}, out[0].Extracted)
+	assert.Empty(t, out[1].Extracted)
+	assert.Equal(t, map[string]interface{}{"level": "warn", "msg": "bye"}, out[2].Extracted)
+}
+
+func TestCSVStage_HeaderMaxIdleTime(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultCSVConfig
+	cfg.Header = true
+	cfg.MaxIdleTime = time.Minute
+	st, err := newCSVStage(util.TestAlloyLogger(t), cfg)
+	require.NoError(t, err)
+
+	now := time.Now()
+	csv := st.(*stageProcessor).Processor.(*csvStage)
+	csv.now = func() time.Time { return now }
+
+	processEntries(st,
+		newEntry(nil, model.LabelSet{"stream": "a"}, "level,msg", now),
+		newEntry(nil, model.LabelSet{"stream": "b"}, "user,status", now),
+	)
+	require.Len(t, csv.headers, 2)
+
+	now = now.Add(2 * time.Minute)
+	out := processEntries(st, newEntry(nil, model.LabelSet{"stream": "a"}, "info,hello", now))
+	// Both headers expired, so the line is read as the new header of the
+	// stream.
+	assert.Empty(t, out[0].Extracted)
+	require.Len(t, csv.headers, 1)
+}
+
+func TestCSVConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	emptySource := ""
+	tests := map[string]struct {
+		config CSVConfig
+		err    error
+	}{
+		"no columns": {
+			CSVConfig{Delimiter: ",", Quote: `"`},
+			ErrCSVColumnsRequired,
+		},
+		"negative max idle time": {
+			CSVConfig{Header: true, Delimiter: ",", Quote: `"`, MaxIdleTime: -time.Second},
+			ErrCSVNegativeMaxIdle,
+		},
+		"multi-character delimiter": {
+			CSVConfig{Columns: []string{"a"}, Delimiter: "||", Quote: `"`},
+			ErrCSVInvalidDelimiter,
+		},
+		"empty quote": {
+			CSVConfig{Columns: []string{"a"}, Delimiter: ","},
+			ErrCSVInvalidQuote,
+		},
+		"delimiter equals quote": {
+			CSVConfig{Columns: []string{"a"}, Delimiter: "'", Quote: "'"},
+			ErrCSVDelimiterIsQuote,
+		},
+		"empty source": {
+			CSVConfig{Columns: []string{"a"}, Delimiter: ",", Quote: `"`, Source: &emptySource},
+			ErrEmptyCSVStageSource,
+		},
+		"valid": {
+			CSVConfig{Columns: []string{"a"}, Delimiter: "\t", Quote: `"`},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package stages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Decode encodings.
+const (
+	DecodeEncodingBase64 = "base64"
+	DecodeEncodingGzip   = "gzip"
+	DecodeEncodingSnappy = "snappy"
+	DecodeEncodingZstd   = "zstd"
+)
+
+// Configuration errors.
+var (
+	ErrDecodeInvalidEncoding = errors.New("decode stage encoding must be one of \"base64\", \"gzip\", \"snappy\" or \"zstd\"")
+	ErrDecodeInvalidMaxSize  = errors.New("decode stage max_decoded_size must be greater than 0")
+	ErrEmptyDecodeStageField = errors.New("decode stage source and target must not be empty")
+	ErrDecodedSizeExceeded   = errors.New("decoded value exceeds max_decoded_size")
+)
+
+// DecodeConfig configures a processing stage that decodes encoded payloads.
+type DecodeConfig struct {
+	Encoding       string           `alloy:"encoding,attr"`
+	Source         *string          `alloy:"source,attr,optional"`
+	Target         *string          `alloy:"target,attr,optional"`
+	MaxDecodedSize units.Base2Bytes `alloy:"max_decoded_size,attr,optional"`
+	When           string           `alloy:"when,attr,optional"`
+}
+
+// DefaultDecodeConfig sets the defaults for DecodeConfig.
+var DefaultDecodeConfig = DecodeConfig{
+	MaxDecodedSize: units.MiB,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *DecodeConfig) SetToDefault() {
+	*c = DefaultDecodeConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *DecodeConfig) Validate() error {
+	switch c.Encoding {
+	case DecodeEncodingBase64, DecodeEncodingGzip, DecodeEncodingSnappy, DecodeEncodingZstd:
+	default:
+		return ErrDecodeInvalidEncoding
+	}
+	if c.MaxDecodedSize <= 0 {
+		return ErrDecodeInvalidMaxSize
+	}
+	if (c.Source != nil && *c.Source == "") || (c.Target != nil && *c.Target == "") {
+		return ErrEmptyDecodeStageField
+	}
+	return nil
+}
+
+// decodeStage decodes the log line or an extracted value.
+type decodeStage struct {
+	stageErrors
+
+	config *DecodeConfig
+	logger log.Logger
+}
+
+// newDecodeStage creates a new decode pipeline stage from a config.
+func newDecodeStage(logger log.Logger, config DecodeConfig) (Stage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return toStage(&decodeStage{
+		config: &config,
+		logger: log.With(logger, "component", "stage", "type", "decode"),
+	}), nil
+}
+
+// Process implements Stage
+func (d *decodeStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the decode stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if d.config.Source != nil {
+		if _, ok := extracted[*d.config.Source]; !ok {
+			if Debug {
+				level.Debug(d.logger).Log("msg", "source does not exist in the set of extracted values", "source", *d.config.Source)
+			}
+			return
+		}
+
+		value, err := getString(extracted[*d.config.Source])
+		if err != nil {
+			if Debug {
+				level.Debug(d.logger).Log("msg", "failed to convert source value to string", "source", *d.config.Source, "err", err, "type", reflect.TypeOf(extracted[*d.config.Source]))
+			}
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		if Debug {
+			level.Debug(d.logger).Log("msg", "cannot decode a nil entry")
+		}
+		return
+	}
+
+	decoded, err := d.decode(*input)
+	if err != nil {
+		if Debug {
+			level.Debug(d.logger).Log("msg", "failed to decode", "encoding", d.config.Encoding, "err", err)
+		}
+		d.reportError()
+		return
+	}
+
+	if d.config.Target != nil {
+		extracted[*d.config.Target] = decoded
+	} else {
+		*entry = decoded
+	}
+}
+
+// decode decodes the input, refusing to produce more than the configured
+// maximum size.
+func (d *decodeStage) decode(input string) (string, error) {
+	maxSize := int64(d.config.MaxDecodedSize)
+
+	switch d.config.Encoding {
+	case DecodeEncodingBase64:
+		input = strings.TrimSpace(input)
+		if int64(base64.StdEncoding.DecodedLen(len(input))) > maxSize+2 {
+			return "", ErrDecodedSizeExceeded
+		}
+		decoded, err := base64.StdEncoding.DecodeString(input)
+		if err != nil {
+			return "", err
+		}
+		if int64(len(decoded)) > maxSize {
+			return "", ErrDecodedSizeExceeded
+		}
+		return string(decoded), nil
+
+	case DecodeEncodingGzip:
+		r, err := gzip.NewReader(strings.NewReader(input))
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		return readLimited(r, maxSize)
+
+	case DecodeEncodingSnappy:
+		// The snappy block format starts with the decoded length, so it can be
+		// checked before decoding.
+		n, err := snappy.DecodedLen([]byte(input))
+		if err != nil {
+			return "", err
+		}
+		if int64(n) > maxSize {
+			return "", ErrDecodedSizeExceeded
+		}
+		decoded, err := snappy.Decode(nil, []byte(input))
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+
+	case DecodeEncodingZstd:
+		r, err := zstd.NewReader(strings.NewReader(input), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		return readLimited(r, maxSize)
+
+	default:
+		return "", fmt.Errorf("unknown encoding %q", d.config.Encoding)
+	}
+}
+
+// readLimited reads r until EOF, failing if it holds more than maxSize bytes.
+func readLimited(r io.Reader, maxSize int64) (string, error) {
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if n > maxSize {
+		return "", ErrDecodedSizeExceeded
+	}
+	return buf.String(), nil
+}
+
+// Name implements Stage
+func (d *decodeStage) Name() string {
+	return StageTypeDecode
+}
@@ -0,0 +1,159 @@
+package stages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testDecodeAlloy = `
+stage.json {
+    expressions = { payload = "" }
+}
+stage.decode {
+    encoding = "base64"
+    source   = "payload"
+    target   = "payload"
+}
+stage.decode {
+    encoding = "gzip"
+    source   = "payload"
+}
+`
+
+func gzipString(t *testing.T, s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestDecodePipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testDecodeAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	payload := base64.StdEncoding.EncodeToString([]byte(gzipString(t, "level=info msg=hello")))
+	out := processEntries(pl, newEntry(nil, nil, `{"payload":"`+payload+`"}`, time.Now()))[0]
+	assert.Equal(t, "level=info msg=hello", out.Line)
+}
+
+func TestDecodeStage_Encodings(t *testing.T) {
+	zstdEncoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer zstdEncoder.Close()
+
+	tests := map[string]struct {
+		encoding string
+		input    string
+		expected string
+	}{
+		"base64": {
+			encoding: DecodeEncodingBase64,
+			input:    base64.StdEncoding.EncodeToString([]byte("hello world")) + "\n",
+			expected: "hello world",
+		},
+		"gzip": {
+			encoding: DecodeEncodingGzip,
+			input:    gzipString(t, "hello world"),
+			expected: "hello world",
+		},
+		"snappy": {
+			encoding: DecodeEncodingSnappy,
+			input:    string(snappy.Encode(nil, []byte("hello world"))),
+			expected: "hello world",
+		},
+		"zstd": {
+			encoding: DecodeEncodingZstd,
+			input:    string(zstdEncoder.EncodeAll([]byte("hello world"), nil)),
+			expected: "hello world",
+		},
+		"invalid input is left untouched": {
+			encoding: DecodeEncodingGzip,
+			input:    "not gzip",
+			expected: "not gzip",
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			cfg := DefaultDecodeConfig
+			cfg.Encoding = tt.encoding
+			st, err := newDecodeStage(util.TestAlloyLogger(t), cfg)
+			require.NoError(t, err)
+
+			out := processEntries(st, newEntry(nil, nil, tt.input, time.Now()))[0]
+			assert.Equal(t, tt.expected, out.Line)
+		})
+	}
+}
+
+func TestDecodeStage_MaxDecodedSize(t *testing.T) {
+	encoders := map[string]func(s string) string{
+		DecodeEncodingBase64: func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		DecodeEncodingGzip:   func(s string) string { return gzipString(t, s) },
+		DecodeEncodingSnappy: func(s string) string { return string(snappy.Encode(nil, []byte(s))) },
+	}
+	for encoding, encode := range encoders {
+		t.Run(encoding, func(t *testing.T) {
+			cfg := DefaultDecodeConfig
+			cfg.Encoding = encoding
+			cfg.MaxDecodedSize = 10
+			st, err := newDecodeStage(util.TestAlloyLogger(t), cfg)
+			require.NoError(t, err)
+			ds := st.(*stageProcessor).Processor.(*decodeStage)
+
+			decoded, err := ds.decode(encode("0123456789"))
+			require.NoError(t, err)
+			assert.Equal(t, "0123456789", decoded)
+
+			_, err = ds.decode(encode("0123456789a"))
+			require.ErrorIs(t, err, ErrDecodedSizeExceeded)
+		})
+	}
+}
+
+func TestDecodeConfig_Validate(t *testing.T) {
+	empty := ""
+	tests := map[string]struct {
+		config DecodeConfig
+		err    error
+	}{
+		"invalid encoding": {
+			DecodeConfig{Encoding: "brotli", MaxDecodedSize: 1},
+			ErrDecodeInvalidEncoding,
+		},
+		"invalid max size": {
+			DecodeConfig{Encoding: DecodeEncodingGzip},
+			ErrDecodeInvalidMaxSize,
+		},
+		"empty source": {
+			DecodeConfig{Encoding: DecodeEncodingGzip, MaxDecodedSize: 1, Source: &empty},
+			ErrEmptyDecodeStageField,
+		},
+		"empty target": {
+			DecodeConfig{Encoding: DecodeEncodingGzip, MaxDecodedSize: 1, Target: &empty},
+			ErrEmptyDecodeStageField,
+		},
+		"valid": {
+			DecodeConfig{Encoding: DecodeEncodingZstd, MaxDecodedSize: 1},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
-type DecolorizeConfig struct{}
+type DecolorizeConfig struct {
+	When string `alloy:"when,attr,optional"`
+}
 
 type decolorizeStage struct{}
 
@@ -19,6 +21,10 @@ func (m *decolorizeStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			decolorizedLine, _ := decolorizer.Process(
 				e.Timestamp.Unix(),
 				[]byte(e.Entry.Line),
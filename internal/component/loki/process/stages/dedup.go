@@ -0,0 +1,205 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrDedupStageInvalidWindow     = errors.New("dedup stage `window` must be greater than 0")
+	ErrDedupStageInvalidMaxEntries = errors.New("dedup stage `max_entries` must be greater than 0")
+	ErrDedupStageInvalidMode       = errors.New("dedup stage `mode` must be one of \"hash\" or \"exact\"")
+)
+
+// Dedup modes.
+const (
+	DedupModeHash  = "hash"
+	DedupModeExact = "exact"
+)
+
+var defaultDedupReason = "dedup_stage"
+
+// DedupConfig contains the configuration for a dedupStage.
+type DedupConfig struct {
+	Window     time.Duration `alloy:"window,attr,optional"`
+	Mode       string        `alloy:"mode,attr,optional"`
+	Labels     []string      `alloy:"labels,attr,optional"`
+	Source     string        `alloy:"source,attr,optional"`
+	MaxEntries int           `alloy:"max_entries,attr,optional"`
+	DropReason string        `alloy:"drop_counter_reason,attr,optional"`
+	When       string        `alloy:"when,attr,optional"`
+}
+
+// DefaultDedupConfig sets the defaults for DedupConfig.
+var DefaultDedupConfig = DedupConfig{
+	Window:     time.Minute,
+	Mode:       DedupModeHash,
+	MaxEntries: 100000,
+	DropReason: defaultDedupReason,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *DedupConfig) SetToDefault() {
+	*c = DefaultDedupConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *DedupConfig) Validate() error {
+	if c.Window <= 0 {
+		return ErrDedupStageInvalidWindow
+	}
+	if c.MaxEntries <= 0 {
+		return ErrDedupStageInvalidMaxEntries
+	}
+	if c.Mode != DedupModeHash && c.Mode != DedupModeExact {
+		return ErrDedupStageInvalidMode
+	}
+	return nil
+}
+
+// dedupKey identifies a log line within a stream. Depending on the mode
+// either the hash or the full content of the line is set.
+type dedupKey struct {
+	stream model.Fingerprint
+	hash   uint64
+	line   string
+}
+
+// dedupStage drops log lines which have already been seen in the same
+// stream within the configured window.
+type dedupStage struct {
+	logger    log.Logger
+	cfg       DedupConfig
+	dropCount *prometheus.CounterVec
+
+	// seen holds the time each tracked line was last forwarded.
+	seen      map[dedupKey]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newDedupStage creates a dedupStage from config.
+func newDedupStage(logger log.Logger, cfg DedupConfig, registerer prometheus.Registerer) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &dedupStage{
+		logger:    log.With(logger, "component", "stage", "type", "dedup"),
+		cfg:       cfg,
+		dropCount: getDropCountMetric(registerer),
+		seen:      make(map[dedupKey]time.Time),
+		now:       time.Now,
+	}, nil
+}
+
+func (m *dedupStage) Run(in chan Entry) chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		counter := m.dropCount.WithLabelValues(m.cfg.DropReason)
+		for e := range in {
+			if e.skip || !m.isDuplicate(e) {
+				out <- e
+				continue
+			}
+			counter.Inc()
+		}
+	}()
+	return out
+}
+
+// isDuplicate reports whether the entry was already forwarded within the
+// window, and starts tracking it otherwise.
+func (m *dedupStage) isDuplicate(e Entry) bool {
+	now := m.now()
+	if now.Sub(m.lastSweep) >= m.cfg.Window {
+		m.sweep(now)
+	}
+
+	key, ok := m.key(e)
+	if !ok {
+		return false
+	}
+
+	if seenAt, ok := m.seen[key]; ok && now.Sub(seenAt) < m.cfg.Window {
+		if Debug {
+			level.Debug(m.logger).Log("msg", "dropping duplicate line", "first_seen", seenAt)
+		}
+		return true
+	}
+
+	// Lines outside of the window are only removed once per window, so that
+	// a full stage doesn't scan all tracked lines for every new line.
+	if len(m.seen) >= m.cfg.MaxEntries {
+		level.Debug(m.logger).Log("msg", fmt.Sprintf("dedup stage is tracking the maximum of %d lines, forwarding line without tracking it", m.cfg.MaxEntries))
+		return false
+	}
+	m.seen[key] = now
+	return false
+}
+
+// key builds the dedup key of an entry. It returns false if the configured
+// source is missing from the extracted values.
+func (m *dedupStage) key(e Entry) (dedupKey, bool) {
+	value := e.Line
+	if m.cfg.Source != "" {
+		v, ok := e.Extracted[m.cfg.Source]
+		if !ok {
+			level.Debug(m.logger).Log("msg", "source does not exist in the set of extracted values", "source", m.cfg.Source)
+			return dedupKey{}, false
+		}
+		s, err := getString(v)
+		if err != nil {
+			level.Debug(m.logger).Log("msg", "failed to convert source value to string", "source", m.cfg.Source, "err", err)
+			return dedupKey{}, false
+		}
+		value = s
+	}
+
+	labels := e.Labels
+	if len(m.cfg.Labels) > 0 {
+		labels = make(model.LabelSet, len(m.cfg.Labels))
+		for _, name := range m.cfg.Labels {
+			if v, ok := e.Labels[model.LabelName(name)]; ok {
+				labels[model.LabelName(name)] = v
+			}
+		}
+	}
+
+	key := dedupKey{stream: labels.Fingerprint()}
+	if m.cfg.Mode == DedupModeExact {
+		key.line = value
+	} else {
+		key.hash = xxhash.Sum64String(value)
+	}
+	return key, true
+}
+
+// sweep removes all lines which are no longer within the window.
+func (m *dedupStage) sweep(now time.Time) {
+	for k, seenAt := range m.seen {
+		if now.Sub(seenAt) >= m.cfg.Window {
+			delete(m.seen, k)
+		}
+	}
+	m.lastSweep = now
+}
+
+// Name implements Stage
+func (m *dedupStage) Name() string {
+	return StageTypeDedup
+}
+
+// Cleanup implements Stage.
+func (*dedupStage) Cleanup() {
+	// no-op
+}
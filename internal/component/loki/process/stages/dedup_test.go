@@ -0,0 +1,173 @@
+package stages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testDedupAlloy = `
+stage.dedup {
+    window              = "10s"
+    drop_counter_reason = "duplicate"
+}
+`
+
+func TestDedupPipeline(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	plName := "test_dedup_pipeline"
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testDedupAlloy), &plName, registry)
+	require.NoError(t, err)
+
+	appA := model.LabelSet{"app": "a"}
+	appB := model.LabelSet{"app": "b"}
+	out := processEntries(pl,
+		newEntry(nil, appA, "repeated line", time.Now()),
+		newEntry(nil, appA, "repeated line", time.Now()),
+		newEntry(nil, appB, "repeated line", time.Now()),
+		newEntry(nil, appA, "another line", time.Now()),
+		newEntry(nil, appA, "repeated line", time.Now()),
+	)
+
+	require.Len(t, out, 3)
+	assert.Equal(t, "repeated line", out[0].Line)
+	assert.Equal(t, appB, out[1].Labels)
+	assert.Equal(t, "another line", out[2].Line)
+
+	expected := `
+# HELP loki_process_dropped_lines_total A count of all log lines dropped as a result of a pipeline stage
+# TYPE loki_process_dropped_lines_total counter
+loki_process_dropped_lines_total{reason="duplicate"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "loki_process_dropped_lines_total"))
+}
+
+func TestDedupStage_Window(t *testing.T) {
+	for _, mode := range []string{DedupModeHash, DedupModeExact} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := DefaultDedupConfig
+			cfg.Window = 10 * time.Second
+			cfg.Mode = mode
+			cfg.Labels = []string{"app"}
+			cfg.Source = "msg"
+			st, err := newDedupStage(util.TestAlloyLogger(t), cfg, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			ds := st.(*dedupStage)
+			now := time.Unix(0, 0)
+			ds.now = func() time.Time { return now }
+
+			entry := func(host, msg string) Entry {
+				return newEntry(map[string]interface{}{"msg": msg}, model.LabelSet{"app": "a", "host": model.LabelValue(host)}, "line", now)
+			}
+
+			// Other labels than the configured ones don't make a line unique.
+			assert.False(t, ds.isDuplicate(entry("h1", "foo")))
+			assert.True(t, ds.isDuplicate(entry("h2", "foo")))
+			assert.False(t, ds.isDuplicate(entry("h1", "bar")))
+
+			now = now.Add(9 * time.Second)
+			assert.True(t, ds.isDuplicate(entry("h1", "foo")))
+
+			// The window is counted from the first forwarded copy.
+			now = now.Add(time.Second)
+			assert.False(t, ds.isDuplicate(entry("h1", "foo")))
+			assert.True(t, ds.isDuplicate(entry("h1", "foo")))
+
+			// Expired lines are swept from memory.
+			now = now.Add(time.Minute)
+			assert.False(t, ds.isDuplicate(entry("h1", "baz")))
+			assert.Len(t, ds.seen, 1)
+
+			// Entries missing the source are always forwarded.
+			missing := newEntry(nil, model.LabelSet{"app": "a"}, "line", now)
+			assert.False(t, ds.isDuplicate(missing))
+			assert.False(t, ds.isDuplicate(missing))
+		})
+	}
+}
+
+func TestDedupStage_MaxEntries(t *testing.T) {
+	cfg := DefaultDedupConfig
+	cfg.MaxEntries = 2
+	st, err := newDedupStage(util.TestAlloyLogger(t), cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	ds := st.(*dedupStage)
+
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "a", time.Now())))
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "b", time.Now())))
+	// The stage is full, so new lines are forwarded without being tracked.
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "c", time.Now())))
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "c", time.Now())))
+	assert.True(t, ds.isDuplicate(newEntry(nil, nil, "a", time.Now())))
+}
+
+func TestDedupStage_MaxEntriesSweep(t *testing.T) {
+	cfg := DefaultDedupConfig
+	cfg.MaxEntries = 2
+	st, err := newDedupStage(util.TestAlloyLogger(t), cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	ds := st.(*dedupStage)
+
+	start := time.Now()
+	now := start
+	ds.now = func() time.Time { return now }
+	ds.sweep(now)
+
+	now = start.Add(cfg.Window / 2)
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "a", now)))
+	now = start.Add(3 * cfg.Window / 4)
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "b", now)))
+	now = start.Add(cfg.Window)
+	assert.True(t, ds.isDuplicate(newEntry(nil, nil, "a", now)))
+
+	// "a" left the window, but the stage only removes lines once per window.
+	now = start.Add(7 * cfg.Window / 4)
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "c", now)))
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "c", now)))
+	assert.Len(t, ds.seen, 2)
+
+	// The next sweep removes "a" and "b", and "c" is tracked.
+	now = start.Add(2 * cfg.Window)
+	assert.False(t, ds.isDuplicate(newEntry(nil, nil, "c", now)))
+	assert.True(t, ds.isDuplicate(newEntry(nil, nil, "c", now)))
+	assert.Len(t, ds.seen, 1)
+}
+
+func TestDedupConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config DedupConfig
+		err    error
+	}{
+		"invalid window": {
+			DedupConfig{Mode: DedupModeHash, MaxEntries: 1},
+			ErrDedupStageInvalidWindow,
+		},
+		"invalid max entries": {
+			DedupConfig{Mode: DedupModeHash, Window: time.Second},
+			ErrDedupStageInvalidMaxEntries,
+		},
+		"invalid mode": {
+			DedupConfig{Mode: "fuzzy", Window: time.Second, MaxEntries: 1},
+			ErrDedupStageInvalidMode,
+		},
+		"valid": {
+			DefaultDedupConfig,
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
@@ -16,10 +16,11 @@ import (
 )
 
 const (
-	ErrDropStageEmptyConfig       = "drop stage config must contain at least one of `source`, `expression`, `older_than` or `longer_than`"
+	ErrDropStageEmptyConfig       = "drop stage config must contain at least one of `source`, `expression`, `condition`, `older_than`, `longer_than` or `drop_empty`"
 	ErrDropStageInvalidConfig     = "drop stage config error, `value` and `expression` cannot both be defined at the same time."
 	ErrDropStageInvalidRegex      = "drop stage regex compilation error: %v"
 	ErrDropStageNoSourceWithValue = "drop stage config must contain `source` if `value` is specified"
+	ErrDropStageInvalidCondition  = "drop stage condition parsing error: %v"
 )
 
 var (
@@ -36,14 +37,17 @@ type DropConfig struct {
 	Value      string           `alloy:"value,attr,optional"`
 	Separator  string           `alloy:"separator,attr,optional"`
 	Expression string           `alloy:"expression,attr,optional"`
+	Condition  string           `alloy:"condition,attr,optional"`
 	OlderThan  time.Duration    `alloy:"older_than,attr,optional"`
 	LongerThan units.Base2Bytes `alloy:"longer_than,attr,optional"`
+	DropEmpty  bool             `alloy:"drop_empty,attr,optional"`
+	When       string           `alloy:"when,attr,optional"`
 }
 
 // validateDropConfig validates the DropConfig for the dropStage
 func validateDropConfig(cfg *DropConfig) (*regexp.Regexp, error) {
 	if cfg == nil ||
-		(cfg.Source == "" && cfg.Expression == "" && cfg.OlderThan == emptyDuration && cfg.LongerThan == emptySize) {
+		(cfg.Source == "" && cfg.Expression == "" && cfg.Condition == "" && cfg.OlderThan == emptyDuration && cfg.LongerThan == emptySize && !cfg.DropEmpty) {
 		return nil, errors.New(ErrDropStageEmptyConfig)
 	}
 	if cfg.DropReason == "" {
@@ -85,10 +89,21 @@ func newDropStage(logger log.Logger, config DropConfig, registerer prometheus.Re
 		return nil, err
 	}
 
+	logger = log.With(logger, "component", "stage", "type", "drop")
+
+	var cond *condition
+	if config.Condition != "" {
+		cond, err = newCondition(logger, registerer, StageTypeDrop, config.Condition)
+		if err != nil {
+			return nil, fmt.Errorf(ErrDropStageInvalidCondition, err)
+		}
+	}
+
 	return &dropStage{
-		logger:    log.With(logger, "component", "stage", "type", "drop"),
+		logger:    logger,
 		cfg:       &config,
 		regex:     regex,
+		condition: cond,
 		dropCount: getDropCountMetric(registerer),
 	}, nil
 }
@@ -98,6 +113,7 @@ type dropStage struct {
 	logger    log.Logger
 	cfg       *DropConfig
 	regex     *regexp.Regexp
+	condition *condition
 	dropCount *prometheus.CounterVec
 }
 
@@ -106,7 +122,7 @@ func (m *dropStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
-			if !m.shouldDrop(e) {
+			if e.skip || !m.shouldDrop(e) {
 				out <- e
 				continue
 			}
@@ -132,6 +148,20 @@ func (m *dropStage) shouldDrop(e Entry) bool {
 		}
 	}
 
+	if m.cfg.DropEmpty {
+		// strings.TrimSpace removes all Unicode white space, not only ASCII.
+		if strings.TrimSpace(e.Line) == "" {
+			if Debug {
+				level.Debug(m.logger).Log("msg", "line met drop criteria for being empty")
+			}
+		} else {
+			if Debug {
+				level.Debug(m.logger).Log("msg", "line will not be dropped, it is not empty")
+			}
+			return false
+		}
+	}
+
 	if m.cfg.OlderThan != emptyDuration {
 		ct := time.Now()
 		if e.Timestamp.Before(ct.Add(-m.cfg.OlderThan)) {
@@ -206,6 +236,18 @@ func (m *dropStage) shouldDrop(e Entry) bool {
 		}
 	}
 
+	if m.condition != nil {
+		if !m.condition.matches(e) {
+			if Debug {
+				level.Debug(m.logger).Log("msg", "line will not be dropped, the provided condition is false")
+			}
+			return false
+		}
+		if Debug {
+			level.Debug(m.logger).Log("msg", "line met drop criteria, the provided condition is true")
+		}
+	}
+
 	// Everything matched, drop the line
 	if Debug {
 		level.Debug(m.logger).Log("msg", "all criteria met, line will be dropped")
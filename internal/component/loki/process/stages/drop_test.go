@@ -41,6 +41,10 @@ stage.drop {
 stage.drop {
 		longer_than = "10000B"
 }
+
+stage.drop {
+		condition = "app == \"loki\" && msg != \"\""
+}
 `
 
 func Test_dropStage_Process(t *testing.T) {
@@ -91,6 +95,36 @@ func Test_dropStage_Process(t *testing.T) {
 			entry:      "123456789",
 			shouldDrop: false,
 		},
+		{
+			name: "Drop Empty Should Drop Empty Line",
+			config: &DropConfig{
+				DropEmpty: true,
+			},
+			labels:     model.LabelSet{},
+			extracted:  map[string]interface{}{},
+			entry:      "",
+			shouldDrop: true,
+		},
+		{
+			name: "Drop Empty Should Drop Unicode Whitespace",
+			config: &DropConfig{
+				DropEmpty: true,
+			},
+			labels:     model.LabelSet{},
+			extracted:  map[string]interface{}{},
+			entry:      " \t\r\n\u00a0\u2003\u3000",
+			shouldDrop: true,
+		},
+		{
+			name: "Drop Empty Should Not Drop Text",
+			config: &DropConfig{
+				DropEmpty: true,
+			},
+			labels:     model.LabelSet{},
+			extracted:  map[string]interface{}{},
+			entry:      "\u00a0 x ",
+			shouldDrop: false,
+		},
 		{
 			name: "Older than Should Drop",
 			config: &DropConfig{
@@ -409,6 +443,113 @@ func Test_dropStage_Process(t *testing.T) {
 			entry:      "12345678901",
 			shouldDrop: true,
 		},
+		{
+			name: "Condition Matched",
+			config: &DropConfig{
+				Condition: `status >= 500 && env != "prod"`,
+			},
+			labels: model.LabelSet{"env": "dev"},
+			extracted: map[string]interface{}{
+				"status": "503",
+			},
+			entry:      "12345678901",
+			shouldDrop: true,
+		},
+		{
+			name: "Condition Numeric String Value",
+			config: &DropConfig{
+				Condition: `status == 200`,
+			},
+			labels: model.LabelSet{},
+			extracted: map[string]interface{}{
+				"status": "200",
+			},
+			entry:      "12345678901",
+			shouldDrop: true,
+		},
+		{
+			name: "Condition String Value",
+			config: &DropConfig{
+				Condition: `status == "OK"`,
+			},
+			labels: model.LabelSet{},
+			extracted: map[string]interface{}{
+				"status": "OK",
+			},
+			entry:      "12345678901",
+			shouldDrop: true,
+		},
+		{
+			name: "Condition Failed To Evaluate",
+			config: &DropConfig{
+				Condition: `status >= 500`,
+			},
+			labels: model.LabelSet{},
+			extracted: map[string]interface{}{
+				"status": "OK",
+			},
+			entry:      "12345678901",
+			shouldDrop: false,
+		},
+		{
+			name: "Condition Not Matched",
+			config: &DropConfig{
+				Condition: `status >= 500 && env != "prod"`,
+			},
+			labels: model.LabelSet{"env": "prod"},
+			extracted: map[string]interface{}{
+				"status": float64(503),
+			},
+			entry:      "12345678901",
+			shouldDrop: false,
+		},
+		{
+			name: "Condition Extracted Values Take Precedence Over Labels",
+			config: &DropConfig{
+				Condition: `env == "prod"`,
+			},
+			labels: model.LabelSet{"env": "dev"},
+			extracted: map[string]interface{}{
+				"env": "prod",
+			},
+			entry:      "12345678901",
+			shouldDrop: true,
+		},
+		{
+			name: "Condition Missing Value",
+			config: &DropConfig{
+				Condition: `status >= 500`,
+			},
+			labels:     model.LabelSet{},
+			extracted:  map[string]interface{}{},
+			entry:      "12345678901",
+			shouldDrop: false,
+		},
+		{
+			name: "Condition Not Boolean",
+			config: &DropConfig{
+				Condition: `status`,
+			},
+			labels: model.LabelSet{},
+			extracted: map[string]interface{}{
+				"status": "503",
+			},
+			entry:      "12345678901",
+			shouldDrop: false,
+		},
+		{
+			name: "Condition And Length Must Match",
+			config: &DropConfig{
+				Condition:  `level == "debug"`,
+				LongerThan: tenBytes,
+			},
+			labels: model.LabelSet{},
+			extracted: map[string]interface{}{
+				"level": "debug",
+			},
+			entry:      "123456789",
+			shouldDrop: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -445,6 +586,11 @@ func TestDropPipeline(t *testing.T) {
 	assert.Equal(t, out[0].Line, testMatchLogLineApp2)
 }
 
+func TestDropStage_InvalidCondition(t *testing.T) {
+	_, err := newDropStage(util.TestAlloyLogger(t), DropConfig{Condition: "status >="}, prometheus.NewRegistry())
+	require.ErrorContains(t, err, "drop stage condition parsing error")
+}
+
 func Test_validateDropConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1,7 +1,10 @@
 package stages
 
 import (
+	"encoding/xml"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/go-kit/log"
@@ -17,12 +20,17 @@ type EventLogMessageConfig struct {
 	Source            string `alloy:"source,attr,optional"`
 	DropInvalidLabels bool   `alloy:"drop_invalid_labels,attr,optional"`
 	OverwriteExisting bool   `alloy:"overwrite_existing,attr,optional"`
+	XMLSource         string `alloy:"xml_source,attr,optional"`
+	When              string `alloy:"when,attr,optional"`
 }
 
 func (e *EventLogMessageConfig) Validate() error {
 	if !model.LabelName(e.Source).IsValid() {
 		return fmt.Errorf(ErrInvalidLabelName, e.Source)
 	}
+	if e.XMLSource != "" && !model.LabelName(e.XMLSource).IsValid() {
+		return fmt.Errorf(ErrInvalidLabelName, e.XMLSource)
+	}
 	return nil
 }
 
@@ -31,6 +39,8 @@ func (e *EventLogMessageConfig) SetToDefault() {
 }
 
 type eventLogMessageStage struct {
+	stageErrors
+
 	cfg    *EventLogMessageConfig
 	logger log.Logger
 }
@@ -49,10 +59,17 @@ func (m *eventLogMessageStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			err := m.processEntry(e.Extracted, key)
 			if err != nil {
 				continue
 			}
+			if m.cfg.XMLSource != "" {
+				m.processXML(e.Extracted, m.cfg.XMLSource)
+			}
 			out <- e
 		}
 	}()
@@ -81,35 +98,224 @@ func (m *eventLogMessageStage) processEntry(extracted map[string]interface{}, ke
 			level.Warn(m.logger).Log("msg", "invalid line parsed from message", "line", line)
 			continue
 		}
-		mkey := parts[0]
-		if !model.LabelName(mkey).IsValid() {
-			if m.cfg.DropInvalidLabels {
-				if Debug {
-					level.Debug(m.logger).Log("msg", "invalid label parsed from message", "key", mkey)
+		m.setExtracted(extracted, parts[0], strings.TrimSpace(parts[1]))
+	}
+	if Debug {
+		level.Debug(m.logger).Log("msg", "extracted data debug in event_log_message stage",
+			"extracted data", fmt.Sprintf("%v", extracted))
+	}
+	return nil
+}
+
+// Process the raw event XML from extracted with the specified key, adding its
+// EventData and UserData fields and the keyword and opcode names into the
+// extracted map
+func (m *eventLogMessageStage) processXML(extracted map[string]interface{}, key string) {
+	value, ok := extracted[key]
+	if !ok {
+		if Debug {
+			level.Debug(m.logger).Log("msg", "xml source not in the extracted values", "source", key)
+		}
+		return
+	}
+	s, err := getString(value)
+	if err != nil {
+		level.Warn(m.logger).Log("msg", "invalid xml value parsed", "value", value)
+		return
+	}
+	fields, err := parseEventXML(s)
+	if err != nil {
+		level.Warn(m.logger).Log("msg", "failed to parse event xml", "err", err)
+		m.reportError()
+		return
+	}
+	for _, f := range fields {
+		m.setExtracted(extracted, f.key, f.value)
+	}
+}
+
+// setExtracted adds a key parsed from the event into the extracted map,
+// handling invalid and already existing keys as configured.
+func (m *eventLogMessageStage) setExtracted(extracted map[string]interface{}, mkey, mval string) {
+	if !model.LabelName(mkey).IsValid() {
+		if m.cfg.DropInvalidLabels {
+			if Debug {
+				level.Debug(m.logger).Log("msg", "invalid label parsed from message", "key", mkey)
+			}
+			return
+		}
+		mkey = SanitizeFullLabelName(mkey)
+	}
+	if _, ok := extracted[mkey]; ok && !m.cfg.OverwriteExisting {
+		level.Info(m.logger).Log("msg", "extracted key that already existed, appending _extracted to key",
+			"key", mkey)
+		mkey += "_extracted"
+	}
+	if !model.LabelValue(mval).IsValid() {
+		if Debug {
+			level.Debug(m.logger).Log("msg", "invalid value parsed from message", "value", mval)
+		}
+		return
+	}
+	extracted[mkey] = mval
+}
+
+// Names of the standard keywords defined in winmeta.xml, by bit.
+var eventKeywordNames = []struct {
+	mask uint64
+	name string
+}{
+	{0x01000000000000, "Response Time"},
+	{0x02000000000000, "WDI Context"},
+	{0x04000000000000, "WDI Diag"},
+	{0x08000000000000, "SQM"},
+	{0x10000000000000, "Audit Failure"},
+	{0x20000000000000, "Audit Success"},
+	{0x40000000000000, "Correlation Hint"},
+	{0x80000000000000, "Classic"},
+}
+
+// Names of the standard opcodes defined in winmeta.xml.
+var eventOpcodeNames = map[uint64]string{
+	0:   "Info",
+	1:   "Start",
+	2:   "Stop",
+	3:   "DCStart",
+	4:   "DCStop",
+	5:   "Extension",
+	6:   "Reply",
+	7:   "Resume",
+	8:   "Suspend",
+	9:   "Send",
+	240: "Receive",
+}
+
+type eventXMLField struct {
+	key   string
+	value string
+}
+
+type eventXMLElement struct {
+	name        string
+	dataName    string
+	text        strings.Builder
+	hasChildren bool
+}
+
+// parseEventXML parses a Windows event XML document, or the inner XML of its
+// EventData or UserData elements, into a list of fields. Named Data elements
+// use their Name attribute as key, unnamed ones are numbered, and any other
+// leaf element uses its own name. Keyword and opcode names are read from
+// RenderingInfo, falling back to the standard names of the System values.
+func parseEventXML(s string) ([]eventXMLField, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<?xml") {
+		if i := strings.Index(s, "?>"); i >= 0 {
+			s = s[i+2:]
+		}
+	}
+
+	var (
+		fields                       []eventXMLField
+		stack                        []*eventXMLElement
+		keywordNames                 []string
+		opcodeName                   string
+		systemKeywords, systemOpcode string
+		unnamedData                  int
+	)
+	inside := func(name string) bool {
+		for _, el := range stack {
+			if el.name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	dec := xml.NewDecoder(strings.NewReader("<root>" + s + "</root>"))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) > 0 {
+				stack[len(stack)-1].hasChildren = true
+			}
+			el := &eventXMLElement{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "Name" {
+					el.dataName = attr.Value
 				}
+			}
+			stack = append(stack, el)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			el := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if el.hasChildren || len(stack) == 0 {
 				continue
 			}
-			mkey = SanitizeFullLabelName(mkey)
-		}
-		if _, ok := extracted[mkey]; ok && !m.cfg.OverwriteExisting {
-			level.Info(m.logger).Log("msg", "extracted key that already existed, appending _extracted to key",
-				"key", mkey)
-			mkey += "_extracted"
+			text := strings.TrimSpace(el.text.String())
+
+			switch {
+			case inside("System"):
+				switch el.name {
+				case "Keywords":
+					systemKeywords = text
+				case "Opcode":
+					systemOpcode = text
+				}
+			case inside("RenderingInfo"):
+				switch el.name {
+				case "Keyword":
+					keywordNames = append(keywordNames, text)
+				case "Opcode":
+					opcodeName = text
+				}
+			case el.name == "Data":
+				key := el.dataName
+				if key == "" {
+					key = "Data_" + strconv.Itoa(unnamedData)
+					unnamedData++
+				}
+				fields = append(fields, eventXMLField{key, text})
+			default:
+				fields = append(fields, eventXMLField{el.name, text})
+			}
 		}
-		mval := strings.TrimSpace(parts[1])
-		if !model.LabelValue(mval).IsValid() {
-			if Debug {
-				level.Debug(m.logger).Log("msg", "invalid value parsed from message", "value", mval)
+	}
+
+	if len(keywordNames) == 0 && systemKeywords != "" {
+		if mask, err := strconv.ParseUint(systemKeywords, 0, 64); err == nil {
+			for _, kw := range eventKeywordNames {
+				if mask&kw.mask != 0 {
+					keywordNames = append(keywordNames, kw.name)
+				}
 			}
-			continue
 		}
-		extracted[mkey] = mval
 	}
-	if Debug {
-		level.Debug(m.logger).Log("msg", "extracted data debug in event_log_message stage",
-			"extracted data", fmt.Sprintf("%v", extracted))
+	if len(keywordNames) > 0 {
+		fields = append(fields, eventXMLField{"Keywords", strings.Join(keywordNames, ",")})
 	}
-	return nil
+
+	if opcodeName == "" && systemOpcode != "" {
+		if opcode, err := strconv.ParseUint(systemOpcode, 10, 8); err == nil {
+			opcodeName = eventOpcodeNames[opcode]
+		}
+	}
+	if opcodeName != "" {
+		fields = append(fields, eventXMLField{"Opcode", opcodeName})
+	}
+	return fields, nil
 }
 
 func (m *eventLogMessageStage) Name() string {
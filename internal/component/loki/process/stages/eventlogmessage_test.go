@@ -142,6 +142,10 @@ func TestEventLogMessageConfig_validate(t *testing.T) {
 			`stage.eventlogmessage { source = ""}`,
 			fmt.Errorf(ErrInvalidLabelName, ""),
 		},
+		"invalid xml source": {
+			`stage.eventlogmessage { xml_source = "event xml"}`,
+			fmt.Errorf(ErrInvalidLabelName, "event xml"),
+		},
 	}
 	for tName, tt := range tests {
 		tt := tt
@@ -342,6 +346,95 @@ func TestEventLogMessage_invalidString(t *testing.T) {
 	assert.Len(t, out, 0, "No output should be produced with a nil input")
 }
 
+var testEvtLogMsgXMLFull = `<?xml version="1.0" encoding="UTF-8"?>
+<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Microsoft-Windows-Security-Auditing"/>
+    <EventID>4624</EventID>
+    <Opcode>0</Opcode>
+    <Keywords>0x8020000000000000</Keywords>
+    <Computer>WinTest2211</Computer>
+  </System>
+  <EventData>
+    <Data Name="TargetUserName">User</Data>
+    <Data Name="LogonType">2</Data>
+    <Data>unnamed</Data>
+  </EventData>
+  <UserData>
+    <LogFileCleared xmlns="http://manifests.microsoft.com/win/2004/08/windows/eventlog">
+      <SubjectUserName>Admin</SubjectUserName>
+    </LogFileCleared>
+  </UserData>
+  <RenderingInfo Culture="en-US">
+    <Message>An account was successfully logged on.</Message>
+    <Opcode>Info</Opcode>
+    <Keywords>
+      <Keyword>Audit Success</Keyword>
+    </Keywords>
+  </RenderingInfo>
+</Event>`
+
+func TestEventLogMessage_XML(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		xml             string
+		extractedValues map[string]interface{}
+	}{
+		"full event": {
+			testEvtLogMsgXMLFull,
+			map[string]interface{}{
+				"TargetUserName":  "User",
+				"LogonType":       "2",
+				"Data_0":          "unnamed",
+				"SubjectUserName": "Admin",
+				"Keywords":        "Audit Success",
+				"Opcode":          "Info",
+			},
+		},
+		"event without rendering info": {
+			`<Event><System><Opcode>1</Opcode><Keywords>0x0090000000000000</Keywords></System></Event>`,
+			map[string]interface{}{
+				"Keywords": "Audit Failure,Classic",
+				"Opcode":   "Start",
+			},
+		},
+		"event data fragment": {
+			`<Data Name='ProcessId'>7344</Data><Data Name='Image'>C:\alloy.exe</Data>`,
+			map[string]interface{}{
+				"ProcessId": "7344",
+				"Image":     `C:\alloy.exe`,
+			},
+		},
+		"user data fragment": {
+			`<EventXML xmlns="Event_NS"><Param1>value</Param1><Param2>other</Param2></EventXML>`,
+			map[string]interface{}{
+				"Param1": "value",
+				"Param2": "other",
+			},
+		},
+		"invalid xml": {
+			`<Data Name='ProcessId'>7344`,
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+		testData.extractedValues["event_xml"] = testData.xml
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			pl, err := NewPipeline(util_log.Logger, loadConfig(`stage.eventlogmessage { xml_source = "event_xml" }`), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+			out := processEntries(pl,
+				newEntry(map[string]interface{}{"event_xml": testData.xml}, nil, "", time.Now()))[0]
+			assert.Equal(t, testData.extractedValues, out.Extracted)
+		})
+	}
+}
+
 var (
 	inputJustKey       = "Key 1:"
 	inputBoth          = "Key 1: Value 1"
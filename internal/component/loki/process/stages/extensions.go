@@ -17,7 +17,9 @@ const (
 
 // DockerConfig is an empty struct that is used to enable a pre-defined
 // pipeline for decoding entries that are using the Docker logs format.
-type DockerConfig struct{}
+type DockerConfig struct {
+	When string `alloy:"when,attr,optional"`
+}
 
 // CRIConfig is an empty struct that is used to enable a pre-defined pipeline
 // for decoding entries that are using the CRI logging format.
@@ -25,6 +27,7 @@ type CRIConfig struct {
 	MaxPartialLines            int    `alloy:"max_partial_lines,attr,optional"`
 	MaxPartialLineSize         uint64 `alloy:"max_partial_line_size,attr,optional"`
 	MaxPartialLineSizeTruncate bool   `alloy:"max_partial_line_size_truncate,attr,optional"`
+	When                       string `alloy:"when,attr,optional"`
 }
 
 var (
@@ -49,6 +52,9 @@ func (args *CRIConfig) Validate() error {
 	if args.MaxPartialLines <= 0 {
 		return fmt.Errorf("max_partial_lines must be greater than 0")
 	}
+	if args.MaxPartialLineSizeTruncate && args.MaxPartialLineSize == 0 {
+		return fmt.Errorf("max_partial_line_size must be greater than 0 when max_partial_line_size_truncate is enabled")
+	}
 
 	return nil
 }
@@ -79,7 +85,7 @@ func NewDocker(logger log.Logger, registerer prometheus.Registerer) (Stage, erro
 		},
 		{
 			OutputConfig: &OutputConfig{
-				"output",
+				Source: "output",
 			},
 		},
 	}
@@ -91,6 +97,10 @@ type cri struct {
 	partialLines map[model.Fingerprint]Entry
 	cfg          CRIConfig
 	base         *Pipeline
+
+	partialLinesJoined    prometheus.Counter
+	partialLinesFlushed   prometheus.Counter
+	partialLinesTruncated prometheus.Counter
 }
 
 var _ Stage = (*cri)(nil)
@@ -105,6 +115,10 @@ func (*cri) Cleanup() {
 	// no-op
 }
 
+func (c *cri) setErrorCounter(counter prometheus.Counter) bool {
+	return c.base.setErrorCounter(counter)
+}
+
 // implements Stage interface
 func (c *cri) Run(entry chan Entry) chan Entry {
 	entry = c.base.Run(entry)
@@ -122,6 +136,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 				}
 
 				level.Warn(c.base.logger).Log("msg", "cri stage: partial lines upperbound exceeded. merging it to single line", "threshold", c.cfg.MaxPartialLines)
+				c.partialLinesFlushed.Add(float64(len(entries)))
 
 				c.partialLines = make(map[model.Fingerprint]Entry, c.cfg.MaxPartialLines)
 				c.ensureTruncateIfRequired(&e)
@@ -136,6 +151,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 				builder.WriteString(prev.Line)
 				builder.WriteString(e.Line)
 				e.Line = builder.String()
+				c.partialLinesJoined.Inc()
 			}
 			c.ensureTruncateIfRequired(&e)
 			c.partialLines[fingerprint] = e
@@ -152,6 +168,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 			builder.WriteString(prev.Line)
 			builder.WriteString(e.Line)
 			e.Line = builder.String()
+			c.partialLinesJoined.Inc()
 			c.ensureTruncateIfRequired(&e)
 			delete(c.partialLines, fingerprint)
 		}
@@ -164,6 +181,7 @@ func (c *cri) Run(entry chan Entry) chan Entry {
 func (c *cri) ensureTruncateIfRequired(e *Entry) {
 	if c.cfg.MaxPartialLineSizeTruncate && len(e.Line) > int(c.cfg.MaxPartialLineSize) {
 		e.Line = e.Line[:c.cfg.MaxPartialLineSize]
+		c.partialLinesTruncated.Inc()
 	}
 }
 
@@ -189,12 +207,12 @@ func NewCRI(logger log.Logger, config CRIConfig, registerer prometheus.Registere
 		},
 		{
 			OutputConfig: &OutputConfig{
-				"content",
+				Source: "content",
 			},
 		},
 		{
 			OutputConfig: &OutputConfig{
-				"tags",
+				Source: "tags",
 			},
 		},
 	}
@@ -207,6 +225,13 @@ func NewCRI(logger log.Logger, config CRIConfig, registerer prometheus.Registere
 	c := cri{
 		cfg:  config,
 		base: p,
+
+		partialLinesJoined: registerCounterVec(registerer, "loki_process", "cri_partial_lines_joined_total",
+			"A count of all partial lines joined to a previous partial line by a cri stage", nil).WithLabelValues(),
+		partialLinesFlushed: registerCounterVec(registerer, "loki_process", "cri_partial_lines_flushed_total",
+			"A count of all partial lines sent without waiting for their full line because max_partial_lines was exceeded", nil).WithLabelValues(),
+		partialLinesTruncated: registerCounterVec(registerer, "loki_process", "cri_partial_lines_truncated_total",
+			"A count of all lines truncated to max_partial_line_size by a cri stage", nil).WithLabelValues(),
 	}
 	c.partialLines = make(map[model.Fingerprint]Entry, c.cfg.MaxPartialLines)
 	return &c, nil
@@ -1,10 +1,12 @@
 package stages
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -221,6 +223,63 @@ func TestCRI_tags(t *testing.T) {
 	}
 }
 
+func TestCRI_metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cfg := CRIConfig{
+		MaxPartialLines:            2,
+		MaxPartialLineSize:         10,
+		MaxPartialLineSizeTruncate: true,
+	}
+	p, err := NewCRI(util_log.Logger, cfg, registry)
+	require.NoError(t, err)
+
+	entries := []testEntry{
+		{line: "2019-05-07T18:57:50.904275087+00:00 stdout P aaaa ", labels: model.LabelSet{"foo": "bar"}},
+		{line: "2019-05-07T18:57:50.904275087+00:00 stdout P bbbbbbbb", labels: model.LabelSet{"foo": "bar"}},
+		{line: "2019-05-07T18:57:50.904275087+00:00 stdout P ccc", labels: model.LabelSet{"foo": "baz"}},
+		{line: "2019-05-07T18:57:50.904275087+00:00 stdout P ddd", labels: model.LabelSet{"foo": "qux"}}, // exceeds max_partial_lines
+		{line: "2019-05-07T18:57:55.904275087+00:00 stdout F x", labels: model.LabelSet{"foo": "qux"}},
+	}
+	got := make([]string, 0)
+	for _, entry := range entries {
+		for _, out := range processEntries(p, newEntry(nil, entry.labels, entry.line, time.Now())) {
+			got = append(got, out.Line)
+		}
+	}
+	assert.ElementsMatch(t, []string{"aaaa bbbbb", "ccc", "dddx"}, got)
+
+	expected := `
+# HELP loki_process_cri_partial_lines_flushed_total A count of all partial lines sent without waiting for their full line because max_partial_lines was exceeded
+# TYPE loki_process_cri_partial_lines_flushed_total counter
+loki_process_cri_partial_lines_flushed_total 2
+# HELP loki_process_cri_partial_lines_joined_total A count of all partial lines joined to a previous partial line by a cri stage
+# TYPE loki_process_cri_partial_lines_joined_total counter
+loki_process_cri_partial_lines_joined_total 2
+# HELP loki_process_cri_partial_lines_truncated_total A count of all lines truncated to max_partial_line_size by a cri stage
+# TYPE loki_process_cri_partial_lines_truncated_total counter
+loki_process_cri_partial_lines_truncated_total 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loki_process_cri_partial_lines_flushed_total",
+		"loki_process_cri_partial_lines_joined_total",
+		"loki_process_cri_partial_lines_truncated_total"))
+}
+
+func TestCRIConfig_Validate(t *testing.T) {
+	cfg := DefaultCRIConfig
+	require.NoError(t, cfg.Validate())
+
+	cfg.MaxPartialLines = 0
+	require.ErrorContains(t, cfg.Validate(), "max_partial_lines must be greater than 0")
+
+	cfg = DefaultCRIConfig
+	cfg.MaxPartialLineSizeTruncate = true
+	require.ErrorContains(t, cfg.Validate(), "max_partial_line_size must be greater than 0")
+
+	cfg.MaxPartialLineSize = 1024
+	require.NoError(t, cfg.Validate())
+}
+
 func TestNewCri(t *testing.T) {
 	tests := map[string]struct {
 		entry          string
@@ -0,0 +1,154 @@
+package stages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Fingerprint algorithms.
+const (
+	FingerprintAlgorithmXXHash = "xxhash"
+	FingerprintAlgorithmSHA256 = "sha256"
+)
+
+// Configuration errors.
+var (
+	ErrFingerprintInvalidAlgorithm = errors.New("fingerprint stage `algorithm` must be one of \"xxhash\" or \"sha256\"")
+	ErrFingerprintTargetRequired   = errors.New("fingerprint stage `target` must not be empty")
+	ErrEmptyFingerprintStageSource = errors.New("empty source")
+)
+
+// FingerprintConfig configures a processing stage that hashes log lines.
+type FingerprintConfig struct {
+	Source                *string  `alloy:"source,attr,optional"`
+	Algorithm             string   `alloy:"algorithm,attr,optional"`
+	Normalize             []string `alloy:"normalize,attr,optional"`
+	Target                string   `alloy:"target,attr,optional"`
+	StructuredMetadataKey string   `alloy:"structured_metadata_key,attr,optional"`
+	When                  string   `alloy:"when,attr,optional"`
+}
+
+// DefaultFingerprintConfig sets the defaults for FingerprintConfig.
+var DefaultFingerprintConfig = FingerprintConfig{
+	Algorithm: FingerprintAlgorithmXXHash,
+	Target:    "fingerprint",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *FingerprintConfig) SetToDefault() {
+	*c = DefaultFingerprintConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *FingerprintConfig) Validate() error {
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyFingerprintStageSource
+	}
+	if c.Algorithm != FingerprintAlgorithmXXHash && c.Algorithm != FingerprintAlgorithmSHA256 {
+		return ErrFingerprintInvalidAlgorithm
+	}
+	if c.Target == "" {
+		return ErrFingerprintTargetRequired
+	}
+	if c.StructuredMetadataKey != "" && !model.LabelName(c.StructuredMetadataKey).IsValid() {
+		return fmt.Errorf(ErrInvalidLabelName, c.StructuredMetadataKey)
+	}
+	return nil
+}
+
+// fingerprintStage stores a hash of the normalized log line.
+type fingerprintStage struct {
+	cfg       FingerprintConfig
+	normalize []*regexp.Regexp
+	logger    log.Logger
+}
+
+// newFingerprintStage creates a new fingerprint pipeline stage from a config.
+func newFingerprintStage(logger log.Logger, cfg FingerprintConfig) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	normalize := make([]*regexp.Regexp, 0, len(cfg.Normalize))
+	for _, expr := range cfg.Normalize {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%v for normalize expression %q: %w", ErrCouldNotCompileRegex, expr, err)
+		}
+		normalize = append(normalize, re)
+	}
+
+	return &fingerprintStage{
+		cfg:       cfg,
+		normalize: normalize,
+		logger:    log.With(logger, "component", "stage", "type", "fingerprint"),
+	}, nil
+}
+
+// Run implements Stage.
+func (f *fingerprintStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		input := e.Line
+		if f.cfg.Source != nil {
+			value, ok := e.Extracted[*f.cfg.Source]
+			if !ok {
+				if Debug {
+					level.Debug(f.logger).Log("msg", "source does not exist in the set of extracted values", "source", *f.cfg.Source)
+				}
+				return e
+			}
+			str, err := getString(value)
+			if err != nil {
+				if Debug {
+					level.Debug(f.logger).Log("msg", "failed to convert source value to string", "source", *f.cfg.Source, "err", err, "type", reflect.TypeOf(value))
+				}
+				return e
+			}
+			input = str
+		}
+
+		fingerprint := f.fingerprint(input)
+		e.Extracted[f.cfg.Target] = fingerprint
+		if f.cfg.StructuredMetadataKey != "" {
+			e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{
+				Name:  f.cfg.StructuredMetadataKey,
+				Value: fingerprint,
+			})
+		}
+		return e
+	})
+}
+
+// fingerprint removes the matches of the normalize expressions from the input
+// and returns the hex encoded hash of the result.
+func (f *fingerprintStage) fingerprint(input string) string {
+	for _, re := range f.normalize {
+		input = re.ReplaceAllString(input, "")
+	}
+	if f.cfg.Algorithm == FingerprintAlgorithmSHA256 {
+		sum := sha256.Sum256([]byte(input))
+		return hex.EncodeToString(sum[:])
+	}
+	return fmt.Sprintf("%016x", xxhash.Sum64String(input))
+}
+
+// Name implements Stage.
+func (f *fingerprintStage) Name() string {
+	return StageTypeFingerprint
+}
+
+// Cleanup implements Stage.
+func (*fingerprintStage) Cleanup() {
+	// no-op
+}
@@ -0,0 +1,117 @@
+package stages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testFingerprintAlloy = `
+stage.fingerprint {
+    normalize               = ["^\\S+ ", "id=[0-9a-f-]+"]
+    structured_metadata_key = "fingerprint"
+}
+`
+
+func TestFingerprintPipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testFingerprintAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, nil, "2024-01-01T00:00:00Z error: user not found id=4f2a-11", time.Now()),
+		newEntry(nil, nil, "2024-01-01T00:00:05Z error: user not found id=9c1b-07", time.Now()),
+		newEntry(nil, nil, "2024-01-01T00:00:07Z error: connection refused", time.Now()),
+	)
+	require.Len(t, out, 3)
+
+	expected := fmt.Sprintf("%016x", xxhash.Sum64String("error: user not found "))
+	assert.Equal(t, expected, out[0].Extracted["fingerprint"])
+	assert.Equal(t, expected, out[1].Extracted["fingerprint"])
+	assert.NotEqual(t, expected, out[2].Extracted["fingerprint"])
+	assert.Equal(t, []logproto.LabelAdapter{{Name: "fingerprint", Value: expected}}, []logproto.LabelAdapter(out[0].StructuredMetadata))
+}
+
+func TestFingerprintStage(t *testing.T) {
+	sum := sha256.Sum256([]byte("GET /users"))
+	source := "request"
+
+	tests := map[string]struct {
+		config    FingerprintConfig
+		extracted map[string]interface{}
+		expected  map[string]interface{}
+	}{
+		"sha256 of source": {
+			FingerprintConfig{Source: &source, Algorithm: FingerprintAlgorithmSHA256, Target: "hash"},
+			map[string]interface{}{"request": "GET /users"},
+			map[string]interface{}{"request": "GET /users", "hash": hex.EncodeToString(sum[:])},
+		},
+		"missing source": {
+			FingerprintConfig{Source: &source, Algorithm: FingerprintAlgorithmSHA256, Target: "hash"},
+			map[string]interface{}{},
+			map[string]interface{}{},
+		},
+		"xxhash is zero padded": {
+			FingerprintConfig{Algorithm: FingerprintAlgorithmXXHash, Target: "fp"},
+			map[string]interface{}{},
+			map[string]interface{}{"fp": fmt.Sprintf("%016x", xxhash.Sum64String("line"))},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			st, err := newFingerprintStage(util.TestAlloyLogger(t), tt.config)
+			require.NoError(t, err)
+			out := processEntries(st, newEntry(tt.extracted, nil, "line", time.Now()))[0]
+			assert.Equal(t, tt.expected, out.Extracted)
+		})
+	}
+}
+
+func TestFingerprintConfig_Validate(t *testing.T) {
+	empty := ""
+	tests := map[string]struct {
+		config FingerprintConfig
+		err    error
+	}{
+		"empty source": {
+			FingerprintConfig{Source: &empty, Algorithm: FingerprintAlgorithmXXHash, Target: "fingerprint"},
+			ErrEmptyFingerprintStageSource,
+		},
+		"invalid algorithm": {
+			FingerprintConfig{Algorithm: "md5", Target: "fingerprint"},
+			ErrFingerprintInvalidAlgorithm,
+		},
+		"empty target": {
+			FingerprintConfig{Algorithm: FingerprintAlgorithmXXHash},
+			ErrFingerprintTargetRequired,
+		},
+		"valid": {
+			DefaultFingerprintConfig,
+			nil,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, tt.config.Validate(), tt.err)
+		})
+	}
+
+	cfg := DefaultFingerprintConfig
+	cfg.StructuredMetadataKey = "invalid-name"
+	require.Error(t, cfg.Validate())
+
+	cfg = DefaultFingerprintConfig
+	cfg.Normalize = []string{"("}
+	_, err := newFingerprintStage(util.TestAlloyLogger(t), cfg)
+	require.ErrorContains(t, err, ErrCouldNotCompileRegex.Error())
+}
@@ -4,8 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-kit/log"
 	"github.com/jmespath/go-jmespath"
 	"github.com/oschwald/geoip2-golang"
@@ -20,6 +26,7 @@ var (
 	ErrEmptySourceGeoIPStageConfig          = errors.New("source cannot be empty")
 	ErrEmptyDBTypeGeoIPStageConfig          = errors.New("db type should be either city or asn")
 	ErrEmptyDBTypeAndValuesGeoIPStageConfig = errors.New("db type or values need to be set")
+	ErrFieldsWithoutDBTypeGeoIPStageConfig  = errors.New("fields can only be set along with db type")
 )
 
 type GeoIPFields int
@@ -54,14 +61,35 @@ var fields = map[GeoIPFields]string{
 	ASNORG:          "geoip_autonomous_system_organization",
 }
 
+// dbTypeFields are the fields populated for each DB type.
+var dbTypeFields = map[string][]GeoIPFields{
+	"city":    {CITYNAME, COUNTRYNAME, COUNTRYCODE, CONTINENTNAME, CONTINENTCODE, LOCATION, POSTALCODE, TIMEZONE, SUBDIVISIONNAME, SUBDIVISIONCODE},
+	"country": {COUNTRYNAME, COUNTRYCODE, CONTINENTNAME, CONTINENTCODE},
+	"asn":     {ASN, ASNORG},
+}
+
+// fieldName returns the name of a field in the fields argument, which is the
+// name of the extracted value without the geoip_ prefix.
+func fieldName(field GeoIPFields) string {
+	return strings.TrimPrefix(fields[field], "geoip_")
+}
+
 // GeoIPConfig represents GeoIP stage config
 type GeoIPConfig struct {
 	DB            string            `alloy:"db,attr"`
 	Source        *string           `alloy:"source,attr"`
 	DBType        string            `alloy:"db_type,attr,optional"`
+	Fields        []string          `alloy:"fields,attr,optional"`
 	CustomLookups map[string]string `alloy:"custom_lookups,attr,optional"`
+	WatchDB       bool              `alloy:"watch_db,attr,optional"`
+	When          string            `alloy:"when,attr,optional"`
 }
 
+// geoIPReloadDelay is how long to wait after the last change to the DB file
+// before reopening it, so that a file which is still being written is not
+// picked up.
+var geoIPReloadDelay = time.Second
+
 func validateGeoIPConfig(c GeoIPConfig) (map[string]*jmespath.JMESPath, error) {
 	if c.DB == "" {
 		return nil, ErrEmptyDBPathGeoIPStageConfig
@@ -80,6 +108,15 @@ func validateGeoIPConfig(c GeoIPConfig) (map[string]*jmespath.JMESPath, error) {
 		return nil, ErrEmptyDBTypeGeoIPStageConfig
 	}
 
+	if len(c.Fields) > 0 && c.DBType == "" {
+		return nil, ErrFieldsWithoutDBTypeGeoIPStageConfig
+	}
+	for _, name := range c.Fields {
+		if !slices.ContainsFunc(dbTypeFields[c.DBType], func(f GeoIPFields) bool { return fieldName(f) == name }) {
+			return nil, fmt.Errorf("field %q is not available for db type %q", name, c.DBType)
+		}
+	}
+
 	if c.CustomLookups == nil {
 		return nil, nil
 	}
@@ -113,19 +150,34 @@ func newGeoIPStage(logger log.Logger, config GeoIPConfig) (Stage, error) {
 		return nil, err
 	}
 
-	return &geoIPStage{
+	g := &geoIPStage{
 		mmdb:              mmdb,
 		logger:            logger,
 		cfgs:              config,
 		valuesExpressions: valuesExpressions,
-	}, nil
+	}
+
+	if config.WatchDB {
+		if err := g.startWatcher(); err != nil {
+			_ = mmdb.Close()
+			return nil, err
+		}
+	}
+	return g, nil
 }
 
 type geoIPStage struct {
 	logger            log.Logger
-	mmdb              *maxminddb.Reader
 	cfgs              GeoIPConfig
 	valuesExpressions map[string]*jmespath.JMESPath
+
+	// mu guards mmdb, which is swapped when the DB file changes.
+	mu   sync.RWMutex
+	mmdb *maxminddb.Reader
+
+	watcher     *fsnotify.Watcher
+	watcherDone chan struct{}
+	closeOnce   sync.Once
 }
 
 // Run implements Stage
@@ -135,6 +187,10 @@ func (g *geoIPStage) Run(in chan Entry) chan Entry {
 		defer close(out)
 		defer g.close()
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			g.process(e.Labels, e.Extracted)
 			out <- e
 		}
@@ -175,6 +231,9 @@ func (g *geoIPStage) process(_ model.LabelSet, extracted map[string]interface{})
 			return
 		}
 	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	if g.cfgs.DBType != "" {
 		switch g.cfgs.DBType {
 		case "city":
@@ -211,13 +270,100 @@ func (g *geoIPStage) process(_ model.LabelSet, extracted map[string]interface{})
 }
 
 func (g *geoIPStage) close() {
-	if err := g.mmdb.Close(); err != nil {
+	g.closeOnce.Do(func() {
+		if g.watcher != nil {
+			if err := g.watcher.Close(); err != nil {
+				level.Error(g.logger).Log("msg", "error while closing mmdb watcher", "err", err)
+			}
+			<-g.watcherDone
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if err := g.mmdb.Close(); err != nil {
+			level.Error(g.logger).Log("msg", "error while closing mmdb", "err", err)
+		}
+	})
+}
+
+// startWatcher watches the directory of the DB file, rather than the file
+// itself, so that the DB is reloaded when the file is atomically replaced.
+func (g *geoIPStage) startWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create mmdb watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(g.cfgs.DB)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch mmdb: %w", err)
+	}
+
+	g.watcher = watcher
+	g.watcherDone = make(chan struct{})
+	go g.watch()
+	return nil
+}
+
+func (g *geoIPStage) watch() {
+	defer close(g.watcherDone)
+
+	dbPath := filepath.Clean(g.cfgs.DB)
+	reload := time.NewTimer(geoIPReloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+
+	for {
+		select {
+		case event, ok := <-g.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != dbPath || !event.Has(fsnotify.Create|fsnotify.Write) {
+				continue
+			}
+			reload.Reset(geoIPReloadDelay)
+		case err, ok := <-g.watcher.Errors:
+			if !ok {
+				return
+			}
+			level.Error(g.logger).Log("msg", "error while watching mmdb", "err", err)
+		case <-reload.C:
+			g.reload()
+		}
+	}
+}
+
+// reload reopens the DB file and swaps it with the current one. If the new
+// file can't be opened, the current DB keeps being used.
+func (g *geoIPStage) reload() {
+	mmdb, err := maxminddb.Open(g.cfgs.DB)
+	if err != nil {
+		level.Error(g.logger).Log("msg", "failed to reload mmdb, keeping the previous one", "db", g.cfgs.DB, "err", err)
+		return
+	}
+
+	g.mu.Lock()
+	old := g.mmdb
+	g.mmdb = mmdb
+	g.mu.Unlock()
+
+	if err := old.Close(); err != nil {
 		level.Error(g.logger).Log("msg", "error while closing mmdb", "err", err)
 	}
+	level.Info(g.logger).Log("msg", "reloaded mmdb", "db", g.cfgs.DB, "build_epoch", mmdb.Metadata.BuildEpoch)
+}
+
+// selected returns whether a field is populated. All the fields of the DB
+// type are populated if the fields argument isn't set.
+func (g *geoIPStage) selected(field GeoIPFields) bool {
+	return len(g.cfgs.Fields) == 0 || slices.Contains(g.cfgs.Fields, fieldName(field))
 }
 
 func (g *geoIPStage) populateExtractedWithCityData(extracted map[string]interface{}, record *geoip2.City) {
 	for field, label := range fields {
+		if !g.selected(field) {
+			continue
+		}
 		switch field {
 		case CITYNAME:
 			cityName := record.City.Names["en"]
@@ -282,6 +428,9 @@ func (g *geoIPStage) populateExtractedWithCityData(extracted map[string]interfac
 
 func (g *geoIPStage) populateExtractedWithASNData(extracted map[string]interface{}, record *geoip2.ASN) {
 	for field, label := range fields {
+		if !g.selected(field) {
+			continue
+		}
 		switch field {
 		case ASN:
 			autonomousSystemNumber := record.AutonomousSystemNumber
@@ -299,6 +448,9 @@ func (g *geoIPStage) populateExtractedWithASNData(extracted map[string]interface
 
 func (g *geoIPStage) populateExtractedWithCountryData(extracted map[string]interface{}, record *geoip2.Country) {
 	for field, label := range fields {
+		if !g.selected(field) {
+			continue
+		}
 		switch field {
 		case COUNTRYNAME:
 			contryName := record.Country.Names["en"]
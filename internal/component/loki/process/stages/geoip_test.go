@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/oschwald/geoip2-golang"
@@ -88,6 +91,33 @@ func Test_ValidateConfigs(t *testing.T) {
 			},
 			errors.New(ErrCouldNotCompileJMES),
 		},
+		{
+			GeoIPConfig{
+				DB:     "test",
+				Source: &source,
+				DBType: "city",
+				Fields: []string{"country_code", "location"},
+			},
+			nil,
+		},
+		{
+			GeoIPConfig{
+				DB:            "test",
+				Source:        &source,
+				Fields:        []string{"country_code"},
+				CustomLookups: map[string]string{"field": "lookup"},
+			},
+			ErrFieldsWithoutDBTypeGeoIPStageConfig,
+		},
+		{
+			GeoIPConfig{
+				DB:     "test",
+				Source: &source,
+				DBType: "country",
+				Fields: []string{"city_name"},
+			},
+			errors.New(`field "city_name" is not available for db type "country"`),
+		},
 	}
 	for _, tt := range tests {
 		_, err := validateGeoIPConfig(tt.config)
@@ -204,6 +234,43 @@ func Test_MaxmindCity(t *testing.T) {
 	}
 }
 
+func Test_MaxmindCityFields(t *testing.T) {
+	mmdb, err := maxminddb.Open("testdata/geoip_maxmind_city.mmdb")
+	require.NoError(t, err)
+	defer mmdb.Close()
+
+	var record geoip2.City
+	require.NoError(t, mmdb.Lookup(net.ParseIP(geoipTestIP), &record))
+
+	config := GeoIPConfig{
+		DB:     "test",
+		Source: &geoipTestSource,
+		DBType: "city",
+		Fields: []string{"country_code", "city_name", "location"},
+	}
+	_, err = validateGeoIPConfig(config)
+	require.NoError(t, err)
+	testStage := &geoIPStage{
+		mmdb:   mmdb,
+		logger: util_log.Logger,
+		cfgs:   config,
+	}
+
+	extracted := map[string]interface{}{}
+	testStage.populateExtractedWithCityData(extracted, &record)
+
+	keys := make([]string, 0, len(extracted))
+	for k := range extracted {
+		keys = append(keys, k)
+	}
+	require.ElementsMatch(t, []string{
+		fields[COUNTRYCODE],
+		fields[CITYNAME],
+		fmt.Sprintf("%s_latitude", fields[LOCATION]),
+		fmt.Sprintf("%s_longitude", fields[LOCATION]),
+	}, keys)
+}
+
 func Test_MaxmindCountry(t *testing.T) {
 	mmdb, err := maxminddb.Open("testdata/geoip_maxmind_country.mmdb")
 	if err != nil {
@@ -249,3 +316,52 @@ func Test_MaxmindCountry(t *testing.T) {
 		}
 	}
 }
+
+func Test_GeoIPReloadsChangedDB(t *testing.T) {
+	defer func(d time.Duration) { geoIPReloadDelay = d }(geoIPReloadDelay)
+	geoIPReloadDelay = 10 * time.Millisecond
+
+	copyFile := func(src, dst string) {
+		data, err := os.ReadFile(src)
+		require.NoError(t, err)
+		// Replace the file atomically, like DB updaters do.
+		require.NoError(t, os.WriteFile(dst+".tmp", data, 0o644))
+		require.NoError(t, os.Rename(dst+".tmp", dst))
+	}
+
+	db := filepath.Join(t.TempDir(), "GeoLite2.mmdb")
+	copyFile("testdata/geoip_maxmind_city.mmdb", db)
+
+	source := "ip"
+	st, err := newGeoIPStage(util_log.Logger, GeoIPConfig{
+		DB:      db,
+		Source:  &source,
+		DBType:  "asn",
+		WatchDB: true,
+	})
+	require.NoError(t, err)
+	g := st.(*geoIPStage)
+	defer g.close()
+
+	databaseType := func() string {
+		g.mu.RLock()
+		defer g.mu.RUnlock()
+		return g.mmdb.Metadata.DatabaseType
+	}
+	require.Equal(t, "GeoIP2-City", databaseType())
+
+	copyFile("testdata/geoip_maxmind_asn.mmdb", db)
+	require.Eventually(t, func() bool {
+		return databaseType() == "GeoLite2-ASN"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	extracted := map[string]interface{}{source: geoipTestIP}
+	g.process(nil, extracted)
+	require.Contains(t, extracted, fields[ASN])
+	require.Contains(t, extracted, fields[ASNORG])
+
+	// A broken file is ignored and the previous DB is kept.
+	require.NoError(t, os.WriteFile(db, []byte("not a mmdb"), 0o644))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, "GeoLite2-ASN", databaseType())
+}
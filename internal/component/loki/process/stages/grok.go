@@ -0,0 +1,201 @@
+package stages
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-grok"
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrGrokPatternsRequired  = errors.New("at least one grok pattern is required")
+	ErrEmptyGrokStageSource  = errors.New("empty source")
+	ErrCouldNotCompileGrok   = errors.New("could not compile grok pattern")
+	ErrCouldNotLoadGrokFile  = errors.New("could not load grok pattern file")
+	ErrInvalidGrokDefinition = errors.New("invalid grok pattern definition")
+)
+
+// GrokConfig configures a processing stage that uses grok patterns to
+// extract values from log lines into the shared values map.
+type GrokConfig struct {
+	Patterns           []string          `alloy:"patterns,attr"`
+	PatternDefinitions map[string]string `alloy:"pattern_definitions,attr,optional"`
+	PatternFiles       []string          `alloy:"pattern_files,attr,optional"`
+	NamedCapturesOnly  bool              `alloy:"named_captures_only,attr,optional"`
+	Source             *string           `alloy:"source,attr,optional"`
+	When               string            `alloy:"when,attr,optional"`
+}
+
+// DefaultGrokConfig sets the defaults for GrokConfig.
+var DefaultGrokConfig = GrokConfig{
+	NamedCapturesOnly: true,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *GrokConfig) SetToDefault() {
+	*c = DefaultGrokConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *GrokConfig) Validate() error {
+	if len(c.Patterns) == 0 {
+		return ErrGrokPatternsRequired
+	}
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyGrokStageSource
+	}
+	return nil
+}
+
+// validateGrokConfig validates the config and returns one compiled grok
+// parser per configured pattern, in the order they are configured.
+func validateGrokConfig(c GrokConfig) ([]*grok.Grok, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	definitions := make(map[string]string)
+	for _, path := range c.PatternFiles {
+		fileDefinitions, err := loadGrokPatternFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%v %q: %w", ErrCouldNotLoadGrokFile, path, err)
+		}
+		for name, def := range fileDefinitions {
+			definitions[name] = def
+		}
+	}
+	// Inline definitions take precedence over the ones loaded from files.
+	for name, def := range c.PatternDefinitions {
+		definitions[name] = def
+	}
+
+	parsers := make([]*grok.Grok, 0, len(c.Patterns))
+	for _, pattern := range c.Patterns {
+		g, err := grok.NewComplete(definitions)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", ErrInvalidGrokDefinition, err)
+		}
+		if err := g.Compile(pattern, c.NamedCapturesOnly); err != nil {
+			return nil, fmt.Errorf("%v %q: %w", ErrCouldNotCompileGrok, pattern, err)
+		}
+		parsers = append(parsers, g)
+	}
+
+	return parsers, nil
+}
+
+// loadGrokPatternFile reads a Logstash-style pattern file, where each
+// non-empty line that is not a comment is of the form `NAME PATTERN`.
+func loadGrokPatternFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	definitions := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, def, ok := strings.Cut(line, " ")
+		def = strings.TrimSpace(def)
+		if !ok || def == "" {
+			// Don't quote the line, the file may not be a pattern file at all.
+			return nil, fmt.Errorf("%w on line %d", ErrInvalidGrokDefinition, lineNo)
+		}
+		definitions[name] = def
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// grokStage sets extracted data using grok patterns.
+type grokStage struct {
+	stageErrors
+
+	config  *GrokConfig
+	parsers []*grok.Grok
+	logger  log.Logger
+}
+
+// newGrokStage creates a new grok pipeline stage from a config.
+func newGrokStage(logger log.Logger, config GrokConfig) (Stage, error) {
+	parsers, err := validateGrokConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return toStage(&grokStage{
+		config:  &config,
+		parsers: parsers,
+		logger:  log.With(logger, "component", "stage", "type", "grok"),
+	}), nil
+}
+
+// Process implements Stage
+func (g *grokStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the grok stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if g.config.Source != nil {
+		if _, ok := extracted[*g.config.Source]; !ok {
+			level.Debug(g.logger).Log("msg", "source does not exist in the set of extracted values", "source", *g.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*g.config.Source])
+		if err != nil {
+			level.Debug(g.logger).Log("msg", "failed to convert source value to string", "source", *g.config.Source, "err", err, "type", reflect.TypeOf(extracted[*g.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(g.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	// The first pattern which matches wins, the same way Logstash's grok
+	// filter behaves with break_on_match enabled.
+	for _, parser := range g.parsers {
+		if !parser.MatchString(*input) {
+			continue
+		}
+		captures, err := parser.ParseTypedString(*input)
+		if err != nil {
+			level.Debug(g.logger).Log("msg", "failed to parse grok captures", "err", err)
+			g.reportError()
+			return
+		}
+		for name, value := range captures {
+			extracted[name] = value
+		}
+		level.Debug(g.logger).Log("msg", "extracted data debug in grok stage", "extracted data", fmt.Sprintf("%v", extracted))
+		return
+	}
+
+	level.Debug(g.logger).Log("msg", "none of the grok patterns matched", "input", *input)
+}
+
+// Name implements Stage
+func (g *grokStage) Name() string {
+	return StageTypeGrok
+}
@@ -0,0 +1,165 @@
+package stages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testGrokAlloySingleStageWithoutSource = `
+stage.grok {
+    patterns = ["%{COMMONAPACHELOG}"]
+}
+`
+
+var testGrokAlloyMultiPatternWithSource = `
+stage.grok {
+    patterns = ["%{IPORHOST:ip} %{USER:ident} %{USER:user} \\[%{HTTPDATE:timestamp}\\] \"%{WORD:action} %{NOTSPACE:path} HTTP/%{NUMBER:protocol_version}\" %{NUMBER:status:int} %{NUMBER:size:int}"]
+}
+stage.grok {
+    patterns = [
+        "^/%{INT:year}\\.css$",
+        "^/%{INT:year}\\.%{EXT:extension}$",
+    ]
+    pattern_definitions = { "EXT" = "[a-z]+" }
+    source              = "path"
+}
+`
+
+var testGrokLogLine = `11.11.11.11 - frank [25/Jan/2000:14:00:01 -0500] "GET /1986.js HTTP/1.1" 200 932`
+
+func TestPipeline_Grok(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"successfully run a pipeline with 1 grok stage using built-in patterns": {
+			testGrokAlloySingleStageWithoutSource,
+			testGrokLogLine,
+			map[string]interface{}{
+				"source.address":            "11.11.11.11",
+				"user.name":                 "frank",
+				"timestamp":                 "25/Jan/2000:14:00:01 -0500",
+				"http.request.method":       "GET",
+				"url.original":              "/1986.js",
+				"http.version":              "1.1",
+				"http.response.status_code": 200,
+				"http.response.body.size":   932,
+			},
+		},
+		"successfully run a pipeline with 2 grok stages, custom definitions and source": {
+			testGrokAlloyMultiPatternWithSource,
+			testGrokLogLine,
+			map[string]interface{}{
+				"ip":               "11.11.11.11",
+				"ident":            "-",
+				"user":             "frank",
+				"timestamp":        "25/Jan/2000:14:00:01 -0500",
+				"action":           "GET",
+				"path":             "/1986.js",
+				"protocol_version": "1.1",
+				"status":           200,
+				"size":             932,
+				"year":             "1986",
+				"extension":        "js",
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger := util.TestAlloyLogger(t)
+			pl, err := NewPipeline(logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestGrokConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	emptySource := ""
+	tests := map[string]struct {
+		config GrokConfig
+		err    error
+	}{
+		"no patterns": {
+			GrokConfig{},
+			ErrGrokPatternsRequired,
+		},
+		"empty source": {
+			GrokConfig{Patterns: []string{"%{WORD:word}"}, Source: &emptySource},
+			ErrEmptyGrokStageSource,
+		},
+		"unknown pattern": {
+			GrokConfig{Patterns: []string{"%{NOT_A_PATTERN:word}"}},
+			ErrCouldNotCompileGrok,
+		},
+		"missing pattern file": {
+			GrokConfig{Patterns: []string{"%{WORD:word}"}, PatternFiles: []string{"/does/not/exist"}},
+			ErrCouldNotLoadGrokFile,
+		},
+		"valid": {
+			GrokConfig{Patterns: []string{"%{WORD:word}"}},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			_, err := validateGrokConfig(tt.config)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGrokPatternFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom")
+	content := "# Custom patterns\n\nTICKET [A-Z]+-%{INT}\nOWNER %{WORD}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	st, err := newGrokStage(util.TestAlloyLogger(t), GrokConfig{
+		Patterns:           []string{"%{TICKET:ticket} assigned to %{OWNER:owner}"},
+		PatternDefinitions: map[string]string{"OWNER": "@%{WORD}"},
+		PatternFiles:       []string{path},
+		NamedCapturesOnly:  true,
+	})
+	require.NoError(t, err)
+
+	out := processEntries(st, newEntry(nil, nil, "LOKI-1234 assigned to @frank", time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"ticket": "LOKI-1234",
+		"owner":  "@frank",
+	}, out.Extracted)
+
+	require.NoError(t, os.WriteFile(path, []byte("BROKEN\n"), 0o644))
+	_, err = validateGrokConfig(GrokConfig{Patterns: []string{"%{WORD}"}, PatternFiles: []string{path}})
+	require.ErrorIs(t, err, ErrInvalidGrokDefinition)
+	require.ErrorContains(t, err, "on line 1")
+	require.NotContains(t, err.Error(), "BROKEN")
+}
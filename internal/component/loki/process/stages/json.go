@@ -18,13 +18,20 @@ const (
 	ErrEmptyJSONStageConfig = "empty json stage configuration"
 	ErrEmptyJSONStageSource = "empty source"
 	ErrMalformedJSON        = "malformed json"
+	ErrInvalidFlattenDepth  = "flatten_max_depth must not be negative"
 )
 
+const defaultFlattenSeparator = "."
+
 // JSONConfig represents a JSON Stage configuration
 type JSONConfig struct {
-	Expressions   map[string]string `alloy:"expressions,attr"`
-	Source        *string           `alloy:"source,attr,optional"`
-	DropMalformed bool              `alloy:"drop_malformed,attr,optional"`
+	Expressions      map[string]string `alloy:"expressions,attr,optional"`
+	Source           *string           `alloy:"source,attr,optional"`
+	DropMalformed    bool              `alloy:"drop_malformed,attr,optional"`
+	Flatten          bool              `alloy:"flatten,attr,optional"`
+	FlattenSeparator string            `alloy:"flatten_separator,attr,optional"`
+	FlattenMaxDepth  int               `alloy:"flatten_max_depth,attr,optional"`
+	When             string            `alloy:"when,attr,optional"`
 }
 
 // validateJSONConfig validates a json config and returns a map of necessary jmespath expressions.
@@ -33,10 +40,18 @@ func validateJSONConfig(c *JSONConfig) (map[string]*jmespath.JMESPath, error) {
 		return nil, errors.New(ErrEmptyJSONStageConfig)
 	}
 
-	if len(c.Expressions) == 0 {
+	if len(c.Expressions) == 0 && !c.Flatten {
 		return nil, errors.New(ErrExpressionsRequired)
 	}
 
+	if c.FlattenSeparator == "" {
+		c.FlattenSeparator = defaultFlattenSeparator
+	}
+
+	if c.FlattenMaxDepth < 0 {
+		return nil, errors.New(ErrInvalidFlattenDepth)
+	}
+
 	if c.Source != nil && *c.Source == "" {
 		return nil, errors.New(ErrEmptyJSONStageSource)
 	}
@@ -60,6 +75,8 @@ func validateJSONConfig(c *JSONConfig) (map[string]*jmespath.JMESPath, error) {
 
 // jsonStage sets extracted data using JMESPath expressions
 type jsonStage struct {
+	stageErrors
+
 	cfg         *JSONConfig
 	expressions map[string]*jmespath.JMESPath
 	logger      log.Logger
@@ -83,6 +100,10 @@ func (j *jsonStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			err := j.processEntry(e.Extracted, &e.Line)
 			if err != nil && j.cfg.DropMalformed {
 				continue
@@ -130,9 +151,14 @@ func (j *jsonStage) processEntry(extracted map[string]interface{}, entry *string
 		if Debug {
 			level.Debug(j.logger).Log("msg", "failed to unmarshal log line", "err", err)
 		}
+		j.reportError()
 		return errors.New(ErrMalformedJSON)
 	}
 
+	if j.cfg.Flatten {
+		j.flatten(extracted, "", data, 1)
+	}
+
 	for n, e := range j.expressions {
 		r, err := e.Search(data)
 		if err != nil {
@@ -141,28 +167,7 @@ func (j *jsonStage) processEntry(extracted map[string]interface{}, entry *string
 			}
 			continue
 		}
-
-		switch r.(type) {
-		case float64:
-			// All numbers in JSON are unmarshaled to float64.
-			extracted[n] = r
-		case string:
-			extracted[n] = r
-		case bool:
-			extracted[n] = r
-		case nil:
-			extracted[n] = nil
-		default:
-			// If the value wasn't a string or a number, marshal it back to json
-			jm, err := json.Marshal(r)
-			if err != nil {
-				if Debug {
-					level.Debug(j.logger).Log("msg", "failed to marshal complex type back to string", "err", err)
-				}
-				continue
-			}
-			extracted[n] = string(jm)
-		}
+		j.setExtracted(extracted, n, r)
 	}
 	if Debug {
 		level.Debug(j.logger).Log("msg", "extracted data debug in json stage", "extracted data", fmt.Sprintf("%v", extracted))
@@ -170,6 +175,48 @@ func (j *jsonStage) processEntry(extracted map[string]interface{}, entry *string
 	return nil
 }
 
+// flatten recursively stores every field of the object in the extracted
+// map, joining the keys of nested objects with the configured separator.
+// Objects nested deeper than the maximum depth are stored as JSON strings.
+func (j *jsonStage) flatten(extracted map[string]interface{}, prefix string, obj map[string]interface{}, depth int) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + j.cfg.FlattenSeparator + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && (j.cfg.FlattenMaxDepth == 0 || depth < j.cfg.FlattenMaxDepth) {
+			j.flatten(extracted, key, nested, depth+1)
+			continue
+		}
+		j.setExtracted(extracted, key, v)
+	}
+}
+
+// setExtracted stores a value decoded from JSON in the extracted map.
+func (j *jsonStage) setExtracted(extracted map[string]interface{}, name string, value interface{}) {
+	switch value.(type) {
+	case float64:
+		// All numbers in JSON are unmarshaled to float64.
+		extracted[name] = value
+	case string:
+		extracted[name] = value
+	case bool:
+		extracted[name] = value
+	case nil:
+		extracted[name] = nil
+	default:
+		// If the value wasn't a string or a number, marshal it back to json
+		jm, err := json.Marshal(value)
+		if err != nil {
+			if Debug {
+				level.Debug(j.logger).Log("msg", "failed to marshal complex type back to string", "err", err)
+			}
+			return
+		}
+		extracted[name] = string(jm)
+	}
+}
+
 // Name implements Stage
 func (j *jsonStage) Name() string {
 	return StageTypeJSON
@@ -30,6 +30,40 @@ stage.json {
 	source      = "extra"
 }`
 
+var testJSONAlloyFlatten = `
+stage.json {
+    flatten = true
+}`
+
+var testJSONAlloyFlattenWithOptions = `
+stage.json {
+    expressions = { "data" = "" }
+}
+
+stage.json {
+    flatten           = true
+    flatten_separator = "_"
+    flatten_max_depth = 2
+    source            = "data"
+}`
+
+var testJSONNestedLogLine = `
+{
+	"app": "loki",
+	"component": ["parser", "type"],
+	"data": {
+		"user": {"name": "frank", "id": 12, "address": {"city": "Athens"}},
+		"ok": true
+	}
+}
+`
+
+var testJSONNestedStringLogLine = `
+{
+	"data": "{\"user\": {\"name\": \"frank\", \"id\": 12, \"address\": {\"city\": \"Athens\"}}, \"ok\": true}"
+}
+`
+
 var testJSONLogLine = `
 {
 	"time":"2012-11-01T22:08:41+00:00",
@@ -71,6 +105,29 @@ func TestPipeline_JSON(t *testing.T) {
 				"user":  "marco",
 			},
 		},
+		"successfully run a pipeline with a flattening json stage": {
+			testJSONAlloyFlatten,
+			testJSONNestedLogLine,
+			map[string]interface{}{
+				"app":                    "loki",
+				"component":              `["parser","type"]`,
+				"data.user.name":         "frank",
+				"data.user.id":           float64(12),
+				"data.user.address.city": "Athens",
+				"data.ok":                true,
+			},
+		},
+		"successfully run a pipeline with a flattening json stage with options": {
+			testJSONAlloyFlattenWithOptions,
+			testJSONNestedStringLogLine,
+			map[string]interface{}{
+				"data":         `{"user": {"name": "frank", "id": 12, "address": {"city": "Athens"}}, "ok": true}`,
+				"user_name":    "frank",
+				"user_id":      float64(12),
+				"user_address": `{"city":"Athens"}`,
+				"ok":           true,
+			},
+		},
 	}
 
 	for testName, testData := range tests {
@@ -163,6 +220,21 @@ func TestJSONConfig_validate(t *testing.T) {
 			3,
 			nil,
 		},
+		"invalid flatten depth": {
+			&JSONConfig{
+				Flatten:         true,
+				FlattenMaxDepth: -1,
+			},
+			0,
+			errors.New(ErrInvalidFlattenDepth),
+		},
+		"valid flatten without expressions": {
+			&JSONConfig{
+				Flatten: true,
+			},
+			0,
+			nil,
+		},
 		"valid with source": {
 			&JSONConfig{
 				Expressions: map[string]string{
@@ -321,6 +393,22 @@ func TestJSONParser_Parse(t *testing.T) {
 				"log": "not a json",
 			},
 		},
+		"filter and projection": {
+			StageConfig{JSONConfig: &JSONConfig{
+				Expressions: map[string]string{
+					"slow_spans": "spans[?duration > `100`].name",
+					"first_span": "spans[0].name",
+					"span_count": "length(spans)",
+				},
+			}},
+			map[string]interface{}{},
+			`{"spans":[{"name":"db","duration":150},{"name":"cache","duration":5},{"name":"http","duration":300}]}`,
+			map[string]interface{}{
+				"slow_spans": `["db","http"]`,
+				"first_span": "db",
+				"span_count": 3.0,
+			},
+		},
 		"nil source": {
 			StageConfig{JSONConfig: &JSONConfig{
 				Expressions: map[string]string{
@@ -0,0 +1,226 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors.
+var (
+	ErrKeyValueEmptyPairDelimiter = errors.New("key_value stage `pair_delimiter` must not be empty")
+	ErrKeyValueEmptyKVDelimiter   = errors.New("key_value stage `key_value_delimiter` must not be empty")
+	ErrKeyValueSameDelimiters     = errors.New("key_value stage `pair_delimiter` and `key_value_delimiter` must be different")
+	ErrKeyValueInvalidQuote       = errors.New("key_value stage `quote` must be a single character")
+	ErrEmptyKeyValueStageSource   = errors.New("empty source")
+	errKeyValueUnterminatedQuote  = errors.New("unterminated quoted string")
+	errKeyValueMissingKVDelimiter = errors.New("pair is missing the key-value delimiter")
+	errKeyValueEmptyKey           = errors.New("pair has an empty key")
+)
+
+// KeyValueConfig configures a processing stage that parses key-value pairs
+// from log lines into the extracted values map.
+type KeyValueConfig struct {
+	PairDelimiter     string   `alloy:"pair_delimiter,attr,optional"`
+	KeyValueDelimiter string   `alloy:"key_value_delimiter,attr,optional"`
+	Quote             string   `alloy:"quote,attr,optional"`
+	Keys              []string `alloy:"keys,attr,optional"`
+	TrimSpace         bool     `alloy:"trim_space,attr,optional"`
+	Source            *string  `alloy:"source,attr,optional"`
+	When              string   `alloy:"when,attr,optional"`
+}
+
+// DefaultKeyValueConfig sets the defaults for KeyValueConfig.
+var DefaultKeyValueConfig = KeyValueConfig{
+	PairDelimiter:     " ",
+	KeyValueDelimiter: "=",
+	Quote:             `"`,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *KeyValueConfig) SetToDefault() {
+	*c = DefaultKeyValueConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *KeyValueConfig) Validate() error {
+	if c.PairDelimiter == "" {
+		return ErrKeyValueEmptyPairDelimiter
+	}
+	if c.KeyValueDelimiter == "" {
+		return ErrKeyValueEmptyKVDelimiter
+	}
+	if c.PairDelimiter == c.KeyValueDelimiter {
+		return ErrKeyValueSameDelimiters
+	}
+	if len([]rune(c.Quote)) > 1 {
+		return ErrKeyValueInvalidQuote
+	}
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyKeyValueStageSource
+	}
+	return nil
+}
+
+// keyValueStage sets extracted data from key-value pairs.
+type keyValueStage struct {
+	stageErrors
+
+	config *KeyValueConfig
+	keys   map[string]struct{}
+	logger log.Logger
+}
+
+// newKeyValueStage creates a new key_value pipeline stage from a config.
+func newKeyValueStage(logger log.Logger, config KeyValueConfig) (Stage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var keys map[string]struct{}
+	if len(config.Keys) > 0 {
+		keys = make(map[string]struct{}, len(config.Keys))
+		for _, k := range config.Keys {
+			keys[k] = struct{}{}
+		}
+	}
+
+	return toStage(&keyValueStage{
+		config: &config,
+		keys:   keys,
+		logger: log.With(logger, "component", "stage", "type", "key_value"),
+	}), nil
+}
+
+// Process implements Stage
+func (kv *keyValueStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the key_value stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if kv.config.Source != nil {
+		if _, ok := extracted[*kv.config.Source]; !ok {
+			level.Debug(kv.logger).Log("msg", "source does not exist in the set of extracted values", "source", *kv.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*kv.config.Source])
+		if err != nil {
+			level.Debug(kv.logger).Log("msg", "failed to convert source value to string", "source", *kv.config.Source, "err", err, "type", reflect.TypeOf(extracted[*kv.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(kv.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	pairs, err := kv.splitPairs(*input)
+	if err != nil {
+		level.Debug(kv.logger).Log("msg", "failed to split key-value pairs", "err", err)
+		kv.reportError()
+		return
+	}
+
+	for _, pair := range pairs {
+		key, value, err := kv.parsePair(pair)
+		if err != nil {
+			level.Debug(kv.logger).Log("msg", "skipping invalid key-value pair", "pair", pair, "err", err)
+			continue
+		}
+		if kv.keys != nil {
+			if _, ok := kv.keys[key]; !ok {
+				continue
+			}
+		}
+		extracted[key] = value
+	}
+	level.Debug(kv.logger).Log("msg", "extracted data debug in key_value stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// splitPairs splits the input on the pair delimiter, ignoring delimiters
+// which appear inside quoted strings. Empty pairs are dropped.
+func (kv *keyValueStage) splitPairs(input string) ([]string, error) {
+	var (
+		pairs  []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(input); {
+		switch {
+		case kv.config.Quote != "" && strings.HasPrefix(input[i:], kv.config.Quote):
+			quoted = !quoted
+			i += len(kv.config.Quote)
+		case quoted && input[i] == '\\':
+			// Skip over the escaped character.
+			i += 2
+		case !quoted && strings.HasPrefix(input[i:], kv.config.PairDelimiter):
+			if pair := input[start:i]; strings.TrimSpace(pair) != "" {
+				pairs = append(pairs, pair)
+			}
+			i += len(kv.config.PairDelimiter)
+			start = i
+		default:
+			i++
+		}
+	}
+	if quoted {
+		return nil, errKeyValueUnterminatedQuote
+	}
+	if pair := input[min(start, len(input)):]; strings.TrimSpace(pair) != "" {
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// parsePair splits a single pair on the first key-value delimiter and
+// unquotes the key and value.
+func (kv *keyValueStage) parsePair(pair string) (string, string, error) {
+	key, value, ok := strings.Cut(pair, kv.config.KeyValueDelimiter)
+	if !ok {
+		return "", "", errKeyValueMissingKVDelimiter
+	}
+	if kv.config.TrimSpace {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	}
+	key, value = kv.unquote(key), kv.unquote(value)
+	if key == "" {
+		return "", "", errKeyValueEmptyKey
+	}
+	return key, value, nil
+}
+
+// unquote strips the surrounding quote characters from s, if present, and
+// resolves backslash escapes inside of it.
+func (kv *keyValueStage) unquote(s string) string {
+	q := kv.config.Quote
+	if q == "" || len(s) < 2*len(q) || !strings.HasPrefix(s, q) || !strings.HasSuffix(s, q) {
+		return s
+	}
+	s = s[len(q) : len(s)-len(q)]
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// Name implements Stage
+func (kv *keyValueStage) Name() string {
+	return StageTypeKeyValue
+}
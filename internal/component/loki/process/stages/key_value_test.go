@@ -0,0 +1,136 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testKeyValueAlloyDefaults = `
+stage.key_value {}
+`
+
+var testKeyValueAlloyCustomDelimiters = `
+stage.key_value {
+    pair_delimiter      = "|"
+    key_value_delimiter = ":"
+    trim_space          = true
+}
+`
+
+var testKeyValueAlloyAllowlistWithSource = `
+stage.regex {
+    expression = "^\\[(?P<level>\\w+)\\] (?P<rest>.*)$"
+}
+stage.key_value {
+    keys   = ["user", "status"]
+    source = "rest"
+}
+`
+
+func TestPipeline_KeyValue(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"default delimiters with quoted values": {
+			testKeyValueAlloyDefaults,
+			`app=loki  msg="hello \"big\" world" url=http://x?a=b empty= invalid`,
+			map[string]interface{}{
+				"app":   "loki",
+				"msg":   `hello "big" world`,
+				"url":   "http://x?a=b",
+				"empty": "",
+			},
+		},
+		"custom delimiters": {
+			testKeyValueAlloyCustomDelimiters,
+			`a:1 | b:2|c : three `,
+			map[string]interface{}{
+				"a": "1",
+				"b": "2",
+				"c": "three",
+			},
+		},
+		"allowlist of keys and source": {
+			testKeyValueAlloyAllowlistWithSource,
+			`[info] user=frank status=200 secret=hunter2`,
+			map[string]interface{}{
+				"level":  "info",
+				"rest":   "user=frank status=200 secret=hunter2",
+				"user":   "frank",
+				"status": "200",
+			},
+		},
+		"unterminated quote": {
+			testKeyValueAlloyDefaults,
+			`a=1 b="2`,
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			logger := util.TestAlloyLogger(t)
+			pl, err := NewPipeline(logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestKeyValueConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	emptySource := ""
+	tests := map[string]struct {
+		config KeyValueConfig
+		err    error
+	}{
+		"empty pair delimiter": {
+			KeyValueConfig{KeyValueDelimiter: "="},
+			ErrKeyValueEmptyPairDelimiter,
+		},
+		"empty key-value delimiter": {
+			KeyValueConfig{PairDelimiter: " "},
+			ErrKeyValueEmptyKVDelimiter,
+		},
+		"same delimiters": {
+			KeyValueConfig{PairDelimiter: "=", KeyValueDelimiter: "="},
+			ErrKeyValueSameDelimiters,
+		},
+		"multi-character quote": {
+			KeyValueConfig{PairDelimiter: " ", KeyValueDelimiter: "=", Quote: `""`},
+			ErrKeyValueInvalidQuote,
+		},
+		"empty source": {
+			KeyValueConfig{PairDelimiter: " ", KeyValueDelimiter: "=", Source: &emptySource},
+			ErrEmptyKeyValueStageSource,
+		},
+		"valid without quoting": {
+			KeyValueConfig{PairDelimiter: ", ", KeyValueDelimiter: ": "},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
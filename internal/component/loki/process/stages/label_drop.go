@@ -13,6 +13,7 @@ var ErrEmptyLabelDropStageConfig = errors.New("labeldrop stage config cannot be
 // LabelDropConfig contains the slice of labels to be dropped.
 type LabelDropConfig struct {
 	Values []string `alloy:"values,attr"`
+	When   string   `alloy:"when,attr,optional"`
 }
 
 func newLabelDropStage(config LabelDropConfig) (Stage, error) {
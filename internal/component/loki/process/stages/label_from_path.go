@@ -0,0 +1,131 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrLabelFromPathNoCaptures = errors.New("label_from_path stage expression must contain at least one named capture group")
+)
+
+// LabelFromPathConfig configures a processing stage that turns the named
+// capture groups of a regular expression applied to a label into labels.
+type LabelFromPathConfig struct {
+	Expression string `alloy:"expression,attr"`
+	Source     string `alloy:"source,attr,optional"`
+	When       string `alloy:"when,attr,optional"`
+}
+
+// DefaultLabelFromPathConfig sets the defaults for LabelFromPathConfig.
+var DefaultLabelFromPathConfig = LabelFromPathConfig{
+	Source: "filename",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *LabelFromPathConfig) SetToDefault() {
+	*c = DefaultLabelFromPathConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *LabelFromPathConfig) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// compile validates the config and returns the compiled expression.
+func (c *LabelFromPathConfig) compile() (*regexp.Regexp, error) {
+	if c.Expression == "" {
+		return nil, ErrExpressionRequired
+	}
+	if !model.LabelName(c.Source).IsValid() {
+		return nil, fmt.Errorf(ErrInvalidLabelName, c.Source)
+	}
+
+	expr, err := regexp.Compile(c.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrCouldNotCompileRegex, err)
+	}
+
+	var captures int
+	for _, name := range expr.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf(ErrInvalidLabelName, name)
+		}
+		captures++
+	}
+	if captures == 0 {
+		return nil, ErrLabelFromPathNoCaptures
+	}
+	return expr, nil
+}
+
+// labelFromPathStage sets labels from the named captures of a regular
+// expression applied to a label.
+type labelFromPathStage struct {
+	source     model.LabelName
+	expression *regexp.Regexp
+	logger     log.Logger
+}
+
+// newLabelFromPathStage creates a new label_from_path pipeline stage from a config.
+func newLabelFromPathStage(logger log.Logger, config LabelFromPathConfig) (Stage, error) {
+	expression, err := config.compile()
+	if err != nil {
+		return nil, err
+	}
+	return toStage(&labelFromPathStage{
+		source:     model.LabelName(config.Source),
+		expression: expression,
+		logger:     log.With(logger, "component", "stage", "type", "label_from_path"),
+	}), nil
+}
+
+// Process implements Stage
+func (l *labelFromPathStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	value, ok := labels[l.source]
+	if !ok {
+		if Debug {
+			level.Debug(l.logger).Log("msg", "source label does not exist", "source", l.source)
+		}
+		return
+	}
+
+	match := l.expression.FindStringSubmatch(string(value))
+	if match == nil {
+		if Debug {
+			level.Debug(l.logger).Log("msg", "regex did not match", "input", value, "regex", l.expression)
+		}
+		return
+	}
+
+	for i, name := range l.expression.SubexpNames() {
+		if i == 0 || name == "" || match[i] == "" {
+			continue
+		}
+		lvalue := model.LabelValue(match[i])
+		if !lvalue.IsValid() {
+			if Debug {
+				level.Debug(l.logger).Log("msg", "invalid label value parsed", "value", lvalue)
+			}
+			continue
+		}
+		labels[model.LabelName(name)] = lvalue
+	}
+}
+
+// Name implements Stage
+func (l *labelFromPathStage) Name() string {
+	return StageTypeLabelFromPath
+}
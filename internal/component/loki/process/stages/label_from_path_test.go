@@ -0,0 +1,82 @@
+package stages
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testLabelFromPathAlloy = `
+stage.label_from_path {
+    expression = "^/var/log/pods/(?P<namespace>[^_]+)_(?P<pod>[^_]+)_[^/]+/(?P<container>[^/]+)/"
+}
+`
+
+func TestLabelFromPathPipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testLabelFromPathAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, model.LabelSet{"filename": "/var/log/pods/default_alloy-0_1234/alloy/0.log"}, "line", time.Now()),
+		newEntry(nil, model.LabelSet{"filename": "/var/log/syslog"}, "line", time.Now()),
+		newEntry(nil, nil, "line", time.Now()),
+	)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, model.LabelSet{
+		"filename":  "/var/log/pods/default_alloy-0_1234/alloy/0.log",
+		"namespace": "default",
+		"pod":       "alloy-0",
+		"container": "alloy",
+	}, out[0].Labels)
+	assert.Equal(t, model.LabelSet{"filename": "/var/log/syslog"}, out[1].Labels)
+	assert.Empty(t, out[2].Labels)
+}
+
+func TestLabelFromPathStage_Source(t *testing.T) {
+	st, err := newLabelFromPathStage(util.TestAlloyLogger(t), LabelFromPathConfig{
+		Expression: `^(?P<service>[a-z]+)-(?:[0-9]+)(?P<empty>x?)$`,
+		Source:     "host",
+	})
+	require.NoError(t, err)
+
+	out := processEntries(st, newEntry(nil, model.LabelSet{"host": "web-01", "service": "old"}, "line", time.Now()))[0]
+	assert.Equal(t, model.LabelSet{"host": "web-01", "service": "web"}, out.Labels)
+}
+
+func TestLabelFromPathConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config LabelFromPathConfig
+		err    error
+	}{
+		"missing expression": {
+			LabelFromPathConfig{Source: "filename"},
+			ErrExpressionRequired,
+		},
+		"invalid source": {
+			LabelFromPathConfig{Expression: "(?P<a>.*)", Source: "file name"},
+			fmt.Errorf(ErrInvalidLabelName, "file name"),
+		},
+		"no named captures": {
+			LabelFromPathConfig{Expression: "(.*)", Source: "filename"},
+			ErrLabelFromPathNoCaptures,
+		},
+		"valid": {
+			LabelFromPathConfig{Expression: "(?P<a>.*)", Source: "filename"},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
@@ -13,6 +13,7 @@ var ErrEmptyLabelAllowStageConfig = errors.New("labelallow stage config cannot b
 // LabelAllowConfig contains the slice of labels to allow through.
 type LabelAllowConfig struct {
 	Values []string `alloy:"values,attr"`
+	When   string   `alloy:"when,attr,optional"`
 }
 
 func newLabelAllowStage(config LabelAllowConfig) (Stage, error) {
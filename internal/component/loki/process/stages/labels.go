@@ -1,9 +1,12 @@
 package stages
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/go-kit/log"
@@ -20,6 +23,7 @@ const (
 // LabelsConfig is a set of labels to be extracted
 type LabelsConfig struct {
 	Values map[string]*string `alloy:"values,attr"`
+	When   string             `alloy:"when,attr,optional"`
 }
 
 // validateLabelsConfig validates the Label stage configuration
@@ -49,8 +53,17 @@ func newLabelStage(logger log.Logger, configs LabelsConfig) (Stage, error) {
 	if err != nil {
 		return nil, err
 	}
+	templates, err := parseLabelTemplates(labelsConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Templated labels aren't read from a single extracted value.
+	for labelName := range templates {
+		delete(labelsConfig, labelName)
+	}
 	return toStage(&labelStage{
 		labelsConfig: labelsConfig,
+		templates:    templates,
 		logger:       logger,
 	}), nil
 }
@@ -58,6 +71,7 @@ func newLabelStage(logger log.Logger, configs LabelsConfig) (Stage, error) {
 // labelStage sets labels from extracted data
 type labelStage struct {
 	labelsConfig map[string]string
+	templates    map[string]*template.Template
 	logger       log.Logger
 }
 
@@ -66,6 +80,59 @@ func (l *labelStage) Process(labels model.LabelSet, extracted map[string]interfa
 	processLabelsConfigs(l.logger, extracted, l.labelsConfig, func(labelName model.LabelName, labelValue model.LabelValue) {
 		labels[labelName] = labelValue
 	})
+	for labelName, t := range l.templates {
+		if labelValue, ok := executeLabelTemplate(l.logger, t, extracted); ok {
+			labels[model.LabelName(labelName)] = labelValue
+		}
+	}
+}
+
+// parseLabelTemplates parses the label values which are Go templates, that
+// is, which contain "{{".
+func parseLabelTemplates(values map[string]string) (map[string]*template.Template, error) {
+	templates := map[string]*template.Template{}
+	for labelName, value := range values {
+		if !strings.Contains(value, "{{") {
+			continue
+		}
+		// A label isn't set if the template refers to a missing value.
+		t, err := template.New("label_template").Funcs(functionMap).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for label %s: %w", labelName, err)
+		}
+		templates[labelName] = t
+	}
+	return templates, nil
+}
+
+// executeLabelTemplate executes a label template over the extracted values.
+// It returns false if the template fails or evaluates to an empty or invalid
+// label value.
+func executeLabelTemplate(logger log.Logger, t *template.Template, extracted map[string]interface{}) (model.LabelValue, bool) {
+	td := make(map[string]interface{}, len(extracted))
+	for k, v := range extracted {
+		s, err := getString(v)
+		if err != nil {
+			continue
+		}
+		td[k] = s
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, td); err != nil {
+		if Debug {
+			level.Debug(logger).Log("msg", "failed to execute label template", "err", err)
+		}
+		return "", false
+	}
+	labelValue := model.LabelValue(buf.String())
+	if labelValue == "" || !labelValue.IsValid() {
+		if Debug {
+			level.Debug(logger).Log("msg", "invalid label value parsed", "value", labelValue)
+		}
+		return "", false
+	}
+	return labelValue, true
 }
 
 type labelsConsumer func(labelName model.LabelName, labelValue model.LabelValue)
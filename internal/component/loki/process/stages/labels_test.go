@@ -76,7 +76,7 @@ var (
 	lv3 = ""
 )
 
-var emptyLabelsConfig = LabelsConfig{nil}
+var emptyLabelsConfig = LabelsConfig{nil, ""}
 
 func TestLabels(t *testing.T) {
 	tests := map[string]struct {
@@ -132,6 +132,7 @@ func TestLabels(t *testing.T) {
 
 func TestLabelStage_Process(t *testing.T) {
 	sourceName := "diff_source"
+	workloadTemplate := "{{ .namespace }}/{{ .app }}"
 	tests := map[string]struct {
 		config         LabelsConfig
 		extractedData  map[string]interface{}
@@ -162,6 +163,31 @@ func TestLabelStage_Process(t *testing.T) {
 				"testLabel": "testValue",
 			},
 		},
+		"template": {
+			LabelsConfig{Values: map[string]*string{
+				"workload": &workloadTemplate,
+				"app":      nil,
+			}},
+			map[string]interface{}{
+				"namespace": "prod",
+				"app":       "api",
+			},
+			model.LabelSet{},
+			model.LabelSet{
+				"workload": "prod/api",
+				"app":      "api",
+			},
+		},
+		"template_with_missing_value": {
+			LabelsConfig{Values: map[string]*string{
+				"workload": &workloadTemplate,
+			}},
+			map[string]interface{}{
+				"app": "api",
+			},
+			model.LabelSet{},
+			model.LabelSet{},
+		},
 		"empty_extracted_data": {
 			LabelsConfig{Values: map[string]*string{
 				"testLabel": &sourceName,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -16,6 +17,7 @@ import (
 var (
 	ErrLimitStageInvalidRateOrBurst = errors.New("limit stage failed to parse rate or burst")
 	ErrLimitStageByLabelMustDrop    = errors.New("When ratelimiting by label, drop must be true")
+	ErrLimitStageByLabelsConflict   = errors.New("limit stage by_label_name and by_labels can't be set together")
 	ratelimitDropReason             = "ratelimit_drop_stage"
 )
 
@@ -24,11 +26,21 @@ const MinReasonableMaxDistinctLabels = 10000 // 80bytes per rate.Limiter ~ 1MiB
 
 // LimitConfig sets up a Limit stage.
 type LimitConfig struct {
-	Rate              float64 `alloy:"rate,attr"`
-	Burst             int     `alloy:"burst,attr"`
-	Drop              bool    `alloy:"drop,attr,optional"`
-	ByLabelName       string  `alloy:"by_label_name,attr,optional"`
-	MaxDistinctLabels int     `alloy:"max_distinct_labels,attr,optional"`
+	Rate              float64  `alloy:"rate,attr"`
+	Burst             int      `alloy:"burst,attr"`
+	Drop              bool     `alloy:"drop,attr,optional"`
+	ByLabelName       string   `alloy:"by_label_name,attr,optional"`
+	ByLabels          []string `alloy:"by_labels,attr,optional"`
+	MaxDistinctLabels int      `alloy:"max_distinct_labels,attr,optional"`
+	When              string   `alloy:"when,attr,optional"`
+}
+
+// byLabels returns the labels to rate-limit by, if any.
+func (cfg LimitConfig) byLabels() []string {
+	if cfg.ByLabelName != "" {
+		return []string{cfg.ByLabelName}
+	}
+	return cfg.ByLabels
 }
 
 func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Registerer) (Stage, error) {
@@ -38,7 +50,8 @@ func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Reg
 	}
 
 	logger = log.With(logger, "component", "stage", "type", "limit")
-	if cfg.ByLabelName != "" && cfg.MaxDistinctLabels < MinReasonableMaxDistinctLabels {
+	byLabels := cfg.byLabels()
+	if len(byLabels) > 0 && cfg.MaxDistinctLabels < MinReasonableMaxDistinctLabels {
 		level.Warn(logger).Log(
 			"msg",
 			fmt.Sprintf("max_distinct_labels was adjusted up to the minimal reasonable value of %d", MinReasonableMaxDistinctLabels),
@@ -52,11 +65,16 @@ func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Reg
 		dropCount: getDropCountMetric(registerer),
 	}
 
-	if cfg.ByLabelName != "" {
+	if len(byLabels) > 0 {
+		r.byLabels = make([]model.LabelName, 0, len(byLabels))
+		for _, name := range byLabels {
+			r.byLabels = append(r.byLabels, model.LabelName(name))
+		}
+		r.byLabelsName = strings.Join(byLabels, ",")
 		r.dropCountByLabel = getDropCountByLabelMetric(registerer)
 		newRateLimiter := func() *rate.Limiter { return rate.NewLimiter(rate.Limit(cfg.Rate), cfg.Burst) }
 		gcCb := func() { r.dropCountByLabel.Reset() }
-		r.rateLimiterByLabel = NewGenMap[model.LabelValue, *rate.Limiter](cfg.MaxDistinctLabels, newRateLimiter, gcCb)
+		r.rateLimiterByLabel = NewGenMap[string, *rate.Limiter](cfg.MaxDistinctLabels, newRateLimiter, gcCb)
 	} else {
 		r.rateLimiter = rate.NewLimiter(rate.Limit(cfg.Rate), cfg.Burst)
 	}
@@ -69,7 +87,11 @@ func validateLimitConfig(cfg LimitConfig) error {
 		return ErrLimitStageInvalidRateOrBurst
 	}
 
-	if cfg.ByLabelName != "" && !cfg.Drop {
+	if cfg.ByLabelName != "" && len(cfg.ByLabels) > 0 {
+		return ErrLimitStageByLabelsConflict
+	}
+
+	if len(cfg.byLabels()) > 0 && !cfg.Drop {
 		return ErrLimitStageByLabelMustDrop
 	}
 	return nil
@@ -80,7 +102,9 @@ type limitStage struct {
 	logger             log.Logger
 	cfg                LimitConfig
 	rateLimiter        *rate.Limiter
-	rateLimiterByLabel GenerationalMap[model.LabelValue, *rate.Limiter]
+	byLabels           []model.LabelName
+	byLabelsName       string
+	rateLimiterByLabel GenerationalMap[string, *rate.Limiter]
 	dropCount          *prometheus.CounterVec
 	dropCountByLabel   *prometheus.CounterVec
 }
@@ -90,7 +114,7 @@ func (m *limitStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
-			if !m.shouldThrottle(e.Labels) {
+			if e.skip || !m.shouldThrottle(e.Labels) {
 				out <- e
 				continue
 			}
@@ -100,17 +124,25 @@ func (m *limitStage) Run(in chan Entry) chan Entry {
 }
 
 func (m *limitStage) shouldThrottle(labels model.LabelSet) bool {
-	if m.cfg.ByLabelName != "" {
-		labelValue, ok := labels[model.LabelName(m.cfg.ByLabelName)]
-		if !ok {
+	if len(m.byLabels) > 0 {
+		var (
+			values = make([]string, len(m.byLabels))
+			found  bool
+		)
+		for i, name := range m.byLabels {
+			value, ok := labels[name]
+			found = found || ok
+			values[i] = string(value)
+		}
+		if !found {
 			return false // if no label found, dont ratelimit
 		}
-		rl := m.rateLimiterByLabel.GetOrCreate(labelValue)
+		rl := m.rateLimiterByLabel.GetOrCreate(strings.Join(values, "\xff"))
 		if rl.Allow() {
 			return false
 		}
 		m.dropCount.WithLabelValues(ratelimitDropReason).Inc()
-		m.dropCountByLabel.WithLabelValues(m.cfg.ByLabelName, string(labelValue)).Inc()
+		m.dropCountByLabel.WithLabelValues(m.byLabelsName, strings.Join(values, ",")).Inc()
 		return true
 	}
 
@@ -1,10 +1,12 @@
 package stages
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,6 +48,15 @@ stage.limit {
 		by_label_name = "app"
 }`
 
+var testLimitByLabelsAlloy = `
+stage.limit {
+		rate  = 1
+		burst = 1
+		drop  = true
+
+		by_labels = ["namespace", "app"]
+}`
+
 var testNonAppLogLine = `
 {
 	"time":"2012-11-01T22:08:41+00:00",
@@ -143,3 +154,63 @@ func TestLimitByLabelPipeline(t *testing.T) {
 	assert.True(t, hasTotal)
 	assert.True(t, hasByLabel)
 }
+
+func TestLimitByLabelsPipeline(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testLimitByLabelsAlloy), &plName, registry)
+	require.NoError(t, err)
+
+	logs := make([]Entry, 0)
+	logCount := 5
+	for _, ls := range []model.LabelSet{
+		{"namespace": "a", "app": "loki"},
+		{"namespace": "b", "app": "loki"},
+		{"namespace": "a"},
+		{},
+	} {
+		for i := 0; i < logCount; i++ {
+			logs = append(logs, newEntry(nil, ls, testNonAppLogLine, time.Now()))
+		}
+	}
+	out := processEntries(pl, logs...)
+	// Only one entry of each label combination will go through + all log lines without any of the labels
+	assert.Len(t, out, 3+logCount)
+
+	expected := `
+# HELP loki_process_dropped_lines_by_label_total A count of all log lines dropped as a result of a pipeline stage
+# TYPE loki_process_dropped_lines_by_label_total counter
+loki_process_dropped_lines_by_label_total{label_name="namespace,app",label_value="a,"} 4
+loki_process_dropped_lines_by_label_total{label_name="namespace,app",label_value="a,loki"} 4
+loki_process_dropped_lines_by_label_total{label_name="namespace,app",label_value="b,loki"} 4
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "loki_process_dropped_lines_by_label_total"))
+}
+
+func TestValidateLimitConfig(t *testing.T) {
+	tests := map[string]struct {
+		config LimitConfig
+		err    error
+	}{
+		"invalid rate": {
+			LimitConfig{Burst: 1},
+			ErrLimitStageInvalidRateOrBurst,
+		},
+		"by labels without drop": {
+			LimitConfig{Rate: 1, Burst: 1, ByLabels: []string{"app"}},
+			ErrLimitStageByLabelMustDrop,
+		},
+		"by label name and by labels": {
+			LimitConfig{Rate: 1, Burst: 1, Drop: true, ByLabelName: "app", ByLabels: []string{"app"}},
+			ErrLimitStageByLabelsConflict,
+		},
+		"valid": {
+			LimitConfig{Rate: 1, Burst: 1, Drop: true, ByLabels: []string{"namespace", "app"}},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, validateLimitConfig(tt.config))
+		})
+	}
+}
@@ -23,6 +23,7 @@ var (
 type LogfmtConfig struct {
 	Mapping map[string]string `alloy:"mapping,attr"`
 	Source  string            `alloy:"source,attr,optional"`
+	When    string            `alloy:"when,attr,optional"`
 }
 
 // validateLogfmtConfig validates a logfmt stage config and returns an inverse mapping of configured mapping.
@@ -51,6 +52,8 @@ func validateLogfmtConfig(c *LogfmtConfig) (map[string]string, error) {
 
 // logfmtStage sets extracted data using logfmt parser
 type logfmtStage struct {
+	stageErrors
+
 	cfg            *LogfmtConfig
 	inverseMapping map[string]string
 	logger         log.Logger
@@ -111,6 +114,7 @@ func (j *logfmtStage) Process(labels model.LabelSet, extracted map[string]interf
 
 	if decoder.Err() != nil {
 		level.Error(j.logger).Log("msg", "failed to decode logfmt", "err", decoder.Err())
+		j.reportError()
 		return
 	}
 
@@ -14,6 +14,7 @@ type LuhnFilterConfig struct {
 	Replacement string  `alloy:"replacement,attr,optional"`
 	Source      *string `alloy:"source,attr,optional"`
 	MinLength   int     `alloy:"min_length,attr,optional"`
+	When        string  `alloy:"when,attr,optional"`
 }
 
 // validateLuhnFilterConfig validates the LuhnFilterConfig.
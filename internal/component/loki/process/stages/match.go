@@ -3,6 +3,7 @@ package stages
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/loki/v3/clients/pkg/logentry/logql"
@@ -18,6 +19,7 @@ var (
 	ErrSelectorSyntax      = errors.New("invalid selector syntax for match stage")
 	ErrStagesWithDropLine  = errors.New("match stage configured to drop entries cannot contains stages")
 	ErrUnknownMatchAction  = errors.New("match stage action should be 'keep' or 'drop'")
+	ErrMatchElseNoStages   = errors.New("match stage else block requires at least one stage")
 
 	MatchActionKeep = "keep"
 	MatchActionDrop = "drop"
@@ -30,6 +32,14 @@ type MatchConfig struct {
 	Action       string        `alloy:"action,attr,optional"`
 	PipelineName string        `alloy:"pipeline_name,attr,optional"`
 	DropReason   string        `alloy:"drop_counter_reason,attr,optional"`
+	Else         *MatchElse    `alloy:"else,block,optional"`
+	When         string        `alloy:"when,attr,optional"`
+}
+
+// MatchElse contains the stages to run on entries which don't match the
+// selector of a matcherStage.
+type MatchElse struct {
+	Stages []StageConfig `alloy:"stage,enum,optional"`
 }
 
 // validateMatcherConfig validates the MatcherConfig for the matcherStage
@@ -51,6 +61,9 @@ func validateMatcherConfig(cfg *MatchConfig) (logql.Expr, error) {
 	if cfg.Action == MatchActionDrop && (cfg.Stages != nil && len(cfg.Stages) != 0) {
 		return nil, ErrStagesWithDropLine
 	}
+	if cfg.Else != nil && len(cfg.Else.Stages) == 0 {
+		return nil, ErrMatchElseNoStages
+	}
 
 	selector, err := logql.ParseExpr(cfg.Selector)
 	if err != nil {
@@ -81,6 +94,20 @@ func newMatcherStage(logger log.Logger, jobName *string, config MatchConfig, reg
 		}
 	}
 
+	var elseStage Stage
+	if config.Else != nil {
+		var elseName *string
+		if nPtr != nil {
+			name := *nPtr + "_else"
+			elseName = &name
+		}
+		elsePl, err := NewPipeline(logger, config.Else.Stages, elseName, registerer)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, fmt.Errorf("match stage failed to create else pipeline from config: %v", config))
+		}
+		elseStage = elsePl
+	}
+
 	filter, err := selector.Filter()
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "error parsing pipeline", err)
@@ -96,6 +123,7 @@ func newMatcherStage(logger log.Logger, jobName *string, config MatchConfig, reg
 		dropCount:  getDropCountMetric(registerer),
 		matchers:   selector.Matchers(),
 		stage:      pl,
+		elseStage:  elseStage,
 		action:     config.Action,
 		filter:     filter,
 	}, nil
@@ -125,9 +153,17 @@ type matcherStage struct {
 	matchers   []*labels.Matcher
 	filter     logql.Filter
 	stage      Stage
+	elseStage  Stage
 	action     string
 }
 
+// setErrorCounter counts the errors of the nested stages as errors of the
+// match stage.
+func (m *matcherStage) setErrorCounter(c prometheus.Counter) bool {
+	ok := setErrorCounter(m.stage, c)
+	return setErrorCounter(m.elseStage, c) || ok
+}
+
 func (m *matcherStage) Run(in chan Entry) chan Entry {
 	switch m.action {
 	case MatchActionDrop:
@@ -142,18 +178,26 @@ func (m *matcherStage) runKeep(in chan Entry) chan Entry {
 	next := make(chan Entry)
 	out := make(chan Entry)
 	outNext := m.stage.Run(next)
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
 	go func() {
-		defer close(out)
+		defer wg.Done()
 		for e := range outNext {
 			out <- e
 		}
 	}()
+	unmatched, closeUnmatched := m.runElse(out, wg)
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
 	go func() {
 		defer close(next)
+		defer closeUnmatched()
 		for e := range in {
 			e, ok := m.processLogQL(e)
-			if !ok {
-				out <- e
+			if !ok && !e.skip {
+				unmatched(e)
 				continue
 			}
 			next <- e
@@ -164,19 +208,48 @@ func (m *matcherStage) runKeep(in chan Entry) chan Entry {
 
 func (m *matcherStage) runDrop(in chan Entry) chan Entry {
 	out := make(chan Entry)
+	wg := new(sync.WaitGroup)
+	unmatched, closeUnmatched := m.runElse(out, wg)
+	wg.Add(1)
 	go func() {
-		defer close(out)
+		defer wg.Done()
+		defer closeUnmatched()
 		for e := range in {
-			if e, ok := m.processLogQL(e); !ok {
-				out <- e
+			if e, ok := m.processLogQL(e); !ok || e.skip {
+				unmatched(e)
 				continue
 			}
 			m.dropCount.WithLabelValues(m.dropReason).Inc()
 		}
 	}()
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
 	return out
 }
 
+// runElse starts the else stages, if any, and returns a function sending
+// entries which don't match the selector through them, or directly to out if
+// there are no else stages. The returned close function must be called once
+// no more entries will be sent.
+func (m *matcherStage) runElse(out chan Entry, wg *sync.WaitGroup) (func(Entry), func()) {
+	if m.elseStage == nil {
+		return func(e Entry) { out <- e }, func() {}
+	}
+
+	next := make(chan Entry)
+	outNext := m.elseStage.Run(next)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for e := range outNext {
+			out <- e
+		}
+	}()
+	return func(e Entry) { next <- e }, func() { close(next) }
+}
+
 func (m *matcherStage) processLogQL(e Entry) (Entry, bool) {
 	for _, filter := range m.matchers {
 		if !filter.Matches(string(e.Labels[model.LabelName(filter.Name)])) {
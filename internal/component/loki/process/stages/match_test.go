@@ -91,6 +91,61 @@ func TestMatchStage(t *testing.T) {
 	close(in)
 }
 
+var testMatchElseAlloy = `
+stage.match {
+		selector = "{app=\"loki\"}"
+		stage.static_labels {
+				values = { matched = "true" }
+		}
+		else {
+				stage.static_labels {
+						values = { matched = "false" }
+				}
+		}
+}
+`
+
+var testMatchDropElseAlloy = `
+stage.match {
+		selector = "{app=\"loki\"}"
+		action   = "drop"
+		else {
+				stage.static_labels {
+						values = { kept = "true" }
+				}
+		}
+}
+`
+
+func TestMatchStage_Else(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+
+	pl, err := NewPipeline(logger, loadConfig(testMatchElseAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+	out := processEntries(pl,
+		newEntry(nil, toLabelSet(map[string]string{"app": "loki"}), "a", time.Now()),
+		newEntry(nil, toLabelSet(map[string]string{"app": "poki"}), "b", time.Now()),
+	)
+	require.Len(t, out, 2)
+	for _, e := range out {
+		if e.Line == "a" {
+			assert.Equal(t, "true", string(e.Labels["matched"]))
+		} else {
+			assert.Equal(t, "false", string(e.Labels["matched"]))
+		}
+	}
+
+	pl, err = NewPipeline(logger, loadConfig(testMatchDropElseAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+	out = processEntries(pl,
+		newEntry(nil, toLabelSet(map[string]string{"app": "loki"}), "a", time.Now()),
+		newEntry(nil, toLabelSet(map[string]string{"app": "poki"}), "b", time.Now()),
+	)
+	require.Len(t, out, 1)
+	assert.Equal(t, "b", out[0].Line)
+	assert.Equal(t, "true", string(out[0].Labels["kept"]))
+}
+
 func TestMatcher(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -160,6 +215,8 @@ func TestMatcher(t *testing.T) {
 				tt.action,
 				"",
 				"",
+				nil,
+				"",
 			}
 			logger := util.TestAlloyLogger(t)
 			s, err := newMatcherStage(logger, nil, matchConfig, prometheus.DefaultRegisterer)
@@ -206,6 +263,7 @@ func TestValidateMatcherConfig(t *testing.T) {
 		{name: "empty stages dropping", cfg: &MatchConfig{Selector: `{app="foo"}`, Action: MatchActionDrop, Stages: emptyStages}},
 		{name: "stages without dropping", cfg: &MatchConfig{Selector: `{app="foo"}`, Action: MatchActionKeep, Stages: defaultStage}},
 		{name: "bad selector", cfg: &MatchConfig{Selector: `{app="foo}`, Action: MatchActionKeep, Stages: defaultStage}, wantErr: true},
+		{name: "else without stages", cfg: &MatchConfig{Selector: `{app="foo"}`, Action: MatchActionKeep, Stages: defaultStage, Else: &MatchElse{}}, wantErr: true},
 		{name: "bad action", cfg: &MatchConfig{Selector: `{app="foo}`, Action: "nope", Stages: emptyStages}, wantErr: true},
 		{
 			name:     "sets default action to keep",
@@ -25,11 +25,13 @@ type MetricConfig struct {
 	Counter   *metric.CounterConfig   `alloy:"counter,block,optional"`
 	Gauge     *metric.GaugeConfig     `alloy:"gauge,block,optional"`
 	Histogram *metric.HistogramConfig `alloy:"histogram,block,optional"`
+	Summary   *metric.SummaryConfig   `alloy:"summary,block,optional"`
 }
 
 // MetricsConfig is a set of configured metrics.
 type MetricsConfig struct {
 	Metrics []MetricConfig `alloy:"metric,enum,optional"`
+	When    string         `alloy:"when,attr,optional"`
 }
 
 type cfgCollector struct {
@@ -87,6 +89,20 @@ func newMetricStage(logger log.Logger, config MetricsConfig, registry prometheus
 			// It is safe to .MustRegister here because the metric created above is unchecked.
 			registry.MustRegister(collector)
 			metrics[cfg.Histogram.Name] = cfgCollector{cfg: cfg, collector: collector}
+		case cfg.Summary != nil:
+			customPrefix := ""
+			if cfg.Summary.Prefix != "" {
+				customPrefix = cfg.Summary.Prefix
+			} else {
+				customPrefix = defaultMetricsPrefix
+			}
+			collector, err = metric.NewSummaries(customPrefix+cfg.Summary.Name, cfg.Summary)
+			if err != nil {
+				return nil, err
+			}
+			// It is safe to .MustRegister here because the metric created above is unchecked.
+			registry.MustRegister(collector)
+			metrics[cfg.Summary.Name] = cfgCollector{cfg: cfg, collector: collector}
 		default:
 			return nil, fmt.Errorf("undefined stage type in '%v', exiting", cfg)
 		}
@@ -109,6 +125,10 @@ func (m *metricStage) Run(in chan Entry) chan Entry {
 		defer close(out)
 
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			m.Process(e.Labels, e.Extracted, &e.Timestamp, &e.Line)
 			out <- e
 		}
@@ -151,6 +171,12 @@ func (m *metricStage) Process(labels model.LabelSet, extracted map[string]interf
 			} else {
 				level.Debug(m.logger).Log("msg", "source does not exist", "err", fmt.Sprintf("source: %s, does not exist", cc.cfg.Histogram.Source))
 			}
+		case cc.cfg.Summary != nil:
+			if v, ok := extracted[cc.cfg.Summary.Source]; ok {
+				m.recordSummary(name, cc.collector.(*metric.Summaries), labels, v)
+			} else {
+				level.Debug(m.logger).Log("msg", "source does not exist", "err", fmt.Sprintf("source: %s, does not exist", cc.cfg.Summary.Source))
+			}
 		}
 	}
 }
@@ -170,6 +196,8 @@ func (m *metricStage) Cleanup() {
 			vec.DeleteAll()
 		case *metric.Histograms:
 			vec.DeleteAll()
+		case *metric.Summaries:
+			vec.DeleteAll()
 		}
 	}
 }
@@ -287,6 +315,33 @@ func (m *metricStage) recordHistogram(name string, histogram *metric.Histograms,
 	histogram.With(labels).Observe(f)
 }
 
+// recordSummary will update a Summary metric
+func (m *metricStage) recordSummary(name string, summary *metric.Summaries, labels model.LabelSet, v interface{}) {
+	// If value matching is defined, make sure value matches.
+	if summary.Cfg.Value != "" {
+		stringVal, err := getString(v)
+		if err != nil {
+			if Debug {
+				level.Debug(m.logger).Log("msg", "failed to convert extracted value to string, "+
+					"can't perform value comparison", "metric", name, "err",
+					fmt.Sprintf("can't convert %v to string", reflect.TypeOf(v)))
+			}
+			return
+		}
+		if summary.Cfg.Value != stringVal {
+			return
+		}
+	}
+	f, err := getFloat(v)
+	if err != nil {
+		if Debug {
+			level.Debug(m.logger).Log("msg", "failed to convert extracted value to float", "metric", name, "err", err)
+		}
+		return
+	}
+	summary.With(labels).Observe(f)
+}
+
 // getFloat will take the provided value and return a float64 if possible
 func getFloat(unk interface{}) (float64, error) {
 	switch i := unk.(type) {
@@ -131,6 +131,42 @@ func TestMetricsPipeline(t *testing.T) {
 	}
 }
 
+func TestNativeHistogram(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	testConfig := `
+stage.json {
+		expressions = { "duration" = "" }
+}
+stage.metrics {
+		metric.histogram {
+				name = "duration_seconds"
+				description = "request duration"
+				source = "duration"
+				native_histogram_bucket_factor = 1.1
+		}
+}`
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testConfig), nil, registry)
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, model.LabelSet{"test": "app"}, `{"duration": 0.25}`, time.Now()),
+		newEntry(nil, model.LabelSet{"test": "app"}, `{"duration": 4}`, time.Now()),
+	)
+	require.Len(t, out, 2)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "loki_process_custom_duration_seconds", families[0].GetName())
+	h := families[0].GetMetric()[0].GetHistogram()
+	require.Equal(t, uint64(2), h.GetSampleCount())
+	require.Equal(t, 4.25, h.GetSampleSum())
+	// Only the native histogram is exposed, without classic buckets.
+	require.Empty(t, h.GetBucket())
+	require.Equal(t, int32(3), h.GetSchema())
+	require.NotEmpty(t, h.GetPositiveSpan())
+}
+
 func TestNegativeGauge(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	testConfig := `
@@ -308,6 +344,27 @@ loki_process_custom_payload_size_bytes_bucket{test="app",le="20"} 1
 loki_process_custom_payload_size_bytes_bucket{test="app",le="+Inf"} 1
 loki_process_custom_payload_size_bytes_sum{test="app"} 10
 loki_process_custom_payload_size_bytes_count{test="app"} 1
+`
+
+	const summaryConfig = `
+stage.json {
+		expressions = { "payload" = "payload" }
+}
+stage.metrics {
+		metric.summary {
+				name = "payload_size_bytes"
+				description = "payload size in bytes"
+				source = "payload"
+				objectives = { "0.5" = 0.05, "0.99" = 0.001 }
+		}
+}`
+
+	const expectedSummaryMetrics = `# HELP loki_process_custom_payload_size_bytes payload size in bytes
+# TYPE loki_process_custom_payload_size_bytes summary
+loki_process_custom_payload_size_bytes{test="app",quantile="0.5"} 10
+loki_process_custom_payload_size_bytes{test="app",quantile="0.99"} 10
+loki_process_custom_payload_size_bytes_sum{test="app"} 10
+loki_process_custom_payload_size_bytes_count{test="app"} 1
 `
 	for name, tc := range map[string]struct {
 		promtailConfig  string
@@ -345,6 +402,15 @@ loki_process_custom_payload_size_bytes_count{test="app"} 1
 			line:            testMetricLogLine1,
 			expectedCollect: expectedHistogramMetrics,
 		},
+		"summary metric with non-prometheus incoming label": {
+			promtailConfig: summaryConfig,
+			labels: model.LabelSet{
+				"test":          "app",
+				"__bad_label__": "2",
+			},
+			line:            testMetricLogLine1,
+			expectedCollect: expectedSummaryMetrics,
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			registry := prometheus.NewRegistry()
@@ -503,6 +569,14 @@ func metricNames(sc StageConfig) []string {
 				customPrefix = defaultMetricsPrefix
 			}
 			result = append(result, customPrefix+config.Histogram.Name)
+		case config.Summary != nil:
+			customPrefix := ""
+			if config.Summary.Prefix != "" {
+				customPrefix = config.Summary.Prefix
+			} else {
+				customPrefix = defaultMetricsPrefix
+			}
+			result = append(result, customPrefix+config.Summary.Name)
 		}
 	}
 	return result
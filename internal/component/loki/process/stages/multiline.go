@@ -27,6 +27,10 @@ type MultilineConfig struct {
 	Expression  string        `alloy:"firstline,attr"`
 	MaxLines    uint64        `alloy:"max_lines,attr,optional"`
 	MaxWaitTime time.Duration `alloy:"max_wait_time,attr,optional"`
+	KeyLabels   []string      `alloy:"key_labels,attr,optional"`
+	MaxStreams  int           `alloy:"max_streams,attr,optional"`
+	MaxIdleTime time.Duration `alloy:"max_idle_time,attr,optional"`
+	When        string        `alloy:"when,attr,optional"`
 }
 
 // DefaultMultilineConfig applies the default values on
@@ -45,6 +49,12 @@ func (args *MultilineConfig) Validate() error {
 	if args.MaxWaitTime <= 0 {
 		return fmt.Errorf("max_wait_time must be greater than 0")
 	}
+	if args.MaxStreams < 0 {
+		return fmt.Errorf("max_streams must not be negative")
+	}
+	if args.MaxIdleTime < 0 {
+		return fmt.Errorf("max_idle_time must not be negative")
+	}
 
 	return nil
 }
@@ -69,6 +79,12 @@ type multilineStage struct {
 	regex  *regexp.Regexp
 }
 
+// multilineStream is a stream with its own multiline block.
+type multilineStream struct {
+	entries  chan Entry
+	lastSeen time.Time
+}
+
 // multilineState captures the internal state of a running multiline stage.
 type multilineState struct {
 	buffer         *bytes.Buffer // The lines of the current multiline block.
@@ -95,40 +111,108 @@ func (m *multilineStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 
-		streams := make(map[model.Fingerprint]chan Entry)
+		streams := make(map[model.Fingerprint]*multilineStream)
 		wg := new(sync.WaitGroup)
 
-		for e := range in {
-			key := e.Labels.FastFingerprint()
-			s, ok := streams[key]
-			if !ok {
-				// Pass through entries until we hit first start line.
-				if !m.regex.MatchString(e.Line) {
-					level.Debug(m.logger).Log("msg", "pass through entry", "stream", key)
+		var evictIdle <-chan time.Time
+		if m.cfg.MaxIdleTime > 0 {
+			ticker := time.NewTicker(m.cfg.MaxIdleTime)
+			defer ticker.Stop()
+			evictIdle = ticker.C
+		}
+
+		for {
+			select {
+			case now := <-evictIdle:
+				for key, s := range streams {
+					if now.Sub(s.lastSeen) >= m.cfg.MaxIdleTime {
+						level.Debug(m.logger).Log("msg", "evicting idle stream", "stream", key)
+						m.closeStream(streams, key)
+					}
+				}
+			case e, ok := <-in:
+				if !ok {
+					// Close all streams and wait for them to finish being processed.
+					for key := range streams {
+						m.closeStream(streams, key)
+					}
+					wg.Wait()
+					return
+				}
+				if e.skip {
 					out <- e
 					continue
 				}
 
-				level.Debug(m.logger).Log("msg", "creating new stream", "stream", key)
-				s = make(chan Entry)
-				streams[key] = s
-
-				wg.Add(1)
-				go m.runMultiline(s, out, wg)
+				key := m.streamKey(e.Labels)
+				s, ok := streams[key]
+				if !ok {
+					// Pass through entries until we hit first start line.
+					if !m.regex.MatchString(e.Line) {
+						level.Debug(m.logger).Log("msg", "pass through entry", "stream", key)
+						out <- e
+						continue
+					}
+
+					if m.cfg.MaxStreams > 0 && len(streams) >= m.cfg.MaxStreams {
+						m.evictOldestStream(streams)
+					}
+
+					level.Debug(m.logger).Log("msg", "creating new stream", "stream", key)
+					s = &multilineStream{entries: make(chan Entry)}
+					streams[key] = s
+
+					wg.Add(1)
+					go m.runMultiline(s.entries, out, wg)
+				}
+				level.Debug(m.logger).Log("msg", "pass entry", "stream", key, "line", e.Line)
+				s.lastSeen = time.Now()
+				s.entries <- e
 			}
-			level.Debug(m.logger).Log("msg", "pass entry", "stream", key, "line", e.Line)
-			s <- e
-		}
-
-		// Close all streams and wait for them to finish being processed.
-		for _, s := range streams {
-			close(s)
 		}
-		wg.Wait()
 	}()
 	return out
 }
 
+// streamKey returns the key of the stream an entry belongs to, made of the
+// configured key labels, or of all labels if none are configured.
+func (m *multilineStage) streamKey(labels model.LabelSet) model.Fingerprint {
+	if len(m.cfg.KeyLabels) == 0 {
+		return labels.FastFingerprint()
+	}
+	subset := make(model.LabelSet, len(m.cfg.KeyLabels))
+	for _, name := range m.cfg.KeyLabels {
+		if v, ok := labels[model.LabelName(name)]; ok {
+			subset[model.LabelName(name)] = v
+		}
+	}
+	return subset.FastFingerprint()
+}
+
+// closeStream stops tracking a stream, which flushes its current block.
+func (m *multilineStage) closeStream(streams map[model.Fingerprint]*multilineStream, key model.Fingerprint) {
+	close(streams[key].entries)
+	delete(streams, key)
+}
+
+// evictOldestStream closes the stream which received an entry the longest
+// time ago.
+func (m *multilineStage) evictOldestStream(streams map[model.Fingerprint]*multilineStream) {
+	var (
+		oldestKey model.Fingerprint
+		oldest    *multilineStream
+	)
+	for key, s := range streams {
+		if oldest == nil || s.lastSeen.Before(oldest.lastSeen) {
+			oldestKey, oldest = key, s
+		}
+	}
+	if oldest != nil {
+		level.Debug(m.logger).Log("msg", "evicting oldest stream because max_streams was reached", "stream", oldestKey)
+		m.closeStream(streams, oldestKey)
+	}
+}
+
 func (m *multilineStage) runMultiline(in chan Entry, out chan Entry, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -128,6 +128,121 @@ func TestMultilineStageMaxWaitTime(t *testing.T) {
 	require.Equal(t, "not a start line hitting timeout", res[1].Line)
 }
 
+func TestMultilineStageKeyLabels(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	mcfg := MultilineConfig{Expression: "^START", MaxWaitTime: 3 * time.Second, KeyLabels: []string{"pod"}}
+	regex, err := validateMultilineConfig(mcfg)
+	require.NoError(t, err)
+
+	stage := &multilineStage{
+		cfg:    mcfg,
+		regex:  regex,
+		logger: logger,
+	}
+
+	entry := func(line, pod, stream string) Entry {
+		e := simpleEntry(line, "label")
+		e.Labels = model.LabelSet{"pod": model.LabelValue(pod), "stream": model.LabelValue(stream)}
+		return e
+	}
+
+	out := processEntries(stage,
+		entry("START line 1", "a", "stdout"),
+		entry("START line 1", "b", "stdout"),
+		entry("not a start line a", "a", "stderr"),
+		entry("not a start line b", "b", "stderr"),
+	)
+
+	sort.Slice(out, func(l, r int) bool {
+		return out[l].Timestamp.Before(out[r].Timestamp)
+	})
+
+	// Lines of a pod are collapsed together regardless of the other labels,
+	// and keep the labels of the start line.
+	require.Len(t, out, 2)
+	require.Equal(t, "START line 1\nnot a start line a", out[0].Line)
+	require.Equal(t, model.LabelSet{"pod": "a", "stream": "stdout"}, out[0].Labels)
+	require.Equal(t, "START line 1\nnot a start line b", out[1].Line)
+	require.Equal(t, model.LabelSet{"pod": "b", "stream": "stdout"}, out[1].Labels)
+}
+
+func TestMultilineStageMaxStreams(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	mcfg := MultilineConfig{Expression: "^START", MaxWaitTime: 3 * time.Second, MaxStreams: 1}
+	regex, err := validateMultilineConfig(mcfg)
+	require.NoError(t, err)
+
+	stage := &multilineStage{
+		cfg:    mcfg,
+		regex:  regex,
+		logger: logger,
+	}
+
+	out := processEntries(stage,
+		simpleEntry("START line 1", "one"),
+		simpleEntry("START line 1", "two"),
+		simpleEntry("not a start line 1", "one"),
+		simpleEntry("not a start line 2", "two"),
+	)
+
+	sort.Slice(out, func(l, r int) bool {
+		return out[l].Timestamp.Before(out[r].Timestamp)
+	})
+
+	// The stream "one" is evicted, so its block is flushed and the next
+	// line is passed through.
+	require.Len(t, out, 3)
+	require.Equal(t, "START line 1", out[0].Line)
+	require.Equal(t, model.LabelValue("one"), out[0].Labels["value"])
+	require.Equal(t, "START line 1\nnot a start line 2", out[1].Line)
+	require.Equal(t, model.LabelValue("two"), out[1].Labels["value"])
+	require.Equal(t, "not a start line 1", out[2].Line)
+	require.Equal(t, model.LabelValue("one"), out[2].Labels["value"])
+}
+
+func TestMultilineStageMaxIdleTime(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	mcfg := MultilineConfig{Expression: "^START", MaxWaitTime: time.Minute, MaxIdleTime: 50 * time.Millisecond}
+	regex, err := validateMultilineConfig(mcfg)
+	require.NoError(t, err)
+
+	stage := &multilineStage{
+		cfg:    mcfg,
+		regex:  regex,
+		logger: logger,
+	}
+
+	in := make(chan Entry, 2)
+	out := stage.Run(in)
+
+	mu := new(sync.Mutex)
+	var res []Entry
+	go func() {
+		for e := range out {
+			mu.Lock()
+			res = append(res, e)
+			mu.Unlock()
+		}
+	}()
+
+	go func() {
+		in <- simpleEntry("START line", "label")
+
+		// Wait for the stream to be evicted, which flushes its block long
+		// before max_wait_time.
+		time.Sleep(200 * time.Millisecond)
+
+		in <- simpleEntry("not a start line after eviction", "label")
+	}()
+	defer close(in)
+
+	require.Eventually(t, func() bool { mu.Lock(); defer mu.Unlock(); return len(res) == 2 }, 2*time.Second, 50*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "START line", res[0].Line)
+	require.Equal(t, "not a start line after eviction", res[1].Line)
+}
+
 func simpleEntry(line, label string) Entry {
 	// We're adding a small wait time here, because on Windows, timers have a
 	// smaller resolution than on Linux. This can mess with the ordering of log
@@ -20,6 +20,7 @@ var (
 // value from the extracted map.
 type OutputConfig struct {
 	Source string `alloy:"source,attr"`
+	When   string `alloy:"when,attr,optional"`
 }
 
 // newOutputStage creates a new outputStage
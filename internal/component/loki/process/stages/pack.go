@@ -2,6 +2,8 @@ package stages
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"reflect"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/golang/snappy"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	json "github.com/json-iterator/go"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,6 +20,15 @@ import (
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
+// Pack compression algorithms.
+const (
+	PackCompressionGzip   = "gzip"
+	PackCompressionSnappy = "snappy"
+)
+
+// ErrPackInvalidCompression is returned for an unknown compression algorithm.
+var ErrPackInvalidCompression = errors.New("pack stage compress must be one of \"gzip\" or \"snappy\"")
+
 // Packed keeps track of the labels and log entry.
 type Packed struct {
 	Labels map[string]string `json:",inline"`
@@ -104,6 +116,8 @@ func (w Packed) MarshalJSON() ([]byte, error) {
 type PackConfig struct {
 	Labels          []string `alloy:"labels,attr"`
 	IngestTimestamp bool     `alloy:"ingest_timestamp,attr,optional"`
+	Compress        string   `alloy:"compress,attr,optional"`
+	When            string   `alloy:"when,attr,optional"`
 }
 
 // DefaultPackConfig sets the defaults.
@@ -116,17 +130,32 @@ func (p *PackConfig) SetToDefault() {
 	*p = DefaultPackConfig
 }
 
+// Validate implements syntax.Validator.
+func (p *PackConfig) Validate() error {
+	switch p.Compress {
+	case "", PackCompressionGzip, PackCompressionSnappy:
+		return nil
+	default:
+		return ErrPackInvalidCompression
+	}
+}
+
 // newPackStage creates a DropStage from config
-func newPackStage(logger log.Logger, config PackConfig, registerer prometheus.Registerer) Stage {
+func newPackStage(logger log.Logger, config PackConfig, registerer prometheus.Registerer) (Stage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	return &packStage{
 		logger:    log.With(logger, "component", "stage", "type", "pack"),
 		cfg:       &config,
 		dropCount: getDropCountMetric(registerer),
-	}
+	}, nil
 }
 
 // packStage applies Label matchers to determine if the include stages should be run
 type packStage struct {
+	stageErrors
+
 	logger    log.Logger
 	cfg       *PackConfig
 	dropCount *prometheus.CounterVec
@@ -137,6 +166,10 @@ func (m *packStage) Run(in chan Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
 			out <- m.pack(e)
 		}
 	}()
@@ -173,9 +206,21 @@ func (m *packStage) pack(e Entry) Entry {
 	wl, err := json.Marshal(w)
 	if err != nil {
 		level.Debug(m.logger).Log("msg", "pack stage failed to marshal packed object to json, packing will be skipped", "err", err)
+		m.reportError()
 		return e
 	}
 
+	// Compress the packed object if configured, base64 encoding it so that it
+	// remains a valid log line.
+	if m.cfg.Compress != "" {
+		wl, err = compressPacked(wl, m.cfg.Compress)
+		if err != nil {
+			level.Debug(m.logger).Log("msg", "pack stage failed to compress packed object, packing will be skipped", "err", err)
+			m.reportError()
+			return e
+		}
+	}
+
 	// Remove anything found which is also a label, do this after the marshalling to not remove labels until
 	// we are sure the line can be successfully packed.
 	for _, fl := range foundLabels {
@@ -194,6 +239,32 @@ func (m *packStage) pack(e Entry) Entry {
 	return e
 }
 
+// compressPacked compresses a packed object with the given algorithm and
+// returns it base64 encoded.
+func compressPacked(b []byte, compression string) ([]byte, error) {
+	var compressed []byte
+	switch compression {
+	case PackCompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		compressed = buf.Bytes()
+	case PackCompressionSnappy:
+		compressed = snappy.Encode(nil, b)
+	default:
+		return nil, fmt.Errorf("unknown compression %q", compression)
+	}
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(compressed)))
+	base64.StdEncoding.Encode(encoded, compressed)
+	return encoded, nil
+}
+
 // Name implements Stage
 func (m *packStage) Name() string {
 	return StageTypePack
@@ -1,9 +1,15 @@
 package stages
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	json "github.com/json-iterator/go"
@@ -337,7 +343,8 @@ func TestPackStage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := util.TestAlloyLogger(t)
-			m := newPackStage(logger, *tt.config, prometheus.DefaultRegisterer)
+			m, err := newPackStage(logger, *tt.config, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
 			// Normal pipeline operation will put all the labels into the extracted map
 			// replicate that here.
 			for labelName, labelValue := range tt.inputEntry.Labels {
@@ -356,3 +363,80 @@ func TestPackStage(t *testing.T) {
 		})
 	}
 }
+
+var testPackCompressAlloy = `
+stage.pack {
+    labels   = ["container"]
+    compress = "%[1]s"
+}
+stage.decode {
+    encoding = "base64"
+}
+stage.decode {
+    encoding = "%[1]s"
+}
+stage.json {
+    expressions = { container = "", _entry = "" }
+}
+stage.output {
+    source = "_entry"
+}
+`
+
+func TestPackStage_Compress(t *testing.T) {
+	expected := `{"container":"foo","` + logqlmodel.PackedEntryKey + `":"test line"}`
+
+	tests := map[string]func(t *testing.T, b []byte) string{
+		PackCompressionGzip: func(t *testing.T, b []byte) string {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			require.NoError(t, err)
+			decompressed, err := io.ReadAll(r)
+			require.NoError(t, err)
+			return string(decompressed)
+		},
+		PackCompressionSnappy: func(t *testing.T, b []byte) string {
+			decompressed, err := snappy.Decode(nil, b)
+			require.NoError(t, err)
+			return string(decompressed)
+		},
+	}
+	for compression, decompress := range tests {
+		t.Run(compression, func(t *testing.T) {
+			m, err := newPackStage(util.TestAlloyLogger(t), PackConfig{
+				Labels:   []string{"container"},
+				Compress: compression,
+			}, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			out := processEntries(m, newEntry(map[string]interface{}{"container": "foo"}, model.LabelSet{"container": "foo"}, "test line", time.Now()))
+			require.Len(t, out, 1)
+			assert.Empty(t, out[0].Labels)
+
+			b, err := base64.StdEncoding.DecodeString(out[0].Line)
+			require.NoError(t, err)
+			assert.Equal(t, expected, decompress(t, b))
+		})
+	}
+}
+
+func TestPackStage_CompressDecodePipeline(t *testing.T) {
+	for _, compression := range []string{PackCompressionGzip, PackCompressionSnappy} {
+		t.Run(compression, func(t *testing.T) {
+			cfg := fmt.Sprintf(testPackCompressAlloy, compression)
+			pl, err := NewPipeline(util.TestAlloyLogger(t), loadConfig(cfg), nil, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			out := processEntries(pl, newEntry(map[string]interface{}{"container": "foo"}, model.LabelSet{"container": "foo"}, "test line", time.Now()))
+			require.Len(t, out, 1)
+			assert.Equal(t, "test line", out[0].Line)
+			assert.Equal(t, "foo", out[0].Extracted["container"])
+		})
+	}
+}
+
+func TestPackConfig_Validate(t *testing.T) {
+	require.NoError(t, (&PackConfig{}).Validate())
+	require.NoError(t, (&PackConfig{Compress: PackCompressionGzip}).Validate())
+	require.NoError(t, (&PackConfig{Compress: PackCompressionSnappy}).Validate())
+	require.Equal(t, ErrPackInvalidCompression, (&PackConfig{Compress: "zstd"}).Validate())
+}
@@ -3,7 +3,9 @@ package stages
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,44 +18,98 @@ import (
 // We define these as pointers types so we can use reflection to check that
 // exactly one is set.
 type StageConfig struct {
-	CRIConfig             *CRIConfig             `alloy:"cri,block,optional"`
-	DecolorizeConfig      *DecolorizeConfig      `alloy:"decolorize,block,optional"`
-	DockerConfig          *DockerConfig          `alloy:"docker,block,optional"`
-	DropConfig            *DropConfig            `alloy:"drop,block,optional"`
-	EventLogMessageConfig *EventLogMessageConfig `alloy:"eventlogmessage,block,optional"`
-	GeoIPConfig           *GeoIPConfig           `alloy:"geoip,block,optional"`
-	JSONConfig            *JSONConfig            `alloy:"json,block,optional"`
-	LabelAllowConfig      *LabelAllowConfig      `alloy:"label_keep,block,optional"`
-	LabelDropConfig       *LabelDropConfig       `alloy:"label_drop,block,optional"`
-	LabelsConfig          *LabelsConfig          `alloy:"labels,block,optional"`
-	LimitConfig           *LimitConfig           `alloy:"limit,block,optional"`
-	LogfmtConfig          *LogfmtConfig          `alloy:"logfmt,block,optional"`
-	LuhnFilterConfig      *LuhnFilterConfig      `alloy:"luhn,block,optional"`
-	MatchConfig           *MatchConfig           `alloy:"match,block,optional"`
-	MetricsConfig         *MetricsConfig         `alloy:"metrics,block,optional"`
-	MultilineConfig       *MultilineConfig       `alloy:"multiline,block,optional"`
-	OutputConfig          *OutputConfig          `alloy:"output,block,optional"`
-	PackConfig            *PackConfig            `alloy:"pack,block,optional"`
-	RegexConfig           *RegexConfig           `alloy:"regex,block,optional"`
-	ReplaceConfig         *ReplaceConfig         `alloy:"replace,block,optional"`
-	StaticLabelsConfig    *StaticLabelsConfig    `alloy:"static_labels,block,optional"`
-	StructuredMetadata    *LabelsConfig          `alloy:"structured_metadata,block,optional"`
-	SamplingConfig        *SamplingConfig        `alloy:"sampling,block,optional"`
-	TemplateConfig        *TemplateConfig        `alloy:"template,block,optional"`
-	TenantConfig          *TenantConfig          `alloy:"tenant,block,optional"`
-	TimestampConfig       *TimestampConfig       `alloy:"timestamp,block,optional"`
+	AggregateConfig       *AggregateConfig          `alloy:"aggregate,block,optional"`
+	AutoParseConfig       *AutoParseConfig          `alloy:"auto_parse,block,optional"`
+	CRIConfig             *CRIConfig                `alloy:"cri,block,optional"`
+	CSVConfig             *CSVConfig                `alloy:"csv,block,optional"`
+	DecodeConfig          *DecodeConfig             `alloy:"decode,block,optional"`
+	DecolorizeConfig      *DecolorizeConfig         `alloy:"decolorize,block,optional"`
+	DedupConfig           *DedupConfig              `alloy:"dedup,block,optional"`
+	DockerConfig          *DockerConfig             `alloy:"docker,block,optional"`
+	DropConfig            *DropConfig               `alloy:"drop,block,optional"`
+	EventLogMessageConfig *EventLogMessageConfig    `alloy:"eventlogmessage,block,optional"`
+	FingerprintConfig     *FingerprintConfig        `alloy:"fingerprint,block,optional"`
+	GeoIPConfig           *GeoIPConfig              `alloy:"geoip,block,optional"`
+	GrokConfig            *GrokConfig               `alloy:"grok,block,optional"`
+	JSONConfig            *JSONConfig               `alloy:"json,block,optional"`
+	KeyValueConfig        *KeyValueConfig           `alloy:"key_value,block,optional"`
+	LabelAllowConfig      *LabelAllowConfig         `alloy:"label_keep,block,optional"`
+	LabelDropConfig       *LabelDropConfig          `alloy:"label_drop,block,optional"`
+	LabelFromPathConfig   *LabelFromPathConfig      `alloy:"label_from_path,block,optional"`
+	LabelsConfig          *LabelsConfig             `alloy:"labels,block,optional"`
+	LimitConfig           *LimitConfig              `alloy:"limit,block,optional"`
+	LogfmtConfig          *LogfmtConfig             `alloy:"logfmt,block,optional"`
+	LuhnFilterConfig      *LuhnFilterConfig         `alloy:"luhn,block,optional"`
+	MatchConfig           *MatchConfig              `alloy:"match,block,optional"`
+	MetricsConfig         *MetricsConfig            `alloy:"metrics,block,optional"`
+	MultilineConfig       *MultilineConfig          `alloy:"multiline,block,optional"`
+	OutputConfig          *OutputConfig             `alloy:"output,block,optional"`
+	PackConfig            *PackConfig               `alloy:"pack,block,optional"`
+	RedactConfig          *RedactConfig             `alloy:"redact,block,optional"`
+	RegexConfig           *RegexConfig              `alloy:"regex,block,optional"`
+	ReplaceConfig         *ReplaceConfig            `alloy:"replace,block,optional"`
+	SeverityConfig        *SeverityConfig           `alloy:"severity,block,optional"`
+	StaticLabelsConfig    *StaticLabelsConfig       `alloy:"static_labels,block,optional"`
+	StructuredMetadata    *StructuredMetadataConfig `alloy:"structured_metadata,block,optional"`
+	SamplingConfig        *SamplingConfig           `alloy:"sampling,block,optional"`
+	TemplateConfig        *TemplateConfig           `alloy:"template,block,optional"`
+	TenantConfig          *TenantConfig             `alloy:"tenant,block,optional"`
+	TimestampConfig       *TimestampConfig          `alloy:"timestamp,block,optional"`
+	TruncateConfig        *TruncateConfig           `alloy:"truncate,block,optional"`
 }
 
 var rateLimiter *rate.Limiter
 var rateLimiterDrop bool
 var rateLimiterDropReason = "global_rate_limiter_drop"
 
+// StageTap is called with every entry leaving a stage of a pipeline, along
+// with the index of the stage in the pipeline and its name. The entry must not
+// be modified or retained after the call returns.
+type StageTap func(index int, name string, e Entry)
+
 // Pipeline pass down a log entry to each stage for mutation and/or label extraction.
 type Pipeline struct {
 	logger    log.Logger
 	stages    []Stage
 	jobName   *string
 	dropCount *prometheus.CounterVec
+	tap       StageTap
+	metrics   *stageMetrics
+}
+
+// stageMetrics holds the metrics reported for each stage of a pipeline.
+type stageMetrics struct {
+	duration *prometheus.HistogramVec
+	received *prometheus.CounterVec
+	sent     *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+}
+
+func newStageMetrics(registerer prometheus.Registerer) *stageMetrics {
+	labels := []string{"stage_index", "stage_type"}
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "loki_process",
+		Name:      "stage_duration_seconds",
+		Help:      "Time spent by each stage of the pipeline processing a log line",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, labels)
+	if err := registerer.Register(duration); err != nil {
+		if existing, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			duration = existing.ExistingCollector.(*prometheus.HistogramVec)
+		} else {
+			// Same behavior as MustRegister if the error is not for AlreadyRegistered
+			panic(err)
+		}
+	}
+	return &stageMetrics{
+		duration: duration,
+		received: registerCounterVec(registerer, "loki_process", "stage_received_lines_total",
+			"A count of all log lines received by each stage of the pipeline", labels),
+		sent: registerCounterVec(registerer, "loki_process", "stage_sent_lines_total",
+			"A count of all log lines sent to the next stage by each stage of the pipeline", labels),
+		errors: registerCounterVec(registerer, "loki_process", "stage_errors_total",
+			"A count of all log lines each stage of the pipeline failed to process", labels),
+	}
 }
 
 // NewPipeline creates a new log entry pipeline from a configuration
@@ -80,6 +136,10 @@ func RunWith(input chan Entry, process func(e Entry) Entry) chan Entry {
 	go func() {
 		defer close(out)
 		for e := range input {
+			if e.skip {
+				out <- e
+				continue
+			}
 			out <- process(e)
 		}
 	}()
@@ -93,6 +153,10 @@ func RunWithSkipOrSendMany(input chan Entry, process func(e Entry) ([]Entry, boo
 	go func() {
 		defer close(out)
 		for e := range input {
+			if e.skip {
+				out <- e
+				continue
+			}
 			results, skip := process(e)
 			if skip {
 				continue
@@ -116,13 +180,109 @@ func (p *Pipeline) Run(in chan Entry) chan Entry {
 		}
 		return e
 	})
-	// chain all stages together.
-	for _, m := range p.stages {
-		in = m.Run(in)
+	if p.tap == nil && p.metrics == nil {
+		// chain all stages together.
+		for _, m := range p.stages {
+			in = m.Run(in)
+		}
+		return in
+	}
+
+	// Observe the entries between each stage.
+	in = p.observe(in, -1)
+	for i, m := range p.stages {
+		in = p.observe(m.Run(in), i)
 	}
 	return in
 }
 
+// observe forwards the entries leaving the stage at the given index to the
+// next stage, and calls the stage tap and updates the stage metrics on the way.
+// An index of -1 observes the entries before the first stage.
+func (p *Pipeline) observe(in chan Entry, index int) chan Entry {
+	var (
+		name  string
+		label string
+		idle  time.Time
+		next  = index + 1
+	)
+	if index >= 0 {
+		name = p.stages[index].Name()
+		label = strconv.Itoa(index + 1)
+	}
+	var nextLabel, nextName string
+	if next < len(p.stages) {
+		nextName = p.stages[next].Name()
+		nextLabel = strconv.Itoa(next + 1)
+	}
+
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		for e := range in {
+			now := time.Now()
+			if index >= 0 {
+				if p.metrics != nil {
+					p.metrics.sent.WithLabelValues(label, name).Inc()
+					// Entries created by the stage aren't timed. The time the
+					// stage was blocked sending the previous entry to the next
+					// stage isn't counted as processing time.
+					if !e.stageStart.IsZero() {
+						start := e.stageStart
+						if idle.After(start) {
+							start = idle
+						}
+						p.metrics.duration.WithLabelValues(label, name).Observe(now.Sub(start).Seconds())
+					}
+				}
+				if p.tap != nil {
+					p.tap(index, name, e)
+				}
+			}
+
+			e.stageStart = time.Time{}
+			if next < len(p.stages) && p.metrics != nil {
+				p.metrics.received.WithLabelValues(nextLabel, nextName).Inc()
+				e.stageStart = time.Now()
+			}
+			out <- e
+			idle = time.Now()
+		}
+	}()
+	return out
+}
+
+// EnableStageMetrics registers the metrics of each stage of the pipeline. It
+// must be called before the pipeline is run.
+func (p *Pipeline) EnableStageMetrics(registerer prometheus.Registerer) {
+	p.metrics = newStageMetrics(registerer)
+	for i, s := range p.stages {
+		labels := []string{strconv.Itoa(i + 1), s.Name()}
+		if !setErrorCounter(s, p.metrics.errors.WithLabelValues(labels...)) {
+			p.metrics.errors.DeleteLabelValues(labels...)
+		}
+	}
+}
+
+// setErrorCounter counts the errors of all the stages of a nested pipeline
+// with the counter of the stage which nests it.
+func (p *Pipeline) setErrorCounter(c prometheus.Counter) bool {
+	if p == nil {
+		return false
+	}
+	var ok bool
+	for _, s := range p.stages {
+		ok = setErrorCounter(s, c) || ok
+	}
+	return ok
+}
+
+// SetStageTap sets a function which is called with every entry leaving each
+// stage of the pipeline. It must be called before the pipeline is run.
+func (p *Pipeline) SetStageTap(tap StageTap) {
+	p.tap = tap
+}
+
 // Name implements Stage
 func (p *Pipeline) Name() string {
 	return StageTypePipeline
@@ -176,6 +336,15 @@ func (p *Pipeline) Wrap(next loki.EntryHandler) loki.EntryHandler {
 	})
 }
 
+// StageNames returns the names of the stages of the pipeline, in order.
+func (p *Pipeline) StageNames() []string {
+	names := make([]string, 0, len(p.stages))
+	for _, s := range p.stages {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
 // Size gets the current number of stages in the pipeline
 func (p *Pipeline) Size() int {
 	return len(p.stages)
@@ -2,6 +2,7 @@ package stages
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -278,6 +280,105 @@ func BenchmarkPipeline(b *testing.B) {
 	}
 }
 
+func TestPipeline_StageTap(t *testing.T) {
+	p, err := NewPipeline(util_log.Logger, loadConfig(testLabelsFromJSONAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	var taps []string
+	p.SetStageTap(func(index int, name string, e Entry) {
+		taps = append(taps, fmt.Sprintf("%d %s %s %s %v", index, name, e.Line, e.Labels, e.Extracted["message"]))
+	})
+
+	out := processEntries(p, newEntry(nil, nil, `{"app":"loki","message":"hello"}`, time.Now()))
+	require.Len(t, out, 1)
+	assert.Equal(t, []string{
+		`0 json {"app":"loki","message":"hello"} {} hello`,
+		`1 labels {"app":"loki","message":"hello"} {app="loki"} hello`,
+		`2 output hello {app="loki"} hello`,
+	}, taps)
+}
+
+var testStageMetricsAlloy = `
+stage.json {
+		expressions = { "app" = "", "level" = "" }
+}
+stage.drop {
+		source = "level"
+		value  = "debug"
+}
+stage.labels {
+		values = { "app" = "" }
+}`
+
+func TestPipeline_StageMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p, err := NewPipeline(util_log.Logger, loadConfig(testStageMetricsAlloy), nil, registry)
+	require.NoError(t, err)
+	p.EnableStageMetrics(registry)
+
+	out := processEntries(p,
+		newEntry(nil, nil, `{"app":"loki","level":"debug"}`, time.Now()),
+		newEntry(nil, nil, `{"app":"loki","level":"info"}`, time.Now()),
+		newEntry(nil, nil, `not json`, time.Now()),
+	)
+	require.Len(t, out, 2)
+	assert.True(t, out[0].stageStart.IsZero())
+
+	expected := `
+# HELP loki_process_stage_errors_total A count of all log lines each stage of the pipeline failed to process
+# TYPE loki_process_stage_errors_total counter
+loki_process_stage_errors_total{stage_index="1",stage_type="json"} 1
+# HELP loki_process_stage_received_lines_total A count of all log lines received by each stage of the pipeline
+# TYPE loki_process_stage_received_lines_total counter
+loki_process_stage_received_lines_total{stage_index="1",stage_type="json"} 3
+loki_process_stage_received_lines_total{stage_index="2",stage_type="drop"} 3
+loki_process_stage_received_lines_total{stage_index="3",stage_type="labels"} 2
+# HELP loki_process_stage_sent_lines_total A count of all log lines sent to the next stage by each stage of the pipeline
+# TYPE loki_process_stage_sent_lines_total counter
+loki_process_stage_sent_lines_total{stage_index="1",stage_type="json"} 3
+loki_process_stage_sent_lines_total{stage_index="2",stage_type="drop"} 2
+loki_process_stage_sent_lines_total{stage_index="3",stage_type="labels"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loki_process_stage_errors_total", "loki_process_stage_received_lines_total", "loki_process_stage_sent_lines_total"))
+	require.Equal(t, 3, testutil.CollectAndCount(registry, "loki_process_stage_duration_seconds"))
+}
+
+var testStageErrorsAlloy = `
+stage.match {
+		selector = "{app=\"loki\"}"
+		stage.logfmt {
+				mapping = { "level" = "" }
+		}
+}
+stage.timestamp {
+		when   = "app == \"loki\""
+		source = "ts"
+		format = "RFC3339"
+}`
+
+func TestPipeline_StageErrors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p, err := NewPipeline(util_log.Logger, loadConfig(testStageErrorsAlloy), nil, registry)
+	require.NoError(t, err)
+	p.EnableStageMetrics(registry)
+
+	processEntries(p,
+		newEntry(map[string]interface{}{"ts": "yesterday"}, model.LabelSet{"app": "loki"}, `level="unterminated`, time.Now()),
+		newEntry(map[string]interface{}{"ts": "yesterday"}, model.LabelSet{"app": "other"}, `level="unterminated`, time.Now()),
+	)
+
+	// The errors of nested and conditional stages are counted for the stage
+	// of the pipeline.
+	expected := `
+# HELP loki_process_stage_errors_total A count of all log lines each stage of the pipeline failed to process
+# TYPE loki_process_stage_errors_total counter
+loki_process_stage_errors_total{stage_index="1",stage_type="match"} 1
+loki_process_stage_errors_total{stage_index="2",stage_type="timestamp"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "loki_process_stage_errors_total"))
+}
+
 func TestPipeline_Wrap(t *testing.T) {
 	now := time.Now()
 	p, err := NewPipeline(util_log.Logger, loadConfig(testMultiStageAlloy), nil, prometheus.DefaultRegisterer)
@@ -0,0 +1,456 @@
+package stages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+// Redact actions.
+const (
+	RedactActionReplace = "replace"
+	RedactActionHash    = "hash"
+	RedactActionMask    = "mask"
+)
+
+// Configuration errors.
+var (
+	ErrRedactNoDetectors         = errors.New("redact stage requires at least one detector")
+	ErrRedactDetectorName        = errors.New("redact stage detector requires a name")
+	ErrRedactDuplicateDetector   = errors.New("redact stage detector names must be unique")
+	ErrRedactUnknownDetector     = errors.New("redact stage detector requires an expression unless it is one of the built-in detectors")
+	ErrRedactInvalidAction       = errors.New("redact stage detector action must be one of \"replace\", \"hash\" or \"mask\"")
+	ErrRedactInvalidMaskChar     = errors.New("redact stage detector mask_character must be a single character")
+	ErrRedactInvalidUnmaskedSize = errors.New("redact stage detector unmasked_prefix and unmasked_suffix must not be negative")
+	ErrEmptyRedactStageSource    = errors.New("empty source")
+)
+
+// builtinRedactDetector is a detector which can be referenced by name only.
+type builtinRedactDetector struct {
+	expression string
+	// validate optionally filters out false positives of the expression.
+	validate func(match string) bool
+	// spans optionally returns the parts of a match to redact, for
+	// expressions which may match more than the sensitive data, for example
+	// a card number followed by other numbers.
+	spans func(match string) [][2]int
+}
+
+var builtinRedactDetectors = map[string]builtinRedactDetector{
+	"aws_access_key": {
+		expression: `\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`,
+	},
+	"credit_card": {
+		expression: `\b(?:[0-9][ \-]?){12,18}[0-9]\b`,
+		// Only redact card numbers with a valid check digit.
+		spans: cardNumberSpans,
+	},
+	"email": {
+		expression: `[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`,
+	},
+	"iban": {
+		expression: `\b[A-Z]{2}[0-9]{2}(?: ?[A-Z0-9]){11,30}\b`,
+		spans:      ibanSpans,
+	},
+	"ipv4": {
+		expression: `\b(?:(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\b`,
+	},
+	"ipv6": {
+		expression: `(?i)(?:[0-9a-f]{0,4}:){2,7}(?:[0-9a-f]{0,4}|(?:[0-9]{1,3}\.){3}[0-9]{1,3})`,
+		// The expression also matches things like timestamps, so only
+		// redact the matches which are valid addresses.
+		validate: func(match string) bool {
+			return net.ParseIP(match) != nil
+		},
+	},
+	"phone": {
+		expression: `(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{3}\)|\b\d{3})[\s.\-]?\d{3}[\s.\-]?\d{4}\b`,
+	},
+	"ssn": {
+		expression: `\b[0-9]{3}-[0-9]{2}-[0-9]{4}\b`,
+		// Area numbers 000, 666 and 900-999, group number 00 and serial
+		// number 0000 are never assigned.
+		validate: func(match string) bool {
+			area, group, serial := match[0:3], match[4:6], match[7:11]
+			return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+		},
+	},
+}
+
+// stripSeparators removes the spaces and dashes from s.
+func stripSeparators(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
+
+// isLuhnString returns whether a string of digits has a valid Luhn check
+// digit. Unlike isLuhn, it supports numbers which overflow an int.
+func isLuhnString(digits string) bool {
+	var sum int
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// isValidIBAN returns whether s is an IBAN with valid check digits.
+func isValidIBAN(s string) bool {
+	s = stripSeparators(s)
+	// Move the country code and check digits to the end, then compute the
+	// remainder of the number formed by replacing letters with 10 to 35.
+	s = s[4:] + s[:4]
+	var remainder int
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// ibanLengths holds the length of the IBANs of each country.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BI": 27, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24,
+	"DE": 22, "DJ": 27, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18,
+	"FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27,
+	"GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20,
+	"LV": 21, "LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27,
+	"MT": 31, "MU": 30, "NI": 28, "NL": 18, "NO": 15, "OM": 23, "PK": 24, "PL": 28,
+	"PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33, "SA": 24, "SC": 31,
+	"SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// tokens returns the start and end offsets of the parts of s separated by
+// any of the characters in seps.
+func tokens(s string, seps string) [][2]int {
+	var res [][2]int
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || strings.IndexByte(seps, s[i]) >= 0 {
+			if start >= 0 {
+				res = append(res, [2]int{start, i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return res
+}
+
+// cardNumberSpans returns the card numbers with a valid check digit in a
+// match of the credit_card expression. A card number starts and ends at
+// separators, so that the numbers around it don't hide it.
+func cardNumberSpans(match string) [][2]int {
+	var spans [][2]int
+	toks := tokens(match, " -")
+	for i := 0; i < len(toks); i++ {
+		// Prefer the longest card number starting at this token.
+		for j := len(toks) - 1; j >= i; j-- {
+			digits := stripSeparators(match[toks[i][0]:toks[j][1]])
+			if len(digits) >= 13 && len(digits) <= 19 && isLuhnString(digits) {
+				spans = append(spans, [2]int{toks[i][0], toks[j][1]})
+				i = j
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// ibanSpans returns the IBANs with valid check digits in a match of the
+// iban expression. The length of the IBANs of known countries is fixed, so
+// the characters following them are ignored. Otherwise, IBANs start and end
+// at spaces.
+func ibanSpans(match string) [][2]int {
+	var spans [][2]int
+	toks := tokens(match, " ")
+	for i := 0; i < len(toks); i++ {
+		start := toks[i][0]
+		if !isIBANPrefix(match[start:toks[i][1]]) {
+			continue
+		}
+		if length, ok := ibanLengths[match[start:start+2]]; ok {
+			end, n := start, 0
+			for ; end < len(match) && n < length; end++ {
+				if match[end] != ' ' {
+					n++
+				}
+			}
+			if n == length && isValidIBAN(match[start:end]) {
+				spans = append(spans, [2]int{start, end})
+				for i+1 < len(toks) && toks[i+1][0] < end {
+					i++
+				}
+			}
+			continue
+		}
+		for j := len(toks) - 1; j >= i; j-- {
+			iban := stripSeparators(match[start:toks[j][1]])
+			if len(iban) >= 15 && len(iban) <= 34 && isValidIBAN(iban) {
+				spans = append(spans, [2]int{start, toks[j][1]})
+				i = j
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// isIBANPrefix returns whether s starts with a country code and two check
+// digits.
+func isIBANPrefix(s string) bool {
+	return len(s) >= 4 &&
+		s[0] >= 'A' && s[0] <= 'Z' && s[1] >= 'A' && s[1] <= 'Z' &&
+		s[2] >= '0' && s[2] <= '9' && s[3] >= '0' && s[3] <= '9'
+}
+
+// RedactDetectorConfig configures a single detector of a redact stage.
+type RedactDetectorConfig struct {
+	Name           string            `alloy:"name,attr"`
+	Expression     string            `alloy:"expression,attr,optional"`
+	Action         string            `alloy:"action,attr,optional"`
+	Replacement    string            `alloy:"replacement,attr,optional"`
+	Salt           alloytypes.Secret `alloy:"salt,attr,optional"`
+	MaskCharacter  string            `alloy:"mask_character,attr,optional"`
+	UnmaskedPrefix int               `alloy:"unmasked_prefix,attr,optional"`
+	UnmaskedSuffix int               `alloy:"unmasked_suffix,attr,optional"`
+}
+
+// DefaultRedactDetectorConfig sets the defaults for RedactDetectorConfig.
+var DefaultRedactDetectorConfig = RedactDetectorConfig{
+	Action:        RedactActionReplace,
+	Replacement:   "**REDACTED**",
+	MaskCharacter: "*",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *RedactDetectorConfig) SetToDefault() {
+	*c = DefaultRedactDetectorConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *RedactDetectorConfig) Validate() error {
+	if c.Name == "" {
+		return ErrRedactDetectorName
+	}
+	if _, ok := builtinRedactDetectors[c.Name]; !ok && c.Expression == "" {
+		return fmt.Errorf("%w: %q", ErrRedactUnknownDetector, c.Name)
+	}
+	switch c.Action {
+	case RedactActionReplace, RedactActionHash:
+	case RedactActionMask:
+		if utf8.RuneCountInString(c.MaskCharacter) != 1 {
+			return ErrRedactInvalidMaskChar
+		}
+		if c.UnmaskedPrefix < 0 || c.UnmaskedSuffix < 0 {
+			return ErrRedactInvalidUnmaskedSize
+		}
+	default:
+		return ErrRedactInvalidAction
+	}
+	return nil
+}
+
+// RedactConfig configures a processing stage that redacts sensitive data.
+type RedactConfig struct {
+	Detectors []RedactDetectorConfig `alloy:"detector,block"`
+	Source    *string                `alloy:"source,attr,optional"`
+	When      string                 `alloy:"when,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (c *RedactConfig) Validate() error {
+	if len(c.Detectors) == 0 {
+		return ErrRedactNoDetectors
+	}
+	if c.Source != nil && *c.Source == "" {
+		return ErrEmptyRedactStageSource
+	}
+	seen := make(map[string]struct{}, len(c.Detectors))
+	for i := range c.Detectors {
+		if err := c.Detectors[i].Validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[c.Detectors[i].Name]; ok {
+			return fmt.Errorf("%w: %q", ErrRedactDuplicateDetector, c.Detectors[i].Name)
+		}
+		seen[c.Detectors[i].Name] = struct{}{}
+	}
+	return nil
+}
+
+// redactDetector is a compiled detector.
+type redactDetector struct {
+	cfg        RedactDetectorConfig
+	expression *regexp.Regexp
+	validate   func(match string) bool
+	spans      func(match string) [][2]int
+	redacted   prometheus.Counter
+}
+
+// redact replaces all the matches of the detector in the input.
+func (d *redactDetector) redact(input string) string {
+	return d.expression.ReplaceAllStringFunc(input, func(match string) string {
+		if d.validate != nil && !d.validate(match) {
+			return match
+		}
+		if d.spans == nil {
+			return d.replace(match)
+		}
+		var sb strings.Builder
+		last := 0
+		for _, span := range d.spans(match) {
+			sb.WriteString(match[last:span[0]])
+			sb.WriteString(d.replace(match[span[0]:span[1]]))
+			last = span[1]
+		}
+		sb.WriteString(match[last:])
+		return sb.String()
+	})
+}
+
+// replace returns the replacement of sensitive data.
+func (d *redactDetector) replace(match string) string {
+	d.redacted.Inc()
+	switch d.cfg.Action {
+	case RedactActionHash:
+		sum := sha256.Sum256([]byte(string(d.cfg.Salt) + match))
+		return hex.EncodeToString(sum[:])
+	case RedactActionMask:
+		return maskString(match, d.cfg.MaskCharacter, d.cfg.UnmaskedPrefix, d.cfg.UnmaskedSuffix)
+	default:
+		return d.cfg.Replacement
+	}
+}
+
+// maskString replaces every character of s with mask, except for the first
+// prefix and last suffix characters. If s is too short, it is fully masked.
+func maskString(s, mask string, prefix, suffix int) string {
+	runes := []rune(s)
+	if prefix+suffix >= len(runes) {
+		prefix, suffix = 0, 0
+	}
+	var sb strings.Builder
+	sb.WriteString(string(runes[:prefix]))
+	sb.WriteString(strings.Repeat(mask, len(runes)-prefix-suffix))
+	sb.WriteString(string(runes[len(runes)-suffix:]))
+	return sb.String()
+}
+
+// redactStage replaces sensitive data found by its detectors.
+type redactStage struct {
+	config    *RedactConfig
+	detectors []*redactDetector
+	logger    log.Logger
+}
+
+// newRedactStage creates a new redact pipeline stage from a config.
+func newRedactStage(logger log.Logger, config RedactConfig, registerer prometheus.Registerer) (Stage, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	redactedCount := registerCounterVec(registerer, "loki_process", "redacted_matches_total",
+		"A count of all matches redacted by a redact stage detector", []string{"detector"})
+
+	detectors := make([]*redactDetector, 0, len(config.Detectors))
+	for _, dc := range config.Detectors {
+		builtin := builtinRedactDetectors[dc.Name]
+		expression := builtin.expression
+		validate, spans := builtin.validate, builtin.spans
+		if dc.Expression != "" {
+			expression, validate, spans = dc.Expression, nil, nil
+		}
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("%v for detector %q: %w", ErrCouldNotCompileRegex, dc.Name, err)
+		}
+		detectors = append(detectors, &redactDetector{
+			cfg:        dc,
+			expression: re,
+			validate:   validate,
+			spans:      spans,
+			redacted:   redactedCount.WithLabelValues(dc.Name),
+		})
+	}
+
+	return toStage(&redactStage{
+		config:    &config,
+		detectors: detectors,
+		logger:    log.With(logger, "component", "stage", "type", "redact"),
+	}), nil
+}
+
+// Process implements Stage
+func (r *redactStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the redact stage should process it
+	// from the extracted map, otherwise should fall back to the entry
+	input := entry
+
+	if r.config.Source != nil {
+		if _, ok := extracted[*r.config.Source]; !ok {
+			level.Debug(r.logger).Log("msg", "source does not exist in the set of extracted values", "source", *r.config.Source)
+			return
+		}
+
+		value, err := getString(extracted[*r.config.Source])
+		if err != nil {
+			level.Debug(r.logger).Log("msg", "failed to convert source value to string", "source", *r.config.Source, "err", err, "type", reflect.TypeOf(extracted[*r.config.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(r.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	result := *input
+	for _, d := range r.detectors {
+		result = d.redact(result)
+	}
+
+	if r.config.Source != nil {
+		extracted[*r.config.Source] = result
+	} else {
+		*entry = result
+	}
+}
+
+// Name implements Stage
+func (r *redactStage) Name() string {
+	return StageTypeRedact
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/mitchellh/mapstructure"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 )
 
@@ -18,18 +20,30 @@ var (
 	ErrExpressionRequired    = errors.New("expression is required")
 	ErrCouldNotCompileRegex  = errors.New("could not compile regular expression")
 	ErrEmptyRegexStageSource = errors.New("empty source")
+	ErrRegexExpressionBoth   = errors.New("only one of expression or expressions can be set")
 )
 
 // RegexConfig configures a processing stage uses regular expressions to
 // extract values from log lines into the shared values map.
 type RegexConfig struct {
-	Expression string  `alloy:"expression,attr"`
-	Source     *string `alloy:"source,attr,optional"`
+	Expression  string   `alloy:"expression,attr,optional"`
+	Expressions []string `alloy:"expressions,attr,optional"`
+	Source      *string  `alloy:"source,attr,optional"`
+	When        string   `alloy:"when,attr,optional"`
 }
 
-// validateRegexConfig validates the config and return a regex
-func validateRegexConfig(c RegexConfig) (*regexp.Regexp, error) {
-	if c.Expression == "" {
+// validateRegexConfig validates the config and returns the regexes, in the
+// order they are tried.
+func validateRegexConfig(c RegexConfig) ([]*regexp.Regexp, error) {
+	if c.Expression != "" && len(c.Expressions) > 0 {
+		return nil, ErrRegexExpressionBoth
+	}
+
+	expressions := c.Expressions
+	if c.Expression != "" {
+		expressions = []string{c.Expression}
+	}
+	if len(expressions) == 0 || slices.Contains(expressions, "") {
 		return nil, ErrExpressionRequired
 	}
 
@@ -37,32 +51,48 @@ func validateRegexConfig(c RegexConfig) (*regexp.Regexp, error) {
 		return nil, ErrEmptyRegexStageSource
 	}
 
-	expr, err := regexp.Compile(c.Expression)
-	if err != nil {
-		return nil, fmt.Errorf("%v: %w", ErrCouldNotCompileRegex, err)
+	regexes := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		expr, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", ErrCouldNotCompileRegex, err)
+		}
+		regexes = append(regexes, expr)
 	}
 
-	return expr, nil
+	return regexes, nil
 }
 
 // regexStage sets extracted data using regular expressions
 type regexStage struct {
-	config     *RegexConfig
-	expression *regexp.Regexp
-	logger     log.Logger
+	config      *RegexConfig
+	expressions []*regexp.Regexp
+	matches     []prometheus.Counter
+	logger      log.Logger
 }
 
 // newRegexStage creates a newRegexStage
-func newRegexStage(logger log.Logger, config RegexConfig) (Stage, error) {
-	expression, err := validateRegexConfig(config)
+func newRegexStage(logger log.Logger, config RegexConfig, registerer prometheus.Registerer) (Stage, error) {
+	expressions, err := validateRegexConfig(config)
 	if err != nil {
 		return nil, err
 	}
-	return toStage(&regexStage{
-		config:     &config,
-		expression: expression,
-		logger:     log.With(logger, "component", "stage", "type", "regex"),
-	}), nil
+
+	r := &regexStage{
+		config:      &config,
+		expressions: expressions,
+		logger:      log.With(logger, "component", "stage", "type", "regex"),
+	}
+	// Matches are only counted when using the expressions argument, to tell
+	// which of the formats the log lines are in.
+	if len(config.Expressions) > 0 {
+		matches := registerCounterVec(registerer, "loki_process", "regex_expression_matches_total",
+			"Number of log lines matched by each expression of a regex stage.", []string{"expression"})
+		for _, expr := range expressions {
+			r.matches = append(r.matches, matches.WithLabelValues(expr.String()))
+		}
+	}
+	return toStage(r), nil
 }
 
 // parseRegexConfig processes an incoming configuration into a RegexConfig
@@ -101,13 +131,26 @@ func (r *regexStage) Process(labels model.LabelSet, extracted map[string]interfa
 		return
 	}
 
-	match := r.expression.FindStringSubmatch(*input)
+	// The expressions are tried in order, and the first one to match is used.
+	var (
+		expression *regexp.Regexp
+		match      []string
+	)
+	for i, expr := range r.expressions {
+		if match = expr.FindStringSubmatch(*input); match != nil {
+			expression = expr
+			if r.matches != nil {
+				r.matches[i].Inc()
+			}
+			break
+		}
+	}
 	if match == nil {
-		level.Debug(r.logger).Log("msg", "regex did not match", "input", *input, "regex", r.expression)
+		level.Debug(r.logger).Log("msg", "regex did not match", "input", *input, "regex", fmt.Sprint(r.expressions))
 		return
 	}
 
-	for i, name := range r.expression.SubexpNames() {
+	for i, name := range expression.SubexpNames() {
 		if i != 0 && name != "" {
 			extracted[name] = match[i]
 		}
@@ -10,8 +10,10 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/util"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var protocolStr = "protocol"
@@ -168,6 +170,25 @@ func TestRegexConfig_validate(t *testing.T) {
 			},
 			nil,
 		},
+		"valid with expressions": {
+			map[string]interface{}{
+				"expressions": []string{"(?P<ts>[0-9]+).*", "(?P<level>[a-z]+).*"},
+			},
+			nil,
+		},
+		"empty expression in expressions": {
+			map[string]interface{}{
+				"expressions": []string{"(?P<ts>[0-9]+).*", ""},
+			},
+			ErrExpressionRequired,
+		},
+		"both expression and expressions": {
+			map[string]interface{}{
+				"expression":  "(?P<ts>[0-9]+).*",
+				"expressions": []string{"(?P<level>[a-z]+).*"},
+			},
+			ErrRegexExpressionBoth,
+		},
 		"valid with source": {
 			map[string]interface{}{
 				"expression": "(?P<ts>[0-9]+).*",
@@ -309,6 +330,41 @@ func TestRegexParser_Parse(t *testing.T) {
 	}
 }
 
+func TestRegexExpressions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	config := `
+stage.regex {
+    expressions = [
+        "^(?P<level>[A-Z]+) (?P<msg>.*)$",
+        "^level=(?P<level>\\S+) msg=(?P<msg>.*)$",
+        "^(?P<msg>.*)$",
+    ]
+}`
+	pl, err := NewPipeline(util.TestAlloyLogger(t), loadConfig(config), nil, registry)
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, nil, "ERROR failed to connect", time.Now()),
+		newEntry(nil, nil, "level=info msg=connected", time.Now()),
+		newEntry(nil, nil, "WARN level=info msg=retrying", time.Now()),
+		newEntry(nil, nil, "connected", time.Now()),
+	)
+	require.Len(t, out, 4)
+	assert.Equal(t, map[string]interface{}{"level": "ERROR", "msg": "failed to connect"}, out[0].Extracted)
+	assert.Equal(t, map[string]interface{}{"level": "info", "msg": "connected"}, out[1].Extracted)
+	// The first expression to match wins.
+	assert.Equal(t, map[string]interface{}{"level": "WARN", "msg": "level=info msg=retrying"}, out[2].Extracted)
+	assert.Equal(t, map[string]interface{}{"msg": "connected"}, out[3].Extracted)
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP loki_process_regex_expression_matches_total Number of log lines matched by each expression of a regex stage.
+# TYPE loki_process_regex_expression_matches_total counter
+loki_process_regex_expression_matches_total{expression="^(?P<level>[A-Z]+) (?P<msg>.*)$"} 2
+loki_process_regex_expression_matches_total{expression="^(?P<msg>.*)$"} 1
+loki_process_regex_expression_matches_total{expression="^level=(?P<level>\\S+) msg=(?P<msg>.*)$"} 1
+`)))
+}
+
 func BenchmarkRegexStage(b *testing.B) {
 	benchmarks := []struct {
 		name   string
@@ -2,6 +2,7 @@ package stages
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -20,11 +21,15 @@ func init() {
 	}
 }
 
+// ErrCouldNotParseReplaceTemplate is returned for an invalid replace template.
+var ErrCouldNotParseReplaceTemplate = errors.New("could not parse replace template")
+
 // ReplaceConfig contains a regexStage configuration
 type ReplaceConfig struct {
 	Expression string `alloy:"expression,attr"`
 	Source     string `alloy:"source,attr,optional"`
 	Replace    string `alloy:"replace,attr,optional"`
+	When       string `alloy:"when,attr,optional"`
 }
 
 func getExpressionRegex(c ReplaceConfig) (*regexp.Regexp, error) {
@@ -41,9 +46,18 @@ func getExpressionRegex(c ReplaceConfig) (*regexp.Regexp, error) {
 
 // replaceStage sets extracted data using regular expressions
 type replaceStage struct {
+	stageErrors
+
 	cfg        ReplaceConfig
 	expression *regexp.Regexp
+	template   *template.Template
 	logger     log.Logger
+
+	// groups holds the capture groups of the current match, which the
+	// template reads with the group and namedGroup functions. They're
+	// functions rather than template data, so that they can't collide with
+	// the extracted values.
+	groups []string
 }
 
 // newReplaceStage creates a newReplaceStage
@@ -53,11 +67,41 @@ func newReplaceStage(logger log.Logger, config ReplaceConfig) (Stage, error) {
 		return nil, err
 	}
 
-	return toStage(&replaceStage{
+	r := &replaceStage{
 		cfg:        config,
 		expression: expression,
 		logger:     log.With(logger, "component", "stage", "type", "replace"),
-	}), nil
+	}
+
+	// Initialize the template with the "replace" string defined by user
+	r.template, err = template.New("pipeline_template").Funcs(functionMap).Funcs(template.FuncMap{
+		"group":      r.group,
+		"namedGroup": r.namedGroup,
+	}).Parse(config.Replace)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrCouldNotParseReplaceTemplate, err)
+	}
+
+	return toStage(r), nil
+}
+
+// group returns the value of the capture group at index i of the current
+// match. The index 0 is the whole match.
+func (r *replaceStage) group(i int) (string, error) {
+	if i < 0 || i >= len(r.groups) {
+		return "", fmt.Errorf("capture group %d doesn't exist", i)
+	}
+	return r.groups[i], nil
+}
+
+// namedGroup returns the value of the named capture group of the current
+// match.
+func (r *replaceStage) namedGroup(name string) (string, error) {
+	i := r.expression.SubexpIndex(name)
+	if i < 0 {
+		return "", fmt.Errorf("capture group %q doesn't exist", name)
+	}
+	return r.group(i)
 }
 
 // Process implements Stage
@@ -98,16 +142,10 @@ func (r *replaceStage) Process(labels model.LabelSet, extracted map[string]inter
 	// All extracted values will be available for templating
 	td := r.getTemplateData(extracted)
 
-	// Initialize the template with the "replace" string defined by user
-	templ, err := template.New("pipeline_template").Funcs(functionMap).Parse(r.cfg.Replace)
-	if err != nil {
-		level.Debug(r.logger).Log("msg", "template initialization error", "err", err)
-		return
-	}
-
-	result, capturedMap, err := r.getReplacedEntry(matchAllIndex, *input, td, templ)
+	result, capturedMap, err := r.getReplacedEntry(matchAllIndex, *input, td, r.template)
 	if err != nil {
 		level.Debug(r.logger).Log("msg", "failed to execute template on extracted value", "err", err)
+		r.reportError()
 		return
 	}
 
@@ -128,7 +166,7 @@ func (r *replaceStage) Process(labels model.LabelSet, extracted map[string]inter
 	level.Debug(r.logger).Log("msg", "extracted data debug in replace stage", "extracted data", fmt.Sprintf("%v", extracted))
 }
 
-func (r *replaceStage) getReplacedEntry(matchAllIndex [][]int, input string, td map[string]string, templ *template.Template) (string, map[string]string, error) {
+func (r *replaceStage) getReplacedEntry(matchAllIndex [][]int, input string, td map[string]interface{}, templ *template.Template) (string, map[string]string, error) {
 	var result string
 	previousInputEndIndex := 0
 	capturedMap := make(map[string]string)
@@ -140,6 +178,16 @@ func (r *replaceStage) getReplacedEntry(matchAllIndex [][]int, input string, td
 	// captured group. Here 0-19 is "11.11.11.11 - frank",  0-11 is "11.11.11.11" and
 	// 14-19 is "frank". So, we advance by 2 index to get the next match
 	for _, matchIndex := range matchAllIndex {
+		// All capture groups of the current match are available for templating
+		r.groups = r.groups[:0]
+		for i := 0; i < len(matchIndex); i += 2 {
+			var group string
+			if matchIndex[i] != -1 {
+				group = input[matchIndex[i]:matchIndex[i+1]]
+			}
+			r.groups = append(r.groups, group)
+		}
+
 		for i := 2; i < len(matchIndex); i += 2 {
 			if matchIndex[i] == -1 {
 				continue
@@ -162,8 +210,8 @@ func (r *replaceStage) getReplacedEntry(matchAllIndex [][]int, input string, td
 	return result + input[previousInputEndIndex:], capturedMap, nil
 }
 
-func (r *replaceStage) getTemplateData(extracted map[string]interface{}) map[string]string {
-	td := make(map[string]string)
+func (r *replaceStage) getTemplateData(extracted map[string]interface{}) map[string]interface{} {
+	td := make(map[string]interface{})
 	for k, v := range extracted {
 		s, err := getString(v)
 		if err != nil {
@@ -50,6 +50,23 @@ stage.replace {
 }
 `
 
+var testReplaceAlloyWithCaptureGroups = `
+stage.replace {
+		expression = "user=(\\S+) token=(?P<token>\\S+)"
+		replace    = "{{ if eq .Value (namedGroup \"token\") }}{{ sha256sum .Value }}{{ else }}{{ substr 0 1 (group 1) | upper }}{{ end }}"
+}
+`
+
+var testReplaceAlloyWithGroupsField = `
+stage.regex {
+		expression = "^(?P<Groups>\\S+)"
+}
+stage.replace {
+		expression = "user=(\\S+)"
+		replace    = "{{ .Groups }}-{{ group 1 }}"
+}
+`
+
 var testReplaceAlloyWithEmptyReplace = `
 stage.replace {
 		expression = "11.11.11.11 - (\\S+\\s)"
@@ -67,6 +84,7 @@ stage.replace {
 var testReplaceLogLine = `11.11.11.11 - frank [25/Jan/2000:14:00:01 -0500] "GET /1986.js HTTP/1.1" 200 932 "-" "Mozilla/5.0 (Windows; U; Windows NT 5.1; de; rv:1.9.1.7) Gecko/20091221 Firefox/3.5.7 GTB6"`
 var testReplaceLogJSONLine = `{"time":"2019-01-01T01:00:00.000000001Z", "level": "info", "msg": "11.11.11.11 - \"POST /loki/api/push/ HTTP/1.1\" 200 932 \"-\" \"Mozilla/5.0 (Windows; U; Windows NT 5.1; de; rv:1.9.1.7) Gecko/20091221 Firefox/3.5.7 GTB6\""}`
 var testReplaceLogLineAdjacentCaptureGroups = `abc`
+var testReplaceLogLineCaptureGroups = `user=frank token=abc`
 
 func TestReplace(t *testing.T) {
 	t.Parallel()
@@ -135,6 +153,22 @@ func TestReplace(t *testing.T) {
 			map[string]interface{}{},
 			`11.11.11.11 - FRANK [25/JAN/2000:14:00:01 -0500] "GET /1986.JS HTTP/1.1" HttpStatusOk 932 "-" "MOZILLA/5.0 (WINDOWS; U; WINDOWS NT 5.1; DE; RV:1.9.1.7) GECKO/20091221 FIREFOX/3.5.7 GTB6"`,
 		},
+		"successfully run a pipeline with capture groups and template functions": {
+			testReplaceAlloyWithCaptureGroups,
+			testReplaceLogLineCaptureGroups,
+			map[string]interface{}{
+				"token": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			},
+			`user=F token=ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad`,
+		},
+		"capture groups don't shadow an extracted value named Groups": {
+			testReplaceAlloyWithGroupsField,
+			testReplaceLogLineCaptureGroups,
+			map[string]interface{}{
+				"Groups": "user=frank",
+			},
+			`user=user=frank-frank token=abc`,
+		},
 		"successfully run a pipeline with empty replace value": {
 			testReplaceAlloyWithEmptyReplace,
 			testReplaceLogLine,
@@ -214,3 +248,11 @@ func TestReplaceConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceInvalidTemplate(t *testing.T) {
+	_, err := newReplaceStage(util.TestAlloyLogger(t), ReplaceConfig{
+		Expression: "(\\S+)",
+		Replace:    "{{ .Value ",
+	})
+	assert.ErrorContains(t, err, ErrCouldNotParseReplaceTemplate.Error())
+}
@@ -24,6 +24,7 @@ var (
 type SamplingConfig struct {
 	DropReason   string  `alloy:"drop_counter_reason,attr,optional"`
 	SamplingRate float64 `alloy:"rate,attr"`
+	When         string  `alloy:"when,attr,optional"`
 }
 
 func (s *SamplingConfig) SetToDefault() {
@@ -68,7 +69,7 @@ func (m *samplingStage) Run(in chan Entry) chan Entry {
 		defer close(out)
 		counter := m.dropCount.WithLabelValues(m.cfg.DropReason)
 		for e := range in {
-			if m.isSampled() {
+			if e.skip || m.isSampled() {
 				out <- e
 				continue
 			}
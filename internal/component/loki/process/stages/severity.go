@@ -0,0 +1,164 @@
+package stages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrSeveritySourceRequired = errors.New("severity stage source is required")
+	ErrSeverityNoOutput       = errors.New("severity stage requires label or severity_number_key to be set")
+	ErrSeverityInvalidLevel   = errors.New("severity stage mapping values must be one of \"trace\", \"debug\", \"info\", \"warn\", \"error\" or \"fatal\"")
+)
+
+// severityNumbers are the OTLP severity numbers of the normalized levels.
+var severityNumbers = map[string]int{
+	"trace": 1,
+	"debug": 5,
+	"info":  9,
+	"warn":  13,
+	"error": 17,
+	"fatal": 21,
+}
+
+// defaultSeverityMapping maps commonly used level names and numbers to
+// normalized levels. Numbers follow the Bunyan and Pino conventions.
+var defaultSeverityMapping = map[string]string{
+	"trace": "trace", "trc": "trace", "verbose": "trace", "finest": "trace", "finer": "trace", "10": "trace",
+	"debug": "debug", "dbg": "debug", "fine": "debug", "d": "debug", "20": "debug",
+	"info": "info", "inf": "info", "information": "info", "informational": "info", "notice": "info", "i": "info", "30": "info",
+	"warn": "warn", "warning": "warn", "wrn": "warn", "w": "warn", "40": "warn",
+	"error": "error", "err": "error", "e": "error", "severe": "error", "50": "error",
+	"fatal": "fatal", "critical": "fatal", "crit": "fatal", "alert": "fatal", "emerg": "fatal", "emergency": "fatal", "panic": "fatal", "60": "fatal",
+}
+
+// SeverityConfig configures a processing stage that normalizes log levels.
+type SeverityConfig struct {
+	Source            string            `alloy:"source,attr,optional"`
+	Label             string            `alloy:"label,attr,optional"`
+	SeverityNumberKey string            `alloy:"severity_number_key,attr,optional"`
+	Mapping           map[string]string `alloy:"mapping,attr,optional"`
+	When              string            `alloy:"when,attr,optional"`
+}
+
+// DefaultSeverityConfig sets the defaults for SeverityConfig.
+var DefaultSeverityConfig = SeverityConfig{
+	Source:            "level",
+	Label:             "level",
+	SeverityNumberKey: "severity_number",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *SeverityConfig) SetToDefault() {
+	*c = DefaultSeverityConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *SeverityConfig) Validate() error {
+	if c.Source == "" {
+		return ErrSeveritySourceRequired
+	}
+	if c.Label == "" && c.SeverityNumberKey == "" {
+		return ErrSeverityNoOutput
+	}
+	for _, name := range []string{c.Label, c.SeverityNumberKey} {
+		if name != "" && !model.LabelName(name).IsValid() {
+			return fmt.Errorf(ErrInvalidLabelName, name)
+		}
+	}
+	for _, lvl := range c.Mapping {
+		if _, ok := severityNumbers[lvl]; !ok {
+			return fmt.Errorf("%w: %q", ErrSeverityInvalidLevel, lvl)
+		}
+	}
+	return nil
+}
+
+// severityStage sets a normalized level label and severity number from an
+// extracted value.
+type severityStage struct {
+	cfg     SeverityConfig
+	mapping map[string]string
+	logger  log.Logger
+}
+
+// newSeverityStage creates a new severity pipeline stage from a config.
+func newSeverityStage(logger log.Logger, cfg SeverityConfig) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	// User-defined entries take precedence over the default mapping.
+	mapping := make(map[string]string, len(defaultSeverityMapping)+len(cfg.Mapping))
+	for k, v := range defaultSeverityMapping {
+		mapping[k] = v
+	}
+	for k, v := range cfg.Mapping {
+		mapping[strings.ToLower(k)] = v
+	}
+
+	return &severityStage{
+		cfg:     cfg,
+		mapping: mapping,
+		logger:  log.With(logger, "component", "stage", "type", "severity"),
+	}, nil
+}
+
+// Run implements Stage.
+func (s *severityStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		value, ok := e.Extracted[s.cfg.Source]
+		if !ok {
+			if Debug {
+				level.Debug(s.logger).Log("msg", "source does not exist in the set of extracted values", "source", s.cfg.Source)
+			}
+			return e
+		}
+		str, err := getString(value)
+		if err != nil {
+			if Debug {
+				level.Debug(s.logger).Log("msg", "failed to convert source value to string", "source", s.cfg.Source, "err", err, "type", reflect.TypeOf(value))
+			}
+			return e
+		}
+
+		lvl, ok := s.mapping[strings.ToLower(strings.TrimSpace(str))]
+		if !ok {
+			if Debug {
+				level.Debug(s.logger).Log("msg", "unknown severity", "value", str)
+			}
+			return e
+		}
+
+		if s.cfg.Label != "" {
+			e.Labels[model.LabelName(s.cfg.Label)] = model.LabelValue(lvl)
+		}
+		if s.cfg.SeverityNumberKey != "" {
+			e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{
+				Name:  s.cfg.SeverityNumberKey,
+				Value: strconv.Itoa(severityNumbers[lvl]),
+			})
+		}
+		return e
+	})
+}
+
+// Name implements Stage.
+func (s *severityStage) Name() string {
+	return StageTypeSeverity
+}
+
+// Cleanup implements Stage.
+func (*severityStage) Cleanup() {
+	// no-op
+}
@@ -0,0 +1,82 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testSeverityAlloy = `
+stage.json {
+    expressions = { level = "" }
+}
+stage.severity {
+    mapping = { "NOTICE" = "warn" }
+}
+`
+
+func TestSeverityPipeline(t *testing.T) {
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testSeverityAlloy), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		line          string
+		expectedLevel model.LabelValue
+		expectedSev   string
+	}{
+		"name":          {`{"level":"WARNING"}`, "warn", "13"},
+		"number":        {`{"level":30}`, "info", "9"},
+		"custom":        {`{"level":"notice"}`, "warn", "13"},
+		"fatal":         {`{"level":"critical"}`, "fatal", "21"},
+		"unknown level": {`{"level":"loud"}`, "", ""},
+		"missing level": {`{"msg":"hello"}`, "", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := processEntries(pl, newEntry(nil, model.LabelSet{}, tt.line, time.Now()))[0]
+			assert.Equal(t, tt.expectedLevel, out.Labels["level"])
+			if tt.expectedSev == "" {
+				assert.Empty(t, out.StructuredMetadata)
+				return
+			}
+			assert.Equal(t, []logproto.LabelAdapter{{Name: "severity_number", Value: tt.expectedSev}}, []logproto.LabelAdapter(out.StructuredMetadata))
+		})
+	}
+}
+
+func TestSeverityConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config SeverityConfig
+		err    error
+	}{
+		"missing source": {
+			SeverityConfig{Label: "level"},
+			ErrSeveritySourceRequired,
+		},
+		"no output": {
+			SeverityConfig{Source: "level"},
+			ErrSeverityNoOutput,
+		},
+		"invalid mapping": {
+			SeverityConfig{Source: "level", Label: "level", Mapping: map[string]string{"NOTICE": "notice"}},
+			ErrSeverityInvalidLevel,
+		},
+		"valid": {
+			DefaultSeverityConfig,
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.ErrorIs(t, tt.config.Validate(), tt.err)
+		})
+	}
+}
@@ -15,17 +15,26 @@ import (
 
 // TODO(@tpaschalis) Let's use this as the list of stages we need to port over.
 const (
+	StageTypeAggregate  = "aggregate"
+	StageTypeAutoParse  = "auto_parse"
 	StageTypeCRI        = "cri"
+	StageTypeCSV        = "csv"
+	StageTypeDecode     = "decode"
 	StageTypeDecolorize = "decolorize"
+	StageTypeDedup      = "dedup"
 	StageTypeDocker     = "docker"
 	StageTypeDrop       = "drop"
 	//TODO(thampiotr): Add support for eventlogmessage stage
 	StageTypeEventLogMessage    = "eventlogmessage"
+	StageTypeFingerprint        = "fingerprint"
 	StageTypeGeoIP              = "geoip"
+	StageTypeGrok               = "grok"
 	StageTypeJSON               = "json"
+	StageTypeKeyValue           = "key_value"
 	StageTypeLabel              = "labels"
 	StageTypeLabelAllow         = "labelallow"
 	StageTypeLabelDrop          = "labeldrop"
+	StageTypeLabelFromPath      = "label_from_path"
 	StageTypeLimit              = "limit"
 	StageTypeLogfmt             = "logfmt"
 	StageTypeLuhn               = "luhn"
@@ -35,14 +44,17 @@ const (
 	StageTypeOutput             = "output"
 	StageTypePack               = "pack"
 	StageTypePipeline           = "pipeline"
+	StageTypeRedact             = "redact"
 	StageTypeRegex              = "regex"
 	StageTypeReplace            = "replace"
 	StageTypeSampling           = "sampling"
+	StageTypeSeverity           = "severity"
 	StageTypeStaticLabels       = "static_labels"
 	StageTypeStructuredMetadata = "structured_metadata"
 	StageTypeTemplate           = "template"
 	StageTypeTenant             = "tenant"
 	StageTypeTimestamp          = "timestamp"
+	StageTypeTruncate           = "truncate"
 )
 
 // Processor takes an existing set of labels, timestamp and log entry and returns either a possibly mutated
@@ -55,6 +67,15 @@ type Processor interface {
 type Entry struct {
 	Extracted map[string]interface{}
 	loki.Entry
+
+	// stageStart is the time the entry was sent to the current stage of a
+	// pipeline with stage metrics enabled.
+	stageStart time.Time
+
+	// skip is set on the entries whose `when` condition is false. Stages must
+	// forward them unchanged, so that they keep their order relative to the
+	// entries the stage processes.
+	skip bool
 }
 
 // Stage can receive entries via an inbound channel and forward mutated entries to an outbound channel.
@@ -64,6 +85,31 @@ type Stage interface {
 	Cleanup()
 }
 
+// errorReporter is implemented by the stages which count the log lines they
+// fail to process. The pipeline sets their counter when stage metrics are
+// enabled, before it runs. setErrorCounter returns false if the stage doesn't
+// report errors after all, for stages which wrap other stages.
+type errorReporter interface {
+	setErrorCounter(prometheus.Counter) bool
+}
+
+// stageErrors implements errorReporter for the stages which embed it.
+type stageErrors struct {
+	errorCounter prometheus.Counter
+}
+
+func (s *stageErrors) setErrorCounter(c prometheus.Counter) bool {
+	s.errorCounter = c
+	return true
+}
+
+// reportError counts a log line the stage failed to process.
+func (s *stageErrors) reportError() {
+	if s.errorCounter != nil {
+		s.errorCounter.Inc()
+	}
+}
+
 func (entry *Entry) copy() *Entry {
 	out, err := yaml.Marshal(entry)
 	if err != nil {
@@ -158,13 +204,18 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 		if err != nil {
 			return nil, err
 		}
+	case cfg.GrokConfig != nil:
+		s, err = newGrokStage(logger, *cfg.GrokConfig)
+		if err != nil {
+			return nil, err
+		}
 	case cfg.RegexConfig != nil:
-		s, err = newRegexStage(logger, *cfg.RegexConfig)
+		s, err = newRegexStage(logger, *cfg.RegexConfig, registerer)
 		if err != nil {
 			return nil, err
 		}
 	case cfg.TimestampConfig != nil:
-		s, err = newTimestampStage(logger, *cfg.TimestampConfig)
+		s, err = newTimestampStage(logger, *cfg.TimestampConfig, registerer)
 		if err != nil {
 			return nil, err
 		}
@@ -209,7 +260,10 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 			return nil, err
 		}
 	case cfg.PackConfig != nil:
-		s = newPackStage(logger, *cfg.PackConfig, registerer)
+		s, err = newPackStage(logger, *cfg.PackConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
 	case cfg.LabelAllowConfig != nil:
 		s, err = newLabelAllowStage(*cfg.LabelAllowConfig)
 		if err != nil {
@@ -239,9 +293,67 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 		s = newSamplingStage(logger, *cfg.SamplingConfig, registerer)
 	case cfg.EventLogMessageConfig != nil:
 		s = newEventLogMessageStage(logger, cfg.EventLogMessageConfig)
+	case cfg.CSVConfig != nil:
+		s, err = newCSVStage(logger, *cfg.CSVConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.KeyValueConfig != nil:
+		s, err = newKeyValueStage(logger, *cfg.KeyValueConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.DedupConfig != nil:
+		s, err = newDedupStage(logger, *cfg.DedupConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.RedactConfig != nil:
+		s, err = newRedactStage(logger, *cfg.RedactConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.DecodeConfig != nil:
+		s, err = newDecodeStage(logger, *cfg.DecodeConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.TruncateConfig != nil:
+		s, err = newTruncateStage(logger, *cfg.TruncateConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.SeverityConfig != nil:
+		s, err = newSeverityStage(logger, *cfg.SeverityConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.LabelFromPathConfig != nil:
+		s, err = newLabelFromPathStage(logger, *cfg.LabelFromPathConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.AggregateConfig != nil:
+		s, err = newAggregateStage(logger, *cfg.AggregateConfig, registerer)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.FingerprintConfig != nil:
+		s, err = newFingerprintStage(logger, *cfg.FingerprintConfig)
+		if err != nil {
+			return nil, err
+		}
+	case cfg.AutoParseConfig != nil:
+		s, err = newAutoParseStage(logger, *cfg.AutoParseConfig)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		panic(fmt.Sprintf("unreachable; should have decoded into one of the StageConfig fields: %+v", cfg))
 	}
+	if when := StageCondition(cfg); when != "" {
+		return newConditionalStage(logger, s, when, registerer)
+	}
 	return s, nil
 }
 
@@ -249,3 +361,14 @@ func New(logger log.Logger, jobName *string, cfg StageConfig, registerer prometh
 func (*stageProcessor) Cleanup() {
 	// no-op
 }
+
+func (s *stageProcessor) setErrorCounter(c prometheus.Counter) bool {
+	return setErrorCounter(s.Processor, c)
+}
+
+// setErrorCounter sets the error counter of a stage or processor, and returns
+// false if it doesn't report errors.
+func setErrorCounter(s interface{}, c prometheus.Counter) bool {
+	r, ok := s.(errorReporter)
+	return ok && r.setErrorCounter(c)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"text/template"
 	"time"
 
 	"github.com/go-kit/log"
@@ -18,6 +19,7 @@ var ErrEmptyStaticLabelStageConfig = errors.New("static_labels stage config cann
 // StaticLabelsConfig contains a map of static labels to be set.
 type StaticLabelsConfig struct {
 	Values map[string]*string `alloy:"values,attr"`
+	When   string             `alloy:"when,attr,optional"`
 }
 
 func newStaticLabelsStage(logger log.Logger, config StaticLabelsConfig) (Stage, error) {
@@ -26,9 +28,21 @@ func newStaticLabelsStage(logger log.Logger, config StaticLabelsConfig) (Stage,
 		return nil, err
 	}
 
+	values := make(map[string]string, len(config.Values))
+	for labelName, value := range config.Values {
+		if value != nil {
+			values[labelName] = *value
+		}
+	}
+	templates, err := parseLabelTemplates(values)
+	if err != nil {
+		return nil, err
+	}
+
 	return toStage(&staticLabelStage{
-		config: config,
-		logger: logger,
+		config:    config,
+		templates: templates,
+		logger:    logger,
 	}), nil
 }
 
@@ -46,8 +60,9 @@ func validateLabelStaticConfig(c StaticLabelsConfig) error {
 
 // staticLabelStage implements Stage.
 type staticLabelStage struct {
-	config StaticLabelsConfig
-	logger log.Logger
+	config    StaticLabelsConfig
+	templates map[string]*template.Template
+	logger    log.Logger
 }
 
 // Process implements Stage.
@@ -56,6 +71,12 @@ func (l *staticLabelStage) Process(labels model.LabelSet, extracted map[string]i
 		if lSrc == nil || *lSrc == "" {
 			continue
 		}
+		if t, ok := l.templates[lName]; ok {
+			if lvalue, ok := executeLabelTemplate(l.logger, t, extracted); ok {
+				labels[model.LabelName(lName)] = lvalue
+			}
+			continue
+		}
 		s, err := getString(*lSrc)
 		if err != nil {
 			level.Debug(l.logger).Log("msg", "failed to convert static label value to string", "err", err, "type", reflect.TypeOf(lSrc))
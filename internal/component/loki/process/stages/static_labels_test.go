@@ -10,10 +10,12 @@ import (
 
 func Test_StaticLabels(t *testing.T) {
 	staticVal := "val"
+	templateVal := "{{ .env | ToUpper }}-{{ .region }}"
 
 	tests := []struct {
 		name           string
 		config         StaticLabelsConfig
+		extracted      map[string]interface{}
 		inputLabels    model.LabelSet
 		expectedLabels model.LabelSet
 	}{
@@ -42,6 +44,23 @@ func Test_StaticLabels(t *testing.T) {
 				"testLabel": "testValue",
 			},
 		},
+		{
+			name: "add templated static label",
+			config: StaticLabelsConfig{Values: map[string]*string{
+				"staticLabel": &templateVal,
+			}},
+			extracted: map[string]interface{}{
+				"env":    "prod",
+				"region": "eu",
+			},
+			inputLabels: model.LabelSet{
+				"testLabel": "testValue",
+			},
+			expectedLabels: model.LabelSet{
+				"testLabel":   "testValue",
+				"staticLabel": "PROD-eu",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -50,7 +69,7 @@ func Test_StaticLabels(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			out := processEntries(st, newEntry(nil, test.inputLabels, "", time.Now()))[0]
+			out := processEntries(st, newEntry(test.extracted, test.inputLabels, "", time.Now()))[0]
 			assert.Equal(t, test.expectedLabels, out.Labels)
 		})
 	}
@@ -1,25 +1,93 @@
 package stages
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/go-kit/log"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/prometheus/common/model"
 
-	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrStructuredMetadataEmptyConfig     = errors.New("structured_metadata stage requires values, prefix or regex")
+	ErrStructuredMetadataPrefixAndRegex  = errors.New("structured_metadata stage prefix and regex are mutually exclusive")
+	ErrStructuredMetadataTrimPrefixUnset = errors.New("structured_metadata stage trim_prefix requires prefix")
+	ErrStructuredMetadataReplacement     = errors.New("structured_metadata stage replacement requires regex")
 )
 
-func newStructuredMetadataStage(logger log.Logger, configs LabelsConfig) (Stage, error) {
-	labelsConfig, err := validateLabelsConfig(configs)
-	if err != nil {
+// StructuredMetadataConfig configures a structured metadata stage.
+type StructuredMetadataConfig struct {
+	Values map[string]*string `alloy:"values,attr,optional"`
+
+	// Bulk promotion of extracted values.
+	Prefix      string `alloy:"prefix,attr,optional"`
+	TrimPrefix  bool   `alloy:"trim_prefix,attr,optional"`
+	Regex       string `alloy:"regex,attr,optional"`
+	Replacement string `alloy:"replacement,attr,optional"`
+	When        string `alloy:"when,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (c *StructuredMetadataConfig) Validate() error {
+	if c.Values == nil && c.Prefix == "" && c.Regex == "" {
+		return ErrStructuredMetadataEmptyConfig
+	}
+	if c.Prefix != "" && c.Regex != "" {
+		return ErrStructuredMetadataPrefixAndRegex
+	}
+	if c.TrimPrefix && c.Prefix == "" {
+		return ErrStructuredMetadataTrimPrefixUnset
+	}
+	if c.Replacement != "" && c.Regex == "" {
+		return ErrStructuredMetadataReplacement
+	}
+	return nil
+}
+
+func newStructuredMetadataStage(logger log.Logger, config StructuredMetadataConfig) (Stage, error) {
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
+
+	var labelsConfig map[string]string
+	if config.Values != nil {
+		var err error
+		labelsConfig, err = validateLabelsConfig(LabelsConfig{Values: config.Values})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var regex *regexp.Regexp
+	if config.Regex != "" {
+		var err error
+		// Like relabeling rules, the expression must match the whole key.
+		regex, err = regexp.Compile("^(?:" + config.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", ErrCouldNotCompileRegex, err)
+		}
+	}
+
 	return &structuredMetadataStage{
 		labelsConfig: labelsConfig,
+		cfg:          config,
+		regex:        regex,
 		logger:       logger,
 	}, nil
 }
 
 type structuredMetadataStage struct {
 	labelsConfig map[string]string
+	cfg          StructuredMetadataConfig
+	regex        *regexp.Regexp
 	logger       log.Logger
 }
 
@@ -37,10 +105,71 @@ func (s *structuredMetadataStage) Run(in chan Entry) chan Entry {
 		processLabelsConfigs(s.logger, e.Extracted, s.labelsConfig, func(labelName model.LabelName, labelValue model.LabelValue) {
 			e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{Name: string(labelName), Value: string(labelValue)})
 		})
+		e = s.promoteExtracted(e)
 		return s.extractFromLabels(e)
 	})
 }
 
+// promoteExtracted adds all the extracted values selected by the prefix or
+// regex to the structured metadata.
+func (s *structuredMetadataStage) promoteExtracted(e Entry) Entry {
+	if s.cfg.Prefix == "" && s.regex == nil {
+		return e
+	}
+
+	keys := make([]string, 0, len(e.Extracted))
+	for key := range e.Extracted {
+		keys = append(keys, key)
+	}
+	// Sort the keys so that the structured metadata has a stable order.
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, ok := s.metadataName(key)
+		if !ok {
+			continue
+		}
+		if !model.LabelName(name).IsValid() {
+			if Debug {
+				level.Debug(s.logger).Log("msg", "invalid structured metadata name", "key", key, "name", name)
+			}
+			continue
+		}
+		value, err := getString(e.Extracted[key])
+		if err != nil {
+			if Debug {
+				level.Debug(s.logger).Log("msg", "failed to convert extracted value to string", "key", key, "err", err, "type", reflect.TypeOf(e.Extracted[key]))
+			}
+			continue
+		}
+		e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{Name: name, Value: value})
+	}
+	return e
+}
+
+// metadataName returns the structured metadata name of a selected extracted
+// key, and false if the key isn't selected.
+func (s *structuredMetadataStage) metadataName(key string) (string, bool) {
+	if s.cfg.Prefix != "" {
+		if !strings.HasPrefix(key, s.cfg.Prefix) {
+			return "", false
+		}
+		if s.cfg.TrimPrefix {
+			return strings.TrimPrefix(key, s.cfg.Prefix), true
+		}
+		return key, true
+	}
+
+	match := s.regex.FindStringSubmatchIndex(key)
+	if match == nil {
+		return "", false
+	}
+	if s.cfg.Replacement == "" {
+		return key, true
+	}
+	return string(s.regex.ExpandString(nil, s.cfg.Replacement, key, match)), true
+}
+
 func (s *structuredMetadataStage) extractFromLabels(e Entry) Entry {
 	labels := e.Labels
 	foundLabels := []model.LabelName{}
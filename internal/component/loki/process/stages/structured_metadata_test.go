@@ -91,6 +91,39 @@ stage.structured_metadata {
 }
 `
 
+var pipelineStagesStructuredMetadataFromPrefix = `
+stage.json {
+	expressions = {app = "", meta_pod = "", meta_node = ""}
+}
+
+stage.structured_metadata {
+	prefix      = "meta_"
+	trim_prefix = true
+}
+`
+
+var pipelineStagesStructuredMetadataFromRegex = `
+stage.json {
+	expressions = {app = "", "http.method" = "http.method", "http.status" = "http.status"}
+}
+
+stage.structured_metadata {
+	regex       = "http\\.(.+)"
+	replacement = "http_$1"
+}
+`
+
+var pipelineStagesStructuredMetadataFromRegexAndValues = `
+stage.json {
+	expressions = {app = "", trace_id = "", span_id = ""}
+}
+
+stage.structured_metadata {
+	values = {"application" = "app"}
+	regex  = ".*_id"
+}
+`
+
 func Test_StructuredMetadataStage(t *testing.T) {
 	tests := map[string]struct {
 		pipelineStagesYaml         string
@@ -136,6 +169,31 @@ func Test_StructuredMetadataStage(t *testing.T) {
 			expectedStructuredMetadata: push.LabelsAdapter{push.LabelAdapter{Name: "pod_name", Value: "loki-querier-664f97db8d-qhnwg"}},
 			expectedLabels:             model.LabelSet{model.LabelName("component"): model.LabelValue("querier")},
 		},
+		"expected structured metadata to be promoted from extracted values with a prefix": {
+			pipelineStagesYaml: pipelineStagesStructuredMetadataFromPrefix,
+			logLine:            `{"app":"loki", "meta_pod":"loki-0", "meta_node":"node-1"}`,
+			expectedStructuredMetadata: push.LabelsAdapter{
+				push.LabelAdapter{Name: "node", Value: "node-1"},
+				push.LabelAdapter{Name: "pod", Value: "loki-0"},
+			},
+		},
+		"expected structured metadata to be promoted from extracted values matching a regex with rewritten keys": {
+			pipelineStagesYaml: pipelineStagesStructuredMetadataFromRegex,
+			logLine:            `{"app":"loki", "http": {"method":"GET", "status":200}}`,
+			expectedStructuredMetadata: push.LabelsAdapter{
+				push.LabelAdapter{Name: "http_method", Value: "GET"},
+				push.LabelAdapter{Name: "http_status", Value: "200"},
+			},
+		},
+		"expected structured metadata to be promoted from extracted values matching a regex along with values": {
+			pipelineStagesYaml: pipelineStagesStructuredMetadataFromRegexAndValues,
+			logLine:            `{"app":"loki", "trace_id":"abc", "span_id":"def"}`,
+			expectedStructuredMetadata: push.LabelsAdapter{
+				push.LabelAdapter{Name: "application", Value: "loki"},
+				push.LabelAdapter{Name: "span_id", Value: "def"},
+				push.LabelAdapter{Name: "trace_id", Value: "abc"},
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -152,3 +210,36 @@ func Test_StructuredMetadataStage(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuredMetadataConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config StructuredMetadataConfig
+		err    error
+	}{
+		"empty": {
+			StructuredMetadataConfig{},
+			ErrStructuredMetadataEmptyConfig,
+		},
+		"prefix and regex": {
+			StructuredMetadataConfig{Prefix: "meta_", Regex: "meta_.*"},
+			ErrStructuredMetadataPrefixAndRegex,
+		},
+		"trim_prefix without prefix": {
+			StructuredMetadataConfig{Regex: "meta_.*", TrimPrefix: true},
+			ErrStructuredMetadataTrimPrefixUnset,
+		},
+		"replacement without regex": {
+			StructuredMetadataConfig{Prefix: "meta_", Replacement: "$1"},
+			ErrStructuredMetadataReplacement,
+		},
+		"valid": {
+			StructuredMetadataConfig{Prefix: "meta_", TrimPrefix: true},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
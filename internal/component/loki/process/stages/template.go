@@ -65,6 +65,7 @@ func init() {
 type TemplateConfig struct {
 	Source   string `alloy:"source,attr"`
 	Template string `alloy:"template,attr"`
+	When     string `alloy:"when,attr,optional"`
 }
 
 // validateTemplateConfig validates the templateStage config.
@@ -92,6 +93,8 @@ func newTemplateStage(logger log.Logger, config TemplateConfig) (Stage, error) {
 
 // templateStage will mutate the incoming entry and set it from extracted data
 type templateStage struct {
+	stageErrors
+
 	cfgs     TemplateConfig
 	logger   log.Logger
 	template *template.Template
@@ -121,6 +124,7 @@ func (o *templateStage) Process(labels model.LabelSet, extracted map[string]inte
 		if Debug {
 			level.Debug(o.logger).Log("msg", "failed to execute template on extracted value", "err", err)
 		}
+		o.reportError()
 		return
 	}
 	st := buf.String()
@@ -363,3 +363,61 @@ func TestTemplateStage_Process(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateStage_Sprig(t *testing.T) {
+	tests := map[string]struct {
+		config            TemplateConfig
+		extracted         map[string]interface{}
+		expectedExtracted map[string]interface{}
+	}{
+		"sprig default and coalesce": {
+			TemplateConfig{
+				Source:   "level",
+				Template: `{{ default "info" .level }} {{ coalesce .missing .app "none" }}`,
+			},
+			map[string]interface{}{
+				"app": "loki",
+			},
+			map[string]interface{}{
+				"app":   "loki",
+				"level": "info loki",
+			},
+		},
+		"sprig string functions": {
+			TemplateConfig{
+				Source:   "msg",
+				Template: `{{ regexReplaceAll "[0-9]+" .Value "N" | trunc 8 | b64enc }}`,
+			},
+			map[string]interface{}{
+				"msg": "user 1234 logged in",
+			},
+			map[string]interface{}{
+				"msg": "dXNlciBOIGw=",
+			},
+		},
+		"sprig dict functions": {
+			TemplateConfig{
+				Source:   "msg",
+				Template: `{{ $d := dict "app" .app }}{{ $_ := set $d "env" "prod" }}{{ keys $d | sortAlpha | join "," }}={{ get $d "app" }}`,
+			},
+			map[string]interface{}{
+				"app": "loki",
+			},
+			map[string]interface{}{
+				"app": "loki",
+				"msg": "app,env=loki",
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			st, err := newTemplateStage(util_log.Logger, test.config)
+			require.NoError(t, err)
+
+			out := processEntries(st, newEntry(test.extracted, nil, "not important for this test", time.Time{}))[0]
+			assert.Equal(t, test.expectedExtracted, out.Extracted)
+		})
+	}
+}
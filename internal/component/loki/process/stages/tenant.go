@@ -2,7 +2,9 @@ package stages
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
 	"time"
 
 	"github.com/go-kit/log"
@@ -14,21 +16,45 @@ import (
 var (
 	ErrTenantStageEmptyLabelSourceOrValue        = errors.New("label, source or value config are required")
 	ErrTenantStageConflictingLabelSourceAndValue = errors.New("label, source and value are mutually exclusive: you should set source, value or label but not all")
+	ErrTenantStageMappingNoRules                 = errors.New("tenant mapping requires at least one rule")
+	ErrTenantStageMappingEmptyRule               = errors.New("tenant mapping rules require an expression and a tenant")
 )
 
 // ReservedLabelTenantID is a shared value used to refer to the tenant ID.
 const ReservedLabelTenantID = "__tenant_id__"
 
 type tenantStage struct {
-	cfg    TenantConfig
-	logger log.Logger
+	cfg          TenantConfig
+	mappingRules []tenantMappingRule
+	logger       log.Logger
+}
+
+// tenantMappingRule is a compiled TenantMappingRuleConfig.
+type tenantMappingRule struct {
+	expression *regexp.Regexp
+	tenant     string
 }
 
 // TenantConfig configures a tenant stage.
 type TenantConfig struct {
-	Label  string `alloy:"label,attr,optional"`
-	Source string `alloy:"source,attr,optional"`
-	Value  string `alloy:"value,attr,optional"`
+	Label   string               `alloy:"label,attr,optional"`
+	Source  string               `alloy:"source,attr,optional"`
+	Value   string               `alloy:"value,attr,optional"`
+	Mapping *TenantMappingConfig `alloy:"mapping,block,optional"`
+	When    string               `alloy:"when,attr,optional"`
+}
+
+// TenantMappingConfig maps the value found by a tenant stage to a tenant ID.
+type TenantMappingConfig struct {
+	Rules   []TenantMappingRuleConfig `alloy:"rule,block,optional"`
+	Default string                    `alloy:"default,attr,optional"`
+}
+
+// TenantMappingRuleConfig maps values matching a regular expression to a
+// tenant ID.
+type TenantMappingRuleConfig struct {
+	Expression string `alloy:"expression,attr"`
+	Tenant     string `alloy:"tenant,attr"`
 }
 
 // validateTenantConfig validates the tenant stage configuration
@@ -41,6 +67,17 @@ func validateTenantConfig(c TenantConfig) error {
 		return ErrTenantStageConflictingLabelSourceAndValue
 	}
 
+	if c.Mapping != nil {
+		if len(c.Mapping.Rules) == 0 {
+			return ErrTenantStageMappingNoRules
+		}
+		for _, rule := range c.Mapping.Rules {
+			if rule.Expression == "" || rule.Tenant == "" {
+				return ErrTenantStageMappingEmptyRule
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -51,9 +88,21 @@ func newTenantStage(logger log.Logger, cfg TenantConfig) (Stage, error) {
 		return nil, err
 	}
 
+	var mappingRules []tenantMappingRule
+	if cfg.Mapping != nil {
+		for _, rule := range cfg.Mapping.Rules {
+			expression, err := regexp.Compile(rule.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", ErrCouldNotCompileRegex, err)
+			}
+			mappingRules = append(mappingRules, tenantMappingRule{expression: expression, tenant: rule.Tenant})
+		}
+	}
+
 	return toStage(&tenantStage{
-		cfg:    cfg,
-		logger: logger,
+		cfg:          cfg,
+		mappingRules: mappingRules,
+		logger:       logger,
 	}), nil
 }
 
@@ -70,6 +119,10 @@ func (s *tenantStage) Process(labels model.LabelSet, extracted map[string]interf
 		tenantID = s.cfg.Value
 	}
 
+	if s.cfg.Mapping != nil {
+		tenantID = s.mapTenant(tenantID)
+	}
+
 	// Skip an empty tenant ID (i.e. failed to get the tenant from the source)
 	if tenantID == "" {
 		return
@@ -83,6 +136,22 @@ func (s *tenantStage) Name() string {
 	return StageTypeTenant
 }
 
+// mapTenant returns the tenant ID of the first mapping rule matching the
+// value, or the default tenant ID if none of them matches.
+func (s *tenantStage) mapTenant(value string) string {
+	if value != "" {
+		for _, rule := range s.mappingRules {
+			match := rule.expression.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			return string(rule.expression.ExpandString(nil, rule.tenant, value, match))
+		}
+	}
+	level.Debug(s.logger).Log("msg", "no tenant mapping rule matched, using the default tenant", "value", value)
+	return s.cfg.Mapping.Default
+}
+
 func (s *tenantStage) getTenantFromSourceField(extracted map[string]interface{}) string {
 	// Get the tenant ID from the source data
 	value, ok := extracted[s.cfg.Source]
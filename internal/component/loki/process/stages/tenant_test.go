@@ -122,6 +122,27 @@ func TestTenantStage_Validation(t *testing.T) {
 			},
 			expectedErr: ErrTenantStageConflictingLabelSourceAndValue,
 		},
+		"should pass on mapping rules set": {
+			config: TenantConfig{
+				Label:   "namespace",
+				Mapping: &TenantMappingConfig{Rules: []TenantMappingRuleConfig{{Expression: "^team-a-", Tenant: "team-a"}}},
+			},
+			expectedErr: nil,
+		},
+		"should fail on mapping without rules": {
+			config: TenantConfig{
+				Label:   "namespace",
+				Mapping: &TenantMappingConfig{Default: "shared"},
+			},
+			expectedErr: ErrTenantStageMappingNoRules,
+		},
+		"should fail on mapping rule without tenant": {
+			config: TenantConfig{
+				Label:   "namespace",
+				Mapping: &TenantMappingConfig{Rules: []TenantMappingRuleConfig{{Expression: "^team-a-"}}},
+			},
+			expectedErr: ErrTenantStageMappingEmptyRule,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -141,6 +162,15 @@ func TestTenantStage_Validation(t *testing.T) {
 	}
 }
 
+var testTenantMapping = &TenantMappingConfig{
+	Rules: []TenantMappingRuleConfig{
+		{Expression: "^team-a-", Tenant: "team-a"},
+		{Expression: "^team-", Tenant: "teams"},
+		{Expression: "^(?P<team>[a-z]+)-prod$", Tenant: "$team"},
+	},
+	Default: "shared",
+}
+
 func TestTenantStage_Process(t *testing.T) {
 	t.Parallel()
 
@@ -198,6 +228,38 @@ func TestTenantStage_Process(t *testing.T) {
 			inputExtracted: map[string]interface{}{},
 			expectedTenant: lokiutil.StringRef("bar"),
 		},
+		"should set the tenant of the first matching mapping rule": {
+			config:         TenantConfig{Label: "namespace", Mapping: testTenantMapping},
+			inputLabels:    model.LabelSet{"namespace": "team-a-prod"},
+			inputExtracted: map[string]interface{}{},
+			expectedTenant: lokiutil.StringRef("team-a"),
+		},
+		"should expand capture groups in the mapped tenant": {
+			config:         TenantConfig{Label: "namespace", Mapping: testTenantMapping},
+			inputLabels:    model.LabelSet{"namespace": "billing-prod"},
+			inputExtracted: map[string]interface{}{},
+			expectedTenant: lokiutil.StringRef("billing"),
+		},
+		"should set the default tenant if no mapping rule matches": {
+			config:         TenantConfig{Source: "namespace", Mapping: testTenantMapping},
+			inputLabels:    model.LabelSet{},
+			inputExtracted: map[string]interface{}{"namespace": "kube-system"},
+			expectedTenant: lokiutil.StringRef("shared"),
+		},
+		"should set the default tenant if the source field is not defined in the extracted map": {
+			config:         TenantConfig{Source: "namespace", Mapping: testTenantMapping},
+			inputLabels:    model.LabelSet{},
+			inputExtracted: map[string]interface{}{},
+			expectedTenant: lokiutil.StringRef("shared"),
+		},
+		"should not set the tenant if no mapping rule matches and there is no default": {
+			config: TenantConfig{Label: "namespace", Mapping: &TenantMappingConfig{
+				Rules: testTenantMapping.Rules,
+			}},
+			inputLabels:    model.LabelSet{"namespace": "kube-system"},
+			inputExtracted: map[string]interface{}{},
+			expectedTenant: nil,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -224,3 +286,33 @@ func TestTenantStage_Process(t *testing.T) {
 		})
 	}
 }
+
+var testTenantAlloyMapping = `
+stage.tenant {
+		label = "namespace"
+		mapping {
+				rule {
+						expression = "^team-a-"
+						tenant     = "team-a"
+				}
+				rule {
+						expression = "^(?P<team>[a-z]+)-prod$"
+						tenant     = "${team}"
+				}
+				default = "shared"
+		}
+}`
+
+func TestPipeline_TenantMapping(t *testing.T) {
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testTenantAlloyMapping), nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	for namespace, expected := range map[string]string{
+		"team-a-dev":  "team-a",
+		"search-prod": "search",
+		"default":     "shared",
+	} {
+		out := processEntries(pl, newEntry(nil, model.LabelSet{"namespace": model.LabelValue(namespace)}, "hello world", time.Now()))[0]
+		assert.Equal(t, model.LabelValue(expected), out.Labels[ReservedLabelTenantID], namespace)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-kit/log"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -24,6 +25,8 @@ var (
 	ErrTimestampSourceMissing    = errors.New("extracted data did not contain a timestamp")
 	ErrTimestampConversionFailed = errors.New("failed to convert extracted time to string")
 	ErrTimestampParsingFailed    = errors.New("failed to parse time")
+	ErrTimestampFallbackRequired = errors.New("fallback_timestamp is required when action_on_failure is static")
+	ErrInvalidFallbackTimestamp  = errors.New("fallback_timestamp must be a RFC3339 timestamp")
 
 	Unix   = "Unix"
 	UnixMs = "UnixMs"
@@ -32,28 +35,42 @@ var (
 
 	TimestampActionOnFailureSkip    = "skip"
 	TimestampActionOnFailureFudge   = "fudge"
+	TimestampActionOnFailureStatic  = "static"
+	TimestampActionOnFailureDrop    = "drop"
 	TimestampActionOnFailureDefault = TimestampActionOnFailureFudge
 
+	timestampDropReason = "timestamp_parse_failure"
+
 	// Maximum number of "streams" for which we keep the last known timestamp
 	maxLastKnownTimestampsCacheSize = 10000
 )
 
 // TimestampActionOnFailureOptions defines the available options for the
 // `action_on_failure` field.
-var TimestampActionOnFailureOptions = []string{TimestampActionOnFailureSkip, TimestampActionOnFailureFudge}
+var TimestampActionOnFailureOptions = []string{
+	TimestampActionOnFailureSkip,
+	TimestampActionOnFailureFudge,
+	TimestampActionOnFailureStatic,
+	TimestampActionOnFailureDrop,
+}
 
 // TimestampConfig configures a processing stage for timestamp extraction.
 type TimestampConfig struct {
-	Source          string   `alloy:"source,attr"`
-	Format          string   `alloy:"format,attr"`
-	FallbackFormats []string `alloy:"fallback_formats,attr,optional"`
-	Location        *string  `alloy:"location,attr,optional"`
-	ActionOnFailure string   `alloy:"action_on_failure,attr,optional"`
+	Source            string   `alloy:"source,attr"`
+	Format            string   `alloy:"format,attr"`
+	FallbackFormats   []string `alloy:"fallback_formats,attr,optional"`
+	Location          *string  `alloy:"location,attr,optional"`
+	ActionOnFailure   string   `alloy:"action_on_failure,attr,optional"`
+	FallbackTimestamp string   `alloy:"fallback_timestamp,attr,optional"`
+	When              string   `alloy:"when,attr,optional"`
 }
 
 type parser func(string) (time.Time, error)
 
-func validateTimestampConfig(cfg *TimestampConfig) (parser, error) {
+// validateTimestampConfig validates the config and returns a parser trying
+// each configured format in order. If onFormatFailure is not nil, it's called
+// with every format which fails to parse a value.
+func validateTimestampConfig(cfg *TimestampConfig, onFormatFailure func(format string)) (parser, error) {
 	if cfg.Source == "" {
 		return nil, ErrTimestampSourceRequired
 	}
@@ -78,28 +95,49 @@ func validateTimestampConfig(cfg *TimestampConfig) (parser, error) {
 		}
 	}
 
-	if len(cfg.FallbackFormats) > 0 {
-		multiConvertDateLayout := func(input string) (time.Time, error) {
-			originalTime, originalErr := convertDateLayout(cfg.Format, loc)(input)
-			if originalErr == nil {
-				return originalTime, originalErr
-			}
-			for i := 0; i < len(cfg.FallbackFormats); i++ {
-				if t, err := convertDateLayout(cfg.FallbackFormats[i], loc)(input); err == nil {
-					return t, err
-				}
-			}
-			return originalTime, originalErr
+	if cfg.ActionOnFailure == TimestampActionOnFailureStatic {
+		if cfg.FallbackTimestamp == "" {
+			return nil, ErrTimestampFallbackRequired
 		}
-		return multiConvertDateLayout, nil
+		if _, err := time.Parse(time.RFC3339Nano, cfg.FallbackTimestamp); err != nil {
+			return nil, fmt.Errorf("%v: %w", ErrInvalidFallbackTimestamp, err)
+		}
+	}
+
+	formats := append([]string{cfg.Format}, cfg.FallbackFormats...)
+	parsers := make([]parser, 0, len(formats))
+	for _, format := range formats {
+		parsers = append(parsers, convertDateLayout(format, loc))
 	}
 
-	return convertDateLayout(cfg.Format, loc), nil
+	return func(input string) (time.Time, error) {
+		var (
+			originalTime time.Time
+			originalErr  error
+		)
+		for i, p := range parsers {
+			t, err := p(input)
+			if err == nil {
+				return t, nil
+			}
+			if onFormatFailure != nil {
+				onFormatFailure(formats[i])
+			}
+			if i == 0 {
+				originalTime, originalErr = t, err
+			}
+		}
+		return originalTime, originalErr
+	}, nil
 }
 
 // newTimestampStage creates a new timestamp extraction pipeline stage.
-func newTimestampStage(logger log.Logger, config TimestampConfig) (Stage, error) {
-	parser, err := validateTimestampConfig(&config)
+func newTimestampStage(logger log.Logger, config TimestampConfig, registerer prometheus.Registerer) (Stage, error) {
+	parseFailures := registerCounterVec(registerer, "loki_process", "timestamp_parse_failures_total",
+		"A count of all timestamps which failed to parse in a timestamp stage, by format", []string{"format"})
+	parser, err := validateTimestampConfig(&config, func(format string) {
+		parseFailures.WithLabelValues(format).Inc()
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -112,18 +150,34 @@ func newTimestampStage(logger log.Logger, config TimestampConfig) (Stage, error)
 		}
 	}
 
-	return toStage(&timestampStage{
+	var fallbackTimestamp time.Time
+	if config.ActionOnFailure == TimestampActionOnFailureStatic {
+		fallbackTimestamp, err = time.Parse(time.RFC3339Nano, config.FallbackTimestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &timestampStage{
 		config:              &config,
 		logger:              logger,
 		parser:              parser,
+		fallbackTimestamp:   fallbackTimestamp,
 		lastKnownTimestamps: lastKnownTimestamps,
-	}), nil
+		dropCount:           getDropCountMetric(registerer),
+	}, nil
 }
 
 type timestampStage struct {
-	config *TimestampConfig
-	logger log.Logger
-	parser parser
+	stageErrors
+
+	config    *TimestampConfig
+	logger    log.Logger
+	parser    parser
+	dropCount *prometheus.CounterVec
+
+	// Timestamp used by the "static" action on failure.
+	fallbackTimestamp time.Time
 
 	// Stores the last known timestamp for a given "stream id" (guessed, since at this stage
 	// there's no reliable way to know it).
@@ -135,16 +189,41 @@ func (ts *timestampStage) Name() string {
 	return StageTypeTimestamp
 }
 
-// Process implements Stage.
-func (ts *timestampStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+// Run implements Stage.
+func (ts *timestampStage) Run(in chan Entry) chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		for e := range in {
+			if e.skip {
+				out <- e
+				continue
+			}
+			if !ts.process(e.Labels, e.Extracted, &e.Timestamp) {
+				ts.dropCount.WithLabelValues(timestampDropReason).Inc()
+				continue
+			}
+			out <- e
+		}
+	}()
+	return out
+}
+
+// Cleanup implements Stage.
+func (*timestampStage) Cleanup() {
+	// no-op
+}
+
+// process updates the timestamp of an entry, and returns false if the entry
+// should be dropped.
+func (ts *timestampStage) process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time) bool {
 	if ts.config == nil {
-		return
+		return true
 	}
 
 	parsedTs, err := ts.parseTimestampFromSource(extracted)
 	if err != nil {
-		ts.processActionOnFailure(labels, t)
-		return
+		return ts.processActionOnFailure(labels, t)
 	}
 
 	// Update the log entry timestamp with the parsed one
@@ -155,6 +234,7 @@ func (ts *timestampStage) Process(labels model.LabelSet, extracted map[string]in
 	if ts.config.ActionOnFailure == TimestampActionOnFailureFudge {
 		ts.lastKnownTimestamps.Add(labels.String(), *t)
 	}
+	return true
 }
 
 func (ts *timestampStage) parseTimestampFromSource(extracted map[string]interface{}) (*time.Time, error) {
@@ -169,6 +249,7 @@ func (ts *timestampStage) parseTimestampFromSource(extracted map[string]interfac
 	s, err := getString(v)
 	if err != nil {
 		level.Debug(ts.logger).Log("msg", ErrTimestampConversionFailed, "err", err, "type", reflect.TypeOf(v))
+		ts.reportError()
 		return nil, ErrTimestampConversionFailed
 	}
 
@@ -176,6 +257,7 @@ func (ts *timestampStage) parseTimestampFromSource(extracted map[string]interfac
 	parsedTs, err := ts.parser(s)
 	if err != nil {
 		level.Debug(ts.logger).Log("msg", ErrTimestampParsingFailed, "err", err, "format", ts.config.Format, "value", s)
+		ts.reportError()
 
 		return nil, ErrTimestampParsingFailed
 	}
@@ -183,13 +265,18 @@ func (ts *timestampStage) parseTimestampFromSource(extracted map[string]interfac
 	return &parsedTs, nil
 }
 
-func (ts *timestampStage) processActionOnFailure(labels model.LabelSet, t *time.Time) {
+func (ts *timestampStage) processActionOnFailure(labels model.LabelSet, t *time.Time) bool {
 	switch ts.config.ActionOnFailure {
 	case TimestampActionOnFailureFudge:
 		ts.processActionOnFailureFudge(labels, t)
+	case TimestampActionOnFailureStatic:
+		*t = ts.fallbackTimestamp
+	case TimestampActionOnFailureDrop:
+		return false
 	case TimestampActionOnFailureSkip:
 		// Nothing to do
 	}
+	return true
 }
 
 func (ts *timestampStage) processActionOnFailureFudge(labels model.LabelSet, t *time.Time) {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -99,6 +100,23 @@ func TestTimestampValidation(t *testing.T) {
 			},
 			err: fmt.Errorf(ErrInvalidLocation.Error(), ""),
 		},
+		"missing fallback timestamp": {
+			config: &TimestampConfig{
+				Source:          "source1",
+				Format:          time.RFC3339,
+				ActionOnFailure: TimestampActionOnFailureStatic,
+			},
+			err: ErrTimestampFallbackRequired,
+		},
+		"invalid fallback timestamp": {
+			config: &TimestampConfig{
+				Source:            "source1",
+				Format:            time.RFC3339,
+				ActionOnFailure:   TimestampActionOnFailureStatic,
+				FallbackTimestamp: "yesterday",
+			},
+			err: ErrInvalidFallbackTimestamp,
+		},
 		"standard format": {
 			config: &TimestampConfig{
 				Source: "source1",
@@ -180,7 +198,7 @@ func TestTimestampValidation(t *testing.T) {
 		test := test
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			parser, err := validateTimestampConfig(test.config)
+			parser, err := validateTimestampConfig(test.config, nil)
 			if (err != nil) != (test.err != nil) {
 				t.Errorf("validateTimestampConfig() expected error = %v, actual error = %v", test.err, err)
 				return
@@ -305,7 +323,7 @@ func TestTimestampStage_Process(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 			logger := util.TestAlloyLogger(t)
-			st, err := newTimestampStage(logger, test.config)
+			st, err := newTimestampStage(logger, test.config, prometheus.NewRegistry())
 			require.NoError(t, err)
 
 			out := processEntries(st, newEntry(test.extracted, nil, "hello world", time.Now()))[0]
@@ -393,6 +411,22 @@ func TestTimestampStage_ProcessActionOnFailure(t *testing.T) {
 				time.Unix(1, 0),
 			},
 		},
+		"should set the fallback timestamp on action_on_failure=static": {
+			config: TimestampConfig{
+				Source:            "time",
+				Format:            time.RFC3339Nano,
+				ActionOnFailure:   TimestampActionOnFailureStatic,
+				FallbackTimestamp: "2000-01-01T00:00:00Z",
+			},
+			inputEntries: []inputEntry{
+				{timestamp: time.Unix(1, 0), extracted: map[string]interface{}{"time": "2019-10-01T01:02:03.400000000Z"}},
+				{timestamp: time.Unix(1, 0), extracted: map[string]interface{}{"time": "not a timestamp"}},
+			},
+			expectedTimestamps: []time.Time{
+				mustParseTime(time.RFC3339Nano, "2019-10-01T01:02:03.400000000Z"),
+				mustParseTime(time.RFC3339Nano, "2000-01-01T00:00:00Z"),
+			},
+		},
 		"should keep the input timestamp on action_on_failure=skip": {
 			config: TimestampConfig{
 				Source:          "time",
@@ -443,7 +477,7 @@ func TestTimestampStage_ProcessActionOnFailure(t *testing.T) {
 			require.Equal(t, len(testData.inputEntries), len(testData.expectedTimestamps))
 
 			logger := util.TestAlloyLogger(t)
-			s, err := newTimestampStage(logger, testData.config)
+			s, err := newTimestampStage(logger, testData.config, prometheus.NewRegistry())
 			require.NoError(t, err)
 
 			for i, inputEntry := range testData.inputEntries {
@@ -453,3 +487,35 @@ func TestTimestampStage_ProcessActionOnFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestTimestampStage_DropAndParseFailures(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s, err := newTimestampStage(util.TestAlloyLogger(t), TimestampConfig{
+		Source:          "time",
+		Format:          time.RFC3339,
+		FallbackFormats: []string{time.DateTime},
+		ActionOnFailure: TimestampActionOnFailureDrop,
+	}, registry)
+	require.NoError(t, err)
+
+	out := processEntries(s,
+		newEntry(map[string]interface{}{"time": "2019-10-01T01:02:03Z"}, nil, "rfc3339", time.Unix(1, 0)),
+		newEntry(map[string]interface{}{"time": "2019-10-01 01:02:03"}, nil, "datetime", time.Unix(1, 0)),
+		newEntry(map[string]interface{}{"time": "invalid"}, nil, "invalid", time.Unix(1, 0)),
+	)
+	require.Len(t, out, 2)
+	assert.Equal(t, "rfc3339", out[0].Line)
+	assert.Equal(t, "datetime", out[1].Line)
+
+	expected := `
+# HELP loki_process_dropped_lines_total A count of all log lines dropped as a result of a pipeline stage
+# TYPE loki_process_dropped_lines_total counter
+loki_process_dropped_lines_total{reason="timestamp_parse_failure"} 1
+# HELP loki_process_timestamp_parse_failures_total A count of all timestamps which failed to parse in a timestamp stage, by format
+# TYPE loki_process_timestamp_parse_failures_total counter
+loki_process_timestamp_parse_failures_total{format="2006-01-02 15:04:05"} 1
+loki_process_timestamp_parse_failures_total{format="2006-01-02T15:04:05Z07:00"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loki_process_dropped_lines_total", "loki_process_timestamp_parse_failures_total"))
+}
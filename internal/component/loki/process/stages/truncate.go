@@ -0,0 +1,101 @@
+package stages
+
+import (
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Configuration errors.
+var (
+	ErrTruncateInvalidLimit  = errors.New("truncate stage limit must be greater than 0")
+	ErrTruncateSuffixTooLong = errors.New("truncate stage suffix must be shorter than limit")
+)
+
+// TruncateConfig configures a processing stage that truncates long log lines.
+type TruncateConfig struct {
+	Limit           units.Base2Bytes `alloy:"limit,attr"`
+	Suffix          string           `alloy:"suffix,attr,optional"`
+	OriginalSizeKey string           `alloy:"original_size_key,attr,optional"`
+	When            string           `alloy:"when,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (c *TruncateConfig) Validate() error {
+	if c.Limit <= 0 {
+		return ErrTruncateInvalidLimit
+	}
+	if int64(len(c.Suffix)) >= int64(c.Limit) {
+		return ErrTruncateSuffixTooLong
+	}
+	return nil
+}
+
+// truncateStage truncates log lines longer than a limit.
+type truncateStage struct {
+	cfg       TruncateConfig
+	logger    log.Logger
+	truncated prometheus.Counter
+}
+
+func newTruncateStage(logger log.Logger, cfg TruncateConfig, registerer prometheus.Registerer) (Stage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &truncateStage{
+		cfg:    cfg,
+		logger: log.With(logger, "component", "stage", "type", "truncate"),
+		truncated: registerCounterVec(registerer, "loki_process", "truncated_lines_total",
+			"A count of all log lines truncated by a truncate stage", nil).WithLabelValues(),
+	}, nil
+}
+
+// Run implements Stage.
+func (m *truncateStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		size := len(e.Line)
+		if int64(size) <= int64(m.cfg.Limit) {
+			return e
+		}
+
+		e.Line = truncateLine(e.Line, int(m.cfg.Limit)-len(m.cfg.Suffix)) + m.cfg.Suffix
+		if m.cfg.OriginalSizeKey != "" {
+			e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{
+				Name:  m.cfg.OriginalSizeKey,
+				Value: strconv.Itoa(size),
+			})
+		}
+		m.truncated.Inc()
+		if Debug {
+			level.Debug(m.logger).Log("msg", "truncated log line", "original_size", size)
+		}
+		return e
+	})
+}
+
+// truncateLine returns the longest prefix of line which is at most size bytes
+// long, without splitting a multi-byte character.
+func truncateLine(line string, size int) string {
+	for size > 0 && !utf8.RuneStart(line[size]) {
+		size--
+	}
+	return line[:size]
+}
+
+// Name implements Stage.
+func (m *truncateStage) Name() string {
+	return StageTypeTruncate
+}
+
+// Cleanup implements Stage.
+func (*truncateStage) Cleanup() {
+	// no-op
+}
@@ -0,0 +1,84 @@
+package stages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/util"
+)
+
+var testTruncateAlloy = `
+stage.truncate {
+    limit             = "16B"
+    suffix            = "..."
+    original_size_key = "original_size"
+}
+`
+
+func TestTruncatePipeline(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger := util.TestAlloyLogger(t)
+	pl, err := NewPipeline(logger, loadConfig(testTruncateAlloy), nil, registry)
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, nil, "short line", time.Now()),
+		newEntry(nil, nil, "exactly 16 bytes", time.Now()),
+		newEntry(nil, nil, "this line is way too long", time.Now()),
+	)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, "short line", out[0].Line)
+	assert.Empty(t, out[0].StructuredMetadata)
+	assert.Equal(t, "exactly 16 bytes", out[1].Line)
+	assert.Empty(t, out[1].StructuredMetadata)
+	assert.Equal(t, "this line is ...", out[2].Line)
+	assert.Equal(t, []logproto.LabelAdapter{{Name: "original_size", Value: "25"}}, []logproto.LabelAdapter(out[2].StructuredMetadata))
+
+	expected := `
+# HELP loki_process_truncated_lines_total A count of all log lines truncated by a truncate stage
+# TYPE loki_process_truncated_lines_total counter
+loki_process_truncated_lines_total 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "loki_process_truncated_lines_total"))
+}
+
+func TestTruncateLine(t *testing.T) {
+	assert.Equal(t, "abc", truncateLine("abcdef", 3))
+	// Multi-byte characters are never split.
+	assert.Equal(t, "a", truncateLine("aüb", 2))
+	assert.Equal(t, "aü", truncateLine("aüb", 3))
+	assert.Equal(t, "", truncateLine("üb", 1))
+}
+
+func TestTruncateConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		config TruncateConfig
+		err    error
+	}{
+		"invalid limit": {
+			TruncateConfig{},
+			ErrTruncateInvalidLimit,
+		},
+		"suffix too long": {
+			TruncateConfig{Limit: 3, Suffix: "..."},
+			ErrTruncateSuffixTooLong,
+		},
+		"valid": {
+			TruncateConfig{Limit: 4, Suffix: "..."},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			require.Equal(t, tt.err, tt.config.Validate())
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package process
+
+import (
+	"sync"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+// workerPool is an entry handler which distributes entries across the entry
+// handlers of several pipelines, so that they're processed in parallel.
+type workerPool struct {
+	in       chan loki.Entry
+	handlers []loki.EntryHandler
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// newWorkerPool creates a worker pool which sends entries to the handlers.
+// If preserveStreamOrder is true, all the entries of a stream are sent to the
+// same handler, so that they're processed in order. Otherwise, entries are
+// sent to the first available handler.
+func newWorkerPool(handlers []loki.EntryHandler, preserveStreamOrder bool) *workerPool {
+	p := &workerPool{
+		in:       make(chan loki.Entry),
+		handlers: handlers,
+	}
+
+	if preserveStreamOrder {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for e := range p.in {
+				h := handlers[uint64(e.Labels.FastFingerprint())%uint64(len(handlers))]
+				h.Chan() <- e
+			}
+		}()
+		return p
+	}
+
+	p.wg.Add(len(handlers))
+	for _, h := range handlers {
+		go func(h loki.EntryHandler) {
+			defer p.wg.Done()
+			for e := range p.in {
+				h.Chan() <- e
+			}
+		}(h)
+	}
+	return p
+}
+
+// Chan implements loki.EntryHandler.
+func (p *workerPool) Chan() chan<- loki.Entry {
+	return p.in
+}
+
+// Stop implements loki.EntryHandler. It waits for the entries already received
+// to be sent to the handlers, then stops the handlers.
+func (p *workerPool) Stop() {
+	p.once.Do(func() {
+		close(p.in)
+		p.wg.Wait()
+		for _, h := range p.handlers {
+			h.Stop()
+		}
+	})
+}
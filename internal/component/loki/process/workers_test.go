@@ -0,0 +1,241 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestWorkers(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"))
+
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+forward_to        = []
+workers           = 4
+instrument_stages = true
+
+stage.regex {
+  expression = "^(?P<n>\\d+)$"
+}
+stage.labels {
+  values = { n = "" }
+}
+stage.label_drop {
+  values = ["n"]
+}`), &args))
+	receiver := loki.NewLogsReceiver()
+	args.ForwardTo = []loki.LogsReceiver{receiver}
+
+	registry := prometheus.NewRegistry()
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     registry,
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, c.Run(ctx))
+	}()
+
+	const (
+		streams          = 8
+		entriesPerStream = 50
+	)
+	go func() {
+		for i := 0; i < entriesPerStream; i++ {
+			for s := 0; s < streams; s++ {
+				c.receiver.Chan() <- loki.Entry{
+					Labels: model.LabelSet{"stream": model.LabelValue(fmt.Sprint(s))},
+					Entry:  logproto.Entry{Timestamp: time.Now(), Line: fmt.Sprint(i)},
+				}
+			}
+		}
+	}()
+
+	// The entries of each stream are received in order.
+	next := make(map[model.LabelValue]int)
+	for i := 0; i < streams*entriesPerStream; i++ {
+		select {
+		case e := <-receiver.Chan():
+			stream := e.Labels["stream"]
+			require.Equal(t, fmt.Sprint(next[stream]), e.Line)
+			next[stream]++
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line")
+		}
+	}
+
+	// Each worker reports its own stage metrics.
+	require.Equal(t, 4*3, testutil.CollectAndCount(registry, "loki_process_stage_received_lines_total"))
+
+	cancel()
+	wg.Wait()
+}
+
+func TestWorkerPool_Unordered(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"))
+
+	var (
+		received []string
+		out      = make(chan loki.Entry)
+		handlers []loki.EntryHandler
+		done     = make(chan struct{})
+	)
+	for i := 0; i < 3; i++ {
+		handlers = append(handlers, loki.NewEntryHandler(out, func() {}))
+	}
+	go func() {
+		defer close(done)
+		for e := range out {
+			received = append(received, e.Line)
+		}
+	}()
+
+	var expected []string
+	p := newWorkerPool(handlers, false)
+	for i := 0; i < 100; i++ {
+		expected = append(expected, fmt.Sprint(i))
+		p.Chan() <- loki.Entry{Entry: logproto.Entry{Line: fmt.Sprint(i)}}
+	}
+	p.Stop()
+	close(out)
+	<-done
+
+	require.ElementsMatch(t, expected, received)
+}
+
+func TestWorkers_Update(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"))
+
+	argsWithWorkers := func(workers int) Arguments {
+		var args Arguments
+		require.NoError(t, syntax.Unmarshal([]byte(fmt.Sprintf(`
+forward_to        = []
+workers           = %d
+instrument_stages = true
+
+stage.drop {
+  expression = "^debug"
+}
+stage.metrics {
+  metric.counter {
+    name        = "lines_total"
+    description = "Number of lines."
+    match_all   = true
+    action      = "inc"
+  }
+}`, workers)), &args))
+		return args
+	}
+
+	registry := prometheus.NewRegistry()
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     registry,
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, argsWithWorkers(1))
+	require.NoError(t, err)
+	defer func() {
+		c.mut.RLock()
+		defer c.mut.RUnlock()
+		c.entryHandler.Stop()
+	}()
+
+	// The metrics registered with the previous number of workers are
+	// unregistered, so that they can be registered again with or without the
+	// worker label.
+	for _, workers := range []int{2, 1, 3} {
+		require.NoError(t, c.Update(argsWithWorkers(workers)))
+		_, err := registry.Gather()
+		require.NoError(t, err)
+	}
+}
+
+func TestWorkers_StatefulStages(t *testing.T) {
+	for _, stage := range []string{
+		`stage.limit {
+  rate  = 10
+  burst = 10
+}`,
+		`stage.dedup {
+  window = "1m"
+}`,
+		`stage.match {
+  selector = "{app=\"foo\"}"
+  stage.multiline {
+    firstline = "^start"
+  }
+}`,
+	} {
+		var args Arguments
+		err := syntax.Unmarshal([]byte(`
+forward_to = []
+workers    = 2
+`+stage), &args)
+		require.ErrorContains(t, err, "can't be used when workers is greater than 1")
+
+		require.NoError(t, syntax.Unmarshal([]byte(`
+forward_to = []
+`+stage), &args))
+	}
+}
+
+func TestWorkers_StreamStatefulStages(t *testing.T) {
+	for _, stage := range []string{
+		`stage.csv {
+  header = true
+}`,
+		`stage.cri {}`,
+		`stage.match {
+  selector = "{app=\"foo\"}"
+  stage.cri {}
+}`,
+	} {
+		var args Arguments
+		err := syntax.Unmarshal([]byte(`
+forward_to            = []
+workers               = 2
+preserve_stream_order = false
+`+stage), &args)
+		require.ErrorContains(t, err, "can't be used when workers is greater than 1 and preserve_stream_order is false")
+
+		// The lines of a stream all go to the same worker when the stream
+		// order is preserved.
+		require.NoError(t, syntax.Unmarshal([]byte(`
+forward_to = []
+workers    = 2
+`+stage), &args))
+	}
+
+	// Without a header, stage.csv has no state.
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+forward_to            = []
+workers               = 2
+preserve_stream_order = false
+stage.csv {
+  columns = ["a", "b"]
+}`), &args))
+}
@@ -108,6 +108,64 @@ func TestRelabeling(t *testing.T) {
 	}
 }
 
+func TestRelabeling_Hash(t *testing.T) {
+	type cfg struct {
+		Rcs []*alloy_relabel.Config `alloy:"rule,block,optional"`
+	}
+	var relabelConfigs cfg
+	err := syntax.Unmarshal([]byte(`
+	rule {
+		action        = "hashmod"
+		source_labels = ["user_id"]
+		modulus       = 16
+		target_label  = "user_bucket"
+	}
+	rule {
+		action        = "sha256"
+		source_labels = ["user_id"]
+		target_label  = "user_hash"
+	}
+	rule {
+		action = "labeldrop"
+		regex  = "user_id"
+	}`), &relabelConfigs)
+	require.NoError(t, err)
+
+	ch := loki.NewLogsReceiver()
+	opts := component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     prometheus.NewRegistry(),
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}
+	args := Arguments{
+		ForwardTo:      []loki.LogsReceiver{ch},
+		RelabelConfigs: relabelConfigs.Rcs,
+		MaxCacheSize:   10,
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+	go c.Run(context.Background())
+
+	c.receiver.Chan() <- loki.Entry{
+		Labels: model.LabelSet{"job": "api", "user_id": "alice"},
+		Entry:  logproto.Entry{Timestamp: time.Now(), Line: "login"},
+	}
+
+	select {
+	case logEntry := <-ch.Chan():
+		require.Equal(t, model.LabelSet{
+			"job":         "api",
+			"user_bucket": "12",
+			// echo -n alice | sha256sum
+			"user_hash": "2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90",
+		}, logEntry.Labels)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for log line")
+	}
+}
+
 func BenchmarkRelabelComponent(b *testing.B) {
 	type cfg struct {
 		Rcs []*alloy_relabel.Config `alloy:"rule,block,optional"`
@@ -0,0 +1,26 @@
+package secretfilter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	secretsRedacted *prometheus.CounterVec
+}
+
+// newMetrics creates a new set of metrics. If reg is non-nil, the metrics
+// will also be registered.
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.secretsRedacted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_secretfilter_secrets_redacted_total",
+		Help: "Total number of secrets redacted, by rule",
+	}, []string{"rule"})
+
+	if reg != nil {
+		reg.MustRegister(m.secretsRedacted)
+	}
+
+	return &m
+}
@@ -6,6 +6,7 @@ import (
 	"embed"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -50,12 +51,16 @@ func init() {
 // component.
 type Arguments struct {
 	ForwardTo      []loki.LogsReceiver `alloy:"forward_to,attr"`
-	GitleaksConfig string              `alloy:"gitleaks_config,attr,optional"` // Path to the custom gitleaks.toml file. If empty, the embedded one is used
-	Types          []string            `alloy:"types,attr,optional"`           // Types of secret to look for (e.g. "aws", "gcp", ...). If empty, all types are included
-	RedactWith     string              `alloy:"redact_with,attr,optional"`     // Redact the secret with this string. Use $SECRET_NAME and $SECRET_HASH to include the secret name and hash
-	ExcludeGeneric bool                `alloy:"exclude_generic,attr,optional"` // Exclude the generic API key rule (default: false)
-	AllowList      []string            `alloy:"allowlist,attr,optional"`       // List of regexes to allowlist (on top of what's in the Gitleaks config)
-	PartialMask    uint                `alloy:"partial_mask,attr,optional"`    // Show the first N characters of the secret (default: 0)
+	GitleaksConfig string              `alloy:"gitleaks_config,attr,optional"`  // Path to the custom gitleaks.toml file. If empty, the embedded one is used
+	Types          []string            `alloy:"types,attr,optional"`            // Types of secret to look for (e.g. "aws", "gcp", ...). If empty, all types are included
+	RedactWith     string              `alloy:"redact_with,attr,optional"`      // Redact the secret with this string. Use $SECRET_NAME and $SECRET_HASH to include the secret name and hash
+	ExcludeGeneric bool                `alloy:"exclude_generic,attr,optional"`  // Exclude the generic API key rule (default: false)
+	AllowList      []string            `alloy:"allowlist,attr,optional"`        // List of regexes to allowlist (on top of what's in the Gitleaks config)
+	AllowListPaths []string            `alloy:"allowlist_paths,attr,optional"`  // List of regexes of file paths (in the filename label) whose entries aren't redacted
+	PartialMask    uint                `alloy:"partial_mask,attr,optional"`     // Show the first N characters of the secret (default: 0)
+	RuleFiles      []string            `alloy:"rule_files,attr,optional"`       // Paths to additional gitleaks.toml files. Their rules replace the rules with the same ID
+	DisabledRules  []string            `alloy:"disabled_rules,attr,optional"`   // IDs of the rules to disable
+	RuleRedactWith map[string]string   `alloy:"rule_redact_with,attr,optional"` // Redaction strings by rule ID, used instead of redact_with
 }
 
 // Exports holds the values exported by the loki.secretfilter component.
@@ -80,13 +85,15 @@ var (
 type Component struct {
 	opts component.Options
 
-	mut       sync.RWMutex
-	args      Arguments
-	receiver  loki.LogsReceiver
-	fanout    []loki.LogsReceiver
-	Rules     []Rule
-	AllowList []AllowRule
+	mut            sync.RWMutex
+	args           Arguments
+	receiver       loki.LogsReceiver
+	fanout         []loki.LogsReceiver
+	Rules          []Rule
+	AllowList      []AllowRule
+	AllowListPaths []AllowRule
 
+	metrics            *metrics
 	debugDataPublisher livedebugging.DebugDataPublisher
 }
 
@@ -97,18 +104,35 @@ type GitLeaksConfig struct {
 		Paths       []string
 		Regexes     []string
 	}
-	Rules []struct {
-		ID          string
-		Description string
-		Regex       string
-		Keywords    []string
-		SecretGroup int
+	Rules []GitLeaksRule
+}
+
+// GitLeaksRule is a rule of a GitLeaksConfig.
+type GitLeaksRule struct {
+	ID          string
+	Description string
+	Regex       string
+	Keywords    []string
+	SecretGroup int
+
+	Allowlist struct {
+		StopWords []string
+		Regexes   []string
+	}
+}
 
-		Allowlist struct {
-			StopWords []string
-			Regexes   []string
+// merge adds the rules and the global allowlist of other to cfg. The rules of
+// other replace the rules of cfg with the same ID.
+func (cfg *GitLeaksConfig) merge(other GitLeaksConfig) {
+	for _, rule := range other.Rules {
+		i := slices.IndexFunc(cfg.Rules, func(r GitLeaksRule) bool { return r.ID == rule.ID })
+		if i >= 0 {
+			cfg.Rules[i] = rule
+		} else {
+			cfg.Rules = append(cfg.Rules, rule)
 		}
 	}
+	cfg.AllowList.Regexes = append(cfg.AllowList.Regexes, other.AllowList.Regexes...)
 }
 
 // New creates a new loki.secretfilter component.
@@ -121,6 +145,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
 		opts:               o,
 		receiver:           loki.NewLogsReceiver(),
+		metrics:            newMetrics(o.Registerer),
 		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
 	}
 
@@ -145,13 +170,16 @@ func (c *Component) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case entry := <-c.receiver.Chan():
+			c.mut.RLock()
 			// Start processing the log entry to redact secrets
 			newEntry := c.processEntry(entry)
+			fanout := c.fanout
+			c.mut.RUnlock()
 			if c.debugDataPublisher.IsActive(componentID) {
 				c.debugDataPublisher.Publish(componentID, fmt.Sprintf("%s => %s", entry.Line, newEntry.Line))
 			}
 
-			for _, f := range c.fanout {
+			for _, f := range fanout {
 				select {
 				case <-ctx.Done():
 					return nil
@@ -163,6 +191,16 @@ func (c *Component) Run(ctx context.Context) error {
 }
 
 func (c *Component) processEntry(entry loki.Entry) loki.Entry {
+	// Entries read from allowlisted files aren't redacted
+	if filename, ok := entry.Labels["filename"]; ok {
+		for _, a := range c.AllowListPaths {
+			if a.Regex.MatchString(string(filename)) {
+				level.Debug(c.opts.Logger).Log("msg", "path in allowlist", "filename", filename, "source", a.Source)
+				return entry
+			}
+		}
+	}
+
 	for _, r := range c.Rules {
 		// To find the secret within the text captured by the regex (and avoid being too greedy), we can use the 'secretGroup' field in the gitleaks.toml file.
 		// But it's rare for regexes to have this field set, so we can use a simple heuristic in other cases.
@@ -211,6 +249,7 @@ func (c *Component) processEntry(entry loki.Entry) loki.Entry {
 
 			// Redact the secret
 			entry.Line = c.redactLine(entry.Line, secret, r.name)
+			c.metrics.secretsRedacted.WithLabelValues(r.name).Inc()
 		}
 	}
 
@@ -219,7 +258,10 @@ func (c *Component) processEntry(entry loki.Entry) loki.Entry {
 
 func (c *Component) redactLine(line string, secret string, ruleName string) string {
 	var redactWith = "<REDACTED-SECRET:" + ruleName + ">"
-	if c.args.RedactWith != "" {
+	if ruleRedactWith, ok := c.args.RuleRedactWith[ruleName]; ok {
+		redactWith = strings.ReplaceAll(ruleRedactWith, "$SECRET_NAME", ruleName)
+		redactWith = strings.ReplaceAll(redactWith, "$SECRET_HASH", hashSecret(secret))
+	} else if c.args.RedactWith != "" {
 		redactWith = c.args.RedactWith
 		redactWith = strings.ReplaceAll(redactWith, "$SECRET_NAME", ruleName)
 		redactWith = strings.ReplaceAll(redactWith, "$SECRET_HASH", hashSecret(secret))
@@ -243,15 +285,9 @@ func hashSecret(secret string) string {
 func (c *Component) Update(args component.Arguments) error {
 	newArgs := args.(Arguments)
 
-	c.mut.Lock()
-	defer c.mut.Unlock()
-	c.args = newArgs
-
-	c.fanout = newArgs.ForwardTo
-
 	// Parse GitLeaks configuration
 	var gitleaksCfg GitLeaksConfig
-	if c.args.GitleaksConfig == "" {
+	if newArgs.GitleaksConfig == "" {
 		// If no config file is explicitely provided, use the embedded one
 		_, err := toml.DecodeFS(embedFs, "gitleaks.toml", &gitleaksCfg)
 		if err != nil {
@@ -259,20 +295,55 @@ func (c *Component) Update(args component.Arguments) error {
 		}
 	} else {
 		// If a config file is provided, use that
-		_, err := toml.DecodeFile(c.args.GitleaksConfig, &gitleaksCfg)
+		_, err := toml.DecodeFile(newArgs.GitleaksConfig, &gitleaksCfg)
 		if err != nil {
 			return err
 		}
 	}
 
-	var ruleGenericApiKey *Rule = nil
+	// Add the rules of the additional rule files
+	for _, path := range newArgs.RuleFiles {
+		var ruleFileCfg GitLeaksConfig
+		if _, err := toml.DecodeFile(path, &ruleFileCfg); err != nil {
+			return fmt.Errorf("failed to read rule file %s: %w", path, err)
+		}
+		gitleaksCfg.merge(ruleFileCfg)
+	}
+
+	// Rules are removed from the Gitleaks configuration over time, so unknown
+	// rule IDs aren't an error
+	ruleIDs := make(map[string]struct{}, len(gitleaksCfg.Rules))
+	for _, rule := range gitleaksCfg.Rules {
+		ruleIDs[rule.ID] = struct{}{}
+	}
+	disabledRules := make(map[string]struct{}, len(newArgs.DisabledRules))
+	for _, id := range newArgs.DisabledRules {
+		if _, ok := ruleIDs[id]; !ok {
+			level.Warn(c.opts.Logger).Log("msg", "disabled rule doesn't exist", "rule", id)
+		}
+		disabledRules[id] = struct{}{}
+	}
+	for id := range newArgs.RuleRedactWith {
+		if _, ok := ruleIDs[id]; !ok {
+			level.Warn(c.opts.Logger).Log("msg", "rule of rule_redact_with doesn't exist", "rule", id)
+		}
+	}
+
+	var (
+		rules             []Rule
+		ruleGenericApiKey *Rule
+	)
 
 	// Compile regexes
 	for _, rule := range gitleaksCfg.Rules {
+		if _, ok := disabledRules[rule.ID]; ok {
+			continue
+		}
+
 		// If specific secret types are provided, only include rules that match the types
-		if c.args.Types != nil && len(c.args.Types) > 0 {
+		if len(newArgs.Types) > 0 {
 			var found bool
-			for _, t := range c.args.Types {
+			for _, t := range newArgs.Types {
 				if strings.HasPrefix(strings.ToLower(rule.ID), strings.ToLower(t)) {
 					found = true
 					break
@@ -313,11 +384,12 @@ func (c *Component) Update(args component.Arguments) error {
 		if strings.ToLower(rule.ID) == "generic-api-key" {
 			ruleGenericApiKey = &newRule
 		} else {
-			c.Rules = append(c.Rules, newRule)
+			rules = append(rules, newRule)
 		}
 	}
 
 	// Compiling global allowlist regexes
+	var allowList []AllowRule
 	// From the Gitleaks config
 	for _, r := range gitleaksCfg.AllowList.Regexes {
 		re, err := regexp.Compile(r)
@@ -325,23 +397,43 @@ func (c *Component) Update(args component.Arguments) error {
 			level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
 			return err
 		}
-		c.AllowList = append(c.AllowList, AllowRule{Regex: re, Source: "gitleaks config"})
+		allowList = append(allowList, AllowRule{Regex: re, Source: "gitleaks config"})
 	}
 	// From the arguments
-	for _, r := range c.args.AllowList {
+	for _, r := range newArgs.AllowList {
 		re, err := regexp.Compile(r)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
 			return err
 		}
-		c.AllowList = append(c.AllowList, AllowRule{Regex: re, Source: "alloy config"})
+		allowList = append(allowList, AllowRule{Regex: re, Source: "alloy config"})
+	}
+
+	// The paths of the Gitleaks config are meant for repositories, so only the
+	// paths from the arguments are used
+	var allowListPaths []AllowRule
+	for _, r := range newArgs.AllowListPaths {
+		re, err := regexp.Compile(r)
+		if err != nil {
+			level.Error(c.opts.Logger).Log("msg", "error compiling allowlist path regex", "error", err)
+			return err
+		}
+		allowListPaths = append(allowListPaths, AllowRule{Regex: re, Source: "alloy config"})
 	}
 
 	// Add the generic API key rule last if needed
-	if ruleGenericApiKey != nil && !c.args.ExcludeGeneric {
-		c.Rules = append(c.Rules, *ruleGenericApiKey)
+	if ruleGenericApiKey != nil && !newArgs.ExcludeGeneric {
+		rules = append(rules, *ruleGenericApiKey)
 	}
 
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.fanout = newArgs.ForwardTo
+	c.Rules = rules
+	c.AllowList = allowList
+	c.AllowListPaths = allowListPaths
+
 	level.Info(c.opts.Logger).Log("Compiled regexes for secret detection", len(c.Rules))
 
 	return nil
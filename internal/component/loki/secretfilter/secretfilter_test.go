@@ -7,11 +7,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/util"
 	"github.com/grafana/alloy/syntax"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -74,6 +78,15 @@ var testConfigs = map[string]string{
 		forward_to = []
 		exclude_generic = true
 	`,
+	"disabled_rules": `
+		forward_to = []
+		disabled_rules = ["grafana-api-key"]
+	`,
+	"rule_redact_with": `
+		forward_to = []
+		redact_with = "<` + customRedactionString + `:$SECRET_NAME>"
+		rule_redact_with = {"grafana-api-key" = "<` + defaultRedactionString + `:$SECRET_NAME>"}
+	`,
 	"custom_gitleaks_file_simple": `
 		forward_to = []
 		gitleaks_config = "not-empty" // This will be replaced with the actual path to the temporary gitleaks config file
@@ -239,6 +252,23 @@ var tt = []struct {
 		testLogs["simple_secret_generic"].log,
 		testLogs["simple_secret_generic"].log, // Generic secret is excluded so no redaction expected
 	},
+	{
+		"disabled_rules",
+		testConfigs["disabled_rules"],
+		"",
+		testLogs["simple_secret"].log,
+		testLogs["simple_secret"].log, // Grafana API key rule is disabled, no redaction expected
+	},
+	{
+		"rule_redact_with",
+		testConfigs["rule_redact_with"],
+		"",
+		testLogs["multiple_secrets"].log,
+		replaceSecrets(
+			replaceSecrets(testLogs["multiple_secrets"].log, []fakeSecret{fakeSecrets["grafana-api-key"]}, false, false, defaultRedactionString),
+			[]fakeSecret{fakeSecrets["gcp-api-key"]}, false, false, customRedactionString,
+		),
+	},
 	{
 		"custom_gitleaks_file_simple",
 		testConfigs["custom_gitleaks_file_simple"],
@@ -336,3 +366,87 @@ func createTempGitleaksConfig(t *testing.T, content string) string {
 func deleteTempGitLeaksConfig(t *testing.T, path string) {
 	require.NoError(t, os.Remove(path))
 }
+
+func TestRuleFiles(t *testing.T) {
+	ruleFile := createTempGitleaksConfig(t, customGitleaksConfig["simple"]+`
+		[[rules]]
+		id = "gcp-api-key"
+		description = "Never matches"
+		regex = '''neverMatches'''
+	`)
+	defer deleteTempGitLeaksConfig(t, ruleFile)
+
+	config := `
+		forward_to = []
+		rule_files = ["` + ruleFile + `"]
+	`
+
+	t.Run("added rule", func(t *testing.T) {
+		log := testLogs["simple_secret_custom"]
+		runTest(t, config, "", log.log, replaceSecrets(log.log, log.secrets, false, false, defaultRedactionString))
+	})
+	t.Run("built-in rule", func(t *testing.T) {
+		log := testLogs["simple_secret"]
+		runTest(t, config, "", log.log, replaceSecrets(log.log, log.secrets, false, false, defaultRedactionString))
+	})
+	t.Run("replaced rule", func(t *testing.T) {
+		log := testLogs["simple_secret_gcp"]
+		runTest(t, config, "", log.log, log.log)
+	})
+}
+
+func TestAllowListPaths(t *testing.T) {
+	ch := loki.NewLogsReceiver()
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     prometheus.NewRegistry(),
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, Arguments{
+		ForwardTo:      []loki.LogsReceiver{ch},
+		AllowListPaths: []string{"/var/log/test/.*"},
+	})
+	require.NoError(t, err)
+
+	log := testLogs["simple_secret"]
+	for _, tc := range []struct {
+		filename string
+		expected string
+	}{
+		{"/var/log/test/app.log", log.log},
+		{"/var/log/app.log", replaceSecrets(log.log, log.secrets, false, false, defaultRedactionString)},
+	} {
+		entry := c.processEntry(loki.Entry{Labels: model.LabelSet{"filename": model.LabelValue(tc.filename)}, Entry: logproto.Entry{Line: log.log}})
+		require.Equal(t, tc.expected, entry.Line, tc.filename)
+	}
+}
+
+func TestRedactionMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c, err := New(component.Options{
+		Logger:         util.TestAlloyLogger(t),
+		Registerer:     reg,
+		OnStateChange:  func(e component.Exports) {},
+		GetServiceData: getServiceData,
+	}, Arguments{})
+	require.NoError(t, err)
+
+	c.processEntry(loki.Entry{Entry: logproto.Entry{Line: testLogs["multiple_secrets"].log}})
+	c.processEntry(loki.Entry{Entry: logproto.Entry{Line: testLogs["simple_secret"].log}})
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP loki_secretfilter_secrets_redacted_total Total number of secrets redacted, by rule
+# TYPE loki_secretfilter_secrets_redacted_total counter
+loki_secretfilter_secrets_redacted_total{rule="gcp-api-key"} 1
+loki_secretfilter_secrets_redacted_total{rule="grafana-api-key"} 2
+`)))
+}
+
+func getServiceData(name string) (interface{}, error) {
+	switch name {
+	case livedebugging.ServiceName:
+		return livedebugging.NewLiveDebugging(), nil
+	default:
+		return nil, fmt.Errorf("service not found %s", name)
+	}
+}
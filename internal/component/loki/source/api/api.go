@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sync"
 
+	"github.com/alecthomas/units"
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	fnet "github.com/grafana/alloy/internal/component/common/net"
@@ -34,6 +35,48 @@ type Arguments struct {
 	Labels               map[string]string   `alloy:"labels,attr,optional"`
 	RelabelRules         relabel.Rules       `alloy:"relabel_rules,attr,optional"`
 	UseIncomingTimestamp bool                `alloy:"use_incoming_timestamp,attr,optional"`
+	JSONMapping          *JSONMapping        `alloy:"json_mapping,block,optional"`
+}
+
+// JSONMapping configures how JSON payloads sent to the /loki/api/v1/json
+// endpoint are mapped to log entries, using JMESPath expressions.
+type JSONMapping struct {
+	Entries         string            `alloy:"entries,attr,optional"`
+	Message         string            `alloy:"message,attr,optional"`
+	Timestamp       string            `alloy:"timestamp,attr,optional"`
+	TimestampFormat string            `alloy:"timestamp_format,attr,optional"`
+	Labels          map[string]string `alloy:"labels,attr,optional"`
+	MaxBodySize     units.Base2Bytes  `alloy:"max_body_size,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (m *JSONMapping) SetToDefault() {
+	*m = JSONMapping{MaxBodySize: lokipush.DefaultMaxJSONBodySize}
+}
+
+// Validate implements syntax.Validator.
+func (m *JSONMapping) Validate() error {
+	if m.MaxBodySize <= 0 {
+		return fmt.Errorf("max_body_size must be greater than 0")
+	}
+	for name := range m.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+	_, err := m.build()
+	return err
+}
+
+func (m *JSONMapping) build() (*lokipush.JSONMapping, error) {
+	return lokipush.NewJSONMapping(lokipush.JSONMappingConfig{
+		Entries:         m.Entries,
+		Message:         m.Message,
+		Timestamp:       m.Timestamp,
+		TimestampFormat: m.TimestampFormat,
+		Labels:          m.Labels,
+		MaxBodySize:     int64(m.MaxBodySize),
+	})
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -156,6 +199,15 @@ func (c *Component) Update(args component.Arguments) error {
 	c.server.SetRelabelRules(newArgs.RelabelRules)
 	c.server.SetKeepTimestamp(newArgs.UseIncomingTimestamp)
 
+	var jsonMapping *lokipush.JSONMapping
+	if newArgs.JSONMapping != nil {
+		var err error
+		if jsonMapping, err = newArgs.JSONMapping.build(); err != nil {
+			return fmt.Errorf("invalid json_mapping: %w", err)
+		}
+	}
+	c.server.SetJSONMapping(jsonMapping)
+
 	return nil
 }
 
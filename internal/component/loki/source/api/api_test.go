@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/phayes/freeport"
 
+	"github.com/alecthomas/units"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/regexp"
@@ -24,6 +26,7 @@ import (
 	"github.com/grafana/alloy/internal/component/common/net"
 	"github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
 )
 
 func TestLokiSourceAPI_Simple(t *testing.T) {
@@ -72,6 +75,102 @@ func TestLokiSourceAPI_Simple(t *testing.T) {
 	})
 }
 
+func TestLokiSourceAPI_JSONMapping(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	receiver := fake.NewClient(func() {})
+	defer receiver.Stop()
+
+	args := testArgsWith(t, func(a *Arguments) {
+		a.ForwardTo = []loki.LogsReceiver{receiver.LogsReceiver()}
+		a.JSONMapping = &JSONMapping{
+			Entries:   "alerts",
+			Message:   "summary",
+			Timestamp: "startsAt",
+			Labels:    map[string]string{"tag": "tag", "alertname": "name"},
+		}
+	})
+	opts := defaultOptions(t)
+	_, shutdown := startTestComponent(t, opts, args, ctx)
+	defer shutdown()
+
+	body := `{"alerts": [
+		{"name": "DiskFull", "summary": "disk is full", "startsAt": "2024-05-06T07:08:09Z"},
+		{"name": "Noisy", "summary": "ignored", "tag": "ignore"}
+	]}`
+	require.Eventually(t, func() bool {
+		resp, err := http.Post(fmt.Sprintf(
+			"http://%s:%d/api/v1/json",
+			args.Server.HTTP.ListenAddress,
+			args.Server.HTTP.ListenPort,
+		), "application/json", strings.NewReader(body))
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusNoContent
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.Eventually(
+		t,
+		func() bool { return len(receiver.Received()) == 1 },
+		5*time.Second,
+		10*time.Millisecond,
+		"did not receive the forwarded message within the timeout",
+	)
+	received := receiver.Received()[0]
+	assert.Equal(t, "disk is full", received.Line)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), received.Timestamp.UTC())
+	assert.Equal(t, model.LabelSet{
+		"alertname": "DiskFull",
+		"foo":       "bar",
+		"fizz":      "buzz",
+	}, received.Labels)
+}
+
+func TestJSONMappingAlloyConfig(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		forward_to = []
+		json_mapping {
+			entries          = "records"
+			message          = "body.text"
+			timestamp        = "ts"
+			timestamp_format = "UnixMs"
+			labels           = {service = "source.name"}
+		}
+	`), &args)
+	require.NoError(t, err)
+	require.Equal(t, "records", args.JSONMapping.Entries)
+	require.Equal(t, units.MiB, args.JSONMapping.MaxBodySize)
+
+	err = syntax.Unmarshal([]byte(`
+		forward_to = []
+		json_mapping {
+			max_body_size = "10MiB"
+		}
+	`), &args)
+	require.NoError(t, err)
+	require.Equal(t, 10*units.MiB, args.JSONMapping.MaxBodySize)
+
+	err = syntax.Unmarshal([]byte(`
+		forward_to = []
+		json_mapping {
+			max_body_size = "0B"
+		}
+	`), &args)
+	require.ErrorContains(t, err, "max_body_size must be greater than 0")
+
+	err = syntax.Unmarshal([]byte(`
+		forward_to = []
+		json_mapping {
+			labels = {service = "source.[name"}
+		}
+	`), &args)
+	require.ErrorContains(t, err, `invalid label "service" expression`)
+}
+
 func TestLokiSourceAPI_Update(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
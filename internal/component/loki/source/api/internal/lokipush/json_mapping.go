@@ -0,0 +1,260 @@
+package lokipush
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// Timestamp formats of JSON payloads which aren't Go time layouts.
+const (
+	TimestampFormatRFC3339 = "RFC3339"
+	TimestampFormatUnix    = "Unix"
+	TimestampFormatUnixMs  = "UnixMs"
+	TimestampFormatUnixUs  = "UnixUs"
+	TimestampFormatUnixNs  = "UnixNs"
+)
+
+// DefaultMaxJSONBodySize is the default maximum size of a JSON payload.
+const DefaultMaxJSONBodySize = 1 << 20
+
+// JSONMappingConfig configures how JSON payloads, such as the bodies of
+// webhooks, are mapped to log entries. Fields are selected with JMESPath
+// expressions.
+type JSONMappingConfig struct {
+	// Entries selects the array of entries of a payload. If empty, a payload
+	// which is an array holds one entry per element, and any other payload is
+	// a single entry.
+	Entries string
+	// Message selects the log line of an entry. If empty, the whole entry is
+	// used. Values which aren't strings are encoded as JSON.
+	Message string
+	// Timestamp selects the timestamp of an entry. If empty, or if the
+	// timestamp of an entry is missing, the time of the request is used.
+	Timestamp string
+	// TimestampFormat is either one of the TimestampFormat constants, or a Go
+	// time layout. Defaults to TimestampFormatRFC3339.
+	TimestampFormat string
+	// Labels maps label names to the expressions selecting their values.
+	Labels map[string]string
+	// MaxBodySize is the maximum size of a payload in bytes. Defaults to
+	// DefaultMaxJSONBodySize.
+	MaxBodySize int64
+}
+
+// JSONMapping maps JSON payloads to log entries.
+type JSONMapping struct {
+	entries         *jmespath.JMESPath
+	message         *jmespath.JMESPath
+	timestamp       *jmespath.JMESPath
+	timestampFormat string
+	labels          map[string]*jmespath.JMESPath
+	maxBodySize     int64
+}
+
+// jsonEntry is a log entry extracted from a JSON payload.
+type jsonEntry struct {
+	line      string
+	timestamp time.Time
+	labels    map[string]string
+}
+
+// NewJSONMapping compiles the expressions of cfg.
+func NewJSONMapping(cfg JSONMappingConfig) (*JSONMapping, error) {
+	var (
+		m   = &JSONMapping{timestampFormat: cfg.TimestampFormat, maxBodySize: cfg.MaxBodySize}
+		err error
+	)
+	compile := func(field, expr string) *jmespath.JMESPath {
+		if expr == "" || err != nil {
+			return nil
+		}
+		var compiled *jmespath.JMESPath
+		compiled, err = jmespath.Compile(expr)
+		if err != nil {
+			err = fmt.Errorf("invalid %s expression %q: %w", field, expr, err)
+		}
+		return compiled
+	}
+
+	m.entries = compile("entries", cfg.Entries)
+	m.message = compile("message", cfg.Message)
+	m.timestamp = compile("timestamp", cfg.Timestamp)
+	m.labels = make(map[string]*jmespath.JMESPath, len(cfg.Labels))
+	for name, expr := range cfg.Labels {
+		m.labels[name] = compile(fmt.Sprintf("label %q", name), expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.timestampFormat == "" {
+		m.timestampFormat = TimestampFormatRFC3339
+	}
+	if m.maxBodySize <= 0 {
+		m.maxBodySize = DefaultMaxJSONBodySize
+	}
+	return m, nil
+}
+
+// extract returns the entries of a JSON payload. If any entry can't be
+// mapped, no entries are returned along with the error.
+func (m *JSONMapping) extract(payload []byte, now time.Time) ([]jsonEntry, error) {
+	var data interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON payload: %w", err)
+	}
+
+	items := []interface{}{data}
+	if m.entries != nil {
+		selected, err := m.entries.Search(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select entries: %w", err)
+		}
+		arr, ok := selected.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("entries expression must select an array, got %T", selected)
+		}
+		items = arr
+	} else if arr, ok := data.([]interface{}); ok {
+		items = arr
+	}
+
+	entries := make([]jsonEntry, 0, len(items))
+	for i, item := range items {
+		entry, err := m.extractEntry(item, now)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (m *JSONMapping) extractEntry(item interface{}, now time.Time) (jsonEntry, error) {
+	entry := jsonEntry{timestamp: now, labels: make(map[string]string, len(m.labels))}
+
+	message := item
+	if m.message != nil {
+		var err error
+		message, err = m.message.Search(item)
+		if err != nil {
+			return entry, fmt.Errorf("failed to select message: %w", err)
+		}
+		if message == nil {
+			return entry, fmt.Errorf("message not found in entry")
+		}
+	}
+	line, err := stringValue(message)
+	if err != nil {
+		return entry, err
+	}
+	entry.line = line
+
+	if m.timestamp != nil {
+		value, err := m.timestamp.Search(item)
+		if err != nil {
+			return entry, fmt.Errorf("failed to select timestamp: %w", err)
+		}
+		if value != nil {
+			entry.timestamp, err = parseTimestamp(value, m.timestampFormat)
+			if err != nil {
+				return entry, err
+			}
+		}
+	}
+
+	for name, expr := range m.labels {
+		value, err := expr.Search(item)
+		if err != nil {
+			return entry, fmt.Errorf("failed to select label %q: %w", name, err)
+		}
+		if value == nil {
+			continue
+		}
+		if entry.labels[name], err = stringValue(value); err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
+
+// stringValue returns strings as is, and encodes any other value as JSON.
+func stringValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+		return string(b), nil
+	}
+}
+
+func parseTimestamp(value interface{}, format string) (time.Time, error) {
+	var unit time.Duration
+	switch format {
+	case TimestampFormatUnix:
+		unit = time.Second
+	case TimestampFormatUnixMs:
+		unit = time.Millisecond
+	case TimestampFormatUnixUs:
+		unit = time.Microsecond
+	case TimestampFormatUnixNs:
+		unit = time.Nanosecond
+	}
+
+	if unit != 0 {
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			// Parse integers exactly, since nanosecond timestamps don't fit
+			// in a float64.
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil && unit == time.Nanosecond {
+				return time.Unix(0, i), nil
+			}
+			var err error
+			if n, err = strconv.ParseFloat(v, 64); err != nil {
+				return time.Time{}, fmt.Errorf("failed to parse %s timestamp %q: %w", format, v, err)
+			}
+		default:
+			return time.Time{}, fmt.Errorf("unexpected %s timestamp type %T", format, value)
+		}
+		// Whole units are converted separately to keep integer timestamps exact.
+		whole, frac := math.Modf(n)
+		var ts time.Time
+		switch unit {
+		case time.Second:
+			ts = time.Unix(int64(whole), 0)
+		case time.Millisecond:
+			ts = time.UnixMilli(int64(whole))
+		case time.Microsecond:
+			ts = time.UnixMicro(int64(whole))
+		case time.Nanosecond:
+			ts = time.Unix(0, int64(whole))
+		}
+		return ts.Add(time.Duration(frac * float64(unit))), nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected timestamp type %T for format %q", value, format)
+	}
+	layout := format
+	if format == TimestampFormatRFC3339 {
+		layout = time.RFC3339Nano
+	}
+	ts, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse timestamp %q: %w", s, err)
+	}
+	return ts, nil
+}
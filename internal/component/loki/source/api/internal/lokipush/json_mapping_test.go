@@ -0,0 +1,112 @@
+package lokipush
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONMapping(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cfg      JSONMappingConfig
+		payload  string
+		expected []jsonEntry
+		err      string
+	}{
+		{
+			name:    "whole payload",
+			payload: `{"event":"deploy","ok":true}`,
+			expected: []jsonEntry{
+				{line: `{"event":"deploy","ok":true}`, timestamp: now, labels: map[string]string{}},
+			},
+		},
+		{
+			name:    "array payload",
+			payload: `[{"msg":"a"},{"msg":"b"}]`,
+			cfg:     JSONMappingConfig{Message: "msg"},
+			expected: []jsonEntry{
+				{line: "a", timestamp: now, labels: map[string]string{}},
+				{line: "b", timestamp: now, labels: map[string]string{}},
+			},
+		},
+		{
+			name: "entries, timestamp and labels",
+			cfg: JSONMappingConfig{
+				Entries:   "records",
+				Message:   "detail.text",
+				Timestamp: "time",
+				Labels:    map[string]string{"service": "source.name", "severity": "level", "missing": "nope"},
+			},
+			payload: `{"records":[{"detail":{"text":"disk full"},"time":"2024-05-06T07:08:09.5Z","source":{"name":"storage"},"level":3}]}`,
+			expected: []jsonEntry{
+				{
+					line:      "disk full",
+					timestamp: time.Date(2024, 5, 6, 7, 8, 9, 500000000, time.UTC),
+					labels:    map[string]string{"service": "storage", "severity": "3"},
+				},
+			},
+		},
+		{
+			name:    "unix milliseconds timestamp",
+			cfg:     JSONMappingConfig{Message: "msg", Timestamp: "ts", TimestampFormat: TimestampFormatUnixMs},
+			payload: `{"msg":"a","ts":1700000000123}`,
+			expected: []jsonEntry{
+				{line: "a", timestamp: time.UnixMilli(1700000000123), labels: map[string]string{}},
+			},
+		},
+		{
+			name:    "Go layout timestamp",
+			cfg:     JSONMappingConfig{Message: "msg", Timestamp: "ts", TimestampFormat: "2006-01-02 15:04:05"},
+			payload: `{"msg":"a","ts":"2024-05-06 07:08:09"}`,
+			expected: []jsonEntry{
+				{line: "a", timestamp: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), labels: map[string]string{}},
+			},
+		},
+		{
+			name:    "payloads with an entry without a message are rejected",
+			cfg:     JSONMappingConfig{Message: "msg"},
+			payload: `[{"msg":"a"},{"other":"b"}]`,
+			err:     "entry 1: message not found in entry",
+		},
+		{
+			name:    "entries must be an array",
+			cfg:     JSONMappingConfig{Entries: "records"},
+			payload: `{"records":{"msg":"a"}}`,
+			err:     "entries expression must select an array",
+		},
+		{
+			name:    "invalid JSON",
+			payload: `{"msg":`,
+			err:     "failed to parse JSON payload",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewJSONMapping(tc.cfg)
+			require.NoError(t, err)
+
+			entries, err := m.extract([]byte(tc.payload), now)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, entries, len(tc.expected))
+			for i := range tc.expected {
+				require.Equal(t, tc.expected[i].line, entries[i].line)
+				require.True(t, tc.expected[i].timestamp.Equal(entries[i].timestamp), "expected %s, got %s", tc.expected[i].timestamp, entries[i].timestamp)
+				require.Equal(t, tc.expected[i].labels, entries[i].labels)
+			}
+		})
+	}
+}
+
+func TestNewJSONMapping_InvalidExpression(t *testing.T) {
+	_, err := NewJSONMapping(JSONMappingConfig{Labels: map[string]string{"service": "source.[name"}})
+	require.ErrorContains(t, err, `invalid label "service" expression`)
+}
@@ -2,6 +2,7 @@ package lokipush
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"net/http"
 	"sort"
@@ -39,6 +40,7 @@ type PushAPIServer struct {
 	labels        model.LabelSet
 	relabelRules  []*relabel.Config
 	keepTimestamp bool
+	jsonMapping   *JSONMapping
 }
 
 func NewPushAPIServer(logger log.Logger,
@@ -95,9 +97,19 @@ func (s *PushAPIServer) Run() error {
 				}),
 			),
 		)
+		router.Path("/api/v1/json").Methods("POST").Handler(
+			tenantHeaderExtractor(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					r.URL.Path = "/loki/api/v1/json"
+					r.RequestURI = "/loki/api/v1/json"
+					s.handleJSON(w, r)
+				}),
+			),
+		)
 		router.Path("/ready").Methods("GET").Handler(http.HandlerFunc(s.ready))
 		router.Path("/loki/api/v1/push").Methods("POST").Handler(tenantHeaderExtractor(http.HandlerFunc(s.handleLoki)))
 		router.Path("/loki/api/v1/raw").Methods("POST").Handler(tenantHeaderExtractor(http.HandlerFunc(s.handlePlaintext)))
+		router.Path("/loki/api/v1/json").Methods("POST").Handler(tenantHeaderExtractor(http.HandlerFunc(s.handleJSON)))
 	})
 	return err
 }
@@ -135,6 +147,23 @@ func (s *PushAPIServer) getKeepTimestamp() bool {
 	return s.keepTimestamp
 }
 
+// SetJSONMapping sets how JSON payloads are mapped to entries. A nil mapping
+// uses the whole payload, or each element of an array payload, as the line.
+func (s *PushAPIServer) SetJSONMapping(mapping *JSONMapping) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	s.jsonMapping = mapping
+}
+
+func (s *PushAPIServer) getJSONMapping() *JSONMapping {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	if s.jsonMapping == nil {
+		return &JSONMapping{maxBodySize: DefaultMaxJSONBodySize}
+	}
+	return s.jsonMapping
+}
+
 func (s *PushAPIServer) SetRelabelRules(rules frelabel.Rules) {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
@@ -194,7 +223,7 @@ func (s *PushAPIServer) handleLoki(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Apply relabeling
-		processed, keep := relabel.Process(lb.Labels(), relabelRules...)
+		processed, keep := frelabel.Process(lb.Labels(), relabelRules...)
 		if !keep || len(processed) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -277,6 +306,81 @@ func (s *PushAPIServer) handlePlaintext(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleJSON ingests JSON payloads which aren't in the Loki push format, such
+// as the bodies of webhooks, by mapping them to entries with the JSON mapping.
+func (s *PushAPIServer) handleJSON(w http.ResponseWriter, r *http.Request) {
+	// Take snapshot of current configs and apply consistently for the entire request.
+	tenantID, _ := tenant.TenantID(r.Context())
+	addLabels := s.getLabels()
+	relabelRules := s.getRelabelRules()
+	mapping := s.getJSONMapping()
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, mapping.maxBodySize))
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to read incoming JSON request", "err", err.Error())
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The whole payload is mapped before any entry is forwarded, so that a
+	// client retrying a rejected payload doesn't duplicate entries.
+	mapped, err := mapping.extract(body, time.Now())
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to map incoming JSON request", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries := make([]loki.Entry, 0, len(mapped))
+	for _, entry := range mapped {
+		lb := labels.NewBuilder(labels.EmptyLabels())
+		for k, v := range entry.labels {
+			lb.Set(k, v)
+		}
+		// Add configured labels
+		for k, v := range addLabels {
+			lb.Set(string(k), string(v))
+		}
+
+		// Apply relabeling
+		processed, keep := frelabel.Process(lb.Labels(), relabelRules...)
+		if !keep {
+			continue
+		}
+
+		filtered := model.LabelSet{}
+		for i := range processed {
+			if strings.HasPrefix(processed[i].Name, "__") {
+				continue
+			}
+			filtered[model.LabelName(processed[i].Name)] = model.LabelValue(processed[i].Value)
+		}
+		if tenantID != "" {
+			filtered[model.LabelName(client.ReservedLabelTenantID)] = model.LabelValue(tenantID)
+		}
+
+		entries = append(entries, loki.Entry{
+			Labels: filtered,
+			Entry: logproto.Entry{
+				Timestamp: entry.timestamp,
+				Line:      entry.line,
+			},
+		})
+	}
+
+	for _, entry := range entries {
+		s.handler.Chan() <- entry
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // NOTE: This code is copied from Promtail (https://github.com/grafana/loki/commit/47e2c5884f443667e64764f3fc3948f8f11abbb8) with changes kept to the minimum.
 // Only the HTTP handler functions are copied to allow for Alloy-specific server configuration and lifecycle management.
 func (s *PushAPIServer) ready(w http.ResponseWriter, r *http.Request) {
@@ -10,8 +10,10 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -359,6 +361,61 @@ func TestPlaintextPushTarget(t *testing.T) {
 	pt.Shutdown()
 }
 
+func TestJSONPushTarget_MaxBodySize(t *testing.T) {
+	eh := fake.NewClient(func() {})
+	defer eh.Stop()
+
+	mapping, err := NewJSONMapping(JSONMappingConfig{MaxBodySize: 32})
+	require.NoError(t, err)
+	pt := &PushAPIServer{logger: log.NewNopLogger(), handler: eh}
+	pt.SetJSONMapping(mapping)
+
+	tests := map[string]struct {
+		body string
+		code int
+	}{
+		"within limit":   {`{"msg": "hello"}`, http.StatusNoContent},
+		"exceeds limit":  {`{"msg": "hello, this is a long log line"}`, http.StatusRequestEntityTooLarge},
+		"invalid json":   {`{"msg": `, http.StatusBadRequest},
+		"exactly limit":  {`{"msg": "012345678901234567890"}`, http.StatusNoContent},
+		"one byte above": {`{"msg": "0123456789012345678901"}`, http.StatusRequestEntityTooLarge},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/json", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			pt.handleJSON(rec, req)
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+	require.Eventually(t, func() bool { return len(eh.Received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJSONPushTarget_RejectedPayload(t *testing.T) {
+	eh := fake.NewClient(func() {})
+	defer eh.Stop()
+
+	mapping, err := NewJSONMapping(JSONMappingConfig{Message: "msg"})
+	require.NoError(t, err)
+	pt := &PushAPIServer{logger: log.NewNopLogger(), handler: eh}
+	pt.SetJSONMapping(mapping)
+
+	// No entry of a rejected payload is forwarded, so that retrying it
+	// doesn't duplicate entries.
+	req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/json", strings.NewReader(`[{"msg": "a"}, {"other": "b"}]`))
+	rec := httptest.NewRecorder()
+	pt.handleJSON(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/loki/api/v1/json", strings.NewReader(`[{"msg": "c"}]`))
+	rec = httptest.NewRecorder()
+	pt.handleJSON(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	require.Eventually(t, func() bool { return len(eh.Received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "c", eh.Received()[0].Line)
+}
+
 func TestPlaintextPushTargetWithXScopeOrgIDHeader(t *testing.T) {
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
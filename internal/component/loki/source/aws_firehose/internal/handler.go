@@ -23,6 +23,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	lokiClient "github.com/grafana/alloy/internal/component/common/loki/client"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -183,7 +184,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 func (h *Handler) postProcessLabels(lbs labels.Labels) model.LabelSet {
 	// apply relabel rules if any
 	if len(h.relabelRules) > 0 {
-		lbs, _ = relabel.Process(lbs, h.relabelRules...)
+		lbs, _ = alloy_relabel.Process(lbs, h.relabelRules...)
 	}
 
 	entryLabels := make(model.LabelSet)
@@ -232,7 +233,7 @@ func (h *Handler) decodeRecord(rec string) ([]byte, RecordOrigin, error) {
 	// code from creating the reader.
 	//
 	// https://github.com/golang/go/blob/master/src/compress/gzip/gunzip.go#L185
-	if !(decodedRec[0] == gzipID1 && decodedRec[1] == gzipID2 && // the first two represent the 1f8b magic bytes
+	if len(decodedRec) < 3 || !(decodedRec[0] == gzipID1 && decodedRec[1] == gzipID2 && // the first two represent the 1f8b magic bytes
 		decodedRec[2] == gzipDeflate) { // the third byte represents the gzip compression method DEFLATE
 		// no gzip, return decoded data
 		return decodedRec, OriginDirectPUT, nil
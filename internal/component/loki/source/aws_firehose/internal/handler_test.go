@@ -270,6 +270,15 @@ func TestHandler(t *testing.T) {
 				require.Equal(t, 400, res.Code)
 			},
 		},
+		"records shorter than the gzip header": {
+			Body: `{"requestId": "a1af4300-6c09-4916-ba8f-12f336176246", "timestamp": 1684422829730, "records": [{"data": "aGk="}, {"data": ""}]}`,
+			Assert: func(t *testing.T, res *httptest.ResponseRecorder, entries []loki.Entry) {
+				require.Equal(t, 200, res.Code)
+				require.Len(t, entries, 2)
+				require.Equal(t, "hi", entries[0].Line)
+				require.Equal(t, "", entries[1].Line)
+			},
+		},
 		"cloudwatch logs control message, and invalid gzipped data": {
 			Body: readTestData(t, "testdata/cw_logs_control_and_bad_records.json"),
 			Assert: func(t *testing.T, res *httptest.ResponseRecorder, entries []loki.Entry) {
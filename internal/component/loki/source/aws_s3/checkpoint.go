@@ -0,0 +1,92 @@
+package aws_s3
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkpointRetention is how long the checkpoint of an object is kept. SQS
+// doesn't retain messages for longer than 14 days, so an object which wasn't
+// read since won't be read again.
+const checkpointRetention = 14 * 24 * time.Hour
+
+// checkpoint is the number of records of an object which were forwarded.
+type checkpoint struct {
+	Records int64     `json:"records"`
+	Updated time.Time `json:"updated"`
+}
+
+// checkpoints tracks how far objects were read, so that a redelivered
+// notification doesn't forward the records of an object twice.
+type checkpoints struct {
+	path string
+
+	mut     sync.Mutex
+	objects map[string]checkpoint
+}
+
+func newCheckpoints(path string) (*checkpoints, error) {
+	c := &checkpoints{
+		path:    path,
+		objects: make(map[string]checkpoint),
+	}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &c.objects); err != nil {
+		return nil, err
+	}
+
+	for key, cp := range c.objects {
+		if time.Since(cp.Updated) > checkpointRetention {
+			delete(c.objects, key)
+		}
+	}
+	return c, nil
+}
+
+// Get returns the number of records of the object which were forwarded.
+func (c *checkpoints) Get(key string) int64 {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.objects[key].Records
+}
+
+// Set records the number of records of the object which were forwarded.
+func (c *checkpoints) Set(key string, records int64) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.objects[key] = checkpoint{Records: records, Updated: time.Now()}
+}
+
+// Delete removes the checkpoint of an object.
+func (c *checkpoints) Delete(key string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	delete(c.objects, key)
+}
+
+// Save writes the checkpoints to disk.
+func (c *checkpoints) Save() error {
+	c.mut.Lock()
+	buf, err := json.Marshal(c.objects)
+	c.mut.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so the checkpoints are never partially
+	// written.
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
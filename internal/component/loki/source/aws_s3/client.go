@@ -0,0 +1,72 @@
+package aws_s3
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// sqsAPI is the subset of the SQS client used by the target.
+type sqsAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// s3API is the subset of the S3 client used by the target.
+type s3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// newClients creates the SQS and S3 clients from the client block.
+func newClients(c Client) (sqsAPI, s3API, error) {
+	configOptions := make([]func(*aws_config.LoadOptions) error, 0)
+	// Override the endpoint.
+	if c.Endpoint != "" {
+		endFunc := aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{URL: c.Endpoint, SigningRegion: c.SigningRegion}, nil
+		})
+		configOptions = append(configOptions, aws_config.WithEndpointResolverWithOptions(endFunc))
+	}
+
+	if c.DisableSSL {
+		configOptions = append(configOptions, aws_config.WithHTTPClient(
+			&http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						InsecureSkipVerify: c.DisableSSL,
+					},
+				},
+			},
+		))
+	}
+
+	// Override the credentials, else the default ones are used.
+	if c.AccessKey != "" {
+		credFunc := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     c.AccessKey,
+				SecretAccessKey: string(c.Secret),
+			}, nil
+		})
+		configOptions = append(configOptions, aws_config.WithCredentialsProvider(credFunc))
+	}
+
+	cfg, err := aws_config.LoadDefaultConfig(context.TODO(), configOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Region != "" {
+		cfg.Region = c.Region
+	}
+
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = c.UsePathStyle
+	})
+	return sqs.NewFromConfig(cfg), s3Client, nil
+}
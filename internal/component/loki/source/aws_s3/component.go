@@ -0,0 +1,235 @@
+package aws_s3
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.source.awss3",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Compression formats of the objects.
+const (
+	CompressionAuto = "auto"
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Formats of the objects.
+const (
+	FormatLines     = "lines"
+	FormatJSONArray = "json_array"
+)
+
+// Arguments holds values which are used to configure the loki.source.awss3
+// component.
+type Arguments struct {
+	QueueURL          string            `alloy:"queue_url,attr"`
+	VisibilityTimeout time.Duration     `alloy:"visibility_timeout,attr,optional"`
+	WaitTime          time.Duration     `alloy:"wait_time,attr,optional"`
+	MaxMessages       int               `alloy:"max_messages,attr,optional"`
+	Compression       string            `alloy:"compression,attr,optional"`
+	Format            string            `alloy:"format,attr,optional"`
+	JSONArrayField    string            `alloy:"json_array_field,attr,optional"`
+	KeyRegex          string            `alloy:"key_regex,attr,optional"`
+	Client            Client            `alloy:"client,block,optional"`
+	Labels            map[string]string `alloy:"labels,attr,optional"`
+
+	ForwardTo    []loki.LogsReceiver `alloy:"forward_to,attr"`
+	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+}
+
+// Client holds the AWS configuration used for both SQS and S3.
+type Client struct {
+	AccessKey     string            `alloy:"key,attr,optional"`
+	Secret        alloytypes.Secret `alloy:"secret,attr,optional"`
+	Endpoint      string            `alloy:"endpoint,attr,optional"`
+	DisableSSL    bool              `alloy:"disable_ssl,attr,optional"`
+	UsePathStyle  bool              `alloy:"use_path_style,attr,optional"`
+	Region        string            `alloy:"region,attr,optional"`
+	SigningRegion string            `alloy:"signing_region,attr,optional"`
+}
+
+// DefaultArguments provides the default arguments for a awss3 component.
+var DefaultArguments = Arguments{
+	VisibilityTimeout: 5 * time.Minute,
+	WaitTime:          20 * time.Second,
+	MaxMessages:       10,
+	Compression:       CompressionAuto,
+	Format:            FormatLines,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if a.QueueURL == "" {
+		return fmt.Errorf("queue_url must not be empty")
+	}
+	// SQS accepts visibility timeouts of up to 12 hours, waits of up to 20
+	// seconds and batches of up to 10 messages.
+	if a.VisibilityTimeout < time.Second || a.VisibilityTimeout > 12*time.Hour {
+		return fmt.Errorf("visibility_timeout must be between 1s and 12h, got %s", a.VisibilityTimeout)
+	}
+	if a.WaitTime < 0 || a.WaitTime > 20*time.Second {
+		return fmt.Errorf("wait_time must be between 0s and 20s, got %s", a.WaitTime)
+	}
+	if a.MaxMessages < 1 || a.MaxMessages > 10 {
+		return fmt.Errorf("max_messages must be between 1 and 10, got %d", a.MaxMessages)
+	}
+	switch a.Compression {
+	case CompressionAuto, CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("unsupported compression %q, must be one of %q, %q, %q or %q", a.Compression, CompressionAuto, CompressionNone, CompressionGzip, CompressionZstd)
+	}
+	switch a.Format {
+	case FormatLines:
+		if a.JSONArrayField != "" {
+			return fmt.Errorf("json_array_field can only be set when format is %q", FormatJSONArray)
+		}
+	case FormatJSONArray:
+	default:
+		return fmt.Errorf("unsupported format %q, must be %q or %q", a.Format, FormatLines, FormatJSONArray)
+	}
+	if a.KeyRegex != "" {
+		if _, err := regexp.Compile(a.KeyRegex); err != nil {
+			return fmt.Errorf("invalid key_regex: %w", err)
+		}
+	}
+	if (a.Client.AccessKey == "") != (a.Client.Secret == "") {
+		return fmt.Errorf("if key or secret are specified then the other must also be specified")
+	}
+	return nil
+}
+
+// Component implements the loki.source.awss3 component.
+type Component struct {
+	opts        component.Options
+	metrics     *metrics
+	checkpoints *checkpoints
+
+	mut    sync.RWMutex
+	fanout []loki.LogsReceiver
+	target *target
+
+	handler loki.LogsReceiver
+}
+
+// New creates a new loki.source.awss3 component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	err := os.MkdirAll(o.DataPath, 0750)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := newCheckpoints(filepath.Join(o.DataPath, "checkpoints.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:        o,
+		metrics:     newMetrics(o.Registerer),
+		checkpoints: checkpoints,
+		handler:     loki.NewLogsReceiver(),
+		fanout:      args.ForwardTo,
+	}
+
+	// Call to Update() to start readers and set receivers once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		level.Info(c.opts.Logger).Log("msg", "loki.source.awss3 component shutting down, stopping target")
+		if c.target != nil {
+			c.target.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.handler.Chan():
+			c.mut.RLock()
+			for _, receiver := range c.fanout {
+				receiver.Chan() <- entry
+			}
+			c.mut.RUnlock()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+	c.fanout = newArgs.ForwardTo
+
+	if c.target != nil {
+		c.target.Stop()
+		c.target = nil
+	}
+
+	sqsClient, s3Client, err := newClients(newArgs.Client)
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to create aws clients with provided config", "err", err)
+		return err
+	}
+
+	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
+	c.target = newTarget(c.metrics, c.opts.Logger, entryHandler, c.checkpoints, sqsClient, s3Client, newArgs)
+
+	return nil
+}
+
+// DebugInfo returns information about the status of the target.
+func (c *Component) DebugInfo() interface{} {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	var info targetDebugInfo
+	if c.target != nil {
+		info.Details = c.target.Details()
+	}
+	return info
+}
+
+type targetDebugInfo struct {
+	Details map[string]string `alloy:"target_info,attr"`
+}
@@ -0,0 +1,122 @@
+package aws_s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	queue_url  = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	labels     = {component = "loki.source.awss3"}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, args.VisibilityTimeout)
+	require.Equal(t, 20*time.Second, args.WaitTime)
+	require.Equal(t, 10, args.MaxMessages)
+	require.Equal(t, CompressionAuto, args.Compression)
+	require.Equal(t, FormatLines, args.Format)
+}
+
+func TestJSONArrayAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	queue_url          = "https://sqs.us-east-1.amazonaws.com/123456789012/cloudtrail"
+	visibility_timeout = "1m"
+	format             = "json_array"
+	json_array_field   = "Records"
+	key_regex          = "AWSLogs/(?P<account_id>[0-9]+)/CloudTrail/(?P<region>[^/]+)/"
+	client {
+		key    = "key"
+		secret = "secret"
+		region = "us-east-1"
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, "Records", args.JSONArrayField)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "visibility timeout too short",
+			config: `
+	queue_url          = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	visibility_timeout = "500ms"
+	forward_to         = []`,
+			err: "visibility_timeout must be between 1s and 12h",
+		},
+		{
+			name: "wait time too long",
+			config: `
+	queue_url  = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	wait_time  = "1m"
+	forward_to = []`,
+			err: "wait_time must be between 0s and 20s",
+		},
+		{
+			name: "too many messages",
+			config: `
+	queue_url    = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	max_messages = 20
+	forward_to   = []`,
+			err: "max_messages must be between 1 and 10",
+		},
+		{
+			name: "invalid compression",
+			config: `
+	queue_url   = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	compression = "bzip2"
+	forward_to  = []`,
+			err: `unsupported compression "bzip2"`,
+		},
+		{
+			name: "json array field with lines",
+			config: `
+	queue_url        = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	json_array_field = "Records"
+	forward_to       = []`,
+			err: `json_array_field can only be set when format is "json_array"`,
+		},
+		{
+			name: "invalid key regex",
+			config: `
+	queue_url  = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	key_regex  = "(unclosed"
+	forward_to = []`,
+			err: "invalid key_regex",
+		},
+		{
+			name: "key without secret",
+			config: `
+	queue_url  = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	client {
+		key = "key"
+	}
+	forward_to = []`,
+			err: "if key or secret are specified then the other must also be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package aws_s3
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	entries  prometheus.Counter
+	objects  prometheus.Counter
+	messages prometheus.Counter
+	errors   *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.entries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_awss3_entries_total",
+		Help: "Total number of log entries read from S3 objects.",
+	})
+	m.objects = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_awss3_objects_total",
+		Help: "Total number of S3 objects read.",
+	})
+	m.messages = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_awss3_messages_total",
+		Help: "Total number of SQS messages processed and deleted.",
+	})
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_source_awss3_errors_total",
+		Help: "Total number of errors while reading S3 objects from SQS notifications.",
+	}, []string{"reason"})
+
+	reg.MustRegister(m.entries, m.objects, m.messages, m.errors)
+	return &m
+}
@@ -0,0 +1,102 @@
+package aws_s3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Object identifies an object referenced by an S3 event notification.
+type s3Object struct {
+	Bucket    string
+	Key       string
+	ETag      string
+	VersionID string
+	Region    string
+	EventName string
+	EventTime time.Time
+}
+
+// checkpointKey identifies the object in the checkpoints. The ETag is part of
+// it so that an overwritten object is read from the start again.
+func (o s3Object) checkpointKey() string {
+	return fmt.Sprintf("%s/%s@%s", o.Bucket, o.Key, o.ETag)
+}
+
+// s3Event is the body of an S3 event notification.
+type s3Event struct {
+	Records []s3EventRecord `json:"Records"`
+
+	// Event is set to s3:TestEvent for the message sent when notifications
+	// are configured on a bucket.
+	Event string `json:"Event"`
+}
+
+type s3EventRecord struct {
+	EventSource string    `json:"eventSource"`
+	AWSRegion   string    `json:"awsRegion"`
+	EventTime   time.Time `json:"eventTime"`
+	EventName   string    `json:"eventName"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key       string `json:"key"`
+			ETag      string `json:"eTag"`
+			VersionID string `json:"versionId"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// snsNotification is the body of an SQS message delivered through an SNS
+// topic.
+type snsNotification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// parseNotification returns the created objects referenced by the body of an
+// SQS message. S3 event notifications can be sent directly to the queue or
+// through an SNS topic.
+func parseNotification(body string) ([]s3Object, error) {
+	var sns snsNotification
+	if err := json.Unmarshal([]byte(body), &sns); err != nil {
+		return nil, fmt.Errorf("failed to decode notification: %w", err)
+	}
+	if sns.Type == "Notification" {
+		body = sns.Message
+	}
+
+	var event s3Event
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		return nil, fmt.Errorf("failed to decode s3 event: %w", err)
+	}
+	if event.Event == "s3:TestEvent" {
+		return nil, nil
+	}
+
+	objects := make([]s3Object, 0, len(event.Records))
+	for _, r := range event.Records {
+		if r.EventSource != "aws:s3" || !strings.HasPrefix(r.EventName, "ObjectCreated:") {
+			continue
+		}
+		// Object keys are URL-encoded in event notifications.
+		key, err := url.QueryUnescape(r.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object key %q: %w", r.S3.Object.Key, err)
+		}
+		objects = append(objects, s3Object{
+			Bucket:    r.S3.Bucket.Name,
+			Key:       key,
+			ETag:      r.S3.Object.ETag,
+			VersionID: r.S3.Object.VersionID,
+			Region:    r.AWSRegion,
+			EventName: r.EventName,
+			EventTime: r.EventTime,
+		})
+	}
+	return objects, nil
+}
@@ -0,0 +1,74 @@
+package aws_s3
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const s3EventBody = `{
+  "Records": [
+    {
+      "eventVersion": "2.1",
+      "eventSource": "aws:s3",
+      "awsRegion": "us-east-1",
+      "eventTime": "2024-03-01T12:00:00.000Z",
+      "eventName": "ObjectCreated:Put",
+      "s3": {
+        "bucket": {"name": "logs"},
+        "object": {"key": "AWSLogs/123456789012/elasticloadbalancing/my+lb%3D1.log.gz", "size": 1024, "eTag": "abc", "versionId": "v1"}
+      }
+    },
+    {
+      "eventVersion": "2.1",
+      "eventSource": "aws:s3",
+      "awsRegion": "us-east-1",
+      "eventTime": "2024-03-01T12:00:01.000Z",
+      "eventName": "ObjectRemoved:Delete",
+      "s3": {
+        "bucket": {"name": "logs"},
+        "object": {"key": "removed.log"}
+      }
+    }
+  ]
+}`
+
+func TestParseNotification(t *testing.T) {
+	expect := []s3Object{{
+		Bucket:    "logs",
+		Key:       "AWSLogs/123456789012/elasticloadbalancing/my lb=1.log.gz",
+		ETag:      "abc",
+		VersionID: "v1",
+		Region:    "us-east-1",
+		EventName: "ObjectCreated:Put",
+		EventTime: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	objects, err := parseNotification(s3EventBody)
+	require.NoError(t, err)
+	require.Equal(t, expect, objects)
+
+	// S3 event notifications delivered through an SNS topic.
+	sns, err := json.Marshal(map[string]string{
+		"Type":     "Notification",
+		"TopicArn": "arn:aws:sns:us-east-1:123456789012:logs",
+		"Message":  s3EventBody,
+	})
+	require.NoError(t, err)
+	objects, err = parseNotification(string(sns))
+	require.NoError(t, err)
+	require.Equal(t, expect, objects)
+}
+
+func TestParseNotification_TestEvent(t *testing.T) {
+	objects, err := parseNotification(`{"Service":"Amazon S3","Event":"s3:TestEvent","Time":"2024-03-01T12:00:00.000Z","Bucket":"logs"}`)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestParseNotification_Invalid(t *testing.T) {
+	_, err := parseNotification("not json")
+	require.ErrorContains(t, err, "failed to decode notification")
+}
@@ -0,0 +1,141 @@
+package aws_s3
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// errStopped is returned when reading an object is interrupted because the
+// target is stopping.
+var errStopped = errors.New("target stopped")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress wraps r to decompress the object content. With
+// CompressionAuto, the compression is detected from the first bytes.
+func decompress(r io.Reader, compression string) (io.ReadCloser, error) {
+	if compression == CompressionAuto {
+		br := bufio.NewReader(r)
+		// A short object can't be compressed, Peek returns what's available.
+		magic, _ := br.Peek(len(zstdMagic))
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			compression = CompressionGzip
+		case bytes.HasPrefix(magic, zstdMagic):
+			compression = CompressionZstd
+		default:
+			compression = CompressionNone
+		}
+		r = br
+	}
+
+	switch compression {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// readRecords splits the decompressed object content in records and calls fn
+// for each of them. It returns errStopped when fn returns false.
+func readRecords(r io.Reader, format, jsonArrayField string, fn func(string) bool) error {
+	if format == FormatJSONArray {
+		return readJSONArray(r, jsonArrayField, fn)
+	}
+	return readLines(r, fn)
+}
+
+// readLines calls fn for each non-empty line.
+func readLines(r io.Reader, fn func(string) bool) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line = strings.TrimRight(line, "\r\n"); line != "" && !fn(line) {
+			return errStopped
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// readJSONArray calls fn for each element of a JSON array. The array is
+// either the whole document, or the value of field in a JSON object, like the
+// Records field of CloudTrail logs. Elements are compacted to a single line.
+func readJSONArray(r io.Reader, field string, fn func(string) bool) error {
+	dec := json.NewDecoder(r)
+	if field == "" {
+		return readArrayElements(dec, fn)
+	}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := tok.(string); key == field {
+			return readArrayElements(dec, fn)
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("field %q not found", field)
+}
+
+func readArrayElements(dec *json.Decoder, fn func(string) bool) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for dec.More() {
+		var elem json.RawMessage
+		if err := dec.Decode(&elem); err != nil {
+			return err
+		}
+		buf.Reset()
+		if err := json.Compact(&buf, elem); err != nil {
+			return err
+		}
+		if !fn(buf.String()) {
+			return errStopped
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %q, got %v", delim, tok)
+	}
+	return nil
+}
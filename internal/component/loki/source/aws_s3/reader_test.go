@@ -0,0 +1,114 @@
+package aws_s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecompress(t *testing.T) {
+	const content = "line 1\nline 2\n"
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zst bytes.Buffer
+	zw, err := zstd.NewWriter(&zst)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := []struct {
+		name        string
+		compression string
+		data        []byte
+	}{
+		{"auto gzip", CompressionAuto, gz.Bytes()},
+		{"auto zstd", CompressionAuto, zst.Bytes()},
+		{"auto none", CompressionAuto, []byte(content)},
+		{"gzip", CompressionGzip, gz.Bytes()},
+		{"zstd", CompressionZstd, zst.Bytes()},
+		{"none", CompressionNone, []byte(content)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := decompress(bytes.NewReader(tt.data), tt.compression)
+			require.NoError(t, err)
+			defer r.Close()
+
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, content, string(out))
+		})
+	}
+
+	// Objects shorter than the magic numbers are read as is.
+	r, err := decompress(strings.NewReader("a"), CompressionAuto)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(out))
+}
+
+func TestReadRecords(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		field  string
+		input  string
+		expect []string
+	}{
+		{
+			name:   "lines",
+			format: FormatLines,
+			input:  "line 1\r\n\nline 2\nline 3",
+			expect: []string{"line 1", "line 2", "line 3"},
+		},
+		{
+			name:   "json array",
+			format: FormatJSONArray,
+			input:  `[{"a": 1}, {"b": [1, 2]}]`,
+			expect: []string{`{"a":1}`, `{"b":[1,2]}`},
+		},
+		{
+			name:   "json array field",
+			format: FormatJSONArray,
+			field:  "Records",
+			input:  `{"Other": {"Records": []}, "Records": [{"eventName": "ConsoleLogin"}, {"eventName": "GetObject"}]}`,
+			expect: []string{`{"eventName":"ConsoleLogin"}`, `{"eventName":"GetObject"}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []string
+			err := readRecords(strings.NewReader(tt.input), tt.format, tt.field, func(record string) bool {
+				records = append(records, record)
+				return true
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, records)
+		})
+	}
+}
+
+func TestReadRecords_Errors(t *testing.T) {
+	noop := func(string) bool { return true }
+
+	err := readRecords(strings.NewReader(`{"Records": []}`), FormatJSONArray, "", noop)
+	require.ErrorContains(t, err, "expected")
+
+	err = readRecords(strings.NewReader(`{"Other": []}`), FormatJSONArray, "Records", noop)
+	require.ErrorContains(t, err, `field "Records" not found`)
+
+	err = readRecords(strings.NewReader("line 1\nline 2\n"), FormatLines, "", func(string) bool { return false })
+	require.ErrorIs(t, err, errStopped)
+}
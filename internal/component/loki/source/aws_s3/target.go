@@ -0,0 +1,372 @@
+package aws_s3
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Internal labels set on every log entry, which can be used in relabel rules.
+const (
+	labelBucket    = "__aws_s3_bucket"
+	labelKey       = "__aws_s3_key"
+	labelRegion    = "__aws_s3_region"
+	labelEventName = "__aws_s3_event_name"
+	labelKeyGroup  = "__aws_s3_key_"
+)
+
+// checkpointInterval is the number of records after which the checkpoint of
+// an object is updated while it's read.
+const checkpointInterval = 1000
+
+var defaultBackoff = backoff.Config{
+	MinBackoff: 1 * time.Second,
+	MaxBackoff: 10 * time.Second,
+	MaxRetries: 0, // Retry forever
+}
+
+// target receives S3 event notifications from an SQS queue, and reads the
+// created objects to convert their records to log entries.
+type target struct {
+	metrics       *metrics
+	logger        log.Logger
+	handler       loki.EntryHandler
+	checkpoints   *checkpoints
+	sqs           sqsAPI
+	s3            s3API
+	args          Arguments
+	labels        model.LabelSet
+	relabelConfig []*relabel.Config
+	keyRegex      *regexp.Regexp
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newTarget(metrics *metrics, logger log.Logger, handler loki.EntryHandler, checkpoints *checkpoints, sqsClient sqsAPI, s3Client s3API, args Arguments) *target {
+	lbls := make(model.LabelSet, len(args.Labels))
+	for k, v := range args.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &target{
+		metrics:       metrics,
+		logger:        logger,
+		handler:       handler,
+		checkpoints:   checkpoints,
+		sqs:           sqsClient,
+		s3:            s3Client,
+		args:          args,
+		labels:        lbls,
+		relabelConfig: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	if args.KeyRegex != "" {
+		// The regular expression is checked when validating the arguments.
+		t.keyRegex = regexp.MustCompile(args.KeyRegex)
+	}
+
+	t.wg.Add(1)
+	go t.run()
+	return t
+}
+
+// run receives messages from the queue until the target is stopped.
+func (t *target) run() {
+	defer t.wg.Done()
+
+	bo := backoff.New(t.ctx, defaultBackoff)
+	for t.ctx.Err() == nil {
+		out, err := t.sqs.ReceiveMessage(t.ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(t.args.QueueURL),
+			MaxNumberOfMessages: int32(t.args.MaxMessages),
+			WaitTimeSeconds:     int32(t.args.WaitTime.Seconds()),
+			VisibilityTimeout:   int32(t.args.VisibilityTimeout.Seconds()),
+		})
+		if err != nil {
+			if t.ctx.Err() != nil {
+				return
+			}
+			level.Error(t.logger).Log("msg", "failed to receive sqs messages", "queue_url", t.args.QueueURL, "err", err)
+			t.metrics.errors.WithLabelValues("receive").Inc()
+			bo.Wait()
+			continue
+		}
+		bo.Reset()
+
+		for _, msg := range out.Messages {
+			if !t.processMessage(msg) {
+				return
+			}
+		}
+	}
+}
+
+// processMessage reads the objects referenced by a message, and deletes the
+// message once all of them were forwarded. It returns false if the target was
+// stopped while processing the message.
+func (t *target) processMessage(msg types.Message) bool {
+	objects, err := parseNotification(aws.ToString(msg.Body))
+	if err != nil {
+		// The message will never be valid, so it's deleted rather than
+		// redelivered.
+		level.Error(t.logger).Log("msg", "failed to parse sqs message, deleting it", "message_id", aws.ToString(msg.MessageId), "err", err)
+		t.metrics.errors.WithLabelValues("parse").Inc()
+		t.deleteMessage(msg, nil)
+		return true
+	}
+
+	stopExtending := t.extendVisibility(msg)
+	defer stopExtending()
+
+	for _, obj := range objects {
+		err := t.processObject(obj)
+		if errors.Is(err, errStopped) {
+			return false
+		}
+		if err != nil {
+			// The message becomes visible again once its visibility timeout
+			// expires, and the object is read again from its checkpoint.
+			level.Error(t.logger).Log("msg", "failed to read s3 object", "bucket", obj.Bucket, "key", obj.Key, "err", err)
+			t.metrics.errors.WithLabelValues("object").Inc()
+			return true
+		}
+	}
+
+	t.deleteMessage(msg, objects)
+	return true
+}
+
+// deleteMessage deletes a processed message, and then the checkpoints of its
+// objects. The checkpoints are kept if the message couldn't be deleted, so
+// that the objects aren't forwarded again when it's redelivered.
+func (t *target) deleteMessage(msg types.Message, objects []s3Object) {
+	_, err := t.sqs.DeleteMessage(t.ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(t.args.QueueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	})
+	if err != nil {
+		level.Warn(t.logger).Log("msg", "failed to delete sqs message", "message_id", aws.ToString(msg.MessageId), "err", err)
+		t.metrics.errors.WithLabelValues("delete").Inc()
+		return
+	}
+	t.metrics.messages.Inc()
+
+	if len(objects) == 0 {
+		return
+	}
+	for _, obj := range objects {
+		t.checkpoints.Delete(obj.checkpointKey())
+	}
+	t.saveCheckpoints()
+}
+
+// extendVisibility periodically extends the visibility timeout of a message
+// while it's processed, so that it isn't delivered to another consumer while
+// large objects are read. The returned function stops extending it.
+func (t *target) extendVisibility(msg types.Message) func() {
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(t.args.VisibilityTimeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := t.sqs.ChangeMessageVisibility(t.ctx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(t.args.QueueURL),
+					ReceiptHandle:     msg.ReceiptHandle,
+					VisibilityTimeout: int32(t.args.VisibilityTimeout.Seconds()),
+				})
+				if err != nil && t.ctx.Err() == nil {
+					level.Warn(t.logger).Log("msg", "failed to extend sqs message visibility", "message_id", aws.ToString(msg.MessageId), "err", err)
+					t.metrics.errors.WithLabelValues("visibility").Inc()
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// processObject reads an object and forwards its records, skipping the
+// records which were already forwarded according to its checkpoint.
+func (t *target) processObject(obj s3Object) error {
+	var keyMatches []string
+	if t.keyRegex != nil {
+		if keyMatches = t.keyRegex.FindStringSubmatch(obj.Key); keyMatches == nil {
+			level.Debug(t.logger).Log("msg", "skipping s3 object not matching key_regex", "bucket", obj.Bucket, "key", obj.Key)
+			return nil
+		}
+	}
+
+	entryLabels, keep := t.objectLabels(obj, keyMatches)
+	if !keep {
+		return nil
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(obj.Bucket),
+		Key:    aws.String(obj.Key),
+	}
+	if obj.VersionID != "" {
+		input.VersionId = aws.String(obj.VersionID)
+	}
+	out, err := t.s3.GetObject(t.ctx, input)
+	if err != nil {
+		if t.ctx.Err() != nil {
+			return errStopped
+		}
+		return err
+	}
+	defer out.Body.Close()
+
+	r, err := decompress(out.Body, t.args.Compression)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var (
+		key       = obj.checkpointKey()
+		forwarded = t.checkpoints.Get(key)
+		records   int64
+	)
+	err = readRecords(r, t.args.Format, t.args.JSONArrayField, func(record string) bool {
+		records++
+		if records <= forwarded {
+			return true
+		}
+		if !t.send(loki.Entry{
+			Labels: entryLabels.Clone(),
+			Entry: logproto.Entry{
+				Timestamp: time.Now(),
+				Line:      record,
+			},
+		}) {
+			return false
+		}
+		forwarded = records
+		if forwarded%checkpointInterval == 0 {
+			t.checkpoints.Set(key, forwarded)
+		}
+		return true
+	})
+
+	// Record how far the object was read, including when reading it was
+	// interrupted, so that it's resumed from there.
+	t.checkpoints.Set(key, forwarded)
+	t.saveCheckpoints()
+	if err != nil {
+		return err
+	}
+	t.metrics.objects.Inc()
+	return nil
+}
+
+// objectLabels returns the labels of the entries read from an object. It
+// returns false if the object was dropped by the relabel rules.
+func (t *target) objectLabels(obj s3Object, keyMatches []string) (model.LabelSet, bool) {
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	lb.Set(labelBucket, obj.Bucket)
+	lb.Set(labelKey, obj.Key)
+	lb.Set(labelRegion, obj.Region)
+	lb.Set(labelEventName, obj.EventName)
+	if t.keyRegex != nil {
+		for i, name := range t.keyRegex.SubexpNames() {
+			if i == 0 || i >= len(keyMatches) {
+				continue
+			}
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			lb.Set(labelKeyGroup+name, keyMatches[i])
+		}
+	}
+
+	processed, keep := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
+	if !keep {
+		return nil, false
+	}
+
+	// Start with the set of labels fixed in the configuration
+	entryLabels := t.labels.Clone()
+	processed.Range(func(lbl labels.Label) {
+		if strings.HasPrefix(lbl.Name, "__") {
+			return
+		}
+		entryLabels[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+	})
+	return entryLabels, true
+}
+
+// send forwards an entry, and returns false if the target was stopped
+// before the entry could be sent.
+func (t *target) send(entry loki.Entry) bool {
+	select {
+	case t.handler.Chan() <- entry:
+		t.metrics.entries.Inc()
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+func (t *target) saveCheckpoints() {
+	if err := t.checkpoints.Save(); err != nil {
+		level.Warn(t.logger).Log("msg", "failed to save checkpoints", "err", err)
+		t.metrics.errors.WithLabelValues("checkpoint").Inc()
+	}
+}
+
+// Details returns some debug information about the target.
+func (t *target) Details() map[string]string {
+	return map[string]string{
+		"queue_url":   t.args.QueueURL,
+		"compression": t.args.Compression,
+		"format":      t.args.Format,
+		"labels":      t.labels.String(),
+	}
+}
+
+// Stop shuts the target down.
+func (t *target) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	t.handler.Stop()
+}
@@ -0,0 +1,207 @@
+package aws_s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+)
+
+// fakeSQS delivers the queued messages once, and records the deleted ones.
+type fakeSQS struct {
+	mut      sync.Mutex
+	messages []types.Message
+	deleted  []string
+}
+
+func (f *fakeSQS) ReceiveMessage(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	f.mut.Lock()
+	messages := f.messages
+	f.messages = nil
+	f.mut.Unlock()
+
+	if len(messages) == 0 {
+		// Simulate long polling on an empty queue.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (f *fakeSQS) DeleteMessage(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.deleted = append(f.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (f *fakeSQS) ChangeMessageVisibility(context.Context, *sqs.ChangeMessageVisibilityInput, ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func (f *fakeSQS) Deleted() []string {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	return append([]string(nil), f.deleted...)
+}
+
+// fakeS3 serves objects by bucket and key.
+type fakeS3 map[string][]byte
+
+func (f fakeS3) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := f[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, fmt.Errorf("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func notification(bucket, key, etag string) types.Message {
+	body := fmt.Sprintf(`{"Records":[{"eventSource":"aws:s3","awsRegion":"us-east-1","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":%q},"object":{"key":%q,"eTag":%q}}}]}`, bucket, key, etag)
+	return types.Message{
+		MessageId:     aws.String(key),
+		ReceiptHandle: aws.String(key),
+		Body:          aws.String(body),
+	}
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func newTestTarget(t *testing.T, sqsClient sqsAPI, s3Client s3API, cps *checkpoints, args Arguments) (*target, loki.LogsReceiver) {
+	ch := loki.NewLogsReceiver()
+	handler := loki.NewEntryHandler(ch.Chan(), func() {})
+
+	base := DefaultArguments
+	base.QueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/logs"
+	base.Labels = args.Labels
+	base.KeyRegex = args.KeyRegex
+	base.RelabelRules = args.RelabelRules
+
+	tgt := newTarget(newMetrics(prometheus.NewRegistry()), log.NewNopLogger(), handler, cps, sqsClient, s3Client, base)
+	t.Cleanup(tgt.Stop)
+	return tgt, ch
+}
+
+func copyLabel(source, target string) *alloy_relabel.Config {
+	cfg := alloy_relabel.DefaultRelabelConfig
+	cfg.SourceLabels = []string{source}
+	cfg.TargetLabel = target
+	return &cfg
+}
+
+func receiveEntries(t *testing.T, ch loki.LogsReceiver, n int) []loki.Entry {
+	var entries []loki.Entry
+	for len(entries) < n {
+		select {
+		case e := <-ch.Chan():
+			entries = append(entries, e)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d entries, expected %d", len(entries), n)
+		}
+	}
+	return entries
+}
+
+func TestTarget(t *testing.T) {
+	const key = "AWSLogs/123456789012/elasticloadbalancing/us-east-1/lb.log.gz"
+
+	sqsClient := &fakeSQS{messages: []types.Message{notification("logs", key, "abc")}}
+	s3Client := fakeS3{"logs/" + key: gzipped(t, "line 1\nline 2\n")}
+	cps, err := newCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	require.NoError(t, err)
+
+	_, ch := newTestTarget(t, sqsClient, s3Client, cps, Arguments{
+		Labels:   map[string]string{"job": "alb"},
+		KeyRegex: `AWSLogs/(?P<account_id>[0-9]+)/`,
+		RelabelRules: alloy_relabel.Rules{
+			copyLabel("__aws_s3_key_account_id", "account_id"),
+			copyLabel("__aws_s3_bucket", "bucket"),
+		},
+	})
+
+	entries := receiveEntries(t, ch, 2)
+	require.Equal(t, "line 1", entries[0].Line)
+	require.Equal(t, "line 2", entries[1].Line)
+	require.Equal(t, model.LabelSet{
+		"job":        "alb",
+		"account_id": "123456789012",
+		"bucket":     "logs",
+	}, entries[0].Labels)
+
+	// The message is deleted once the object is forwarded, and the checkpoint
+	// of the object isn't needed anymore.
+	require.Eventually(t, func() bool {
+		return len(sqsClient.Deleted()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(t, cps.Get("logs/"+key+"@abc"))
+}
+
+func TestTarget_ResumeFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	cps, err := newCheckpoints(path)
+	require.NoError(t, err)
+	cps.Set("logs/app.log@abc", 2)
+	require.NoError(t, cps.Save())
+
+	// Checkpoints are loaded from disk.
+	cps, err = newCheckpoints(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cps.Get("logs/app.log@abc"))
+
+	sqsClient := &fakeSQS{messages: []types.Message{notification("logs", "app.log", "abc")}}
+	s3Client := fakeS3{"logs/app.log": []byte("line 1\nline 2\nline 3\n")}
+	_, ch := newTestTarget(t, sqsClient, s3Client, cps, Arguments{})
+
+	entries := receiveEntries(t, ch, 1)
+	require.Equal(t, "line 3", entries[0].Line)
+	require.Eventually(t, func() bool {
+		return len(sqsClient.Deleted()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTarget_FailedObject(t *testing.T) {
+	cps, err := newCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	require.NoError(t, err)
+
+	sqsClient := &fakeSQS{messages: []types.Message{
+		notification("logs", "missing.log", "abc"),
+		{ReceiptHandle: aws.String("invalid"), Body: aws.String("not json")},
+	}}
+	tgt, _ := newTestTarget(t, sqsClient, fakeS3{}, cps, Arguments{})
+
+	// The message of the missing object is kept to be redelivered, while the
+	// invalid message is deleted.
+	require.Eventually(t, func() bool {
+		return len(sqsClient.Deleted()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"invalid"}, sqsClient.Deleted())
+	require.Equal(t, 1.0, testutil.ToFloat64(tgt.metrics.errors.WithLabelValues("object")))
+}
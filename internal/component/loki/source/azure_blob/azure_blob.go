@@ -0,0 +1,275 @@
+package azure_blob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	fnet "github.com/grafana/alloy/internal/component/common/net"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.source.azure_blob",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Authentication mechanisms.
+const (
+	AuthenticationMechanismDefault          = "default"
+	AuthenticationMechanismManagedIdentity  = "managed_identity"
+	AuthenticationMechanismConnectionString = "connection_string"
+	AuthenticationMechanismSharedKey        = "shared_key"
+)
+
+// Arguments holds values which are used to configure the
+// loki.source.azure_blob component.
+type Arguments struct {
+	AccountURL     string              `alloy:"account_url,attr,optional"`
+	Container      string              `alloy:"container,attr"`
+	Prefix         string              `alloy:"prefix,attr,optional"`
+	PollFrequency  time.Duration       `alloy:"poll_frequency,attr,optional"`
+	Authentication AzureAuthentication `alloy:"authentication,block,optional"`
+	EventGrid      *EventGrid          `alloy:"event_grid,block,optional"`
+	Labels         map[string]string   `alloy:"labels,attr,optional"`
+
+	ForwardTo    []loki.LogsReceiver `alloy:"forward_to,attr"`
+	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+}
+
+// AzureAuthentication describes how to authenticate with the storage
+// account.
+type AzureAuthentication struct {
+	Mechanism        string            `alloy:"mechanism,attr,optional"`
+	ClientID         string            `alloy:"client_id,attr,optional"`
+	ConnectionString alloytypes.Secret `alloy:"connection_string,attr,optional"`
+	AccountKey       alloytypes.Secret `alloy:"account_key,attr,optional"`
+}
+
+// EventGrid configures the server receiving Event Grid notifications of
+// created blobs.
+type EventGrid struct {
+	Server *fnet.ServerConfig `alloy:",squash"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (e *EventGrid) SetToDefault() {
+	*e = EventGrid{
+		Server: fnet.DefaultServerConfig(),
+	}
+}
+
+// DefaultArguments provides the default arguments for an azure_blob
+// component.
+var DefaultArguments = Arguments{
+	PollFrequency: time.Minute,
+	Authentication: AzureAuthentication{
+		Mechanism: AuthenticationMechanismDefault,
+	},
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if a.Container == "" {
+		return fmt.Errorf("container must not be empty")
+	}
+	if a.PollFrequency < 0 {
+		return fmt.Errorf("poll_frequency must not be negative")
+	}
+	if a.PollFrequency == 0 && a.EventGrid == nil {
+		return fmt.Errorf("poll_frequency can only be disabled when the event_grid block is set")
+	}
+
+	auth := a.Authentication
+	switch auth.Mechanism {
+	case AuthenticationMechanismDefault, AuthenticationMechanismManagedIdentity:
+	case AuthenticationMechanismConnectionString:
+		if auth.ConnectionString == "" {
+			return fmt.Errorf("connection_string must be set for %s authentication", auth.Mechanism)
+		}
+	case AuthenticationMechanismSharedKey:
+		if auth.AccountKey == "" {
+			return fmt.Errorf("account_key must be set for %s authentication", auth.Mechanism)
+		}
+	default:
+		return fmt.Errorf("unsupported authentication mechanism %q, must be one of %q, %q, %q or %q", auth.Mechanism,
+			AuthenticationMechanismDefault, AuthenticationMechanismManagedIdentity, AuthenticationMechanismConnectionString, AuthenticationMechanismSharedKey)
+	}
+	if auth.ClientID != "" && auth.Mechanism != AuthenticationMechanismManagedIdentity {
+		return fmt.Errorf("client_id can only be set for %s authentication", AuthenticationMechanismManagedIdentity)
+	}
+	// The connection string includes the endpoint of the storage account.
+	if (a.AccountURL == "") != (auth.Mechanism == AuthenticationMechanismConnectionString) {
+		return fmt.Errorf("account_url must be set, unless the %s authentication mechanism is used", AuthenticationMechanismConnectionString)
+	}
+	return nil
+}
+
+// Component implements the loki.source.azure_blob component.
+type Component struct {
+	opts          component.Options
+	metrics       *metrics
+	serverMetrics *util.UncheckedCollector
+	posFile       positions.Positions
+
+	mut    sync.RWMutex
+	fanout []loki.LogsReceiver
+	target *target
+	server *fnet.TargetServer
+
+	handler loki.LogsReceiver
+}
+
+// New creates a new loki.source.azure_blob component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	err := os.MkdirAll(o.DataPath, 0750)
+	if err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+	positionsFile, err := positions.New(o.Logger, positions.Config{
+		SyncPeriod:        10 * time.Second,
+		PositionsFile:     filepath.Join(o.DataPath, "positions.yml"),
+		IgnoreInvalidYaml: false,
+		ReadOnly:          false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:          o,
+		metrics:       newMetrics(o.Registerer),
+		serverMetrics: util.NewUncheckedCollector(nil),
+		posFile:       positionsFile,
+		handler:       loki.NewLogsReceiver(),
+		fanout:        args.ForwardTo,
+	}
+	o.Registerer.MustRegister(c.serverMetrics)
+
+	// Call to Update() to start readers and set receivers once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		level.Info(c.opts.Logger).Log("msg", "loki.source.azure_blob component shutting down, stopping target")
+		c.stop()
+		c.posFile.Stop()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.handler.Chan():
+			c.mut.RLock()
+			for _, receiver := range c.fanout {
+				receiver.Chan() <- entry
+			}
+			c.mut.RUnlock()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+	c.fanout = newArgs.ForwardTo
+
+	c.stop()
+
+	client, err := newBlobClient(newArgs)
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to create azure blob client with provided config", "err", err)
+		return err
+	}
+
+	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
+	c.target = newTarget(c.metrics, c.opts.Logger, entryHandler, c.posFile, client, newArgs)
+
+	if newArgs.EventGrid != nil {
+		registry := prometheus.NewRegistry()
+		c.serverMetrics.SetCollector(registry)
+
+		jobName := strings.ReplaceAll(c.opts.ID, ".", "_")
+		c.server, err = fnet.NewTargetServer(c.opts.Logger, jobName, registry, newArgs.EventGrid.Server)
+		if err != nil {
+			return err
+		}
+
+		handler := newEventGridHandler(c.opts.Logger, c.metrics, c.target)
+		if err := c.server.MountAndRun(func(router *mux.Router) {
+			router.Path("/azure_blob/api/v1/eventgrid").Methods("POST").Handler(handler)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stop shuts down the current server and target. It is not goroutine-safe
+// and mut write lock must be held when it's called.
+func (c *Component) stop() {
+	if c.server != nil {
+		c.server.StopAndShutdown()
+		c.server = nil
+	}
+	if c.target != nil {
+		c.target.Stop()
+		c.target = nil
+	}
+}
+
+// DebugInfo returns information about the status of the target.
+func (c *Component) DebugInfo() interface{} {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	var info targetDebugInfo
+	if c.target != nil {
+		info.Details = c.target.Details()
+	}
+	return info
+}
+
+type targetDebugInfo struct {
+	Details map[string]string `alloy:"target_info,attr"`
+}
@@ -0,0 +1,136 @@
+package azure_blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	account_url = "https://logs.blob.core.windows.net"
+	container   = "insights-logs-auditevent"
+	labels      = {component = "loki.source.azure_blob"}
+	forward_to  = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, args.PollFrequency)
+	require.Equal(t, AuthenticationMechanismDefault, args.Authentication.Mechanism)
+	require.Nil(t, args.EventGrid)
+}
+
+func TestEventGridAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	account_url    = "https://logs.blob.core.windows.net"
+	container      = "insights-logs-auditevent"
+	poll_frequency = "0s"
+	authentication {
+		mechanism = "managed_identity"
+		client_id = "00000000-0000-0000-0000-000000000000"
+	}
+	event_grid {
+		http {
+			listen_port = 8080
+		}
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.NotNil(t, args.EventGrid)
+	require.Equal(t, 8080, args.EventGrid.Server.HTTP.ListenPort)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "listing disabled without event grid",
+			config: `
+	account_url    = "https://logs.blob.core.windows.net"
+	container      = "logs"
+	poll_frequency = "0s"
+	forward_to     = []`,
+			err: "poll_frequency can only be disabled when the event_grid block is set",
+		},
+		{
+			name: "missing account url",
+			config: `
+	container  = "logs"
+	forward_to = []`,
+			err: "account_url must be set",
+		},
+		{
+			name: "account url with connection string",
+			config: `
+	account_url = "https://logs.blob.core.windows.net"
+	container   = "logs"
+	authentication {
+		mechanism         = "connection_string"
+		connection_string = "DefaultEndpointsProtocol=https;AccountName=logs"
+	}
+	forward_to = []`,
+			err: "account_url must be set, unless the connection_string authentication mechanism is used",
+		},
+		{
+			name: "shared key without key",
+			config: `
+	account_url = "https://logs.blob.core.windows.net"
+	container   = "logs"
+	authentication {
+		mechanism = "shared_key"
+	}
+	forward_to = []`,
+			err: "account_key must be set for shared_key authentication",
+		},
+		{
+			name: "client id without managed identity",
+			config: `
+	account_url = "https://logs.blob.core.windows.net"
+	container   = "logs"
+	authentication {
+		client_id = "00000000-0000-0000-0000-000000000000"
+	}
+	forward_to = []`,
+			err: "client_id can only be set for managed_identity authentication",
+		},
+		{
+			name: "invalid mechanism",
+			config: `
+	account_url = "https://logs.blob.core.windows.net"
+	container   = "logs"
+	authentication {
+		mechanism = "sas"
+	}
+	forward_to = []`,
+			err: `unsupported authentication mechanism "sas"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestAccountNameFromURL(t *testing.T) {
+	name, err := accountNameFromURL("https://logs.blob.core.windows.net/")
+	require.NoError(t, err)
+	require.Equal(t, "logs", name)
+
+	_, err = accountNameFromURL("https:///container")
+	require.ErrorContains(t, err, "missing storage account name")
+}
@@ -0,0 +1,150 @@
+package azure_blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// errNoNewData is returned when downloading a blob from its end.
+var errNoNewData = errors.New("no new data")
+
+// blobInfo describes a blob of the container.
+type blobInfo struct {
+	Name         string
+	Type         blob.BlobType
+	Size         int64
+	LastModified time.Time
+}
+
+// blobClient is the subset of the storage operations used by the target.
+type blobClient interface {
+	// ListBlobs returns the blobs of the container whose name starts with
+	// prefix.
+	ListBlobs(ctx context.Context, container, prefix string) ([]blobInfo, error)
+	// Download returns the content of a blob starting at offset. It returns
+	// errNoNewData if offset is the size of the blob.
+	Download(ctx context.Context, container, name string, offset int64) (io.ReadCloser, error)
+}
+
+// newBlobClient creates a client for the storage account using the
+// configured authentication mechanism.
+func newBlobClient(args Arguments) (blobClient, error) {
+	var (
+		client *azblob.Client
+		err    error
+	)
+
+	auth := args.Authentication
+	switch auth.Mechanism {
+	case AuthenticationMechanismConnectionString:
+		client, err = azblob.NewClientFromConnectionString(string(auth.ConnectionString), nil)
+	case AuthenticationMechanismSharedKey:
+		var accountName string
+		accountName, err = accountNameFromURL(args.AccountURL)
+		if err != nil {
+			return nil, err
+		}
+		var cred *azblob.SharedKeyCredential
+		cred, err = azblob.NewSharedKeyCredential(accountName, string(auth.AccountKey))
+		if err != nil {
+			return nil, err
+		}
+		client, err = azblob.NewClientWithSharedKeyCredential(args.AccountURL, cred, nil)
+	case AuthenticationMechanismManagedIdentity:
+		var opts azidentity.ManagedIdentityCredentialOptions
+		if auth.ClientID != "" {
+			opts.ID = azidentity.ClientID(auth.ClientID)
+		}
+		var cred azcore.TokenCredential
+		cred, err = azidentity.NewManagedIdentityCredential(&opts)
+		if err != nil {
+			return nil, err
+		}
+		client, err = azblob.NewClient(args.AccountURL, cred, nil)
+	default:
+		var cred azcore.TokenCredential
+		cred, err = azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		client, err = azblob.NewClient(args.AccountURL, cred, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &azureBlobClient{client: client}, nil
+}
+
+// accountNameFromURL returns the storage account name, which is the first
+// label of the host of the account URL.
+func accountNameFromURL(accountURL string) (string, error) {
+	u, err := url.Parse(accountURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid account_url: %w", err)
+	}
+	name, _, _ := strings.Cut(u.Hostname(), ".")
+	if name == "" {
+		return "", fmt.Errorf("invalid account_url %q: missing storage account name", accountURL)
+	}
+	return name, nil
+}
+
+type azureBlobClient struct {
+	client *azblob.Client
+}
+
+func (c *azureBlobClient) ListBlobs(ctx context.Context, container, prefix string) ([]blobInfo, error) {
+	var opts azblob.ListBlobsFlatOptions
+	if prefix != "" {
+		opts.Prefix = &prefix
+	}
+
+	var blobs []blobInfo
+	pager := c.client.NewListBlobsFlatPager(container, &opts)
+	for pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Segment.BlobItems {
+			if item.Name == nil || item.Properties == nil {
+				continue
+			}
+			info := blobInfo{Name: *item.Name}
+			if item.Properties.BlobType != nil {
+				info.Type = *item.Properties.BlobType
+			}
+			if item.Properties.ContentLength != nil {
+				info.Size = *item.Properties.ContentLength
+			}
+			if item.Properties.LastModified != nil {
+				info.LastModified = *item.Properties.LastModified
+			}
+			blobs = append(blobs, info)
+		}
+	}
+	return blobs, nil
+}
+
+func (c *azureBlobClient) Download(ctx context.Context, container, name string, offset int64) (io.ReadCloser, error) {
+	resp, err := c.client.DownloadStream(ctx, container, name, &azblob.DownloadStreamOptions{
+		Range: azblob.HTTPRange{Offset: offset},
+	})
+	if bloberror.HasCode(err, bloberror.InvalidRange) {
+		return nil, errNoNewData
+	} else if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
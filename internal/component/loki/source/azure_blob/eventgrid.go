@@ -0,0 +1,118 @@
+package azure_blob
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Event Grid event types handled by the component.
+const (
+	eventTypeSubscriptionValidation = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	eventTypeBlobCreated            = "Microsoft.Storage.BlobCreated"
+)
+
+// eventGridEvent is an event using the Event Grid schema.
+type eventGridEvent struct {
+	ID        string          `json:"id"`
+	EventType string          `json:"eventType"`
+	Subject   string          `json:"subject"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type subscriptionValidationData struct {
+	ValidationCode string `json:"validationCode"`
+}
+
+type blobCreatedData struct {
+	BlobType string `json:"blobType"`
+}
+
+// blobNotifier queues blobs to be read.
+type blobNotifier interface {
+	Notify(b blobInfo) bool
+}
+
+// eventGridHandler receives the events of an Event Grid webhook
+// subscription, and notifies the target of the blobs created in its
+// container.
+type eventGridHandler struct {
+	logger    log.Logger
+	metrics   *metrics
+	container string
+	notifier  blobNotifier
+}
+
+func newEventGridHandler(logger log.Logger, metrics *metrics, t *target) http.Handler {
+	return &eventGridHandler{
+		logger:    logger,
+		metrics:   metrics,
+		container: t.args.Container,
+		notifier:  t,
+	}
+}
+
+func (h *eventGridHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var events []eventGridEvent
+	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+		level.Warn(h.logger).Log("msg", "failed to decode event grid events", "err", err)
+		h.metrics.errors.WithLabelValues("event").Inc()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range events {
+		switch event.EventType {
+		case eventTypeSubscriptionValidation:
+			// Event Grid validates that the endpoint accepts the events of a
+			// subscription when it's created.
+			var data subscriptionValidationData
+			if err := json.Unmarshal(event.Data, &data); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level.Info(h.logger).Log("msg", "validating event grid subscription", "event_id", event.ID)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"validationResponse": data.ValidationCode})
+			return
+
+		case eventTypeBlobCreated:
+			name, ok := h.blobName(event.Subject)
+			if !ok {
+				continue
+			}
+			var data blobCreatedData
+			if err := json.Unmarshal(event.Data, &data); err != nil {
+				level.Warn(h.logger).Log("msg", "failed to decode event grid event", "event_id", event.ID, "err", err)
+				h.metrics.errors.WithLabelValues("event").Inc()
+				continue
+			}
+			h.metrics.events.Inc()
+
+			// The size of the blob is unknown, since data may have been
+			// appended since the event was sent.
+			if !h.notifier.Notify(blobInfo{Name: name, Type: blob.BlobType(data.BlobType), Size: -1}) {
+				// Event Grid retries delivering the events later.
+				http.Error(w, "too many blobs waiting to be read", http.StatusServiceUnavailable)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// blobName extracts the name of the blob from the subject of an event, like
+// /blobServices/default/containers/<container>/blobs/<name>. It returns false
+// if the blob isn't part of the container.
+func (h *eventGridHandler) blobName(subject string) (string, bool) {
+	prefix := "/blobServices/default/containers/" + h.container + "/blobs/"
+	if !strings.HasPrefix(subject, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(subject, prefix), true
+}
@@ -0,0 +1,89 @@
+package azure_blob
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	blobs []blobInfo
+	full  bool
+}
+
+func (n *fakeNotifier) Notify(b blobInfo) bool {
+	if n.full {
+		return false
+	}
+	n.blobs = append(n.blobs, b)
+	return true
+}
+
+func newTestHandler(notifier blobNotifier) *eventGridHandler {
+	return &eventGridHandler{
+		logger:    log.NewNopLogger(),
+		metrics:   newMetrics(prometheus.NewRegistry()),
+		container: "logs",
+		notifier:  notifier,
+	}
+}
+
+func TestEventGridHandler_Validation(t *testing.T) {
+	body := `[{
+		"id": "2d1781af-3a4c-4d7c-bd0c-e34b19da4e66",
+		"eventType": "Microsoft.EventGrid.SubscriptionValidationEvent",
+		"subject": "",
+		"data": {"validationCode": "512d38b6-c7b8-40c8-89fe-f46f9e9622b6"}
+	}]`
+
+	rec := httptest.NewRecorder()
+	newTestHandler(&fakeNotifier{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"validationResponse": "512d38b6-c7b8-40c8-89fe-f46f9e9622b6"}`, rec.Body.String())
+}
+
+func TestEventGridHandler_BlobCreated(t *testing.T) {
+	body := `[
+		{
+			"id": "1",
+			"eventType": "Microsoft.Storage.BlobCreated",
+			"subject": "/blobServices/default/containers/logs/blobs/y=2024/m=03/PT1H.json",
+			"data": {"api": "PutBlob", "blobType": "AppendBlob", "contentLength": 0}
+		},
+		{
+			"id": "2",
+			"eventType": "Microsoft.Storage.BlobCreated",
+			"subject": "/blobServices/default/containers/other/blobs/PT1H.json",
+			"data": {"api": "PutBlob", "blobType": "BlockBlob"}
+		},
+		{
+			"id": "3",
+			"eventType": "Microsoft.Storage.BlobDeleted",
+			"subject": "/blobServices/default/containers/logs/blobs/old.json",
+			"data": {"api": "DeleteBlob", "blobType": "BlockBlob"}
+		}
+	]`
+
+	notifier := &fakeNotifier{}
+	rec := httptest.NewRecorder()
+	newTestHandler(notifier).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []blobInfo{{Name: "y=2024/m=03/PT1H.json", Type: blob.BlobTypeAppendBlob, Size: -1}}, notifier.blobs)
+
+	// Event Grid retries the delivery when too many blobs are waiting.
+	rec = httptest.NewRecorder()
+	newTestHandler(&fakeNotifier{full: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestEventGridHandler_Invalid(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestHandler(&fakeNotifier{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,34 @@
+package azure_blob
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	entries prometheus.Counter
+	blobs   prometheus.Counter
+	events  prometheus.Counter
+	errors  *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.entries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_azure_blob_entries_total",
+		Help: "Total number of log entries read from blobs.",
+	})
+	m.blobs = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_azure_blob_reads_total",
+		Help: "Total number of times blobs were read.",
+	})
+	m.events = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_azure_blob_events_total",
+		Help: "Total number of Event Grid notifications of created blobs received.",
+	})
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_source_azure_blob_errors_total",
+		Help: "Total number of errors while reading blobs.",
+	}, []string{"reason"})
+
+	reg.MustRegister(m.entries, m.blobs, m.events, m.errors)
+	return &m
+}
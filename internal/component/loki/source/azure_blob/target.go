@@ -0,0 +1,287 @@
+package azure_blob
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/go-kit/log"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Internal labels set on every log entry, which can be used in relabel rules.
+const (
+	labelContainer = "__azure_blob_container"
+	labelName      = "__azure_blob_name"
+	labelType      = "__azure_blob_type"
+)
+
+// maxPendingBlobs is the number of blobs notified through Event Grid which
+// can wait to be read.
+const maxPendingBlobs = 1000
+
+// target reads the blobs of a container, either when they're listed or when
+// Event Grid notifies that they were created, and forwards their lines as
+// log entries. The offset of each blob is tracked, so appended data is read
+// from where the blob was last read.
+type target struct {
+	metrics       *metrics
+	logger        log.Logger
+	handler       loki.EntryHandler
+	positions     positions.Positions
+	client        blobClient
+	args          Arguments
+	labels        model.LabelSet
+	relabelConfig []*relabel.Config
+
+	pending chan blobInfo
+	// listed holds the blobs found by the last listing, so that the
+	// positions of deleted blobs are removed.
+	listed map[string]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newTarget(metrics *metrics, logger log.Logger, handler loki.EntryHandler, positions positions.Positions, client blobClient, args Arguments) *target {
+	lbls := make(model.LabelSet, len(args.Labels))
+	for k, v := range args.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &target{
+		metrics:       metrics,
+		logger:        logger,
+		handler:       handler,
+		positions:     positions,
+		client:        client,
+		args:          args,
+		labels:        lbls,
+		relabelConfig: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
+		pending:       make(chan blobInfo, maxPendingBlobs),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	t.wg.Add(1)
+	go t.run()
+	return t
+}
+
+func (t *target) run() {
+	defer t.wg.Done()
+
+	var tick <-chan time.Time
+	if t.args.PollFrequency > 0 {
+		ticker := time.NewTicker(t.args.PollFrequency)
+		defer ticker.Stop()
+		tick = ticker.C
+
+		t.list()
+	}
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-tick:
+			t.list()
+		case b := <-t.pending:
+			t.read(b)
+		}
+	}
+}
+
+// Notify queues a blob to be read. It returns false if too many blobs are
+// already waiting to be read.
+func (t *target) Notify(b blobInfo) bool {
+	if !strings.HasPrefix(b.Name, t.args.Prefix) {
+		return true
+	}
+	select {
+	case t.pending <- b:
+		return true
+	default:
+		return false
+	}
+}
+
+// list reads the new data of all the blobs of the container.
+func (t *target) list() {
+	blobs, err := t.client.ListBlobs(t.ctx, t.args.Container, t.args.Prefix)
+	if err != nil {
+		if t.ctx.Err() == nil {
+			level.Error(t.logger).Log("msg", "failed to list blobs", "container", t.args.Container, "err", err)
+			t.metrics.errors.WithLabelValues("list").Inc()
+		}
+		return
+	}
+
+	listed := make(map[string]struct{}, len(blobs))
+	for _, b := range blobs {
+		listed[b.Name] = struct{}{}
+		if !t.read(b) {
+			return
+		}
+	}
+
+	for name := range t.listed {
+		if _, ok := listed[name]; !ok {
+			t.positions.Remove(t.positionKey(name), "")
+		}
+	}
+	t.listed = listed
+}
+
+// read forwards the lines of a blob written since it was last read. It
+// returns false if the target was stopped.
+func (t *target) read(b blobInfo) bool {
+	key := t.positionKey(b.Name)
+	offset, err := t.positions.Get(key, "")
+	if err != nil {
+		level.Warn(t.logger).Log("msg", "invalid position of blob, reading it from the start", "blob", b.Name, "err", err)
+		offset = 0
+	}
+	// The size of blobs notified through Event Grid isn't known.
+	if b.Size >= 0 {
+		if b.Size < offset {
+			// The blob was overwritten.
+			offset = 0
+		}
+		if b.Size == offset {
+			return true
+		}
+	}
+
+	entryLabels, keep := t.blobLabels(b)
+	if !keep {
+		return true
+	}
+
+	body, err := t.client.Download(t.ctx, t.args.Container, b.Name, offset)
+	if errors.Is(err, errNoNewData) {
+		return true
+	} else if err != nil {
+		if t.ctx.Err() != nil {
+			return false
+		}
+		level.Error(t.logger).Log("msg", "failed to download blob", "blob", b.Name, "err", err)
+		t.metrics.errors.WithLabelValues("download").Inc()
+		return true
+	}
+	defer body.Close()
+
+	br := bufio.NewReader(body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			if t.ctx.Err() != nil {
+				return false
+			}
+			level.Error(t.logger).Log("msg", "failed to read blob", "blob", b.Name, "err", err)
+			t.metrics.errors.WithLabelValues("read").Inc()
+			return true
+		}
+
+		// Append blobs may still be written to, so an incomplete last line
+		// is read once it's terminated.
+		complete := strings.HasSuffix(line, "\n")
+		if line != "" && (complete || b.Type == blob.BlobTypeBlockBlob) {
+			if text := strings.TrimRight(line, "\r\n"); text != "" {
+				if !t.send(loki.Entry{
+					Labels: entryLabels.Clone(),
+					Entry: logproto.Entry{
+						Timestamp: time.Now(),
+						Line:      text,
+					},
+				}) {
+					return false
+				}
+			}
+			offset += int64(len(line))
+			t.positions.Put(key, "", offset)
+		}
+
+		if err == io.EOF {
+			t.metrics.blobs.Inc()
+			return true
+		}
+	}
+}
+
+// positionKey returns the key of the blob in the positions file. Cursor keys
+// are used, since positions of paths which don't exist on disk are removed.
+func (t *target) positionKey(name string) string {
+	return positions.CursorKey(t.args.Container + "/" + name)
+}
+
+// blobLabels returns the labels of the entries read from a blob. It returns
+// false if the blob was dropped by the relabel rules.
+func (t *target) blobLabels(b blobInfo) (model.LabelSet, bool) {
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	lb.Set(labelContainer, t.args.Container)
+	lb.Set(labelName, b.Name)
+	lb.Set(labelType, string(b.Type))
+
+	processed, keep := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
+	if !keep {
+		return nil, false
+	}
+
+	// Start with the set of labels fixed in the configuration
+	entryLabels := t.labels.Clone()
+	processed.Range(func(lbl labels.Label) {
+		if strings.HasPrefix(lbl.Name, "__") {
+			return
+		}
+		entryLabels[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+	})
+	return entryLabels, true
+}
+
+// send forwards an entry, and returns false if the target was stopped
+// before the entry could be sent.
+func (t *target) send(entry loki.Entry) bool {
+	select {
+	case t.handler.Chan() <- entry:
+		t.metrics.entries.Inc()
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+// Details returns some debug information about the target.
+func (t *target) Details() map[string]string {
+	return map[string]string{
+		"account_url":    t.args.AccountURL,
+		"container":      t.args.Container,
+		"prefix":         t.args.Prefix,
+		"poll_frequency": t.args.PollFrequency.String(),
+		"event_grid":     strconv.FormatBool(t.args.EventGrid != nil),
+		"labels":         t.labels.String(),
+	}
+}
+
+// Stop shuts the target down.
+func (t *target) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	t.handler.Stop()
+}
@@ -0,0 +1,216 @@
+package azure_blob
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+)
+
+type fakeBlob struct {
+	blobType blob.BlobType
+	content  string
+}
+
+// fakeBlobClient serves blobs from memory.
+type fakeBlobClient struct {
+	mut   sync.Mutex
+	blobs map[string]*fakeBlob
+}
+
+func (c *fakeBlobClient) ListBlobs(_ context.Context, _, prefix string) ([]blobInfo, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var infos []blobInfo
+	for name, b := range c.blobs {
+		if strings.HasPrefix(name, prefix) {
+			infos = append(infos, blobInfo{Name: name, Type: b.blobType, Size: int64(len(b.content))})
+		}
+	}
+	return infos, nil
+}
+
+func (c *fakeBlobClient) Download(_ context.Context, _, name string, offset int64) (io.ReadCloser, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	b := c.blobs[name]
+	if offset >= int64(len(b.content)) {
+		return nil, errNoNewData
+	}
+	return io.NopCloser(strings.NewReader(b.content[offset:])), nil
+}
+
+func (c *fakeBlobClient) set(name string, blobType blob.BlobType, content string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.blobs[name] = &fakeBlob{blobType: blobType, content: content}
+}
+
+func (c *fakeBlobClient) remove(name string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	delete(c.blobs, name)
+}
+
+func newTestTarget(t *testing.T, client blobClient, args Arguments) (*target, loki.LogsReceiver, positions.Positions) {
+	pos, err := positions.New(log.NewNopLogger(), positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: filepath.Join(t.TempDir(), "positions.yml"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(pos.Stop)
+
+	ch := loki.NewLogsReceiverWithChannel(make(chan loki.Entry, 100))
+	lbls := make(model.LabelSet, len(args.Labels))
+	for k, v := range args.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// The target isn't started, so tests call list and read directly.
+	return &target{
+		metrics:       newMetrics(prometheus.NewRegistry()),
+		logger:        log.NewNopLogger(),
+		handler:       loki.NewEntryHandler(ch.Chan(), func() {}),
+		positions:     pos,
+		client:        client,
+		args:          args,
+		labels:        lbls,
+		relabelConfig: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
+		pending:       make(chan blobInfo, maxPendingBlobs),
+		ctx:           ctx,
+		cancel:        cancel,
+	}, ch, pos
+}
+
+func drainLines(ch loki.LogsReceiver) []string {
+	var lines []string
+	for {
+		select {
+		case e := <-ch.Chan():
+			lines = append(lines, e.Line)
+		default:
+			return lines
+		}
+	}
+}
+
+func TestTarget_AppendBlob(t *testing.T) {
+	client := &fakeBlobClient{blobs: map[string]*fakeBlob{}}
+	tgt, ch, pos := newTestTarget(t, client, Arguments{Container: "logs"})
+
+	// The incomplete last line of an append blob waits until it's terminated.
+	client.set("PT1H.json", blob.BlobTypeAppendBlob, "line 1\r\nline 2\npart")
+	tgt.list()
+	require.Equal(t, []string{"line 1", "line 2"}, drainLines(ch))
+
+	offset, err := pos.Get(tgt.positionKey("PT1H.json"), "")
+	require.NoError(t, err)
+	require.Equal(t, int64(len("line 1\r\nline 2\n")), offset)
+
+	client.set("PT1H.json", blob.BlobTypeAppendBlob, "line 1\r\nline 2\npartial line 3\n\nline 4\n")
+	tgt.list()
+	require.Equal(t, []string{"partial line 3", "line 4"}, drainLines(ch))
+
+	// Nothing is read when the blob didn't change.
+	tgt.list()
+	require.Empty(t, drainLines(ch))
+
+	// The position of deleted blobs is removed.
+	client.remove("PT1H.json")
+	tgt.list()
+	require.Empty(t, pos.GetString(tgt.positionKey("PT1H.json"), ""))
+}
+
+func TestTarget_BlockBlob(t *testing.T) {
+	client := &fakeBlobClient{blobs: map[string]*fakeBlob{}}
+	tgt, ch, _ := newTestTarget(t, client, Arguments{Container: "logs"})
+
+	// Block blobs are complete, so their last line is read even if it isn't
+	// terminated.
+	client.set("export.log", blob.BlobTypeBlockBlob, "line 1\nline 2")
+	tgt.list()
+	require.Equal(t, []string{"line 1", "line 2"}, drainLines(ch))
+
+	// An overwritten blob is read from the start.
+	client.set("export.log", blob.BlobTypeBlockBlob, "new\n")
+	tgt.list()
+	require.Equal(t, []string{"new"}, drainLines(ch))
+}
+
+func TestTarget_Labels(t *testing.T) {
+	client := &fakeBlobClient{blobs: map[string]*fakeBlob{}}
+	client.set("resourceId=/SUBSCRIPTIONS/1/PT1H.json", blob.BlobTypeAppendBlob, "line\n")
+
+	copyLabel := func(source, target string) *alloy_relabel.Config {
+		cfg := alloy_relabel.DefaultRelabelConfig
+		cfg.SourceLabels = []string{source}
+		cfg.TargetLabel = target
+		return &cfg
+	}
+	tgt, ch, _ := newTestTarget(t, client, Arguments{
+		Container: "insights-logs",
+		Labels:    map[string]string{"job": "azure"},
+		RelabelRules: alloy_relabel.Rules{
+			copyLabel("__azure_blob_container", "container"),
+			copyLabel("__azure_blob_name", "blob"),
+			copyLabel("__azure_blob_type", "blob_type"),
+		},
+	})
+
+	require.True(t, tgt.read(blobInfo{Name: "resourceId=/SUBSCRIPTIONS/1/PT1H.json", Type: blob.BlobTypeAppendBlob, Size: -1}))
+	entry := <-ch.Chan()
+	require.Equal(t, model.LabelSet{
+		"job":       "azure",
+		"container": "insights-logs",
+		"blob":      "resourceId=/SUBSCRIPTIONS/1/PT1H.json",
+		"blob_type": "AppendBlob",
+	}, entry.Labels)
+}
+
+func TestTarget_Notify(t *testing.T) {
+	client := &fakeBlobClient{blobs: map[string]*fakeBlob{}}
+	client.set("logs/a.log", blob.BlobTypeBlockBlob, "line\n")
+
+	ch := loki.NewLogsReceiver()
+	pos, err := positions.New(log.NewNopLogger(), positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: filepath.Join(t.TempDir(), "positions.yml"),
+	})
+	require.NoError(t, err)
+	defer pos.Stop()
+
+	// Listing is disabled, so blobs are only read when notified.
+	tgt := newTarget(newMetrics(prometheus.NewRegistry()), log.NewNopLogger(), loki.NewEntryHandler(ch.Chan(), func() {}), pos, client, Arguments{
+		Container: "container",
+		Prefix:    "logs/",
+	})
+	defer tgt.Stop()
+
+	require.True(t, tgt.Notify(blobInfo{Name: "other/b.log", Size: -1}))
+	require.True(t, tgt.Notify(blobInfo{Name: "logs/a.log", Type: blob.BlobTypeBlockBlob, Size: -1}))
+
+	select {
+	case entry := <-ch.Chan():
+		require.Equal(t, "line", entry.Line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("blob wasn't read")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/prometheus/prometheus/model/relabel"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 )
 
 type azureMonitorResourceLogs struct {
@@ -179,7 +180,7 @@ func (e *AzureEventHubsTargetMessageParser) getLabels(logRecord *azureMonitorRes
 	var processed labels.Labels
 	// apply relabeling
 	if len(relabelConfig) > 0 {
-		processed, _ = relabel.Process(lbs, relabelConfig...)
+		processed, _ = alloy_relabel.Process(lbs, relabelConfig...)
 	} else {
 		processed = lbs
 	}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/util/strutil"
 )
 
 func init() {
@@ -49,8 +51,21 @@ const (
 	dockerLabel                = model.MetaLabelPrefix + "docker_"
 	dockerLabelContainerPrefix = dockerLabel + "container_"
 	dockerLabelContainerID     = dockerLabelContainerPrefix + "id"
+	dockerLabelContainerLabel  = dockerLabelContainerPrefix + "label_"
+
+	composeLabelProject         = "com.docker.compose.project"
+	composeLabelService         = "com.docker.compose.service"
+	composeLabelContainerNumber = "com.docker.compose.container-number"
 )
 
+// composeLabels maps the Docker Compose container labels to the Loki labels
+// they're exposed as when compose_labels is enabled.
+var composeLabels = map[string]model.LabelName{
+	composeLabelProject:         "compose_project",
+	composeLabelService:         "compose_service",
+	composeLabelContainerNumber: "compose_container_number",
+}
+
 // Arguments holds values which are used to configure the loki.source.docker
 // component.
 type Arguments struct {
@@ -61,6 +76,9 @@ type Arguments struct {
 	RelabelRules     alloy_relabel.Rules     `alloy:"relabel_rules,attr,optional"`
 	HTTPClientConfig *types.HTTPClientConfig `alloy:"http_client_config,block,optional"`
 	RefreshInterval  time.Duration           `alloy:"refresh_interval,attr,optional"`
+	ComposeLabels    bool                    `alloy:"compose_labels,attr,optional"`
+	ComposeProjects  []string                `alloy:"compose_projects,attr,optional"`
+	ContainerLabels  []string                `alloy:"container_labels,attr,optional"`
 }
 
 // GetDefaultArguments return an instance of Arguments with the optional fields
@@ -87,12 +105,57 @@ func (a *Arguments) Validate() error {
 		if a.RefreshInterval <= 0 {
 			return fmt.Errorf("refresh_interval must be positive, got %q", a.RefreshInterval)
 		}
-		return a.HTTPClientConfig.Validate()
+		if err := a.HTTPClientConfig.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, name := range a.ContainerLabels {
+		if name == "" {
+			return fmt.Errorf("container_labels must not contain empty label names")
+		}
 	}
 
 	return nil
 }
 
+// containerLabel returns the value of the Docker container label name from
+// the discovery labels of a target.
+func containerLabel(labels model.LabelSet, name string) (model.LabelValue, bool) {
+	v, ok := labels[model.LabelName(dockerLabelContainerLabel+strutil.SanitizeLabelName(name))]
+	return v, ok
+}
+
+// containerMetadataLabels returns the labels built from the container labels
+// of a target according to the compose_labels and container_labels
+// arguments.
+func containerMetadataLabels(args Arguments, labels model.LabelSet) model.LabelSet {
+	res := make(model.LabelSet)
+	if args.ComposeLabels {
+		for name, lbl := range composeLabels {
+			if v, ok := containerLabel(labels, name); ok && v != "" {
+				res[lbl] = v
+			}
+		}
+	}
+	for _, name := range args.ContainerLabels {
+		if v, ok := containerLabel(labels, name); ok && v != "" {
+			res[model.LabelName(strutil.SanitizeLabelName(name))] = v
+		}
+	}
+	return res
+}
+
+// matchesComposeProjects reports whether a target belongs to one of the
+// Compose projects in the compose_projects argument. All targets match when
+// compose_projects is empty.
+func matchesComposeProjects(args Arguments, labels model.LabelSet) bool {
+	if len(args.ComposeProjects) == 0 {
+		return true
+	}
+	project, _ := containerLabel(labels, composeLabelProject)
+	return slices.Contains(args.ComposeProjects, string(project))
+}
+
 var (
 	_ component.Component      = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
@@ -250,6 +313,9 @@ func (c *Component) Update(args component.Arguments) error {
 			continue
 		}
 		seenTargets[string(containerID)] = struct{}{}
+		if !matchesComposeProjects(newArgs, markedTarget.labels) {
+			continue
+		}
 
 		tgt, err := dt.NewTarget(
 			c.metrics,
@@ -257,7 +323,7 @@ func (c *Component) Update(args component.Arguments) error {
 			c.manager.opts.handler,
 			c.manager.opts.positions,
 			string(containerID),
-			markedTarget.labels.Merge(c.defaultLabels),
+			markedTarget.labels.Merge(containerMetadataLabels(newArgs, markedTarget.labels)).Merge(c.defaultLabels),
 			c.rcs,
 			c.manager.opts.client,
 		)
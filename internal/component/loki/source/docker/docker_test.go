@@ -105,6 +105,57 @@ func TestDuplicateTargets(t *testing.T) {
 	require.Equal(t, cmp.manager.tasks[0].target.LabelsStr(), "{__meta_docker_container_id=\"foo\", __meta_docker_port_private=\"8080\"}")
 }
 
+func TestComposeLabels(t *testing.T) {
+	var cfg = `
+		host       = "tcp://127.0.0.1:9377"
+		targets    = [
+			{
+				__meta_docker_container_id = "web",
+				__meta_docker_container_label_com_docker_compose_project = "shop",
+				__meta_docker_container_label_com_docker_compose_service = "frontend",
+				__meta_docker_container_label_com_docker_compose_container_number = "1",
+				__meta_docker_container_label_com_example_team = "payments",
+			},
+			{
+				__meta_docker_container_id = "db",
+				__meta_docker_container_label_com_docker_compose_project = "infra",
+				__meta_docker_container_label_com_docker_compose_service = "postgres",
+			},
+			{__meta_docker_container_id = "standalone"},
+		]
+		forward_to       = []
+		compose_labels   = true
+		compose_projects = ["shop"]
+		container_labels = ["com.example.team", "com.example.missing"]
+	`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(cfg), &args)
+	require.NoError(t, err)
+
+	cmp, err := New(component.Options{
+		ID:         "loki.source.docker.test",
+		Logger:     util.TestAlloyLogger(t),
+		Registerer: prometheus.NewRegistry(),
+		DataPath:   t.TempDir(),
+	}, args)
+	require.NoError(t, err)
+
+	require.Len(t, cmp.manager.tasks, 1)
+	labels := cmp.manager.tasks[0].target.LabelsStr()
+	require.Contains(t, labels, `compose_project="shop"`)
+	require.Contains(t, labels, `compose_service="frontend"`)
+	require.Contains(t, labels, `compose_container_number="1"`)
+	require.Contains(t, labels, `com_example_team="payments"`)
+	require.NotContains(t, labels, "com_example_missing")
+
+	// Without a project filter, containers outside of a Compose project are
+	// tailed too.
+	args.ComposeProjects = nil
+	require.NoError(t, cmp.Update(args))
+	require.Len(t, cmp.manager.tasks, 3)
+}
+
 func TestRestart(t *testing.T) {
 	runningState := true
 	client := clientMock{
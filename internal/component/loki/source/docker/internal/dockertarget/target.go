@@ -26,6 +26,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -289,7 +290,7 @@ func (t *Target) getStreamLabels(logStream string) model.LabelSet {
 		lb.Set(string(k), string(v))
 	}
 	lb.Set(dockerLabelLogStream, logStream)
-	processed, _ := relabel.Process(lb.Labels(), t.relabelConfig...)
+	processed, _ := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
 
 	filtered := make(model.LabelSet)
 	for _, lbl := range processed {
@@ -3,9 +3,6 @@ package file
 import (
 	"encoding"
 	"fmt"
-	"strings"
-
-	"golang.org/x/exp/maps"
 )
 
 type CompressionFormat string
@@ -32,7 +29,7 @@ func (ut *CompressionFormat) UnmarshalText(text []byte) error {
 		return fmt.Errorf(
 			"unsupported compression format: %q - please use one of %q",
 			s,
-			strings.Join(maps.Keys(supportedCompressedFormats()), ", "),
+			supportedCompressedFormatsList(),
 		)
 	}
 	*ut = CompressionFormat(s)
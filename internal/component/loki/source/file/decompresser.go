@@ -6,6 +6,7 @@ package file
 
 import (
 	"bufio"
+	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
 	"compress/zlib"
@@ -13,6 +14,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,26 +22,68 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/common/model"
+	"github.com/ulikunitz/xz"
 	"go.uber.org/atomic"
-	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/ianaindex"
-	"golang.org/x/text/transform"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/positions"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
+// formatAuto detects the compression format of each file from its first bytes.
+const formatAuto = "auto"
+
 func supportedCompressedFormats() map[string]struct{} {
 	return map[string]struct{}{
-		"gz":  {},
-		"z":   {},
-		"bz2": {},
+		"gz":       {},
+		"z":        {},
+		"bz2":      {},
+		"zst":      {},
+		"xz":       {},
+		formatAuto: {},
 		// TODO: add support for zip.
 	}
 }
 
+// supportedCompressedFormatsList returns the supported formats, sorted and separated by commas.
+func supportedCompressedFormatsList() string {
+	formats := make([]string, 0, len(supportedCompressedFormats()))
+	for format := range supportedCompressedFormats() {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return strings.Join(formats, ", ")
+}
+
+// magicNumbers maps the compression formats to the bytes their files start with.
+var magicNumbers = []struct {
+	format string
+	magic  []byte
+}{
+	{"gz", []byte{0x1f, 0x8b}},
+	{"bz2", []byte("BZh")},
+	{"zst", []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+}
+
+// detectFormat returns the compression format of a file from its first bytes, or an empty string if it isn't
+// recognized.
+func detectFormat(header []byte) string {
+	for _, m := range magicNumbers {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.format
+		}
+	}
+	// zlib streams have no magic number, but a two bytes header with the deflate compression method which is a
+	// multiple of 31.
+	if len(header) >= 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return "z"
+	}
+	return ""
+}
+
 type decompressor struct {
 	metrics   *metrics
 	logger    log.Logger
@@ -57,7 +101,7 @@ type decompressor struct {
 	posdone chan struct{}
 	done    chan struct{}
 
-	decoder *encoding.Decoder
+	decoder *lineDecoder
 
 	position int64
 	size     int64
@@ -82,14 +126,13 @@ func newDecompressor(
 		return nil, fmt.Errorf("failed to get positions: %w", err)
 	}
 
-	var decoder *encoding.Decoder
+	var decoder *lineDecoder
 	if encodingFormat != "" {
 		level.Info(logger).Log("msg", "decompressor will decode messages", "from", encodingFormat, "to", "UTF8")
-		encoder, err := ianaindex.IANA.Encoding(encodingFormat)
+		decoder, err = newLineDecoder(encodingFormat)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get IANA encoding %s: %w", encodingFormat, err)
+			return nil, err
 		}
-		decoder = encoder.NewDecoder()
 	}
 
 	decompressor := &decompressor{
@@ -116,14 +159,26 @@ func newDecompressor(
 
 // mountReader instantiate a reader ready to be used by the decompressor.
 //
-// The reader implementation is selected based on the given CompressionFormat.
+// The reader implementation is selected based on the given CompressionFormat, or on the first bytes of the file if
+// the format is "auto".
 // If the actual file format is incorrect, the reading of the header may fail and return an error - depending on the
 // implementation of the underlying compression library. In any case, when a file is corrupted, the subsequent reading
 // of lines will fail.
-func mountReader(f *os.File, logger log.Logger, format CompressionFormat) (reader io.Reader, err error) {
+func mountReader(file *os.File, logger log.Logger, format CompressionFormat) (reader io.Reader, err error) {
 	var decompressLib string
 
-	switch format.String() {
+	f := bufio.NewReader(file)
+	formatName := format.String()
+	if formatName == formatAuto {
+		// A short or empty file returns an error along with the bytes it has, which are still worth checking.
+		header, _ := f.Peek(6)
+		formatName = detectFormat(header)
+		if formatName == "" {
+			return nil, fmt.Errorf("could not detect the compression format of file %q", file.Name())
+		}
+	}
+
+	switch formatName {
 	case "gz":
 		decompressLib = "compress/gzip"
 		reader, err = gzip.NewReader(f)
@@ -133,6 +188,16 @@ func mountReader(f *os.File, logger log.Logger, format CompressionFormat) (reade
 	case "bz2":
 		decompressLib = "bzip2"
 		reader = bzip2.NewReader(f)
+	case "zst":
+		decompressLib = "zstd"
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(f, zstd.WithDecoderConcurrency(1))
+		if err == nil {
+			reader = d.IOReadCloser()
+		}
+	case "xz":
+		decompressLib = "xz"
+		reader, err = xz.NewReader(f)
 	}
 
 	if err != nil && err != io.EOF {
@@ -140,14 +205,10 @@ func mountReader(f *os.File, logger log.Logger, format CompressionFormat) (reade
 	}
 
 	if reader == nil {
-		supportedFormatsList := strings.Builder{}
-		for format := range supportedCompressedFormats() {
-			supportedFormatsList.WriteString(format)
-		}
-		return nil, fmt.Errorf("file %q has unsupported format, it has to be one of %q", f.Name(), supportedFormatsList.String())
+		return nil, fmt.Errorf("file %q has unsupported format, it has to be one of %q", file.Name(), supportedCompressedFormatsList())
 	}
 
-	level.Debug(logger).Log("msg", fmt.Sprintf("using %q to decompress file %q", decompressLib, f.Name()))
+	level.Debug(logger).Log("msg", fmt.Sprintf("using %q to decompress file %q", decompressLib, file.Name()))
 	return reader, nil
 }
 
@@ -206,6 +267,9 @@ func (d *decompressor) readLines() {
 		level.Error(d.logger).Log("msg", "error mounting new reader", "err", err)
 		return
 	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	level.Info(d.logger).Log("msg", "successfully mounted reader", "path", d.path, "ext", filepath.Ext(d.path))
 
@@ -298,12 +362,7 @@ func (d *decompressor) IsRunning() bool {
 }
 
 func (d *decompressor) convertToUTF8(text string) (string, error) {
-	res, _, err := transform.String(d.decoder, text)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode text to UTF8: %w", err)
-	}
-
-	return res, nil
+	return d.decoder.decode(text)
 }
 
 // cleanupMetrics removes all metrics exported by this reader
@@ -4,7 +4,10 @@ package file
 // of the reader interface.
 
 import (
+	"bytes"
+	"compress/zlib"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -161,6 +164,56 @@ func TestOnelineFiles(t *testing.T) {
 		require.Equal(t, string(fileContent), entries[0].Line)
 	})
 
+	t.Run("zstd file", func(t *testing.T) {
+		file := "testdata/onelinelog.log.zst"
+		handler := fake.NewClient(func() {})
+		defer handler.Stop()
+
+		d := &decompressor{
+			logger:  log.NewNopLogger(),
+			running: atomic.NewBool(false),
+			handler: handler,
+			path:    file,
+			done:    make(chan struct{}),
+			metrics: newMetrics(prometheus.NewRegistry()),
+			cfg:     DecompressionConfig{Format: "zst"},
+		}
+
+		d.readLines()
+
+		<-d.done
+		time.Sleep(time.Millisecond * 200)
+
+		entries := handler.Received()
+		require.Equal(t, 1, len(entries))
+		require.Equal(t, string(fileContent), entries[0].Line)
+	})
+
+	t.Run("xz file", func(t *testing.T) {
+		file := "testdata/onelinelog.log.xz"
+		handler := fake.NewClient(func() {})
+		defer handler.Stop()
+
+		d := &decompressor{
+			logger:  log.NewNopLogger(),
+			running: atomic.NewBool(false),
+			handler: handler,
+			path:    file,
+			done:    make(chan struct{}),
+			metrics: newMetrics(prometheus.NewRegistry()),
+			cfg:     DecompressionConfig{Format: "xz"},
+		}
+
+		d.readLines()
+
+		<-d.done
+		time.Sleep(time.Millisecond * 200)
+
+		entries := handler.Received()
+		require.Equal(t, 1, len(entries))
+		require.Equal(t, string(fileContent), entries[0].Line)
+	})
+
 	t.Run("tar.gz file", func(t *testing.T) {
 		file := "testdata/onelinelog.tar.gz"
 		handler := fake.NewClient(func() {})
@@ -188,3 +241,59 @@ func TestOnelineFiles(t *testing.T) {
 		require.Contains(t, firstEntry.Line, `5.202.214.160 - - [26/Jan/2019:19:45:25 +0330] "GET / HTTP/1.1" 200 30975 "https://www.zanbil.ir/" "Mozilla/5.0 (Windows NT 6.2; WOW64; rv:21.0) Gecko/20100101 Firefox/21.0" "-"`)
 	})
 }
+
+// TestAutoDetectedFormats tests that the compression format of files is detected from their first bytes, regardless
+// of their name.
+func TestAutoDetectedFormats(t *testing.T) {
+	fileContent, err := os.ReadFile("testdata/onelinelog.log")
+	require.NoError(t, err)
+
+	for _, file := range []string{
+		"testdata/onelinelog.log.gz",
+		"testdata/onelinelog.log.bz2",
+		"testdata/onelinelog.log.zst",
+		"testdata/onelinelog.log.xz",
+	} {
+		t.Run(file, func(t *testing.T) {
+			// Copy the file without its extension, so that only its content tells its format.
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			path := filepath.Join(t.TempDir(), "onelinelog.log.1")
+			require.NoError(t, os.WriteFile(path, content, 0o644))
+
+			handler := fake.NewClient(func() {})
+			defer handler.Stop()
+
+			d := &decompressor{
+				logger:  log.NewNopLogger(),
+				running: atomic.NewBool(false),
+				handler: handler,
+				path:    path,
+				done:    make(chan struct{}),
+				metrics: newMetrics(prometheus.NewRegistry()),
+				cfg:     DecompressionConfig{Format: "auto"},
+			}
+
+			d.readLines()
+
+			<-d.done
+			time.Sleep(time.Millisecond * 200)
+
+			entries := handler.Received()
+			require.Equal(t, 1, len(entries))
+			require.Equal(t, string(fileContent), entries[0].Line)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	var zlibBuf bytes.Buffer
+	w := zlib.NewWriter(&zlibBuf)
+	_, err := w.Write([]byte("line"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "z", detectFormat(zlibBuf.Bytes()))
+	require.Equal(t, "", detectFormat([]byte("plain text log line")))
+	require.Equal(t, "", detectFormat(nil))
+}
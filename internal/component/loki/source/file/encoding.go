@@ -0,0 +1,77 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+// encodingLabel is the label of targets which overrides the encoding
+// argument of the component for that target.
+const encodingLabel = "__encoding__"
+
+// lineDecoder transcodes the lines read from a file to UTF-8.
+type lineDecoder struct {
+	decoder *encoding.Decoder
+
+	// Lines are split on the '\n' byte, while newlines are two bytes long in
+	// UTF-16. The other byte of the newline is left at the end of the line
+	// for big endian, or at the start of the next line for little endian, and
+	// must be removed before decoding.
+	utf16     bool
+	bigEndian bool
+}
+
+// newLineDecoder returns a lineDecoder for the encoding with the given IANA
+// name or alias, such as "UTF-16LE", "Shift_JIS" or "latin1".
+func newLineDecoder(name string) (*lineDecoder, error) {
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IANA encoding %s: %w", name, err)
+	}
+	// Some encodings are known to IANA, but not supported.
+	if enc == nil {
+		return nil, fmt.Errorf("unsupported encoding %s", name)
+	}
+
+	canonical, _ := ianaindex.IANA.Name(enc)
+	return &lineDecoder{
+		decoder:   enc.NewDecoder(),
+		utf16:     strings.HasPrefix(canonical, "UTF-16"),
+		bigEndian: canonical != "UTF-16LE",
+	}, nil
+}
+
+// validateEncoding checks that lines can be decoded from the encoding with
+// the given name.
+func validateEncoding(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := newLineDecoder(name)
+	return err
+}
+
+// decode transcodes a line to UTF-8.
+func (d *lineDecoder) decode(text string) (string, error) {
+	if d.utf16 && len(text)%2 == 1 {
+		if d.bigEndian && text[len(text)-1] == 0 {
+			text = text[:len(text)-1]
+		} else if !d.bigEndian && text[0] == 0 {
+			text = text[1:]
+		}
+	}
+
+	res, _, err := transform.String(d.decoder, text)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode text to UTF8: %w", err)
+	}
+	if d.utf16 {
+		// Windows newlines also leave a carriage return at the end of the line.
+		res = strings.TrimSuffix(res, "\r")
+	}
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestLineDecoder(t *testing.T) {
+	encode := func(t *testing.T, enc interface{ Bytes([]byte) ([]byte, error) }, s string) string {
+		b, err := enc.Bytes([]byte(s))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	tests := []struct {
+		encoding string
+		// line is the line as split on '\n' bytes.
+		line     string
+		expected string
+	}{
+		{
+			encoding: "UTF-16LE",
+			line:     encode(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(), "hello"),
+			expected: "hello",
+		},
+		{
+			// The second byte of the previous newline.
+			encoding: "utf-16le",
+			line:     "\x00" + encode(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(), "hello\r"),
+			expected: "hello",
+		},
+		{
+			// The first byte of the newline.
+			encoding: "UTF-16BE",
+			line:     encode(t, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder(), "hello\r") + "\x00",
+			expected: "hello",
+		},
+		{
+			encoding: "Shift_JIS",
+			line:     encode(t, japanese.ShiftJIS.NewEncoder(), "こんにちは"),
+			expected: "こんにちは",
+		},
+		{
+			encoding: "latin1",
+			line:     "caf\xe9",
+			expected: "café",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.encoding, func(t *testing.T) {
+			d, err := newLineDecoder(tc.encoding)
+			require.NoError(t, err)
+			actual, err := d.decode(tc.line)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValidateEncoding(t *testing.T) {
+	require.NoError(t, validateEncoding(""))
+	require.NoError(t, validateEncoding("windows-1252"))
+	require.ErrorContains(t, validateEncoding("klingon"), "failed to get IANA encoding")
+	require.ErrorContains(t, validateEncoding("UTF-32"), "unsupported encoding")
+}
@@ -32,8 +32,15 @@ func init() {
 }
 
 const (
-	pathLabel     = "__path__"
-	filenameLabel = "filename"
+	pathLabel          = "__path__"
+	startPositionLabel = "__start_position__"
+	filenameLabel      = "filename"
+)
+
+// Positions from which files without a stored position are read.
+const (
+	StartPositionBeginning = "beginning"
+	StartPositionEnd       = "end"
 )
 
 // Arguments holds values which are used to configure the loki.source.file
@@ -45,6 +52,8 @@ type Arguments struct {
 	DecompressionConfig DecompressionConfig `alloy:"decompression,block,optional"`
 	FileWatch           FileWatch           `alloy:"file_watch,block,optional"`
 	TailFromEnd         bool                `alloy:"tail_from_end,attr,optional"`
+	StartPosition       string              `alloy:"start_position,attr,optional"`
+	IgnoreOlderThan     time.Duration       `alloy:"ignore_older_than,attr,optional"`
 	LegacyPositionsFile string              `alloy:"legacy_positions_file,attr,optional"`
 }
 
@@ -58,6 +67,7 @@ var DefaultArguments = Arguments{
 		MinPollFrequency: 250 * time.Millisecond,
 		MaxPollFrequency: 250 * time.Millisecond,
 	},
+	StartPosition: StartPositionBeginning,
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -65,6 +75,27 @@ func (a *Arguments) SetToDefault() {
 	*a = DefaultArguments
 }
 
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if err := validateStartPosition(a.StartPosition); err != nil {
+		return err
+	}
+	if a.IgnoreOlderThan < 0 {
+		return fmt.Errorf("ignore_older_than must not be negative")
+	}
+	if err := validateEncoding(a.Encoding); err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+	return nil
+}
+
+func validateStartPosition(position string) error {
+	if position != StartPositionBeginning && position != StartPositionEnd {
+		return fmt.Errorf("invalid start position %q, must be %q or %q", position, StartPositionBeginning, StartPositionEnd)
+	}
+	return nil
+}
+
 type DecompressionConfig struct {
 	Enabled      bool              `alloy:"enabled,attr"`
 	InitialDelay time.Duration     `alloy:"initial_delay,attr,optional"`
@@ -217,8 +248,29 @@ func (c *Component) Update(args component.Arguments) error {
 
 		c.reportSize(path, labels.String())
 
+		// The start position of a target overrides the arguments of the component.
+		startPosition, ignoreOlderThan := c.args.StartPosition, c.args.IgnoreOlderThan
+		if c.args.TailFromEnd {
+			startPosition = StartPositionEnd
+		}
+		if position, ok := target[startPositionLabel]; ok {
+			if err := validateStartPosition(position); err != nil {
+				level.Warn(c.opts.Logger).Log("msg", "ignoring start position of target", "filename", path, "error", err)
+			} else {
+				startPosition, ignoreOlderThan = position, 0
+			}
+		}
+		encoding := c.args.Encoding
+		if enc, ok := target[encodingLabel]; ok {
+			if err := validateEncoding(enc); err != nil {
+				level.Warn(c.opts.Logger).Log("msg", "ignoring encoding of target", "filename", path, "error", err)
+			} else {
+				encoding = enc
+			}
+		}
+
 		handler := loki.AddLabelsMiddleware(labels).Wrap(loki.NewEntryHandler(c.handler.Chan(), func() {}))
-		reader, err := c.startTailing(path, labels, handler)
+		reader, err := c.startTailing(path, labels, handler, startPosition, ignoreOlderThan, encoding)
 		if err != nil {
 			continue
 		}
@@ -308,7 +360,11 @@ func missing(as map[positions.Entry]reader, bs map[positions.Entry]struct{}) map
 // startTailing starts and returns a reader for the given path. For most files,
 // this will be a tailer implementation. If the file suffix alludes to it being
 // a compressed file, then a decompressor will be started instead.
-func (c *Component) startTailing(path string, labels model.LabelSet, handler loki.EntryHandler) (reader, error) {
+//
+// Files without a stored position are tailed from startPosition, or from the
+// end if they haven't been modified for longer than a non-zero ignoreOlderThan.
+// Lines are transcoded from encoding to UTF-8 if encoding is set.
+func (c *Component) startTailing(path string, labels model.LabelSet, handler loki.EntryHandler, startPosition string, ignoreOlderThan time.Duration, encoding string) (reader, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
 		level.Error(c.opts.Logger).Log("msg", "failed to tail file, stat failed", "error", err, "filename", path)
@@ -332,7 +388,7 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			c.posFile,
 			path,
 			labels.String(),
-			c.args.Encoding,
+			encoding,
 			c.args.DecompressionConfig,
 		)
 		if err != nil {
@@ -346,6 +402,11 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			MinPollFrequency: c.args.FileWatch.MinPollFrequency,
 			MaxPollFrequency: c.args.FileWatch.MaxPollFrequency,
 		}
+		tailFromEnd := startPosition == StartPositionEnd
+		if ignoreOlderThan > 0 && time.Since(fi.ModTime()) > ignoreOlderThan {
+			level.Debug(c.opts.Logger).Log("msg", "ignoring existing content of old file", "filename", path, "modified", fi.ModTime())
+			tailFromEnd = true
+		}
 		tailer, err := newTailer(
 			c.metrics,
 			c.opts.Logger,
@@ -353,9 +414,9 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			c.posFile,
 			path,
 			labels.String(),
-			c.args.Encoding,
+			encoding,
 			pollOptions,
-			c.args.TailFromEnd,
+			tailFromEnd,
 		)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to start tailer", "error", err, "filename", path)
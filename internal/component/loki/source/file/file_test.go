@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/runtime/componenttest"
 	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
@@ -289,7 +290,7 @@ func TestEncoding(t *testing.T) {
 	select {
 	case logEntry := <-ch1.Chan():
 		require.WithinDuration(t, time.Now(), logEntry.Timestamp, 1*time.Second)
-		require.Equal(t, "hello world!�", logEntry.Line)
+		require.Equal(t, "hello world!", logEntry.Line)
 
 	case <-time.After(5 * time.Second):
 		require.FailNow(t, "failed waiting for log line")
@@ -314,3 +315,162 @@ func TestEncoding(t *testing.T) {
 		"expected positions.yml file to be written eventually",
 	)
 }
+
+func TestStartPosition(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+
+	// Every file has an existing line named after it, and files are either
+	// recently modified or stale.
+	createFile := func(name string, stale bool) *os.File {
+		f, err := os.Create(filepath.Join(opts.DataPath, name))
+		require.NoError(t, err)
+		_, err = f.WriteString(name + "-old\n")
+		require.NoError(t, err)
+		if stale {
+			modified := time.Now().Add(-2 * time.Hour)
+			require.NoError(t, os.Chtimes(f.Name(), modified, modified))
+		}
+		return f
+	}
+	recent := createFile("recent", false)
+	stale := createFile("stale", true)
+	replay := createFile("replay", true)
+	end := createFile("end", false)
+	for _, f := range []*os.File{recent, stale, replay, end} {
+		defer f.Close()
+	}
+
+	ch1 := loki.NewLogsReceiver()
+	args := DefaultArguments
+	args.StartPosition = StartPositionBeginning
+	args.IgnoreOlderThan = time.Hour
+	args.Targets = []discovery.Target{
+		{"__path__": recent.Name()},
+		{"__path__": stale.Name()},
+		{"__path__": replay.Name(), "__start_position__": "beginning"},
+		{"__path__": end.Name(), "__start_position__": "end"},
+	}
+	args.ForwardTo = []loki.LogsReceiver{ch1}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	for _, f := range []*os.File{recent, stale, replay, end} {
+		_, err = f.WriteString(filepath.Base(f.Name()) + "-new\n")
+		require.NoError(t, err)
+	}
+
+	expected := map[string]bool{
+		"recent-old": true, "recent-new": true,
+		"stale-new":  true,
+		"replay-old": true, "replay-new": true,
+		"end-new": true,
+	}
+	received := map[string]bool{}
+	for len(received) < len(expected) {
+		select {
+		case logEntry := <-ch1.Chan():
+			require.True(t, expected[logEntry.Line], "unexpected line %q", logEntry.Line)
+			received[logEntry.Line] = true
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log lines", "received %v", received)
+		}
+	}
+}
+
+func TestStartPositionValidation(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		targets        = []
+		forward_to     = []
+		start_position = "middle"
+	`), &args)
+	require.ErrorContains(t, err, `invalid start position "middle"`)
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets           = []
+		forward_to        = []
+		ignore_older_than = "24h"
+	`), &args))
+	require.Equal(t, StartPositionBeginning, args.StartPosition)
+	require.Equal(t, 24*time.Hour, args.IgnoreOlderThan)
+}
+
+func TestTargetEncoding(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+
+	// The files are encoded in UTF-16LE with Windows newlines, and in latin1.
+	utf16, err := os.Create(filepath.Join(opts.DataPath, "utf16"))
+	require.NoError(t, err)
+	defer utf16.Close()
+	latin1, err := os.Create(filepath.Join(opts.DataPath, "latin1"))
+	require.NoError(t, err)
+	defer latin1.Close()
+
+	ch1 := loki.NewLogsReceiver()
+	args := DefaultArguments
+	args.Encoding = "UTF-16LE"
+	args.Targets = []discovery.Target{
+		{"__path__": utf16.Name()},
+		{"__path__": latin1.Name(), "__encoding__": "latin1"},
+	}
+	args.ForwardTo = []loki.LogsReceiver{ch1}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	utf16Bytes, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte("première\r\ndeuxième\r\n"))
+	require.NoError(t, err)
+	_, err = utf16.Write(utf16Bytes)
+	require.NoError(t, err)
+	_, err = latin1.Write([]byte("caf\xe9\n"))
+	require.NoError(t, err)
+
+	expected := map[string]bool{"première": true, "deuxième": true, "café": true}
+	received := map[string]bool{}
+	for len(received) < len(expected) {
+		select {
+		case logEntry := <-ch1.Chan():
+			require.True(t, expected[logEntry.Line], "unexpected line %q", logEntry.Line)
+			received[logEntry.Line] = true
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log lines", "received %v", received)
+		}
+	}
+}
+
+func TestEncodingValidation(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		encoding   = "klingon"
+	`), &args)
+	require.ErrorContains(t, err, "invalid encoding")
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		encoding   = "shift_jis"
+	`), &args))
+}
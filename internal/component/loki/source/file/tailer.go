@@ -18,9 +18,6 @@ import (
 	"github.com/grafana/tail/watch"
 	"github.com/prometheus/common/model"
 	"go.uber.org/atomic"
-	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/ianaindex"
-	"golang.org/x/text/transform"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/positions"
@@ -46,7 +43,7 @@ type tailer struct {
 	posdone chan struct{}
 	done    chan struct{}
 
-	decoder *encoding.Decoder
+	decoder *lineDecoder
 }
 
 func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, positions positions.Positions, path string,
@@ -110,11 +107,10 @@ func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, p
 
 	if encoding != "" {
 		level.Info(tailer.logger).Log("msg", "Will decode messages", "from", encoding, "to", "UTF8")
-		encoder, err := ianaindex.IANA.Encoding(encoding)
+		decoder, err := newLineDecoder(encoding)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get IANA encoding %s: %w", encoding, err)
+			return nil, err
 		}
-		decoder := encoder.NewDecoder()
 		tailer.decoder = decoder
 	}
 
@@ -336,12 +332,7 @@ func (t *tailer) IsRunning() bool {
 }
 
 func (t *tailer) convertToUTF8(text string) (string, error) {
-	res, _, err := transform.String(t.decoder, text)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode text to UTF8: %w", err)
-	}
-
-	return res, nil
+	return t.decoder.decode(text)
 }
 
 // cleanupMetrics removes all metrics exported by this tailer
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/alecthomas/units"
+
 	fnet "github.com/grafana/alloy/internal/component/common/net"
 )
 
@@ -14,6 +16,48 @@ type PullConfig struct {
 	Labels               map[string]string `alloy:"labels,attr,optional"`
 	UseIncomingTimestamp bool              `alloy:"use_incoming_timestamp,attr,optional"`
 	UseFullLine          bool              `alloy:"use_full_line,attr,optional"`
+
+	// Flow control and ack deadline settings of the subscriber.
+	MaxOutstandingMessages int              `alloy:"max_outstanding_messages,attr,optional"`
+	MaxOutstandingBytes    units.Base2Bytes `alloy:"max_outstanding_bytes,attr,optional"`
+	MaxAckExtension        time.Duration    `alloy:"max_ack_extension,attr,optional"`
+	MaxAckExtensionPeriod  time.Duration    `alloy:"max_ack_extension_period,attr,optional"`
+
+	// ExactlyOnceDelivery confirms the acks of messages, for subscriptions
+	// with exactly-once delivery enabled. Messages which can't be forwarded
+	// within ForwardTimeout are nacked, so that Pub/Sub delivers them again.
+	ExactlyOnceDelivery bool          `alloy:"exactly_once_delivery,attr,optional"`
+	ForwardTimeout      time.Duration `alloy:"forward_timeout,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (p *PullConfig) SetToDefault() {
+	*p = PullConfig{
+		MaxOutstandingMessages: 1000,
+		MaxOutstandingBytes:    units.GiB,
+		MaxAckExtension:        time.Hour,
+	}
+}
+
+// Validate implements syntax.Validator.
+func (p *PullConfig) Validate() error {
+	if p.MaxOutstandingMessages < 1 {
+		return fmt.Errorf("max_outstanding_messages must be at least 1")
+	}
+	if p.MaxOutstandingBytes < 1 {
+		return fmt.Errorf("max_outstanding_bytes must be greater than zero")
+	}
+	if p.MaxAckExtension <= 0 {
+		return fmt.Errorf("max_ack_extension must be greater than zero")
+	}
+	// Pub/Sub extends ack deadlines by at most 10 minutes at a time.
+	if p.MaxAckExtensionPeriod < 0 || p.MaxAckExtensionPeriod > 10*time.Minute {
+		return fmt.Errorf("max_ack_extension_period must be between 0s and 10m")
+	}
+	if p.ForwardTimeout < 0 {
+		return fmt.Errorf("forward_timeout must not be negative")
+	}
+	return nil
 }
 
 // PushConfig configures a GCPLog target with the 'push' strategy.
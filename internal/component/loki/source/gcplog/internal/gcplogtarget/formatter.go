@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/prometheus/model/relabel"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 )
 
 // GCPLogEntry that will be written to the pubsub topic according to the following spec.
@@ -76,7 +77,7 @@ func parseGCPLogsEntry(data []byte, other model.LabelSet, otherInternal labels.L
 
 	// apply relabeling
 	if len(relabelConfig) > 0 {
-		processed, _ = relabel.Process(lbs.Labels(), relabelConfig...)
+		processed, _ = alloy_relabel.Process(lbs.Labels(), relabelConfig...)
 	} else {
 		processed = lbs.Labels()
 	}
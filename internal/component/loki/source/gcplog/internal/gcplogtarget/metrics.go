@@ -15,6 +15,8 @@ type Metrics struct {
 	gcplogEntries                 *prometheus.CounterVec
 	gcplogErrors                  *prometheus.CounterVec
 	gcplogTargetLastSuccessScrape *prometheus.GaugeVec
+	gcplogNacked                  *prometheus.CounterVec
+	gcplogAckErrors               *prometheus.CounterVec
 
 	gcpPushEntries *prometheus.CounterVec
 	gcpPushErrors  *prometheus.CounterVec
@@ -41,6 +43,16 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		Help: "Timestamp of target's last successful poll",
 	}, []string{"project", "target"})
 
+	m.gcplogNacked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_source_gcplog_pull_nacked_messages_total",
+		Help: "Number of messages nacked because they couldn't be forwarded, by reason",
+	}, []string{"project", "reason"})
+
+	m.gcplogAckErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_source_gcplog_pull_ack_errors_total",
+		Help: "Number of acks that failed with exactly-once delivery",
+	}, []string{"project"})
+
 	// Push subscription metrics
 	m.gcpPushEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "loki_source_gcplog_push_entries_total",
@@ -56,6 +68,8 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.gcplogEntries,
 		m.gcplogErrors,
 		m.gcplogTargetLastSuccessScrape,
+		m.gcplogNacked,
+		m.gcplogAckErrors,
 		m.gcpPushEntries,
 		m.gcpPushErrors,
 	)
@@ -76,7 +76,7 @@ func NewPullTarget(metrics *Metrics, logger log.Logger, handler loki.EntryHandle
 		ctx:           ctx,
 		cancel:        cancel,
 		ps:            ps,
-		sub:           ps.SubscriptionInProject(config.Subscription, config.ProjectID),
+		sub:           newSubscription(ps, config),
 		backoff:       backoff.New(ctx, defaultBackoff),
 		msgs:          make(chan *pubsub.Message),
 	}
@@ -91,6 +91,17 @@ func NewPullTarget(metrics *Metrics, logger log.Logger, handler loki.EntryHandle
 	return target, nil
 }
 
+// newSubscription returns the subscription of config, with its flow control
+// and ack deadline settings.
+func newSubscription(ps *pubsub.Client, config *gcptypes.PullConfig) *pubsub.Subscription {
+	sub := ps.SubscriptionInProject(config.Subscription, config.ProjectID)
+	sub.ReceiveSettings.MaxOutstandingMessages = config.MaxOutstandingMessages
+	sub.ReceiveSettings.MaxOutstandingBytes = int(config.MaxOutstandingBytes)
+	sub.ReceiveSettings.MaxExtension = config.MaxAckExtension
+	sub.ReceiveSettings.MaxExtensionPeriod = config.MaxAckExtensionPeriod
+	return sub
+}
+
 func (t *PullTarget) run() error {
 	t.wg.Add(1)
 	defer t.wg.Done()
@@ -110,16 +121,61 @@ func (t *PullTarget) run() error {
 			entry, err := parseGCPLogsEntry(m.Data, lbls, nil, t.config.UseIncomingTimestamp, t.config.UseFullLine, t.relabelConfig)
 			if err != nil {
 				level.Error(t.logger).Log("event", "error formating log entry", "cause", err)
-				m.Ack()
+				t.ack(m)
 				break
 			}
-			t.handler.Chan() <- entry
-			m.Ack() // Ack only after log is sent.
+			if reason := t.forward(entry); reason != "" {
+				// Nack the message so that Pub/Sub redelivers it, instead of
+				// waiting for its ack deadline to expire.
+				m.Nack()
+				t.metrics.gcplogNacked.WithLabelValues(t.config.ProjectID, reason).Inc()
+				break
+			}
+			t.ack(m) // Ack only after log is sent.
 			t.metrics.gcplogEntries.WithLabelValues(t.config.ProjectID).Inc()
 		}
 	}
 }
 
+// forward sends entry to the handler. It returns the reason the entry
+// couldn't be sent, or an empty string if it was.
+func (t *PullTarget) forward(entry loki.Entry) string {
+	var timeout <-chan time.Time
+	if t.config.ForwardTimeout > 0 {
+		timer := time.NewTimer(t.config.ForwardTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case t.handler.Chan() <- entry:
+		return ""
+	case <-timeout:
+		return "forward_timeout"
+	case <-t.ctx.Done():
+		return "shutdown"
+	}
+}
+
+// ack acks m. With exactly-once delivery, the ack is confirmed in the
+// background, and a failed ack means Pub/Sub will redeliver the message.
+func (t *PullTarget) ack(m *pubsub.Message) {
+	if !t.config.ExactlyOnceDelivery {
+		m.Ack()
+		return
+	}
+
+	res := m.AckWithResult()
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		if _, err := res.Get(t.ctx); err != nil && t.ctx.Err() == nil {
+			level.Warn(t.logger).Log("msg", "failed to ack pubsub message", "id", m.ID, "err", err)
+			t.metrics.gcplogAckErrors.WithLabelValues(t.config.ProjectID).Inc()
+		}
+	}()
+}
+
 func (t *PullTarget) consumeSubscription() {
 	// NOTE(kavi): `cancel` the context as exiting from this goroutine should stop main `run` loop
 	// It makesense as no more messages will be received.
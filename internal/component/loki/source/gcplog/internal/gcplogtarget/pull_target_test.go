@@ -11,9 +11,11 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/backoff"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"gotest.tools/assert"
 
+	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/client/fake"
 	"github.com/grafana/alloy/internal/component/loki/source/gcplog/gcptypes"
 )
@@ -87,6 +89,41 @@ func TestPullTarget_RunStop(t *testing.T) {
 	})
 }
 
+func TestPullTarget_ForwardTimeout(t *testing.T) {
+	tc := testPullTarget(t)
+	cfg := *testConfig
+	cfg.ForwardTimeout = 10 * time.Millisecond
+	cfg.ExactlyOnceDelivery = true
+	tc.target.config = &cfg
+	// Nothing reads from the handler, so forwarding entries times out.
+	tc.target.handler = loki.NewEntryHandler(make(chan loki.Entry), func() {})
+
+	go func() { _ = tc.target.run() }()
+
+	tc.sub.messages <- &pubsub.Message{Data: []byte(gcpLogEntry)}
+	tc.sub.messages <- &pubsub.Message{Data: []byte(gcpLogEntry)}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(tc.target.metrics.gcplogNacked.WithLabelValues(project, "forward_timeout")) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 0.0, testutil.ToFloat64(tc.target.metrics.gcplogEntries.WithLabelValues(project)))
+
+	require.NoError(t, tc.target.Stop())
+	require.Equal(t, 0.0, testutil.ToFloat64(tc.target.metrics.gcplogAckErrors.WithLabelValues(project)))
+}
+
+func TestPullConfig_Validate(t *testing.T) {
+	var cfg gcptypes.PullConfig
+	cfg.SetToDefault()
+	require.NoError(t, cfg.Validate())
+
+	cfg.MaxAckExtensionPeriod = 11 * time.Minute
+	require.EqualError(t, cfg.Validate(), "max_ack_extension_period must be between 0s and 10m")
+
+	cfg.SetToDefault()
+	cfg.MaxOutstandingMessages = 0
+	require.EqualError(t, cfg.Validate(), "max_outstanding_messages must be at least 1")
+}
+
 // func TestPullTarget_Ready(t *testing.T) {
 // 	tc := testPullTarget(t)
 // 	assert.Equal(t, true, tc.target.Ready())
@@ -21,6 +21,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	fnet "github.com/grafana/alloy/internal/component/common/net"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -105,7 +106,7 @@ func (h *HerokuTarget) drain(w http.ResponseWriter, r *http.Request) {
 			lb.Set(ReservedLabelTenantID, tenantIDHeaderValue)
 		}
 
-		processed, _ := relabel.Process(lb.Labels(), h.relabelConfigs...)
+		processed, _ := alloy_relabel.Process(lb.Labels(), h.relabelConfigs...)
 
 		// Start with the set of labels fixed in the configuration
 		filtered := h.Labels().Clone()
@@ -0,0 +1,126 @@
+package kafkatarget
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// MSK IAM tokens are presigned kafka-cluster:Connect requests, encoded in
+// base64. This follows https://github.com/aws/aws-msk-iam-sasl-signer-go.
+const (
+	mskIAMSigningName = "kafka-cluster"
+	mskIAMAction      = "kafka-cluster:Connect"
+	mskIAMUserAgent   = "grafana-alloy"
+	mskIAMTokenExpiry = 15 * time.Minute
+
+	// emptyPayloadHash is the SHA-256 hash of an empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// AWSMSKIAMConfig configures the credentials used to sign MSK IAM tokens.
+type AWSMSKIAMConfig struct {
+	// Region of the MSK cluster. Defaults to the region of the AWS
+	// environment.
+	Region string
+
+	// RoleARN is the role to assume. If empty, the credentials of the AWS
+	// environment are used directly.
+	RoleARN string
+
+	// RoleSessionName is the session name used when assuming RoleARN.
+	RoleSessionName string
+}
+
+// TokenProviderAWSMSKIAM implements sarama.AccessTokenProvider for Amazon MSK
+// clusters using IAM access control.
+type TokenProviderAWSMSKIAM struct {
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	now         func() time.Time
+}
+
+func newAWSMSKIAMTokenProvider(cfg AWSMSKIAMConfig) (*TokenProviderAWSMSKIAM, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return nil, fmt.Errorf("the region of the MSK cluster must be set")
+	}
+
+	credentials := awsCfg.Credentials
+	if cfg.RoleARN != "" {
+		credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if cfg.RoleSessionName != "" {
+				o.RoleSessionName = cfg.RoleSessionName
+			}
+		}))
+	}
+
+	return &TokenProviderAWSMSKIAM{
+		region:      awsCfg.Region,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		now:         time.Now,
+	}, nil
+}
+
+// Token returns a new *sarama.AccessToken or an error
+func (t *TokenProviderAWSMSKIAM) Token() (*sarama.AccessToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	creds, err := t.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	query := url.Values{
+		"Action":        {mskIAMAction},
+		"X-Amz-Expires": {strconv.Itoa(int(mskIAMTokenExpiry.Seconds()))},
+	}
+	endpoint := url.URL{
+		Scheme:   "https",
+		Host:     fmt.Sprintf("kafka.%s.amazonaws.com", t.region),
+		Path:     "/",
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, _, err := t.signer.PresignHTTP(ctx, creds, req, emptyPayloadHash, mskIAMSigningName, t.region, t.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign MSK IAM token: %w", err)
+	}
+	signedURL, err := url.Parse(signed)
+	if err != nil {
+		return nil, err
+	}
+	signedQuery := signedURL.Query()
+	signedQuery.Add("User-Agent", mskIAMUserAgent)
+	signedURL.RawQuery = signedQuery.Encode()
+
+	return &sarama.AccessToken{Token: base64.RawURLEncoding.EncodeToString([]byte(signedURL.String()))}, nil
+}
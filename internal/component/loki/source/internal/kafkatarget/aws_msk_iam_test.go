@@ -0,0 +1,59 @@
+package kafkatarget
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenProviderAWSMSKIAM(t *testing.T) {
+	signingTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	provider := &TokenProviderAWSMSKIAM{
+		region:      "eu-west-1",
+		credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", "session"),
+		signer:      v4.NewSigner(),
+		now:         func() time.Time { return signingTime },
+	}
+
+	token, err := provider.Token()
+	require.NoError(t, err)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token.Token)
+	require.NoError(t, err)
+	u, err := url.Parse(string(decoded))
+	require.NoError(t, err)
+
+	require.Equal(t, "https", u.Scheme)
+	require.Equal(t, "kafka.eu-west-1.amazonaws.com", u.Host)
+	query := u.Query()
+	require.Equal(t, "kafka-cluster:Connect", query.Get("Action"))
+	require.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+	require.Equal(t, "AKIDEXAMPLE/20240102/eu-west-1/kafka-cluster/aws4_request", query.Get("X-Amz-Credential"))
+	require.Equal(t, "20240102T030405Z", query.Get("X-Amz-Date"))
+	require.Equal(t, "900", query.Get("X-Amz-Expires"))
+	require.Equal(t, "session", query.Get("X-Amz-Security-Token"))
+	require.NotEmpty(t, query.Get("X-Amz-Signature"))
+	require.Equal(t, mskIAMUserAgent, query.Get("User-Agent"))
+}
+
+func TestTokenProviderAWSMSKIAM_CredentialsError(t *testing.T) {
+	provider := &TokenProviderAWSMSKIAM{
+		region: "eu-west-1",
+		credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, errors.New("no credentials")
+		}),
+		signer: v4.NewSigner(),
+		now:    time.Now,
+	}
+
+	_, err := provider.Token()
+	require.ErrorContains(t, err, "failed to retrieve AWS credentials: no credentials")
+}
@@ -76,6 +76,9 @@ type TokenProviderType string
 const (
 	// TokenProviderTypeAzure represents using the Azure as the token provider
 	TokenProviderTypeAzure TokenProviderType = "azure"
+	// TokenProviderTypeAWSMSKIAM represents using AWS credentials to sign
+	// tokens for Amazon MSK clusters using IAM access control
+	TokenProviderTypeAWSMSKIAM TokenProviderType = "aws_msk_iam"
 )
 
 // KafkaSASLConfig describe the SASL configuration for authentication with Kafka brokers
@@ -104,6 +107,9 @@ type OAuthConfig struct {
 	TokenProvider TokenProviderType `yaml:"token_provider,omitempty"`
 
 	Scopes []string
+
+	// AWSMSKIAM is used when TokenProvider is TokenProviderTypeAWSMSKIAM
+	AWSMSKIAM AWSMSKIAMConfig `yaml:"aws_msk_iam,omitempty"`
 }
 
 // MessageParser defines parsing for each incoming message
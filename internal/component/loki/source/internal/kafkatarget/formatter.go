@@ -10,13 +10,15 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 )
 
 func format(lbs labels.Labels, cfg []*relabel.Config) model.LabelSet {
 	if len(lbs) == 0 {
 		return nil
 	}
-	processed, _ := relabel.Process(lbs, cfg...)
+	processed, _ := alloy_relabel.Process(lbs, cfg...)
 	labelOut := model.LabelSet(LabelsToMetric(processed))
 	for k := range labelOut {
 		if strings.HasPrefix(string(k), "__") {
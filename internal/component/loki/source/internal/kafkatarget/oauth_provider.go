@@ -19,6 +19,8 @@ func NewOAuthProvider(opts OAuthConfig) (sarama.AccessTokenProvider, error) {
 			return nil, err
 		}
 		return &TokenProviderAzure{tokenProvider: cred, scopes: opts.Scopes}, nil
+	case TokenProviderTypeAWSMSKIAM:
+		return newAWSMSKIAMTokenProvider(opts.AWSMSKIAM)
 	default:
 		return nil, fmt.Errorf("token provider '%s' is not supported", opts.TokenProvider)
 	}
@@ -0,0 +1,256 @@
+package sockettarget
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// maxDatagramSize is the size of the buffer UDP packets are read into.
+const maxDatagramSize = 64 * 1024
+
+// TCPConfig configures a TCP listener.
+type TCPConfig struct {
+	Config
+
+	// IdleTimeout closes connections which haven't sent anything for this
+	// duration. Connections are never closed if it's 0.
+	IdleTimeout time.Duration
+	// TLSConfig enables TLS if it's not nil.
+	TLSConfig *tls.Config
+}
+
+// listener holds what's shared by the TCP and UDP listeners.
+type listener struct {
+	logger  log.Logger
+	metrics *Metrics
+	handler loki.EntryHandler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newListener(logger log.Logger, metrics *Metrics, handler loki.EntryHandler) listener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return listener{
+		logger:  logger,
+		metrics: metrics,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// send sends an entry to the handler, unless the listener is stopped.
+func (l *listener) send(e loki.Entry) {
+	select {
+	case l.handler.Chan() <- e:
+		l.metrics.entries.Inc()
+	case <-l.ctx.Done():
+	}
+}
+
+// TCPListener reads log lines from TCP connections.
+type TCPListener struct {
+	listener
+	cfg TCPConfig
+	ln  net.Listener
+
+	connsMut sync.Mutex
+	conns    map[net.Conn]struct{}
+}
+
+// NewTCPListener starts listening for TCP connections on cfg.Address. Stop
+// must be called to close the listener.
+func NewTCPListener(logger log.Logger, metrics *Metrics, handler loki.EntryHandler, cfg TCPConfig) (*TCPListener, error) {
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TLSConfig != nil {
+		ln = tls.NewListener(ln, cfg.TLSConfig)
+	}
+
+	l := &TCPListener{
+		listener: newListener(logger, metrics, handler),
+		cfg:      cfg,
+		ln:       ln,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	l.wg.Add(1)
+	go l.acceptConnections()
+	level.Info(logger).Log("msg", "listening for TCP connections", "address", ln.Addr())
+	return l, nil
+}
+
+// Addr returns the address the listener listens on.
+func (l *TCPListener) Addr() net.Addr { return l.ln.Addr() }
+
+func (l *TCPListener) acceptConnections() {
+	defer l.wg.Done()
+
+	for {
+		conn, err := l.ln.Accept()
+		if l.ctx.Err() != nil {
+			return
+		} else if err != nil {
+			level.Warn(l.logger).Log("msg", "failed to accept TCP connection", "err", err)
+			l.metrics.errors.WithLabelValues("accept").Inc()
+			continue
+		}
+
+		// Stop closes the connections after canceling the context, so the
+		// context is checked again with the lock held.
+		l.connsMut.Lock()
+		if l.ctx.Err() != nil {
+			l.connsMut.Unlock()
+			_ = conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.connsMut.Unlock()
+
+		l.wg.Add(1)
+		go l.handleConnection(conn)
+	}
+}
+
+func (l *TCPListener) handleConnection(conn net.Conn) {
+	defer l.wg.Done()
+	defer func() {
+		l.connsMut.Lock()
+		delete(l.conns, conn)
+		l.connsMut.Unlock()
+		_ = conn.Close()
+		l.metrics.connections.Dec()
+	}()
+	l.metrics.connections.Inc()
+
+	var ip string
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		ip = addr.IP.String()
+	}
+
+	err := readLines(&idleTimeoutConn{Conn: conn, timeout: l.cfg.IdleTimeout}, l.cfg.Config, func(line string) {
+		l.send(newEntry(l.cfg.Config, ip, line))
+	})
+	if err != nil && l.ctx.Err() == nil {
+		reason := "read"
+		if errors.Is(err, errMessageTooLong) {
+			reason = "message_too_long"
+		}
+		l.metrics.errors.WithLabelValues(reason).Inc()
+		level.Warn(l.logger).Log("msg", "closing TCP connection after error", "remote", conn.RemoteAddr(), "err", err)
+	}
+}
+
+// Stop closes the listener and all of its connections, and waits for them to
+// be handled.
+func (l *TCPListener) Stop() {
+	l.cancel()
+	_ = l.ln.Close()
+
+	l.connsMut.Lock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.connsMut.Unlock()
+
+	l.wg.Wait()
+}
+
+// idleTimeoutConn extends the read deadline of a connection before each read.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	if c.timeout > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(b)
+}
+
+// UDPListener reads log lines from UDP packets. Each packet holds one or more
+// complete log lines.
+type UDPListener struct {
+	listener
+	cfg  Config
+	conn net.PacketConn
+}
+
+// NewUDPListener starts listening for UDP packets on cfg.Address. Stop must
+// be called to close the listener.
+func NewUDPListener(logger log.Logger, metrics *Metrics, handler loki.EntryHandler, cfg Config) (*UDPListener, error) {
+	conn, err := net.ListenPacket("udp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &UDPListener{
+		listener: newListener(logger, metrics, handler),
+		cfg:      cfg,
+		conn:     conn,
+	}
+	l.wg.Add(1)
+	go l.readPackets()
+	level.Info(logger).Log("msg", "listening for UDP packets", "address", conn.LocalAddr())
+	return l, nil
+}
+
+// Addr returns the address the listener listens on.
+func (l *UDPListener) Addr() net.Addr { return l.conn.LocalAddr() }
+
+func (l *UDPListener) readPackets() {
+	defer l.wg.Done()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := l.conn.ReadFrom(buf)
+		if l.ctx.Err() != nil {
+			return
+		} else if err != nil {
+			level.Warn(l.logger).Log("msg", "failed to read UDP packet", "err", err)
+			l.metrics.errors.WithLabelValues("read").Inc()
+			continue
+		}
+
+		var ip string
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			ip = udpAddr.IP.String()
+		}
+
+		// Lines are sent before reading the next packet, so buf can be reused.
+		err = readLines(bytes.NewReader(buf[:n]), l.cfg, func(line string) {
+			l.send(newEntry(l.cfg, ip, line))
+		})
+		if err != nil {
+			reason := "read"
+			if errors.Is(err, errMessageTooLong) {
+				reason = "message_too_long"
+			}
+			l.metrics.errors.WithLabelValues(reason).Inc()
+			level.Warn(l.logger).Log("msg", "failed to read log lines of UDP packet", "remote", addr, "err", err)
+		}
+	}
+}
+
+// Stop closes the listener and waits for the packet being read to be handled.
+func (l *UDPListener) Stop() {
+	l.cancel()
+	_ = l.conn.Close()
+	l.wg.Wait()
+}
@@ -0,0 +1,39 @@
+package sockettarget
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics of a listener.
+type Metrics struct {
+	entries     prometheus.Counter
+	errors      *prometheus.CounterVec
+	connections prometheus.Gauge
+}
+
+// NewMetrics creates the metrics of a listener, whose names start with
+// namespace, and registers them with reg. The connections metric is only
+// registered if withConnections is true.
+func NewMetrics(reg prometheus.Registerer, namespace string, withConnections bool) *Metrics {
+	var m Metrics
+
+	m.entries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "entries_total",
+		Help:      "Total number of log lines received.",
+	})
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "errors_total",
+		Help:      "Total number of errors while reading log lines.",
+	}, []string{"reason"})
+	m.connections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connections",
+		Help:      "Number of open connections.",
+	})
+
+	reg.MustRegister(m.entries, m.errors)
+	if withConnections {
+		reg.MustRegister(m.connections)
+	}
+	return &m
+}
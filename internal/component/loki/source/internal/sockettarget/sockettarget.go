@@ -0,0 +1,156 @@
+// Package sockettarget implements listeners which read raw log lines from TCP
+// connections and UDP packets. It's shared by the loki.source.tcp and
+// loki.source.udp components.
+package sockettarget
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+// Framings of the log lines.
+const (
+	// FramingNewline separates log lines with a line feed. A trailing carriage
+	// return is removed.
+	FramingNewline = "newline"
+	// FramingOctetCounting prefixes each log line with its length in bytes, as
+	// a decimal number followed by a space, as described in RFC 6587.
+	FramingOctetCounting = "octet_counting"
+)
+
+// Defaults of the listeners.
+const (
+	DefaultMaxMessageLength = 8192
+	DefaultIdleTimeout      = 120 * time.Second
+)
+
+// Config configures a listener.
+type Config struct {
+	// Address to listen on.
+	Address string
+	// Framing of the log lines, FramingNewline or FramingOctetCounting.
+	Framing string
+	// MaxMessageLength is the maximum length of a log line in bytes. Longer
+	// lines are truncated with the newline framing, and close the connection
+	// with the octet counting framing.
+	MaxMessageLength int
+	// Labels to add to every entry.
+	Labels model.LabelSet
+	// SourceIPLabel is the name of the label set to the IP address of the
+	// sender. No label is set if it's empty.
+	SourceIPLabel model.LabelName
+}
+
+// ValidateFraming returns an error if framing isn't supported.
+func ValidateFraming(framing string) error {
+	switch framing {
+	case FramingNewline, FramingOctetCounting:
+		return nil
+	default:
+		return fmt.Errorf("framing must be %q or %q, got %q", FramingNewline, FramingOctetCounting, framing)
+	}
+}
+
+// errMessageTooLong is returned for octet counted log lines which are longer
+// than the maximum length.
+var errMessageTooLong = errors.New("log line exceeds max_message_length")
+
+// readLines reads the log lines of r with the framing of cfg, and calls fn
+// with each of them until r returns an error. It returns nil at the end of r.
+func readLines(r io.Reader, cfg Config, fn func(line string)) error {
+	br := bufio.NewReaderSize(r, cfg.MaxMessageLength+1)
+	if cfg.Framing == FramingOctetCounting {
+		return readOctetCounted(br, cfg.MaxMessageLength, fn)
+	}
+	return readNewlines(br, cfg.MaxMessageLength, fn)
+}
+
+func readNewlines(br *bufio.Reader, maxLength int, fn func(line string)) error {
+	for {
+		line, err := readLine(br, maxLength)
+		if len(line) > 0 {
+			fn(line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// readLine reads a line of at most maxLength bytes. The rest of longer lines
+// is discarded.
+func readLine(br *bufio.Reader, maxLength int) (string, error) {
+	var (
+		line      []byte
+		truncated bool
+	)
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !truncated {
+			if remaining := maxLength - len(line); len(chunk) > remaining {
+				line = append(line, chunk[:remaining]...)
+				truncated = true
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		return string(bytes.TrimRight(line, "\r\n")), err
+	}
+}
+
+func readOctetCounted(br *bufio.Reader, maxLength int, fn func(line string)) error {
+	for {
+		prefix, err := br.ReadString(' ')
+		if errors.Is(err, io.EOF) && prefix == "" {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		length, err := strconv.Atoi(prefix[:len(prefix)-1])
+		if err != nil || length < 0 {
+			return fmt.Errorf("invalid octet count %q", prefix[:len(prefix)-1])
+		} else if length > maxLength {
+			return errMessageTooLong
+		}
+
+		line := make([]byte, length)
+		if _, err := io.ReadFull(br, line); err != nil {
+			return err
+		}
+		fn(string(line))
+	}
+}
+
+// newEntry returns an entry for a log line sent from ip.
+func newEntry(cfg Config, ip string, line string) loki.Entry {
+	labels := cfg.Labels.Clone()
+	if labels == nil {
+		labels = make(model.LabelSet, 1)
+	}
+	if cfg.SourceIPLabel != "" && ip != "" {
+		labels[cfg.SourceIPLabel] = model.LabelValue(ip)
+	}
+	return loki.Entry{
+		Labels: labels,
+		Entry: logproto.Entry{
+			Timestamp: time.Now(),
+			Line:      line,
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package sockettarget
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+func TestReadLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		framing   string
+		input     string
+		maxLength int
+		expected  []string
+		err       string
+	}{
+		{
+			name:      "newline",
+			framing:   FramingNewline,
+			input:     "first\nsecond\r\n\nthird",
+			maxLength: 100,
+			expected:  []string{"first", "second", "third"},
+		},
+		{
+			name:      "newline truncated",
+			framing:   FramingNewline,
+			input:     "0123456789\nabc\n",
+			maxLength: 4,
+			expected:  []string{"0123", "abc"},
+		},
+		{
+			name:      "octet counting",
+			framing:   FramingOctetCounting,
+			input:     "5 hello11 hello\nworld0 ",
+			maxLength: 100,
+			expected:  []string{"hello", "hello\nworld", ""},
+		},
+		{
+			name:      "octet counting too long",
+			framing:   FramingOctetCounting,
+			input:     "5 hello11 hello world",
+			maxLength: 10,
+			expected:  []string{"hello"},
+			err:       errMessageTooLong.Error(),
+		},
+		{
+			name:      "octet counting invalid count",
+			framing:   FramingOctetCounting,
+			input:     "abc hello",
+			maxLength: 10,
+			err:       `invalid octet count "abc"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var lines []string
+			err := readLines(strings.NewReader(tc.input), Config{Framing: tc.framing, MaxMessageLength: tc.maxLength}, func(line string) {
+				lines = append(lines, line)
+			})
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, lines)
+		})
+	}
+}
+
+func receive(t *testing.T, ch chan loki.Entry, n int) []loki.Entry {
+	t.Helper()
+
+	var entries []loki.Entry
+	for len(entries) < n {
+		select {
+		case e := <-ch:
+			entries = append(entries, e)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for entries")
+		}
+	}
+	return entries
+}
+
+func TestTCPListener(t *testing.T) {
+	ch := make(chan loki.Entry)
+	l, err := NewTCPListener(log.NewNopLogger(), NewMetrics(prometheus.NewRegistry(), "test", true), loki.NewEntryHandler(ch, func() {}), TCPConfig{
+		Config: Config{
+			Address:          "127.0.0.1:0",
+			Framing:          FramingNewline,
+			MaxMessageLength: DefaultMaxMessageLength,
+			Labels:           model.LabelSet{"job": "appliance"},
+			SourceIPLabel:    "source_ip",
+		},
+	})
+	require.NoError(t, err)
+	defer l.Stop()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello\nworld\n"))
+	require.NoError(t, err)
+
+	entries := receive(t, ch, 2)
+	require.Equal(t, "hello", entries[0].Line)
+	require.Equal(t, "world", entries[1].Line)
+	require.Equal(t, model.LabelSet{"job": "appliance", "source_ip": "127.0.0.1"}, entries[0].Labels)
+}
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1.
+func newTestCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTCPListener_TLS(t *testing.T) {
+	cert := newTestCertificate(t)
+
+	ch := make(chan loki.Entry)
+	l, err := NewTCPListener(log.NewNopLogger(), NewMetrics(prometheus.NewRegistry(), "test", true), loki.NewEntryHandler(ch, func() {}), TCPConfig{
+		Config: Config{
+			Address:          "127.0.0.1:0",
+			Framing:          FramingOctetCounting,
+			MaxMessageLength: DefaultMaxMessageLength,
+		},
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	})
+	require.NoError(t, err)
+	defer l.Stop()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("11 hello\nworld"))
+	require.NoError(t, err)
+
+	entries := receive(t, ch, 1)
+	require.Equal(t, "hello\nworld", entries[0].Line)
+	require.Empty(t, entries[0].Labels)
+}
+
+func TestTCPListener_StopWithOpenConnection(t *testing.T) {
+	ch := make(chan loki.Entry)
+	l, err := NewTCPListener(log.NewNopLogger(), NewMetrics(prometheus.NewRegistry(), "test", true), loki.NewEntryHandler(ch, func() {}), TCPConfig{
+		Config: Config{
+			Address:          "127.0.0.1:0",
+			Framing:          FramingNewline,
+			MaxMessageLength: DefaultMaxMessageLength,
+		},
+	})
+	require.NoError(t, err)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	// The line is never received, so the connection is blocked sending it.
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	go func() {
+		l.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out stopping the listener")
+	}
+}
+
+func TestUDPListener(t *testing.T) {
+	ch := make(chan loki.Entry)
+	l, err := NewUDPListener(log.NewNopLogger(), NewMetrics(prometheus.NewRegistry(), "test", false), loki.NewEntryHandler(ch, func() {}), Config{
+		Address:          "127.0.0.1:0",
+		Framing:          FramingNewline,
+		MaxMessageLength: DefaultMaxMessageLength,
+		SourceIPLabel:    "source_ip",
+	})
+	require.NoError(t, err)
+	defer l.Stop()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello\nworld"))
+	require.NoError(t, err)
+
+	entries := receive(t, ch, 2)
+	require.Equal(t, "hello", entries[0].Line)
+	require.Equal(t, "world", entries[1].Line)
+	require.Equal(t, model.LabelSet{"source_ip": "127.0.0.1"}, entries[1].Labels)
+}
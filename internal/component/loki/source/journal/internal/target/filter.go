@@ -0,0 +1,85 @@
+package target
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Match is a FIELD=VALUE condition on a journal entry.
+type Match struct {
+	Field string
+	Value string
+}
+
+// ParseMatches parses a space-separated list of FIELD=VALUE matches.
+func ParseMatches(s string) ([]Match, error) {
+	var matches []Match
+	for _, m := range strings.Fields(s) {
+		fv := strings.Split(m, "=")
+		if len(fv) != 2 {
+			return nil, fmt.Errorf("invalid match %q, must be FIELD=VALUE", m)
+		}
+		matches = append(matches, Match{Field: fv[0], Value: fv[1]})
+	}
+	return matches, nil
+}
+
+// FieldFilter keeps or drops journal entries depending on whether the value
+// of a field matches a regular expression.
+type FieldFilter struct {
+	Field string
+	Regex *regexp.Regexp
+	// Drop drops the entries whose field matches, instead of only keeping
+	// them.
+	Drop bool
+}
+
+// Filter selects the journal entries to forward, in addition to the matches
+// applied by the journal reader.
+type Filter struct {
+	// MatchGroups are OR-ed together, while the matches of a group are
+	// AND-ed. Entries aren't filtered by match groups when there are none.
+	MatchGroups [][]Match
+	// FieldFilters must all accept an entry for it to be forwarded.
+	FieldFilters []FieldFilter
+	// ExcludeKernel drops the messages of the kernel.
+	ExcludeKernel bool
+}
+
+// Keep returns whether the journal entry with the given fields should be
+// forwarded. A nil Filter keeps all entries.
+func (f *Filter) Keep(fields map[string]string) bool {
+	if f == nil {
+		return true
+	}
+	if f.ExcludeKernel && fields["_TRANSPORT"] == "kernel" {
+		return false
+	}
+	if len(f.MatchGroups) > 0 && !f.matchesAnyGroup(fields) {
+		return false
+	}
+	for _, ff := range f.FieldFilters {
+		value, ok := fields[ff.Field]
+		if matched := ok && ff.Regex.MatchString(value); matched == ff.Drop {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Filter) matchesAnyGroup(fields map[string]string) bool {
+	for _, group := range f.MatchGroups {
+		matched := true
+		for _, m := range group {
+			if value, ok := fields[m.Field]; !ok || value != m.Value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package target
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatches(t *testing.T) {
+	matches, err := ParseMatches("_SYSTEMD_UNIT=foo.service  PRIORITY=3")
+	require.NoError(t, err)
+	require.Equal(t, []Match{{Field: "_SYSTEMD_UNIT", Value: "foo.service"}, {Field: "PRIORITY", Value: "3"}}, matches)
+
+	_, err = ParseMatches("_SYSTEMD_UNIT")
+	require.ErrorContains(t, err, `invalid match "_SYSTEMD_UNIT"`)
+}
+
+func TestFilter(t *testing.T) {
+	var (
+		fooInfo  = map[string]string{"_SYSTEMD_UNIT": "foo.service", "PRIORITY": "6", "MESSAGE": "started"}
+		fooError = map[string]string{"_SYSTEMD_UNIT": "foo.service", "PRIORITY": "3", "MESSAGE": "failed"}
+		barInfo  = map[string]string{"_SYSTEMD_UNIT": "bar.service", "PRIORITY": "6", "MESSAGE": "healthcheck ok"}
+		kernel   = map[string]string{"_TRANSPORT": "kernel", "PRIORITY": "4", "MESSAGE": "oom"}
+	)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		keep   []map[string]string
+		drop   []map[string]string
+	}{
+		{
+			name: "nil",
+			keep: []map[string]string{fooInfo, fooError, barInfo, kernel},
+		},
+		{
+			name: "match groups",
+			filter: &Filter{MatchGroups: [][]Match{
+				{{Field: "_SYSTEMD_UNIT", Value: "foo.service"}, {Field: "PRIORITY", Value: "3"}},
+				{{Field: "_SYSTEMD_UNIT", Value: "bar.service"}},
+			}},
+			keep: []map[string]string{fooError, barInfo},
+			drop: []map[string]string{fooInfo, kernel},
+		},
+		{
+			name: "keep field filter",
+			filter: &Filter{FieldFilters: []FieldFilter{
+				{Field: "_SYSTEMD_UNIT", Regex: regexp.MustCompile(`^(foo|bar)\.service$`)},
+			}},
+			keep: []map[string]string{fooInfo, fooError, barInfo},
+			drop: []map[string]string{kernel},
+		},
+		{
+			name: "drop field filter",
+			filter: &Filter{FieldFilters: []FieldFilter{
+				{Field: "MESSAGE", Regex: regexp.MustCompile(`^healthcheck`), Drop: true},
+			}},
+			keep: []map[string]string{fooInfo, fooError, kernel},
+			drop: []map[string]string{barInfo},
+		},
+		{
+			name:   "exclude kernel",
+			filter: &Filter{ExcludeKernel: true},
+			keep:   []map[string]string{fooInfo, fooError, barInfo},
+			drop:   []map[string]string{kernel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, fields := range tt.keep {
+				require.True(t, tt.filter.Keep(fields), "expected %v to be kept", fields)
+			}
+			for _, fields := range tt.drop {
+				require.False(t, tt.filter.Keep(fields), "expected %v to be dropped", fields)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/common/loki/positions"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -108,6 +109,7 @@ type JournalTarget struct {
 	positionPath  string
 	relabelConfig []*relabel.Config
 	config        *scrapeconfig.JournalTargetConfig
+	filter        *Filter
 	labels        model.LabelSet
 
 	r     journalReader
@@ -123,6 +125,7 @@ func NewJournalTarget(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	filter *Filter,
 ) (*JournalTarget, error) {
 
 	return journalTargetWithReader(
@@ -133,6 +136,7 @@ func NewJournalTarget(
 		jobName,
 		relabelConfig,
 		targetConfig,
+		filter,
 		defaultJournalReaderFunc,
 		defaultJournalEntryFunc,
 	)
@@ -146,6 +150,7 @@ func journalTargetWithReader(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	filter *Filter,
 	readerFunc journalReaderFunc,
 	entryFunc journalEntryFunc,
 ) (*JournalTarget, error) {
@@ -170,6 +175,7 @@ func journalTargetWithReader(
 		relabelConfig: relabelConfig,
 		labels:        targetConfig.Labels,
 		config:        targetConfig,
+		filter:        filter,
 
 		until: until,
 	}
@@ -192,15 +198,14 @@ func journalTargetWithReader(
 		EntryFunc:   entryFunc,
 	}
 
-	matches := strings.Fields(targetConfig.Matches)
+	matches, err := ParseMatches(targetConfig.Matches)
+	if err != nil {
+		return nil, errors.New("Error parsing journal reader 'matches' config value")
+	}
 	for _, m := range matches {
-		fv := strings.Split(m, "=")
-		if len(fv) != 2 {
-			return nil, errors.New("Error parsing journal reader 'matches' config value")
-		}
 		cb.Matches = append(cb.Matches, sdjournal.Match{
-			Field: fv[0],
-			Value: fv[1],
+			Field: m.Field,
+			Value: m.Value,
 		})
 	}
 
@@ -288,6 +293,10 @@ func (t *JournalTarget) generateJournalConfig(
 func (t *JournalTarget) formatter(entry *sdjournal.JournalEntry) (string, error) {
 	ts := time.Unix(0, int64(entry.RealtimeTimestamp)*int64(time.Microsecond))
 
+	if !t.filter.Keep(entry.Fields) {
+		return journalEmptyStr, nil
+	}
+
 	var msg string
 
 	if t.config.JSON {
@@ -316,7 +325,7 @@ func (t *JournalTarget) formatter(entry *sdjournal.JournalEntry) (string, error)
 		entryLabels[string(k)] = string(v)
 	}
 
-	processedLabels, _ := relabel.Process(labels.FromMap(entryLabels), t.relabelConfig...)
+	processedLabels, _ := alloy_relabel.Process(labels.FromMap(entryLabels), t.relabelConfig...)
 
 	processedLabelsMap := processedLabels.Map()
 	lbls := make(model.LabelSet, len(processedLabelsMap))
@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -105,7 +107,7 @@ func TestJournalTarget(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, nil, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -159,7 +161,7 @@ func TestJournalTargetParsingErrors(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, nil, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -236,7 +238,7 @@ func TestJournalTarget_JSON(t *testing.T) {
 	cfg := &scrapeconfig.JournalTargetConfig{JSON: true}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", relabels,
-		cfg, newMockJournalReader, newMockJournalEntry(nil))
+		cfg, nil, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -261,6 +263,54 @@ func TestJournalTarget_JSON(t *testing.T) {
 	}
 }
 
+func TestJournalTarget_Filter(t *testing.T) {
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+
+	ps, err := positions.New(logger, positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: filepath.Join(t.TempDir(), "positions.yml"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewClient(func() {})
+
+	filter := &Filter{
+		MatchGroups: [][]Match{
+			{{Field: "_SYSTEMD_UNIT", Value: "foo.service"}},
+			{{Field: "_SYSTEMD_UNIT", Value: "bar.service"}, {Field: "PRIORITY", Value: "3"}},
+		},
+		FieldFilters: []FieldFilter{
+			{Field: "MESSAGE", Regex: regexp.MustCompile("^healthcheck"), Drop: true},
+		},
+	}
+	cfg := &scrapeconfig.JournalTargetConfig{Labels: model.LabelSet{"job": "test"}}
+
+	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
+		cfg, filter, newMockJournalReader, newMockJournalEntry(nil))
+	require.NoError(t, err)
+
+	r := jt.r.(*mockJournalReader)
+	r.t = t
+
+	r.Write(map[string]string{"MESSAGE": "foo started", "_SYSTEMD_UNIT": "foo.service", "PRIORITY": "6"})
+	r.Write(map[string]string{"MESSAGE": "healthcheck ok", "_SYSTEMD_UNIT": "foo.service", "PRIORITY": "6"})
+	r.Write(map[string]string{"MESSAGE": "bar info", "_SYSTEMD_UNIT": "bar.service", "PRIORITY": "6"})
+	r.Write(map[string]string{"MESSAGE": "bar failed", "_SYSTEMD_UNIT": "bar.service", "PRIORITY": "3"})
+	r.Write(map[string]string{"MESSAGE": "other", "_SYSTEMD_UNIT": "other.service", "PRIORITY": "3"})
+
+	require.NoError(t, jt.Stop())
+	client.Stop()
+
+	var lines []string
+	for _, entry := range client.Received() {
+		lines = append(lines, entry.Line)
+	}
+	require.Equal(t, []string{"foo started", "bar failed"}, lines)
+}
+
 func TestJournalTarget_Since(t *testing.T) {
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
@@ -287,7 +337,7 @@ func TestJournalTarget_Since(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, nil, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -327,7 +377,7 @@ func TestJournalTarget_Cursor_TooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, nil, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -367,7 +417,7 @@ func TestJournalTarget_Cursor_NotTooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, nil, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -418,7 +468,7 @@ func TestJournalTarget_Matches(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, nil, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -114,9 +114,13 @@ func (c *Component) Update(args component.Arguments) error {
 		}
 	}
 	rcs := alloy_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelRules)
+	filter, err := newArgs.filter()
+	if err != nil {
+		return err
+	}
 	entryHandler := loki.NewEntryHandler(c.handler, func() {})
 
-	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs))
+	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs), filter)
 	if err != nil {
 		return err
 	}
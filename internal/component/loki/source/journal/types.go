@@ -1,28 +1,72 @@
 package journal
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/component/loki/source/journal/internal/target"
+)
+
+// Field filter actions.
+const (
+	FieldFilterActionKeep = "keep"
+	FieldFilterActionDrop = "drop"
 )
 
 // Arguments are the arguments for the component.
 type Arguments struct {
-	FormatAsJson bool                `alloy:"format_as_json,attr,optional"`
-	MaxAge       time.Duration       `alloy:"max_age,attr,optional"`
-	Path         string              `alloy:"path,attr,optional"`
-	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
-	Matches      string              `alloy:"matches,attr,optional"`
-	Receivers    []loki.LogsReceiver `alloy:"forward_to,attr"`
-	Labels       map[string]string   `alloy:"labels,attr,optional"`
+	FormatAsJson  bool                `alloy:"format_as_json,attr,optional"`
+	MaxAge        time.Duration       `alloy:"max_age,attr,optional"`
+	Path          string              `alloy:"path,attr,optional"`
+	RelabelRules  alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+	Matches       string              `alloy:"matches,attr,optional"`
+	MatchGroups   []string            `alloy:"match_groups,attr,optional"`
+	IncludeKernel bool                `alloy:"include_kernel,attr,optional"`
+	FieldFilters  []FieldFilter       `alloy:"field_filter,block,optional"`
+	Receivers     []loki.LogsReceiver `alloy:"forward_to,attr"`
+	Labels        map[string]string   `alloy:"labels,attr,optional"`
+}
+
+// FieldFilter keeps or drops journal entries depending on whether a field
+// matches a regular expression.
+type FieldFilter struct {
+	Field  string `alloy:"field,attr"`
+	Regex  string `alloy:"regex,attr"`
+	Action string `alloy:"action,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (f *FieldFilter) SetToDefault() {
+	*f = FieldFilter{
+		Action: FieldFilterActionKeep,
+	}
+}
+
+// Validate implements syntax.Validator.
+func (f *FieldFilter) Validate() error {
+	if f.Field == "" {
+		return fmt.Errorf("field_filter field must not be empty")
+	}
+	if _, err := regexp.Compile(f.Regex); err != nil {
+		return fmt.Errorf("invalid field_filter regex for field %s: %w", f.Field, err)
+	}
+	switch f.Action {
+	case FieldFilterActionKeep, FieldFilterActionDrop:
+	default:
+		return fmt.Errorf("unsupported field_filter action %q, must be %q or %q", f.Action, FieldFilterActionKeep, FieldFilterActionDrop)
+	}
+	return nil
 }
 
 func defaultArgs() Arguments {
 	return Arguments{
-		FormatAsJson: false,
-		MaxAge:       7 * time.Hour,
-		Path:         "",
+		FormatAsJson:  false,
+		MaxAge:        7 * time.Hour,
+		Path:          "",
+		IncludeKernel: true,
 	}
 }
 
@@ -30,3 +74,44 @@ func defaultArgs() Arguments {
 func (r *Arguments) SetToDefault() {
 	*r = defaultArgs()
 }
+
+// Validate implements syntax.Validator.
+func (r *Arguments) Validate() error {
+	if _, err := target.ParseMatches(r.Matches); err != nil {
+		return fmt.Errorf("invalid matches: %w", err)
+	}
+	_, err := r.filter()
+	return err
+}
+
+// filter returns the filter applied to journal entries in addition to
+// matches. It returns nil if entries aren't filtered.
+func (r *Arguments) filter() (*target.Filter, error) {
+	if len(r.MatchGroups) == 0 && len(r.FieldFilters) == 0 && r.IncludeKernel {
+		return nil, nil
+	}
+
+	f := &target.Filter{ExcludeKernel: !r.IncludeKernel}
+	for _, group := range r.MatchGroups {
+		matches, err := target.ParseMatches(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match_groups: %w", err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("invalid match_groups: groups must not be empty")
+		}
+		f.MatchGroups = append(f.MatchGroups, matches)
+	}
+	for _, ff := range r.FieldFilters {
+		re, err := regexp.Compile(ff.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field_filter regex for field %s: %w", ff.Field, err)
+		}
+		f.FieldFilters = append(f.FieldFilters, target.FieldFilter{
+			Field: ff.Field,
+			Regex: re,
+			Drop:  ff.Action == FieldFilterActionDrop,
+		})
+	}
+	return f, nil
+}
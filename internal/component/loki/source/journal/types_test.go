@@ -0,0 +1,103 @@
+package journal
+
+import (
+	"testing"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_Filter(t *testing.T) {
+	var exampleAlloyConfig = `
+	match_groups   = ["_SYSTEMD_UNIT=nginx.service", "_SYSTEMD_UNIT=sshd.service PRIORITY=3"]
+	include_kernel = false
+	field_filter {
+		field  = "MESSAGE"
+		regex  = "^healthcheck"
+		action = "drop"
+	}
+	field_filter {
+		field = "_HOSTNAME"
+		regex = "web-.*"
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+
+	filter, err := args.filter()
+	require.NoError(t, err)
+	require.Len(t, filter.MatchGroups, 2)
+	require.Len(t, filter.MatchGroups[1], 2)
+	require.True(t, filter.ExcludeKernel)
+	require.True(t, filter.FieldFilters[0].Drop)
+	require.False(t, filter.FieldFilters[1].Drop)
+
+	require.True(t, filter.Keep(map[string]string{"_SYSTEMD_UNIT": "nginx.service", "_HOSTNAME": "web-1", "MESSAGE": "GET /"}))
+	require.False(t, filter.Keep(map[string]string{"_SYSTEMD_UNIT": "nginx.service", "_HOSTNAME": "web-1", "MESSAGE": "healthcheck"}))
+	require.False(t, filter.Keep(map[string]string{"_SYSTEMD_UNIT": "sshd.service", "_HOSTNAME": "web-1", "PRIORITY": "6"}))
+}
+
+func TestArguments_NoFilter(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`forward_to = []`), &args)
+	require.NoError(t, err)
+	require.True(t, args.IncludeKernel)
+
+	filter, err := args.filter()
+	require.NoError(t, err)
+	require.Nil(t, filter)
+}
+
+func TestArguments_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "invalid matches",
+			config: `matches = "_SYSTEMD_UNIT"`,
+			err:    "invalid matches",
+		},
+		{
+			name:   "invalid match group",
+			config: `match_groups = ["PRIORITY=3", "_SYSTEMD_UNIT"]`,
+			err:    "invalid match_groups",
+		},
+		{
+			name:   "empty match group",
+			config: `match_groups = [""]`,
+			err:    "groups must not be empty",
+		},
+		{
+			name: "invalid regex",
+			config: `
+	field_filter {
+		field = "MESSAGE"
+		regex = "(unclosed"
+	}`,
+			err: "invalid field_filter regex for field MESSAGE",
+		},
+		{
+			name: "invalid action",
+			config: `
+	field_filter {
+		field  = "MESSAGE"
+		regex  = ".*"
+		action = "replace"
+	}`,
+			err: `unsupported field_filter action "replace"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config+"\n\tforward_to = []"), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
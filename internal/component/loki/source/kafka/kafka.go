@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/IBM/sarama"
@@ -63,8 +64,27 @@ type KafkaSASLConfig struct {
 }
 
 type OAuthConfigConfig struct {
-	TokenProvider string   `alloy:"token_provider,attr"`
-	Scopes        []string `alloy:"scopes,attr"`
+	TokenProvider string          `alloy:"token_provider,attr"`
+	Scopes        []string        `alloy:"scopes,attr,optional"`
+	AWSMSKIAM     AWSMSKIAMConfig `alloy:"aws_msk_iam,block,optional"`
+}
+
+// AWSMSKIAMConfig describes the AWS credentials used to authenticate with
+// Amazon MSK clusters using IAM access control.
+type AWSMSKIAMConfig struct {
+	Region          string `alloy:"region,attr,optional"`
+	RoleARN         string `alloy:"role_arn,attr,optional"`
+	RoleSessionName string `alloy:"role_session_name,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (c *OAuthConfigConfig) Validate() error {
+	switch kt.TokenProviderType(c.TokenProvider) {
+	case kt.TokenProviderTypeAzure, kt.TokenProviderTypeAWSMSKIAM:
+	default:
+		return fmt.Errorf("unsupported token provider %q, must be %q or %q", c.TokenProvider, kt.TokenProviderTypeAzure, kt.TokenProviderTypeAWSMSKIAM)
+	}
+	return nil
 }
 
 // DefaultArguments provides the default arguments for a kafka component.
@@ -207,6 +227,11 @@ func (auth KafkaAuthentication) Convert() kt.Authentication {
 			OAuthConfig: kt.OAuthConfig{
 				TokenProvider: kt.TokenProviderType(auth.SASLConfig.OAuthConfig.TokenProvider),
 				Scopes:        auth.SASLConfig.OAuthConfig.Scopes,
+				AWSMSKIAM: kt.AWSMSKIAMConfig{
+					Region:          auth.SASLConfig.OAuthConfig.AWSMSKIAM.Region,
+					RoleARN:         auth.SASLConfig.OAuthConfig.AWSMSKIAM.RoleARN,
+					RoleSessionName: auth.SASLConfig.OAuthConfig.AWSMSKIAM.RoleSessionName,
+				},
 			},
 		},
 	}
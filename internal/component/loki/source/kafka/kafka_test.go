@@ -3,6 +3,7 @@ package kafka
 import (
 	"testing"
 
+	kt "github.com/grafana/alloy/internal/component/loki/source/internal/kafkatarget"
 	"github.com/grafana/alloy/syntax"
 	"github.com/stretchr/testify/require"
 )
@@ -84,3 +85,60 @@ func TestSASLOAuthAlloyConfig(t *testing.T) {
 	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
 	require.NoError(t, err)
 }
+
+func TestSASLAWSMSKIAMAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	brokers = ["b-1.example.kafka.eu-west-1.amazonaws.com:9098"]
+	topics  = ["quickstart-events"]
+
+	authentication {
+		type = "sasl"
+		sasl_config {
+			mechanism = "OAUTHBEARER"
+			use_tls   = true
+			oauth_config {
+				token_provider = "aws_msk_iam"
+				aws_msk_iam {
+					region   = "eu-west-1"
+					role_arn = "arn:aws:iam::123456789012:role/kafka-consumer"
+				}
+			}
+		}
+	}
+	labels     = {component = "loki.source.kafka"}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+
+	oauth := args.Convert().KafkaConfig.Authentication.SASLConfig.OAuthConfig
+	require.Equal(t, kt.TokenProviderTypeAWSMSKIAM, oauth.TokenProvider)
+	require.Equal(t, kt.AWSMSKIAMConfig{
+		Region:  "eu-west-1",
+		RoleARN: "arn:aws:iam::123456789012:role/kafka-consumer",
+	}, oauth.AWSMSKIAM)
+}
+
+func TestSASLOAuthAlloyConfig_UnsupportedTokenProvider(t *testing.T) {
+	var exampleAlloyConfig = `
+	brokers = ["localhost:9092"]
+	topics  = ["quickstart-events"]
+
+	authentication {
+		type = "sasl"
+		sasl_config {
+			mechanism = "OAUTHBEARER"
+			oauth_config {
+				token_provider = "gcp"
+			}
+		}
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.ErrorContains(t, err, `unsupported token provider "gcp"`)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/prometheus/common/model"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -45,6 +46,35 @@ type Arguments struct {
 	Client commonk8s.ClientArguments `alloy:"client,block,optional"`
 
 	Clustering cluster.ComponentBlock `alloy:"clustering,block,optional"`
+
+	// Metadata of the nodes and owners of pods to add to entries.
+	Metadata MetadataArguments `alloy:"metadata,block,optional"`
+}
+
+// MetadataArguments configures the metadata of the node and the owner of a pod
+// which is added to the entries of its containers.
+type MetadataArguments struct {
+	NodeLabels         map[string]string `alloy:"node_labels,attr,optional"`
+	Owner              bool              `alloy:"owner,attr,optional"`
+	StructuredMetadata bool              `alloy:"structured_metadata,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (args *MetadataArguments) Validate() error {
+	for name := range args.NodeLabels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q in node_labels", name)
+		}
+	}
+	return nil
+}
+
+func (args *MetadataArguments) config() kubetail.MetadataConfig {
+	return kubetail.MetadataConfig{
+		NodeLabels:         args.NodeLabels,
+		Owner:              args.Owner,
+		StructuredMetadata: args.StructuredMetadata,
+	}
 }
 
 // DefaultArguments holds default settings for loki.source.kubernetes.
@@ -127,6 +157,9 @@ func (c *Component) Run(ctx context.Context) error {
 		if c.tailer != nil {
 			c.tailer.Stop()
 		}
+		if c.lastOptions != nil && c.lastOptions.Metadata != nil {
+			c.lastOptions.Metadata.Stop()
+		}
 	}()
 
 	for {
@@ -166,6 +199,7 @@ func (c *Component) Update(args component.Arguments) error {
 	case c.tailer == nil:
 		// First call to Update; build the tailer.
 		c.tailer = kubetail.NewManager(c.log, managerOpts)
+		c.lastOptions = managerOpts
 
 	case managerOpts != c.lastOptions:
 		// Options changed; pass it to the tailer.
@@ -175,6 +209,12 @@ func (c *Component) Update(args component.Arguments) error {
 		// TODO(rfratto): should we have a generous update timeout to prevent this
 		// from potentially hanging forever?
 		_ = c.tailer.UpdateOptions(context.Background(), managerOpts)
+
+		// The informers of the previous options are stopped once no tailer
+		// uses them anymore.
+		if c.lastOptions != nil && c.lastOptions.Metadata != nil {
+			c.lastOptions.Metadata.Stop()
+		}
 		c.lastOptions = managerOpts
 
 	default:
@@ -226,7 +266,7 @@ func (c *Component) NotifyClusterChange() {
 //
 // getTailerOptions must only be called when c.mut is held.
 func (c *Component) getTailerOptions(args Arguments) (*kubetail.Options, error) {
-	if reflect.DeepEqual(c.args.Client, args.Client) && c.lastOptions != nil {
+	if reflect.DeepEqual(c.args.Client, args.Client) && reflect.DeepEqual(c.args.Metadata, args.Metadata) && c.lastOptions != nil {
 		return c.lastOptions, nil
 	}
 
@@ -239,10 +279,16 @@ func (c *Component) getTailerOptions(args Arguments) (*kubetail.Options, error)
 		return c.lastOptions, fmt.Errorf("building Kubernetes client: %w", err)
 	}
 
+	var metadata *kubetail.Metadata
+	if cfg := args.Metadata.config(); cfg.Enabled() {
+		metadata = kubetail.NewMetadata(clientSet, cfg)
+	}
+
 	return &kubetail.Options{
 		Client:    clientSet,
 		Handler:   loki.NewEntryHandler(c.handler.Chan(), func() {}),
 		Positions: c.positions,
+		Metadata:  metadata,
 	}, nil
 }
 
@@ -23,6 +23,9 @@ type Options struct {
 
 	// Positions interface so tailers can save/restore offsets in log files.
 	Positions positions.Positions
+
+	// Metadata of pods to add to entries. May be nil.
+	Metadata *Metadata
 }
 
 // A Manager manages a set of running Tailers.
@@ -0,0 +1,187 @@
+package kubetail
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// Labels set from the owner of a pod.
+const (
+	LabelOwnerKind = "owner_kind"
+	LabelOwnerName = "owner_name"
+)
+
+// metadataResync is the resync period of the informers of a Metadata.
+const metadataResync = 10 * time.Minute
+
+// MetadataConfig configures the metadata of the node and the owner of a pod
+// which is added to the entries of its containers.
+type MetadataConfig struct {
+	// NodeLabels maps label names to the keys of the labels of the node to
+	// copy.
+	NodeLabels map[string]string
+
+	// Owner adds the kind and name of the workload owning the pod, such as a
+	// Deployment rather than its ReplicaSet.
+	Owner bool
+
+	// StructuredMetadata adds the metadata as structured metadata instead of
+	// labels.
+	StructuredMetadata bool
+}
+
+// Enabled reports whether any metadata is added.
+func (c MetadataConfig) Enabled() bool {
+	return len(c.NodeLabels) > 0 || c.Owner
+}
+
+// Metadata resolves the metadata of pods from informers.
+type Metadata struct {
+	cfg  MetadataConfig
+	stop chan struct{}
+
+	factory     informers.SharedInformerFactory
+	pods        corelisters.PodLister
+	nodes       corelisters.NodeLister
+	replicaSets appslisters.ReplicaSetLister
+	jobs        batchlisters.JobLister
+}
+
+// NewMetadata creates a new Metadata and starts its informers. Only the
+// resources needed by cfg are watched. Stop must be called to stop the
+// informers.
+func NewMetadata(client kubernetes.Interface, cfg MetadataConfig) *Metadata {
+	factory := informers.NewSharedInformerFactory(client, metadataResync)
+	m := &Metadata{
+		cfg:     cfg,
+		stop:    make(chan struct{}),
+		factory: factory,
+		pods:    factory.Core().V1().Pods().Lister(),
+	}
+	if len(cfg.NodeLabels) > 0 {
+		m.nodes = factory.Core().V1().Nodes().Lister()
+	}
+	if cfg.Owner {
+		m.replicaSets = factory.Apps().V1().ReplicaSets().Lister()
+		m.jobs = factory.Batch().V1().Jobs().Lister()
+	}
+	factory.Start(m.stop)
+	return m
+}
+
+// Stop stops the informers.
+func (m *Metadata) Stop() {
+	close(m.stop)
+	m.factory.Shutdown()
+}
+
+// Apply returns a function which adds the metadata of the pod to an entry.
+// It blocks until the informers are synced or ctx is canceled.
+func (m *Metadata) Apply(ctx context.Context, pod types.NamespacedName) (func(loki.Entry) loki.Entry, error) {
+	metadata, err := m.resolve(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.cfg.StructuredMetadata {
+		names := make([]string, 0, len(metadata))
+		for name := range metadata {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		return func(e loki.Entry) loki.Entry {
+			for _, name := range names {
+				e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{
+					Name:  name,
+					Value: string(metadata[model.LabelName(name)]),
+				})
+			}
+			return e
+		}, nil
+	}
+
+	return func(e loki.Entry) loki.Entry {
+		// Labels of the target take precedence over the metadata.
+		for name, value := range metadata {
+			if _, ok := e.Labels[name]; !ok {
+				e.Labels[name] = value
+			}
+		}
+		return e
+	}, nil
+}
+
+// resolve returns the metadata of the pod.
+func (m *Metadata) resolve(ctx context.Context, key types.NamespacedName) (model.LabelSet, error) {
+	for typ, synced := range m.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return nil, fmt.Errorf("failed to sync informer for %s", typ)
+		}
+	}
+
+	pod, err := m.pods.Pods(key.Namespace).Get(key.Name)
+	if err != nil {
+		return nil, fmt.Errorf("getting pod: %w", err)
+	}
+
+	res := make(model.LabelSet)
+	if len(m.cfg.NodeLabels) > 0 && pod.Spec.NodeName != "" {
+		node, err := m.nodes.Get(pod.Spec.NodeName)
+		if err != nil {
+			return nil, fmt.Errorf("getting node: %w", err)
+		}
+		for name, key := range m.cfg.NodeLabels {
+			if value, ok := node.Labels[key]; ok {
+				res[model.LabelName(name)] = model.LabelValue(value)
+			}
+		}
+	}
+
+	if m.cfg.Owner {
+		if kind, name := m.owner(pod.Namespace, pod.OwnerReferences); kind != "" {
+			res[LabelOwnerKind] = model.LabelValue(kind)
+			res[LabelOwnerName] = model.LabelValue(name)
+		}
+	}
+	return res, nil
+}
+
+// owner returns the kind and name of the workload owning an object with the
+// owner references refs. ReplicaSets owned by a Deployment and Jobs owned by
+// a CronJob are resolved to their owner.
+func (m *Metadata) owner(namespace string, refs []metav1.OwnerReference) (kind, name string) {
+	ref := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: refs})
+	if ref == nil {
+		return "", ""
+	}
+
+	var ownerRefs []metav1.OwnerReference
+	switch ref.Kind {
+	case "ReplicaSet":
+		if rs, err := m.replicaSets.ReplicaSets(namespace).Get(ref.Name); err == nil {
+			ownerRefs = rs.OwnerReferences
+		}
+	case "Job":
+		if job, err := m.jobs.Jobs(namespace).Get(ref.Name); err == nil {
+			ownerRefs = job.OwnerReferences
+		}
+	}
+	if owner := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: ownerRefs}); owner != nil {
+		return owner.Kind, owner.Name
+	}
+	return ref.Kind, ref.Name
+}
@@ -0,0 +1,161 @@
+package kubetail
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/loki/pkg/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+}
+
+func newTestMetadata(t *testing.T, cfg MetadataConfig) *Metadata {
+	objects := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-a",
+			Labels: map[string]string{"topology.kubernetes.io/zone": "eu-west-1a"},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "api-5d4f8",
+			OwnerReferences: controllerRef("Deployment", "api"),
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "api-5d4f8-x2k9z",
+				OwnerReferences: controllerRef("ReplicaSet", "api-5d4f8"),
+			},
+			Spec: corev1.PodSpec{NodeName: "node-a"},
+		},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "backup-28512",
+			OwnerReferences: controllerRef("CronJob", "backup"),
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "backup-28512-abcde",
+				OwnerReferences: controllerRef("Job", "backup-28512"),
+			},
+			Spec: corev1.PodSpec{NodeName: "node-b"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "db-0",
+				OwnerReferences: controllerRef("StatefulSet", "db"),
+			},
+		},
+	}
+
+	m := NewMetadata(fake.NewSimpleClientset(objects...), cfg)
+	t.Cleanup(m.Stop)
+	return m
+}
+
+func TestMetadata_Labels(t *testing.T) {
+	m := newTestMetadata(t, MetadataConfig{
+		NodeLabels: map[string]string{"zone": "topology.kubernetes.io/zone"},
+		Owner:      true,
+	})
+
+	tt := []struct {
+		pod    string
+		expect model.LabelSet
+	}{
+		{
+			pod: "api-5d4f8-x2k9z",
+			expect: model.LabelSet{
+				"job":        "loki.source.kubernetes.pods",
+				"zone":       "eu-west-1a",
+				"owner_kind": "Deployment",
+				"owner_name": "api",
+			},
+		},
+		{
+			// The node of the pod isn't known, so the pod fails to resolve.
+			pod: "backup-28512-abcde",
+		},
+		{
+			pod: "db-0",
+			expect: model.LabelSet{
+				"job":        "loki.source.kubernetes.pods",
+				"owner_kind": "StatefulSet",
+				"owner_name": "db",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, tc := range tt {
+		t.Run(tc.pod, func(t *testing.T) {
+			apply, err := m.Apply(ctx, types.NamespacedName{Namespace: "default", Name: tc.pod})
+			if tc.expect == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			e := apply(loki.Entry{
+				Labels: model.LabelSet{"job": "loki.source.kubernetes.pods"},
+				Entry:  logproto.Entry{Line: "hello"},
+			})
+			require.Equal(t, tc.expect, e.Labels)
+			require.Empty(t, e.StructuredMetadata)
+		})
+	}
+}
+
+func TestMetadata_CronJobOwner(t *testing.T) {
+	m := newTestMetadata(t, MetadataConfig{Owner: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	apply, err := m.Apply(ctx, types.NamespacedName{Namespace: "default", Name: "backup-28512-abcde"})
+	require.NoError(t, err)
+	e := apply(loki.Entry{Labels: model.LabelSet{}})
+	require.Equal(t, model.LabelSet{"owner_kind": "CronJob", "owner_name": "backup"}, e.Labels)
+}
+
+func TestMetadata_StructuredMetadata(t *testing.T) {
+	m := newTestMetadata(t, MetadataConfig{
+		NodeLabels:         map[string]string{"zone": "topology.kubernetes.io/zone"},
+		Owner:              true,
+		StructuredMetadata: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	apply, err := m.Apply(ctx, types.NamespacedName{Namespace: "default", Name: "api-5d4f8-x2k9z"})
+	require.NoError(t, err)
+
+	e := apply(loki.Entry{Labels: model.LabelSet{"job": "loki.source.kubernetes.pods"}})
+	require.Equal(t, model.LabelSet{"job": "loki.source.kubernetes.pods"}, e.Labels)
+	require.Equal(t, push.LabelsAdapter{
+		{Name: "owner_kind", Value: "Deployment"},
+		{Name: "owner_name", Value: "api"},
+		{Name: "zone", Value: "eu-west-1a"},
+	}, e.StructuredMetadata)
+}
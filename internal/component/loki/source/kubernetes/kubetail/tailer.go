@@ -143,6 +143,17 @@ func (t *tailer) tail(ctx context.Context, handler loki.EntryHandler) error {
 
 	var lastReadTime time.Time
 
+	addMetadata := func(e loki.Entry) loki.Entry { return e }
+	if t.opts.Metadata != nil {
+		// Entries are still sent without metadata if it can't be resolved,
+		// for example when the pod was deleted.
+		if apply, err := t.opts.Metadata.Apply(ctx, key); err != nil {
+			level.Warn(t.log).Log("msg", "failed to resolve pod metadata", "err", err)
+		} else {
+			addMetadata = apply
+		}
+	}
+
 	if offset, err := t.opts.Positions.Get(positionsEnt.Path, positionsEnt.Labels); err != nil {
 		level.Warn(t.log).Log("msg", "failed to load last read offset", "err", err)
 	} else {
@@ -253,13 +264,13 @@ func (t *tailer) tail(ctx context.Context, handler loki.EntryHandler) error {
 			}
 			lastReadTime = entryTimestamp
 
-			entry := loki.Entry{
+			entry := addMetadata(loki.Entry{
 				Labels: t.lset.Clone(),
 				Entry: logproto.Entry{
 					Timestamp: entryTimestamp,
 					Line:      entryLine,
 				},
-			}
+			})
 
 			select {
 			case <-ctx.Done():
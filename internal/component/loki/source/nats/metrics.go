@@ -0,0 +1,24 @@
+package nats
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	entries prometheus.Counter
+	errors  *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	var m metrics
+
+	m.entries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "loki_source_nats_entries_total",
+		Help: "Total number of log entries read from NATS.",
+	})
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_source_nats_errors_total",
+		Help: "Total number of errors while consuming messages from NATS.",
+	}, []string{"reason"})
+
+	reg.MustRegister(m.entries, m.errors)
+	return &m
+}
@@ -0,0 +1,239 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.source.nats",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Deliver policies of JetStream consumers.
+const (
+	DeliverPolicyAll            = "all"
+	DeliverPolicyNew            = "new"
+	DeliverPolicyLast           = "last"
+	DeliverPolicyLastPerSubject = "last_per_subject"
+)
+
+// Arguments holds values which are used to configure the loki.source.nats
+// component.
+type Arguments struct {
+	Servers              []string          `alloy:"servers,attr"`
+	Subjects             []string          `alloy:"subjects,attr"`
+	QueueGroup           string            `alloy:"queue_group,attr,optional"`
+	JetStream            *JetStreamConfig  `alloy:"jetstream,block,optional"`
+	Authentication       Authentication    `alloy:"authentication,block,optional"`
+	TLSConfig            *config.TLSConfig `alloy:"tls_config,block,optional"`
+	UseIncomingTimestamp bool              `alloy:"use_incoming_timestamp,attr,optional"`
+	Labels               map[string]string `alloy:"labels,attr,optional"`
+
+	ForwardTo    []loki.LogsReceiver `alloy:"forward_to,attr"`
+	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+}
+
+// JetStreamConfig configures the JetStream consumer used to read messages
+// from a stream.
+type JetStreamConfig struct {
+	Stream        string        `alloy:"stream,attr"`
+	DurableName   string        `alloy:"durable_name,attr,optional"`
+	DeliverPolicy string        `alloy:"deliver_policy,attr,optional"`
+	AckWait       time.Duration `alloy:"ack_wait,attr,optional"`
+	MaxAckPending int           `alloy:"max_ack_pending,attr,optional"`
+}
+
+// DefaultJetStreamConfig provides the default arguments for a JetStream
+// consumer.
+var DefaultJetStreamConfig = JetStreamConfig{
+	DeliverPolicy: DeliverPolicyAll,
+	AckWait:       30 * time.Second,
+	MaxAckPending: 1000,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (c *JetStreamConfig) SetToDefault() {
+	*c = DefaultJetStreamConfig
+}
+
+// Validate implements syntax.Validator.
+func (c *JetStreamConfig) Validate() error {
+	if c.Stream == "" {
+		return fmt.Errorf("stream must not be empty")
+	}
+	if strings.ContainsAny(c.DurableName, ".*> \t") {
+		return fmt.Errorf("durable_name %q must not contain '.', '*', '>' or whitespace", c.DurableName)
+	}
+	switch c.DeliverPolicy {
+	case DeliverPolicyAll, DeliverPolicyNew, DeliverPolicyLast, DeliverPolicyLastPerSubject:
+	default:
+		return fmt.Errorf("unsupported deliver_policy %q, must be one of %q, %q, %q or %q", c.DeliverPolicy, DeliverPolicyAll, DeliverPolicyNew, DeliverPolicyLast, DeliverPolicyLastPerSubject)
+	}
+	if c.AckWait <= 0 {
+		return fmt.Errorf("ack_wait must be greater than 0")
+	}
+	if c.MaxAckPending <= 0 {
+		return fmt.Errorf("max_ack_pending must be greater than 0")
+	}
+	return nil
+}
+
+// Authentication describes how to authenticate with the NATS servers. At
+// most one method can be used.
+type Authentication struct {
+	CredentialsFile string            `alloy:"credentials_file,attr,optional"`
+	NKeySeedFile    string            `alloy:"nkey_seed_file,attr,optional"`
+	User            string            `alloy:"user,attr,optional"`
+	Password        alloytypes.Secret `alloy:"password,attr,optional"`
+	Token           alloytypes.Secret `alloy:"token,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (a *Authentication) Validate() error {
+	methods := 0
+	for _, set := range []bool{a.CredentialsFile != "", a.NKeySeedFile != "", a.User != "", a.Token != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return fmt.Errorf("at most one of credentials_file, nkey_seed_file, user or token must be set")
+	}
+	if a.Password != "" && a.User == "" {
+		return fmt.Errorf("password requires user to be set")
+	}
+	return nil
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = Arguments{}
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if len(a.Servers) == 0 {
+		return fmt.Errorf("at least one server must be provided")
+	}
+	if len(a.Subjects) == 0 {
+		return fmt.Errorf("at least one subject must be provided")
+	}
+	if a.JetStream != nil && a.QueueGroup != "" {
+		return fmt.Errorf("queue_group can't be used with jetstream, JetStream consumers with the same durable_name already share messages")
+	}
+	return nil
+}
+
+// Component implements the loki.source.nats component.
+type Component struct {
+	opts    component.Options
+	metrics *metrics
+
+	mut    sync.RWMutex
+	fanout []loki.LogsReceiver
+	target *target
+
+	handler loki.LogsReceiver
+}
+
+// New creates a new loki.source.nats component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:    o,
+		metrics: newMetrics(o.Registerer),
+		handler: loki.NewLogsReceiver(),
+		fanout:  args.ForwardTo,
+	}
+
+	// Call to Update() to start readers and set receivers once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		level.Info(c.opts.Logger).Log("msg", "loki.source.nats component shutting down, stopping target")
+		if c.target != nil {
+			c.target.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.handler.Chan():
+			c.mut.RLock()
+			for _, receiver := range c.fanout {
+				receiver.Chan() <- entry
+			}
+			c.mut.RUnlock()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+	c.fanout = newArgs.ForwardTo
+
+	if c.target != nil {
+		c.target.Stop()
+		c.target = nil
+	}
+
+	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
+	t, err := newTarget(c.metrics, c.opts.Logger, entryHandler, newArgs)
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to create nats target with provided config", "err", err)
+		return err
+	}
+	c.target = t
+
+	return nil
+}
+
+// DebugInfo returns information about the status of the target.
+func (c *Component) DebugInfo() interface{} {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	var info targetDebugInfo
+	if c.target != nil {
+		info.Details = c.target.Details()
+	}
+	return info
+}
+
+type targetDebugInfo struct {
+	Details map[string]string `alloy:"target_info,attr"`
+}
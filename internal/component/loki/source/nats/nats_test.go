@@ -0,0 +1,130 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	servers                = ["nats://localhost:4222"]
+	subjects               = ["logs.>"]
+	queue_group            = "alloy"
+	labels                 = {component = "loki.source.nats"}
+	forward_to             = []
+	use_incoming_timestamp = true
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Nil(t, args.JetStream)
+	require.Equal(t, "alloy", args.QueueGroup)
+}
+
+func TestJetStreamAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	servers  = ["nats://localhost:4222"]
+	subjects = ["logs.>"]
+	jetstream {
+		stream       = "LOGS"
+		durable_name = "alloy"
+	}
+	authentication {
+		credentials_file = "/fake/user.creds"
+	}
+	tls_config {
+		ca_file = "/fake/ca.pem"
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, &JetStreamConfig{
+		Stream:        "LOGS",
+		DurableName:   "alloy",
+		DeliverPolicy: DeliverPolicyAll,
+		AckWait:       30 * time.Second,
+		MaxAckPending: 1000,
+	}, args.JetStream)
+	require.Equal(t, "/fake/user.creds", args.Authentication.CredentialsFile)
+	require.Equal(t, "/fake/ca.pem", args.TLSConfig.CAFile)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "no subjects",
+			config: `
+	servers    = ["nats://localhost:4222"]
+	subjects   = []
+	forward_to = []`,
+			err: "at least one subject must be provided",
+		},
+		{
+			name: "queue group with jetstream",
+			config: `
+	servers     = ["nats://localhost:4222"]
+	subjects    = ["logs"]
+	queue_group = "alloy"
+	jetstream {
+		stream = "LOGS"
+	}
+	forward_to = []`,
+			err: "queue_group can't be used with jetstream",
+		},
+		{
+			name: "invalid durable name",
+			config: `
+	servers  = ["nats://localhost:4222"]
+	subjects = ["logs"]
+	jetstream {
+		stream       = "LOGS"
+		durable_name = "loki.source.nats"
+	}
+	forward_to = []`,
+			err: `durable_name "loki.source.nats" must not contain`,
+		},
+		{
+			name: "invalid deliver policy",
+			config: `
+	servers  = ["nats://localhost:4222"]
+	subjects = ["logs"]
+	jetstream {
+		stream         = "LOGS"
+		deliver_policy = "oldest"
+	}
+	forward_to = []`,
+			err: `unsupported deliver_policy "oldest"`,
+		},
+		{
+			name: "multiple authentication methods",
+			config: `
+	servers  = ["nats://localhost:4222"]
+	subjects = ["logs"]
+	authentication {
+		user  = "alloy"
+		token = "secret"
+	}
+	forward_to = []`,
+			err: "at most one of credentials_file, nkey_seed_file, user or token must be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Internal labels set on every log entry, which can be used in relabel rules.
+const (
+	labelSubject           = "__nats_subject"
+	labelSubjectToken      = "__nats_subject_token_"
+	labelHeader            = "__nats_header_"
+	labelJetStreamStream   = "__nats_jetstream_stream"
+	labelJetStreamConsumer = "__nats_jetstream_consumer"
+)
+
+var (
+	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	defaultBackoff = backoff.Config{
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: 10 * time.Second,
+		MaxRetries: 0, // Retry forever
+	}
+)
+
+// target consumes messages from NATS subjects, either directly or through a
+// JetStream consumer, and converts them to log entries.
+type target struct {
+	metrics       *metrics
+	logger        log.Logger
+	handler       loki.EntryHandler
+	args          Arguments
+	labels        model.LabelSet
+	relabelConfig []*relabel.Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	conn *nats.Conn
+	subs []*nats.Subscription
+}
+
+func newTarget(metrics *metrics, logger log.Logger, handler loki.EntryHandler, args Arguments) (*target, error) {
+	opts, err := connectOptions(logger, metrics, args)
+	if err != nil {
+		return nil, err
+	}
+
+	lbls := make(model.LabelSet, len(args.Labels))
+	for k, v := range args.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &target{
+		metrics:       metrics,
+		logger:        logger,
+		handler:       handler,
+		args:          args,
+		labels:        lbls,
+		relabelConfig: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	// Connections are retried in the background, so that the component still
+	// starts when the servers are unavailable.
+	t.conn, err = nats.Connect(strings.Join(args.Servers, ","), opts...)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	if args.JetStream != nil {
+		js, err := jetstream.New(t.conn)
+		if err != nil {
+			t.Stop()
+			return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+		}
+		t.wg.Add(1)
+		go t.consumeJetStream(js)
+		return t, nil
+	}
+
+	for _, subject := range args.Subjects {
+		sub, err := t.conn.QueueSubscribe(subject, args.QueueGroup, t.handleMsg)
+		if err != nil {
+			t.Stop()
+			return nil, fmt.Errorf("failed to subscribe to subject %q: %w", subject, err)
+		}
+		t.subs = append(t.subs, sub)
+	}
+	return t, nil
+}
+
+func connectOptions(logger log.Logger, metrics *metrics, args Arguments) ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.Name("alloy"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				level.Warn(logger).Log("msg", "disconnected from NATS", "err", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			level.Info(logger).Log("msg", "reconnected to NATS", "url", nc.ConnectedUrlRedacted())
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			level.Error(logger).Log("msg", "error while consuming messages from NATS", "err", err)
+			metrics.errors.WithLabelValues("connection").Inc()
+		}),
+	}
+
+	auth := args.Authentication
+	switch {
+	case auth.CredentialsFile != "":
+		opts = append(opts, nats.UserCredentials(auth.CredentialsFile))
+	case auth.NKeySeedFile != "":
+		opt, err := nats.NkeyOptionFromSeed(auth.NKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nkey seed: %w", err)
+		}
+		opts = append(opts, opt)
+	case auth.User != "":
+		opts = append(opts, nats.UserInfo(auth.User, string(auth.Password)))
+	case auth.Token != "":
+		opts = append(opts, nats.Token(string(auth.Token)))
+	}
+
+	if args.TLSConfig != nil {
+		tlsConfig, err := promconfig.NewTLSConfig(args.TLSConfig.Convert())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+	return opts, nil
+}
+
+// consumeJetStream creates the JetStream consumer and consumes its messages
+// until the target is stopped. Creating the consumer is retried, since the
+// stream may not exist yet or the servers may be unavailable.
+func (t *target) consumeJetStream(js jetstream.JetStream) {
+	defer t.wg.Done()
+
+	cfg := t.consumerConfig()
+	bo := backoff.New(t.ctx, defaultBackoff)
+	for bo.Ongoing() {
+		consumer, err := js.CreateOrUpdateConsumer(t.ctx, t.args.JetStream.Stream, cfg)
+		if err != nil {
+			level.Error(t.logger).Log("msg", "failed to create JetStream consumer", "stream", t.args.JetStream.Stream, "err", err)
+			t.metrics.errors.WithLabelValues("consumer").Inc()
+			bo.Wait()
+			continue
+		}
+
+		consumeCtx, err := consumer.Consume(t.handleJetStreamMsg, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+			level.Warn(t.logger).Log("msg", "error while consuming JetStream messages", "stream", t.args.JetStream.Stream, "err", err)
+			t.metrics.errors.WithLabelValues("consumer").Inc()
+		}))
+		if err != nil {
+			level.Error(t.logger).Log("msg", "failed to consume JetStream messages", "stream", t.args.JetStream.Stream, "err", err)
+			t.metrics.errors.WithLabelValues("consumer").Inc()
+			bo.Wait()
+			continue
+		}
+
+		<-t.ctx.Done()
+		consumeCtx.Stop()
+		return
+	}
+}
+
+func (t *target) consumerConfig() jetstream.ConsumerConfig {
+	js := t.args.JetStream
+	cfg := jetstream.ConsumerConfig{
+		Durable:       js.DurableName,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       js.AckWait,
+		MaxAckPending: js.MaxAckPending,
+	}
+	switch js.DeliverPolicy {
+	case DeliverPolicyNew:
+		cfg.DeliverPolicy = jetstream.DeliverNewPolicy
+	case DeliverPolicyLast:
+		cfg.DeliverPolicy = jetstream.DeliverLastPolicy
+	case DeliverPolicyLastPerSubject:
+		cfg.DeliverPolicy = jetstream.DeliverLastPerSubjectPolicy
+	default:
+		cfg.DeliverPolicy = jetstream.DeliverAllPolicy
+	}
+	// Multiple filter subjects require NATS 2.10, so they're only used when
+	// needed.
+	if len(t.args.Subjects) == 1 {
+		cfg.FilterSubject = t.args.Subjects[0]
+	} else {
+		cfg.FilterSubjects = t.args.Subjects
+	}
+	return cfg
+}
+
+func (t *target) handleMsg(msg *nats.Msg) {
+	entry, ok := t.buildEntry(msg.Subject, msg.Header, msg.Data, nil)
+	if ok {
+		t.send(entry)
+	}
+}
+
+func (t *target) handleJetStreamMsg(msg jetstream.Msg) {
+	md, err := msg.Metadata()
+	if err != nil {
+		level.Warn(t.logger).Log("msg", "failed to read JetStream message metadata", "subject", msg.Subject(), "err", err)
+	}
+
+	entry, ok := t.buildEntry(msg.Subject(), msg.Headers(), msg.Data(), md)
+	if ok && !t.send(entry) {
+		// The target is stopping, let the message be redelivered.
+		_ = msg.Nak()
+		return
+	}
+	// Ack only after the entry is sent.
+	if err := msg.Ack(); err != nil {
+		level.Warn(t.logger).Log("msg", "failed to acknowledge JetStream message", "subject", msg.Subject(), "err", err)
+		t.metrics.errors.WithLabelValues("ack").Inc()
+	}
+}
+
+// send forwards an entry, and returns false if the target was stopped
+// before the entry could be sent.
+func (t *target) send(entry loki.Entry) bool {
+	select {
+	case t.handler.Chan() <- entry:
+		t.metrics.entries.Inc()
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+// buildEntry converts a message to a log entry. It returns false if the
+// entry was dropped by the relabel rules.
+func (t *target) buildEntry(subject string, header nats.Header, data []byte, md *jetstream.MsgMetadata) (loki.Entry, bool) {
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	lb.Set(labelSubject, subject)
+	for i, token := range strings.Split(subject, ".") {
+		lb.Set(fmt.Sprintf("%s%d", labelSubjectToken, i), token)
+	}
+	for name, values := range header {
+		lb.Set(labelHeader+invalidLabelCharRE.ReplaceAllString(name, "_"), strings.Join(values, ","))
+	}
+	if md != nil {
+		lb.Set(labelJetStreamStream, md.Stream)
+		lb.Set(labelJetStreamConsumer, md.Consumer)
+	}
+
+	processed, keep := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
+	if !keep {
+		return loki.Entry{}, false
+	}
+
+	// Start with the set of labels fixed in the configuration
+	entryLabels := t.labels.Clone()
+	processed.Range(func(lbl labels.Label) {
+		if strings.HasPrefix(lbl.Name, "__") {
+			return
+		}
+		entryLabels[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+	})
+
+	timestamp := time.Now()
+	if t.args.UseIncomingTimestamp && md != nil {
+		timestamp = md.Timestamp
+	}
+
+	return loki.Entry{
+		Labels: entryLabels,
+		Entry: logproto.Entry{
+			Timestamp: timestamp,
+			Line:      string(data),
+		},
+	}, true
+}
+
+// Details returns some debug information about the target.
+func (t *target) Details() map[string]string {
+	details := map[string]string{
+		"servers":  strings.Join(t.args.Servers, ","),
+		"subjects": strings.Join(t.args.Subjects, ","),
+		"status":   t.conn.Status().String(),
+		"labels":   t.labels.String(),
+	}
+	if t.args.JetStream != nil {
+		details["stream"] = t.args.JetStream.Stream
+	}
+	return details
+}
+
+// Stop shuts the target down.
+func (t *target) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	for _, sub := range t.subs {
+		_ = sub.Unsubscribe()
+	}
+	t.conn.Close()
+	t.handler.Stop()
+}
@@ -0,0 +1,110 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+func TestBuildEntry(t *testing.T) {
+	tgt := &target{
+		labels: model.LabelSet{"job": "nats"},
+		relabelConfig: []*relabel.Config{
+			{
+				SourceLabels: model.LabelNames{"__nats_subject_token_1"},
+				TargetLabel:  "service",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.*)"),
+				Replacement:  "$1",
+			},
+			{
+				SourceLabels: model.LabelNames{"__nats_header_X_Tenant"},
+				TargetLabel:  "tenant",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.*)"),
+				Replacement:  "$1",
+			},
+			{
+				SourceLabels: model.LabelNames{"__nats_jetstream_stream"},
+				TargetLabel:  "stream",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.+)"),
+				Replacement:  "$1",
+			},
+		},
+	}
+
+	header := nats.Header{}
+	header.Set("X-Tenant", "team-a")
+
+	entry, ok := tgt.buildEntry("logs.api.eu", header, []byte("hello"), nil)
+	require.True(t, ok)
+	require.Equal(t, "hello", entry.Line)
+	require.Equal(t, model.LabelSet{"job": "nats", "service": "api", "tenant": "team-a"}, entry.Labels)
+	require.WithinDuration(t, time.Now(), entry.Timestamp, time.Minute)
+
+	// The timestamp of JetStream messages is only used when requested.
+	ts := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	md := &jetstream.MsgMetadata{Stream: "LOGS", Consumer: "alloy", Timestamp: ts}
+	entry, ok = tgt.buildEntry("logs.api", nil, []byte("hello"), md)
+	require.True(t, ok)
+	require.Equal(t, model.LabelSet{"job": "nats", "service": "api", "stream": "LOGS"}, entry.Labels)
+	require.NotEqual(t, ts, entry.Timestamp)
+
+	tgt.args.UseIncomingTimestamp = true
+	entry, ok = tgt.buildEntry("logs.api", nil, []byte("hello"), md)
+	require.True(t, ok)
+	require.Equal(t, ts, entry.Timestamp)
+}
+
+func TestBuildEntry_Dropped(t *testing.T) {
+	tgt := &target{
+		relabelConfig: []*relabel.Config{
+			{
+				SourceLabels: model.LabelNames{"__nats_subject_token_0"},
+				Action:       relabel.Drop,
+				Regex:        relabel.MustNewRegexp("debug"),
+			},
+		},
+	}
+
+	_, ok := tgt.buildEntry("debug.api", nil, []byte("hello"), nil)
+	require.False(t, ok)
+	_, ok = tgt.buildEntry("logs.api", nil, []byte("hello"), nil)
+	require.True(t, ok)
+}
+
+func TestTarget_UnavailableServer(t *testing.T) {
+	ch := loki.NewLogsReceiver()
+	handler := loki.NewEntryHandler(ch.Chan(), func() {})
+
+	// Connections are retried in the background, so an unavailable server
+	// doesn't prevent the target from starting.
+	tgt, err := newTarget(newMetrics(prometheus.NewRegistry()), log.NewNopLogger(), handler, Arguments{
+		Servers:  []string{"nats://127.0.0.1:1"},
+		Subjects: []string{"logs.>"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "nats://127.0.0.1:1", tgt.Details()["servers"])
+
+	// Messages are dropped once the target is stopped.
+	tgt.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tgt.handleMsg(&nats.Msg{Subject: "logs.api", Data: []byte("hello")})
+	select {
+	case <-ch.Chan():
+		t.Fatal("unexpected entry")
+	case <-ctx.Done():
+	}
+}
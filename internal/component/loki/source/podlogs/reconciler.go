@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/kubernetes/kubetail"
 	monitoringv1alpha2 "github.com/grafana/alloy/internal/component/loki/source/podlogs/internal/apis/monitoring/v1alpha2"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -16,7 +17,6 @@ import (
 	"github.com/grafana/ckit/shard"
 	"github.com/prometheus/common/model"
 	promlabels "github.com/prometheus/prometheus/model/labels"
-	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/util/strutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -225,7 +225,7 @@ func (r *reconciler) reconcilePodLogs(ctx context.Context, cli client.Client, po
 				Container:     container,
 				InitContainer: initContainer,
 			})
-			processedLabels, _ := relabel.Process(targetLabels.Copy(), relabelRules...)
+			processedLabels, _ := alloy_relabel.Process(targetLabels.Copy(), relabelRules...)
 
 			defaultJob := fmt.Sprintf("%s/%s:%s", podLogs.Namespace, podLogs.Name, container.Name)
 			finalLabels, err := kubetail.PrepareLabels(processedLabels, defaultJob)
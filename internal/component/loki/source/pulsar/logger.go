@@ -0,0 +1,78 @@
+package pulsar
+
+import (
+	"fmt"
+
+	pulsarlog "github.com/apache/pulsar-client-go/pulsar/log"
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// clientLogger adapts the logger of the component to the logger interface of
+// the Pulsar client.
+type clientLogger struct {
+	logger log.Logger
+}
+
+var _ pulsarlog.Logger = clientLogger{}
+
+func newClientLogger(logger log.Logger) pulsarlog.Logger {
+	return clientLogger{logger: logger}
+}
+
+func (l clientLogger) SubLogger(fields pulsarlog.Fields) pulsarlog.Logger {
+	return clientLogger{logger: withFields(l.logger, fields)}
+}
+
+func (l clientLogger) WithFields(fields pulsarlog.Fields) pulsarlog.Entry {
+	return clientLogger{logger: withFields(l.logger, fields)}
+}
+
+func (l clientLogger) WithField(name string, value interface{}) pulsarlog.Entry {
+	return clientLogger{logger: log.With(l.logger, name, value)}
+}
+
+func (l clientLogger) WithError(err error) pulsarlog.Entry {
+	return clientLogger{logger: log.With(l.logger, "err", err)}
+}
+
+func (l clientLogger) Debug(args ...interface{}) {
+	level.Debug(l.logger).Log("msg", fmt.Sprint(args...))
+}
+
+func (l clientLogger) Info(args ...interface{}) {
+	level.Info(l.logger).Log("msg", fmt.Sprint(args...))
+}
+
+func (l clientLogger) Warn(args ...interface{}) {
+	level.Warn(l.logger).Log("msg", fmt.Sprint(args...))
+}
+
+func (l clientLogger) Error(args ...interface{}) {
+	level.Error(l.logger).Log("msg", fmt.Sprint(args...))
+}
+
+func (l clientLogger) Debugf(format string, args ...interface{}) {
+	level.Debug(l.logger).Log("msg", fmt.Sprintf(format, args...))
+}
+
+func (l clientLogger) Infof(format string, args ...interface{}) {
+	level.Info(l.logger).Log("msg", fmt.Sprintf(format, args...))
+}
+
+func (l clientLogger) Warnf(format string, args ...interface{}) {
+	level.Warn(l.logger).Log("msg", fmt.Sprintf(format, args...))
+}
+
+func (l clientLogger) Errorf(format string, args ...interface{}) {
+	level.Error(l.logger).Log("msg", fmt.Sprintf(format, args...))
+}
+
+func withFields(logger log.Logger, fields pulsarlog.Fields) log.Logger {
+	kvs := make([]interface{}, 0, len(fields)*2)
+	for k, v := range fields {
+		kvs = append(kvs, k, v)
+	}
+	return log.With(logger, kvs...)
+}
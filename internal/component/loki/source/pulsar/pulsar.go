@@ -0,0 +1,225 @@
+package pulsar
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.source.pulsar",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Subscription types.
+const (
+	SubscriptionTypeExclusive = "exclusive"
+	SubscriptionTypeShared    = "shared"
+	SubscriptionTypeFailover  = "failover"
+	SubscriptionTypeKeyShared = "key_shared"
+)
+
+// Initial positions of new subscriptions.
+const (
+	InitialPositionLatest   = "latest"
+	InitialPositionEarliest = "earliest"
+)
+
+// Authentication types.
+const (
+	AuthenticationTypeNone  = "none"
+	AuthenticationTypeToken = "token"
+	AuthenticationTypeTLS   = "tls"
+)
+
+// Arguments holds values which are used to configure the loki.source.pulsar
+// component.
+type Arguments struct {
+	URL                  string               `alloy:"url,attr"`
+	Topics               []string             `alloy:"topics,attr,optional"`
+	TopicsPattern        string               `alloy:"topics_pattern,attr,optional"`
+	Subscription         string               `alloy:"subscription,attr,optional"`
+	SubscriptionType     string               `alloy:"subscription_type,attr,optional"`
+	InitialPosition      string               `alloy:"initial_position,attr,optional"`
+	Authentication       PulsarAuthentication `alloy:"authentication,block,optional"`
+	TLSConfig            config.TLSConfig     `alloy:"tls_config,block,optional"`
+	UseIncomingTimestamp bool                 `alloy:"use_incoming_timestamp,attr,optional"`
+	Labels               map[string]string    `alloy:"labels,attr,optional"`
+
+	ForwardTo    []loki.LogsReceiver `alloy:"forward_to,attr"`
+	RelabelRules alloy_relabel.Rules `alloy:"relabel_rules,attr,optional"`
+}
+
+// PulsarAuthentication describes the configuration for authentication with
+// Pulsar brokers.
+type PulsarAuthentication struct {
+	Type      string            `alloy:"type,attr,optional"`
+	Token     alloytypes.Secret `alloy:"token,attr,optional"`
+	TokenFile string            `alloy:"token_file,attr,optional"`
+}
+
+// DefaultArguments provides the default arguments for a pulsar component.
+var DefaultArguments = Arguments{
+	Subscription:     "loki.source.pulsar",
+	SubscriptionType: SubscriptionTypeShared,
+	InitialPosition:  InitialPositionLatest,
+	Authentication: PulsarAuthentication{
+		Type: AuthenticationTypeNone,
+	},
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if (len(a.Topics) == 0) == (a.TopicsPattern == "") {
+		return fmt.Errorf("exactly one of topics or topics_pattern must be provided")
+	}
+	if a.Subscription == "" {
+		return fmt.Errorf("subscription must not be empty")
+	}
+	switch a.SubscriptionType {
+	case SubscriptionTypeExclusive, SubscriptionTypeShared, SubscriptionTypeFailover, SubscriptionTypeKeyShared:
+	default:
+		return fmt.Errorf("unsupported subscription_type %q, must be one of %q, %q, %q or %q", a.SubscriptionType, SubscriptionTypeExclusive, SubscriptionTypeShared, SubscriptionTypeFailover, SubscriptionTypeKeyShared)
+	}
+	switch a.InitialPosition {
+	case InitialPositionLatest, InitialPositionEarliest:
+	default:
+		return fmt.Errorf("unsupported initial_position %q, must be %q or %q", a.InitialPosition, InitialPositionLatest, InitialPositionEarliest)
+	}
+
+	// The Pulsar client only reads TLS certificates and keys from files.
+	if a.TLSConfig.CA != "" || a.TLSConfig.Cert != "" || a.TLSConfig.Key != "" {
+		return fmt.Errorf("tls_config only supports ca_file, cert_file and key_file, not inline PEM values")
+	}
+
+	auth := a.Authentication
+	switch auth.Type {
+	case AuthenticationTypeNone:
+	case AuthenticationTypeToken:
+		if (auth.Token == "") == (auth.TokenFile == "") {
+			return fmt.Errorf("exactly one of token or token_file must be set for token authentication")
+		}
+	case AuthenticationTypeTLS:
+		if a.TLSConfig.CertFile == "" || a.TLSConfig.KeyFile == "" {
+			return fmt.Errorf("cert_file and key_file must be set in tls_config for tls authentication")
+		}
+	default:
+		return fmt.Errorf("unsupported authentication type %q, must be one of %q, %q or %q", auth.Type, AuthenticationTypeNone, AuthenticationTypeToken, AuthenticationTypeTLS)
+	}
+	return nil
+}
+
+// Component implements the loki.source.pulsar component.
+type Component struct {
+	opts    component.Options
+	metrics *metrics
+
+	mut    sync.RWMutex
+	fanout []loki.LogsReceiver
+	target *target
+
+	handler loki.LogsReceiver
+}
+
+// New creates a new loki.source.pulsar component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:    o,
+		metrics: newMetrics(o.Registerer),
+		handler: loki.NewLogsReceiver(),
+		fanout:  args.ForwardTo,
+	}
+
+	// Call to Update() to start readers and set receivers once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		level.Info(c.opts.Logger).Log("msg", "loki.source.pulsar component shutting down, stopping target")
+		if c.target != nil {
+			c.target.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.handler.Chan():
+			c.mut.RLock()
+			for _, receiver := range c.fanout {
+				receiver.Chan() <- entry
+			}
+			c.mut.RUnlock()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+	c.fanout = newArgs.ForwardTo
+
+	if c.target != nil {
+		c.target.Stop()
+		c.target = nil
+	}
+
+	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
+	t, err := newTarget(c.metrics, c.opts.Logger, c.opts.Registerer, entryHandler, newArgs)
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to create pulsar client with provided config", "err", err)
+		return err
+	}
+	c.target = t
+
+	return nil
+}
+
+// DebugInfo returns information about the status of the target.
+func (c *Component) DebugInfo() interface{} {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	var info targetDebugInfo
+	if c.target != nil {
+		info.Details = c.target.Details()
+	}
+	return info
+}
+
+type targetDebugInfo struct {
+	Details map[string]string `alloy:"target_info,attr"`
+}
@@ -0,0 +1,150 @@
+package pulsar
+
+import (
+	"testing"
+
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	url                    = "pulsar://localhost:6650"
+	topics                 = ["persistent://public/default/logs"]
+	labels                 = {component = "loki.source.pulsar"}
+	forward_to             = []
+	use_incoming_timestamp = true
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, "loki.source.pulsar", args.Subscription)
+	require.Equal(t, SubscriptionTypeShared, args.SubscriptionType)
+	require.Equal(t, InitialPositionLatest, args.InitialPosition)
+	require.Equal(t, AuthenticationTypeNone, args.Authentication.Type)
+}
+
+func TestTokenAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	url               = "pulsar+ssl://localhost:6651"
+	topics_pattern    = "persistent://public/default/logs-.*"
+	subscription      = "alloy"
+	subscription_type = "key_shared"
+	initial_position  = "earliest"
+	authentication {
+		type  = "token"
+		token = "secret"
+	}
+	tls_config {
+		ca_file = "/fake/ca.pem"
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+
+	opts := clientOptions(nil, nil, args)
+	require.Equal(t, "/fake/ca.pem", opts.TLSTrustCertsFilePath)
+	require.True(t, opts.TLSValidateHostname)
+	require.NotNil(t, opts.Authentication)
+}
+
+func TestTLSAlloyConfig(t *testing.T) {
+	var exampleAlloyConfig = `
+	url    = "pulsar+ssl://localhost:6651"
+	topics = ["logs"]
+	authentication {
+		type = "tls"
+	}
+	tls_config {
+		ca_file   = "/fake/ca.pem"
+		cert_file = "/fake/client.pem"
+		key_file  = "/fake/client.key"
+	}
+	forward_to = []
+`
+
+	var args Arguments
+	err := syntax.Unmarshal([]byte(exampleAlloyConfig), &args)
+	require.NoError(t, err)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "topics and pattern",
+			config: `
+	url            = "pulsar://localhost:6650"
+	topics         = ["logs"]
+	topics_pattern = "logs-.*"
+	forward_to     = []`,
+			err: "exactly one of topics or topics_pattern must be provided",
+		},
+		{
+			name: "invalid subscription type",
+			config: `
+	url               = "pulsar://localhost:6650"
+	topics            = ["logs"]
+	subscription_type = "key-shared"
+	forward_to        = []`,
+			err: `unsupported subscription_type "key-shared"`,
+		},
+		{
+			name: "invalid initial position",
+			config: `
+	url              = "pulsar://localhost:6650"
+	topics           = ["logs"]
+	initial_position = "oldest"
+	forward_to       = []`,
+			err: `unsupported initial_position "oldest"`,
+		},
+		{
+			name: "token without a value",
+			config: `
+	url    = "pulsar://localhost:6650"
+	topics = ["logs"]
+	authentication {
+		type = "token"
+	}
+	forward_to = []`,
+			err: "exactly one of token or token_file must be set",
+		},
+		{
+			name: "tls without a certificate",
+			config: `
+	url    = "pulsar://localhost:6650"
+	topics = ["logs"]
+	authentication {
+		type = "tls"
+	}
+	forward_to = []`,
+			err: "cert_file and key_file must be set in tls_config",
+		},
+		{
+			name: "inline certificate",
+			config: `
+	url    = "pulsar://localhost:6650"
+	topics = ["logs"]
+	tls_config {
+		ca_pem = "-----BEGIN CERTIFICATE-----"
+	}
+	forward_to = []`,
+			err: "tls_config only supports ca_file, cert_file and key_file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Internal labels set on every log entry, which can be used in relabel rules.
+const (
+	labelTopic        = "__meta_pulsar_topic"
+	labelMessageKey   = "__meta_pulsar_message_key"
+	labelProducerName = "__meta_pulsar_producer_name"
+	labelSubscription = "__meta_pulsar_subscription"
+	labelProperty     = "__meta_pulsar_property_"
+)
+
+var (
+	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	defaultBackoff = backoff.Config{
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: 10 * time.Second,
+		MaxRetries: 0, // Retry forever
+	}
+)
+
+// target consumes messages from Pulsar topics through a subscription and
+// converts them to log entries.
+type target struct {
+	metrics       *metrics
+	logger        log.Logger
+	handler       loki.EntryHandler
+	args          Arguments
+	labels        model.LabelSet
+	relabelConfig []*relabel.Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	client pulsar.Client
+}
+
+func newTarget(metrics *metrics, logger log.Logger, reg prometheus.Registerer, handler loki.EntryHandler, args Arguments) (*target, error) {
+	client, err := pulsar.NewClient(clientOptions(logger, reg, args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pulsar client: %w", err)
+	}
+
+	lbls := make(model.LabelSet, len(args.Labels))
+	for k, v := range args.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &target{
+		metrics:       metrics,
+		logger:        logger,
+		handler:       handler,
+		args:          args,
+		labels:        lbls,
+		relabelConfig: alloy_relabel.ComponentToPromRelabelConfigs(args.RelabelRules),
+		ctx:           ctx,
+		cancel:        cancel,
+		client:        client,
+	}
+
+	t.wg.Add(1)
+	go t.run()
+	return t, nil
+}
+
+func clientOptions(logger log.Logger, reg prometheus.Registerer, args Arguments) pulsar.ClientOptions {
+	opts := pulsar.ClientOptions{
+		URL:                        args.URL,
+		TLSTrustCertsFilePath:      args.TLSConfig.CAFile,
+		TLSAllowInsecureConnection: args.TLSConfig.InsecureSkipVerify,
+		TLSValidateHostname:        !args.TLSConfig.InsecureSkipVerify,
+		Logger:                     newClientLogger(logger),
+		MetricsRegisterer:          reg,
+	}
+
+	switch args.Authentication.Type {
+	case AuthenticationTypeToken:
+		if args.Authentication.TokenFile != "" {
+			opts.Authentication = pulsar.NewAuthenticationTokenFromFile(args.Authentication.TokenFile)
+		} else {
+			opts.Authentication = pulsar.NewAuthenticationToken(string(args.Authentication.Token))
+		}
+	case AuthenticationTypeTLS:
+		opts.Authentication = pulsar.NewAuthenticationTLS(args.TLSConfig.CertFile, args.TLSConfig.KeyFile)
+	}
+	return opts
+}
+
+func (t *target) consumerOptions() pulsar.ConsumerOptions {
+	opts := pulsar.ConsumerOptions{
+		Topics:           t.args.Topics,
+		TopicsPattern:    t.args.TopicsPattern,
+		SubscriptionName: t.args.Subscription,
+	}
+
+	switch t.args.SubscriptionType {
+	case SubscriptionTypeExclusive:
+		opts.Type = pulsar.Exclusive
+	case SubscriptionTypeFailover:
+		opts.Type = pulsar.Failover
+	case SubscriptionTypeKeyShared:
+		opts.Type = pulsar.KeyShared
+	default:
+		opts.Type = pulsar.Shared
+	}
+
+	if t.args.InitialPosition == InitialPositionEarliest {
+		opts.SubscriptionInitialPosition = pulsar.SubscriptionPositionEarliest
+	} else {
+		opts.SubscriptionInitialPosition = pulsar.SubscriptionPositionLatest
+	}
+	return opts
+}
+
+// run subscribes to the topics and consumes their messages until the target
+// is stopped. Subscribing is retried, since the brokers may be unavailable.
+func (t *target) run() {
+	defer t.wg.Done()
+
+	var (
+		consumer pulsar.Consumer
+		err      error
+		bo       = backoff.New(t.ctx, defaultBackoff)
+	)
+	for bo.Ongoing() {
+		consumer, err = t.subscribe()
+		if err == nil || t.ctx.Err() != nil {
+			break
+		}
+		level.Error(t.logger).Log("msg", "failed to subscribe to pulsar topics", "subscription", t.args.Subscription, "err", err)
+		t.metrics.errors.WithLabelValues("subscribe").Inc()
+		bo.Wait()
+	}
+	if consumer == nil {
+		return
+	}
+	defer consumer.Close()
+
+	for {
+		msg, err := consumer.Receive(t.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || t.ctx.Err() != nil {
+				return
+			}
+			level.Warn(t.logger).Log("msg", "failed to receive pulsar message", "err", err)
+			t.metrics.errors.WithLabelValues("receive").Inc()
+			continue
+		}
+
+		entry, ok := t.buildEntry(msg)
+		if ok && !t.send(entry) {
+			// The target is stopping, let the message be redelivered.
+			return
+		}
+		// Ack only after the entry is sent.
+		if err := consumer.Ack(msg); err != nil {
+			level.Warn(t.logger).Log("msg", "failed to acknowledge pulsar message", "topic", msg.Topic(), "err", err)
+			t.metrics.errors.WithLabelValues("ack").Inc()
+		}
+	}
+}
+
+// subscribe creates the consumer, and gives up when the target is stopped.
+// Subscribing blocks until the brokers answer or the operation times out.
+func (t *target) subscribe() (pulsar.Consumer, error) {
+	type result struct {
+		consumer pulsar.Consumer
+		err      error
+	}
+	res := make(chan result, 1)
+	go func() {
+		consumer, err := t.client.Subscribe(t.consumerOptions())
+		res <- result{consumer, err}
+	}()
+
+	select {
+	case r := <-res:
+		return r.consumer, r.err
+	case <-t.ctx.Done():
+		go func() {
+			if r := <-res; r.consumer != nil {
+				r.consumer.Close()
+			}
+		}()
+		return nil, t.ctx.Err()
+	}
+}
+
+// send forwards an entry, and returns false if the target was stopped
+// before the entry could be sent.
+func (t *target) send(entry loki.Entry) bool {
+	select {
+	case t.handler.Chan() <- entry:
+		t.metrics.entries.Inc()
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+// buildEntry converts a message to a log entry. It returns false if the
+// entry was dropped by the relabel rules.
+func (t *target) buildEntry(msg pulsar.Message) (loki.Entry, bool) {
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	lb.Set(labelTopic, msg.Topic())
+	lb.Set(labelMessageKey, msg.Key())
+	lb.Set(labelProducerName, msg.ProducerName())
+	lb.Set(labelSubscription, t.args.Subscription)
+	for name, value := range msg.Properties() {
+		lb.Set(labelProperty+invalidLabelCharRE.ReplaceAllString(name, "_"), value)
+	}
+
+	processed, keep := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
+	if !keep {
+		return loki.Entry{}, false
+	}
+
+	// Start with the set of labels fixed in the configuration
+	entryLabels := t.labels.Clone()
+	processed.Range(func(lbl labels.Label) {
+		if strings.HasPrefix(lbl.Name, "__") {
+			return
+		}
+		entryLabels[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+	})
+
+	timestamp := time.Now()
+	if t.args.UseIncomingTimestamp {
+		// The event time is optional and set by producers, while the publish
+		// time is always set by the broker.
+		if eventTime := msg.EventTime(); !eventTime.IsZero() {
+			timestamp = eventTime
+		} else {
+			timestamp = msg.PublishTime()
+		}
+	}
+
+	return loki.Entry{
+		Labels: entryLabels,
+		Entry: logproto.Entry{
+			Timestamp: timestamp,
+			Line:      string(msg.Payload()),
+		},
+	}, true
+}
+
+// Details returns some debug information about the target.
+func (t *target) Details() map[string]string {
+	details := map[string]string{
+		"url":               t.args.URL,
+		"subscription":      t.args.Subscription,
+		"subscription_type": t.args.SubscriptionType,
+		"labels":            t.labels.String(),
+	}
+	if len(t.args.Topics) > 0 {
+		details["topics"] = strings.Join(t.args.Topics, ",")
+	} else {
+		details["topics_pattern"] = t.args.TopicsPattern
+	}
+	return details
+}
+
+// Stop shuts the target down.
+func (t *target) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	t.client.Close()
+	t.handler.Stop()
+}
@@ -0,0 +1,144 @@
+package pulsar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+// fakeMessage implements the parts of pulsar.Message used by the target.
+type fakeMessage struct {
+	pulsar.Message
+
+	topic       string
+	key         string
+	properties  map[string]string
+	payload     string
+	publishTime time.Time
+	eventTime   time.Time
+}
+
+func (m fakeMessage) Topic() string                 { return m.topic }
+func (m fakeMessage) Key() string                   { return m.key }
+func (m fakeMessage) ProducerName() string          { return "producer" }
+func (m fakeMessage) Properties() map[string]string { return m.properties }
+func (m fakeMessage) Payload() []byte               { return []byte(m.payload) }
+func (m fakeMessage) PublishTime() time.Time        { return m.publishTime }
+func (m fakeMessage) EventTime() time.Time          { return m.eventTime }
+
+func TestBuildEntry(t *testing.T) {
+	tgt := &target{
+		args:   Arguments{Subscription: "alloy"},
+		labels: model.LabelSet{"job": "pulsar"},
+		relabelConfig: []*relabel.Config{
+			{
+				SourceLabels: model.LabelNames{"__meta_pulsar_topic"},
+				TargetLabel:  "topic",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.*)"),
+				Replacement:  "$1",
+			},
+			{
+				SourceLabels: model.LabelNames{"__meta_pulsar_property_app_name"},
+				TargetLabel:  "app",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.+)"),
+				Replacement:  "$1",
+			},
+			{
+				SourceLabels: model.LabelNames{"__meta_pulsar_subscription"},
+				TargetLabel:  "subscription",
+				Action:       relabel.Replace,
+				Regex:        relabel.MustNewRegexp("(.*)"),
+				Replacement:  "$1",
+			},
+		},
+	}
+
+	var (
+		publishTime = time.Date(2024, time.March, 1, 12, 0, 1, 0, time.UTC)
+		eventTime   = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+		msg         = fakeMessage{
+			topic:       "persistent://public/default/logs",
+			key:         "key",
+			properties:  map[string]string{"app.name": "api"},
+			payload:     "hello",
+			publishTime: publishTime,
+		}
+	)
+
+	entry, ok := tgt.buildEntry(msg)
+	require.True(t, ok)
+	require.Equal(t, "hello", entry.Line)
+	require.Equal(t, model.LabelSet{
+		"job":          "pulsar",
+		"topic":        "persistent://public/default/logs",
+		"app":          "api",
+		"subscription": "alloy",
+	}, entry.Labels)
+	require.WithinDuration(t, time.Now(), entry.Timestamp, time.Minute)
+
+	// The publish time is used when messages don't have an event time.
+	tgt.args.UseIncomingTimestamp = true
+	entry, ok = tgt.buildEntry(msg)
+	require.True(t, ok)
+	require.Equal(t, publishTime, entry.Timestamp)
+
+	msg.eventTime = eventTime
+	entry, ok = tgt.buildEntry(msg)
+	require.True(t, ok)
+	require.Equal(t, eventTime, entry.Timestamp)
+}
+
+func TestBuildEntry_Dropped(t *testing.T) {
+	tgt := &target{
+		relabelConfig: []*relabel.Config{
+			{
+				SourceLabels: model.LabelNames{"__meta_pulsar_message_key"},
+				Action:       relabel.Drop,
+				Regex:        relabel.MustNewRegexp("debug"),
+			},
+		},
+	}
+
+	_, ok := tgt.buildEntry(fakeMessage{key: "debug"})
+	require.False(t, ok)
+	_, ok = tgt.buildEntry(fakeMessage{key: "info"})
+	require.True(t, ok)
+}
+
+func TestTarget_UnavailableBroker(t *testing.T) {
+	ch := loki.NewLogsReceiver()
+	handler := loki.NewEntryHandler(ch.Chan(), func() {})
+
+	// Subscribing is retried in the background, so an unavailable broker
+	// doesn't prevent the target from starting or stopping.
+	tgt, err := newTarget(newMetrics(prometheus.NewRegistry()), log.NewNopLogger(), prometheus.NewRegistry(), handler, Arguments{
+		URL:              "pulsar://127.0.0.1:1",
+		Topics:           []string{"logs"},
+		Subscription:     "alloy",
+		SubscriptionType: SubscriptionTypeShared,
+		InitialPosition:  InitialPositionLatest,
+		Authentication:   PulsarAuthentication{Type: AuthenticationTypeNone},
+	})
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	go func() {
+		tgt.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("target didn't stop")
+	}
+}
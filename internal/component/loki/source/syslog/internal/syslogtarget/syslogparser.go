@@ -0,0 +1,75 @@
+package syslogtarget
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/nontransparent"
+	"github.com/leodido/go-syslog/v4/octetcounting"
+)
+
+// Framings of syslog messages sent over a stream.
+const (
+	// FramingAuto detects the framing of each connection.
+	FramingAuto = "auto"
+	// FramingOctetCounting prefixes each message with its length, as defined
+	// in RFC 6587.
+	FramingOctetCounting = "octet_counting"
+	// FramingNonTransparent terminates each message with a newline, as
+	// defined in RFC 6587.
+	FramingNonTransparent = "non_transparent"
+)
+
+// parseStream parses a syslog stream from the given Reader, calling the
+// callback function with the parsed messages. It returns on EOF or
+// unrecoverable errors.
+//
+// With FramingAuto, the framing is detected from the first byte of the stream
+// which isn't whitespace: a digit starts the length of an octet-counted
+// message, and '<' starts the priority of a newline-terminated message.
+// Devices sometimes send newlines or NUL bytes before their first message,
+// which are skipped.
+//
+// This is based on the syslogparser package of Promtail.
+func parseStream(isRFC3164Message bool, framing string, r io.Reader, callback func(res *syslog.Result), maxMessageLength int) error {
+	buf := bufio.NewReaderSize(r, 1<<10)
+
+	var b byte
+	for {
+		var err error
+		if b, err = buf.ReadByte(); err != nil {
+			return err
+		}
+		if !isFramingPadding(b) {
+			break
+		}
+	}
+	_ = buf.UnreadByte()
+
+	octetCounting := b >= '0' && b <= '9'
+	switch {
+	case framing == FramingOctetCounting && !octetCounting,
+		framing == FramingNonTransparent && b != '<',
+		(framing == FramingAuto || framing == "") && !octetCounting && b != '<':
+		return fmt.Errorf("invalid or unsupported framing. first byte: '%s'", string(b))
+	}
+
+	opts := []syslog.ParserOption{syslog.WithListener(callback), syslog.WithMaxMessageLength(maxMessageLength), syslog.WithBestEffort()}
+	switch {
+	case octetCounting && isRFC3164Message:
+		octetcounting.NewParserRFC3164(opts...).Parse(buf)
+	case octetCounting:
+		octetcounting.NewParser(opts...).Parse(buf)
+	case isRFC3164Message:
+		nontransparent.NewParserRFC3164(opts...).Parse(buf)
+	default:
+		nontransparent.NewParser(opts...).Parse(buf)
+	}
+	return nil
+}
+
+func isFramingPadding(b byte) bool {
+	return b == '\n' || b == '\r' || b == ' ' || b == '\t' || b == 0
+}
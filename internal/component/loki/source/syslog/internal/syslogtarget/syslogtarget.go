@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/prometheus/model/relabel"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
@@ -32,6 +33,22 @@ var (
 	DefaultProtocol         = protocolTCP
 )
 
+// Options configures the behaviour of a SyslogTarget which isn't part of the
+// Promtail configuration.
+type Options struct {
+	// Framing of the messages sent over TCP. Defaults to FramingAuto.
+	Framing string
+	// RFC3164DefaultToCurrentYear sets the year of RFC3164 timestamps, which
+	// don't have one, to the year which puts them closest to the current time.
+	RFC3164DefaultToCurrentYear bool
+	// RFC3164Timezone is the timezone of RFC3164 timestamps, which don't have
+	// one. Defaults to UTC.
+	RFC3164Timezone *time.Location
+	// RFC3164NormalizeHostname lowercases the hostname of RFC3164 messages
+	// and removes its trailing dot.
+	RFC3164NormalizeHostname bool
+}
+
 // SyslogTarget listens to syslog messages.
 // nolint:revive
 type SyslogTarget struct {
@@ -39,6 +56,7 @@ type SyslogTarget struct {
 	logger        log.Logger
 	handler       loki.EntryHandler
 	config        *scrapeconfig.SyslogTargetConfig
+	options       Options
 	relabelConfig []*relabel.Config
 
 	transport Transport
@@ -60,12 +78,14 @@ func NewSyslogTarget(
 	handler loki.EntryHandler,
 	relabel []*relabel.Config,
 	config *scrapeconfig.SyslogTargetConfig,
+	options Options,
 ) (*SyslogTarget, error) {
 	t := &SyslogTarget{
 		metrics:       metrics,
 		logger:        logger,
 		handler:       handler,
 		config:        config,
+		options:       options,
 		relabelConfig: relabel,
 		messagesDone:  make(chan struct{}),
 	}
@@ -74,6 +94,7 @@ func NewSyslogTarget(
 	case protocolTCP:
 		t.transport = NewSyslogTCPTransport(
 			config,
+			options,
 			t.handleMessage,
 			t.handleMessageError,
 			logger,
@@ -81,6 +102,7 @@ func NewSyslogTarget(
 	case protocolUDP:
 		t.transport = NewSyslogUDPTransport(
 			config,
+			options,
 			t.handleMessage,
 			t.handleMessageError,
 			logger,
@@ -147,7 +169,7 @@ func (t *SyslogTarget) handleMessageRFC5424(connLabels labels.Labels, msg syslog
 		}
 	}
 
-	processed, _ := relabel.Process(lb.Labels(), t.relabelConfig...)
+	processed, _ := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
 
 	filtered := make(model.LabelSet)
 	for _, lbl := range processed {
@@ -192,7 +214,11 @@ func (t *SyslogTarget) handleMessageRFC3164(connLabels labels.Labels, msg syslog
 		lb.Set("__syslog_message_facility", *v)
 	}
 	if v := rfc3164Msg.Hostname; v != nil {
-		lb.Set("__syslog_message_hostname", *v)
+		hostname := *v
+		if t.options.RFC3164NormalizeHostname {
+			hostname = normalizeHostname(hostname)
+		}
+		lb.Set("__syslog_message_hostname", hostname)
 	}
 	if v := rfc3164Msg.Appname; v != nil {
 		lb.Set("__syslog_message_app_name", *v)
@@ -204,7 +230,7 @@ func (t *SyslogTarget) handleMessageRFC3164(connLabels labels.Labels, msg syslog
 		lb.Set("__syslog_message_msg_id", *v)
 	}
 
-	processed, _ := relabel.Process(lb.Labels(), t.relabelConfig...)
+	processed, _ := alloy_relabel.Process(lb.Labels(), t.relabelConfig...)
 
 	filtered := make(model.LabelSet)
 	for _, lbl := range processed {
@@ -216,7 +242,7 @@ func (t *SyslogTarget) handleMessageRFC3164(connLabels labels.Labels, msg syslog
 
 	var timestamp time.Time
 	if t.config.UseIncomingTimestamp && rfc3164Msg.Timestamp != nil {
-		timestamp = *rfc3164Msg.Timestamp
+		timestamp = t.rfc3164Timestamp(*rfc3164Msg.Timestamp, time.Now())
 	} else {
 		timestamp = time.Now()
 	}
@@ -226,6 +252,39 @@ func (t *SyslogTarget) handleMessageRFC3164(connLabels labels.Labels, msg syslog
 	t.messages <- message{filtered, m, timestamp}
 }
 
+// rfc3164Timestamp completes timestamps in the RFC3164 format, which have
+// neither a year nor a timezone, according to the options of the target.
+func (t *SyslogTarget) rfc3164Timestamp(ts time.Time, now time.Time) time.Time {
+	// Timestamps with a year, like RFC3339 timestamps, are already complete.
+	if ts.Year() != 0 {
+		return ts
+	}
+
+	loc := t.options.RFC3164Timezone
+	if loc == nil {
+		loc = time.UTC
+	}
+	year := 0
+	if t.options.RFC3164DefaultToCurrentYear {
+		// Messages sent around new year can be from the previous or the next
+		// year, depending on the clocks of the devices.
+		var closest time.Duration
+		for y := now.In(loc).Year() - 1; y <= now.In(loc).Year()+1; y++ {
+			candidate := time.Date(y, ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+			if d := candidate.Sub(now).Abs(); year == 0 || d < closest {
+				year, closest = y, d
+			}
+		}
+	}
+	return time.Date(year, ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+}
+
+// normalizeHostname lowercases a hostname and removes the trailing dot of
+// fully qualified names.
+func normalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(hostname), ".")
+}
+
 func (t *SyslogTarget) handleMessage(connLabels labels.Labels, msg syslog.Message) {
 	if t.config.IsRFC3164Message() {
 		t.handleMessageRFC3164(connLabels, msg)
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -19,7 +20,6 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
-	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/syslog/syslogparser"
 	"github.com/leodido/go-syslog/v4"
 	promconfig "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
@@ -321,7 +321,7 @@ func Benchmark_SyslogTarget(b *testing.B) {
 				Labels: model.LabelSet{
 					"test": "syslog_target",
 				},
-			})
+			}, Options{})
 			b.Cleanup(func() {
 				require.NoError(b, tgt.Stop())
 			})
@@ -384,7 +384,7 @@ func TestSyslogTarget(t *testing.T) {
 				Labels: model.LabelSet{
 					"test": "syslog_target",
 				},
-			})
+			}, Options{})
 			require.NoError(t, err)
 
 			require.Eventually(t, tgt.Ready, time.Second, 10*time.Millisecond)
@@ -499,7 +499,7 @@ func TestSyslogTarget_RFC5424Messages(t *testing.T) {
 					"test": "syslog_target",
 				},
 				UseRFC5424Message: true,
-			})
+			}, Options{})
 			require.NoError(t, err)
 			require.Eventually(t, tgt.Ready, time.Second, 10*time.Millisecond)
 			defer func() {
@@ -546,7 +546,7 @@ func TestSyslogTarget_TLSConfigWithoutServerCertificate(t *testing.T) {
 		TLSConfig: promconfig.TLSConfig{
 			KeyFile: "foo",
 		},
-	})
+	}, Options{})
 	require.Error(t, err, "error setting up syslog target: certificate and key files are required")
 }
 
@@ -561,7 +561,7 @@ func TestSyslogTarget_TLSConfigWithoutServerKey(t *testing.T) {
 		TLSConfig: promconfig.TLSConfig{
 			CertFile: "foo",
 		},
-	})
+	}, Options{})
 	require.Error(t, err, "error setting up syslog target: certificate and key files are required")
 }
 
@@ -605,7 +605,7 @@ func testSyslogTargetWithTLS(t *testing.T, fmtFunc formatFunc) {
 			CertFile: serverCertFile.Name(),
 			KeyFile:  serverKeyFile.Name(),
 		},
-	})
+	}, Options{})
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, tgt.Stop())
@@ -738,7 +738,7 @@ func testSyslogTargetWithTLSVerifyClientCertificate(t *testing.T, fmtFunc format
 			CertFile: serverCertFile.Name(),
 			KeyFile:  serverKeyFile.Name(),
 		},
-	})
+	}, Options{})
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, tgt.Stop())
@@ -807,7 +807,7 @@ func TestSyslogTarget_InvalidData(t *testing.T) {
 
 	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
-	})
+	}, Options{})
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, tgt.Stop())
@@ -838,7 +838,7 @@ func TestSyslogTarget_NonUTF8Message(t *testing.T) {
 
 	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
-	})
+	}, Options{})
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, tgt.Stop())
@@ -877,7 +877,7 @@ func TestSyslogTarget_IdleTimeout(t *testing.T) {
 	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 		IdleTimeout:   time.Millisecond,
-	})
+	}, Options{})
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, tgt.Stop())
@@ -919,7 +919,107 @@ func TestParseStream_WithAsyncPipe(t *testing.T) {
 		results = append(results, res)
 	}
 
-	err := syslogparser.ParseStream(false, pipe, cb, DefaultMaxMessageLength)
+	err := parseStream(false, FramingAuto, pipe, cb, DefaultMaxMessageLength)
 	require.NoError(t, err)
 	require.Equal(t, 3, len(results))
 }
+
+func TestParseStream_Framing(t *testing.T) {
+	const msg = "<165>1 2018-10-11T22:14:15.003Z host5 e - id1 - An application event log entry..."
+	var (
+		nonTransparent = msg + "\n"
+		octetCounted   = fmt.Sprintf("%d %s", len(msg), msg)
+	)
+
+	tests := []struct {
+		name     string
+		framing  string
+		input    string
+		expected int
+		err      string
+	}{
+		{name: "auto non-transparent", framing: FramingAuto, input: nonTransparent + nonTransparent, expected: 2},
+		{name: "auto octet-counting", framing: FramingAuto, input: octetCounted + octetCounted, expected: 2},
+		{name: "auto with leading padding", framing: FramingAuto, input: "\x00\r\n " + octetCounted, expected: 1},
+		{name: "explicit non-transparent", framing: FramingNonTransparent, input: nonTransparent, expected: 1},
+		{name: "explicit octet-counting", framing: FramingOctetCounting, input: octetCounted, expected: 1},
+		{name: "mismatched framing", framing: FramingOctetCounting, input: nonTransparent, err: "invalid or unsupported framing. first byte: '<'"},
+		{name: "invalid first byte", framing: FramingAuto, input: "hello", err: "invalid or unsupported framing. first byte: 'h'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []*syslog.Result
+			err := parseStream(false, tt.framing, strings.NewReader(tt.input), func(res *syslog.Result) {
+				results = append(results, res)
+			}, DefaultMaxMessageLength)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, tt.expected)
+			for _, res := range results {
+				require.NoError(t, res.Error)
+			}
+		})
+	}
+}
+
+func TestSyslogTarget_RFC3164Timestamp(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// RFC3164 timestamps are parsed without a year, in UTC.
+	stamp := time.Date(0, time.December, 31, 23, 59, 0, 0, time.UTC)
+	now := time.Date(2024, time.January, 1, 0, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		options  Options
+		ts       time.Time
+		expected time.Time
+	}{
+		{
+			name:     "defaults",
+			ts:       stamp,
+			expected: stamp,
+		},
+		{
+			name:     "previous year around new year",
+			options:  Options{RFC3164DefaultToCurrentYear: true},
+			ts:       stamp,
+			expected: time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC),
+		},
+		{
+			name:     "current year",
+			options:  Options{RFC3164DefaultToCurrentYear: true},
+			ts:       time.Date(0, time.January, 1, 0, 0, 10, 0, time.UTC),
+			expected: time.Date(2024, time.January, 1, 0, 0, 10, 0, time.UTC),
+		},
+		{
+			name:     "timezone",
+			options:  Options{RFC3164DefaultToCurrentYear: true, RFC3164Timezone: berlin},
+			ts:       time.Date(0, time.January, 1, 1, 0, 10, 0, time.UTC),
+			expected: time.Date(2024, time.January, 1, 0, 0, 10, 0, time.UTC),
+		},
+		{
+			name:     "timestamp with a year",
+			options:  Options{RFC3164DefaultToCurrentYear: true, RFC3164Timezone: berlin},
+			ts:       time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &SyslogTarget{options: tt.options}
+			require.True(t, tt.expected.Equal(target.rfc3164Timestamp(tt.ts, now)), "got %s", target.rfc3164Timestamp(tt.ts, now))
+		})
+	}
+}
+
+func TestNormalizeHostname(t *testing.T) {
+	require.Equal(t, "host.example.com", normalizeHostname("Host.Example.COM."))
+	require.Equal(t, "host", normalizeHostname("host"))
+}
@@ -20,7 +20,6 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
-	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/syslog/syslogparser"
 	"github.com/leodido/go-syslog/v4"
 	"github.com/mwitkow/go-conntrack"
 	"github.com/prometheus/common/config"
@@ -46,8 +45,9 @@ type handleMessage func(labels.Labels, syslog.Message)
 type handleMessageError func(error)
 
 type baseTransport struct {
-	config *scrapeconfig.SyslogTargetConfig
-	logger log.Logger
+	config  *scrapeconfig.SyslogTargetConfig
+	options Options
+	logger  log.Logger
 
 	openConnections *sync.WaitGroup
 
@@ -107,10 +107,11 @@ func lookupAddr(addr string) string {
 	return strings.Join(names, ",")
 }
 
-func newBaseTransport(config *scrapeconfig.SyslogTargetConfig, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) *baseTransport {
+func newBaseTransport(config *scrapeconfig.SyslogTargetConfig, options Options, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) *baseTransport {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &baseTransport{
 		config:             config,
+		options:            options,
 		logger:             logger,
 		openConnections:    new(sync.WaitGroup),
 		handleMessage:      handleMessage,
@@ -163,9 +164,9 @@ type TCPTransport struct {
 	listener net.Listener
 }
 
-func NewSyslogTCPTransport(config *scrapeconfig.SyslogTargetConfig, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) Transport {
+func NewSyslogTCPTransport(config *scrapeconfig.SyslogTargetConfig, options Options, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) Transport {
 	return &TCPTransport{
-		baseTransport: newBaseTransport(config, handleMessage, handleError, logger),
+		baseTransport: newBaseTransport(config, options, handleMessage, handleError, logger),
 	}
 }
 
@@ -321,7 +322,7 @@ func (t *TCPTransport) handleConnection(cn net.Conn) {
 
 	lbs := t.connectionLabels(ipFromConn(c).String())
 
-	err := syslogparser.ParseStream(t.config.IsRFC3164Message(), c, func(result *syslog.Result) {
+	err := parseStream(t.config.IsRFC3164Message(), t.options.Framing, c, func(result *syslog.Result) {
 		if err := result.Error; err != nil {
 			t.handleMessageError(err)
 			return
@@ -355,9 +356,9 @@ type UDPTransport struct {
 	udpConn *net.UDPConn
 }
 
-func NewSyslogUDPTransport(config *scrapeconfig.SyslogTargetConfig, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) Transport {
+func NewSyslogUDPTransport(config *scrapeconfig.SyslogTargetConfig, options Options, handleMessage handleMessage, handleError handleMessageError, logger log.Logger) Transport {
 	return &UDPTransport{
-		baseTransport: newBaseTransport(config, handleMessage, handleError, logger),
+		baseTransport: newBaseTransport(config, options, handleMessage, handleError, logger),
 	}
 }
 
@@ -446,7 +447,7 @@ func (t *UDPTransport) handleRcv(c *ConnPipe) {
 
 		r := bytes.NewReader(datagram[:n])
 
-		err = syslogparser.ParseStream(t.config.IsRFC3164Message(), r, func(result *syslog.Result) {
+		err = parseStream(t.config.IsRFC3164Message(), t.options.Framing, r, func(result *syslog.Result) {
 			if err := result.Error; err != nil {
 				t.handleMessageError(err)
 			} else {
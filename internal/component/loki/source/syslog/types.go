@@ -30,6 +30,11 @@ type ListenerConfig struct {
 	MaxMessageLength     int               `alloy:"max_message_length,attr,optional"`
 	TLSConfig            config.TLSConfig  `alloy:"tls_config,block,optional"`
 	SyslogFormat         string            `alloy:"syslog_format,attr,optional"`
+	Framing              string            `alloy:"framing,attr,optional"`
+
+	RFC3164DefaultToCurrentYear bool   `alloy:"rfc3164_default_to_current_year,attr,optional"`
+	RFC3164DefaultTimezone      string `alloy:"rfc3164_default_timezone,attr,optional"`
+	RFC3164NormalizeHostname    bool   `alloy:"rfc3164_normalize_hostname,attr,optional"`
 }
 
 // DefaultListenerConfig provides the default arguments for a syslog listener.
@@ -38,6 +43,7 @@ var DefaultListenerConfig = ListenerConfig{
 	IdleTimeout:      st.DefaultIdleTimeout,
 	MaxMessageLength: st.DefaultMaxMessageLength,
 	SyslogFormat:     SyslogFormatRFC5424,
+	Framing:          st.FramingAuto,
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -56,9 +62,36 @@ func (sc *ListenerConfig) Validate() error {
 		return err
 	}
 
+	switch sc.Framing {
+	case st.FramingAuto, st.FramingOctetCounting, st.FramingNonTransparent:
+	default:
+		return fmt.Errorf("syslog listener framing should be one of %q, %q or %q, got %q", st.FramingAuto, st.FramingOctetCounting, st.FramingNonTransparent, sc.Framing)
+	}
+
+	if sc.RFC3164DefaultTimezone != "" {
+		if _, err := time.LoadLocation(sc.RFC3164DefaultTimezone); err != nil {
+			return fmt.Errorf("invalid rfc3164_default_timezone %q: %w", sc.RFC3164DefaultTimezone, err)
+		}
+	}
+
 	return nil
 }
 
+// Options returns the options of the listener which aren't part of the
+// Promtail configuration.
+func (sc ListenerConfig) Options() st.Options {
+	opts := st.Options{
+		Framing:                     sc.Framing,
+		RFC3164DefaultToCurrentYear: sc.RFC3164DefaultToCurrentYear,
+		RFC3164NormalizeHostname:    sc.RFC3164NormalizeHostname,
+	}
+	if sc.RFC3164DefaultTimezone != "" {
+		// The timezone was checked by Validate.
+		opts.RFC3164Timezone, _ = time.LoadLocation(sc.RFC3164DefaultTimezone)
+	}
+	return opts
+}
+
 // Convert is used to bridge between the Alloy and Promtail types.
 func (sc ListenerConfig) Convert() (*scrapeconfig.SyslogTargetConfig, error) {
 	lbls := make(model.LabelSet, len(sc.Labels))
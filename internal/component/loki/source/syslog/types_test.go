@@ -35,6 +35,27 @@ func TestValidate(t *testing.T) {
 			},
 			errSubstring: "unknown syslog format",
 		},
+		{
+			name: "InvalidFraming",
+			scFn: func(sc *ListenerConfig) {
+				sc.Framing = "invalid"
+			},
+			errSubstring: "syslog listener framing should be",
+		},
+		{
+			name: "ValidTimezone",
+			scFn: func(sc *ListenerConfig) {
+				sc.RFC3164DefaultTimezone = "Europe/Berlin"
+			},
+			errSubstring: "",
+		},
+		{
+			name: "InvalidTimezone",
+			scFn: func(sc *ListenerConfig) {
+				sc.RFC3164DefaultTimezone = "Nowhere/Invalid"
+			},
+			errSubstring: "invalid rfc3164_default_timezone",
+		},
 	}
 
 	for _, tt := range tests {
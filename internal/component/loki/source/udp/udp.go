@@ -0,0 +1,167 @@
+package udp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/loki/source/internal/sockettarget"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.source.udp",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.source.udp
+// component.
+type Arguments struct {
+	ListenAddress    string            `alloy:"listen_address,attr"`
+	Framing          string            `alloy:"framing,attr,optional"`
+	MaxMessageLength int               `alloy:"max_message_length,attr,optional"`
+	Labels           map[string]string `alloy:"labels,attr,optional"`
+	SourceIPLabel    string            `alloy:"source_ip_label,attr,optional"`
+
+	ForwardTo []loki.LogsReceiver `alloy:"forward_to,attr"`
+}
+
+// DefaultArguments provides the default arguments of loki.source.udp.
+var DefaultArguments = Arguments{
+	Framing:          sockettarget.FramingNewline,
+	MaxMessageLength: sockettarget.DefaultMaxMessageLength,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if err := sockettarget.ValidateFraming(a.Framing); err != nil {
+		return err
+	}
+	if a.MaxMessageLength <= 0 {
+		return fmt.Errorf("max_message_length must be greater than 0")
+	}
+	if a.SourceIPLabel != "" && !model.LabelName(a.SourceIPLabel).IsValid() {
+		return fmt.Errorf("invalid source_ip_label %q", a.SourceIPLabel)
+	}
+	return nil
+}
+
+// listenerConfig returns the configuration of the listener.
+func (a *Arguments) listenerConfig() sockettarget.Config {
+	labels := make(model.LabelSet, len(a.Labels))
+	for k, v := range a.Labels {
+		labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	return sockettarget.Config{
+		Address:          a.ListenAddress,
+		Framing:          a.Framing,
+		MaxMessageLength: a.MaxMessageLength,
+		Labels:           labels,
+		SourceIPLabel:    model.LabelName(a.SourceIPLabel),
+	}
+}
+
+// Component implements the loki.source.udp component.
+type Component struct {
+	opts    component.Options
+	metrics *sockettarget.Metrics
+	handler loki.LogsReceiver
+
+	mut      sync.RWMutex
+	args     Arguments
+	fanout   []loki.LogsReceiver
+	listener *sockettarget.UDPListener
+}
+
+// New creates a new loki.source.udp component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:    o,
+		metrics: sockettarget.NewMetrics(o.Registerer, "loki_source_udp", false),
+		handler: loki.NewLogsReceiver(),
+	}
+
+	// Call to Update() to start the listener and set receivers once at the
+	// start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		if c.listener != nil {
+			c.listener.Stop()
+			c.listener = nil
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.handler.Chan():
+			c.mut.RLock()
+			for _, receiver := range c.fanout {
+				receiver.Chan() <- entry
+			}
+			c.mut.RUnlock()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.fanout = newArgs.ForwardTo
+
+	// Only restart the listener if its configuration changed, so that no
+	// packets are lost when only forward_to changes.
+	oldArgs, listenerArgs := c.args, newArgs
+	oldArgs.ForwardTo, listenerArgs.ForwardTo = nil, nil
+	if c.listener != nil && reflect.DeepEqual(oldArgs, listenerArgs) {
+		return nil
+	}
+
+	if c.listener != nil {
+		c.listener.Stop()
+		c.listener = nil
+	}
+
+	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
+	l, err := sockettarget.NewUDPListener(c.opts.Logger, c.metrics, entryHandler, newArgs.listenerConfig())
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to start UDP listener", "err", err)
+		return err
+	}
+	c.listener = l
+	c.args = newArgs
+	return nil
+}
@@ -0,0 +1,111 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/loki/source/internal/sockettarget"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestAlloyConfig(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+	listen_address  = "0.0.0.0:5171"
+	framing         = "octet_counting"
+	labels          = {job = "appliance"}
+	source_ip_label = "source_ip"
+	forward_to = []
+`), &args)
+	require.NoError(t, err)
+	require.Equal(t, sockettarget.FramingOctetCounting, args.Framing)
+	require.Equal(t, sockettarget.DefaultMaxMessageLength, args.MaxMessageLength)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "invalid framing",
+			config: `
+	listen_address = "0.0.0.0:5171"
+	framing        = "null"
+	forward_to     = []`,
+			err: `framing must be "newline" or "octet_counting", got "null"`,
+		},
+		{
+			name: "invalid max message length",
+			config: `
+	listen_address     = "0.0.0.0:5171"
+	max_message_length = 0
+	forward_to         = []`,
+			err: "max_message_length must be greater than 0",
+		},
+		{
+			name: "invalid source ip label",
+			config: `
+	listen_address  = "0.0.0.0:5171"
+	source_ip_label = "source-ip"
+	forward_to      = []`,
+			err: `invalid source_ip_label "source-ip"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestComponent(t *testing.T) {
+	receiver := loki.NewLogsReceiver()
+	args := DefaultArguments
+	args.ListenAddress = "127.0.0.1:0"
+	args.Labels = map[string]string{"job": "appliance"}
+	args.ForwardTo = []loki.LogsReceiver{receiver}
+
+	c, err := New(component.Options{
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	// Changing forward_to doesn't restart the listener.
+	addr := c.listener.Addr().String()
+	require.NoError(t, c.Update(args))
+	require.Equal(t, addr, c.listener.Addr().String())
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	select {
+	case e := <-receiver.Chan():
+		require.Equal(t, "hello", e.Line)
+		require.Equal(t, model.LabelSet{"job": "appliance"}, e.Labels)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for the entry")
+	}
+}
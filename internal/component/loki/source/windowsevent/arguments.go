@@ -5,6 +5,7 @@ package windowsevent
 // https://github.com/grafana/loki/blob/bde65667f7c88af17b7729e3621d7bd5d1d3b45f/clients/pkg/promtail/scrapeconfig/scrapeconfig.go#L211-L255
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
@@ -25,8 +26,20 @@ type Arguments struct {
 	ForwardTo            []loki.LogsReceiver `alloy:"forward_to,attr"`
 	Labels               map[string]string   `alloy:"labels,attr,optional"`
 	LegacyBookmarkPath   string              `alloy:"legacy_bookmark_path,attr,optional"`
+
+	EventDataFormat       string        `alloy:"event_data_format,attr,optional"`
+	BookmarkFlushInterval time.Duration `alloy:"bookmark_flush_interval,attr,optional"`
 }
 
+// Formats of the event data and user data of the events.
+const (
+	// EventDataFormatXML renders the event data and user data as their XML.
+	EventDataFormatXML = "xml"
+	// EventDataFormatFields renders the event data and user data as JSON
+	// objects of their fields.
+	EventDataFormatFields = "fields"
+)
+
 func defaultArgs() Arguments {
 	return Arguments{
 		Locale:               0,
@@ -38,6 +51,7 @@ func defaultArgs() Arguments {
 		ExcludeUserdata:      false,
 		ExcludeEventMessage:  false,
 		UseIncomingTimestamp: false,
+		EventDataFormat:      EventDataFormatXML,
 	}
 }
 
@@ -45,3 +59,14 @@ func defaultArgs() Arguments {
 func (r *Arguments) SetToDefault() {
 	*r = defaultArgs()
 }
+
+// Validate implements syntax.Validator.
+func (r *Arguments) Validate() error {
+	if r.EventDataFormat != EventDataFormatXML && r.EventDataFormat != EventDataFormatFields {
+		return fmt.Errorf("unsupported event_data_format %q, must be %q or %q", r.EventDataFormat, EventDataFormatXML, EventDataFormatFields)
+	}
+	if r.BookmarkFlushInterval < 0 {
+		return fmt.Errorf("bookmark_flush_interval must not be negative")
+	}
+	return nil
+}
@@ -22,6 +22,11 @@ type bookMark struct {
 	isNew  bool
 	path   string
 	buf    []byte
+
+	// current is the rendered bookmark at the last updated event, and saved
+	// the one in the file.
+	current string
+	saved   string
 }
 
 // newBookMark creates a new windows event bookmark.
@@ -74,18 +79,35 @@ func newBookMark(path string) (*bookMark, error) {
 		}
 	}
 	return &bookMark{
-		handle: bm,
-		path:   path,
-		isNew:  fileString == "",
-		buf:    buf,
+		handle:  bm,
+		path:    path,
+		isNew:   fileString == "",
+		buf:     buf,
+		current: fileString,
+		saved:   fileString,
 	}, nil
 }
 
-// save Saves the bookmark at the current event position.
-func (b *bookMark) save(event win_eventlog.EvtHandle) error {
+// update moves the bookmark to the given event. The bookmark is only saved to
+// its file by flush.
+func (b *bookMark) update(event win_eventlog.EvtHandle) error {
 	newBookmark, err := win_eventlog.UpdateBookmark(b.handle, event, b.buf)
 	if err != nil {
 		return err
 	}
-	return atomic.WriteFile(b.path, bytes.NewReader([]byte(newBookmark)))
+	b.current = newBookmark
+	return nil
+}
+
+// flush saves the bookmark to its file if it moved since it was last saved.
+// The file is replaced atomically, so it always holds a complete bookmark.
+func (b *bookMark) flush() error {
+	if b.current == b.saved {
+		return nil
+	}
+	if err := atomic.WriteFile(b.path, bytes.NewReader([]byte(b.current))); err != nil {
+		return err
+	}
+	b.saved = b.current
+	return nil
 }
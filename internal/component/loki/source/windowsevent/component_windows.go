@@ -116,17 +116,23 @@ func (c *Component) Update(args component.Arguments) error {
 		return err
 	}
 
-	winTarget, err := NewTarget(c.opts.Logger, c.handle, nil, convertConfig(newArgs))
-	if err != nil {
-		return err
-	}
-	// Stop the original target.
+	// Stop the original target first, so that it saves its bookmark before
+	// the new target reads it.
 	if c.target != nil {
 		err := c.target.Stop()
+		c.target = nil
 		if err != nil {
 			return err
 		}
 	}
+
+	winTarget, err := NewTarget(c.opts.Logger, c.handle, nil, convertConfig(newArgs), targetOptions{
+		EventDataFormat:       newArgs.EventDataFormat,
+		BookmarkFlushInterval: newArgs.BookmarkFlushInterval,
+	})
+	if err != nil {
+		return err
+	}
 	c.target = winTarget
 
 	c.args = newArgs
@@ -0,0 +1,106 @@
+package windowsevent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlField is an XML element rendered as a field.
+type xmlField struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Value    string     `xml:",chardata"`
+	InnerXML []byte     `xml:",innerxml"`
+}
+
+// eventDataFields returns the fields of the inner XML of the EventData
+// element of an event. Fields are named after the Name attribute of their
+// element. Elements without a Name attribute, like the Data elements of
+// classic events, are named after the element and their position among the
+// elements of the same name, starting at 1.
+func eventDataFields(innerXML []byte) (map[string]string, error) {
+	elements, err := xmlElements(innerXML)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(elements))
+	unnamed := make(map[string]int)
+	for _, e := range elements {
+		name := attrValue(e.Attrs, "Name")
+		if name == "" {
+			unnamed[e.XMLName.Local]++
+			name = fmt.Sprintf("%s%d", e.XMLName.Local, unnamed[e.XMLName.Local])
+		}
+		fields[name] = e.value()
+	}
+	return fields, nil
+}
+
+// userDataFields returns the fields of the inner XML of the UserData element
+// of an event. The UserData element holds a single element defined by the
+// event provider, whose child elements are the fields.
+func userDataFields(innerXML []byte) (map[string]string, error) {
+	elements, err := xmlElements(innerXML)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	for _, root := range elements {
+		children, err := xmlElements(root.InnerXML)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range children {
+			fields[e.XMLName.Local] = e.value()
+		}
+	}
+	return fields, nil
+}
+
+// xmlElements decodes the top-level elements of an XML fragment.
+func xmlElements(fragment []byte) ([]xmlField, error) {
+	var elements []xmlField
+	d := xml.NewDecoder(bytes.NewReader(fragment))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return elements, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var e xmlField
+		if err := d.DecodeElement(&e, &start); err != nil {
+			return nil, err
+		}
+		elements = append(elements, e)
+	}
+}
+
+// value returns the text of the element, or its inner XML if it has child
+// elements.
+func (e xmlField) value() string {
+	if bytes.ContainsRune(e.InnerXML, '<') {
+		return strings.TrimSpace(string(e.InnerXML))
+	}
+	return strings.TrimSpace(e.Value)
+}
+
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
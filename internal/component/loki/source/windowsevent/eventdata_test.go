@@ -0,0 +1,93 @@
+package windowsevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestEventDataFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		innerXML string
+		expected map[string]string
+	}{
+		{
+			name: "named data",
+			innerXML: `<Data Name='SubjectUserSid'>S-1-5-18</Data>
+				<Data Name='SubjectUserName'>WIN-HOST$</Data>
+				<Data Name='TargetLogonId'>0x3e7</Data>
+				<Data Name='Empty'></Data>`,
+			expected: map[string]string{
+				"SubjectUserSid":  "S-1-5-18",
+				"SubjectUserName": "WIN-HOST$",
+				"TargetLogonId":   "0x3e7",
+				"Empty":           "",
+			},
+		},
+		{
+			name:     "unnamed data",
+			innerXML: `<Data>first</Data><Data>second &amp; third</Data><Binary>00FF</Binary>`,
+			expected: map[string]string{
+				"Data1":   "first",
+				"Data2":   "second & third",
+				"Binary1": "00FF",
+			},
+		},
+		{
+			name:     "empty",
+			innerXML: ``,
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := eventDataFields([]byte(tc.innerXML))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fields)
+		})
+	}
+
+	_, err := eventDataFields([]byte(`<Data Name='a'>unclosed`))
+	require.Error(t, err)
+}
+
+func TestUserDataFields(t *testing.T) {
+	fields, err := userDataFields([]byte(`<LogFileCleared xmlns='http://manifests.microsoft.com/win/2004/08/windows/eventlog'>
+		<SubjectUserName>admin</SubjectUserName>
+		<SubjectDomainName>CORP</SubjectDomainName>
+		<Details><Reason>manual</Reason></Details>
+	</LogFileCleared>`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"SubjectUserName":   "admin",
+		"SubjectDomainName": "CORP",
+		"Details":           "<Reason>manual</Reason>",
+	}, fields)
+}
+
+func TestInvalidAlloyConfig(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		eventlog_name     = "Application"
+		forward_to        = []
+		event_data_format = "yaml"
+	`), &args)
+	require.EqualError(t, err, `unsupported event_data_format "yaml", must be "xml" or "fields"`)
+
+	err = syntax.Unmarshal([]byte(`
+		eventlog_name           = "Application"
+		forward_to              = []
+		bookmark_flush_interval = "-1s"
+	`), &args)
+	require.EqualError(t, err, "bookmark_flush_interval must not be negative")
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		eventlog_name = "Application"
+		forward_to    = []
+	`), &args))
+	require.Equal(t, EventDataFormatXML, args.EventDataFormat)
+}
@@ -36,9 +36,11 @@ type Event struct {
 	Correlation   *Correlation `json:"correlation,omitempty"`
 	Execution     *Execution   `json:"execution,omitempty"`
 
+	// UserData and EventData are either strings of XML, or maps of their
+	// fields.
 	Security  *Security `json:"security,omitempty"`
-	UserData  string    `json:"user_data,omitempty"`
-	EventData string    `json:"event_data,omitempty"`
+	UserData  any       `json:"user_data,omitempty"`
+	EventData any       `json:"event_data,omitempty"`
 	Message   string    `json:"message,omitempty"`
 }
 
@@ -59,7 +61,7 @@ type Correlation struct {
 }
 
 // formatLine format a Loki log line from a windows event.
-func formatLine(cfg *scrapeconfig.WindowsEventsTargetConfig, event win_eventlog.Event) (string, error) {
+func formatLine(cfg *scrapeconfig.WindowsEventsTargetConfig, eventDataFormat string, event win_eventlog.Event) (string, error) {
 	structuredEvent := Event{
 		Source:        event.Source.Name,
 		Channel:       event.Channel,
@@ -77,11 +79,11 @@ func formatLine(cfg *scrapeconfig.WindowsEventsTargetConfig, event win_eventlog.
 		EventRecordID: event.EventRecordID,
 	}
 
-	if !cfg.ExcludeEventData {
-		structuredEvent.EventData = string(event.EventData.InnerXML)
+	if !cfg.ExcludeEventData && len(event.EventData.InnerXML) > 0 {
+		structuredEvent.EventData = formatData(event.EventData.InnerXML, eventDataFormat, eventDataFields)
 	}
-	if !cfg.ExcludeUserData {
-		structuredEvent.UserData = string(event.UserData.InnerXML)
+	if !cfg.ExcludeUserData && len(event.UserData.InnerXML) > 0 {
+		structuredEvent.UserData = formatData(event.UserData.InnerXML, eventDataFormat, userDataFields)
 	}
 	if !cfg.ExcludeEventMessage {
 		structuredEvent.Message = event.Message
@@ -119,3 +121,15 @@ func formatLine(cfg *scrapeconfig.WindowsEventsTargetConfig, event win_eventlog.
 	}
 	return jsoniter.MarshalToString(structuredEvent)
 }
+
+// formatData returns the fields of the inner XML of the event data or user
+// data of an event if eventDataFormat is EventDataFormatFields, or the XML
+// itself otherwise or if it can't be parsed.
+func formatData(innerXML []byte, eventDataFormat string, parseFields func([]byte) (map[string]string, error)) any {
+	if eventDataFormat == EventDataFormatFields {
+		if fields, err := parseFields(innerXML); err == nil {
+			return fields
+		}
+	}
+	return string(innerXML)
+}
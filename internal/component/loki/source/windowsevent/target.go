@@ -25,12 +25,25 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/windows/win_eventlog"
 
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+
+	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 )
 
+// targetOptions holds the settings of a Target which aren't part of
+// Promtail's config.
+type targetOptions struct {
+	// EventDataFormat is the format of the event data and user data of the
+	// events, and BookmarkFlushInterval the minimum time between saves of
+	// the bookmark. The bookmark is saved after each batch of events if it's 0.
+	EventDataFormat       string
+	BookmarkFlushInterval time.Duration
+}
+
 type Target struct {
 	subscription  win_eventlog.EvtHandle
 	handler       api.EntryHandler
 	cfg           *scrapeconfig.WindowsEventsTargetConfig
+	opts          targetOptions
 	relabelConfig []*relabel.Config
 	logger        log.Logger
 
@@ -49,6 +62,7 @@ func NewTarget(
 	handler api.EntryHandler,
 	relabel []*relabel.Config,
 	cfg *scrapeconfig.WindowsEventsTargetConfig,
+	opts targetOptions,
 ) (*Target, error) {
 	sigEvent, err := windows.CreateEvent(nil, 0, 0, nil)
 	if err != nil {
@@ -64,6 +78,7 @@ func NewTarget(
 	t := &Target{
 		done:          make(chan struct{}),
 		cfg:           cfg,
+		opts:          opts,
 		bm:            bm,
 		relabelConfig: relabel,
 		logger:        logger,
@@ -99,7 +114,10 @@ func (t *Target) loop() {
 	t.ready = true
 	t.wg.Add(1)
 	interval := time.NewTicker(t.cfg.PollInterval)
+	lastFlush := time.Now()
 	defer func() {
+		// Save the position of the events which were sent before stopping.
+		t.flushBookmark()
 		t.ready = false
 		t.wg.Done()
 		interval.Stop()
@@ -120,25 +138,67 @@ func (t *Target) loop() {
 			}
 			t.err = nil
 			// we have received events to handle.
-			for i, entry := range t.renderEntries(events) {
-				t.handler.Chan() <- entry
-				if err := t.bm.save(handles[i]); err != nil {
-					t.err = err
-					level.Error(util_log.Logger).Log("msg", "error saving bookmark", "err", err)
-				}
-			}
+			sent := t.sendEvents(events, handles)
 			win_eventlog.Close(handles)
+			if !sent {
+				return
+			}
 
+			if time.Since(lastFlush) >= t.opts.BookmarkFlushInterval {
+				t.flushBookmark()
+				lastFlush = time.Now()
+			}
 		}
 		// no more messages we wait for next poll timer tick.
 		select {
 		case <-t.done:
 			return
 		case <-interval.C:
+			if time.Since(lastFlush) >= t.opts.BookmarkFlushInterval {
+				t.flushBookmark()
+				lastFlush = time.Now()
+			}
 		}
 	}
 }
 
+// sendEvents sends the entries of a batch of events to the handler. The
+// bookmark is only moved past the batch once all of its entries were sent, so
+// that no event is lost if the target is stopped in the middle of the batch.
+// It returns false if the target was stopped before all entries were sent.
+func (t *Target) sendEvents(events []win_eventlog.Event, handles []win_eventlog.EvtHandle) bool {
+	for _, entry := range t.renderEntries(events) {
+		select {
+		case t.handler.Chan() <- entry:
+		case <-t.done:
+			return false
+		}
+	}
+
+	// Events which failed to be rendered are skipped by the fetcher, so the
+	// bookmark is moved to the last handle of the batch rather than to the
+	// handle of the last entry.
+	for i := len(handles) - 1; i >= 0; i-- {
+		if handles[i] == 0 {
+			continue
+		}
+		if err := t.bm.update(handles[i]); err != nil {
+			t.err = err
+			level.Error(util_log.Logger).Log("msg", "error updating bookmark", "err", err)
+		}
+		break
+	}
+	return true
+}
+
+// flushBookmark saves the bookmark to its file.
+func (t *Target) flushBookmark() {
+	if err := t.bm.flush(); err != nil {
+		t.err = err
+		level.Error(util_log.Logger).Log("msg", "error saving bookmark", "err", err)
+	}
+}
+
 // renderEntries renders Loki entries from windows event logs
 func (t *Target) renderEntries(events []win_eventlog.Event) []api.Entry {
 	res := make([]api.Entry, 0, len(events))
@@ -169,7 +229,7 @@ func (t *Target) renderEntries(events []win_eventlog.Event) []api.Entry {
 			lbs.Set("computer", event.Computer)
 		}
 		// apply relabelings.
-		processed, _ := relabel.Process(lbs.Labels(), t.relabelConfig...)
+		processed, _ := alloy_relabel.Process(lbs.Labels(), t.relabelConfig...)
 
 		for _, lbl := range processed {
 			if strings.HasPrefix(lbl.Name, "__") {
@@ -178,7 +238,7 @@ func (t *Target) renderEntries(events []win_eventlog.Event) []api.Entry {
 			entry.Labels[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
 		}
 
-		line, err := formatLine(t.cfg, event)
+		line, err := formatLine(t.cfg, t.opts.EventDataFormat, event)
 		if err != nil {
 			level.Warn(t.logger).Log("msg", "error formatting event", "err", err)
 			continue
@@ -28,9 +28,16 @@ type EndpointOptions struct {
 	MaxBackoff        time.Duration           `alloy:"max_backoff_period,attr,optional"`  // increase exponentially to this level
 	MaxBackoffRetries int                     `alloy:"max_backoff_retries,attr,optional"` // give up after this many; zero means infinite retries
 	TenantID          string                  `alloy:"tenant_id,attr,optional"`
+	TenantLabel       string                  `alloy:"tenant_label,attr,optional"`
+	TenantRoutes      []TenantRoute           `alloy:"tenant_route,block,optional"`
 	RetryOnHTTP429    bool                    `alloy:"retry_on_http_429,attr,optional"`
+	Compression       string                  `alloy:"compression,attr,optional"`
+	CompressionLevel  int                     `alloy:"compression_level,attr,optional"`
 	HTTPClientConfig  *types.HTTPClientConfig `alloy:",squash"`
 	QueueConfig       QueueConfig             `alloy:"queue_config,block,optional"`
+	Weight            int                     `alloy:"weight,attr,optional"`
+
+	StructuredMetadataLimits StructuredMetadataLimits `alloy:"structured_metadata_limits,block,optional"`
 }
 
 // GetDefaultEndpointOptions defines the default settings for sending logs to a
@@ -48,6 +55,8 @@ func GetDefaultEndpointOptions() EndpointOptions {
 		MaxBackoffRetries: 10,
 		HTTPClientConfig:  types.CloneDefaultHTTPClientConfig(),
 		RetryOnHTTP429:    true,
+		Compression:       client.CompressionSnappy,
+		Weight:            1,
 	}
 
 	return defaultEndpointOptions
@@ -64,6 +73,24 @@ func (r *EndpointOptions) Validate() error {
 		return fmt.Errorf("failed to parse remote url %q: %w", r.URL, err)
 	}
 
+	if err := client.ValidateCompression(r.Compression, r.CompressionLevel); err != nil {
+		return err
+	}
+
+	if err := client.ValidateStructuredMetadataLimits(r.StructuredMetadataLimits.convert()); err != nil {
+		return err
+	}
+
+	if r.Weight < 1 {
+		return fmt.Errorf("weight must be at least 1")
+	}
+
+	for _, route := range r.TenantRoutes {
+		if _, err := client.ParseTenantRoute(route.Selector, route.Tenant); err != nil {
+			return err
+		}
+	}
+
 	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
 	if r.HTTPClientConfig != nil {
 		return r.HTTPClientConfig.Validate()
@@ -72,11 +99,46 @@ func (r *EndpointOptions) Validate() error {
 	return nil
 }
 
-// QueueConfig controls how the queue logs remote write client is configured. Note that this client is only used when the
-// loki.write component has WAL support enabled.
+// TenantRoute sends the entries matching a stream selector to a tenant.
+type TenantRoute struct {
+	Selector string `alloy:"selector,attr"`
+	Tenant   string `alloy:"tenant,attr"`
+}
+
+// StructuredMetadataLimits limits the structured metadata of each log entry.
+type StructuredMetadataLimits struct {
+	MaxEntriesCount int              `alloy:"max_entries_count,attr,optional"`
+	MaxSize         units.Base2Bytes `alloy:"max_size,attr,optional"`
+	OverflowPolicy  string           `alloy:"overflow_policy,attr,optional"`
+	Priority        []string         `alloy:"priority,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (l *StructuredMetadataLimits) SetToDefault() {
+	*l = StructuredMetadataLimits{
+		OverflowPolicy: client.StructuredMetadataDropKeys,
+	}
+}
+
+func (l StructuredMetadataLimits) convert() client.StructuredMetadataLimits {
+	return client.StructuredMetadataLimits{
+		MaxEntriesCount: l.MaxEntriesCount,
+		MaxSize:         int(l.MaxSize),
+		OverflowPolicy:  l.OverflowPolicy,
+		Priority:        l.Priority,
+	}
+}
+
+// QueueConfig controls how batches are queued before being sent to an endpoint. Capacity and DrainTimeout only apply
+// to the queue client used when the loki.write component has WAL support enabled, while MaxQueuedBytes, OnFull and
+// Shards only apply when it's disabled.
 type QueueConfig struct {
-	Capacity     units.Base2Bytes `alloy:"capacity,attr,optional"`
-	DrainTimeout time.Duration    `alloy:"drain_timeout,attr,optional"`
+	Capacity       units.Base2Bytes `alloy:"capacity,attr,optional"`
+	DrainTimeout   time.Duration    `alloy:"drain_timeout,attr,optional"`
+	MaxInFlight    int              `alloy:"max_in_flight,attr,optional"`
+	MaxQueuedBytes units.Base2Bytes `alloy:"max_queued_bytes,attr,optional"`
+	OnFull         string           `alloy:"on_full,attr,optional"`
+	Shards         int              `alloy:"shards,attr,optional"`
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -84,13 +146,43 @@ func (q *QueueConfig) SetToDefault() {
 	*q = QueueConfig{
 		Capacity:     10 * units.MiB, // considering the default BatchSize of 1MiB, this gives us a default buffered channel of size 10
 		DrainTimeout: 15 * time.Second,
+		MaxInFlight:  1,
+		OnFull:       client.QueueFullBlock,
+		Shards:       1,
 	}
 }
 
+// Validate implements syntax.Validator.
+func (q *QueueConfig) Validate() error {
+	if q.MaxInFlight < 1 {
+		return fmt.Errorf("max_in_flight must be at least 1")
+	}
+	if q.MaxQueuedBytes < 0 {
+		return fmt.Errorf("max_queued_bytes must not be negative")
+	}
+	if q.OnFull != client.QueueFullBlock && q.OnFull != client.QueueFullDrop {
+		return fmt.Errorf("unsupported on_full %q, must be %q or %q", q.OnFull, client.QueueFullBlock, client.QueueFullDrop)
+	}
+	if q.Shards < 1 {
+		return fmt.Errorf("shards must be at least 1")
+	}
+	// Shards send the batches of a stream in order, which max_in_flight would break.
+	if q.Shards > 1 && q.MaxInFlight > 1 {
+		return fmt.Errorf("max_in_flight can't be greater than 1 when shards is greater than 1")
+	}
+	return nil
+}
+
 func (args Arguments) convertClientConfigs() []client.Config {
 	var res []client.Config
 	for _, cfg := range args.Endpoints {
 		url, _ := url.Parse(cfg.URL)
+		var routes []client.TenantRoute
+		for _, r := range cfg.TenantRoutes {
+			// Routes have already been validated.
+			route, _ := client.ParseTenantRoute(r.Selector, r.Tenant)
+			routes = append(routes, route)
+		}
 		cc := client.Config{
 			Name:      cfg.Name,
 			URL:       flagext.URLValue{URL: url},
@@ -103,13 +195,22 @@ func (args Arguments) convertClientConfigs() []client.Config {
 				MaxBackoff: cfg.MaxBackoff,
 				MaxRetries: cfg.MaxBackoffRetries,
 			},
-			ExternalLabels:         lokiflagext.LabelSet{LabelSet: utils.ToLabelSet(args.ExternalLabels)},
-			Timeout:                cfg.RemoteTimeout,
-			TenantID:               cfg.TenantID,
-			DropRateLimitedBatches: !cfg.RetryOnHTTP429,
+			ExternalLabels:           lokiflagext.LabelSet{LabelSet: utils.ToLabelSet(args.ExternalLabels)},
+			Timeout:                  cfg.RemoteTimeout,
+			TenantID:                 cfg.TenantID,
+			TenantLabel:              cfg.TenantLabel,
+			TenantRoutes:             routes,
+			DropRateLimitedBatches:   !cfg.RetryOnHTTP429,
+			Compression:              cfg.Compression,
+			CompressionLevel:         cfg.CompressionLevel,
+			StructuredMetadataLimits: cfg.StructuredMetadataLimits.convert(),
 			Queue: client.QueueConfig{
-				Capacity:     int(cfg.QueueConfig.Capacity),
-				DrainTimeout: cfg.QueueConfig.DrainTimeout,
+				Capacity:       int(cfg.QueueConfig.Capacity),
+				DrainTimeout:   cfg.QueueConfig.DrainTimeout,
+				MaxInFlight:    cfg.QueueConfig.MaxInFlight,
+				MaxQueuedBytes: int(cfg.QueueConfig.MaxQueuedBytes),
+				OnFull:         cfg.QueueConfig.OnFull,
+				Shards:         cfg.QueueConfig.Shards,
 			},
 		}
 		res = append(res, cc)
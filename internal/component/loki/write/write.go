@@ -7,6 +7,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/alecthomas/units"
+
 	"github.com/grafana/alloy/internal/alloyseed"
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
@@ -35,22 +37,70 @@ type Arguments struct {
 	ExternalLabels map[string]string `alloy:"external_labels,attr,optional"`
 	MaxStreams     int               `alloy:"max_streams,attr,optional"`
 	WAL            WalArguments      `alloy:"wal,block,optional"`
+	Routing        RoutingArguments  `alloy:"routing,block,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if a.WAL.Enabled && a.Routing.Mode != "" && a.Routing.Mode != client.RoutingFanout {
+		return fmt.Errorf("routing mode %q isn't supported when WAL is enabled", a.Routing.Mode)
+	}
+	return nil
+}
+
+// RoutingArguments holds the settings for routing log entries to the
+// endpoints.
+type RoutingArguments struct {
+	Mode             string        `alloy:"mode,attr,optional"`
+	FailureThreshold int           `alloy:"failure_threshold,attr,optional"`
+	RecoveryInterval time.Duration `alloy:"recovery_interval,attr,optional"`
+}
+
+// DefaultRoutingArguments provides the default settings for routing log
+// entries to the endpoints.
+var DefaultRoutingArguments = RoutingArguments{
+	Mode:             client.RoutingFanout,
+	FailureThreshold: 3,
+	RecoveryInterval: 30 * time.Second,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (ra *RoutingArguments) SetToDefault() {
+	*ra = DefaultRoutingArguments
+}
+
+// Validate implements syntax.Validator.
+func (ra *RoutingArguments) Validate() error {
+	if err := client.ValidateRoutingMode(ra.Mode); err != nil {
+		return err
+	}
+	if ra.FailureThreshold < 1 {
+		return fmt.Errorf("failure_threshold must be at least 1")
+	}
+	if ra.RecoveryInterval <= 0 {
+		return fmt.Errorf("recovery_interval must be greater than 0")
+	}
+	return nil
 }
 
 // WalArguments holds the settings for configuring the Write-Ahead Log (WAL) used
 // by the underlying remote write client.
 type WalArguments struct {
-	Enabled          bool          `alloy:"enabled,attr,optional"`
-	MaxSegmentAge    time.Duration `alloy:"max_segment_age,attr,optional"`
-	MinReadFrequency time.Duration `alloy:"min_read_frequency,attr,optional"`
-	MaxReadFrequency time.Duration `alloy:"max_read_frequency,attr,optional"`
-	DrainTimeout     time.Duration `alloy:"drain_timeout,attr,optional"`
+	Enabled          bool             `alloy:"enabled,attr,optional"`
+	MaxSegmentAge    time.Duration    `alloy:"max_segment_age,attr,optional"`
+	MaxSize          units.Base2Bytes `alloy:"max_size,attr,optional"`
+	MinReadFrequency time.Duration    `alloy:"min_read_frequency,attr,optional"`
+	MaxReadFrequency time.Duration    `alloy:"max_read_frequency,attr,optional"`
+	DrainTimeout     time.Duration    `alloy:"drain_timeout,attr,optional"`
 }
 
 func (wa *WalArguments) Validate() error {
 	if wa.MinReadFrequency >= wa.MaxReadFrequency {
 		return fmt.Errorf("WAL min read frequency should be lower than max read frequency")
 	}
+	if wa.MaxSize < 0 {
+		return fmt.Errorf("WAL max size must not be negative")
+	}
 	return nil
 }
 
@@ -175,6 +225,7 @@ func (c *Component) Update(args component.Arguments) error {
 	walCfg := wal.Config{
 		Enabled:       newArgs.WAL.Enabled,
 		MaxSegmentAge: newArgs.WAL.MaxSegmentAge,
+		MaxSize:       int64(newArgs.WAL.MaxSize),
 		WatchConfig: wal.WatchConfig{
 			MinReadFrequency: newArgs.WAL.MinReadFrequency,
 			MaxReadFrequency: newArgs.WAL.MaxReadFrequency,
@@ -198,9 +249,18 @@ func (c *Component) Update(args component.Arguments) error {
 		notifier = c.walWriter
 	}
 
+	routingCfg := client.RoutingConfig{
+		Mode:             newArgs.Routing.Mode,
+		FailureThreshold: newArgs.Routing.FailureThreshold,
+		RecoveryInterval: newArgs.Routing.RecoveryInterval,
+	}
+	for _, e := range newArgs.Endpoints {
+		routingCfg.Weights = append(routingCfg.Weights, e.Weight)
+	}
+
 	c.clientManger, err = client.NewManager(c.metrics, c.opts.Logger, limit.Config{
 		MaxStreams: newArgs.MaxStreams,
-	}, c.opts.Registerer, walCfg, notifier, cfgs...)
+	}, c.opts.Registerer, walCfg, routingCfg, notifier, cfgs...)
 	if err != nil {
 		return fmt.Errorf("failed to create client manager: %w", err)
 	}
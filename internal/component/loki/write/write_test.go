@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	loki_util "github.com/grafana/loki/v3/pkg/util"
 	"github.com/prometheus/common/model"
@@ -56,6 +57,245 @@ func TestBadAlloyConfig(t *testing.T) {
 	require.ErrorContains(t, err, "at most one of basic_auth, authorization, oauth2, bearer_token & bearer_token_file must be configured")
 }
 
+func TestCompressionAlloyConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url               = "http://0.0.0.0:11111/loki/api/v1/push"
+		compression       = "gzip"
+		compression_level = 3
+	}
+`), &args))
+	require.Equal(t, "gzip", args.Endpoints[0].Compression)
+	require.Equal(t, 3, args.convertClientConfigs()[0].CompressionLevel)
+
+	err := syntax.Unmarshal([]byte(`
+	endpoint {
+		url         = "http://0.0.0.0:11111/loki/api/v1/push"
+		compression = "zstd"
+	}
+`), &args)
+	require.ErrorContains(t, err, `unsupported compression "zstd"`)
+
+	err = syntax.Unmarshal([]byte(`
+	endpoint {
+		url               = "http://0.0.0.0:11111/loki/api/v1/push"
+		compression_level = 3
+	}
+`), &args)
+	require.ErrorContains(t, err, "compression level is not supported with snappy compression")
+}
+
+func TestQueueAlloyConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		queue_config {
+			max_in_flight    = 4
+			max_queued_bytes = "8MiB"
+			on_full          = "drop"
+		}
+	}
+`), &args))
+	queue := args.convertClientConfigs()[0].Queue
+	require.Equal(t, 4, queue.MaxInFlight)
+	require.Equal(t, 8*1024*1024, queue.MaxQueuedBytes)
+	require.Equal(t, "drop", queue.OnFull)
+	// Unset attributes keep their defaults.
+	require.Equal(t, 15*time.Second, queue.DrainTimeout)
+
+	err := syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		queue_config {
+			on_full = "spill"
+		}
+	}
+`), &args)
+	require.ErrorContains(t, err, `unsupported on_full "spill"`)
+
+	err = syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		queue_config {
+			max_in_flight = 0
+		}
+	}
+`), &args)
+	require.ErrorContains(t, err, "max_in_flight must be at least 1")
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		queue_config {
+			shards = 8
+		}
+	}
+`), &args))
+	require.Equal(t, 8, args.convertClientConfigs()[0].Queue.Shards)
+
+	err = syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		queue_config {
+			max_in_flight = 2
+			shards        = 8
+		}
+	}
+`), &args)
+	require.ErrorContains(t, err, "max_in_flight can't be greater than 1 when shards is greater than 1")
+}
+
+func TestRoutingAlloyConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url    = "http://primary:3100/loki/api/v1/push"
+		weight = 3
+	}
+	endpoint {
+		url = "http://backup:3100/loki/api/v1/push"
+	}
+	routing {
+		mode = "weighted"
+	}
+`), &args))
+	require.Equal(t, "weighted", args.Routing.Mode)
+	require.Equal(t, 3, args.Routing.FailureThreshold)
+	require.Equal(t, 30*time.Second, args.Routing.RecoveryInterval)
+	require.Equal(t, 3, args.Endpoints[0].Weight)
+	require.Equal(t, 1, args.Endpoints[1].Weight)
+
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "unsupported mode",
+			config: `
+	endpoint {
+		url = "http://primary:3100/loki/api/v1/push"
+	}
+	routing {
+		mode = "random"
+	}`,
+			err: `unsupported routing mode "random"`,
+		},
+		{
+			name: "invalid weight",
+			config: `
+	endpoint {
+		url    = "http://primary:3100/loki/api/v1/push"
+		weight = 0
+	}`,
+			err: "weight must be at least 1",
+		},
+		{
+			name: "invalid failure threshold",
+			config: `
+	endpoint {
+		url = "http://primary:3100/loki/api/v1/push"
+	}
+	routing {
+		mode              = "failover"
+		failure_threshold = 0
+	}`,
+			err: "failure_threshold must be at least 1",
+		},
+		{
+			name: "routing with WAL",
+			config: `
+	endpoint {
+		url = "http://primary:3100/loki/api/v1/push"
+	}
+	routing {
+		mode = "failover"
+	}
+	wal {
+		enabled = true
+	}`,
+			err: `routing mode "failover" isn't supported when WAL is enabled`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tt.config), &args), tt.err)
+		})
+	}
+}
+
+func TestStructuredMetadataLimitsAlloyConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		structured_metadata_limits {
+			max_entries_count = 64
+			max_size          = "32KiB"
+			priority          = ["trace_id", "span_id"]
+		}
+	}
+`), &args))
+	limits := args.convertClientConfigs()[0].StructuredMetadataLimits
+	require.Equal(t, 64, limits.MaxEntriesCount)
+	require.Equal(t, 32*1024, limits.MaxSize)
+	require.Equal(t, "drop_keys", limits.OverflowPolicy)
+	require.Equal(t, []string{"trace_id", "span_id"}, limits.Priority)
+
+	err := syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		structured_metadata_limits {
+			max_entries_count = 64
+			overflow_policy   = "sample"
+		}
+	}
+`), &args)
+	require.ErrorContains(t, err, `unsupported structured metadata overflow policy "sample"`)
+}
+
+func TestTenantRoutesAlloyConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+	endpoint {
+		url          = "http://0.0.0.0:11111/loki/api/v1/push"
+		tenant_id    = "default"
+		tenant_label = "tenant"
+
+		tenant_route {
+			selector = "{namespace=~\"team-a-.*\"}"
+			tenant   = "team-a"
+		}
+	}
+`), &args))
+	cfg := args.convertClientConfigs()[0]
+	require.Equal(t, "tenant", cfg.TenantLabel)
+	require.Len(t, cfg.TenantRoutes, 1)
+	require.Equal(t, "team-a", cfg.TenantRoutes[0].Tenant)
+
+	err := syntax.Unmarshal([]byte(`
+	endpoint {
+		url = "http://0.0.0.0:11111/loki/api/v1/push"
+
+		tenant_route {
+			selector = "namespace=team-a"
+			tenant   = "team-a"
+		}
+	}
+`), &args)
+	require.ErrorContains(t, err, `invalid tenant route selector "namespace=team-a"`)
+}
+
 func TestUnmarshallWalAttrributes(t *testing.T) {
 	type testcase struct {
 		raw           string
@@ -98,12 +338,14 @@ func TestUnmarshallWalAttrributes(t *testing.T) {
 			raw: `
 			enabled = true
 			max_segment_age = "10m"
+			max_size = "1GiB"
 			min_read_frequency = "11ms"
 			drain_timeout = "5m"
 			`,
 			expected: WalArguments{
 				Enabled:          true,
 				MaxSegmentAge:    time.Minute * 10,
+				MaxSize:          units.GiB,
 				MinReadFrequency: time.Millisecond * 11,
 				MaxReadFrequency: wal.DefaultWatchConfig.MaxReadFrequency,
 				DrainTimeout:     time.Minute * 5,
@@ -201,6 +443,75 @@ func testSingleEndpoint(t *testing.T, alterConfig func(arguments *Arguments)) {
 	}
 }
 
+func TestWriteFailover(t *testing.T) {
+	// The primary endpoint always fails, so the log entries fail over to the
+	// backup endpoint.
+	var primaryRequests, backupEntries atomic.Int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests.Inc()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var pushReq logproto.PushRequest
+		require.NoError(t, loki_util.ParseProtoReader(context.Background(), r.Body, int(r.ContentLength), math.MaxInt32, &pushReq, loki_util.RawSnappy))
+		for _, s := range pushReq.Streams {
+			backupEntries.Add(int64(len(s.Entries)))
+		}
+	}))
+	defer backup.Close()
+
+	cfg := fmt.Sprintf(`
+		endpoint {
+			url                 = "%s"
+			batch_wait          = "10ms"
+			min_backoff_period  = "10ms"
+			max_backoff_retries = 1
+		}
+		endpoint {
+			url        = "%s"
+			batch_wait = "10ms"
+		}
+		routing {
+			mode              = "failover"
+			failure_threshold = 1
+			recovery_interval = "1h"
+		}
+	`, primary.URL, backup.URL)
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	tc, err := componenttest.NewControllerFromID(util.TestLogger(t), "loki.write")
+	require.NoError(t, err)
+	go func() {
+		err = tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitExports(time.Second))
+	exports := tc.Exports().(Exports)
+
+	logEntry := loki.Entry{
+		Labels: model.LabelSet{"foo": "bar"},
+		Entry:  logproto.Entry{Timestamp: time.Now(), Line: "very important log"},
+	}
+	exports.Receiver.Chan() <- logEntry
+	require.Eventually(t, func() bool {
+		return primaryRequests.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once the primary endpoint is unhealthy, it's no longer sent anything.
+	require.Eventually(t, func() bool {
+		exports.Receiver.Chan() <- logEntry
+		return backupEntries.Load() > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	requests := primaryRequests.Load()
+	for i := 0; i < 10; i++ {
+		exports.Receiver.Chan() <- logEntry
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, requests, primaryRequests.Load())
+}
+
 func TestEntrySentToTwoWriteComponents(t *testing.T) {
 	t.Run("wal disabled", func(t *testing.T) {
 		testMultipleEndpoint(t, func(arguments *Arguments) {})
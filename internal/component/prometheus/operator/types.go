@@ -66,7 +66,7 @@ func (args *Arguments) Validate() error {
 	if len(args.Namespaces) == 0 {
 		args.Namespaces = []string{apiv1.NamespaceAll}
 	}
-	return nil
+	return alloy_relabel.ValidateMetricsActions(args.RelabelConfigs)
 }
 
 type DebugInfo struct {
@@ -63,7 +63,7 @@ func (arg *Arguments) Validate() error {
 	if arg.CacheSize <= 0 {
 		return fmt.Errorf("max_cache_size must be greater than 0 and is %d", arg.CacheSize)
 	}
-	return nil
+	return alloy_relabel.ValidateMetricsActions(arg.MetricRelabelConfigs)
 }
 
 // Exports holds values which are exported by the prometheus.relabel component.
@@ -129,7 +129,7 @@ func (r *EndpointOptions) Validate() error {
 		}
 	}
 
-	return nil
+	return alloy_relabel.ValidateMetricsActions(r.WriteRelabelConfigs)
 }
 
 // QueueOptions handles the low level queue config options for a remote_write
@@ -29,12 +29,13 @@ func (s *ScrapeConfigBuilder) AppendJournalConfig() {
 		}
 	}
 	args := journal.Arguments{
-		FormatAsJson: jc.JSON,
-		MaxAge:       maxAge,
-		Path:         jc.Path,
-		Receivers:    s.getOrNewProcessStageReceivers(),
-		Labels:       convertPromLabels(jc.Labels),
-		RelabelRules: alloyrelabel.Rules{},
+		FormatAsJson:  jc.JSON,
+		MaxAge:        maxAge,
+		Path:          jc.Path,
+		IncludeKernel: true,
+		Receivers:     s.getOrNewProcessStageReceivers(),
+		Labels:        convertPromLabels(jc.Labels),
+		RelabelRules:  alloyrelabel.Rules{},
 	}
 	relabelRulesExpr := s.getOrNewDiscoveryRelabelRules()
 	hook := func(val interface{}) interface{} {
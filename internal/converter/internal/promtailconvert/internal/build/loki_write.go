@@ -53,6 +53,8 @@ func toLokiWriteArguments(config *client.Config, diags *diag.Diagnostics) *lokiw
 				RemoteTimeout:     config.Timeout,
 				TenantID:          config.TenantID,
 				RetryOnHTTP429:    !config.DropRateLimitedBatches,
+				Compression:       lokiwrite.GetDefaultEndpointOptions().Compression,
+				Weight:            lokiwrite.GetDefaultEndpointOptions().Weight,
 			},
 		},
 		ExternalLabels: convertFlagLabels(config.ExternalLabels),
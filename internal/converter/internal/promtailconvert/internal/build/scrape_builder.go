@@ -78,6 +78,7 @@ func (s *ScrapeConfigBuilder) AppendLokiSourceFile(watchConfig *file.WatchConfig
 		DecompressionConfig: convertDecompressionConfig(s.cfg.DecompressionCfg),
 		FileWatch:           convertFileWatchConfig(watchConfig),
 		LegacyPositionsFile: positionsCfg.PositionsFile,
+		StartPosition:       lokisourcefile.DefaultArguments.StartPosition,
 	}
 	overrideHook := func(val interface{}) interface{} {
 		if _, ok := val.([]discovery.Target); ok {
@@ -133,10 +134,9 @@ func (s *ScrapeConfigBuilder) getOrNewProcessStageReceivers() []loki.LogsReceive
 			alloyStages[i] = fs
 		}
 	}
-	args := process.Arguments{
-		ForwardTo: s.globalCtx.WriteReceivers,
-		Stages:    alloyStages,
-	}
+	args := process.DefaultArguments
+	args.ForwardTo = s.globalCtx.WriteReceivers
+	args.Stages = alloyStages
 	compLabel := common.LabelForParts(s.globalCtx.LabelPrefix, s.cfg.JobName)
 	s.f.Body().AppendBlock(common.NewBlockWithOverride([]string{"loki", "process"}, compLabel, args))
 	s.processStageReceivers = []loki.LogsReceiver{common.ConvertLogsReceiver{
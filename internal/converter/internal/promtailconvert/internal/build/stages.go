@@ -102,7 +102,7 @@ func convertStructuredMetadata(cfg interface{}, diags *diag.Diagnostics) (stages
 		addInvalidStageError(diags, cfg, err)
 		return stages.StageConfig{}, false
 	}
-	return stages.StageConfig{StructuredMetadata: &stages.LabelsConfig{
+	return stages.StageConfig{StructuredMetadata: &stages.StructuredMetadataConfig{
 		Values: *pLabels,
 	}}, true
 }
@@ -531,6 +531,9 @@ func toAlloyMetricsProcessStage(name string, pMetric promtailstages.MetricConfig
 			MaxIdle:     maxIdle,
 			Value:       defaultEmpty(pHistogram.Cfg.Value),
 			Buckets:     pHistogram.Cfg.Buckets,
+
+			NativeHistogramMaxBucketNumber:  metric.DefaultHistogramConfig.NativeHistogramMaxBucketNumber,
+			NativeHistogramMinResetDuration: metric.DefaultHistogramConfig.NativeHistogramMinResetDuration,
 		}
 	}
 	return fMetric, true
@@ -32,6 +32,7 @@ func (s *ScrapeConfigBuilder) AppendSyslogConfig() {
 		MaxMessageLength:     s.cfg.SyslogConfig.MaxMessageLength,
 		TLSConfig:            *common.ToTLSConfig(&s.cfg.SyslogConfig.TLSConfig),
 		SyslogFormat:         syslogFormat,
+		Framing:              syslog.DefaultListenerConfig.Framing,
 	}
 
 	// If the syslog format is not set, use the default.
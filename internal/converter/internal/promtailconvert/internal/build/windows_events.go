@@ -23,6 +23,7 @@ func (s *ScrapeConfigBuilder) AppendWindowsEventsConfig() {
 		UseIncomingTimestamp: winCfg.UseIncomingTimestamp,
 		ForwardTo:            make([]loki.LogsReceiver, 0),
 		Labels:               convertPromLabels(winCfg.Labels),
+		EventDataFormat:      windowsevent.EventDataFormatXML,
 	}
 
 	override := func(val interface{}) interface{} {
@@ -90,6 +90,10 @@ type traversalWalker struct {
 
 	buildTraversal   bool      // Whether
 	currentTraversal Traversal // currentTraversal being built.
+
+	// params counts the function parameters in scope by name. Traversals
+	// starting with a parameter aren't references to components.
+	params map[string]int
 }
 
 func (tw *traversalWalker) Visit(node ast.Node) ast.Visitor {
@@ -125,6 +129,17 @@ func (tw *traversalWalker) Visit(node ast.Node) ast.Visitor {
 			ast.Walk(tw, arg)
 		}
 		return nil
+
+	case *ast.FuncExpr:
+		tw.flush()
+		if tw.params == nil {
+			tw.params = make(map[string]int)
+		}
+		tw.params[n.Param.Name]++
+		ast.Walk(tw, n.Body)
+		tw.flush()
+		tw.params[n.Param.Name]--
+		return nil
 	}
 
 	return tw
@@ -133,7 +148,7 @@ func (tw *traversalWalker) Visit(node ast.Node) ast.Visitor {
 // flush will flush the in-progress traversal to the traversals list and unset
 // the buildTraversal state.
 func (tw *traversalWalker) flush() {
-	if tw.buildTraversal && len(tw.currentTraversal) > 0 {
+	if tw.buildTraversal && len(tw.currentTraversal) > 0 && tw.params[tw.currentTraversal[0].Name] == 0 {
 		tw.traversals = append(tw.traversals, tw.currentTraversal)
 	}
 	tw.buildTraversal = false
@@ -182,6 +182,32 @@ func TestLoader(t *testing.T) {
 		require.NoError(t, diags.ErrorOrNil())
 	})
 
+	t.Run("Load component with function parameters", func(t *testing.T) {
+		file := `
+			testcomponents.passthrough "static" {
+				input = "hello, world!"
+			}
+
+			testcomponents.passthrough "filtered" {
+				input = string.join(array.filter([testcomponents.passthrough.static.output, ""], line => line != ""), "")
+			}
+		`
+		l := controller.NewLoader(newLoaderOptions())
+		diags := applyFromContent(t, l, []byte(file), nil, nil)
+		require.NoError(t, diags.ErrorOrNil())
+		requireGraph(t, l.Graph(), graphDefinition{
+			Nodes: []string{
+				"testcomponents.passthrough.static",
+				"testcomponents.passthrough.filtered",
+				"logging",
+				"tracing",
+			},
+			OutEdges: []edge{
+				{From: "testcomponents.passthrough.filtered", To: "testcomponents.passthrough.static"},
+			},
+		})
+	})
+
 	t.Run("Load with correct stability level", func(t *testing.T) {
 		l := controller.NewLoader(newLoaderOptionsWithStability(featuregate.StabilityPublicPreview))
 		diags := applyFromContent(t, l, []byte(testFile), nil, nil)
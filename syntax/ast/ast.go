@@ -116,8 +116,9 @@ type ObjectField struct {
 
 // AccessExpr accesses a field in an object value by name.
 type AccessExpr struct {
-	Value Expr
-	Name  *Ident
+	Value    Expr
+	Name     *Ident
+	Optional bool // True if the field was accessed with ?.
 }
 
 // IndexExpr accesses an index in an array value.
@@ -148,6 +149,21 @@ type BinaryExpr struct {
 	Left, Right Expr
 }
 
+// ConditionalExpr evaluates to one of two values, depending on whether its
+// condition is true or false.
+type ConditionalExpr struct {
+	Condition, True, False Expr
+	QuestionPos, ColonPos  token.Pos
+}
+
+// FuncExpr is an anonymous function with a single parameter. Calling it
+// evaluates Body with Param set to the argument of the call.
+type FuncExpr struct {
+	Param    *Ident
+	ArrowPos token.Pos
+	Body     Expr
+}
+
 // ParenExpr represents an expression wrapped in parentheses.
 type ParenExpr struct {
 	Inner                Expr
@@ -171,6 +187,8 @@ var (
 	_ Node = (*CallExpr)(nil)
 	_ Node = (*UnaryExpr)(nil)
 	_ Node = (*BinaryExpr)(nil)
+	_ Node = (*ConditionalExpr)(nil)
+	_ Node = (*FuncExpr)(nil)
 	_ Node = (*ParenExpr)(nil)
 
 	_ Stmt = (*AttributeStmt)(nil)
@@ -185,40 +203,46 @@ var (
 	_ Expr = (*CallExpr)(nil)
 	_ Expr = (*UnaryExpr)(nil)
 	_ Expr = (*BinaryExpr)(nil)
+	_ Expr = (*ConditionalExpr)(nil)
+	_ Expr = (*FuncExpr)(nil)
 	_ Expr = (*ParenExpr)(nil)
 )
 
-func (n *File) astNode()           {}
-func (n Body) astNode()            {}
-func (n CommentGroup) astNode()    {}
-func (n *Comment) astNode()        {}
-func (n *AttributeStmt) astNode()  {}
-func (n *BlockStmt) astNode()      {}
-func (n *Ident) astNode()          {}
-func (n *IdentifierExpr) astNode() {}
-func (n *LiteralExpr) astNode()    {}
-func (n *ArrayExpr) astNode()      {}
-func (n *ObjectExpr) astNode()     {}
-func (n *AccessExpr) astNode()     {}
-func (n *IndexExpr) astNode()      {}
-func (n *CallExpr) astNode()       {}
-func (n *UnaryExpr) astNode()      {}
-func (n *BinaryExpr) astNode()     {}
-func (n *ParenExpr) astNode()      {}
+func (n *File) astNode()            {}
+func (n Body) astNode()             {}
+func (n CommentGroup) astNode()     {}
+func (n *Comment) astNode()         {}
+func (n *AttributeStmt) astNode()   {}
+func (n *BlockStmt) astNode()       {}
+func (n *Ident) astNode()           {}
+func (n *IdentifierExpr) astNode()  {}
+func (n *LiteralExpr) astNode()     {}
+func (n *ArrayExpr) astNode()       {}
+func (n *ObjectExpr) astNode()      {}
+func (n *AccessExpr) astNode()      {}
+func (n *IndexExpr) astNode()       {}
+func (n *CallExpr) astNode()        {}
+func (n *UnaryExpr) astNode()       {}
+func (n *BinaryExpr) astNode()      {}
+func (n *ConditionalExpr) astNode() {}
+func (n *FuncExpr) astNode()        {}
+func (n *ParenExpr) astNode()       {}
 
 func (n *AttributeStmt) astStmt() {}
 func (n *BlockStmt) astStmt()     {}
 
-func (n *IdentifierExpr) astExpr() {}
-func (n *LiteralExpr) astExpr()    {}
-func (n *ArrayExpr) astExpr()      {}
-func (n *ObjectExpr) astExpr()     {}
-func (n *AccessExpr) astExpr()     {}
-func (n *IndexExpr) astExpr()      {}
-func (n *CallExpr) astExpr()       {}
-func (n *UnaryExpr) astExpr()      {}
-func (n *BinaryExpr) astExpr()     {}
-func (n *ParenExpr) astExpr()      {}
+func (n *IdentifierExpr) astExpr()  {}
+func (n *LiteralExpr) astExpr()     {}
+func (n *ArrayExpr) astExpr()       {}
+func (n *ObjectExpr) astExpr()      {}
+func (n *AccessExpr) astExpr()      {}
+func (n *IndexExpr) astExpr()       {}
+func (n *CallExpr) astExpr()        {}
+func (n *UnaryExpr) astExpr()       {}
+func (n *BinaryExpr) astExpr()      {}
+func (n *ConditionalExpr) astExpr() {}
+func (n *FuncExpr) astExpr()        {}
+func (n *ParenExpr) astExpr()       {}
 
 // StartPos returns the position of the first character belonging to a Node.
 func StartPos(n Node) token.Pos {
@@ -264,6 +288,10 @@ func StartPos(n Node) token.Pos {
 		return n.KindPos
 	case *BinaryExpr:
 		return StartPos(n.Left)
+	case *ConditionalExpr:
+		return StartPos(n.Condition)
+	case *FuncExpr:
+		return StartPos(n.Param)
 	case *ParenExpr:
 		return n.LParenPos
 	default:
@@ -315,6 +343,10 @@ func EndPos(n Node) token.Pos {
 		return EndPos(n.Value)
 	case *BinaryExpr:
 		return EndPos(n.Right)
+	case *ConditionalExpr:
+		return EndPos(n.False)
+	case *FuncExpr:
+		return EndPos(n.Body)
 	case *ParenExpr:
 		return n.RParenPos
 	default:
@@ -63,6 +63,13 @@ func Walk(v Visitor, node Node) {
 	case *BinaryExpr:
 		Walk(v, n.Left)
 		Walk(v, n.Right)
+	case *ConditionalExpr:
+		Walk(v, n.Condition)
+		Walk(v, n.True)
+		Walk(v, n.False)
+	case *FuncExpr:
+		Walk(v, n.Param)
+		Walk(v, n.Body)
 	case *ParenExpr:
 		Walk(v, n.Inner)
 	default:
@@ -3,6 +3,7 @@ module github.com/grafana/alloy/syntax
 go 1.21.0
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/fatih/color v1.15.0
 	github.com/ohler55/ojg v1.20.1
 	github.com/stretchr/testify v1.8.4
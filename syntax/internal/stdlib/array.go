@@ -0,0 +1,389 @@
+package stdlib
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// arrayFilter returns the elements of an array for which a function returns
+// true.
+var arrayFilter = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray, value.TypeFunction); err != nil {
+		return value.Null, err
+	}
+	list, fn := args[0], args[1]
+
+	res := make([]value.Value, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		elem := list.Index(i)
+
+		keep, err := fn.Call(elem)
+		if err != nil {
+			return value.Null, err
+		}
+		if keep.Type() != value.TypeBool {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("function should return bool, got %s for element %d", keep.Type(), i),
+			}
+		}
+
+		if keep.Bool() {
+			res = append(res, elem)
+		}
+	}
+	return value.Array(res...), nil
+})
+
+// arrayMap returns the results of calling a function with each element of an
+// array.
+var arrayMap = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray, value.TypeFunction); err != nil {
+		return value.Null, err
+	}
+	list, fn := args[0], args[1]
+
+	res := make([]value.Value, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		var err error
+		res[i], err = fn.Call(list.Index(i))
+		if err != nil {
+			return value.Null, err
+		}
+	}
+	return value.Array(res...), nil
+})
+
+// arraySort returns the elements of an array in ascending order. An optional
+// function computes the key to sort each element by; elements are their own
+// key otherwise. Keys must either all be numbers or all be strings, and
+// elements with equal keys keep their order.
+var arraySort = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	var err error
+	if len(args) == 1 {
+		err = checkArgs(funcValue, args, value.TypeArray)
+	} else {
+		err = checkArgs(funcValue, args, value.TypeArray, value.TypeFunction)
+	}
+	if err != nil {
+		return value.Null, err
+	}
+	list := args[0]
+
+	elems := make([]value.Value, list.Len())
+	keys := make([]value.Value, list.Len())
+	for i := range elems {
+		elems[i] = list.Index(i)
+		keys[i] = elems[i]
+		if len(args) == 2 {
+			if keys[i], err = args[1].Call(elems[i]); err != nil {
+				return value.Null, err
+			}
+		}
+
+		if ty := keys[i].Type(); ty != value.TypeNumber && ty != value.TypeString {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("sort key should be number or string, got %s for element %d", ty, i),
+			}
+		} else if ty != keys[0].Type() {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("sort keys should all have the same type, got %s and %s", keys[0].Type(), ty),
+			}
+		}
+	}
+
+	sort.Stable(sortByKeys{elems: elems, keys: keys})
+	return value.Array(elems...), nil
+})
+
+type sortByKeys struct {
+	elems, keys []value.Value
+}
+
+func (s sortByKeys) Len() int { return len(s.elems) }
+
+func (s sortByKeys) Swap(i, j int) {
+	s.elems[i], s.elems[j] = s.elems[j], s.elems[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s sortByKeys) Less(i, j int) bool {
+	a, b := s.keys[i], s.keys[j]
+	if a.Type() == value.TypeString {
+		return strings.Compare(a.Text(), b.Text()) < 0
+	}
+
+	aNum, bNum := a.Number(), b.Number()
+	switch value.FitNumberKinds(aNum.Kind(), bNum.Kind()) {
+	case value.NumberKindUint:
+		return aNum.Uint() < bNum.Uint()
+	case value.NumberKindInt:
+		return aNum.Int() < bNum.Int()
+	default:
+		return aNum.Float() < bNum.Float()
+	}
+}
+
+// arrayUnique returns the elements of an array without duplicates, keeping
+// the first occurrence of each element.
+var arrayUnique = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray); err != nil {
+		return value.Null, err
+	}
+	list := args[0]
+
+	var (
+		res     = make([]value.Value, 0, list.Len())
+		buckets = make(map[uint64][]value.Value, list.Len())
+		seed    = maphash.MakeSeed()
+	)
+NextElement:
+	for i := 0; i < list.Len(); i++ {
+		elem := list.Index(i)
+		h := hashValue(seed, elem)
+		for _, seen := range buckets[h] {
+			if value.Equal(elem, seen) {
+				continue NextElement
+			}
+		}
+		buckets[h] = append(buckets[h], elem)
+		res = append(res, elem)
+	}
+	return value.Array(res...), nil
+})
+
+// hashValue returns a hash of v which is consistent with value.Equal: equal
+// values always have the same hash. Values which can't be hashed cheaply,
+// like functions and capsules, only hash their type.
+func hashValue(seed maphash.Seed, v value.Value) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	h.WriteByte(byte(v.Type()))
+
+	switch v.Type() {
+	case value.TypeNumber:
+		// Numbers are equal regardless of their Go type, so hash them as floats
+		// and treat -0 and 0 as the same number.
+		f := v.Number().Float()
+		if f == 0 {
+			f = 0
+		}
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		h.Write(buf[:])
+	case value.TypeString:
+		h.WriteString(v.Text())
+	case value.TypeBool:
+		if v.Bool() {
+			h.WriteByte(1)
+		}
+	case value.TypeArray:
+		var buf [8]byte
+		for i := 0; i < v.Len(); i++ {
+			binary.LittleEndian.PutUint64(buf[:], hashValue(seed, v.Index(i)))
+			h.Write(buf[:])
+		}
+	case value.TypeObject:
+		// Keys are unordered, so combine the hash of each entry with a sum.
+		var sum uint64
+		for _, key := range v.Keys() {
+			elem, _ := v.Key(key)
+			sum += maphash.String(seed, key) ^ hashValue(seed, elem)
+		}
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], sum)
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// arrayReverse returns the elements of an array in reverse order.
+var arrayReverse = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray); err != nil {
+		return value.Null, err
+	}
+	list := args[0]
+
+	res := make([]value.Value, list.Len())
+	for i := range res {
+		res[i] = list.Index(list.Len() - 1 - i)
+	}
+	return value.Array(res...), nil
+})
+
+// arrayContains returns true if an array has an element which is equal to a
+// value.
+var arrayContains = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	index, err := indexOf(funcValue, args)
+	if err != nil {
+		return value.Null, err
+	}
+	return value.Bool(index >= 0), nil
+})
+
+// arrayIndexOf returns the index of the first element of an array which is
+// equal to a value, or -1 if there is no such element.
+var arrayIndexOf = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	index, err := indexOf(funcValue, args)
+	if err != nil {
+		return value.Null, err
+	}
+	return value.Int(int64(index)), nil
+})
+
+func indexOf(funcValue value.Value, args []value.Value) (int, error) {
+	if len(args) != 2 {
+		return -1, value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected 2 args, got %d", len(args)),
+		}
+	}
+	if err := checkArgs(funcValue, args[:1], value.TypeArray); err != nil {
+		return -1, err
+	}
+	list, v := args[0], args[1]
+
+	for i := 0; i < list.Len(); i++ {
+		if value.Equal(list.Index(i), v) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// arrayAny returns true if a function returns true for any element of an
+// array. The function isn't called for the elements after the first one it
+// returns true for.
+var arrayAny = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray, value.TypeFunction); err != nil {
+		return value.Null, err
+	}
+	list, fn := args[0], args[1]
+
+	for i := 0; i < list.Len(); i++ {
+		match, err := fn.Call(list.Index(i))
+		if err != nil {
+			return value.Null, err
+		}
+		if match.Type() != value.TypeBool {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("function should return bool, got %s for element %d", match.Type(), i),
+			}
+		}
+
+		if match.Bool() {
+			return value.Bool(true), nil
+		}
+	}
+	return value.Bool(false), nil
+})
+
+// arrayRange returns the numbers from start (inclusive) to end (exclusive),
+// separated by step. It accepts (end), (start, end) and (start, end, step)
+// as arguments, where start defaults to 0 and step defaults to 1.
+func arrayRange(args ...int64) ([]int64, error) {
+	var start, end, step int64 = 0, 0, 1
+	switch len(args) {
+	case 1:
+		end = args[0]
+	case 2:
+		start, end = args[0], args[1]
+	case 3:
+		start, end, step = args[0], args[1], args[2]
+	default:
+		return nil, fmt.Errorf("expected 1 to 3 args, got %d", len(args))
+	}
+
+	if step == 0 {
+		return nil, fmt.Errorf("step must not be 0")
+	}
+
+	// The distance and the step are computed as unsigned integers so that they
+	// don't overflow.
+	var dist, absStep uint64
+	switch {
+	case step > 0 && end > start:
+		dist, absStep = uint64(end)-uint64(start), uint64(step)
+	case step < 0 && end < start:
+		dist, absStep = uint64(start)-uint64(end), uint64(-(step+1))+1
+	default:
+		return []int64{}, nil
+	}
+
+	n := (dist-1)/absStep + 1
+	if n > maxGeneratedSize {
+		return nil, fmt.Errorf("range has %d elements, which is more than the maximum of %d", n, maxGeneratedSize)
+	}
+
+	res := make([]int64, n)
+	for i := range res {
+		res[i] = start + int64(i)*step
+	}
+	return res, nil
+}
+
+// arraySlice returns the elements of an array from start (inclusive) to end
+// (exclusive). end defaults to the length of the array.
+var arraySlice = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	var err error
+	if len(args) == 2 {
+		err = checkArgs(funcValue, args, value.TypeArray, value.TypeNumber)
+	} else {
+		err = checkArgs(funcValue, args, value.TypeArray, value.TypeNumber, value.TypeNumber)
+	}
+	if err != nil {
+		return value.Null, err
+	}
+	list := args[0]
+
+	start, end := args[1].Int(), int64(list.Len())
+	if len(args) == 3 {
+		end = args[2].Int()
+	}
+	if start < 0 || end > int64(list.Len()) || start > end {
+		return value.Null, value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("invalid slice [%d:%d] of an array of length %d", start, end, list.Len()),
+		}
+	}
+
+	res := make([]value.Value, 0, end-start)
+	for i := start; i < end; i++ {
+		res = append(res, list.Index(int(i)))
+	}
+	return value.Array(res...), nil
+})
+
+// checkArgs checks that args holds one value of each type in types.
+func checkArgs(funcValue value.Value, args []value.Value, types ...value.Type) error {
+	if len(args) != len(types) {
+		return value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected %d args, got %d", len(types), len(args)),
+		}
+	}
+
+	for i, expect := range types {
+		if args[i].Type() != expect {
+			return value.ArgError{
+				Function: funcValue,
+				Argument: args[i],
+				Index:    i,
+				Inner: value.TypeError{
+					Value:    args[i],
+					Expected: expect,
+				},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package stdlib
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// toInt converts a string or a number into an integer. Floating-point numbers
+// are truncated toward zero.
+var toInt = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	arg, err := stringOrType(funcValue, args, value.TypeNumber)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if arg.Type() == value.TypeNumber {
+		if num := arg.Number(); num.Kind() == value.NumberKindUint {
+			return value.Uint(num.Uint()), nil
+		}
+		return value.Int(arg.Int()), nil
+	}
+
+	i, err := strconv.ParseInt(strings.TrimSpace(arg.Text()), 10, 64)
+	if err != nil {
+		return value.Null, value.Error{Value: arg, Inner: fmt.Errorf("is not an integer")}
+	}
+	return value.Int(i), nil
+})
+
+// toFloat converts a string or a number into a floating-point number.
+var toFloat = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	arg, err := stringOrType(funcValue, args, value.TypeNumber)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if arg.Type() == value.TypeNumber {
+		return value.Float(arg.Float()), nil
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(arg.Text()), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return value.Null, value.Error{Value: arg, Inner: fmt.Errorf("is not a number")}
+	}
+	return value.Float(f), nil
+})
+
+// toBool converts a string or a bool into a bool. The strings "1", "t", "T",
+// "true", "TRUE" and "True" are true, and "0", "f", "F", "false", "FALSE" and
+// "False" are false.
+var toBool = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	arg, err := stringOrType(funcValue, args, value.TypeBool)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if arg.Type() == value.TypeBool {
+		return arg, nil
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(arg.Text()))
+	if err != nil {
+		return value.Null, value.Error{Value: arg, Inner: fmt.Errorf("is not a bool")}
+	}
+	return value.Bool(b), nil
+})
+
+// stringOrType checks that args holds a single string or a single value of
+// type ty, and returns it.
+func stringOrType(funcValue value.Value, args []value.Value, ty value.Type) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected 1 args, got %d", len(args)),
+		}
+	}
+	if args[0].Type() != value.TypeString && args[0].Type() != ty {
+		return value.Null, value.Error{
+			Value: args[0],
+			Inner: fmt.Errorf("should be string or %s, got %s", ty, args[0].Type()),
+		}
+	}
+	return args[0], nil
+}
+
+// parseDuration parses a duration like "1m30s". The result is a
+// time.Duration, which Alloy represents as a normalized string like "1m30s",
+// so it can be used for any duration attribute.
+func parseDuration(in string) (time.Duration, error) {
+	return time.ParseDuration(strings.TrimSpace(in))
+}
+
+var (
+	bytesPattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+	byteUnits = map[string]float64{
+		"":    1,
+		"b":   1,
+		"kb":  1e3,
+		"mb":  1e6,
+		"gb":  1e9,
+		"tb":  1e12,
+		"pb":  1e15,
+		"kib": 1 << 10,
+		"mib": 1 << 20,
+		"gib": 1 << 30,
+		"tib": 1 << 40,
+		"pib": 1 << 50,
+	}
+)
+
+// parseBytes parses a size like "512MiB" into a number of bytes. Units are
+// case-insensitive; KB, MB, GB, TB and PB are powers of 1000 and KiB, MiB,
+// GiB, TiB and PiB are powers of 1024. Fractions of bytes are rounded down.
+func parseBytes(in string) (int64, error) {
+	m := bytesPattern.FindStringSubmatch(strings.TrimSpace(in))
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a size in bytes", in)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("%q has an unknown unit %q", in, m[2])
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a size in bytes", in)
+	}
+
+	size := math.Floor(n * unit)
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("%q is too large", in)
+	}
+	return int64(size), nil
+}
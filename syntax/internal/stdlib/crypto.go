@@ -0,0 +1,39 @@
+package stdlib
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/fnv"
+
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+// The hash functions return the hexadecimal encoding of the hash of their
+// input.
+var crypto = map[string]interface{}{
+	"sha256":      hashFunc(sha256.New),
+	"sha1":        hashFunc(sha1.New),
+	"md5":         hashFunc(md5.New),
+	"fnv64a":      hashFunc(func() hash.Hash { return fnv.New64a() }),
+	"hmac_sha256": hmacSHA256,
+}
+
+func hashFunc(newHash func() hash.Hash) func(string) string {
+	return func(in string) string {
+		h := newHash()
+		h.Write([]byte(in))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of message with key. The key is a secret
+// so that secrets can be passed without converting them first.
+func hmacSHA256(key alloytypes.Secret, message string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))
+}
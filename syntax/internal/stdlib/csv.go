@@ -0,0 +1,98 @@
+package stdlib
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// csvOptions are the options of encoding.from_csv.
+type csvOptions struct {
+	Header    *bool  `alloy:"header,attr,optional"`
+	Separator string `alloy:"separator,attr,optional"`
+	Comment   string `alloy:"comment,attr,optional"`
+}
+
+// csvDecode decodes CSV records. If the first record is a header, which is
+// the default, every following record is decoded as an object whose fields
+// are named after the header. Otherwise, every record is decoded as an array.
+func csvDecode(in string, opts ...csvOptions) (interface{}, error) {
+	var o csvOptions
+	switch len(opts) {
+	case 0:
+	case 1:
+		o = opts[0]
+	default:
+		return nil, fmt.Errorf("expected at most one options object, got %d", len(opts))
+	}
+
+	r := csv.NewReader(strings.NewReader(in))
+	if o.Separator != "" {
+		sep, err := csvRune("separator", o.Separator)
+		if err != nil {
+			return nil, err
+		}
+		r.Comma = sep
+	}
+	if o.Comment != "" {
+		comment, err := csvRune("comment", o.Comment)
+		if err != nil {
+			return nil, err
+		}
+		r.Comment = comment
+	}
+
+	if o.Header != nil && !*o.Header {
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if records == nil {
+			records = [][]string{}
+		}
+		return records, nil
+	}
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return []map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(header))
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if _, ok := seen[header[i]]; ok {
+			return nil, fmt.Errorf("duplicate column %q in header", header[i])
+		}
+		seen[header[i]] = struct{}{}
+	}
+
+	res := []map[string]string{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		res = append(res, row)
+	}
+}
+
+// csvRune returns the single character of the option s.
+func csvRune(option string, s string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == utf8.RuneError {
+		return 0, fmt.Errorf("%s must be a single character, got %q", option, s)
+	}
+	return r, nil
+}
@@ -0,0 +1,858 @@
+package stdlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+// encodingJQ runs a jq program against a value, and returns the single value
+// the program outputs. Strings are decoded as JSON before the program runs.
+//
+// Only a subset of jq is supported; see parseJQ. Programs are limited to
+// maxJQDepth levels of nesting, and to producing values of maxJQSize in total.
+var encodingJQ = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 2 {
+		return value.Null, value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected 2 args, got %d", len(args)),
+		}
+	}
+	if err := checkArgs(funcValue, args[1:], value.TypeString); err != nil {
+		return value.Null, err
+	}
+	input, program := args[0], args[1]
+
+	if input.Type() == value.TypeString {
+		var v interface{}
+		if err := json.Unmarshal([]byte(input.Text()), &v); err != nil {
+			return value.Null, value.Error{Value: input, Inner: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		input = value.Encode(v)
+	}
+
+	filter, err := parseJQ(program.Text())
+	if err != nil {
+		return value.Null, value.Error{Value: program, Inner: err}
+	}
+
+	res, err := filter(input)
+	if err != nil {
+		return value.Null, value.Error{Value: funcValue, Inner: err}
+	}
+	if len(res) != 1 {
+		return value.Null, value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("program returned %d results, expected 1; wrap it in [...] to collect its results into an array", len(res)),
+		}
+	}
+	return res[0], nil
+})
+
+// jqFilter is a compiled jq expression. Like in jq, a filter returns any
+// number of outputs for a single input.
+type jqFilter func(in value.Value) ([]value.Value, error)
+
+// maxJQDepth is the maximum nesting depth of a jq program, which keeps the
+// parser from exhausting the stack.
+const maxJQDepth = 256
+
+// maxJQSize is the maximum total size of the values a jq program produces,
+// counting one for each output, one for each element of the arrays and
+// objects it builds, and one for each byte of their strings. The number of
+// outputs multiplies with each filter, so that without it a short program
+// could exhaust the memory and the CPU of the process.
+const maxJQSize = maxGeneratedSize
+
+var errJQSize = fmt.Errorf("program produced more than the maximum of %d values and bytes", maxJQSize)
+
+// jqBudget tracks the size of the values produced by an evaluation of a jq
+// program against maxJQSize.
+type jqBudget struct {
+	used int
+}
+
+// count adds n outputs to the budget.
+func (b *jqBudget) count(n int) error {
+	if n > maxJQSize-b.used {
+		b.used = maxJQSize + 1
+		return errJQSize
+	}
+	b.used += n
+	return nil
+}
+
+// countProduct adds n*m outputs to the budget without overflowing.
+func (b *jqBudget) countProduct(n, m int) error {
+	if n > 0 && m > maxJQSize/n {
+		return b.count(maxJQSize + 1)
+	}
+	return b.count(n * m)
+}
+
+// charge adds the size of the values to the budget, including the elements of
+// arrays and objects and the bytes of strings.
+func (b *jqBudget) charge(vals ...value.Value) error {
+	for _, v := range vals {
+		if err := b.count(1); err != nil {
+			return err
+		}
+		switch v.Type() {
+		case value.TypeString:
+			if err := b.count(len(v.Text())); err != nil {
+				return err
+			}
+		case value.TypeArray:
+			for i := 0; i < v.Len(); i++ {
+				if err := b.charge(v.Index(i)); err != nil {
+					return err
+				}
+			}
+		case value.TypeObject:
+			for _, key := range v.Keys() {
+				elem, _ := v.Key(key)
+				if err := b.count(len(key)); err != nil {
+					return err
+				}
+				if err := b.charge(elem); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jqFunctions holds the number of arguments of each supported jq function.
+var jqFunctions = map[string]int{
+	"keys":   0,
+	"length": 0,
+	"map":    1,
+	"select": 1,
+}
+
+// parseJQ compiles a jq program. The supported subset of jq is:
+//
+//   - Paths: ., .foo, ."foo", .[0], .[-1], .["foo"] and .[]
+//   - Literals: numbers, strings, true, false and null
+//   - Array and object construction: [...] and {a: .b, "c": .d}
+//   - Operators: |, ",", == and !=
+//   - The functions in jqFunctions
+func parseJQ(program string) (jqFilter, error) {
+	tokens, err := scanJQ(program)
+	if err != nil {
+		return nil, err
+	}
+	p := &jqParser{tokens: tokens, budget: &jqBudget{}}
+
+	filter, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != jqEOF {
+		return nil, p.unexpected(tok)
+	}
+	return func(in value.Value) ([]value.Value, error) {
+		p.budget.used = 0
+		return filter(in)
+	}, nil
+}
+
+type jqTokenKind int
+
+const (
+	jqEOF jqTokenKind = iota
+	jqPunct
+	jqIdent
+	jqNumber
+	jqString
+)
+
+type jqToken struct {
+	kind jqTokenKind
+	text string // For strings, the unquoted text.
+	pos  int
+}
+
+// jqPuncts are the punctuation tokens of jq, longest first.
+var jqPuncts = []string{
+	"==", "!=",
+	".", "[", "]", "{", "}", "(", ")", "|", ",", ":", "-",
+}
+
+func scanJQ(program string) ([]jqToken, error) {
+	var tokens []jqToken
+	pos := 0
+NextToken:
+	for {
+		for pos < len(program) && strings.ContainsRune(" \t\r\n", rune(program[pos])) {
+			pos++
+		}
+		if pos == len(program) {
+			return append(tokens, jqToken{kind: jqEOF, pos: pos}), nil
+		}
+		rest := program[pos:]
+
+		switch c := rest[0]; {
+		case c == '_' || isASCIILetter(c):
+			n := 1
+			for n < len(rest) && (rest[n] == '_' || isASCIILetter(rest[n]) || isASCIIDigit(rest[n])) {
+				n++
+			}
+			tokens = append(tokens, jqToken{kind: jqIdent, text: rest[:n], pos: pos})
+			pos += n
+			continue NextToken
+
+		case isASCIIDigit(c):
+			n := 1
+			for n < len(rest) && (isASCIIDigit(rest[n]) || strings.ContainsRune(".eE", rune(rest[n])) ||
+				(strings.ContainsRune("+-", rune(rest[n])) && strings.ContainsRune("eE", rune(rest[n-1])))) {
+				n++
+			}
+			tokens = append(tokens, jqToken{kind: jqNumber, text: rest[:n], pos: pos})
+			pos += n
+			continue NextToken
+
+		case c == '"':
+			n := 1
+			for n < len(rest) && rest[n] != '"' {
+				if rest[n] == '\\' {
+					n++
+				}
+				n++
+			}
+			if n >= len(rest) {
+				return nil, fmt.Errorf("unterminated string at offset %d", pos)
+			}
+			var text string
+			if err := json.Unmarshal([]byte(rest[:n+1]), &text); err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d", pos)
+			}
+			tokens = append(tokens, jqToken{kind: jqString, text: text, pos: pos})
+			pos += n + 1
+			continue NextToken
+		}
+
+		for _, punct := range jqPuncts {
+			if strings.HasPrefix(rest, punct) {
+				tokens = append(tokens, jqToken{kind: jqPunct, text: punct, pos: pos})
+				pos += len(punct)
+				continue NextToken
+			}
+		}
+		r, _ := utf8.DecodeRuneInString(rest)
+		return nil, fmt.Errorf("unexpected character %q at offset %d", r, pos)
+	}
+}
+
+func isASCIILetter(c byte) bool { return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') }
+
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+type jqParser struct {
+	tokens []jqToken
+	next   int
+	depth  int
+
+	// budget is shared by the filters of the program.
+	budget *jqBudget
+}
+
+// enter increments the nesting depth of the parser, and fails if it's more
+// than maxJQDepth. The caller must call leave when done.
+func (p *jqParser) enter() error {
+	p.depth++
+	if p.depth > maxJQDepth {
+		return fmt.Errorf("program is nested more than %d levels deep at %s", maxJQDepth, describeJQToken(p.peek()))
+	}
+	return nil
+}
+
+func (p *jqParser) leave() { p.depth-- }
+
+func (p *jqParser) peek() jqToken { return p.tokens[p.next] }
+
+func (p *jqParser) advance() jqToken {
+	tok := p.tokens[p.next]
+	if tok.kind != jqEOF {
+		p.next++
+	}
+	return tok
+}
+
+// accept consumes the next token if it's the punctuation or keyword text.
+func (p *jqParser) accept(text string) bool {
+	if tok := p.peek(); (tok.kind == jqPunct || tok.kind == jqIdent) && tok.text == text {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *jqParser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q, got %s", text, describeJQToken(p.peek()))
+	}
+	return nil
+}
+
+func (p *jqParser) unexpected(tok jqToken) error {
+	return fmt.Errorf("unexpected %s", describeJQToken(tok))
+}
+
+func describeJQToken(tok jqToken) string {
+	switch tok.kind {
+	case jqEOF:
+		return "end of program"
+	case jqString:
+		return fmt.Sprintf("string %q at offset %d", tok.text, tok.pos)
+	default:
+		return fmt.Sprintf("%q at offset %d", tok.text, tok.pos)
+	}
+}
+
+// parsePipe parses `a | b`, which runs b for each output of a.
+func (p *jqParser) parsePipe() (jqFilter, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	lhs, err := p.parseComma()
+	if err != nil || !p.accept("|") {
+		return lhs, err
+	}
+	rhs, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return p.pipe(lhs, rhs), nil
+}
+
+// parseComma parses `a, b`, which returns the outputs of a followed by the
+// outputs of b.
+func (p *jqParser) parseComma() (jqFilter, error) {
+	lhs, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		rhs, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		prev := lhs
+		lhs = func(in value.Value) ([]value.Value, error) {
+			a, err := prev(in)
+			if err != nil {
+				return nil, err
+			}
+			b, err := rhs(in)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.budget.count(len(a) + len(b)); err != nil {
+				return nil, err
+			}
+			return append(a, b...), nil
+		}
+	}
+	return lhs, nil
+}
+
+// parseComparison parses `a == b` and `a != b`. Like in Alloy, numbers are
+// equal regardless of their type, so 1 == 1.0 is true.
+func (p *jqParser) parseComparison() (jqFilter, error) {
+	lhs, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!="} {
+		if !p.accept(op) {
+			continue
+		}
+		rhs, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return p.binary(lhs, rhs, func(a, b value.Value) value.Value {
+			return value.Bool(value.Equal(a, b) == (op == "=="))
+		}), nil
+	}
+	return lhs, nil
+}
+
+// binary returns a filter which applies op to each combination of the
+// outputs of lhs and rhs.
+func (p *jqParser) binary(lhs, rhs jqFilter, op func(a, b value.Value) value.Value) jqFilter {
+	return func(in value.Value) ([]value.Value, error) {
+		as, err := lhs(in)
+		if err != nil {
+			return nil, err
+		}
+		bs, err := rhs(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.budget.countProduct(len(as), len(bs)); err != nil {
+			return nil, err
+		}
+
+		res := make([]value.Value, 0, len(as)*len(bs))
+		for _, b := range bs {
+			for _, a := range as {
+				res = append(res, op(a, b))
+			}
+		}
+		return res, nil
+	}
+}
+
+// parsePostfix parses a term followed by any number of field accesses,
+// indexes and iterations.
+func (p *jqParser) parsePostfix() (jqFilter, error) {
+	filter, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var suffix jqFilter
+		switch tok := p.peek(); {
+		case tok.kind == jqPunct && tok.text == ".":
+			p.advance()
+			if suffix, err = p.parseField(tok); err != nil {
+				return nil, err
+			}
+		case tok.kind == jqPunct && tok.text == "[":
+			if suffix, err = p.parseIndex(); err != nil {
+				return nil, err
+			}
+		default:
+			return filter, nil
+		}
+		filter = p.pipe(filter, suffix)
+	}
+}
+
+// parseField parses the name following the "." token dot, like foo in .foo.
+func (p *jqParser) parseField(dot jqToken) (jqFilter, error) {
+	tok := p.peek()
+	if (tok.kind != jqIdent && tok.kind != jqString) || tok.pos != dot.pos+1 {
+		return nil, p.unexpected(tok)
+	}
+	p.advance()
+	key := value.String(tok.text)
+	return func(in value.Value) ([]value.Value, error) {
+		v, err := jqIndex(in, key)
+		if err != nil {
+			return nil, err
+		}
+		return []value.Value{v}, nil
+	}, nil
+}
+
+// parseIndex parses `[]`, which returns each element of an array or object,
+// and `[0]` or `["foo"]`, which return a single element.
+func (p *jqParser) parseIndex() (jqFilter, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.accept("]") {
+		return jqIterate, nil
+	}
+
+	var (
+		key value.Value
+		err error
+	)
+	switch tok := p.advance(); {
+	case tok.kind == jqString:
+		key = value.String(tok.text)
+	case tok.kind == jqNumber:
+		key, err = jqParseNumber(tok, false)
+	case tok.kind == jqPunct && tok.text == "-" && p.peek().kind == jqNumber:
+		key, err = jqParseNumber(p.advance(), true)
+	default:
+		return nil, p.unexpected(tok)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return func(in value.Value) ([]value.Value, error) {
+		v, err := jqIndex(in, key)
+		if err != nil {
+			return nil, err
+		}
+		return []value.Value{v}, nil
+	}, nil
+}
+
+func (p *jqParser) parseTerm() (jqFilter, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case jqNumber:
+		v, err := jqParseNumber(tok, false)
+		return jqConst(v), err
+
+	case jqString:
+		return jqConst(value.String(tok.text)), nil
+
+	case jqIdent:
+		return p.parseIdent(tok)
+
+	case jqPunct:
+		switch tok.text {
+		case ".":
+			if next := p.peek(); (next.kind == jqIdent || next.kind == jqString) && next.pos == tok.pos+1 {
+				return p.parseField(tok)
+			}
+			return jqIdentity, nil
+
+		case "-":
+			if next := p.peek(); next.kind == jqNumber {
+				v, err := jqParseNumber(p.advance(), true)
+				return jqConst(v), err
+			}
+
+		case "(":
+			filter, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return filter, p.expect(")")
+
+		case "[":
+			if p.accept("]") {
+				return jqConst(value.Array()), nil
+			}
+			filter, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return func(in value.Value) ([]value.Value, error) {
+				vals, err := filter(in)
+				if err != nil {
+					return nil, err
+				}
+				arr := value.Array(vals...)
+				if err := p.budget.charge(arr); err != nil {
+					return nil, err
+				}
+				return []value.Value{arr}, nil
+			}, nil
+
+		case "{":
+			return p.parseObject()
+		}
+	}
+	return nil, p.unexpected(tok)
+}
+
+// jqParseNumber parses a number token into an integer if possible, or a
+// floating-point number otherwise.
+func jqParseNumber(tok jqToken, negative bool) (value.Value, error) {
+	text := tok.text
+	if negative {
+		text = "-" + text
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return value.Int(i), nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return value.Null, fmt.Errorf("invalid number %q at offset %d", tok.text, tok.pos)
+	}
+	return value.Float(f), nil
+}
+
+// parseIdent parses the literals true, false and null, and function calls.
+func (p *jqParser) parseIdent(tok jqToken) (jqFilter, error) {
+	switch tok.text {
+	case "true":
+		return jqConst(value.Bool(true)), nil
+	case "false":
+		return jqConst(value.Bool(false)), nil
+	case "null":
+		return jqConst(value.Null), nil
+	}
+
+	arity, ok := jqFunctions[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", tok.text, tok.pos)
+	}
+
+	var args []jqFilter
+	if p.accept("(") {
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) != arity {
+		return nil, fmt.Errorf("function %s expects %d arguments, got %d", tok.text, arity, len(args))
+	}
+	return p.call(tok.text, args), nil
+}
+
+// parseObject parses the entries of an object after the opening brace. An
+// entry is an identifier or a string, followed by a colon and a value.
+func (p *jqParser) parseObject() (jqFilter, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	type entry struct {
+		key   string
+		value jqFilter
+	}
+	var entries []entry
+
+	for !p.accept("}") {
+		if len(entries) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		tok := p.advance()
+		if tok.kind != jqIdent && tok.kind != jqString {
+			return nil, p.unexpected(tok)
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		val, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: tok.text, value: val})
+	}
+
+	return func(in value.Value) ([]value.Value, error) {
+		// Each value may have several outputs, so the result is an object for
+		// each combination of them.
+		objs := []map[string]value.Value{{}}
+		for _, e := range entries {
+			vals, err := e.value(in)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.budget.countProduct(len(objs), len(vals)); err != nil {
+				return nil, err
+			}
+
+			var next []map[string]value.Value
+			for _, obj := range objs {
+				for _, v := range vals {
+					cp := make(map[string]value.Value, len(obj)+1)
+					for k, v := range obj {
+						cp[k] = v
+					}
+					cp[e.key] = v
+					next = append(next, cp)
+				}
+			}
+			objs = next
+		}
+
+		res := make([]value.Value, len(objs))
+		for i, obj := range objs {
+			res[i] = value.Object(obj)
+		}
+		if err := p.budget.charge(res...); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}, nil
+}
+
+func jqIdentity(in value.Value) ([]value.Value, error) {
+	return []value.Value{in}, nil
+}
+
+func jqConst(v value.Value) jqFilter {
+	return func(value.Value) ([]value.Value, error) {
+		return []value.Value{v}, nil
+	}
+}
+
+// pipe returns a filter which runs rhs for each output of lhs.
+func (p *jqParser) pipe(lhs, rhs jqFilter) jqFilter {
+	return func(in value.Value) ([]value.Value, error) {
+		vals, err := lhs(in)
+		if err != nil {
+			return nil, err
+		}
+		var res []value.Value
+		for _, v := range vals {
+			out, err := rhs(v)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.budget.count(len(out)); err != nil {
+				return nil, err
+			}
+			res = append(res, out...)
+		}
+		return res, nil
+	}
+}
+
+// jqIndex returns the element of an object with a string key, or the element
+// of an array with a number index. Indexing null, a missing key or an index
+// out of range returns null, and negative indexes count from the end.
+func jqIndex(in value.Value, key value.Value) (value.Value, error) {
+	switch {
+	case in.Type() == value.TypeNull:
+		return value.Null, nil
+
+	case in.Type() == value.TypeObject && key.Type() == value.TypeString:
+		v, ok := in.Key(key.Text())
+		if !ok {
+			return value.Null, nil
+		}
+		return v, nil
+
+	case in.Type() == value.TypeArray && key.Type() == value.TypeNumber:
+		i := key.Int()
+		if i < 0 {
+			i += int64(in.Len())
+		}
+		if i < 0 || i >= int64(in.Len()) {
+			return value.Null, nil
+		}
+		return in.Index(int(i)), nil
+	}
+
+	if key.Type() == value.TypeString {
+		return value.Null, fmt.Errorf("cannot index %s with %q", in.Type(), key.Text())
+	}
+	return value.Null, fmt.Errorf("cannot index %s with %s", in.Type(), key.Type())
+}
+
+// jqIterate returns the elements of an array, or the values of an object
+// sorted by their keys.
+func jqIterate(in value.Value) ([]value.Value, error) {
+	switch in.Type() {
+	case value.TypeArray:
+		res := make([]value.Value, in.Len())
+		for i := range res {
+			res[i] = in.Index(i)
+		}
+		return res, nil
+	case value.TypeObject:
+		keys := sortedKeys(in)
+		res := make([]value.Value, len(keys))
+		for i, key := range keys {
+			res[i], _ = in.Key(key)
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", in.Type())
+	}
+}
+
+// call returns a filter which calls one of the jqFunctions. The values which
+// the functions build are charged to the budget.
+func (p *jqParser) call(name string, args []jqFilter) jqFilter {
+	switch name {
+	case "select":
+		return func(in value.Value) ([]value.Value, error) {
+			conds, err := args[0](in)
+			if err != nil {
+				return nil, err
+			}
+			var res []value.Value
+			for _, cond := range conds {
+				if jqTruthy(cond) {
+					res = append(res, in)
+				}
+			}
+			return res, nil
+		}
+
+	case "map":
+		return func(in value.Value) ([]value.Value, error) {
+			vals, err := p.pipe(jqIterate, args[0])(in)
+			if err != nil {
+				return nil, err
+			}
+			arr := value.Array(vals...)
+			if err := p.budget.charge(arr); err != nil {
+				return nil, err
+			}
+			return []value.Value{arr}, nil
+		}
+	}
+
+	fn := map[string]func(value.Value) (value.Value, error){
+		"keys":   jqKeys,
+		"length": jqLength,
+	}[name]
+	return func(in value.Value) ([]value.Value, error) {
+		v, err := fn(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.budget.charge(v); err != nil {
+			return nil, err
+		}
+		return []value.Value{v}, nil
+	}
+}
+
+// jqTruthy returns false for false and null, and true for any other value.
+func jqTruthy(v value.Value) bool {
+	switch v.Type() {
+	case value.TypeNull:
+		return false
+	case value.TypeBool:
+		return v.Bool()
+	default:
+		return true
+	}
+}
+
+// jqKeys returns the sorted keys of an object.
+func jqKeys(in value.Value) (value.Value, error) {
+	if in.Type() != value.TypeObject {
+		return value.Null, fmt.Errorf("%s has no keys", in.Type())
+	}
+	keys := sortedKeys(in)
+	res := make([]value.Value, len(keys))
+	for i, key := range keys {
+		res[i] = value.String(key)
+	}
+	return value.Array(res...), nil
+}
+
+// jqLength returns the number of elements of an array or object, the number
+// of characters of a string, or 0 for null.
+func jqLength(in value.Value) (value.Value, error) {
+	switch in.Type() {
+	case value.TypeNull:
+		return value.Int(0), nil
+	case value.TypeArray, value.TypeObject:
+		return value.Int(int64(in.Len())), nil
+	case value.TypeString:
+		return value.Int(int64(utf8.RuneCountInString(in.Text()))), nil
+	default:
+		return value.Null, fmt.Errorf("%s has no length", in.Type())
+	}
+}
@@ -0,0 +1,145 @@
+package stdlib
+
+import (
+	"slices"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+var mapNamespace = map[string]interface{}{
+	"keys":   mapKeys,
+	"values": mapValues,
+	"merge":  mapMerge,
+	"pick":   mapPick,
+	"omit":   mapOmit,
+}
+
+// mapKeys returns the sorted keys of an object.
+var mapKeys = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeObject); err != nil {
+		return value.Null, err
+	}
+
+	keys := sortedKeys(args[0])
+	res := make([]value.Value, len(keys))
+	for i, key := range keys {
+		res[i] = value.String(key)
+	}
+	return value.Array(res...), nil
+})
+
+// mapValues returns the values of an object, sorted by their keys.
+var mapValues = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeObject); err != nil {
+		return value.Null, err
+	}
+
+	keys := sortedKeys(args[0])
+	res := make([]value.Value, len(keys))
+	for i, key := range keys {
+		res[i], _ = args[0].Key(key)
+	}
+	return value.Array(res...), nil
+})
+
+// mapMerge deeply merges objects. When several objects have the same key, the
+// value of the last object is used, unless the values are all objects, in
+// which case they're merged too.
+var mapMerge = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	for i, arg := range args {
+		if arg.Type() != value.TypeObject {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: arg,
+				Index:    i,
+				Inner: value.TypeError{
+					Value:    arg,
+					Expected: value.TypeObject,
+				},
+			}
+		}
+	}
+	return mergeObjects(args), nil
+})
+
+func mergeObjects(objs []value.Value) value.Value {
+	res := make(map[string]value.Value)
+	for _, obj := range objs {
+		for _, key := range obj.Keys() {
+			v, _ := obj.Key(key)
+			if prev, ok := res[key]; ok && prev.Type() == value.TypeObject && v.Type() == value.TypeObject {
+				v = mergeObjects([]value.Value{prev, v})
+			}
+			res[key] = v
+		}
+	}
+	return value.Object(res)
+}
+
+// mapPick returns an object with only the given keys of another object. Keys
+// which aren't in the object are ignored.
+var mapPick = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	obj, keys, err := objectAndKeys(funcValue, args)
+	if err != nil {
+		return value.Null, err
+	}
+
+	res := make(map[string]value.Value, len(keys))
+	for _, key := range keys {
+		if v, ok := obj.Key(key); ok {
+			res[key] = v
+		}
+	}
+	return value.Object(res), nil
+})
+
+// mapOmit returns an object without the given keys of another object.
+var mapOmit = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	obj, keys, err := objectAndKeys(funcValue, args)
+	if err != nil {
+		return value.Null, err
+	}
+
+	res := make(map[string]value.Value, obj.Len())
+	for _, key := range obj.Keys() {
+		if !slices.Contains(keys, key) {
+			res[key], _ = obj.Key(key)
+		}
+	}
+	return value.Object(res), nil
+})
+
+// objectAndKeys checks that args holds an object followed by an array of
+// strings, and returns them.
+func objectAndKeys(funcValue value.Value, args []value.Value) (value.Value, []string, error) {
+	if err := checkArgs(funcValue, args, value.TypeObject, value.TypeArray); err != nil {
+		return value.Null, nil, err
+	}
+
+	keys := make([]string, args[1].Len())
+	for i := range keys {
+		key := args[1].Index(i)
+		if key.Type() != value.TypeString {
+			return value.Null, nil, value.ArgError{
+				Function: funcValue,
+				Argument: args[1],
+				Index:    1,
+				Inner: value.ElementError{
+					Value: args[1],
+					Index: i,
+					Inner: value.TypeError{Value: key, Expected: value.TypeString},
+				},
+			}
+		}
+		keys[i] = key.Text()
+	}
+	return args[0], keys, nil
+}
+
+func sortedKeys(obj value.Value) []string {
+	// Keys may return a slice which is shared with obj, so it's cloned before
+	// being sorted.
+	keys := slices.Clone(obj.Keys())
+	slices.Sort(keys)
+	return keys
+}
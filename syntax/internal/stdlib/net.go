@@ -0,0 +1,72 @@
+package stdlib
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+var netNamespace = map[string]interface{}{
+	"cidr_contains": cidrContains,
+	"cidr_host":     cidrHost,
+	"parse_ip":      parseIP,
+	"is_private":    isPrivateIP,
+}
+
+// cidrContains reports whether the IP address ip is in the CIDR block cidr.
+func cidrContains(cidr string, ip string) (bool, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return false, err
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, err
+	}
+	return prefix.Contains(addr.Unmap()), nil
+}
+
+// cidrHost returns the IP address with the host number n in the CIDR block
+// cidr. A negative n counts back from the last address of the block.
+func cidrHost(cidr string, n int64) (string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return "", err
+	}
+	prefix = prefix.Masked()
+
+	hostBits := uint(prefix.Addr().BitLen() - prefix.Bits())
+	size := new(big.Int).Lsh(big.NewInt(1), hostBits)
+
+	num := big.NewInt(n)
+	if n < 0 {
+		num.Add(num, size)
+	}
+	if num.Sign() < 0 || num.Cmp(size) >= 0 {
+		return "", fmt.Errorf("host number %d is out of range of %s, which has %s addresses", n, prefix, size)
+	}
+
+	base := prefix.Addr().AsSlice()
+	num.Add(num, new(big.Int).SetBytes(base))
+	addr, _ := netip.AddrFromSlice(num.FillBytes(make([]byte, len(base))))
+	return addr.String(), nil
+}
+
+// parseIP returns the canonical form of the IP address ip.
+func parseIP(ip string) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// isPrivateIP reports whether the IP address ip is in one of the private
+// address ranges of RFC 1918 and RFC 4193.
+func isPrivateIP(ip string) (bool, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, err
+	}
+	return addr.Unmap().IsPrivate(), nil
+}
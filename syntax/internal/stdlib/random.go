@@ -0,0 +1,90 @@
+package stdlib
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"reflect"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+var randomNamespace = map[string]interface{}{
+	"int":    randomInt,
+	"string": randomString,
+}
+
+// randomInt returns a random integer between min and max, both inclusive. If
+// a seed is given, the same arguments always return the same integer.
+func randomInt(min int64, max int64, seed ...string) (int64, error) {
+	r, err := newRandom(seed)
+	if err != nil {
+		return 0, err
+	}
+	if min > max {
+		return 0, fmt.Errorf("min (%d) must not be greater than max (%d)", min, max)
+	}
+
+	span := uint64(max) - uint64(min)
+	if span >= math.MaxInt64 {
+		return 0, fmt.Errorf("range [%d, %d] is too large", min, max)
+	}
+	return min + r.Int63n(int64(span)+1), nil
+}
+
+// randomString returns a string of n characters picked randomly from charset.
+// If a seed is given, the same arguments always return the same string.
+func randomString(n int64, charset string, seed ...string) (string, error) {
+	r, err := newRandom(seed)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", fmt.Errorf("length must not be negative, got %d", n)
+	}
+	if n > maxGeneratedSize {
+		return "", fmt.Errorf("length must not be more than %d, got %d", maxGeneratedSize, n)
+	}
+
+	chars := []rune(charset)
+	if len(chars) == 0 {
+		return "", fmt.Errorf("charset must not be empty")
+	}
+
+	res := make([]rune, n)
+	for i := range res {
+		res[i] = chars[r.Intn(len(chars))]
+	}
+	return string(res), nil
+}
+
+// IsRandom returns true if fn is a function of the random namespace, or
+// uuid.v4.
+func IsRandom(fn value.Value) bool {
+	ptr := fn.Reflect().Pointer()
+	if reflect.ValueOf(uuidV4).Pointer() == ptr {
+		return true
+	}
+	for _, f := range randomNamespace {
+		if reflect.ValueOf(f).Pointer() == ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// newRandom returns a random number generator. It's seeded from the hash of
+// the optional seed, or randomly if there is no seed.
+func newRandom(seed []string) (*rand.Rand, error) {
+	switch len(seed) {
+	case 0:
+		return rand.New(rand.NewSource(rand.Int63())), nil
+	case 1:
+		h := fnv.New64a()
+		h.Write([]byte(seed[0]))
+		return rand.New(rand.NewSource(int64(h.Sum64()))), nil
+	default:
+		return nil, fmt.Errorf("expected at most 1 seed, got %d", len(seed))
+	}
+}
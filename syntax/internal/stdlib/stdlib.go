@@ -3,13 +3,21 @@ package stdlib
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/grafana/alloy/syntax/internal/value"
 	"github.com/ohler55/ojg/jp"
@@ -25,7 +33,7 @@ var DeprecatedIdentifiers = map[string]interface{}{
 	"json_decode":   jsonDecode,
 	"yaml_decode":   yamlDecode,
 	"base64_decode": base64Decode,
-	"format":        fmt.Sprintf,
+	"format":        stringFormat,
 	"join":          strings.Join,
 	"replace":       strings.ReplaceAll,
 	"split":         strings.Split,
@@ -51,12 +59,26 @@ var Identifiers = map[string]interface{}{
 	// New stdlib functions
 	"sys":      sys,
 	"convert":  convert,
+	"crypto":   crypto,
 	"array":    array,
 	"encoding": encoding,
 	"string":   str,
+	"targets":  targetsNamespace,
 	"file":     file,
+	"map":      mapNamespace,
+	"net":      netNamespace,
+	"random":   randomNamespace,
+	"time":     timeNamespace,
+	"url":      urlNamespace,
+	"uuid":     uuidNamespace,
+	"version":  versionNamespace,
 }
 
+// maxGeneratedSize is the maximum number of elements of the arrays, and the
+// maximum length of the strings, which functions like array.range generate. It
+// keeps a single expression from exhausting the memory of the process.
+const maxGeneratedSize = 1 << 20
+
 func init() {
 	// Adds the deprecatedIdentifiers to the map of valid identifiers.
 	maps.Copy(Identifiers, DeprecatedIdentifiers)
@@ -64,43 +86,188 @@ func init() {
 
 var file = map[string]interface{}{
 	"path_join": filepath.Join,
+	"exists":    fileExists,
+	"glob":      fileGlob,
 }
 
 var encoding = map[string]interface{}{
+	"from_csv":    csvDecode,
 	"from_json":   jsonDecode,
 	"from_yaml":   yamlDecode,
+	"from_toml":   tomlDecode,
+	"from_xml":    xmlDecode,
 	"from_base64": base64Decode,
+	"to_base64":   base64Encode,
+	"from_hex":    hexDecode,
+	"to_hex":      hexEncode,
+	"jq":          encodingJQ,
 }
 
 var str = map[string]interface{}{
-	"format":      fmt.Sprintf,
-	"join":        strings.Join,
-	"replace":     strings.ReplaceAll,
-	"split":       strings.Split,
-	"to_lower":    strings.ToLower,
-	"to_upper":    strings.ToUpper,
-	"trim":        strings.Trim,
-	"trim_prefix": strings.TrimPrefix,
-	"trim_suffix": strings.TrimSuffix,
-	"trim_space":  strings.TrimSpace,
+	"camel_case":     stringCamelCase,
+	"contains":       strings.Contains,
+	"fields":         strings.Fields,
+	"format":         stringFormat,
+	"has_prefix":     strings.HasPrefix,
+	"has_suffix":     strings.HasSuffix,
+	"join":           strings.Join,
+	"pad_left":       stringPadLeft,
+	"pad_right":      stringPadRight,
+	"regex_find_all": regexFindAll,
+	"regex_match":    regexMatch,
+	"regex_replace":  regexReplace,
+	"repeat":         stringRepeat,
+	"replace":        strings.ReplaceAll,
+	"snake_case":     stringSnakeCase,
+	"split":          strings.Split,
+	"template":       stringTemplate,
+	"title":          stringTitle,
+	"to_lower":       strings.ToLower,
+	"to_upper":       strings.ToUpper,
+	"trim":           strings.Trim,
+	"trim_prefix":    strings.TrimPrefix,
+	"trim_suffix":    strings.TrimSuffix,
+	"trim_space":     strings.TrimSpace,
 }
 
 var array = map[string]interface{}{
-	"concat": concat,
+	"concat":   concat,
+	"filter":   arrayFilter,
+	"map":      arrayMap,
+	"sort":     arraySort,
+	"unique":   arrayUnique,
+	"reverse":  arrayReverse,
+	"contains": arrayContains,
+	"index_of": arrayIndexOf,
+	"any":      arrayAny,
+	"range":    arrayRange,
+	"slice":    arraySlice,
 }
 
 var convert = map[string]interface{}{
-	"nonsensitive": nonSensitive,
+	"nonsensitive":   nonSensitive,
+	"to_secret":      toSecret,
+	"to_int":         toInt,
+	"to_float":       toFloat,
+	"to_bool":        toBool,
+	"parse_duration": parseDuration,
+	"parse_bytes":    parseBytes,
 }
 
 var sys = map[string]interface{}{
-	"env": os.Getenv,
+	"env":      sysEnv,
+	"hostname": os.Hostname,
+}
+
+// sysEnv returns the value of an environment variable. If the variable is
+// unset or empty, it returns the optional default value instead.
+func sysEnv(name string, def ...string) (string, error) {
+	if len(def) > 1 {
+		return "", fmt.Errorf("expected at most 1 default value, got %d", len(def))
+	}
+
+	if v := os.Getenv(name); v != "" || len(def) == 0 {
+		return v, nil
+	}
+	return def[0], nil
 }
 
 func nonSensitive(secret alloytypes.Secret) string {
 	return string(secret)
 }
 
+// toSecret marks a string as sensitive. Secrets are returned unchanged.
+func toSecret(in alloytypes.Secret) alloytypes.Secret {
+	return in
+}
+
+// stringFormat formats its arguments like fmt.Sprintf. If any argument is a
+// secret, the result is a secret too.
+func stringFormat(format string, args ...interface{}) interface{} {
+	var secret bool
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case alloytypes.Secret:
+			args[i], secret = string(arg), true
+		case alloytypes.OptionalSecret:
+			args[i], secret = arg.Value, secret || arg.IsSecret
+		}
+	}
+
+	res := fmt.Sprintf(format, args...)
+	if secret {
+		return alloytypes.Secret(res)
+	}
+	return res
+}
+
+// regexMatch reports whether in contains a match of the regular expression
+// pattern.
+func regexMatch(in string, pattern string) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(in), nil
+}
+
+// regexFindAll returns all the successive matches of the regular expression
+// pattern in in. Each match is a list holding the text of the match followed
+// by the text of its capture groups.
+func regexFindAll(in string, pattern string) ([][]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matches := re.FindAllStringSubmatch(in, -1)
+	if matches == nil {
+		return [][]string{}, nil
+	}
+	return matches, nil
+}
+
+// regexReplace replaces the matches of the regular expression pattern in in
+// with replacement. Inside replacement, $1 or ${1} stand for the text of the
+// first capture group, and ${name} for the text of the capture group named
+// name.
+func regexReplace(in string, pattern string, replacement string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(in, replacement), nil
+}
+
+// stringTemplate renders the Go text/template tmpl with the values of vars.
+// Referring to a key which isn't in vars is an error.
+func stringTemplate(tmpl string, vars map[string]interface{}) (string, error) {
+	t, err := template.New("template").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&limitedWriter{w: &sb, n: maxGeneratedSize}, vars); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// limitedWriter writes to w until n bytes are written, and fails afterwards,
+// which stops the execution of a template rendering a large output.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.n {
+		return 0, fmt.Errorf("output must not be longer than %d bytes", maxGeneratedSize)
+	}
+	lw.n -= len(p)
+	return lw.w.Write(p)
+}
+
 // concat is implemented as a raw function so it can bypass allocations
 // converting arguments into []interface{}. concat is optimized to allow it
 // to perform well when it is in the hot path for combining targets from many
@@ -146,6 +313,30 @@ var concat = value.RawFunction(func(funcValue value.Value, args ...value.Value)
 	return value.Array(raw...), nil
 })
 
+// fileExists returns true if a file or directory exists at path.
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fileGlob returns the sorted paths matching pattern, which uses the syntax
+// of filepath.Match.
+func fileGlob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		return []string{}, nil
+	}
+	return matches, nil
+}
+
 func jsonDecode(in string) (interface{}, error) {
 	var res interface{}
 	err := json.Unmarshal([]byte(in), &res)
@@ -164,6 +355,39 @@ func yamlDecode(in string) (interface{}, error) {
 	return res, nil
 }
 
+func tomlDecode(in string) (interface{}, error) {
+	var res map[string]interface{}
+	if _, err := toml.Decode(in, &res); err != nil {
+		return nil, err
+	}
+	return tomlTimesToStrings(res), nil
+}
+
+// tomlTimesToStrings replaces the TOML dates and times nested in v with RFC
+// 3339 strings, which is how times are represented in Alloy.
+func tomlTimesToStrings(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = tomlTimesToStrings(e)
+		}
+	case []map[string]interface{}:
+		// Arrays of tables are decoded as []map[string]interface{}.
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			res[i] = tomlTimesToStrings(e)
+		}
+		return res
+	case []interface{}:
+		for i, e := range v {
+			v[i] = tomlTimesToStrings(e)
+		}
+	}
+	return v
+}
+
 func base64Decode(in string) (interface{}, error) {
 	decoded, err := base64.StdEncoding.DecodeString(in)
 	if err != nil {
@@ -172,6 +396,22 @@ func base64Decode(in string) (interface{}, error) {
 	return decoded, nil
 }
 
+func base64Encode(in string) string {
+	return base64.StdEncoding.EncodeToString([]byte(in))
+}
+
+func hexDecode(in string) (string, error) {
+	decoded, err := hex.DecodeString(in)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+func hexEncode(in string) string {
+	return hex.EncodeToString([]byte(in))
+}
+
 func jsonPath(jsonString string, path string) (interface{}, error) {
 	jsonPathExpr, err := jp.ParseString(path)
 	if err != nil {
@@ -0,0 +1,143 @@
+package stdlib
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stringPadLeft pads in on the left with pad, which defaults to a space,
+// until it's width characters long.
+func stringPadLeft(in string, width int, pad ...string) (string, error) {
+	padding, err := makePadding(in, width, pad)
+	if err != nil {
+		return "", err
+	}
+	return padding + in, nil
+}
+
+// stringPadRight pads in on the right with pad, which defaults to a space,
+// until it's width characters long.
+func stringPadRight(in string, width int, pad ...string) (string, error) {
+	padding, err := makePadding(in, width, pad)
+	if err != nil {
+		return "", err
+	}
+	return in + padding, nil
+}
+
+func makePadding(in string, width int, pad []string) (string, error) {
+	char := " "
+	switch len(pad) {
+	case 0:
+	case 1:
+		if utf8.RuneCountInString(pad[0]) != 1 {
+			return "", fmt.Errorf("padding must be a single character, got %q", pad[0])
+		}
+		char = pad[0]
+	default:
+		return "", fmt.Errorf("expected at most 1 padding character, got %d", len(pad))
+	}
+
+	if width > maxGeneratedSize {
+		return "", fmt.Errorf("width must not be more than %d, got %d", maxGeneratedSize, width)
+	}
+
+	n := width - utf8.RuneCountInString(in)
+	if n <= 0 {
+		return "", nil
+	}
+	return strings.Repeat(char, n), nil
+}
+
+// stringRepeat returns count copies of in.
+func stringRepeat(in string, count int) (string, error) {
+	if count < 0 {
+		return "", fmt.Errorf("count must not be negative, got %d", count)
+	}
+	if len(in) > 0 && count > maxGeneratedSize/len(in) {
+		return "", fmt.Errorf("result must not be longer than %d bytes, got %d copies of %d bytes", maxGeneratedSize, count, len(in))
+	}
+	return strings.Repeat(in, count), nil
+}
+
+// stringTitle converts the first letter of each word of in to upper case.
+func stringTitle(in string) string {
+	var sb strings.Builder
+	prev := ' '
+	for _, r := range in {
+		if !isWordRune(prev) {
+			r = unicode.ToTitle(r)
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return sb.String()
+}
+
+// stringSnakeCase converts in to lower case words separated by underscores,
+// like "api_server_name".
+func stringSnakeCase(in string) string {
+	words := splitWords(in)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// stringCamelCase converts in to words without separators, where all words
+// but the first one start with an upper case letter, like "apiServerName".
+func stringCamelCase(in string) string {
+	words := splitWords(in)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			r, size := utf8.DecodeRuneInString(word)
+			word = string(unicode.ToUpper(r)) + word[size:]
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits in into words. Words are separated by any character which
+// isn't a letter or a digit, and by changes of case like in "apiServer" or
+// "HTTPServer".
+func splitWords(in string) []string {
+	runes := []rune(in)
+
+	var (
+		words []string
+		start = -1
+	)
+	for i, r := range runes {
+		if !isWordRune(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextIsLower {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
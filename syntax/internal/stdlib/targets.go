@@ -0,0 +1,237 @@
+package stdlib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+var targetsNamespace = map[string]interface{}{
+	"filter":   targetsFilter,
+	"group_by": targetsGroupBy,
+}
+
+// targetsFilter returns the targets which match all the label matchers of a
+// selector like `{job=~"api.*", env!="dev"}`.
+var targetsFilter = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray, value.TypeString); err != nil {
+		return value.Null, err
+	}
+	targets := args[0]
+
+	matchers, err := parseSelector(args[1].Text())
+	if err != nil {
+		return value.Null, value.Error{Value: args[1], Inner: err}
+	}
+
+	res := make([]value.Value, 0, targets.Len())
+	for i := 0; i < targets.Len(); i++ {
+		target := targets.Index(i)
+		if target.Type() != value.TypeObject {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: targets,
+				Index:    0,
+				Inner: value.ElementError{
+					Value: targets,
+					Index: i,
+					Inner: value.TypeError{Value: target, Expected: value.TypeObject},
+				},
+			}
+		}
+
+		matches, err := matchTarget(target, matchers)
+		if err != nil {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: targets,
+				Index:    0,
+				Inner:    value.ElementError{Value: targets, Index: i, Inner: err},
+			}
+		}
+		if matches {
+			res = append(res, target)
+		}
+	}
+	return value.Array(res...), nil
+})
+
+// targetsGroupBy groups targets by the values of some labels. It returns an
+// object which maps the values of the labels, joined with ";", to the list of
+// targets with these values.
+var targetsGroupBy = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if err := checkArgs(funcValue, args, value.TypeArray, value.TypeArray); err != nil {
+		return value.Null, err
+	}
+	targets := args[0]
+
+	labels := make([]string, args[1].Len())
+	for i := range labels {
+		label := args[1].Index(i)
+		if label.Type() != value.TypeString {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: args[1],
+				Index:    1,
+				Inner: value.ElementError{
+					Value: args[1],
+					Index: i,
+					Inner: value.TypeError{Value: label, Expected: value.TypeString},
+				},
+			}
+		}
+		labels[i] = label.Text()
+	}
+
+	groups := make(map[string][]value.Value)
+	values := make([]string, len(labels))
+	for i := 0; i < targets.Len(); i++ {
+		target := targets.Index(i)
+		if target.Type() != value.TypeObject {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: targets,
+				Index:    0,
+				Inner: value.ElementError{
+					Value: targets,
+					Index: i,
+					Inner: value.TypeError{Value: target, Expected: value.TypeObject},
+				},
+			}
+		}
+
+		for j, label := range labels {
+			v, err := targetLabel(target, label)
+			if err != nil {
+				return value.Null, value.ArgError{
+					Function: funcValue,
+					Argument: targets,
+					Index:    0,
+					Inner:    value.ElementError{Value: targets, Index: i, Inner: err},
+				}
+			}
+			values[j] = v
+		}
+
+		key := strings.Join(values, ";")
+		groups[key] = append(groups[key], target)
+	}
+
+	res := make(map[string]value.Value, len(groups))
+	for key, group := range groups {
+		res[key] = value.Array(group...)
+	}
+	return value.Object(res), nil
+})
+
+// matchTarget returns true if target matches all matchers. Labels which aren't
+// in target are matched as empty strings.
+func matchTarget(target value.Value, matchers []labelMatcher) (bool, error) {
+	for _, m := range matchers {
+		label, err := targetLabel(target, m.name)
+		if err != nil {
+			return false, err
+		}
+		if !m.matches(label) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// targetLabel returns the value of a label of target, or an empty string if
+// target doesn't have the label.
+func targetLabel(target value.Value, name string) (string, error) {
+	v, ok := target.Key(name)
+	if !ok {
+		return "", nil
+	}
+	if v.Type() != value.TypeString {
+		return "", value.FieldError{
+			Value: target,
+			Field: name,
+			Inner: value.TypeError{Value: v, Expected: value.TypeString},
+		}
+	}
+	return v.Text(), nil
+}
+
+// labelMatcher is a single label matcher of a selector, like `job=~"api.*"`.
+type labelMatcher struct {
+	name  string
+	op    string // One of "=", "!=", "=~" or "!~"
+	value string
+	re    *regexp.Regexp // Set for "=~" and "!~"
+}
+
+func (m labelMatcher) matches(label string) bool {
+	switch m.op {
+	case "=":
+		return label == m.value
+	case "!=":
+		return label != m.value
+	case "=~":
+		return m.re.MatchString(label)
+	default: // "!~"
+		return !m.re.MatchString(label)
+	}
+}
+
+var (
+	selectorLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+	selectorOperator  = regexp.MustCompile(`^(=~|!~|!=|=)`)
+)
+
+// parseSelector parses a selector made of label matchers between curly
+// braces, like `{job=~"api.*", env!="dev"}`. Like in Prometheus, regular
+// expressions must match the whole label value.
+func parseSelector(selector string) ([]labelMatcher, error) {
+	rest := strings.TrimSpace(selector)
+	if !strings.HasPrefix(rest, "{") || !strings.HasSuffix(rest, "}") {
+		return nil, fmt.Errorf("selector should be surrounded by curly braces")
+	}
+	rest = rest[1 : len(rest)-1]
+
+	var matchers []labelMatcher
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return matchers, nil
+		}
+
+		var m labelMatcher
+		if m.name = selectorLabelName.FindString(rest); m.name == "" {
+			return nil, fmt.Errorf("expected label name at %q", rest)
+		}
+		rest = strings.TrimSpace(rest[len(m.name):])
+
+		if m.op = selectorOperator.FindString(rest); m.op == "" {
+			return nil, fmt.Errorf("expected one of =, !=, =~ or !~ after label %q", m.name)
+		}
+		rest = strings.TrimSpace(rest[len(m.op):])
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil || quoted[0] == '\'' {
+			return nil, fmt.Errorf("expected quoted string for label %q", m.name)
+		}
+		m.value, _ = strconv.Unquote(quoted)
+		rest = strings.TrimSpace(rest[len(quoted):])
+
+		if m.op == "=~" || m.op == "!~" {
+			if m.re, err = regexp.Compile("^(?:" + m.value + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid regular expression for label %q: %w", m.name, err)
+			}
+		}
+		matchers = append(matchers, m)
+
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("expected , or } after the matcher for label %q", m.name)
+			}
+			rest = rest[1:]
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package stdlib
+
+import (
+	"fmt"
+	"time"
+)
+
+// Times are represented as RFC 3339 strings, so that they can be assigned to
+// string attributes and used in other string functions without conversion.
+var timeNamespace = map[string]interface{}{
+	"now":    timeNow,
+	"parse":  timeParse,
+	"format": timeFormat,
+	"unix":   timeUnix,
+	"add":    timeAdd,
+}
+
+// timeNow returns the current time in UTC.
+func timeNow() string {
+	return formatTime(time.Now())
+}
+
+// timeParse parses in according to the Go time layout. Times without a time
+// zone are in UTC.
+func timeParse(layout string, in string) (string, error) {
+	t, err := time.Parse(layout, in)
+	if err != nil {
+		return "", err
+	}
+	return formatTime(t), nil
+}
+
+// timeFormat formats the time t according to the Go time layout.
+func timeFormat(t string, layout string) (string, error) {
+	parsed, err := parseTime(t)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Format(layout), nil
+}
+
+// timeUnix returns the number of seconds elapsed between January 1, 1970 UTC
+// and the time t.
+func timeUnix(t string) (int64, error) {
+	parsed, err := parseTime(t)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Unix(), nil
+}
+
+// timeAdd returns the time t plus the duration d, which may be negative.
+func timeAdd(t string, d string) (string, error) {
+	parsed, err := parseTime(t)
+	if err != nil {
+		return "", err
+	}
+	duration, err := time.ParseDuration(d)
+	if err != nil {
+		return "", err
+	}
+	return formatTime(parsed.Add(duration)), nil
+}
+
+func parseTime(t string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339Nano, t)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time", t)
+	}
+	return parsed, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
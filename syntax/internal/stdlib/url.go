@@ -0,0 +1,63 @@
+package stdlib
+
+import (
+	"fmt"
+	"net/url"
+)
+
+var urlNamespace = map[string]interface{}{
+	"parse":        urlParse,
+	"encode":       urlEncode,
+	"query_escape": url.QueryEscape,
+	"join":         url.JoinPath,
+}
+
+// urlParse parses a URL into an object holding its components.
+func urlParse(in string) (map[string]interface{}, error) {
+	u, err := url.Parse(in)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]interface{})
+	for name, values := range u.Query() {
+		params[name] = values
+	}
+
+	return map[string]interface{}{
+		"scheme":   u.Scheme,
+		"username": u.User.Username(),
+		"host":     u.Hostname(),
+		"port":     u.Port(),
+		"path":     u.Path,
+		"query":    u.RawQuery,
+		"params":   params,
+		"fragment": u.Fragment,
+	}, nil
+}
+
+// urlEncode encodes params into a URL query string, sorted by name. The value
+// of a parameter is either a single value or an array of values.
+func urlEncode(params map[string]interface{}) (string, error) {
+	values := make(url.Values, len(params))
+	for name, v := range params {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				values.Add(name, queryValue(e))
+			}
+		case nil:
+			return "", fmt.Errorf("parameter %q is null", name)
+		default:
+			values.Add(name, queryValue(v))
+		}
+	}
+	return values.Encode(), nil
+}
+
+func queryValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,83 @@
+package stdlib
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+var uuidNamespace = map[string]interface{}{
+	"v4": uuidV4,
+	"v5": uuidV5,
+}
+
+// The namespaces defined by RFC 4122, which can be passed to uuid.v5 by name.
+var uuidNamespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+// uuidV4 returns a random UUID.
+func uuidV4() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	return formatUUID(u, 4), nil
+}
+
+// uuidV5 returns the UUID derived from the SHA-1 hash of the namespace and
+// name. namespace is either a UUID or the name of an RFC 4122 namespace.
+func uuidV5(namespace string, name string) (string, error) {
+	if ns, ok := uuidNamespaces[namespace]; ok {
+		namespace = ns
+	}
+	ns, err := parseUUID(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha1.New()
+	h.Write(ns[:])
+	h.Write([]byte(name))
+
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+	return formatUUID(u, 5), nil
+}
+
+// formatUUID sets the version and the RFC 4122 variant of u, and returns its
+// string form.
+func formatUUID(u [16]byte, version byte) string {
+	u[6] = (u[6] & 0x0f) | version<<4
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("%q is not a UUID or a namespace name", s)
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return u, fmt.Errorf("%q is not a UUID or a namespace name", s)
+	}
+	copy(u[:], b)
+	return u, nil
+}
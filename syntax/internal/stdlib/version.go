@@ -0,0 +1,142 @@
+package stdlib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionNamespace = map[string]interface{}{
+	"parse":    versionParse,
+	"compare":  versionCompare,
+	"at_least": versionAtLeast,
+}
+
+// semver is a version following the Semantic Versioning specification.
+type semver struct {
+	Major      int64  `alloy:"major,attr"`
+	Minor      int64  `alloy:"minor,attr"`
+	Patch      int64  `alloy:"patch,attr"`
+	Prerelease string `alloy:"prerelease,attr"`
+	Build      string `alloy:"build,attr"`
+}
+
+// versionPattern matches versions like "1.2.3", "v1.28.3-gke.100" or
+// "2.4.0-rc.1+build.5". The minor and patch numbers may be omitted.
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+func parseSemver(in string) (semver, error) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(in))
+	if m == nil {
+		return semver{}, fmt.Errorf("%q is not a version", in)
+	}
+
+	var (
+		v      = semver{Prerelease: m[4], Build: m[5]}
+		fields = []*int64{&v.Major, &v.Minor, &v.Patch}
+	)
+	for i, field := range fields {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(m[i+1], 10, 64)
+		if err != nil {
+			return semver{}, fmt.Errorf("%q is not a version: %q is too large", in, m[i+1])
+		}
+		*field = n
+	}
+	return v, nil
+}
+
+// versionParse parses a version into an object with its major, minor and
+// patch numbers, its prerelease and its build metadata. Missing minor and
+// patch numbers are 0.
+func versionParse(in string) (semver, error) {
+	return parseSemver(in)
+}
+
+// versionCompare returns -1, 0 or 1 if a is lower than, equal to or greater
+// than b in the Semantic Versioning precedence. Build metadata is ignored.
+func versionCompare(a string, b string) (int, error) {
+	va, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareSemver(va, vb), nil
+}
+
+// versionAtLeast returns true if the version in is greater than or equal to
+// the version min.
+func versionAtLeast(in string, min string) (bool, error) {
+	cmp, err := versionCompare(in, min)
+	if err != nil {
+		return false, err
+	}
+	return cmp >= 0, nil
+}
+
+func compareSemver(a, b semver) int {
+	for _, c := range []int{
+		cmpOrdered(a.Major, b.Major),
+		cmpOrdered(a.Minor, b.Minor),
+		cmpOrdered(a.Patch, b.Patch),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+
+	// A version without a prerelease has a higher precedence than the same
+	// version with a prerelease.
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+
+	aIDs, bIDs := strings.Split(a.Prerelease, "."), strings.Split(b.Prerelease, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := comparePrereleaseID(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return cmpOrdered(len(aIDs), len(bIDs))
+}
+
+// comparePrereleaseID compares two dot-separated identifiers of a prerelease.
+// Numeric identifiers are compared numerically and have a lower precedence
+// than alphanumeric identifiers, which are compared lexically.
+func comparePrereleaseID(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmpOrdered(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// cmpOrdered returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func cmpOrdered[T int | int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,130 @@
+package stdlib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	defaultXMLAttributePrefix = "@"
+	defaultXMLTextKey         = "#text"
+)
+
+// xmlElement is an XML element being decoded.
+type xmlElement struct {
+	name   string
+	fields map[string]interface{}
+	text   strings.Builder
+}
+
+// xmlDecode decodes an XML document into an object holding its root element.
+//
+// Elements with neither attributes nor child elements are decoded as their
+// text. Other elements are decoded as objects, whose fields are their
+// attributes, prefixed with the attribute prefix, and their child elements.
+// The text of an element decoded as an object is stored under the text key.
+// Child elements which appear more than once are decoded as arrays.
+//
+// The attribute prefix and text key can be changed with the
+// attribute_prefix and text_key options.
+func xmlDecode(in string, opts ...map[string]string) (interface{}, error) {
+	attrPrefix, textKey := defaultXMLAttributePrefix, defaultXMLTextKey
+	if len(opts) > 1 {
+		return nil, fmt.Errorf("expected at most one options object, got %d", len(opts))
+	}
+	for _, o := range opts {
+		for k, v := range o {
+			switch k {
+			case "attribute_prefix":
+				attrPrefix = v
+			case "text_key":
+				textKey = v
+			default:
+				return nil, fmt.Errorf("unsupported option %q", k)
+			}
+		}
+	}
+
+	var (
+		root  map[string]interface{}
+		stack []*xmlElement
+	)
+
+	d := xml.NewDecoder(bytes.NewReader([]byte(in)))
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected element %q after the root element", tok.Name.Local)
+			}
+			e := &xmlElement{name: tok.Name.Local, fields: make(map[string]interface{})}
+			for _, attr := range tok.Attr {
+				// Namespace declarations aren't attributes of the element.
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				e.fields[attrPrefix+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, e)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			}
+
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			value := e.value(textKey)
+			if len(stack) == 0 {
+				root = map[string]interface{}{e.name: value}
+				continue
+			}
+			addXMLField(stack[len(stack)-1].fields, e.name, value)
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("missing root element")
+	}
+	return root, nil
+}
+
+// value returns the decoded value of e.
+func (e *xmlElement) value(textKey string) interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.fields) == 0 {
+		return text
+	}
+	if text != "" {
+		e.fields[textKey] = text
+	}
+	return e.fields
+}
+
+// addXMLField adds the value of a child element to fields, turning the field
+// into an array if the element appears more than once.
+func addXMLField(fields map[string]interface{}, name string, value interface{}) {
+	existing, ok := fields[name]
+	if !ok {
+		fields[name] = value
+		return
+	}
+	if values, ok := existing.([]interface{}); ok {
+		fields[name] = append(values, value)
+		return
+	}
+	fields[name] = []interface{}{existing, value}
+}
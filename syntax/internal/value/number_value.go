@@ -133,3 +133,19 @@ func (nv Number) ToString() string {
 	}
 	panic("syntax/value: unreachable")
 }
+
+// FitNumberKinds returns the NumberKind which can represent numbers of both
+// kinds a and b.
+func FitNumberKinds(a, b NumberKind) NumberKind {
+	aPrec, bPrec := numberKindPrec[a], numberKindPrec[b]
+	if aPrec > bPrec {
+		return a
+	}
+	return b
+}
+
+var numberKindPrec = map[NumberKind]int{
+	NumberKindUint:  0,
+	NumberKindInt:   1,
+	NumberKindFloat: 2,
+}
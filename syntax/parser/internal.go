@@ -379,9 +379,65 @@ func (n blockName) ValidAttribute() bool {
 
 // ParseExpression parses a single expression.
 //
-//	Expression = BinOpExpr
+//	Expression = CondExpr [ "=>" Expression ]
+//
+// An expression followed by "=>" is an anonymous function, and the expression
+// before "=>" must be a single identifier naming its parameter.
 func (p *parser) ParseExpression() ast.Expr {
-	return p.parseBinOp(1)
+	expr := p.parseCondExpr()
+	if p.tok != token.ARROW {
+		return expr
+	}
+
+	arrowPos, _, _ := p.expect(token.ARROW)
+	body := p.ParseExpression()
+
+	param, ok := expr.(*ast.IdentifierExpr)
+	if !ok {
+		p.diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			StartPos: ast.StartPos(expr).Position(),
+			EndPos:   ast.EndPos(expr).Position(),
+			Message:  "expected identifier as function parameter",
+		})
+		return expr
+	}
+
+	return &ast.FuncExpr{
+		Param:    param.Ident,
+		ArrowPos: arrowPos,
+		Body:     body,
+	}
+}
+
+// parseCondExpr parses a conditional expression. If there is no conditional
+// expression in the current state, a single binary expression will be
+// returned instead.
+//
+//	CondExpr = BinOpExpr [ "?" Expression ":" Expression ]
+//
+// The conditional operator has the lowest precedence and is
+// right-associative, so a ? b : c ? d : e is parsed as a ? b : (c ? d : e).
+func (p *parser) parseCondExpr() ast.Expr {
+	cond := p.parseBinOp(1)
+	if p.tok != token.QUESTION {
+		return cond
+	}
+
+	res := &ast.ConditionalExpr{Condition: cond}
+	res.QuestionPos, _, _ = p.expect(token.QUESTION)
+	res.True = p.ParseExpression()
+
+	if p.tok != token.COLON {
+		// Don't consume the unexpected token, which is likely the end of the
+		// statement.
+		p.addErrorf("expected %s, got %s", token.COLON, p.tok)
+		res.False = &ast.LiteralExpr{Kind: token.NULL, Value: "null", ValuePos: p.pos}
+		return res
+	}
+	res.ColonPos, _, _ = p.expect(token.COLON)
+	res.False = p.ParseExpression()
+	return res
 }
 
 // parseBinOp is the entrypoint for binary expressions. If there is no binary
@@ -461,7 +517,7 @@ func (p *parser) parsePowExpr() ast.Expr {
 //	UnaryExpr = OperExpr | unary_op UnaryExpr
 //
 //	OperExpr   = PrimaryExpr { AccessExpr | IndexExpr | CallExpr }
-//	AccessExpr = "." identifier
+//	AccessExpr = ( "." | "?." ) identifier
 //	IndexExpr  = "[" Expression "]"
 //	CallExpr   = "(" [ ExpressionList ] ")"
 func (p *parser) parseUnaryExpr() ast.Expr {
@@ -481,7 +537,8 @@ func (p *parser) parseUnaryExpr() ast.Expr {
 NextOper:
 	for {
 		switch p.tok {
-		case token.DOT: // AccessExpr
+		case token.DOT, token.OPTDOT: // AccessExpr
+			optional := p.tok == token.OPTDOT
 			p.next()
 			namePos, _, name := p.expect(token.IDENT)
 
@@ -491,6 +548,7 @@ NextOper:
 					Name:    name,
 					NamePos: namePos,
 				},
+				Optional: optional,
 			}
 
 		case token.LBRACK: // IndexExpr
@@ -93,8 +93,10 @@ func TestParseExpressions(t *testing.T) {
 		"logical ops":  `true || false && true`,
 		"pow operator": "1 ^ 2 ^ 3",
 
-		"field access":   `a.b.c.d`,
-		"element access": `a[0][1][2]`,
+		"field access":          `a.b.c.d`,
+		"optional field access": `a?.b?.c.d`,
+		"element access":        `a[0][1][2]`,
+		"conditional fraction":  `a ?.5 : 1`,
 
 		"call no args":             `a()`,
 		"call one arg":             `a(1)`,
@@ -108,6 +110,18 @@ func TestParseExpressions(t *testing.T) {
 
 		"parens": `(1 + 5) * 100`,
 
+		"conditional":         `env == "prod" ? "a" : "b"`,
+		"nested conditional":  `a ? b : c ? d : e`,
+		"conditional in call": `f(a ? [1] : [], { x = b ? 1 : 2 })`,
+		"conditional multiline": `a ?
+			b :
+			c`,
+
+		"function":             `x => x + 1`,
+		"function in call":     `array.filter(list, t => t.port == "80" && t.enabled)`,
+		"nested function":      `f(a => f(b => a + b))`,
+		"function conditional": `x => x > 0 ? x : -x`,
+
 		"mixed expression": `(a.b.c)(1, 3 * some_list[magic_index * 2]).resulting_field`,
 	}
 
@@ -149,7 +149,11 @@ func (w *walker) walkExpr(e ast.Expr) {
 
 	case *ast.AccessExpr:
 		w.walkExpr(e.Value)
-		w.p.Write(token.DOT, e.Name)
+		if e.Optional {
+			w.p.Write(token.OPTDOT, e.Name)
+		} else {
+			w.p.Write(token.DOT, e.Name)
+		}
 
 	case *ast.IndexExpr:
 		w.walkExpr(e.Value)
@@ -175,6 +179,18 @@ func (w *walker) walkExpr(e ast.Expr) {
 		w.p.Write(wsBlank, e.KindPos, e.Kind, wsBlank)
 		w.walkExpr(e.Right)
 
+	case *ast.ConditionalExpr:
+		w.walkExpr(e.Condition)
+		w.p.Write(wsBlank, e.QuestionPos, token.QUESTION, wsBlank)
+		w.walkExpr(e.True)
+		w.p.Write(wsBlank, e.ColonPos, token.COLON, wsBlank)
+		w.walkExpr(e.False)
+
+	case *ast.FuncExpr:
+		w.p.Write(e.Param.NamePos, e.Param)
+		w.p.Write(wsBlank, e.ArrowPos, token.ARROW, wsBlank)
+		w.walkExpr(e.Body)
+
 	case *ast.ParenExpr:
 		w.p.Write(token.LPAREN)
 		w.walkExpr(e.Inner)
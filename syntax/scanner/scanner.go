@@ -33,6 +33,7 @@ import (
 //   NEQ     = "!="
 //   ASSIGN  = "="
 //   EQ      = "=="
+//   ARROW   = "=>"
 //   LT      = "<"
 //   LTE     = "<="
 //   GT      = ">"
@@ -51,6 +52,7 @@ import (
 //   RBRACK  = "]"
 //   COMMA   = ","
 //   DOT     = "."
+//   OPTDOT  = "?."
 //
 // The EBNF for escape_sequence is currently undocumented; see scanEscape for
 // details. The escape sequences supported by Alloy are the same as the escape
@@ -275,8 +277,13 @@ scanAgain:
 
 		case '!': // !, !=
 			tok = s.switch2(token.NOT, token.NEQ, '=')
-		case '=': // =, ==
-			tok = s.switch2(token.ASSIGN, token.EQ, '=')
+		case '=': // =, ==, =>
+			if s.ch == '>' {
+				s.next() // consume '>'
+				tok = token.ARROW
+			} else {
+				tok = s.switch2(token.ASSIGN, token.EQ, '=')
+			}
 		case '<': // <, <=
 			tok = s.switch2(token.LT, token.LTE, '=')
 		case '>': // >, >=
@@ -338,6 +345,16 @@ scanAgain:
 		case '.':
 			// NOTE: Fractions starting with '.' are handled by outer switch
 			tok = token.DOT
+		case '?': // ?, ?.
+			// A '?' followed by a fraction like ".5" is a conditional operator.
+			if s.ch == '.' && !isDecimal(rune(s.peek())) {
+				s.next() // consume '.'
+				tok = token.OPTDOT
+			} else {
+				tok = token.QUESTION
+			}
+		case ':':
+			tok = token.COLON
 
 		default:
 			// s.next() reports invalid BOMs so we don't need to repeat the error.
@@ -69,6 +69,10 @@ var tokens = []tokenExample{
 	{token.LCURLY, "{"},
 	{token.COMMA, ","},
 	{token.DOT, "."},
+	{token.OPTDOT, "?."},
+	{token.QUESTION, "?"},
+	{token.COLON, ":"},
+	{token.ARROW, "=>"},
 
 	{token.RPAREN, ")"},
 	{token.RBRACK, "]"},
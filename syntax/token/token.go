@@ -49,14 +49,18 @@ const (
 	MOD // %
 	POW // ^
 
-	LCURLY // {
-	RCURLY // }
-	LPAREN // (
-	RPAREN // )
-	LBRACK // [
-	RBRACK // ]
-	COMMA  // ,
-	DOT    // .
+	LCURLY   // {
+	RCURLY   // }
+	LPAREN   // (
+	RPAREN   // )
+	LBRACK   // [
+	RBRACK   // ]
+	COMMA    // ,
+	DOT      // .
+	OPTDOT   // ?.
+	QUESTION // ?
+	COLON    // :
+	ARROW    // =>
 	operatorEnd
 
 	TERMINATOR // \n
@@ -102,6 +106,11 @@ var tokenNames = [...]string{
 	RBRACK: "]",
 	COMMA:  ",",
 	DOT:    ".",
+	OPTDOT: "?.",
+
+	QUESTION: "?",
+	COLON:    ":",
+	ARROW:    "=>",
 
 	TERMINATOR: "TERMINATOR",
 }
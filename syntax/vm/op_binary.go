@@ -3,7 +3,6 @@ package vm
 import (
 	"fmt"
 	"math"
-	"reflect"
 
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/grafana/alloy/syntax/internal/value"
@@ -29,15 +28,26 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 		rhs = tryUnwrapOptionalSecret(rhs)
 	}
 
+	// Adding a secret to a string or to another secret results in a secret, so
+	// that sensitive values can be composed without being converted with
+	// convert.nonsensitive first.
+	if op == token.ADD {
+		lhsText, lhsSecret, lhsOK := secretOrString(lhs)
+		rhsText, rhsSecret, rhsOK := secretOrString(rhs)
+		if lhsOK && rhsOK && (lhsSecret || rhsSecret) {
+			return value.Encode(alloytypes.Secret(lhsText + rhsText)), nil
+		}
+	}
+
 	// TODO(rfratto): evalBinop should check for underflows and overflows
 
 	// We have special handling for EQ and NEQ since it's valid to attempt to
 	// compare values of any two types.
 	switch op {
 	case token.EQ:
-		return value.Bool(valuesEqual(lhs, rhs)), nil
+		return value.Bool(value.Equal(lhs, rhs)), nil
 	case token.NEQ:
-		return value.Bool(!valuesEqual(lhs, rhs)), nil
+		return value.Bool(!value.Equal(lhs, rhs)), nil
 	}
 
 	// The type of lhs and rhs must be acceptable for the binary operator.
@@ -71,7 +81,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 		}
 
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(lhsNum.Uint() + rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -82,7 +92,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 	case token.SUB: // number - number
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(lhsNum.Uint() - rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -93,7 +103,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 	case token.MUL: // number * number
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(lhsNum.Uint() * rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -104,7 +114,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 	case token.DIV: // number / number
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(lhsNum.Uint() / rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -115,7 +125,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 	case token.MOD: // number % number
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(lhsNum.Uint() % rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -126,7 +136,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 	case token.POW: // number ^ number
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Uint(intPow(lhsNum.Uint(), rhsNum.Uint())), nil
 		case value.NumberKindInt:
@@ -143,7 +153,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 		// Not a string; must be a number.
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Bool(lhsNum.Uint() < rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -160,7 +170,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 		// Not a string; must be a number.
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Bool(lhsNum.Uint() > rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -177,7 +187,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 		// Not a string; must be a number.
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Bool(lhsNum.Uint() <= rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -194,7 +204,7 @@ func evalBinop(lhs value.Value, op token.Token, rhs value.Value) (value.Value, e
 
 		// Not a string; must be a number.
 		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
+		switch value.FitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
 		case value.NumberKindUint:
 			return value.Bool(lhsNum.Uint() >= rhsNum.Uint()), nil
 		case value.NumberKindInt:
@@ -222,79 +232,22 @@ func tryUnwrapOptionalSecret(val value.Value) value.Value {
 	return value.String(optSecret.Value)
 }
 
-// valuesEqual returns true if two Values are equal.
-func valuesEqual(lhs value.Value, rhs value.Value) bool {
-	if lhs.Type() != rhs.Type() {
-		// Two values with different types are never equal.
-		return false
+// secretOrString returns the text of a string, alloytypes.Secret or
+// alloytypes.OptionalSecret value, and whether the value is sensitive. ok is
+// false for any other value.
+func secretOrString(val value.Value) (text string, secret bool, ok bool) {
+	if val.Type() == value.TypeString {
+		return val.Text(), false, true
 	}
 
-	switch lhs.Type() {
-	case value.TypeNull:
-		// Nothing to compare here: both lhs and rhs have the null type,
-		// so they're equal.
-		return true
-
-	case value.TypeNumber:
-		// Two numbers are equal if they have equal values. However, we have to
-		// determine what comparison we want to do and upcast the values to a
-		// different Go type as needed (so that 3 == 3.0 is true).
-		lhsNum, rhsNum := lhs.Number(), rhs.Number()
-		switch fitNumberKinds(lhsNum.Kind(), rhsNum.Kind()) {
-		case value.NumberKindUint:
-			return lhsNum.Uint() == rhsNum.Uint()
-		case value.NumberKindInt:
-			return lhsNum.Int() == rhsNum.Int()
-		case value.NumberKindFloat:
-			return lhsNum.Float() == rhsNum.Float()
-		}
-
-	case value.TypeString:
-		return lhs.Text() == rhs.Text()
-
-	case value.TypeBool:
-		return lhs.Bool() == rhs.Bool()
-
-	case value.TypeArray:
-		// Two arrays are equal if they have equal elements.
-		if lhs.Len() != rhs.Len() {
-			return false
-		}
-		for i := 0; i < lhs.Len(); i++ {
-			if !valuesEqual(lhs.Index(i), rhs.Index(i)) {
-				return false
-			}
-		}
-		return true
-
-	case value.TypeObject:
-		// Two objects are equal if they have equal elements.
-		if lhs.Len() != rhs.Len() {
-			return false
-		}
-		for _, key := range lhs.Keys() {
-			lhsElement, _ := lhs.Key(key)
-			rhsElement, inRHS := rhs.Key(key)
-			if !inRHS {
-				return false
-			}
-			if !valuesEqual(lhsElement, rhsElement) {
-				return false
-			}
-		}
-		return true
-
-	case value.TypeFunction:
-		// Two functions are never equal. We can't compare functions in Go, so
-		// there's no way to compare them in Alloy syntax right now.
-		return false
-
-	case value.TypeCapsule:
-		// Two capsules are only equal if the underlying values are deeply equal.
-		return reflect.DeepEqual(lhs.Interface(), rhs.Interface())
+	switch v := val.Interface().(type) {
+	case alloytypes.Secret:
+		return string(v), true, true
+	case alloytypes.OptionalSecret:
+		return v.Value, v.IsSecret, true
+	default:
+		return "", false, false
 	}
-
-	panic("syntax/vm: unreachable")
 }
 
 // binopAllowedTypes maps what type of values are permitted for a specific
@@ -334,20 +287,6 @@ func acceptableBinopType(val value.Value, op token.Token) bool {
 	return false
 }
 
-func fitNumberKinds(a, b value.NumberKind) value.NumberKind {
-	aPrec, bPrec := numberKindPrec[a], numberKindPrec[b]
-	if aPrec > bPrec {
-		return a
-	}
-	return b
-}
-
-var numberKindPrec = map[value.NumberKind]int{
-	value.NumberKindUint:  0,
-	value.NumberKindInt:   1,
-	value.NumberKindFloat: 2,
-}
-
 func intPow[Number int64 | uint64](n, m Number) Number {
 	if m == 0 {
 		return 1
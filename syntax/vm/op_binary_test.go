@@ -13,9 +13,10 @@ import (
 func TestVM_OptionalSecret_Conversion(t *testing.T) {
 	scope := &vm.Scope{
 		Variables: map[string]any{
-			"string_val":     "hello",
-			"non_secret_val": alloytypes.OptionalSecret{IsSecret: false, Value: "world"},
-			"secret_val":     alloytypes.OptionalSecret{IsSecret: true, Value: "secret"},
+			"string_val":      "hello",
+			"non_secret_val":  alloytypes.OptionalSecret{IsSecret: false, Value: "world"},
+			"secret_val":      alloytypes.OptionalSecret{IsSecret: true, Value: "secret"},
+			"real_secret_val": alloytypes.Secret("token"),
 		},
 	}
 
@@ -61,14 +62,45 @@ func TestVM_OptionalSecret_Conversion(t *testing.T) {
 			expect: bool(false),
 		},
 		{
-			name:        "secret + string",
+			name:   "secret + string",
+			input:  `secret_val + string_val`,
+			expect: alloytypes.Secret("secrethello"),
+		},
+		{
+			name:   "string + secret",
+			input:  `string_val + secret_val`,
+			expect: alloytypes.Secret("hellosecret"),
+		},
+		{
+			name:   "capsule (non secret) + secret",
+			input:  `non_secret_val + secret_val`,
+			expect: alloytypes.Secret("worldsecret"),
+		},
+		{
+			name:   "secret + secret",
+			input:  `secret_val + real_secret_val`,
+			expect: alloytypes.Secret("secrettoken"),
+		},
+		{
+			name:   "string + secret + string",
+			input:  `"Bearer " + real_secret_val + "!"`,
+			expect: alloytypes.Secret("Bearer token!"),
+		},
+		{
+			name:        "secret + string into string",
 			input:       `secret_val + string_val`,
-			expectError: "secret_val should be one of [number string] for binop +",
+			expect:      string(""),
+			expectError: "secrets may not be converted into strings",
+		},
+		{
+			name:        "number + secret",
+			input:       `1 + real_secret_val`,
+			expectError: "real_secret_val should be one of [number string] for binop +",
 		},
 		{
-			name:        "string + secret",
-			input:       `string_val + secret_val`,
-			expectError: "secret_val should be one of [number string] for binop +",
+			name:        "secret - string",
+			input:       `secret_val - string_val`,
+			expectError: "secret_val should be one of [number] for binop -",
 		},
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/diag"
@@ -23,6 +24,18 @@ type Evaluator struct {
 	// optimizations, allowing for precomputing and storing the result of
 	// anything that is constant.
 	node ast.Node
+
+	// randomMut guards the results of the calls to functions of the random
+	// namespace made during the current and the previous call to Evaluate.
+	randomMut       sync.Mutex
+	randomCalls     map[randomCall]value.Value
+	prevRandomCalls map[randomCall]value.Value
+}
+
+// randomCall identifies a call to a function of the random namespace.
+type randomCall struct {
+	expr *ast.CallExpr
+	args string
 }
 
 // New creates a new Evaluator for the given AST node. The given node must be
@@ -42,6 +55,16 @@ func (vm *Evaluator) Evaluate(scope *Scope, v interface{}) (err error) {
 	// return decorated error messages.
 	assoc := make(map[value.Value]ast.Node)
 
+	// Only keep the results of the random calls which are made again. The maps
+	// are swapped and reused, and callRandom allocates them on first use, so
+	// expressions without random calls don't allocate anything here.
+	vm.randomMut.Lock()
+	if len(vm.randomCalls) > 0 || len(vm.prevRandomCalls) > 0 {
+		vm.prevRandomCalls, vm.randomCalls = vm.randomCalls, vm.prevRandomCalls
+		clear(vm.randomCalls)
+	}
+	vm.randomMut.Unlock()
+
 	defer func() {
 		if err != nil {
 			// Decorate the error on return.
@@ -315,6 +338,24 @@ func (vm *Evaluator) evaluateExpr(scope *Scope, assoc map[value.Value]ast.Node,
 		}
 		return evalBinop(lhs, expr.Kind, rhs)
 
+	case *ast.ConditionalExpr:
+		cond, err := vm.evaluateExpr(scope, assoc, expr.Condition)
+		if err != nil {
+			return value.Null, err
+		}
+		if cond.Type() != value.TypeBool {
+			return value.Null, value.TypeError{Value: cond, Expected: value.TypeBool}
+		}
+		// Only the chosen branch is evaluated, so the other one may reference
+		// values which don't exist.
+		if cond.Bool() {
+			return vm.evaluateExpr(scope, assoc, expr.True)
+		}
+		return vm.evaluateExpr(scope, assoc, expr.False)
+
+	case *ast.FuncExpr:
+		return vm.evaluateFunc(scope, expr), nil
+
 	case *ast.ArrayExpr:
 		vals := make([]value.Value, len(expr.Elements))
 		for i, element := range expr.Elements {
@@ -349,110 +390,232 @@ func (vm *Evaluator) evaluateExpr(scope *Scope, assoc map[value.Value]ast.Node,
 		}
 		return value.Encode(val), nil
 
-	case *ast.AccessExpr:
+	case *ast.AccessExpr, *ast.IndexExpr, *ast.CallExpr:
+		val, _, err := vm.evaluateChain(scope, assoc, expr)
+		return val, err
+
+	case *ast.ParenExpr:
+		return vm.evaluateExpr(scope, assoc, expr.Inner)
+
+	case *ast.UnaryExpr:
 		val, err := vm.evaluateExpr(scope, assoc, expr.Value)
 		if err != nil {
 			return value.Null, err
 		}
+		return evalUnaryOp(expr.Kind, val)
+
+	default:
+		panic(fmt.Sprintf("syntax/vm: unexpected ast.Expr type %T", expr))
+	}
+}
+
+// evaluateChain evaluates an access, index or call expression. It also returns
+// true if an optional access of the chain of accesses, indexes and calls
+// leading to expr resulted in null, in which case the rest of the chain is
+// skipped and expr evaluates to null.
+func (vm *Evaluator) evaluateChain(scope *Scope, assoc map[value.Value]ast.Node, expr ast.Expr) (value.Value, bool, error) {
+	switch expr := expr.(type) {
+	case *ast.AccessExpr:
+		val, skip, err := vm.evaluateChainValue(scope, assoc, expr.Value)
+		if skip || err != nil {
+			return value.Null, skip, err
+		}
+
+		// Accessing a field of anything but an object, or a missing field, with
+		// ?. returns null and skips the rest of the chain.
+		if expr.Optional && val.Type() != value.TypeObject {
+			return value.Null, true, nil
+		} else if val.Type() == value.TypeNull {
+			// Null values can't be associated with the node they come from, so
+			// the error is reported at the position of the access.
+			return value.Null, false, diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				StartPos: ast.StartPos(expr).Position(),
+				EndPos:   ast.EndPos(expr).Position(),
+				Message:  fmt.Sprintf("cannot access field %q of null; use ?. to access fields of values which may be null", expr.Name.Name),
+			}
+		}
 
 		switch val.Type() {
 		case value.TypeObject:
 			res, ok := val.Key(expr.Name.Name)
-			if !ok {
-				return value.Null, diag.Diagnostic{
+			if !ok && expr.Optional {
+				return value.Null, true, nil
+			} else if !ok {
+				return value.Null, false, diag.Diagnostic{
 					Severity: diag.SeverityLevelError,
 					StartPos: ast.StartPos(expr.Name).Position(),
 					EndPos:   ast.EndPos(expr.Name).Position(),
 					Message:  fmt.Sprintf("field %q does not exist", expr.Name.Name),
 				}
 			}
-			return res, nil
+			return res, false, nil
 		default:
-			return value.Null, value.Error{
+			return value.Null, false, value.Error{
 				Value: val,
 				Inner: fmt.Errorf("cannot access field %q on value of type %s", expr.Name.Name, val.Type()),
 			}
 		}
 
 	case *ast.IndexExpr:
-		val, err := vm.evaluateExpr(scope, assoc, expr.Value)
-		if err != nil {
-			return value.Null, err
+		val, skip, err := vm.evaluateChainValue(scope, assoc, expr.Value)
+		if skip || err != nil {
+			return value.Null, skip, err
 		}
 		idx, err := vm.evaluateExpr(scope, assoc, expr.Index)
 		if err != nil {
-			return value.Null, err
+			return value.Null, false, err
 		}
 
 		switch val.Type() {
 		case value.TypeArray:
 			// Arrays are indexed with a number.
 			if idx.Type() != value.TypeNumber {
-				return value.Null, value.TypeError{Value: idx, Expected: value.TypeNumber}
+				return value.Null, false, value.TypeError{Value: idx, Expected: value.TypeNumber}
 			}
 			intIndex := int(idx.Int())
 
 			if intIndex < 0 || intIndex >= val.Len() {
-				return value.Null, value.Error{
+				return value.Null, false, value.Error{
 					Value: idx,
 					Inner: fmt.Errorf("index %d is out of range of array with length %d", intIndex, val.Len()),
 				}
 			}
-			return val.Index(intIndex), nil
+			return val.Index(intIndex), false, nil
 
 		case value.TypeObject:
 			// Objects are indexed with a string.
 			if idx.Type() != value.TypeString {
-				return value.Null, value.TypeError{Value: idx, Expected: value.TypeString}
+				return value.Null, false, value.TypeError{Value: idx, Expected: value.TypeString}
 			}
 
 			field, ok := val.Key(idx.Text())
 			if !ok {
 				// If a key doesn't exist in an object accessed with [], return null.
-				return value.Null, nil
+				return value.Null, false, nil
 			}
-			return field, nil
+			return field, false, nil
 
 		default:
-			return value.Null, value.Error{
+			return value.Null, false, value.Error{
 				Value: val,
 				Inner: fmt.Errorf("expected object or array, got %s", val.Type()),
 			}
 		}
 
-	case *ast.ParenExpr:
-		return vm.evaluateExpr(scope, assoc, expr.Inner)
-
-	case *ast.UnaryExpr:
-		val, err := vm.evaluateExpr(scope, assoc, expr.Value)
-		if err != nil {
-			return value.Null, err
-		}
-		return evalUnaryOp(expr.Kind, val)
-
 	case *ast.CallExpr:
-		funcVal, err := vm.evaluateExpr(scope, assoc, expr.Value)
-		if err != nil {
-			return funcVal, err
+		funcVal, skip, err := vm.evaluateChainValue(scope, assoc, expr.Value)
+		if skip || err != nil {
+			return value.Null, skip, err
 		}
 		if funcVal.Type() != value.TypeFunction {
-			return value.Null, value.TypeError{Value: funcVal, Expected: value.TypeFunction}
+			return value.Null, false, value.TypeError{Value: funcVal, Expected: value.TypeFunction}
 		}
 
 		args := make([]value.Value, len(expr.Args))
 		for i := 0; i < len(expr.Args); i++ {
 			args[i], err = vm.evaluateExpr(scope, assoc, expr.Args[i])
 			if err != nil {
-				return value.Null, err
+				return value.Null, false, err
 			}
 		}
-		return funcVal.Call(args...)
+		if stdlib.IsRandom(funcVal) {
+			res, err := vm.callRandom(scope, expr, funcVal, args)
+			return res, false, err
+		}
+		res, err := funcVal.Call(args...)
+		return res, false, err
 
 	default:
 		panic(fmt.Sprintf("syntax/vm: unexpected ast.Expr type %T", expr))
 	}
 }
 
+// evaluateChainValue evaluates the value being accessed, indexed or called by
+// an expression of a chain.
+func (vm *Evaluator) evaluateChainValue(scope *Scope, assoc map[value.Value]ast.Node, expr ast.Expr) (value.Value, bool, error) {
+	switch expr.(type) {
+	case *ast.AccessExpr, *ast.IndexExpr, *ast.CallExpr:
+		v, skip, err := vm.evaluateChain(scope, assoc, expr)
+		if v != value.Null {
+			assoc[v] = expr
+		}
+		return v, skip, err
+	default:
+		v, err := vm.evaluateExpr(scope, assoc, expr)
+		return v, false, err
+	}
+}
+
+// callRandom calls fn, a function of the random namespace. Its result is kept
+// for the lifetime of the Evaluator, which is the lifetime of the loaded
+// config, so evaluating the call again with the same arguments, like when a
+// value it depends on changes, returns the same result. Calls in the body of a
+// function expression also need the same function arguments to return the same
+// result, so that a call in array.map returns a different value per element.
+func (vm *Evaluator) callRandom(scope *Scope, expr *ast.CallExpr, fn value.Value, args []value.Value) (value.Value, error) {
+	var keyValues []interface{}
+	for _, arg := range args {
+		keyValues = append(keyValues, arg.Interface())
+	}
+	for s := scope; s != nil; s = s.Parent {
+		if !s.funcParam {
+			continue
+		}
+		for _, param := range s.Variables {
+			keyValues = append(keyValues, param.(value.Value).Interface())
+		}
+	}
+	call := randomCall{expr: expr, args: fmt.Sprintf("%#v", keyValues)}
+
+	vm.randomMut.Lock()
+	defer vm.randomMut.Unlock()
+
+	if res, ok := vm.randomCalls[call]; ok {
+		return res, nil
+	}
+	res, ok := vm.prevRandomCalls[call]
+	if !ok {
+		var err error
+		if res, err = fn.Call(args...); err != nil {
+			return value.Null, err
+		}
+	}
+	if vm.randomCalls == nil {
+		vm.randomCalls = make(map[randomCall]value.Value)
+	}
+	vm.randomCalls[call] = res
+	return res, nil
+}
+
+// evaluateFunc returns a function value which evaluates the body of expr with
+// the parameter of expr set to its argument.
+func (vm *Evaluator) evaluateFunc(scope *Scope, expr *ast.FuncExpr) value.Value {
+	return value.Func(value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if len(args) != 1 {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("expected 1 args, got %d", len(args)),
+			}
+		}
+
+		inner := &Scope{
+			Parent:    scope,
+			Variables: map[string]interface{}{expr.Param.Name: args[0]},
+			funcParam: true,
+		}
+
+		// The function may be called after Evaluate returns, or from multiple
+		// goroutines, so each call decorates its errors with its own assoc map.
+		assoc := make(map[value.Value]ast.Node)
+		res, err := vm.evaluateExpr(inner, assoc, expr.Body)
+		if err != nil {
+			return value.Null, makeDiagnostic(err, assoc)
+		}
+		return res, nil
+	}))
+}
+
 // A Scope exposes a set of variables available to use during evaluation.
 type Scope struct {
 	// Parent optionally points to a parent Scope containing more variable.
@@ -467,6 +630,10 @@ type Scope struct {
 	// Evaluate; maps and slices will be copied by reference for performance
 	// optimizations.
 	Variables map[string]interface{}
+
+	// funcParam is true if Variables holds the parameter of a function
+	// expression.
+	funcParam bool
 }
 
 // Lookup looks up a named identifier from the scope, all of the scope's
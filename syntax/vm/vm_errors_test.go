@@ -65,6 +65,30 @@ func TestVM_ExprErrors(t *testing.T) {
 			}{},
 			expect: `test:1:7: [0, 1, 2] should be string, got array`,
 		},
+		{
+			name:  "conditional with non-bool condition",
+			input: `key = 1 + 1 ? "a" : "b"`,
+			into: &struct {
+				Key string `alloy:"key,attr"`
+			}{},
+			expect: `test:1:7: 1 + 1 should be bool, got number`,
+		},
+		{
+			name:  "error in function body",
+			input: `key = (x => x + "a")(1)`,
+			into: &struct {
+				Key int `alloy:"key,attr"`
+			}{},
+			expect: `test:1:17: "a" should be number, got string`,
+		},
+		{
+			name:  "function called with wrong number of arguments",
+			input: `key = (x => x)(1, 2)`,
+			into: &struct {
+				Key int `alloy:"key,attr"`
+			}{},
+			expect: `test:1:7: (x => x) expected 1 args, got 2`,
+		},
 	}
 
 	for _, tc := range tt {
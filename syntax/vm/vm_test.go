@@ -123,6 +123,20 @@ func TestVM_Evaluate(t *testing.T) {
 		{`{ a = 15 }.a`, int(15)},
 		{`{ a = { b = 12 } }.a.b`, int(12)},
 		{`{}["foo"]`, nil},
+		{`{ a = 15 }?.a`, int(15)},
+		{`{ a = 15 }?.b`, nil},
+		{`{ a = { b = 12 } }?.a?.b`, int(12)},
+		{`{ a = 15 }?.b?.c`, nil},
+		{`null?.a`, nil},
+		{`null?.a.b`, nil},
+		{`null?.a[0].b`, nil},
+		{`null?.a.b()`, nil},
+		{`{ a = 15 }?.b.c`, nil},
+		{`{ a = 15 }.a?.b`, nil},
+		{`[1, 2]?.a`, nil},
+		{`"abc"?.a.b`, nil},
+		{`{ a = { b = 12 } }?.a.b`, int(12)},
+		{`true ?.5 : 1`, float64(0.5)},
 
 		// Indexing
 		{`[0, 1, 2][1]`, int(1)},
@@ -132,6 +146,22 @@ func TestVM_Evaluate(t *testing.T) {
 		// Paren
 		{`(15)`, int(15)},
 
+		// Conditional
+		{`true ? 1 : 2`, int(1)},
+		{`false ? 1 : 2`, int(2)},
+		{`foobar == 42 ? "yes" : "no"`, string("yes")},
+		{`false ? 1 : true ? 2 : 3`, int(2)},
+		{`(true ? [1] : [2, 3])[0]`, int(1)},
+		{`1 + 2 == 3 ? 1 + 2 : 0`, int(3)},
+		{`true ? 1 : does_not_exist`, int(1)}, // Only the chosen branch is evaluated
+
+		// Functions
+		{`(x => x + 1)(2)`, int(3)},
+		{`(x => x + foobar)(1)`, int(43)}, // Variables of the enclosing scope are visible
+		{`(foobar => foobar)(1)`, int(1)}, // Parameters shadow the enclosing scope
+		{`(x => y => x - y)(5)(3)`, int(2)},
+		{`(x => x.a ? x.b : 0)({ a = true, b = 7 })`, int(7)},
+
 		// Unary
 		{`!true`, bool(false)},
 		{`!false`, bool(true)},
@@ -237,6 +267,29 @@ func TestVM_Evaluate_AccessExpr(t *testing.T) {
 		require.EqualError(t, err, `1:12: field "b" does not exist`)
 	})
 
+	t.Run("Lookup on non-object", func(t *testing.T) {
+		expr, err := parser.ParseExpression(`{ a = 15 }.a.b`)
+		require.NoError(t, err)
+
+		eval := vm.New(expr)
+
+		var v interface{}
+		err = eval.Evaluate(nil, &v)
+		require.EqualError(t, err, `1:1: {a = 15}.a cannot access field "b" on value of type number`)
+	})
+
+	t.Run("Lookup after parenthesized optional lookup", func(t *testing.T) {
+		// Parentheses end the chain skipped by ?.
+		expr, err := parser.ParseExpression(`({ a = 15 }?.b).c`)
+		require.NoError(t, err)
+
+		eval := vm.New(expr)
+
+		var v interface{}
+		err = eval.Evaluate(nil, &v)
+		require.EqualError(t, err, `1:1: cannot access field "c" of null; use ?. to access fields of values which may be null`)
+	})
+
 	t.Run("Invalid lookup 2", func(t *testing.T) {
 		_, err := parser.ParseExpression(`{ a = 15 }.7`)
 		require.EqualError(t, err, `1:11: expected TERMINATOR, got FLOAT`)